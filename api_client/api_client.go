@@ -4,47 +4,97 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"fmt"
+	"log/slog"
 	"math/big"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/rysk-finance/v2_client_go/audit"
 	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/logging"
+	"github.com/rysk-finance/v2_client_go/metrics"
+	"github.com/rysk-finance/v2_client_go/ratelimit"
+	"github.com/rysk-finance/v2_client_go/tracing"
+	"github.com/rysk-finance/v2_client_go/tx_manager"
+	"github.com/rysk-finance/v2_client_go/typed_data"
 	"github.com/rysk-finance/v2_client_go/types"
 	"github.com/rysk-finance/v2_client_go/utils"
 
-	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	geth_types "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// typedDataEndpoints are the REST endpoints of the actions submitted by `SubmitTypedData`, by primary type.
+var typedDataEndpoints = map[types.PrimaryType]struct {
+	method string
+	path   types.APIEndpoint
+}{
+	constants.PRIMARY_TYPE_ORDER:          {http.MethodPost, constants.API_ENDPOINT_NEW_ORDER},
+	constants.PRIMARY_TYPE_CANCEL_ORDER:   {http.MethodDelete, constants.API_ENDPOINT_CANCEL_ORDER},
+	constants.PRIMARY_TYPE_CANCEL_ORDERS:  {http.MethodDelete, constants.API_ENDPOINT_CANCEL_ALL_OPEN_ORDERS},
+	constants.PRIMARY_TYPE_APPROVE_SIGNER: {http.MethodPost, constants.API_ENDPOINT_APPROVE_REVOKE_SIGNER},
+	constants.PRIMARY_TYPE_WITHDRAW:       {http.MethodPost, constants.API_ENDPOINT_WITHDRAW},
+	constants.PRIMARY_TYPE_REFERRAL:       {http.MethodPost, constants.API_ENDPOINT_ADD_REFEREE},
+}
+
 // RyskV2APIClientConfiguration holds the configuration for the RyskV2 API client.
 type RyskV2APIClientConfiguration struct {
-	Env          types.Environment // `constants.ENVIRONMENT_TESTNET` or `constants.ENVIRONMENT_MAINNET`.
-	PrivateKey   string            // Private key as a string, e.g., `0x2638b4...` or `2638b4...`.
-	RpcUrl       string            // RPC URL of the Ethereum client.
-	SubAccountId uint8             // ID of the subaccount to use.
+	Env                types.Environment                           // `constants.ENVIRONMENT_TESTNET` or `constants.ENVIRONMENT_MAINNET`.
+	PrivateKey         string                                      // Private key as a string, e.g., `0x2638b4...` or `2638b4...`.
+	RpcUrl             string                                      // RPC URL of the Ethereum client.
+	SubAccountId       uint8                                       // ID of the subaccount to use.
+	Gas                *types.GasConfiguration                     // Optional EIP-1559 gas settings for on-chain transactions, defaults to suggested fees.
+	TransactionManager *tx_manager.TransactionManagerConfiguration // Optional transaction manager settings, on-chain transactions track nonces locally when set.
+	ApprovalMode       types.ApprovalMode                          // Approval mode used by `Deposit`, `constants.APPROVAL_MODE_EXACT` (default) or `constants.APPROVAL_MODE_MAX`.
+	BaseUrl            string                                      // Optional REST API base URL, defaults to `constants.API_BASE_URL[Env]`.
+	Retry              *utils.RetryConfiguration                   // Optional retry policy for REST requests, requests are sent once when nil.
+	RateLimiter        *ratelimit.RateLimiter                      // Optional rate limiter of REST requests, e.g. shared with the websocket client of the account.
+	Metrics            *metrics.Metrics                            // Optional metrics of REST requests, signatures, order rejects and transactions, nothing is recorded when nil.
+	Tracer             *tracing.Tracer                             // Optional tracer of the client methods, signatures and REST requests, nothing is traced when nil.
+	Logger             *slog.Logger                                // Optional logger of REST requests and responses with redacted signatures, retries and transactions, nothing is logged when nil.
+	AuditLog           *audit.Log                                  // Optional audit log of signed orders, cancels, signer approvals and withdrawals with their responses, actions are not sent when they cannot be recorded.
 }
 
 // RyskV2APIClient is the main client for interacting with the RyskV2 API.
 type RyskV2APIClient struct {
-	env              types.Environment        // Environment (testnet or mainnet).
-	baseUrl          string                   // Base URL for the API.
-	privateKeyString string                   // Private key as a string.
-	addressString    string                   // Address derived from the private key.
-	privateKey       *ecdsa.PrivateKey        // ECDSA private key.
-	address          common.Address           // Common address derived from the private key.
-	ciao             common.Address           // Address for the CIAO contract.
-	usdb             common.Address           // Address for the USDC contract.
-	domain           apitypes.TypedDataDomain // Typed data domain for EIP-712.
-	SubAccountId     int64                    // Subaccount ID.
-	HttpClient       *http.Client             // HTTP client for making requests.
-	EthClient        types.IEthClient         // Ethereum client for interacting with the blockchain.
+	env                types.Environment              // Environment (testnet or mainnet).
+	baseUrl            string                         // Base URL for the API.
+	privateKeyString   string                         // Private key as a string.
+	addressString      string                         // Address derived from the private key.
+	privateKey         *ecdsa.PrivateKey              // ECDSA private key.
+	address            common.Address                 // Common address derived from the private key.
+	ciao               common.Address                 // Address for the CIAO contract.
+	usdb               common.Address                 // Address for the USDC contract.
+	domain             apitypes.TypedDataDomain       // Typed data domain for EIP-712.
+	SubAccountId       int64                          // Subaccount ID.
+	HttpClient         *http.Client                   // HTTP client for making requests, wrapped in the configured middlewares.
+	EthClient          types.IEthClient               // Ethereum client for interacting with the blockchain.
+	GasConfiguration   *types.GasConfiguration        // EIP-1559 gas settings for on-chain transactions.
+	TransactionManager *tx_manager.TransactionManager // Optional transaction manager for on-chain transactions.
+	ApprovalMode       types.ApprovalMode             // Approval mode used by `Deposit`, `constants.APPROVAL_MODE_EXACT` (default) or `constants.APPROVAL_MODE_MAX`.
+	metrics            *metrics.Metrics               // Optional metrics, nil records nothing.
+	tracer             *tracing.Tracer                // Optional tracer, nil traces nothing.
+	logger             *slog.Logger                   // Optional logger, nil logs nothing.
+	auditLog           *audit.Log                     // Optional audit log, nil records nothing.
+
+	wrapHTTPClient func(types.IHTTPClient) types.IHTTPClient // wrapHTTPClient wraps a client in the logging, metrics, tracing, rate limiter and retry middlewares.
+	httpClient     types.IHTTPClient                         // httpClient sends requests through the middlewares with `HttpClient`.
+}
+
+// exportedHTTPClient sends requests with the current `HttpClient` of a client, so replacing it keeps the middlewares.
+type exportedHTTPClient struct {
+	client *RyskV2APIClient
+}
+
+func (exported *exportedHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return exported.client.HttpClient.Do(req)
 }
 
 // NewRyskV2APIClient creates a new RyskV2APIClient instance.
@@ -72,45 +122,141 @@ func NewRyskV2APIClient(config *RyskV2APIClientConfiguration) (*RyskV2APIClient,
 		return nil, fmt.Errorf("failed to connect to the Ethereum client: %v", err)
 	}
 
+	// Default base URL to the environment one.
+	baseUrl := config.BaseUrl
+	if baseUrl == "" {
+		baseUrl = constants.API_BASE_URL[config.Env]
+	}
+
+	// Wrap HTTP client with logging, metrics, tracing, rate limiter and retry policy, each attempt being logged, recorded,
+	// traced and drawing from the budget.
+	wrapHTTPClient := func(httpClient types.IHTTPClient) types.IHTTPClient {
+		if config.Logger != nil {
+			httpClient = logging.NewHTTPClient(httpClient, config.Logger)
+		}
+		if config.Metrics != nil {
+			httpClient = metrics.NewHTTPClient(httpClient, config.Metrics)
+		}
+		if config.Tracer != nil {
+			httpClient = tracing.NewHTTPClient(httpClient, config.Tracer)
+		}
+		if config.RateLimiter != nil {
+			httpClient = ratelimit.NewHTTPClient(httpClient, config.RateLimiter)
+		}
+		if config.Retry != nil {
+			retry := *config.Retry
+			retry.OnRetry = func(request *http.Request, attempt int, err error, delay time.Duration) {
+				logging.OrDiscard(config.Logger).WarnContext(request.Context(), "retrying rest request", "method", request.Method, "url", request.URL.String(), "attempt", attempt, "error", err, "delay", delay)
+				if config.Retry.OnRetry != nil {
+					config.Retry.OnRetry(request, attempt, err, delay)
+				}
+			}
+			httpClient = utils.NewRetryHTTPClient(httpClient, &retry)
+		}
+		return httpClient
+	}
+
 	// Return a new `RyskV2.Client`.
 	apiClient := &RyskV2APIClient{
 		env:              config.Env,
-		baseUrl:          constants.API_BASE_URL[config.Env],
+		baseUrl:          baseUrl,
 		privateKey:       privateKey,
 		privateKeyString: privateKeyString,
 		address:          common.HexToAddress(utils.AddressFromPrivateKey(privateKeyString)),
 		addressString:    utils.AddressFromPrivateKey(privateKeyString),
 		ciao:             common.HexToAddress(constants.CIAO_ADDRESS[config.Env]),
 		usdb:             common.HexToAddress(constants.USDC_ADDRESS[config.Env]),
-		domain: apitypes.TypedDataDomain{
-			Name:              constants.DOMAIN_NAME,
-			Version:           constants.DOMAIN_VERSION,
-			ChainId:           constants.CHAIN_ID[config.Env],
-			VerifyingContract: constants.ORDER_DISPATCHER_ADDRESS[config.Env],
-		},
-		SubAccountId: int64(config.SubAccountId),
-		HttpClient:   utils.GetHTTPClient(10 * time.Second),
-		EthClient:    client,
+		domain:           typed_data.Domain(config.Env),
+		SubAccountId:     int64(config.SubAccountId),
+		HttpClient:       utils.GetHTTPClient(10 * time.Second),
+		EthClient:        client,
+		GasConfiguration: config.Gas,
+		ApprovalMode:     config.ApprovalMode,
+		metrics:          config.Metrics,
+		tracer:           config.Tracer,
+		logger:           config.Logger,
+		auditLog:         config.AuditLog,
+		wrapHTTPClient:   wrapHTTPClient,
+	}
+	apiClient.httpClient = wrapHTTPClient(&exportedHTTPClient{client: apiClient})
+
+	// Create transaction manager.
+	if config.TransactionManager != nil {
+		transactionManagerConfiguration := *config.TransactionManager
+		transactionManagerConfiguration.EthClient = client
+		transactionManagerConfiguration.PrivateKey = privateKeyString
+		if transactionManagerConfiguration.GasConfiguration == nil {
+			transactionManagerConfiguration.GasConfiguration = config.Gas
+		}
+		apiClient.TransactionManager, err = tx_manager.NewTransactionManager(&transactionManagerConfiguration)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create transaction manager: %v", err)
+		}
 	}
 
 	apiClient.addReferee()
 	return apiClient, nil
 }
 
+// Address returns the Ethereum address of the account.
+//
+// Returns:
+//   - The account address derived from the private key.
+func (RyskV2Client *RyskV2APIClient) Address() common.Address {
+	return RyskV2Client.address
+}
+
+// USDCAddress returns the address of the USDC token of the environment.
+//
+// Returns:
+//   - The USDC token address.
+func (RyskV2Client *RyskV2APIClient) USDCAddress() common.Address {
+	return RyskV2Client.usdb
+}
+
+// WithSubAccount returns a copy of the client acting on another sub-account.
+// The copy shares the signer, HTTP client, Ethereum client and transaction manager with the original client.
+//
+// Parameters:
+//   - subAccountId: The ID of the sub-account to act on.
+//
+// Returns:
+//   - A pointer to a RyskV2APIClient acting on `subAccountId`.
+func (RyskV2Client *RyskV2APIClient) WithSubAccount(subAccountId uint8) *RyskV2APIClient {
+	subAccountClient := *RyskV2Client
+	subAccountClient.SubAccountId = int64(subAccountId)
+	subAccountClient.httpClient = subAccountClient.wrapHTTPClient(&exportedHTTPClient{client: &subAccountClient})
+	return &subAccountClient
+}
+
 // Get24hrPriceChangeStatistics returns 24-hour rolling window price change statistics.
 // These statistics do not reflect the UTC day, but rather a 24-hour rolling window for the previous 24 hours.
 // If no `Product` is provided, ticker data for all assets will be returned.
 //
+// It calls `Get24hrPriceChangeStatisticsCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) Get24hrPriceChangeStatistics(product *types.Product) (*http.Response, error) {
+	return RyskV2Client.Get24hrPriceChangeStatisticsCtx(context.Background(), product)
+}
+
+// Get24hrPriceChangeStatisticsCtx returns 24-hour rolling window price change statistics.
+// These statistics do not reflect the UTC day, but rather a 24-hour rolling window for the previous 24 hours.
+// If no `Product` is provided, ticker data for all assets will be returned.
+//
 // Parameters:
+//   - ctx: Context bounding the HTTP request for the statistics.
 //   - product: A pointer to a Product struct for which the statistics are being retrieved.
 //     If nil, ticker data for all assets will be returned.
 //
 // Returns:
 //   - A pointer to an http.Response containing the response from the server.
 //   - An error if the request fails.
-func (RyskV2Client *RyskV2APIClient) Get24hrPriceChangeStatistics(product *types.Product) (*http.Response, error) {
+func (RyskV2Client *RyskV2APIClient) Get24hrPriceChangeStatisticsCtx(ctx context.Context, product *types.Product) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "Get24hrPriceChangeStatistics", tracing.Product(product))
+	defer span.End()
+
 	// Create HTTP request.
-	request, err := http.NewRequest(
+	request, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		RyskV2Client.baseUrl+string(constants.API_ENDPOINT_GET_24H_TICKER_PRICE_CHANGE_STATISTICS),
 		nil,
@@ -127,20 +273,32 @@ func (RyskV2Client *RyskV2APIClient) Get24hrPriceChangeStatistics(product *types
 	}
 
 	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	return utils.SendHTTPRequest(RyskV2Client.httpClient, request)
 }
 
 // GetProduct returns details for a specific product by its symbol.
 //
+// It calls `GetProductCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) GetProduct(symbol string) (*http.Response, error) {
+	return RyskV2Client.GetProductCtx(context.Background(), symbol)
+}
+
+// GetProductCtx returns details for a specific product by its symbol.
+//
 // Parameters:
+//   - ctx: Context bounding the HTTP request for the product.
 //   - symbol: The symbol of the product for which details are being retrieved.
 //
 // Returns:
 //   - A pointer to an http.Response containing the response from the server with product details.
 //   - An error if the request fails.
-func (RyskV2Client *RyskV2APIClient) GetProduct(symbol string) (*http.Response, error) {
+func (RyskV2Client *RyskV2APIClient) GetProductCtx(ctx context.Context, symbol string) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "GetProduct", tracing.ATTRIBUTE_PRODUCT.String(symbol))
+	defer span.End()
+
 	// Create HTTP request.
-	request, err := http.NewRequest(
+	request, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		RyskV2Client.baseUrl+string(constants.API_ENDPOINT_GET_PRODUCT)+symbol,
 		nil,
@@ -150,20 +308,32 @@ func (RyskV2Client *RyskV2APIClient) GetProduct(symbol string) (*http.Response,
 	}
 
 	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	return utils.SendHTTPRequest(RyskV2Client.httpClient, request)
 }
 
 // GetProductById retrieves details for a specific product by its unique identifier.
 //
+// It calls `GetProductByIdCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) GetProductById(id int64) (*http.Response, error) {
+	return RyskV2Client.GetProductByIdCtx(context.Background(), id)
+}
+
+// GetProductByIdCtx retrieves details for a specific product by its unique identifier.
+//
 // Parameters:
+//   - ctx: Context bounding the HTTP request for the product.
 //   - id: The ID of the product.
 //
 // Returns:
 //   - A pointer to an http.Response containing the response from the API call.
 //   - An error if the API call fails or if the response is not as expected.
-func (RyskV2Client *RyskV2APIClient) GetProductById(id int64) (*http.Response, error) {
+func (RyskV2Client *RyskV2APIClient) GetProductByIdCtx(ctx context.Context, id int64) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "GetProductById")
+	defer span.End()
+
 	// Create HTTP request.
-	request, err := http.NewRequest(
+	request, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		RyskV2Client.baseUrl+string(constants.API_ENDPOINT_GET_PRODUCT_BY_ID)+strconv.FormatInt(id, 10),
 		nil,
@@ -173,21 +343,33 @@ func (RyskV2Client *RyskV2APIClient) GetProductById(id int64) (*http.Response, e
 	}
 
 	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	return utils.SendHTTPRequest(RyskV2Client.httpClient, request)
 }
 
 // GetKlineData retrieves Kline/Candlestick bars for a symbol based on the provided parameters.
 //
+// It calls `GetKlineDataCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) GetKlineData(params *types.KlineDataRequest) (*http.Response, error) {
+	return RyskV2Client.GetKlineDataCtx(context.Background(), params)
+}
+
+// GetKlineDataCtx retrieves Kline/Candlestick bars for a symbol based on the provided parameters.
+//
 // Parameters:
+//   - ctx: Context bounding the HTTP request for the klines.
 //   - params: A pointer to a KlineDataRequest struct containing the parameters for the request,
 //     including symbol, interval (timeframe), startTime, and optional endTime.
 //
 // Returns:
 //   - A pointer to an http.Response containing the response from the API call.
 //   - An error if the API call fails or if the response is not as expected.
-func (RyskV2Client *RyskV2APIClient) GetKlineData(params *types.KlineDataRequest) (*http.Response, error) {
+func (RyskV2Client *RyskV2APIClient) GetKlineDataCtx(ctx context.Context, params *types.KlineDataRequest) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "GetKlineData", tracing.Product(params.Product))
+	defer span.End()
+
 	// Create HTTP request.
-	request, err := http.NewRequest(
+	request, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		string(RyskV2Client.baseUrl)+string(constants.API_ENDPOINT_GET_KLINE_DATA),
 		nil,
@@ -214,17 +396,31 @@ func (RyskV2Client *RyskV2APIClient) GetKlineData(params *types.KlineDataRequest
 	request.URL.RawQuery = query.Encode()
 
 	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	return utils.SendHTTPRequest(RyskV2Client.httpClient, request)
 }
 
 // ListProducts retrieves a list of products available for trading on the platform.
 //
+// It calls `ListProductsCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) ListProducts() (*http.Response, error) {
+	return RyskV2Client.ListProductsCtx(context.Background())
+}
+
+// ListProductsCtx retrieves a list of products available for trading on the platform.
+//
+// Parameters:
+//   - ctx: Context bounding the HTTP request for the products.
+//
 // Returns:
 //   - A pointer to an http.Response containing the response from the API call.
 //   - An error if the API call fails or if the response is not as expected.
-func (RyskV2Client *RyskV2APIClient) ListProducts() (*http.Response, error) {
+func (RyskV2Client *RyskV2APIClient) ListProductsCtx(ctx context.Context) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "ListProducts")
+	defer span.End()
+
 	// Create HTTP request.
-	request, err := http.NewRequest(
+	request, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		string(RyskV2Client.baseUrl)+string(constants.API_ENDPOINT_LIST_PRODUCTS),
 		nil,
@@ -234,20 +430,32 @@ func (RyskV2Client *RyskV2APIClient) ListProducts() (*http.Response, error) {
 	}
 
 	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	return utils.SendHTTPRequest(RyskV2Client.httpClient, request)
 }
 
 // OrderBook retrieves the order book (bids and asks) for a specific market.
 //
+// It calls `OrderBookCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) OrderBook(params *types.OrderBookRequest) (*http.Response, error) {
+	return RyskV2Client.OrderBookCtx(context.Background(), params)
+}
+
+// OrderBookCtx retrieves the order book (bids and asks) for a specific market.
+//
 // Parameters:
+//   - ctx: Context bounding the HTTP request for the order book.
 //   - params: A pointer to an OrderBookRequest struct containing the parameters for the request.
 //
 // Returns:
 //   - A pointer to an http.Response containing the response from the API call.
 //   - An error if the API call fails or if the response is not as expected.
-func (RyskV2Client *RyskV2APIClient) OrderBook(params *types.OrderBookRequest) (*http.Response, error) {
+func (RyskV2Client *RyskV2APIClient) OrderBookCtx(ctx context.Context, params *types.OrderBookRequest) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "OrderBook", tracing.Product(params.Product))
+	defer span.End()
+
 	// Create HTTP request.
-	request, err := http.NewRequest(
+	request, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		string(RyskV2Client.baseUrl)+string(constants.API_ENDPOINT_ORDER_BOOK),
 		nil,
@@ -268,17 +476,31 @@ func (RyskV2Client *RyskV2APIClient) OrderBook(params *types.OrderBookRequest) (
 	request.URL.RawQuery = query.Encode()
 
 	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	return utils.SendHTTPRequest(RyskV2Client.httpClient, request)
 }
 
 // ServerTime retrieves the current server time from the API.
 //
+// It calls `ServerTimeCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) ServerTime() (*http.Response, error) {
+	return RyskV2Client.ServerTimeCtx(context.Background())
+}
+
+// ServerTimeCtx retrieves the current server time from the API.
+//
+// Parameters:
+//   - ctx: Context bounding the HTTP request for the server time.
+//
 // Returns:
 //   - A pointer to an http.Response containing the response from the API call.
 //   - An error if the API call fails or if the response is not as expected.
-func (RyskV2Client *RyskV2APIClient) ServerTime() (*http.Response, error) {
+func (RyskV2Client *RyskV2APIClient) ServerTimeCtx(ctx context.Context) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "ServerTime")
+	defer span.End()
+
 	// Create HTTP request.
-	request, err := http.NewRequest(
+	request, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		string(RyskV2Client.baseUrl)+string(constants.API_ENDPOINT_SERVER_TIME),
 		nil,
@@ -288,35 +510,59 @@ func (RyskV2Client *RyskV2APIClient) ServerTime() (*http.Response, error) {
 	}
 
 	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	return utils.SendHTTPRequest(RyskV2Client.httpClient, request)
 }
 
 // ApproveSigner approves a Signer for a SubAccount. This operation allows the specified
 // Signer to sign transactions on behalf of the SubAccount.
 //
+// It calls `ApproveSignerCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) ApproveSigner(params *types.ApproveRevokeSignerRequest) (*http.Response, error) {
+	return RyskV2Client.ApproveSignerCtx(context.Background(), params)
+}
+
+// ApproveSignerCtx approves a Signer for a SubAccount. This operation allows the specified
+// Signer to sign transactions on behalf of the SubAccount.
+//
 // Params:
+//   - ctx: Context bounding the signing of the approval and its HTTP request.
 //   - params: An instance of types.ApproveRevokeSignerRequest containing the necessary
 //     parameters for approving the signer.
 //
 // Returns:
 //   - A pointer to an http.Response containing the response from the API call.
 //   - An error if the API call fails or if the response is not as expected.
-func (RyskV2Client *RyskV2APIClient) ApproveSigner(params *types.ApproveRevokeSignerRequest) (*http.Response, error) {
-	return RyskV2Client.approveRevokeSigner(params, true)
+func (RyskV2Client *RyskV2APIClient) ApproveSignerCtx(ctx context.Context, params *types.ApproveRevokeSignerRequest) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "ApproveSigner")
+	defer span.End()
+
+	return RyskV2Client.approveRevokeSigner(ctx, params, true)
 }
 
 // RevokeSigner revokes a Signer for a SubAccount. This operation disables the specified
 // Signer from signing transactions on behalf of the SubAccount.
 //
+// It calls `RevokeSignerCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) RevokeSigner(params *types.ApproveRevokeSignerRequest) (*http.Response, error) {
+	return RyskV2Client.RevokeSignerCtx(context.Background(), params)
+}
+
+// RevokeSignerCtx revokes a Signer for a SubAccount. This operation disables the specified
+// Signer from signing transactions on behalf of the SubAccount.
+//
 // Params:
+//   - ctx: Context bounding the signing of the revocation and its HTTP request.
 //   - params: An instance of types.ApproveRevokeSignerRequest containing the necessary
 //     parameters for revoking the signer.
 //
 // Returns:
 //   - A pointer to an http.Response containing the response from the API call.
 //   - An error if the API call fails or if the response is not as expected.
-func (RyskV2Client *RyskV2APIClient) RevokeSigner(params *types.ApproveRevokeSignerRequest) (*http.Response, error) {
-	return RyskV2Client.approveRevokeSigner(params, false)
+func (RyskV2Client *RyskV2APIClient) RevokeSignerCtx(ctx context.Context, params *types.ApproveRevokeSignerRequest) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "RevokeSigner")
+	defer span.End()
+
+	return RyskV2Client.approveRevokeSigner(ctx, params, false)
 }
 
 // approveRevokeSigner approves or revokes a signer for a `SubAccount`.
@@ -325,17 +571,17 @@ func (RyskV2Client *RyskV2APIClient) RevokeSigner(params *types.ApproveRevokeSig
 // based on the value of `isApproved`.
 //
 // Parameters:
+//   - ctx: Context bounding the signing of the approval or revocation and its HTTP request.
 //   - params: The parameters containing the request details, including signer information.
 //   - isApproved: Boolean flag indicating whether to approve (true) or revoke (false) the signer.
 //
 // Returns:
 //   - *http.Response: The HTTP response received from the API after the operation.
 //   - error: An error if the operation encountered any issues.
-func (RyskV2Client *RyskV2APIClient) approveRevokeSigner(params *types.ApproveRevokeSignerRequest, isApproved bool) (*http.Response, error) {
+func (RyskV2Client *RyskV2APIClient) approveRevokeSigner(ctx context.Context, params *types.ApproveRevokeSignerRequest, isApproved bool) (*http.Response, error) {
 	// Generate EIP712 signature.
-	signature, err := utils.SignMessage(
-		RyskV2Client.domain,
-		RyskV2Client.privateKeyString,
+	signature, action, err := RyskV2Client.signAction(
+		ctx,
 		constants.PRIMARY_TYPE_APPROVE_SIGNER,
 		&struct {
 			Account        string `json:"account"`
@@ -356,7 +602,8 @@ func (RyskV2Client *RyskV2APIClient) approveRevokeSigner(params *types.ApproveRe
 	}
 
 	// Create HTTP request.
-	request, err := utils.CreateHTTPRequestWithBody(
+	request, err := utils.CreateHTTPRequestWithBodyCtx(
+		ctx,
 		http.MethodPost,
 		string(RyskV2Client.baseUrl)+string(constants.API_ENDPOINT_APPROVE_REVOKE_SIGNER),
 		&struct {
@@ -379,24 +626,34 @@ func (RyskV2Client *RyskV2APIClient) approveRevokeSigner(params *types.ApproveRe
 		return nil, err
 	}
 
-	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	// Send HTTP request, recording the action, and return result.
+	return RyskV2Client.sendAction(action, request)
 }
 
 // Withdraw initiates a withdrawal of USDC from the Rysk V2 account.
 //
+// It calls `WithdrawCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) Withdraw(params *types.WithdrawRequest) (*http.Response, error) {
+	return RyskV2Client.WithdrawCtx(context.Background(), params)
+}
+
+// WithdrawCtx initiates a withdrawal of USDC from the Rysk V2 account.
+//
 // Params:
+//   - ctx: Context bounding the signing of the withdrawal and its HTTP request.
 //   - params: An instance of types.WithdrawRequest containing the withdrawal parameters,
 //     including the withdrawal amount and destination address.
 //
 // Returns:
 //   - A pointer to an http.Response containing the response from the API call.
 //   - An error if the API call fails or if the response is not as expected.
-func (RyskV2Client *RyskV2APIClient) Withdraw(params *types.WithdrawRequest) (*http.Response, error) {
+func (RyskV2Client *RyskV2APIClient) WithdrawCtx(ctx context.Context, params *types.WithdrawRequest) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "Withdraw")
+	defer span.End()
+
 	// Generate EIP712 signature.
-	signature, err := utils.SignMessage(
-		RyskV2Client.domain,
-		RyskV2Client.privateKeyString,
+	signature, action, err := RyskV2Client.signAction(
+		ctx,
 		constants.PRIMARY_TYPE_WITHDRAW,
 		&struct {
 			Account      string `json:"account"`
@@ -417,7 +674,8 @@ func (RyskV2Client *RyskV2APIClient) Withdraw(params *types.WithdrawRequest) (*h
 	}
 
 	// Create HTTP request.
-	request, err := utils.CreateHTTPRequestWithBody(
+	request, err := utils.CreateHTTPRequestWithBodyCtx(
+		ctx,
 		http.MethodPost,
 		string(RyskV2Client.baseUrl)+string(constants.API_ENDPOINT_WITHDRAW),
 		&struct {
@@ -440,24 +698,34 @@ func (RyskV2Client *RyskV2APIClient) Withdraw(params *types.WithdrawRequest) (*h
 		return nil, err
 	}
 
-	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	// Send HTTP request, recording the action, and return result.
+	return RyskV2Client.sendAction(action, request)
 }
 
 // NewOrder creates a new order on the SubAccount.
 //
+// It calls `NewOrderCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) NewOrder(params *types.NewOrderRequest) (*http.Response, error) {
+	return RyskV2Client.NewOrderCtx(context.Background(), params)
+}
+
+// NewOrderCtx creates a new order on the SubAccount.
+//
 // Params:
+//   - ctx: Context bounding the signing of the order and its HTTP request.
 //   - params: An instance of types.NewOrderRequest containing the order parameters,
 //     including the order type (limit/market), quantity, side (buy/sell), price, and symbol.
 //
 // Returns:
 //   - A pointer to an http.Response containing the response from the API call.
 //   - An error if the API call fails or if the response is not as expected.
-func (RyskV2Client *RyskV2APIClient) NewOrder(params *types.NewOrderRequest) (*http.Response, error) {
+func (RyskV2Client *RyskV2APIClient) NewOrderCtx(ctx context.Context, params *types.NewOrderRequest) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "NewOrder", tracing.Product(params.Product), tracing.OrderType(params.OrderType))
+	defer span.End()
+
 	// Generate EIP712 signature.
-	signature, err := utils.SignMessage(
-		RyskV2Client.domain,
-		RyskV2Client.privateKeyString,
+	signature, action, err := RyskV2Client.signAction(
+		ctx,
 		constants.PRIMARY_TYPE_ORDER,
 		&struct {
 			Account      string `json:"account"`
@@ -488,7 +756,8 @@ func (RyskV2Client *RyskV2APIClient) NewOrder(params *types.NewOrderRequest) (*h
 	}
 
 	// Create HTTP request.
-	request, err := utils.CreateHTTPRequestWithBody(
+	request, err := utils.CreateHTTPRequestWithBodyCtx(
+		ctx,
 		http.MethodPost,
 		string(RyskV2Client.baseUrl)+string(constants.API_ENDPOINT_NEW_ORDER),
 		&struct {
@@ -521,24 +790,34 @@ func (RyskV2Client *RyskV2APIClient) NewOrder(params *types.NewOrderRequest) (*h
 		return nil, err
 	}
 
-	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	// Send HTTP request, recording the action, and return result.
+	return RyskV2Client.sendAction(action, request)
 }
 
 // CancelOrderAndReplace cancels an order and creates a new order on the SubAccount.
 //
+// It calls `CancelOrderAndReplaceCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) CancelOrderAndReplace(params *types.CancelOrderAndReplaceRequest) (*http.Response, error) {
+	return RyskV2Client.CancelOrderAndReplaceCtx(context.Background(), params)
+}
+
+// CancelOrderAndReplaceCtx cancels an order and creates a new order on the SubAccount.
+//
 // Params:
+//   - ctx: Context bounding the signing of the replacement order and the HTTP request.
 //   - params: An instance of types.CancelOrderAndReplaceRequest containing the necessary
 //     parameters to identify the order to cancel and the new order parameters.
 //
 // Returns:
 //   - A pointer to an http.Response containing the response from the API call.
 //   - An error if the API call fails or if the response is not as expected.
-func (RyskV2Client *RyskV2APIClient) CancelOrderAndReplace(params *types.CancelOrderAndReplaceRequest) (*http.Response, error) {
+func (RyskV2Client *RyskV2APIClient) CancelOrderAndReplaceCtx(ctx context.Context, params *types.CancelOrderAndReplaceRequest) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "CancelOrderAndReplace", tracing.Product(params.NewOrder.Product), tracing.OrderType(params.NewOrder.OrderType))
+	defer span.End()
+
 	// Generate EIP712 signature.
-	signature, err := utils.SignMessage(
-		RyskV2Client.domain,
-		RyskV2Client.privateKeyString,
+	signature, action, err := RyskV2Client.signAction(
+		ctx,
 		constants.PRIMARY_TYPE_ORDER,
 		&struct {
 			Account      string `json:"account"`
@@ -569,7 +848,8 @@ func (RyskV2Client *RyskV2APIClient) CancelOrderAndReplace(params *types.CancelO
 	}
 
 	// Create HTTP request.
-	request, err := utils.CreateHTTPRequestWithBody(
+	request, err := utils.CreateHTTPRequestWithBodyCtx(
+		ctx,
 		http.MethodPost,
 		string(RyskV2Client.baseUrl)+string(constants.API_ENDPOINT_CANCEL_REPLACE_ORDER),
 		&struct {
@@ -608,24 +888,34 @@ func (RyskV2Client *RyskV2APIClient) CancelOrderAndReplace(params *types.CancelO
 		return nil, err
 	}
 
-	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	// Send HTTP request, recording the action, and return result.
+	return RyskV2Client.sendAction(action, request)
 }
 
 // CancelOrder cancels an active order on the SubAccount.
 //
+// It calls `CancelOrderCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) CancelOrder(params *types.CancelOrderRequest) (*http.Response, error) {
+	return RyskV2Client.CancelOrderCtx(context.Background(), params)
+}
+
+// CancelOrderCtx cancels an active order on the SubAccount.
+//
 // Params:
+//   - ctx: Context bounding the signing of the cancellation and its HTTP request.
 //   - params: An instance of types.CancelOrderRequest containing the necessary
 //     parameters to identify the order to cancel.
 //
 // Returns:
 //   - A pointer to an http.Response containing the response from the API call.
 //   - An error if the API call fails or if the response is not as expected.
-func (RyskV2Client *RyskV2APIClient) CancelOrder(params *types.CancelOrderRequest) (*http.Response, error) {
+func (RyskV2Client *RyskV2APIClient) CancelOrderCtx(ctx context.Context, params *types.CancelOrderRequest) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "CancelOrder", tracing.Product(params.Product))
+	defer span.End()
+
 	// Generate EIP712 signature.
-	signature, err := utils.SignMessage(
-		RyskV2Client.domain,
-		RyskV2Client.privateKeyString,
+	signature, action, err := RyskV2Client.signAction(
+		ctx,
 		constants.PRIMARY_TYPE_CANCEL_ORDER,
 		&struct {
 			Account      string `json:"account"`
@@ -644,7 +934,8 @@ func (RyskV2Client *RyskV2APIClient) CancelOrder(params *types.CancelOrderReques
 	}
 
 	// Create HTTP request.
-	request, err := utils.CreateHTTPRequestWithBody(
+	request, err := utils.CreateHTTPRequestWithBodyCtx(
+		ctx,
 		http.MethodDelete,
 		string(RyskV2Client.baseUrl)+string(constants.API_ENDPOINT_CANCEL_ORDER),
 		&struct {
@@ -665,23 +956,33 @@ func (RyskV2Client *RyskV2APIClient) CancelOrder(params *types.CancelOrderReques
 		return nil, err
 	}
 
-	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	// Send HTTP request, recording the action, and return result.
+	return RyskV2Client.sendAction(action, request)
 }
 
 // CancelAllOpenOrders cancels all active orders on a specific product for the SubAccount.
 //
+// It calls `CancelAllOpenOrdersCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) CancelAllOpenOrders(product *types.Product) (*http.Response, error) {
+	return RyskV2Client.CancelAllOpenOrdersCtx(context.Background(), product)
+}
+
+// CancelAllOpenOrdersCtx cancels all active orders on a specific product for the SubAccount.
+//
 // Params:
+//   - ctx: Context bounding the signing of the cancellation and its HTTP request.
 //   - product: The product for which all active orders should be canceled.
 //
 // Returns:
 //   - A pointer to an http.Response containing the response from the API call.
 //   - An error if the API call fails or if the response is not as expected.
-func (RyskV2Client *RyskV2APIClient) CancelAllOpenOrders(product *types.Product) (*http.Response, error) {
+func (RyskV2Client *RyskV2APIClient) CancelAllOpenOrdersCtx(ctx context.Context, product *types.Product) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "CancelAllOpenOrders", tracing.Product(product))
+	defer span.End()
+
 	// Generate EIP712 signature.
-	signature, err := utils.SignMessage(
-		RyskV2Client.domain,
-		RyskV2Client.privateKeyString,
+	signature, action, err := RyskV2Client.signAction(
+		ctx,
 		constants.PRIMARY_TYPE_CANCEL_ORDERS,
 		&struct {
 			Account      string `json:"account"`
@@ -698,7 +999,8 @@ func (RyskV2Client *RyskV2APIClient) CancelAllOpenOrders(product *types.Product)
 	}
 
 	// Create HTTP request.
-	request, err := utils.CreateHTTPRequestWithBody(
+	request, err := utils.CreateHTTPRequestWithBodyCtx(
+		ctx,
 		http.MethodDelete,
 		string(RyskV2Client.baseUrl)+string(constants.API_ENDPOINT_CANCEL_ALL_OPEN_ORDERS),
 		&struct {
@@ -717,20 +1019,83 @@ func (RyskV2Client *RyskV2APIClient) CancelAllOpenOrders(product *types.Product)
 		return nil, err
 	}
 
-	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	// Send HTTP request, recording the action, and return result.
+	return RyskV2Client.sendAction(action, request)
+}
+
+// SubmitTypedData submits an action whose typed data, as built by `typed_data.Builder`, was signed externally.
+//
+// It calls `SubmitTypedDataCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) SubmitTypedData(typedData *apitypes.TypedData, signature string) (*http.Response, error) {
+	return RyskV2Client.SubmitTypedDataCtx(context.Background(), typedData, signature)
+}
+
+// SubmitTypedDataCtx submits an action whose typed data, as built by `typed_data.Builder`, was signed externally, e.g.
+// by a hardware wallet through `eth_signTypedData_v4`. Orders, cancels, cancels of all open orders, signer approvals
+// and revocations, withdrawals and referrals are sent to their endpoint for the account of the typed data, which may
+// differ from the client account; logins are only submitted by the websocket client.
+//
+// Params:
+//   - ctx: Context bounding the HTTP request, the typed data being signed already.
+//   - typedData: The typed data of the action, in the domain of the client.
+//   - signature: The external signature of the typed data in hexadecimal format.
+//
+// Returns:
+//   - A pointer to an http.Response containing the response from the API call.
+//   - An error if the action is not supported, the typed data or signature is invalid, or the API call fails.
+func (RyskV2Client *RyskV2APIClient) SubmitTypedDataCtx(ctx context.Context, typedData *apitypes.TypedData, signature string) (*http.Response, error) {
+	primaryType := types.PrimaryType(typedData.PrimaryType)
+	ctx, span := RyskV2Client.startSpan(ctx, "SubmitTypedData", tracing.PrimaryType(primaryType))
+	defer span.End()
+
+	// Rebuild request params from typed data.
+	endpoint, ok := typedDataEndpoints[primaryType]
+	if !ok {
+		return nil, fmt.Errorf("primary type %q cannot be submitted over REST", typedData.PrimaryType)
+	}
+	params, err := typed_data.Params(RyskV2Client.domain, typedData, signature)
+	if err != nil {
+		return nil, err
+	}
+	var action *audit.Action
+	if RyskV2Client.auditLog != nil {
+		if action, err = audit.NewAction(RyskV2Client.domain, primaryType, typedData.Message, signature); err != nil {
+			return nil, err
+		}
+	}
+
+	// Create HTTP request.
+	request, err := utils.CreateHTTPRequestWithBodyCtx(ctx, endpoint.method, string(RyskV2Client.baseUrl)+string(endpoint.path), params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send HTTP request, recording the action, and return result.
+	return RyskV2Client.sendAction(action, request)
 }
 
 // GetSpotBalances retrieves spot balances for the SubAccount.
 //
+// It calls `GetSpotBalancesCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) GetSpotBalances() (*http.Response, error) {
+	return RyskV2Client.GetSpotBalancesCtx(context.Background())
+}
+
+// GetSpotBalancesCtx retrieves spot balances for the SubAccount.
+//
+// Parameters:
+//   - ctx: Context bounding the signing of the authentication and the HTTP request for the balances.
+//
 // Returns:
 //   - A pointer to an http.Response containing the response from the API call.
 //   - An error if the API call fails or if the response is not as expected.
-func (RyskV2Client *RyskV2APIClient) GetSpotBalances() (*http.Response, error) {
+func (RyskV2Client *RyskV2APIClient) GetSpotBalancesCtx(ctx context.Context) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "GetSpotBalances")
+	defer span.End()
+
 	// Generate EIP712 signature.
-	signature, err := utils.SignMessage(
-		RyskV2Client.domain,
-		RyskV2Client.privateKeyString,
+	signature, err := RyskV2Client.signMessage(
+		ctx,
 		constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION,
 		&struct {
 			Account      string `json:"account"`
@@ -745,7 +1110,8 @@ func (RyskV2Client *RyskV2APIClient) GetSpotBalances() (*http.Response, error) {
 	}
 
 	// Create HTTP request.
-	request, err := http.NewRequest(
+	request, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		string(RyskV2Client.baseUrl)+string(constants.API_ENDPOINT_GET_SPOT_BALANCES),
 		nil,
@@ -762,22 +1128,32 @@ func (RyskV2Client *RyskV2APIClient) GetSpotBalances() (*http.Response, error) {
 	request.URL.RawQuery = query.Encode()
 
 	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	return utils.SendHTTPRequest(RyskV2Client.httpClient, request)
 }
 
 // GetPerpetualPosition retrieves the perpetual position for a specific product and SubAccount.
 //
+// It calls `GetPerpetualPositionCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) GetPerpetualPosition(product *types.Product) (*http.Response, error) {
+	return RyskV2Client.GetPerpetualPositionCtx(context.Background(), product)
+}
+
+// GetPerpetualPositionCtx retrieves the perpetual position for a specific product and SubAccount.
+//
 // Parameters:
+//   - ctx: Context bounding the signing of the authentication and the HTTP request for the position.
 //   - product: The product for which the perpetual position is requested.
 //
 // Returns:
 //   - A pointer to an http.Response containing the response from the API call.
 //   - An error if the API call fails or if the response is not as expected.
-func (RyskV2Client *RyskV2APIClient) GetPerpetualPosition(product *types.Product) (*http.Response, error) {
+func (RyskV2Client *RyskV2APIClient) GetPerpetualPositionCtx(ctx context.Context, product *types.Product) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "GetPerpetualPosition", tracing.Product(product))
+	defer span.End()
+
 	// Generate EIP712 signature.
-	signature, err := utils.SignMessage(
-		RyskV2Client.domain,
-		RyskV2Client.privateKeyString,
+	signature, err := RyskV2Client.signMessage(
+		ctx,
 		constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION,
 		&struct {
 			Account      string `json:"account"`
@@ -792,7 +1168,8 @@ func (RyskV2Client *RyskV2APIClient) GetPerpetualPosition(product *types.Product
 	}
 
 	// Create HTTP request.
-	request, err := http.NewRequest(
+	request, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		string(RyskV2Client.baseUrl)+string(constants.API_ENDPOINT_GET_PERPETUAL_POSITION),
 		nil,
@@ -810,19 +1187,31 @@ func (RyskV2Client *RyskV2APIClient) GetPerpetualPosition(product *types.Product
 	request.URL.RawQuery = query.Encode()
 
 	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	return utils.SendHTTPRequest(RyskV2Client.httpClient, request)
 }
 
 // GetPerpetualPositionAllProducts retrieves the perpetual position for all products for a SubAccount.
 //
+// It calls `GetPerpetualPositionAllProductsCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) GetPerpetualPositionAllProducts() (*http.Response, error) {
+	return RyskV2Client.GetPerpetualPositionAllProductsCtx(context.Background())
+}
+
+// GetPerpetualPositionAllProductsCtx retrieves the perpetual position for all products for a SubAccount.
+//
+// Parameters:
+//   - ctx: Context bounding the signing of the authentication and the HTTP request for the positions.
+//
 // Returns:
 //   - A pointer to an http.Response containing the response from the API call.
 //   - An error if the API call fails or if the response is not as expected.
-func (RyskV2Client *RyskV2APIClient) GetPerpetualPositionAllProducts() (*http.Response, error) {
+func (RyskV2Client *RyskV2APIClient) GetPerpetualPositionAllProductsCtx(ctx context.Context) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "GetPerpetualPositionAllProducts")
+	defer span.End()
+
 	// Generate EIP712 signature.
-	signature, err := utils.SignMessage(
-		RyskV2Client.domain,
-		RyskV2Client.privateKeyString,
+	signature, err := RyskV2Client.signMessage(
+		ctx,
 		constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION,
 		&struct {
 			Account      string `json:"account"`
@@ -837,7 +1226,8 @@ func (RyskV2Client *RyskV2APIClient) GetPerpetualPositionAllProducts() (*http.Re
 	}
 
 	// Create HTTP request.
-	request, err := http.NewRequest(
+	request, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		string(RyskV2Client.baseUrl)+string(constants.API_ENDPOINT_GET_PERPETUAL_POSITION),
 		nil,
@@ -854,19 +1244,31 @@ func (RyskV2Client *RyskV2APIClient) GetPerpetualPositionAllProducts() (*http.Re
 	request.URL.RawQuery = query.Encode()
 
 	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	return utils.SendHTTPRequest(RyskV2Client.httpClient, request)
 }
 
 // ListApprovedSigners retrieves a list of all approved signers for a specific `SubAccount`.
 //
+// It calls `ListApprovedSignersCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) ListApprovedSigners() (*http.Response, error) {
+	return RyskV2Client.ListApprovedSignersCtx(context.Background())
+}
+
+// ListApprovedSignersCtx retrieves a list of all approved signers for a specific `SubAccount`.
+//
+// Parameters:
+//   - ctx: Context bounding the signing of the authentication and the HTTP request for the signers.
+//
 // Returns:
 //   - A pointer to an http.Response containing the response from the API call.
 //   - An error if the API call fails or if the response is not as expected.
-func (RyskV2Client *RyskV2APIClient) ListApprovedSigners() (*http.Response, error) {
+func (RyskV2Client *RyskV2APIClient) ListApprovedSignersCtx(ctx context.Context) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "ListApprovedSigners")
+	defer span.End()
+
 	// Generate EIP712 signature.
-	signature, err := utils.SignMessage(
-		RyskV2Client.domain,
-		RyskV2Client.privateKeyString,
+	signature, err := RyskV2Client.signMessage(
+		ctx,
 		constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION,
 		&struct {
 			Account      string `json:"account"`
@@ -881,7 +1283,8 @@ func (RyskV2Client *RyskV2APIClient) ListApprovedSigners() (*http.Response, erro
 	}
 
 	// Create HTTP request.
-	request, err := http.NewRequest(
+	request, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		string(RyskV2Client.baseUrl)+string(constants.API_ENDPOINT_LIST_APPROVED_SIGNERS),
 		nil,
@@ -898,22 +1301,32 @@ func (RyskV2Client *RyskV2APIClient) ListApprovedSigners() (*http.Response, erro
 	request.URL.RawQuery = query.Encode()
 
 	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	return utils.SendHTTPRequest(RyskV2Client.httpClient, request)
 }
 
 // ListOpenOrders retrieves all open orders on the `SubAccount` for a specific product.
 //
+// It calls `ListOpenOrdersCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) ListOpenOrders(product *types.Product) (*http.Response, error) {
+	return RyskV2Client.ListOpenOrdersCtx(context.Background(), product)
+}
+
+// ListOpenOrdersCtx retrieves all open orders on the `SubAccount` for a specific product.
+//
 // Parameters:
+//   - ctx: Context bounding the signing of the authentication and the HTTP request for the orders.
 //   - product: A pointer to a `types.Product` struct representing the product for which open orders are to be retrieved.
 //
 // Returns:
 //   - A pointer to an http.Response containing the response from the API call.
 //   - An error if the API call fails or if the response is not as expected.
-func (RyskV2Client *RyskV2APIClient) ListOpenOrders(product *types.Product) (*http.Response, error) {
+func (RyskV2Client *RyskV2APIClient) ListOpenOrdersCtx(ctx context.Context, product *types.Product) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "ListOpenOrders", tracing.Product(product))
+	defer span.End()
+
 	// Generate EIP712 signature.
-	signature, err := utils.SignMessage(
-		RyskV2Client.domain,
-		RyskV2Client.privateKeyString,
+	signature, err := RyskV2Client.signMessage(
+		ctx,
 		constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION,
 		&struct {
 			Account      string `json:"account"`
@@ -928,7 +1341,8 @@ func (RyskV2Client *RyskV2APIClient) ListOpenOrders(product *types.Product) (*ht
 	}
 
 	// Create HTTP request.
-	request, err := http.NewRequest(
+	request, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		string(RyskV2Client.baseUrl)+string(constants.API_ENDPOINT_LIST_OPEN_ORDERS),
 		nil,
@@ -946,19 +1360,31 @@ func (RyskV2Client *RyskV2APIClient) ListOpenOrders(product *types.Product) (*ht
 	request.URL.RawQuery = query.Encode()
 
 	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	return utils.SendHTTPRequest(RyskV2Client.httpClient, request)
 }
 
 // ListOpenOrdersAllProducts retrieves all open orders on the `SubAccount` for a all products.
 //
+// It calls `ListOpenOrdersAllProductsCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) ListOpenOrdersAllProducts() (*http.Response, error) {
+	return RyskV2Client.ListOpenOrdersAllProductsCtx(context.Background())
+}
+
+// ListOpenOrdersAllProductsCtx retrieves all open orders on the `SubAccount` for a all products.
+//
+// Parameters:
+//   - ctx: Context bounding the signing of the authentication and the HTTP request for the orders.
+//
 // Returns:
 //   - A pointer to an http.Response containing the response from the API call.
 //   - An error if the API call fails or if the response is not as expected.
-func (RyskV2Client *RyskV2APIClient) ListOpenOrdersAllProducts() (*http.Response, error) {
+func (RyskV2Client *RyskV2APIClient) ListOpenOrdersAllProductsCtx(ctx context.Context) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "ListOpenOrdersAllProducts")
+	defer span.End()
+
 	// Generate EIP712 signature.
-	signature, err := utils.SignMessage(
-		RyskV2Client.domain,
-		RyskV2Client.privateKeyString,
+	signature, err := RyskV2Client.signMessage(
+		ctx,
 		constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION,
 		&struct {
 			Account      string `json:"account"`
@@ -973,7 +1399,8 @@ func (RyskV2Client *RyskV2APIClient) ListOpenOrdersAllProducts() (*http.Response
 	}
 
 	// Create HTTP request.
-	request, err := http.NewRequest(
+	request, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		string(RyskV2Client.baseUrl)+string(constants.API_ENDPOINT_LIST_OPEN_ORDERS),
 		nil,
@@ -990,22 +1417,32 @@ func (RyskV2Client *RyskV2APIClient) ListOpenOrdersAllProducts() (*http.Response
 	request.URL.RawQuery = query.Encode()
 
 	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	return utils.SendHTTPRequest(RyskV2Client.httpClient, request)
 }
 
 // ListOrders retrieves all orders on the `SubAccount` for a specific product.
 //
+// It calls `ListOrdersCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) ListOrders(params *types.ListOrdersRequest) (*http.Response, error) {
+	return RyskV2Client.ListOrdersCtx(context.Background(), params)
+}
+
+// ListOrdersCtx retrieves all orders on the `SubAccount` for a specific product.
+//
 // Parameters:
+//   - ctx: Context bounding the signing of the authentication and the HTTP request for the orders.
 //   - params: A pointer to a `types.ListOrdersRequest` struct containing parameters for listing orders.
 //
 // Returns:
 //   - A pointer to an http.Response containing the response from the API call.
 //   - An error if the API call fails or if the response is not as expected.
-func (RyskV2Client *RyskV2APIClient) ListOrders(params *types.ListOrdersRequest) (*http.Response, error) {
+func (RyskV2Client *RyskV2APIClient) ListOrdersCtx(ctx context.Context, params *types.ListOrdersRequest) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "ListOrders", tracing.Product(params.Product))
+	defer span.End()
+
 	// Generate EIP712 signature.
-	signature, err := utils.SignMessage(
-		RyskV2Client.domain,
-		RyskV2Client.privateKeyString,
+	signature, err := RyskV2Client.signMessage(
+		ctx,
 		constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION,
 		&struct {
 			Account      string `json:"account"`
@@ -1020,7 +1457,8 @@ func (RyskV2Client *RyskV2APIClient) ListOrders(params *types.ListOrdersRequest)
 	}
 
 	// Create HTTP request.
-	request, err := http.NewRequest(
+	request, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		string(RyskV2Client.baseUrl)+string(constants.API_ENDPOINT_LIST_ORDERS),
 		nil,
@@ -1041,22 +1479,32 @@ func (RyskV2Client *RyskV2APIClient) ListOrders(params *types.ListOrdersRequest)
 	request.URL.RawQuery = query.Encode()
 
 	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	return utils.SendHTTPRequest(RyskV2Client.httpClient, request)
 }
 
-// ListOrders retrieves all orders on the `SubAccount` for a specific product.
+// ListOrdersAllProducts retrieves the orders with the given IDs on the `SubAccount` across all products.
+//
+// It calls `ListOrdersAllProductsCtx` with a background context.
+func (RyskV2Client *RyskV2APIClient) ListOrdersAllProducts(ids []string) (*http.Response, error) {
+	return RyskV2Client.ListOrdersAllProductsCtx(context.Background(), ids)
+}
+
+// ListOrdersAllProductsCtx retrieves the orders with the given IDs on the `SubAccount` across all products.
 //
 // Parameters:
-//   - params: A pointer to a `types.ListOrdersRequest` struct containing parameters for listing orders.
+//   - ctx: Context bounding the signing of the authentication and the HTTP request for the orders.
+//   - ids: The IDs of the orders to retrieve.
 //
 // Returns:
 //   - A pointer to an http.Response containing the response from the API call.
 //   - An error if the API call fails or if the response is not as expected.
-func (RyskV2Client *RyskV2APIClient) ListOrdersAllProducts(ids []string) (*http.Response, error) {
+func (RyskV2Client *RyskV2APIClient) ListOrdersAllProductsCtx(ctx context.Context, ids []string) (*http.Response, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "ListOrdersAllProducts")
+	defer span.End()
+
 	// Generate EIP712 signature.
-	signature, err := utils.SignMessage(
-		RyskV2Client.domain,
-		RyskV2Client.privateKeyString,
+	signature, err := RyskV2Client.signMessage(
+		ctx,
 		constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION,
 		&struct {
 			Account      string `json:"account"`
@@ -1071,7 +1519,8 @@ func (RyskV2Client *RyskV2APIClient) ListOrdersAllProducts(ids []string) (*http.
 	}
 
 	// Create HTTP request.
-	request, err := http.NewRequest(
+	request, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		string(RyskV2Client.baseUrl)+string(constants.API_ENDPOINT_LIST_ORDERS),
 		nil,
@@ -1091,7 +1540,7 @@ func (RyskV2Client *RyskV2APIClient) ListOrdersAllProducts(ids []string) (*http.
 	request.URL.RawQuery = query.Encode()
 
 	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	return utils.SendHTTPRequest(RyskV2Client.httpClient, request)
 }
 
 // ApproveUSDC approves Rysk V2 to spend USDC on your behalf.
@@ -1104,34 +1553,14 @@ func (RyskV2Client *RyskV2APIClient) ListOrdersAllProducts(ids []string) (*http.
 //   - A pointer to a geth_types.Transaction representing the Ethereum transaction.
 //   - An error if the Ethereum transaction fails or encounters an issue.
 func (RyskV2Client *RyskV2APIClient) ApproveUSDC(ctx context.Context, amount *big.Int) (*geth_types.Transaction, error) {
-	// Parse ABI
-	parsedABI, _ := abi.JSON(strings.NewReader(constants.ERC20_ABI))
-
-	// Pack transaction data
-	data, _ := parsedABI.Pack("approve", RyskV2Client.ciao, amount)
-
-	// Get transaction parameters
-	nonce, gasPrice, chainID, gasLimit, err := utils.GetTransactionParams(ctx, RyskV2Client.EthClient, RyskV2Client.privateKey, &RyskV2Client.address, &RyskV2Client.usdb, &data)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create a new transaction
-	tx := geth_types.NewTransaction(nonce, RyskV2Client.usdb, big.NewInt(0), gasLimit, gasPrice, data)
-
-	// Sign transaction
-	signedTx, _ := geth_types.SignTx(tx, geth_types.NewEIP155Signer(chainID), RyskV2Client.privateKey)
-
-	// Send transaction
-	err = RyskV2Client.EthClient.SendTransaction(ctx, signedTx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send transaction: %v", err)
-	}
+	ctx, span := RyskV2Client.startSpan(ctx, "ApproveUSDC")
+	defer span.End()
 
-	return signedTx, nil
+	return RyskV2Client.account().ApproveUSDC(ctx, amount)
 }
 
 // DepositUSDC sends USDC to Rysk V2.
+// The CIAO contract must already be allowed to spend `amount`, see `ApproveUSDC` or `Deposit`.
 //
 // Parameters:
 //   - ctx: The context.Context for the Ethereum transaction.
@@ -1141,43 +1570,50 @@ func (RyskV2Client *RyskV2APIClient) ApproveUSDC(ctx context.Context, amount *bi
 //   - A pointer to a geth_types.Transaction representing the Ethereum transaction.
 //   - An error if the Ethereum transaction fails or encounters an issue.
 func (RyskV2Client *RyskV2APIClient) DepositUSDC(ctx context.Context, amount *big.Int) (*geth_types.Transaction, error) {
-	// Approve self as signer
-	_, err := RyskV2Client.ApproveSigner(&types.ApproveRevokeSignerRequest{
-		ApprovedSigner: RyskV2Client.addressString,
-		Nonce:          time.Now().UnixMicro(),
-	})
-	if err != nil {
-		return nil, err
-	}
+	ctx, span := RyskV2Client.startSpan(ctx, "DepositUSDC")
+	defer span.End()
 
-	// Parse ABI
-	parsedABI, _ := abi.JSON(strings.NewReader(constants.CIAO_ABI))
-
-	// Pack transaction data
-	data, _ := parsedABI.Pack("deposit", RyskV2Client.address, uint8(RyskV2Client.SubAccountId), amount, RyskV2Client.usdb)
-
-	// Get transaction parameters
-	nonce, gasPrice, chainID, gasLimit, err := utils.GetTransactionParams(ctx, RyskV2Client.EthClient, RyskV2Client.privateKey, &RyskV2Client.address, &RyskV2Client.ciao, &data)
-	if err != nil {
-		return nil, err
-	}
+	return RyskV2Client.account().DepositUSDC(ctx, amount)
+}
 
-	// Create a new transaction
-	tx := geth_types.NewTransaction(nonce, RyskV2Client.ciao, big.NewInt(0), gasLimit, gasPrice, data)
+// Deposit deposits USDC to Rysk V2, approving the CIAO contract first only when needed.
+//
+// The workflow checks the USDC balance and the CIAO minimum deposit amount, approves
+// the missing allowance according to `ApprovalMode`, deposits and waits for each receipt.
+//
+// Parameters:
+//   - ctx: The context.Context for the Ethereum transactions.
+//   - amount: The amount of USDC tokens to deposit, specified as a *big.Int.
+//
+// Returns:
+//   - A pointer to a types.DepositResult holding the transactions and receipts.
+//   - An error if a check fails or a transaction fails or reverts, CIAO reverts match `utils.ErrBalanceInsufficient` and friends with `errors.Is`.
+func (RyskV2Client *RyskV2APIClient) Deposit(ctx context.Context, amount *big.Int) (*types.DepositResult, error) {
+	ctx, span := RyskV2Client.startSpan(ctx, "Deposit")
+	defer span.End()
 
-	// Sign transaction
-	signedTx, _ := geth_types.SignTx(tx, geth_types.NewEIP155Signer(chainID), RyskV2Client.privateKey)
+	return RyskV2Client.account().Deposit(ctx, RyskV2Client, amount)
+}
 
-	// Send transaction
-	err = RyskV2Client.EthClient.SendTransaction(ctx, signedTx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send transaction: %v", err)
+// account returns the on-chain account of the client, from its current settings.
+func (RyskV2Client *RyskV2APIClient) account() *tx_manager.Account {
+	return &tx_manager.Account{
+		EthClient:          RyskV2Client.EthClient,
+		PrivateKey:         RyskV2Client.privateKey,
+		Address:            RyskV2Client.address,
+		SubAccountId:       RyskV2Client.SubAccountId,
+		CIAO:               RyskV2Client.ciao,
+		USDC:               RyskV2Client.usdb,
+		GasConfiguration:   RyskV2Client.GasConfiguration,
+		TransactionManager: RyskV2Client.TransactionManager,
+		ApprovalMode:       RyskV2Client.ApprovalMode,
+		Metrics:            RyskV2Client.metrics,
+		Logger:             RyskV2Client.logger,
 	}
-
-	return signedTx, nil
 }
 
 // WaitTransaction waits for a transaction to be mined and returns its receipt.
+// With a transaction manager, it waits for the configured confirmations and follows replacements.
 //
 // Parameters:
 //   - ctx: The context.Context for the Ethereum transaction.
@@ -1187,11 +1623,63 @@ func (RyskV2Client *RyskV2APIClient) DepositUSDC(ctx context.Context, amount *bi
 //   - A pointer to a geth_types.Receipt containing the transaction receipt once the transaction is mined.
 //   - An error if the transaction fails to be mined or encounters an issue.
 func (RyskV2Client *RyskV2APIClient) WaitTransaction(ctx context.Context, transaction *geth_types.Transaction) (*geth_types.Receipt, error) {
-	receipt, err := bind.WaitMined(ctx, RyskV2Client.EthClient, transaction)
+	ctx, span := RyskV2Client.startSpan(ctx, "WaitTransaction")
+	defer span.End()
+
+	return RyskV2Client.account().WaitTransaction(ctx, transaction)
+}
+
+// log returns the logger of the client, discarding records when not configured.
+func (RyskV2Client *RyskV2APIClient) log() *slog.Logger {
+	return logging.OrDiscard(RyskV2Client.logger)
+}
+
+// signMessage signs an EIP-712 message of the account within a span, recording the signing latency.
+func (RyskV2Client *RyskV2APIClient) signMessage(ctx context.Context, primaryType types.PrimaryType, message interface{}) (string, error) {
+	ctx, span := RyskV2Client.tracer.Start(ctx, tracing.SPAN_SIGN, tracing.PrimaryType(primaryType))
+	start := time.Now()
+	signature, err := utils.SignMessageCtx(ctx, RyskV2Client.domain, RyskV2Client.privateKeyString, primaryType, message)
+	if err == nil {
+		RyskV2Client.metrics.ObserveSigning(primaryType, time.Since(start))
+	}
+	tracing.End(span, err)
+	return signature, err
+}
+
+// signAction signs the EIP-712 message of an action like `signMessage`, returning the action to record when auditing.
+func (RyskV2Client *RyskV2APIClient) signAction(ctx context.Context, primaryType types.PrimaryType, message interface{}) (string, *audit.Action, error) {
+	signature, err := RyskV2Client.signMessage(ctx, primaryType, message)
+	if err != nil || RyskV2Client.auditLog == nil {
+		return signature, nil, err
+	}
+	action, err := audit.NewAction(RyskV2Client.domain, primaryType, message, signature)
+	if err != nil {
+		return "", nil, err
+	}
+	return signature, action, nil
+}
+
+// sendAction sends the request of a signed action, recording the action before sending it and its response when
+// auditing. The action is not sent when it cannot be recorded.
+func (RyskV2Client *RyskV2APIClient) sendAction(action *audit.Action, request *http.Request) (*http.Response, error) {
+	if action == nil {
+		return utils.SendHTTPRequest(RyskV2Client.httpClient, request)
+	}
+	sequence, err := RyskV2Client.auditLog.Request(action, request)
 	if err != nil {
 		return nil, err
 	}
-	return receipt, nil
+	res, err := utils.SendHTTPRequest(RyskV2Client.httpClient, request)
+	if auditErr := RyskV2Client.auditLog.Response(sequence, request, res, err); auditErr != nil {
+		RyskV2Client.log().ErrorContext(request.Context(), "audit log failed", "sequence", sequence, "error", auditErr)
+	}
+	return res, err
+}
+
+// startSpan starts the span of a client method, recording the sub-account.
+func (RyskV2Client *RyskV2APIClient) startSpan(ctx context.Context, method string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	attributes = append(attributes, tracing.SubAccountId(RyskV2Client.SubAccountId))
+	return RyskV2Client.tracer.Start(ctx, "RyskV2APIClient."+method, attributes...)
 }
 
 // addReferee adds a referee to author referral code.
@@ -1236,5 +1724,5 @@ func (RyskV2Client *RyskV2APIClient) addReferee() (*http.Response, error) {
 	}
 
 	// Send HTTP request and return result.
-	return utils.SendHTTPRequest(RyskV2Client.HttpClient, request)
+	return utils.SendHTTPRequest(RyskV2Client.httpClient, request)
 }
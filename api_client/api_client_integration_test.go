@@ -4,21 +4,29 @@
 package api_client
 
 import (
+	"bytes"
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"math/big"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	geth_types "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/joho/godotenv"
 	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/ryskfake"
 	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils/mocks"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -28,9 +36,47 @@ type ApiClientIntegrationTestSuite struct {
 	PrivateKeys     string
 	RpcUrl          string
 	RyskV2ApiClient *RyskV2APIClient
+	PriceUrl        string
+	Server          *ryskfake.Server // Server is the fake exchange the suite runs against when `RYSK_FAKE` is set.
+	PriceServer     *httptest.Server // PriceServer serves a fixed ETH price when `RYSK_FAKE` is set.
 }
 
 func (s *ApiClientIntegrationTestSuite) SetupSuite() {
+	s.PriceUrl = "https://api.coinbase.com/v2/exchange-rates?currency=ETH"
+
+	// Run against a fake exchange and chain, without network, when `RYSK_FAKE` is set.
+	if os.Getenv("RYSK_FAKE") != "" {
+		server, err := ryskfake.NewServer(&ryskfake.ServerConfiguration{})
+		require.NoError(s.T(), err)
+		privateKey, err := crypto.GenerateKey()
+		require.NoError(s.T(), err)
+		apiClient, err := NewRyskV2APIClient(&RyskV2APIClientConfiguration{
+			Env:          constants.ENVIRONMENT_TESTNET,
+			PrivateKey:   hex.EncodeToString(crypto.FromECDSA(privateKey)),
+			RpcUrl:       server.URL(),
+			BaseUrl:      server.URL(),
+			SubAccountId: 1,
+		})
+		require.NoError(s.T(), err)
+		server.Credit(apiClient.Address(), 1, apiClient.USDCAddress(), new(big.Int).Mul(big.NewInt(1000), constants.E18))
+		mockEthClient := new(mocks.MockEthClient)
+		mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(0), nil)
+		mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+		mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
+		mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(421614), nil)
+		mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
+		mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+		mockEthClient.On("TransactionReceipt", mock.Anything, mock.Anything).Return(&geth_types.Receipt{Status: geth_types.ReceiptStatusSuccessful}, nil)
+		apiClient.EthClient = mockEthClient
+		s.PriceServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Write([]byte(`{"data":{"currency":"ETH","rates":{"USD":"2000.5"}}}`))
+		}))
+		s.PriceUrl = s.PriceServer.URL
+		s.Server = server
+		s.RyskV2ApiClient = apiClient
+		return
+	}
+
 	if err := godotenv.Load(); err != nil {
 		fmt.Println("Error loading .env file:", err)
 		return
@@ -44,6 +90,13 @@ func (s *ApiClientIntegrationTestSuite) SetupSuite() {
 	s.RyskV2ApiClient = apiClient
 }
 
+func (s *ApiClientIntegrationTestSuite) TearDownSuite() {
+	if s.Server != nil {
+		s.Server.Close()
+		s.PriceServer.Close()
+	}
+}
+
 func (s *ApiClientIntegrationTestSuite) SetupTest() {
 	time.Sleep(100 * time.Millisecond)
 }
@@ -221,7 +274,7 @@ func (s *ApiClientIntegrationTestSuite) TestIntegration_NewOrder() {
 	// get market price
 	request, err := http.NewRequest(
 		http.MethodGet,
-		"https://api.coinbase.com/v2/exchange-rates?currency=ETH",
+		s.PriceUrl,
 		nil,
 	)
 	require.NoError(s.T(), err)
@@ -293,7 +346,7 @@ func (s *ApiClientIntegrationTestSuite) TestIntegration_CancelOrderAndReplace()
 	// get market price
 	req, err := http.NewRequest(
 		http.MethodGet,
-		"https://api.coinbase.com/v2/exchange-rates?currency=ETH",
+		s.PriceUrl,
 		nil,
 	)
 	require.NoError(s.T(), err)
@@ -394,7 +447,7 @@ func (s *ApiClientIntegrationTestSuite) TestIntegration_CancelOrder() {
 	// get market price
 	req, err := http.NewRequest(
 		http.MethodGet,
-		"https://api.coinbase.com/v2/exchange-rates?currency=ETH",
+		s.PriceUrl,
 		nil,
 	)
 	require.NoError(s.T(), err)
@@ -603,10 +656,11 @@ func (s *ApiClientIntegrationTestSuite) TestIntegration_addReferee() {
 }
 
 func verifyValidJSONResponse(t *testing.T, response *http.Response) {
-	// Read response
-	defer response.Body.Close()
+	// Read response, keeping the body readable by the caller
 	bytesBody, err := io.ReadAll(response.Body)
+	response.Body.Close()
 	require.NoError(t, err)
+	response.Body = io.NopCloser(bytes.NewReader(bytesBody))
 
 	// Check if res is valid JSON by trying to unmarshal it
 	var data interface{}
@@ -4,6 +4,7 @@
 package api_client
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"encoding/json"
@@ -18,10 +19,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
 	geth_types "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/joho/godotenv"
 	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/tx_manager"
 	"github.com/rysk-finance/v2_client_go/types"
 	"github.com/rysk-finance/v2_client_go/utils"
 	"github.com/rysk-finance/v2_client_go/utils/mocks"
@@ -117,6 +121,60 @@ func (s *ApiClientUnitTestSuite) TestUnit_NewRyskV2APIClient_InvalidRPCURL() {
 	require.Nil(s.T(), apiClient)
 }
 
+// countingTransport counts the requests sent, answering `503 Service Unavailable` to the first one.
+type countingTransport struct {
+	requests int
+}
+
+func (transport *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport.requests++
+	status := http.StatusOK
+	if transport.requests == 1 {
+		status = http.StatusServiceUnavailable
+	}
+	return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("{}")), Request: req}, nil
+}
+
+func (s *ApiClientUnitTestSuite) TestUnit_NewRyskV2APIClient_ReplacedHttpClient() {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(s.T(), err)
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	retries := 0
+	apiClient, err := NewRyskV2APIClient(&RyskV2APIClientConfiguration{
+		Env:        constants.ENVIRONMENT_TESTNET,
+		PrivateKey: hex.EncodeToString(crypto.FromECDSA(privateKey)),
+		RpcUrl:     server.URL,
+		BaseUrl:    server.URL,
+		Retry: &utils.RetryConfiguration{
+			InitialBackoff: time.Millisecond,
+			OnRetry: func(*http.Request, int, error, time.Duration) {
+				retries++
+			},
+		},
+	})
+	require.NoError(s.T(), err)
+
+	// A replaced HTTP client still sends through the middlewares, on sub-account copies too.
+	transport := &countingTransport{}
+	apiClient.HttpClient = &http.Client{Transport: transport}
+	res, err := apiClient.GetProduct("ETH-PERP")
+	require.NoError(s.T(), err)
+	res.Body.Close()
+	require.Equal(s.T(), 2, transport.requests)
+	require.Equal(s.T(), 1, retries)
+
+	subAccountClient := apiClient.WithSubAccount(2)
+	subAccountTransport := &countingTransport{}
+	subAccountClient.HttpClient = &http.Client{Transport: subAccountTransport}
+	res, err = subAccountClient.GetProduct("ETH-PERP")
+	require.NoError(s.T(), err)
+	res.Body.Close()
+	require.Equal(s.T(), 2, subAccountTransport.requests)
+	require.Equal(s.T(), 2, retries)
+	require.Equal(s.T(), 2, transport.requests)
+}
+
 func (s *ApiClientUnitTestSuite) TestUnit_Get24hrPriceChangeStatistics_WithBadRequest() {
 	s.RyskV2APIClient.baseUrl = "http://\t"
 
@@ -1489,7 +1547,8 @@ func (s *ApiClientUnitTestSuite) TestUnit_ApproveUSDC() {
 	mockEthClient := new(mocks.MockEthClient)
 	s.RyskV2APIClient.EthClient = mockEthClient
 	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
-	mockEthClient.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
 	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
 	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
 	mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
@@ -1503,7 +1562,8 @@ func (s *ApiClientUnitTestSuite) TestUnit_ApproveUSDC_ErrorGettingParameters() {
 	mockEthClient := new(mocks.MockEthClient)
 	s.RyskV2APIClient.EthClient = mockEthClient
 	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
-	mockEthClient.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
 	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
 	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), fmt.Errorf("error getting parameters"))
 
@@ -1512,11 +1572,68 @@ func (s *ApiClientUnitTestSuite) TestUnit_ApproveUSDC_ErrorGettingParameters() {
 	require.Nil(s.T(), transaction)
 }
 
+func (s *ApiClientUnitTestSuite) TestUnit_ApproveUSDC_DynamicFeeTransaction() {
+	mockEthClient := new(mocks.MockEthClient)
+	s.RyskV2APIClient.EthClient = mockEthClient
+	s.RyskV2APIClient.GasConfiguration = &types.GasConfiguration{GasLimitBuffer: 20}
+	defer func() { s.RyskV2APIClient.GasConfiguration = nil }()
+	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(100), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000)}, nil)
+	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
+	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
+	mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+
+	transaction, err := s.RyskV2APIClient.ApproveUSDC(context.Background(), big.NewInt(1000))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), uint8(geth_types.DynamicFeeTxType), transaction.Type())
+	require.Equal(s.T(), big.NewInt(100), transaction.GasTipCap())
+	require.Equal(s.T(), big.NewInt(2100), transaction.GasFeeCap())
+	require.Equal(s.T(), uint64(25200), transaction.Gas())
+}
+
+func (s *ApiClientUnitTestSuite) TestUnit_ApproveUSDC_TransactionManager() {
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(7), nil).Once()
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(100), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000)}, nil)
+	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
+	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
+	mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+	transactionManager, err := tx_manager.NewTransactionManager(&tx_manager.TransactionManagerConfiguration{
+		EthClient:  mockEthClient,
+		PrivateKey: s.PrivateKey,
+	})
+	require.NoError(s.T(), err)
+	s.RyskV2APIClient.TransactionManager = transactionManager
+	defer func() { s.RyskV2APIClient.TransactionManager = nil }()
+
+	firstTransaction, err := s.RyskV2APIClient.ApproveUSDC(context.Background(), big.NewInt(1000))
+	require.NoError(s.T(), err)
+	secondTransaction, err := s.RyskV2APIClient.ApproveUSDC(context.Background(), big.NewInt(1000))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), uint64(7), firstTransaction.Nonce())
+	require.Equal(s.T(), uint64(8), secondTransaction.Nonce())
+	mockEthClient.AssertNumberOfCalls(s.T(), "PendingNonceAt", 1)
+}
+
+func (s *ApiClientUnitTestSuite) TestUnit_ApproveUSDC_ErrorGasFees() {
+	mockEthClient := new(mocks.MockEthClient)
+	s.RyskV2APIClient.EthClient = mockEthClient
+	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return((*big.Int)(nil), fmt.Errorf("failed to get gas tip cap"))
+
+	transaction, err := s.RyskV2APIClient.ApproveUSDC(context.Background(), big.NewInt(1000))
+	require.Error(s.T(), err)
+	require.Nil(s.T(), transaction)
+}
+
 func (s *ApiClientUnitTestSuite) TestUnit_ApproveUSDC_ErrorSendTransaction() {
 	mockEthClient := new(mocks.MockEthClient)
 	s.RyskV2APIClient.EthClient = mockEthClient
 	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
-	mockEthClient.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
 	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
 	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
 	mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(fmt.Errorf("failed to send transaction"))
@@ -1530,7 +1647,8 @@ func (s *ApiClientUnitTestSuite) TestUnit_DepositUSDC() {
 	mockEthClient := new(mocks.MockEthClient)
 	s.RyskV2APIClient.EthClient = mockEthClient
 	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
-	mockEthClient.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
 	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
 	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
 	mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
@@ -1540,18 +1658,12 @@ func (s *ApiClientUnitTestSuite) TestUnit_DepositUSDC() {
 	require.NotNil(s.T(), transaction)
 }
 
-func (s *ApiClientUnitTestSuite) TestUnit_DepositUSDC_ErrorApproveSigner() {
-	s.RyskV2APIClient.addressString = ""
-	transaction, err := s.RyskV2APIClient.DepositUSDC(context.Background(), big.NewInt(1000))
-	require.Error(s.T(), err)
-	require.Nil(s.T(), transaction)
-}
-
 func (s *ApiClientUnitTestSuite) TestUnit_DepositUSDC_ErrorGettingParameters() {
 	mockEthClient := new(mocks.MockEthClient)
 	s.RyskV2APIClient.EthClient = mockEthClient
 	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
-	mockEthClient.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
 	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
 	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), fmt.Errorf("error getting parameters"))
 
@@ -1564,7 +1676,8 @@ func (s *ApiClientUnitTestSuite) TestUnit_DepositUSDC_ErrorSendTransaction() {
 	mockEthClient := new(mocks.MockEthClient)
 	s.RyskV2APIClient.EthClient = mockEthClient
 	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
-	mockEthClient.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
 	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
 	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
 	mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(fmt.Errorf("failed to send transaction"))
@@ -1574,11 +1687,125 @@ func (s *ApiClientUnitTestSuite) TestUnit_DepositUSDC_ErrorSendTransaction() {
 	require.Nil(s.T(), transaction)
 }
 
+func (s *ApiClientUnitTestSuite) TestUnit_Address() {
+	require.Equal(s.T(), common.HexToAddress(s.Address), s.RyskV2APIClient.Address())
+	require.Equal(s.T(), common.HexToAddress(constants.USDC_ADDRESS[constants.ENVIRONMENT_TESTNET]), s.RyskV2APIClient.USDCAddress())
+}
+
+func (s *ApiClientUnitTestSuite) TestUnit_WithSubAccount() {
+	subAccountClient := s.RyskV2APIClient.WithSubAccount(7)
+	require.Equal(s.T(), int64(7), subAccountClient.SubAccountId)
+	require.NotEqual(s.T(), int64(7), s.RyskV2APIClient.SubAccountId)
+	require.Equal(s.T(), s.RyskV2APIClient.EthClient, subAccountClient.EthClient)
+	require.Equal(s.T(), s.RyskV2APIClient.addressString, subAccountClient.addressString)
+}
+
+func newDepositMockEthClient(balance int64, minDepositAmount int64, allowance int64, status uint64) *mocks.MockEthClient {
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.OnCallContractMethod(constants.ERC20_ABI, "balanceOf", big.NewInt(balance))
+	mockEthClient.OnCallContractMethod(constants.CIAO_ABI, "minDepositAmount", big.NewInt(minDepositAmount))
+	mockEthClient.OnCallContractMethod(constants.ERC20_ABI, "allowance", big.NewInt(allowance))
+	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
+	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
+	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
+	mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+	mockEthClient.On("TransactionReceipt", mock.Anything, mock.Anything).Return(&geth_types.Receipt{Status: status}, nil)
+	return mockEthClient
+}
+
+func (s *ApiClientUnitTestSuite) TestUnit_Deposit() {
+	mockEthClient := newDepositMockEthClient(5000, 10, 0, geth_types.ReceiptStatusSuccessful)
+	s.RyskV2APIClient.EthClient = mockEthClient
+
+	result, err := s.RyskV2APIClient.Deposit(context.Background(), big.NewInt(1000))
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), result.ApproveTransaction)
+	require.NotNil(s.T(), result.ApproveReceipt)
+	require.NotNil(s.T(), result.DepositTransaction)
+	require.NotNil(s.T(), result.DepositReceipt)
+	require.True(s.T(), bytes.HasSuffix(result.ApproveTransaction.Data(), common.LeftPadBytes(big.NewInt(1000).Bytes(), 32)))
+	mockEthClient.AssertNumberOfCalls(s.T(), "SendTransaction", 2)
+}
+
+func (s *ApiClientUnitTestSuite) TestUnit_Deposit_AllowanceSufficient() {
+	mockEthClient := newDepositMockEthClient(5000, 10, 1000, geth_types.ReceiptStatusSuccessful)
+	s.RyskV2APIClient.EthClient = mockEthClient
+
+	result, err := s.RyskV2APIClient.Deposit(context.Background(), big.NewInt(1000))
+	require.NoError(s.T(), err)
+	require.Nil(s.T(), result.ApproveTransaction)
+	require.Nil(s.T(), result.ApproveReceipt)
+	require.NotNil(s.T(), result.DepositTransaction)
+	require.NotNil(s.T(), result.DepositReceipt)
+	mockEthClient.AssertNumberOfCalls(s.T(), "SendTransaction", 1)
+}
+
+func (s *ApiClientUnitTestSuite) TestUnit_Deposit_ApprovalModeMax() {
+	s.RyskV2APIClient.EthClient = newDepositMockEthClient(5000, 10, 0, geth_types.ReceiptStatusSuccessful)
+	s.RyskV2APIClient.ApprovalMode = constants.APPROVAL_MODE_MAX
+	defer func() { s.RyskV2APIClient.ApprovalMode = "" }()
+
+	result, err := s.RyskV2APIClient.Deposit(context.Background(), big.NewInt(1000))
+	require.NoError(s.T(), err)
+	require.True(s.T(), bytes.HasSuffix(result.ApproveTransaction.Data(), abi.MaxUint256.Bytes()))
+}
+
+func (s *ApiClientUnitTestSuite) TestUnit_Deposit_ErrorInsufficientBalance() {
+	mockEthClient := newDepositMockEthClient(500, 10, 0, geth_types.ReceiptStatusSuccessful)
+	s.RyskV2APIClient.EthClient = mockEthClient
+
+	result, err := s.RyskV2APIClient.Deposit(context.Background(), big.NewInt(1000))
+	require.Error(s.T(), err)
+	require.Nil(s.T(), result)
+	mockEthClient.AssertNotCalled(s.T(), "SendTransaction", mock.Anything, mock.Anything)
+}
+
+func (s *ApiClientUnitTestSuite) TestUnit_Deposit_ErrorBelowMinimum() {
+	mockEthClient := newDepositMockEthClient(5000, 2000, 0, geth_types.ReceiptStatusSuccessful)
+	s.RyskV2APIClient.EthClient = mockEthClient
+
+	result, err := s.RyskV2APIClient.Deposit(context.Background(), big.NewInt(1000))
+	require.Error(s.T(), err)
+	require.Nil(s.T(), result)
+	mockEthClient.AssertNotCalled(s.T(), "SendTransaction", mock.Anything, mock.Anything)
+}
+
+func (s *ApiClientUnitTestSuite) TestUnit_Deposit_ErrorReverted() {
+	mockEthClient := newDepositMockEthClient(5000, 10, 1000, geth_types.ReceiptStatusFailed)
+	mockEthClient.On("CallContract", mock.Anything, mock.Anything, mock.Anything).Return([]byte(nil), &mocks.MockDataError{Data: "0x" + hex.EncodeToString(crypto.Keccak256([]byte("DepositQuantityInvalid()"))[:4])})
+	s.RyskV2APIClient.EthClient = mockEthClient
+
+	result, err := s.RyskV2APIClient.Deposit(context.Background(), big.NewInt(1000))
+	require.ErrorIs(s.T(), err, utils.ErrDepositQuantityInvalid)
+	require.NotNil(s.T(), result.DepositTransaction)
+	require.NotNil(s.T(), result.DepositReceipt)
+}
+
+func (s *ApiClientUnitTestSuite) TestUnit_Deposit_ErrorEstimateGasRevert() {
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.OnCallContractMethod(constants.ERC20_ABI, "balanceOf", big.NewInt(5000))
+	mockEthClient.OnCallContractMethod(constants.CIAO_ABI, "minDepositAmount", big.NewInt(10))
+	mockEthClient.OnCallContractMethod(constants.ERC20_ABI, "allowance", big.NewInt(1000))
+	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
+	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
+	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(0), &mocks.MockDataError{Data: "0x" + hex.EncodeToString(crypto.Keccak256([]byte("BalanceInsufficient()"))[:4])})
+	s.RyskV2APIClient.EthClient = mockEthClient
+
+	result, err := s.RyskV2APIClient.Deposit(context.Background(), big.NewInt(1000))
+	require.ErrorIs(s.T(), err, utils.ErrBalanceInsufficient)
+	require.Nil(s.T(), result.DepositTransaction)
+}
+
 func (s *ApiClientUnitTestSuite) TestUnit_WaitTransaction() {
 	mockEthClient := new(mocks.MockEthClient)
 	s.RyskV2APIClient.EthClient = mockEthClient
 	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
-	mockEthClient.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
 	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
 	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
 	mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
@@ -1597,7 +1824,8 @@ func (s *ApiClientUnitTestSuite) TestUnit_WaitTransaction_WaitMinedError() {
 	mockEthClient := new(mocks.MockEthClient)
 	s.RyskV2APIClient.EthClient = mockEthClient
 	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
-	mockEthClient.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
 	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
 	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
 	mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
@@ -0,0 +1,372 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+)
+
+// RecordType is the type of an audit record.
+type RecordType string
+
+const (
+	RECORD_TYPE_ACTION    RecordType = "action"    // RECORD_TYPE_ACTION records a signed action before it is sent.
+	RECORD_TYPE_RESPONSE  RecordType = "response"  // RECORD_TYPE_RESPONSE records the response of the server to an action, or the error sending it.
+	TRANSPORT_REST        string     = "rest"      // TRANSPORT_REST is the transport of actions sent by the REST client.
+	TRANSPORT_WEBSOCKET   string     = "websocket" // TRANSPORT_WEBSOCKET is the transport of actions sent by the websocket client.
+	MAX_PENDING_RESPONSES int        = 4096        // MAX_PENDING_RESPONSES bounds the websocket actions awaiting a response, later ones are recorded without it.
+)
+
+// GENESIS_HASH is the previous hash of the first record of a log.
+var GENESIS_HASH = hexutil.Encode(make([]byte, sha256.Size))
+
+// Record is an entry of the audit log.
+type Record struct {
+	Sequence       uint64              `json:"sequence"`                 // Sequence is the position of the record in the log, from 1.
+	Timestamp      int64               `json:"timestamp"`                // Timestamp is the time of the record in milliseconds.
+	Type           RecordType          `json:"type"`                     // Type is `RECORD_TYPE_ACTION` or `RECORD_TYPE_RESPONSE`.
+	Transport      string              `json:"transport"`                // Transport is `TRANSPORT_REST` or `TRANSPORT_WEBSOCKET`.
+	Endpoint       string              `json:"endpoint"`                 // Endpoint is the HTTP method and path, or the websocket method.
+	MessageId      string              `json:"messageId,omitempty"`      // MessageId is the ID of websocket requests.
+	ActionSequence uint64              `json:"actionSequence,omitempty"` // ActionSequence is the sequence of the action of a response.
+	TypedData      *apitypes.TypedData `json:"typedData,omitempty"`      // TypedData is the EIP-712 typed data signed by an action.
+	Digest         string              `json:"digest,omitempty"`         // Digest is the EIP-712 digest signed by an action.
+	Signature      string              `json:"signature,omitempty"`      // Signature is the signature of an action.
+	Request        json.RawMessage     `json:"request,omitempty"`        // Request is the payload sent by an action.
+	Status         int                 `json:"status,omitempty"`         // Status is the HTTP status of a REST response.
+	Response       json.RawMessage     `json:"response,omitempty"`       // Response is the body of a response, a JSON string if it is not JSON.
+	Error          string              `json:"error,omitempty"`          // Error is the error sending an action or receiving its response.
+	PreviousHash   string              `json:"previousHash"`             // PreviousHash is the hash of the previous record, `GENESIS_HASH` for the first one.
+}
+
+// entry is a line of the log: a record as written and its hash, which chains the next record.
+type entry struct {
+	Hash   string          `json:"hash"`   // Hash is the SHA-256 hash of the record bytes.
+	Record json.RawMessage `json:"record"` // Record is the JSON of the record.
+}
+
+// Action is an EIP-712 signed action, with the typed data and digest it signs.
+type Action struct {
+	TypedData apitypes.TypedData // TypedData is the signed typed data, with the types of its primary type.
+	Digest    string             // Digest is the hex EIP-712 digest of the typed data.
+	Signature string             // Signature is the hex signature of the digest.
+}
+
+// NewAction creates the Action of a signed message.
+//
+// Parameters:
+//   - domain: The EIP-712 domain of the signature.
+//   - primaryType: The primary type of the message.
+//   - message: The signed message, as passed to `utils.SignMessage`.
+//   - signature: The signature of the message.
+//
+// Returns:
+//   - A pointer to Action.
+//   - An error if the message is not valid typed data.
+func NewAction(domain apitypes.TypedDataDomain, primaryType types.PrimaryType, message interface{}, signature string) (*Action, error) {
+	typedData, err := utils.TypedData(domain, primaryType, message)
+	if err != nil {
+		return nil, err
+	}
+	digest, _, err := apitypes.TypedDataAndHash(*typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash typed data: %v", err)
+	}
+	return &Action{TypedData: *typedData, Digest: hexutil.Encode(digest), Signature: signature}, nil
+}
+
+// LogConfiguration holds the configuration of an audit log.
+type LogConfiguration struct {
+	Path string // Path of the log file, created if missing and appended to after verifying its chain otherwise.
+}
+
+// pendingAction is a websocket action awaiting its response.
+type pendingAction struct {
+	sequence uint64 // sequence is the sequence of the action record.
+	method   string // method is the websocket method of the action.
+}
+
+// Log is an append-only audit log of signed actions and their responses, shared by clients of any account. Each record
+// holds the hash of the previous one, so that editing, removing or reordering records breaks the chain checked by
+// `Verify`; the last hash, as returned by `Verify`, should be kept elsewhere to detect truncation.
+//
+// Actions are recorded before they are sent, and are not sent if they cannot be recorded. Once a write fails, the log
+// refuses every later record, so that no action is sent unaudited.
+type Log struct {
+	mutex    sync.Mutex               // mutex guards the fields below and writes.
+	file     *os.File                 // file is the log file, opened for appending.
+	sequence uint64                   // sequence is the sequence of the last record.
+	hash     string                   // hash is the hash of the last record.
+	err      error                    // err is the first write error, refusing later records.
+	pending  map[string]pendingAction // pending holds the websocket actions awaiting a response by message ID.
+	now      func() time.Time         // now returns the time of records.
+}
+
+// NewLog opens an audit log, verifying the chain of its existing records.
+//
+// Parameters:
+//   - config: A pointer to LogConfiguration.
+//
+// Returns:
+//   - A pointer to Log.
+//   - An error if the file cannot be opened, or its chain is broken.
+func NewLog(config *LogConfiguration) (*Log, error) {
+	file, err := os.OpenFile(config.Path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %v", err)
+	}
+	summary, err := Verify(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to verify audit log %s: %v", config.Path, err)
+	}
+	return &Log{
+		file:     file,
+		sequence: summary.Records,
+		hash:     summary.LastHash,
+		pending:  map[string]pendingAction{},
+		now:      time.Now,
+	}, nil
+}
+
+// Close closes the log file, refusing later records.
+//
+// Returns:
+//   - The error closing the file.
+func (log *Log) Close() error {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	if log.err == nil {
+		log.err = errors.New("audit log closed")
+	}
+	return log.file.Close()
+}
+
+// Err returns the error refusing records, after a failed write or once closed.
+//
+// Returns:
+//   - The error, nil while records are written.
+func (log *Log) Err() error {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	return log.err
+}
+
+// Request records a REST action before it is sent.
+//
+// Parameters:
+//   - action: The signed action.
+//   - req: The request of the action, whose body is read from a copy.
+//
+// Returns:
+//   - The sequence of the action record, to record its response with `Response`.
+//   - An error if the action cannot be recorded, in which case it must not be sent.
+func (log *Log) Request(action *Action, req *http.Request) (uint64, error) {
+	var payload []byte
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read audited request: %v", err)
+		}
+		payload, err = io.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read audited request: %v", err)
+		}
+	}
+
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	return log.append(&Record{
+		Type:      RECORD_TYPE_ACTION,
+		Transport: TRANSPORT_REST,
+		Endpoint:  req.Method + " " + req.URL.Path,
+		TypedData: &action.TypedData,
+		Digest:    action.Digest,
+		Signature: action.Signature,
+		Request:   rawJSON(payload),
+	})
+}
+
+// Response records the response to a REST action, leaving the body to the caller.
+//
+// Parameters:
+//   - sequence: The sequence of the action record returned by `Request`.
+//   - req: The request of the action.
+//   - res: The response of the server, nil on error.
+//   - err: The error sending the request.
+//
+// Returns:
+//   - An error if the response cannot be read or recorded.
+func (log *Log) Response(sequence uint64, req *http.Request, res *http.Response, err error) error {
+	record := &Record{
+		Type:           RECORD_TYPE_RESPONSE,
+		Transport:      TRANSPORT_REST,
+		Endpoint:       req.Method + " " + req.URL.Path,
+		ActionSequence: sequence,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	} else {
+		body, readErr := io.ReadAll(res.Body)
+		res.Body.Close()
+		res.Body = io.NopCloser(strings.NewReader(string(body)))
+		record.Status = res.StatusCode
+		record.Response = rawJSON(body)
+		if readErr != nil {
+			record.Error = readErr.Error()
+		}
+	}
+
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	_, err = log.append(record)
+	return err
+}
+
+// RPCRequest records a websocket action before it is sent, its response being recorded by `ObserveMessage`.
+//
+// Parameters:
+//   - action: The signed action.
+//   - request: The RPC request of the action.
+//
+// Returns:
+//   - An error if the action cannot be recorded, in which case it must not be sent.
+func (log *Log) RPCRequest(action *Action, request *types.WebsocketRequest) error {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode audited request: %v", err)
+	}
+
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	sequence, err := log.append(&Record{
+		Type:      RECORD_TYPE_ACTION,
+		Transport: TRANSPORT_WEBSOCKET,
+		Endpoint:  string(request.Method),
+		MessageId: request.ID,
+		TypedData: &action.TypedData,
+		Digest:    action.Digest,
+		Signature: action.Signature,
+		Request:   payload,
+	})
+	if err != nil {
+		return err
+	}
+	if _, ok := log.pending[request.ID]; ok || len(log.pending) < MAX_PENDING_RESPONSES {
+		log.pending[request.ID] = pendingAction{sequence: sequence, method: string(request.Method)}
+	}
+	return nil
+}
+
+// RPCFailed records the error sending a websocket action.
+//
+// Parameters:
+//   - messageId: The message ID of the request.
+//   - err: The error of the connection.
+func (log *Log) RPCFailed(messageId string, err error) {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	pending, ok := log.pending[messageId]
+	if !ok {
+		return
+	}
+	delete(log.pending, messageId)
+	log.append(&Record{
+		Type:           RECORD_TYPE_RESPONSE,
+		Transport:      TRANSPORT_WEBSOCKET,
+		Endpoint:       pending.method,
+		MessageId:      messageId,
+		ActionSequence: pending.sequence,
+		Error:          err.Error(),
+	})
+}
+
+// ObserveMessage records a message of the RPC connection if it is the response to a pending action.
+//
+// Parameters:
+//   - body: The message.
+func (log *Log) ObserveMessage(body []byte) {
+	var response types.WebsocketResponse
+	if err := json.Unmarshal(body, &response); err != nil || response.ID == "" {
+		return
+	}
+
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	pending, ok := log.pending[response.ID]
+	if !ok {
+		return
+	}
+	delete(log.pending, response.ID)
+	log.append(&Record{
+		Type:           RECORD_TYPE_RESPONSE,
+		Transport:      TRANSPORT_WEBSOCKET,
+		Endpoint:       pending.method,
+		MessageId:      response.ID,
+		ActionSequence: pending.sequence,
+		Response:       rawJSON(body),
+	})
+}
+
+// append chains and writes a record, the mutex being held.
+func (log *Log) append(record *Record) (uint64, error) {
+	if log.err != nil {
+		return 0, log.err
+	}
+	record.Sequence = log.sequence + 1
+	record.Timestamp = log.now().UnixMilli()
+	record.PreviousHash = log.hash
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode audit record: %v", err)
+	}
+	hash := hashRecord(recordJSON)
+	line, err := json.Marshal(&entry{Hash: hash, Record: recordJSON})
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode audit record: %v", err)
+	}
+
+	// Write the record and flush it to disk, refusing later records on failure.
+	if _, err := log.file.Write(append(line, '\n')); err != nil {
+		log.err = fmt.Errorf("failed to write audit log: %v", err)
+		return 0, log.err
+	}
+	if err := log.file.Sync(); err != nil {
+		log.err = fmt.Errorf("failed to sync audit log: %v", err)
+		return 0, log.err
+	}
+	log.sequence = record.Sequence
+	log.hash = hash
+	return record.Sequence, nil
+}
+
+// hashRecord returns the hex SHA-256 hash of a record as written.
+func hashRecord(recordJSON []byte) string {
+	hash := sha256.Sum256(recordJSON)
+	return hexutil.Encode(hash[:])
+}
+
+// rawJSON returns a body as JSON, encoded as a string if it is not JSON, or nil if it is empty.
+func rawJSON(body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+	if json.Valid(body) {
+		return body
+	}
+	encoded, _ := json.Marshal(string(body))
+	return encoded
+}
@@ -0,0 +1,345 @@
+//go:build !integration
+// +build !integration
+
+package audit
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditUnitTestSuite struct {
+	suite.Suite
+	Path       string
+	Log        *Log
+	PrivateKey string
+	Domain     apitypes.TypedDataDomain
+}
+
+func (s *AuditUnitTestSuite) SetupTest() {
+	s.Path = filepath.Join(s.T().TempDir(), "audit.jsonl")
+	log, err := NewLog(&LogConfiguration{Path: s.Path})
+	require.NoError(s.T(), err)
+	log.now = func() time.Time { return time.UnixMilli(1718000000000) }
+	s.Log = log
+
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(s.T(), err)
+	s.PrivateKey = hex.EncodeToString(crypto.FromECDSA(privateKey))
+	s.Domain = apitypes.TypedDataDomain{
+		Name:              constants.DOMAIN_NAME,
+		Version:           constants.DOMAIN_VERSION,
+		ChainId:           math.NewHexOrDecimal256(421614),
+		VerifyingContract: constants.ORDER_DISPATCHER_ADDRESS[constants.ENVIRONMENT_TESTNET],
+	}
+}
+
+func (s *AuditUnitTestSuite) TearDownTest() {
+	s.Log.Close()
+}
+
+func TestRunSuiteUnit_AuditUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditUnitTestSuite))
+}
+
+// messages is a websocket reader returning messages in order, then an error.
+type messages []string
+
+func (reader *messages) ReadMessage() (int, []byte, error) {
+	if len(*reader) == 0 {
+		return 0, nil, io.EOF
+	}
+	body := (*reader)[0]
+	*reader = (*reader)[1:]
+	return 1, []byte(body), nil
+}
+
+// action signs a withdrawal.
+func (s *AuditUnitTestSuite) action(nonce string) *Action {
+	message := &struct {
+		Account      string `json:"account"`
+		SubAccountId string `json:"subAccountId"`
+		Asset        string `json:"asset"`
+		Quantity     string `json:"quantity"`
+		Nonce        string `json:"nonce"`
+	}{
+		Account:      utils.AddressFromPrivateKey(s.PrivateKey),
+		SubAccountId: "1",
+		Asset:        constants.USDC_ADDRESS[constants.ENVIRONMENT_TESTNET],
+		Quantity:     constants.E18.String(),
+		Nonce:        nonce,
+	}
+	signature, err := utils.SignMessage(s.Domain, s.PrivateKey, constants.PRIMARY_TYPE_WITHDRAW, message)
+	require.NoError(s.T(), err)
+	action, err := NewAction(s.Domain, constants.PRIMARY_TYPE_WITHDRAW, message, signature)
+	require.NoError(s.T(), err)
+	return action
+}
+
+// records returns the records of the log file.
+func (s *AuditUnitTestSuite) records() []Record {
+	body, err := os.ReadFile(s.Path)
+	require.NoError(s.T(), err)
+	var records []Record
+	for _, line := range strings.Split(strings.TrimSpace(string(body)), "\n") {
+		var lineEntry entry
+		require.NoError(s.T(), json.Unmarshal([]byte(line), &lineEntry))
+		var record Record
+		require.NoError(s.T(), json.Unmarshal(lineEntry.Record, &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+// lines returns the lines of the log file.
+func (s *AuditUnitTestSuite) lines() []string {
+	body, err := os.ReadFile(s.Path)
+	require.NoError(s.T(), err)
+	return strings.SplitAfter(string(body), "\n")
+}
+
+// write replaces the log file with lines.
+func (s *AuditUnitTestSuite) write(lines []string) {
+	require.NoError(s.T(), os.WriteFile(s.Path, []byte(strings.Join(lines, "")), 0600))
+}
+
+func (s *AuditUnitTestSuite) TestUnit_NewAction() {
+	action := s.action("1")
+	require.Equal(s.T(), string(constants.PRIMARY_TYPE_WITHDRAW), action.TypedData.PrimaryType)
+	require.Len(s.T(), action.TypedData.Types, 2)
+	require.Equal(s.T(), "1", action.TypedData.Message["nonce"])
+
+	// The signature is over the digest.
+	signature := hexutil.MustDecode(action.Signature)
+	signature[crypto.RecoveryIDOffset] -= 27
+	publicKey, err := crypto.SigToPub(hexutil.MustDecode(action.Digest), signature)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), utils.AddressFromPrivateKey(s.PrivateKey), crypto.PubkeyToAddress(*publicKey).Hex())
+	signer, err := utils.RecoverSigner(action.TypedData.Domain, constants.PRIMARY_TYPE_WITHDRAW, action.TypedData.Message, action.Signature)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), crypto.PubkeyToAddress(*publicKey), signer)
+
+	_, err = NewAction(s.Domain, constants.PRIMARY_TYPE_WITHDRAW, make(chan int), "0x")
+	require.Error(s.T(), err)
+	_, err = NewAction(s.Domain, constants.PRIMARY_TYPE_WITHDRAW, map[string]string{}, "0x")
+	require.Error(s.T(), err)
+}
+
+func (s *AuditUnitTestSuite) TestUnit_REST() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":400,"message":"nonce used"}`))
+	}))
+	defer server.Close()
+
+	// Actions are recorded before they are sent, responses after, leaving the body to the caller.
+	action := s.action("1")
+	req, _ := utils.CreateHTTPRequestWithBody(http.MethodPost, server.URL+"/withdraw", map[string]string{"signature": action.Signature})
+	sequence, err := s.Log.Request(action, req)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), uint64(1), sequence)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), s.Log.Response(sequence, req, res, nil))
+	body, err := io.ReadAll(res.Body)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), `{"code":400,"message":"nonce used"}`, string(body))
+
+	// Errors are recorded.
+	req, _ = utils.CreateHTTPRequestWithBody(http.MethodPost, "http://127.0.0.1:1/withdraw", map[string]string{})
+	sequence, err = s.Log.Request(s.action("2"), req)
+	require.NoError(s.T(), err)
+	_, err = http.DefaultClient.Do(req)
+	require.Error(s.T(), err)
+	require.NoError(s.T(), s.Log.Response(sequence, req, nil, err))
+
+	records := s.records()
+	require.Len(s.T(), records, 4)
+	require.Equal(s.T(), RECORD_TYPE_ACTION, records[0].Type)
+	require.Equal(s.T(), TRANSPORT_REST, records[0].Transport)
+	require.Equal(s.T(), "POST /withdraw", records[0].Endpoint)
+	require.Equal(s.T(), action.Digest, records[0].Digest)
+	require.Equal(s.T(), action.Signature, records[0].Signature)
+	require.JSONEq(s.T(), `{"signature":"`+action.Signature+`"}`, string(records[0].Request))
+	require.Equal(s.T(), int64(1718000000000), records[0].Timestamp)
+	require.Equal(s.T(), GENESIS_HASH, records[0].PreviousHash)
+	require.Equal(s.T(), RECORD_TYPE_RESPONSE, records[1].Type)
+	require.Equal(s.T(), uint64(1), records[1].ActionSequence)
+	require.Equal(s.T(), http.StatusBadRequest, records[1].Status)
+	require.JSONEq(s.T(), `{"code":400,"message":"nonce used"}`, string(records[1].Response))
+	require.Equal(s.T(), uint64(3), records[3].ActionSequence)
+	require.NotEmpty(s.T(), records[3].Error)
+
+	summary, err := VerifyFile(s.Path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), &Summary{Records: 4, Actions: 2, Responses: 2, LastHash: s.Log.hash}, summary)
+}
+
+func (s *AuditUnitTestSuite) TestUnit_Websocket() {
+	// Responses are recorded when read, once, and failures when sending.
+	for _, id := range []string{"1", "2", "3"} {
+		request := &types.WebsocketRequest{JsonRPC: constants.WS_JSON_RPC, ID: id, Method: constants.WS_METHOD_WITHDRAW, Params: map[string]string{}}
+		require.NoError(s.T(), s.Log.RPCRequest(s.action(id), request))
+	}
+	s.Log.RPCFailed("3", errors.New("closed"))
+	s.Log.RPCFailed("unknown", errors.New("closed"))
+	reader := NewReader(&messages{
+		`{"id":"2","error":{"code":400,"message":"nonce used"}}`,
+		`{"id":"1","result":{}}`,
+		`{"id":"1","result":{}}`,
+		`{"stream":"ethperp@trade","data":{}}`,
+		`not json`,
+	}, s.Log)
+	for {
+		if _, _, err := reader.ReadMessage(); err != nil {
+			require.ErrorIs(s.T(), err, io.EOF)
+			break
+		}
+	}
+	records := s.records()
+	require.Len(s.T(), records, 6)
+	require.Equal(s.T(), TRANSPORT_WEBSOCKET, records[0].Transport)
+	require.Equal(s.T(), string(constants.WS_METHOD_WITHDRAW), records[0].Endpoint)
+	require.Equal(s.T(), "1", records[0].MessageId)
+	for i, expected := range []struct {
+		messageId string
+		sequence  uint64
+	}{{"3", 3}, {"2", 2}, {"1", 1}} {
+		require.Equal(s.T(), RECORD_TYPE_RESPONSE, records[i+3].Type)
+		require.Equal(s.T(), expected.messageId, records[i+3].MessageId)
+		require.Equal(s.T(), expected.sequence, records[i+3].ActionSequence)
+	}
+	require.Equal(s.T(), "closed", records[3].Error)
+	require.Empty(s.T(), s.Log.pending)
+
+	// Pending actions are bounded.
+	s.Log.pending = map[string]pendingAction{}
+	for i := 0; i <= MAX_PENDING_RESPONSES; i++ {
+		s.Log.pending[strings.Repeat("x", i+1)] = pendingAction{}
+	}
+	request := &types.WebsocketRequest{JsonRPC: constants.WS_JSON_RPC, ID: "4", Method: constants.WS_METHOD_WITHDRAW}
+	require.NoError(s.T(), s.Log.RPCRequest(s.action("4"), request))
+	require.NotContains(s.T(), s.Log.pending, "4")
+}
+
+func (s *AuditUnitTestSuite) TestUnit_Reopen() {
+	req, _ := utils.CreateHTTPRequestWithBody(http.MethodPost, "http://localhost/withdraw", map[string]string{})
+	_, err := s.Log.Request(s.action("1"), req)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), s.Log.Close())
+	_, err = s.Log.Request(s.action("2"), req)
+	require.Error(s.T(), err)
+
+	// Reopened logs continue the chain.
+	log, err := NewLog(&LogConfiguration{Path: s.Path})
+	require.NoError(s.T(), err)
+	s.Log = log
+	sequence, err := log.Request(s.action("2"), req)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), uint64(2), sequence)
+	summary, err := VerifyFile(s.Path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), uint64(2), summary.Actions)
+
+	// Logs that cannot be opened or written refuse records.
+	_, err = NewLog(&LogConfiguration{Path: filepath.Join(s.T().TempDir(), "missing", "audit.jsonl")})
+	require.Error(s.T(), err)
+	log.file.Close()
+	_, err = log.Request(s.action("3"), req)
+	require.Error(s.T(), err)
+	require.ErrorIs(s.T(), log.RPCRequest(s.action("4"), &types.WebsocketRequest{ID: "4"}), log.Err())
+	require.Error(s.T(), log.Err())
+}
+
+func (s *AuditUnitTestSuite) TestUnit_Verify() {
+	req, _ := utils.CreateHTTPRequestWithBody(http.MethodPost, "http://localhost/withdraw", map[string]string{})
+	for _, nonce := range []string{"1", "2", "3"} {
+		_, err := s.Log.Request(s.action(nonce), req)
+		require.NoError(s.T(), err)
+	}
+	lines := s.lines()
+	require.Len(s.T(), lines, 4)
+	require.Empty(s.T(), lines[3])
+
+	summary, err := Verify(strings.NewReader(""))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), &Summary{LastHash: GENESIS_HASH}, summary)
+	_, err = VerifyFile(filepath.Join(s.T().TempDir(), "missing.jsonl"))
+	require.Error(s.T(), err)
+
+	// verifyError returns the error verifying edited lines.
+	verifyError := func(edited []string) *VerificationError {
+		s.write(edited)
+		_, err := VerifyFile(s.Path)
+		var verificationError *VerificationError
+		require.ErrorAs(s.T(), err, &verificationError)
+		_, err = NewLog(&LogConfiguration{Path: s.Path})
+		require.Error(s.T(), err)
+		return verificationError
+	}
+
+	// Edited records do not match their hash.
+	edited := append([]string{}, lines...)
+	edited[1] = strings.Replace(edited[1], `"nonce":"2"`, `"nonce":"5"`, 1)
+	verificationError := verifyError(edited)
+	require.Equal(s.T(), uint64(2), verificationError.Line)
+	require.Contains(s.T(), verificationError.Reason, "does not match record hash")
+
+	// Rehashed records break the chain.
+	var lineEntry entry
+	require.NoError(s.T(), json.Unmarshal([]byte(edited[1]), &lineEntry))
+	lineEntry.Hash = hashRecord(lineEntry.Record)
+	rehashed, _ := json.Marshal(&lineEntry)
+	edited[1] = string(rehashed) + "\n"
+	verificationError = verifyError(edited)
+	require.Equal(s.T(), uint64(2), verificationError.Line)
+	require.Contains(s.T(), verificationError.Reason, "digest")
+	require.Equal(s.T(), uint64(3), verifyError([]string{lines[0], lines[1], string(rehashed) + "\n"}).Line)
+
+	// Removed, reordered and truncated records are detected.
+	require.Contains(s.T(), verifyError([]string{lines[0], lines[2]}).Reason, "sequence 3, expected 2")
+	require.Contains(s.T(), verifyError([]string{lines[1], lines[0]}).Reason, "sequence 2, expected 1")
+	require.Equal(s.T(), &VerificationError{Line: 3, Reason: "truncated record"}, verifyError([]string{lines[0], lines[1], strings.TrimSuffix(lines[2], "\n")}))
+	require.Equal(s.T(), "line 1: invalid entry", verifyError([]string{"{}\n"}).Error())
+
+	// Actions carry a valid signature.
+	var record Record
+	require.NoError(s.T(), json.Unmarshal([]byte(lines[0]), &lineEntry))
+	require.NoError(s.T(), json.Unmarshal(lineEntry.Record, &record))
+	record.Signature = hexutil.Encode(make([]byte, crypto.SignatureLength))
+	recordJSON, _ := json.Marshal(&record)
+	unsigned, _ := json.Marshal(&entry{Hash: hashRecord(recordJSON), Record: recordJSON})
+	require.Contains(s.T(), verifyError([]string{string(unsigned) + "\n"}).Reason, "invalid signature")
+
+	// Responses follow their action.
+	record.Sequence, record.PreviousHash, record.Type, record.ActionSequence = 1, GENESIS_HASH, RECORD_TYPE_RESPONSE, 1
+	recordJSON, _ = json.Marshal(&record)
+	response, _ := json.Marshal(&entry{Hash: hashRecord(recordJSON), Record: recordJSON})
+	require.Contains(s.T(), verifyError([]string{string(response) + "\n"}).Reason, "response to unknown action 1")
+
+	// Untouched logs verify.
+	s.write(lines)
+	summary, err = Verify(bytes.NewReader([]byte(strings.Join(lines, ""))))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), uint64(3), summary.Records)
+}
@@ -0,0 +1,37 @@
+package audit
+
+import (
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+// Reader is a `types.IWSReader` recording the responses to the websocket actions of a log.
+type Reader struct {
+	reader types.IWSReader
+	log    *Log
+}
+
+// NewReader creates a Reader recording responses with `ObserveMessage`.
+//
+// Parameters:
+//   - reader: The RPC websocket connection.
+//   - log: The audit log of the actions.
+//
+// Returns:
+//   - A pointer to Reader.
+func NewReader(reader types.IWSReader, log *Log) *Reader {
+	return &Reader{reader: reader, log: log}
+}
+
+// ReadMessage reads the next message and observes it.
+//
+// Returns:
+//   - messageType: The websocket message type.
+//   - body: The message.
+//   - err: The error of the connection.
+func (reader *Reader) ReadMessage() (int, []byte, error) {
+	messageType, body, err := reader.reader.ReadMessage()
+	if err == nil {
+		reader.log.ObserveMessage(body)
+	}
+	return messageType, body, err
+}
@@ -0,0 +1,145 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+)
+
+// Summary describes a verified audit log.
+type Summary struct {
+	Records   uint64 `json:"records"`   // Records is the number of records.
+	Actions   uint64 `json:"actions"`   // Actions is the number of action records.
+	Responses uint64 `json:"responses"` // Responses is the number of response records.
+	LastHash  string `json:"lastHash"`  // LastHash is the hash of the last record, `GENESIS_HASH` for an empty log.
+}
+
+// VerificationError reports the first invalid line of an audit log.
+type VerificationError struct {
+	Line   uint64 // Line is the number of the invalid line, from 1.
+	Reason string // Reason describes why the line is invalid.
+}
+
+// Error returns the line and reason of the error.
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Reason)
+}
+
+// Verify replays an audit log, checking that each record hashes to its hash, follows the previous record in sequence and
+// hash, that actions carry a valid signature of the digest of their typed data and that responses follow their action.
+//
+// Parameters:
+//   - reader: The audit log, read from its current position to the end.
+//
+// Returns:
+//   - A pointer to Summary.
+//   - A *VerificationError on the first invalid line, or the error reading the log.
+func Verify(reader io.Reader) (*Summary, error) {
+	summary := &Summary{LastHash: GENESIS_HASH}
+	actions := map[uint64]bool{}
+	lines := bufio.NewReader(reader)
+	for line := uint64(1); ; line++ {
+		body, err := lines.ReadBytes('\n')
+		if errors.Is(err, io.EOF) && len(body) == 0 {
+			return summary, nil
+		}
+		if errors.Is(err, io.EOF) {
+			return nil, &VerificationError{Line: line, Reason: "truncated record"}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit log: %v", err)
+		}
+		record, hash, reason := verifyLine(body, line, summary.LastHash, actions)
+		if reason != "" {
+			return nil, &VerificationError{Line: line, Reason: reason}
+		}
+
+		summary.Records = record.Sequence
+		summary.LastHash = hash
+		if record.Type == RECORD_TYPE_ACTION {
+			actions[record.Sequence] = true
+			summary.Actions++
+		} else {
+			summary.Responses++
+		}
+	}
+}
+
+// VerifyFile verifies the audit log of a file with `Verify`.
+//
+// Parameters:
+//   - path: The path of the audit log.
+//
+// Returns:
+//   - A pointer to Summary.
+//   - A *VerificationError on the first invalid line, or the error reading the file.
+func VerifyFile(path string) (*Summary, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %v", err)
+	}
+	defer file.Close()
+	return Verify(file)
+}
+
+// verifyLine checks a line of the log against the previous hash and the sequences of earlier actions.
+//
+// Returns:
+//   - The record and its hash.
+//   - The reason the line is invalid, empty if it is valid.
+func verifyLine(body []byte, line uint64, previousHash string, actions map[uint64]bool) (*Record, string, string) {
+	var lineEntry entry
+	if err := json.Unmarshal(body, &lineEntry); err != nil || len(lineEntry.Record) == 0 {
+		return nil, "", "invalid entry"
+	}
+	hash := hashRecord(lineEntry.Record)
+	if hash != lineEntry.Hash {
+		return nil, "", fmt.Sprintf("hash %s does not match record hash %s", lineEntry.Hash, hash)
+	}
+
+	var record Record
+	decoder := json.NewDecoder(bytes.NewReader(lineEntry.Record))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&record); err != nil {
+		return nil, "", fmt.Sprintf("invalid record: %v", err)
+	}
+	if record.Sequence != line {
+		return nil, "", fmt.Sprintf("sequence %d, expected %d", record.Sequence, line)
+	}
+	if record.PreviousHash != previousHash {
+		return nil, "", fmt.Sprintf("previous hash %s does not match %s", record.PreviousHash, previousHash)
+	}
+
+	switch record.Type {
+	case RECORD_TYPE_ACTION:
+		if record.TypedData == nil || record.Signature == "" {
+			return nil, "", "action without typed data or signature"
+		}
+		digest, _, err := apitypes.TypedDataAndHash(*record.TypedData)
+		if err != nil {
+			return nil, "", fmt.Sprintf("invalid typed data: %v", err)
+		}
+		if hexutil.Encode(digest) != record.Digest {
+			return nil, "", fmt.Sprintf("digest %s does not match typed data digest %s", record.Digest, hexutil.Encode(digest))
+		}
+		if _, err := utils.RecoverSigner(record.TypedData.Domain, types.PrimaryType(record.TypedData.PrimaryType), record.TypedData.Message, record.Signature); err != nil {
+			return nil, "", err.Error()
+		}
+	case RECORD_TYPE_RESPONSE:
+		if !actions[record.ActionSequence] {
+			return nil, "", fmt.Sprintf("response to unknown action %d", record.ActionSequence)
+		}
+	default:
+		return nil, "", fmt.Sprintf("unknown record type %q", record.Type)
+	}
+	return &record, hash, ""
+}
@@ -0,0 +1,265 @@
+package backfill
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+const (
+	MAX_PAGE_LIMIT              int64         = 1000
+	DEFAULT_REQUESTS_PER_SECOND float64       = 5
+	DEFAULT_MAX_RETRIES         int           = 5
+	DEFAULT_RETRY_DELAY         time.Duration = time.Second
+)
+
+// IKlineFetcher retrieves one page of klines, implemented by `api_client.RyskV2APIClient`.
+type IKlineFetcher interface {
+	GetKlineDataCtx(ctx context.Context, params *types.KlineDataRequest) (*http.Response, error)
+}
+
+// BackfillerConfiguration holds the configuration for the kline backfiller.
+type BackfillerConfiguration struct {
+	Client            IKlineFetcher // Client used to fetch pages, e.g. a `*api_client.RyskV2APIClient`.
+	PageLimit         int64         // Number of klines requested per page. Defaults to, and is capped at, `MAX_PAGE_LIMIT`.
+	RequestsPerSecond float64       // Maximum request rate. Defaults to `DEFAULT_REQUESTS_PER_SECOND`.
+	MaxRetries        int           // Number of retries of a page on rate limiting (429) or server (5xx) errors. Defaults to `DEFAULT_MAX_RETRIES`.
+	RetryDelay        time.Duration // Delay before the first retry, doubled after each retry unless the server sends `Retry-After`. Defaults to `DEFAULT_RETRY_DELAY`.
+}
+
+// BackfillRequest is a range of klines to backfill.
+type BackfillRequest struct {
+	Product   *types.Product // The product.
+	Interval  types.Interval // The kline interval.
+	StartTime int64          // UNIX timestamp (in ms) of the range start, rounded down to the interval.
+	EndTime   int64          // UNIX timestamp (in ms) of the range end, inclusive. Defaults to now.
+}
+
+// MissingRange is a run of consecutive bars absent from the results, e.g. periods without trades or data outages.
+type MissingRange struct {
+	From int64 `json:"from"` // Open time (in ms) of the first missing bar.
+	To   int64 `json:"to"`   // Open time (in ms) of the last missing bar.
+	Bars int64 `json:"bars"` // Number of missing bars.
+}
+
+// Report summarizes a backfill.
+type Report struct {
+	Klines     int64          `json:"klines"`     // Number of klines delivered.
+	Requests   int64          `json:"requests"`   // Number of requests sent, retries included.
+	Duplicates int64          `json:"duplicates"` // Number of bars dropped as duplicates of a delivered bar.
+	Missing    []MissingRange `json:"missing"`    // Missing bars within the range, oldest first.
+}
+
+// Backfiller pages through kline history.
+type Backfiller struct {
+	client         IKlineFetcher
+	pageLimit      int64
+	requestSpacing time.Duration
+	maxRetries     int
+	retryDelay     time.Duration
+	lastRequest    time.Time
+}
+
+// NewBackfiller creates a new Backfiller instance.
+//
+// Parameters:
+//   - config: A pointer to BackfillerConfiguration containing the configuration settings.
+//
+// Returns:
+//   - A pointer to Backfiller.
+//   - An error if no client is provided.
+func NewBackfiller(config *BackfillerConfiguration) (*Backfiller, error) {
+	if config.Client == nil {
+		return nil, fmt.Errorf("backfiller requires a client")
+	}
+
+	backfiller := &Backfiller{
+		client:     config.Client,
+		pageLimit:  config.PageLimit,
+		maxRetries: config.MaxRetries,
+		retryDelay: config.RetryDelay,
+	}
+	if backfiller.pageLimit <= 0 || backfiller.pageLimit > MAX_PAGE_LIMIT {
+		backfiller.pageLimit = MAX_PAGE_LIMIT
+	}
+	requestsPerSecond := config.RequestsPerSecond
+	if requestsPerSecond <= 0 {
+		requestsPerSecond = DEFAULT_REQUESTS_PER_SECOND
+	}
+	backfiller.requestSpacing = time.Duration(float64(time.Second) / requestsPerSecond)
+	if backfiller.maxRetries == 0 {
+		backfiller.maxRetries = DEFAULT_MAX_RETRIES
+	}
+	if backfiller.retryDelay == 0 {
+		backfiller.retryDelay = DEFAULT_RETRY_DELAY
+	}
+	return backfiller, nil
+}
+
+// Stream pages through a range and passes each kline, oldest first and once per open time, to a callback.
+// Pages cover at most `PageLimit` bars of time so that no bar is cut off, whichever end of the page the API trims.
+//
+// Parameters:
+//   - ctx: The context for the requests.
+//   - request: A pointer to BackfillRequest describing the range.
+//   - onKline: Callback invoked with each kline, returning an error stops the backfill.
+//
+// Returns:
+//   - A pointer to the Report, also returned alongside errors to describe the progress made.
+//   - An error if the request is invalid, a page cannot be fetched or the callback fails.
+func (backfiller *Backfiller) Stream(ctx context.Context, request *BackfillRequest, onKline func(kline types.Kline) error) (*Report, error) {
+	report := &Report{}
+	if request.Product == nil {
+		return report, fmt.Errorf("backfill requires a product")
+	}
+	duration, ok := constants.INTERVAL_DURATIONS[request.Interval]
+	if !ok {
+		return report, fmt.Errorf("invalid interval %q", request.Interval)
+	}
+	width := duration.Milliseconds()
+	start := request.StartTime - request.StartTime%width
+	end := request.EndTime
+	if end == 0 {
+		end = time.Now().UnixMilli()
+	}
+	if end < start {
+		return report, fmt.Errorf("end time %d is before start time %d", end, request.StartTime)
+	}
+
+	// next is the open time of the first bar not delivered yet.
+	next := start
+	for pageStart := start; pageStart <= end; pageStart += backfiller.pageLimit * width {
+		pageEnd := min(pageStart+backfiller.pageLimit*width-1, end)
+		klines, err := backfiller.fetch(ctx, report, &types.KlineDataRequest{
+			Product:   request.Product,
+			Interval:  request.Interval,
+			StartTime: pageStart,
+			EndTime:   pageEnd,
+			Limit:     backfiller.pageLimit,
+		})
+		if err != nil {
+			return report, err
+		}
+
+		sort.Slice(klines, func(i, j int) bool {
+			return klines[i].OpenTime < klines[j].OpenTime
+		})
+		for _, kline := range klines {
+			if kline.OpenTime < next || kline.OpenTime > end {
+				if kline.OpenTime >= start {
+					report.Duplicates++
+				}
+				continue
+			}
+			if kline.OpenTime > next {
+				report.addMissing(next, kline.OpenTime, width)
+			}
+			if err := onKline(kline); err != nil {
+				return report, err
+			}
+			report.Klines++
+			next = kline.OpenTime + width
+		}
+	}
+	if next <= end {
+		report.addMissing(next, end-end%width+width, width)
+	}
+	return report, nil
+}
+
+// Fetch pages through a range and returns every kline, oldest first.
+//
+// Parameters:
+//   - ctx: The context for the requests.
+//   - request: A pointer to BackfillRequest describing the range.
+//
+// Returns:
+//   - The klines.
+//   - A pointer to the Report.
+//   - An error if the request is invalid or a page cannot be fetched.
+func (backfiller *Backfiller) Fetch(ctx context.Context, request *BackfillRequest) ([]types.Kline, *Report, error) {
+	var klines []types.Kline
+	report, err := backfiller.Stream(ctx, request, func(kline types.Kline) error {
+		klines = append(klines, kline)
+		return nil
+	})
+	return klines, report, err
+}
+
+// addMissing records the bars opening from `from` (inclusive) to `to` (exclusive) as missing.
+func (report *Report) addMissing(from int64, to int64, width int64) {
+	bars := (to - from) / width
+	if bars <= 0 {
+		return
+	}
+	report.Missing = append(report.Missing, MissingRange{From: from, To: from + (bars-1)*width, Bars: bars})
+}
+
+// fetch requests a page, waiting for the rate limit and retrying rate limited and server errors.
+func (backfiller *Backfiller) fetch(ctx context.Context, report *Report, params *types.KlineDataRequest) ([]types.Kline, error) {
+	delay := backfiller.retryDelay
+	for attempt := 0; ; attempt++ {
+		// Space requests.
+		if err := sleep(ctx, time.Until(backfiller.lastRequest.Add(backfiller.requestSpacing))); err != nil {
+			return nil, err
+		}
+		backfiller.lastRequest = time.Now()
+		report.Requests++
+
+		res, err := backfiller.client.GetKlineDataCtx(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get klines from %d: %v", params.StartTime, err)
+		}
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read klines from %d: %v", params.StartTime, err)
+		}
+
+		// Retry rate limited and server errors.
+		retryable := res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+		if retryable && attempt < backfiller.maxRetries {
+			wait := delay
+			if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+				wait = time.Duration(seconds) * time.Second
+			}
+			if err := sleep(ctx, wait); err != nil {
+				return nil, err
+			}
+			delay *= 2
+			continue
+		}
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			return nil, fmt.Errorf("failed to get klines from %d: unexpected status code %d: %s", params.StartTime, res.StatusCode, string(body))
+		}
+
+		var klines []types.Kline
+		if err := json.Unmarshal(body, &klines); err != nil {
+			return nil, fmt.Errorf("failed to decode klines from %d: %v", params.StartTime, err)
+		}
+		return klines, nil
+	}
+}
+
+// sleep waits for a duration or until the context is done.
+func sleep(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,309 @@
+//go:build !integration
+// +build !integration
+
+package backfill
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rysk-finance/v2_client_go/api_client"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/ryskfake"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	START  int64 = 1700000040000 // START is aligned to the minute.
+	MINUTE int64 = 60000
+)
+
+type BackfillUnitTestSuite struct {
+	suite.Suite
+}
+
+func TestRunSuiteUnit_BackfillUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(BackfillUnitTestSuite))
+}
+
+// stubFetcher serves 1m klines at the given open times. Like the exchange, it returns the most recent
+// `limit` klines of the range, and it also returns the kline preceding the range.
+type stubFetcher struct {
+	openTimes []int64
+	statuses  []int // statuses are returned, in order, before serving klines.
+	requests  []types.KlineDataRequest
+	blocking  bool // blocking requests wait for their context to be done.
+}
+
+func (fetcher *stubFetcher) GetKlineDataCtx(ctx context.Context, params *types.KlineDataRequest) (*http.Response, error) {
+	fetcher.requests = append(fetcher.requests, *params)
+	if fetcher.blocking {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if len(fetcher.statuses) > 0 {
+		status := fetcher.statuses[0]
+		fetcher.statuses = fetcher.statuses[1:]
+		header := http.Header{}
+		header.Set("Retry-After", "0")
+		return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader("error"))}, nil
+	}
+
+	var klines []types.Kline
+	for _, openTime := range fetcher.openTimes {
+		if openTime >= params.StartTime-MINUTE && openTime <= params.EndTime {
+			klines = append(klines, types.Kline{Symbol: params.Product.Symbol, Interval: params.Interval, OpenTime: openTime, CloseTime: openTime + MINUTE - 1, Close: strconv.FormatInt(openTime, 10)})
+		}
+	}
+	if int64(len(klines)) > params.Limit {
+		klines = klines[int64(len(klines))-params.Limit:]
+	}
+	body, err := json.Marshal(klines)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(body))}, nil
+}
+
+// minutes lists the open times of `count` bars from START, skipping the given bar indexes.
+func minutes(count int64, skip ...int64) []int64 {
+	skipped := make(map[int64]bool)
+	for _, index := range skip {
+		skipped[index] = true
+	}
+	var openTimes []int64
+	for index := int64(0); index < count; index++ {
+		if !skipped[index] {
+			openTimes = append(openTimes, START+index*MINUTE)
+		}
+	}
+	return openTimes
+}
+
+func (s *BackfillUnitTestSuite) newBackfiller(fetcher IKlineFetcher) *Backfiller {
+	backfiller, err := NewBackfiller(&BackfillerConfiguration{Client: fetcher, RequestsPerSecond: 1000, RetryDelay: time.Millisecond})
+	require.NoError(s.T(), err)
+	return backfiller
+}
+
+func (s *BackfillUnitTestSuite) request(bars int64) *BackfillRequest {
+	return &BackfillRequest{Product: &constants.PRODUCT_ETH_PERP, Interval: constants.INTERVAL_1M, StartTime: START, EndTime: START + bars*MINUTE - 1}
+}
+
+func (s *BackfillUnitTestSuite) TestUnit_NewBackfiller() {
+	_, err := NewBackfiller(&BackfillerConfiguration{})
+	require.Error(s.T(), err)
+
+	backfiller, err := NewBackfiller(&BackfillerConfiguration{Client: &stubFetcher{}, PageLimit: 5000})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), MAX_PAGE_LIMIT, backfiller.pageLimit)
+	require.Equal(s.T(), 200*time.Millisecond, backfiller.requestSpacing)
+}
+
+func (s *BackfillUnitTestSuite) TestUnit_Fetch_Pagination() {
+	fetcher := &stubFetcher{openTimes: minutes(2500, 1200, 1201, 1202, 2497, 2498, 2499), statuses: []int{http.StatusTooManyRequests}}
+	backfiller := s.newBackfiller(fetcher)
+
+	// An unaligned start is rounded down to the minute.
+	request := s.request(2500)
+	request.StartTime += 30000
+	klines, report, err := backfiller.Fetch(context.Background(), request)
+	require.NoError(s.T(), err)
+
+	// Three pages of 1000 minutes, the first one retried once.
+	require.Len(s.T(), fetcher.requests, 4)
+	require.Equal(s.T(), int64(4), report.Requests)
+	for i, page := range fetcher.requests[1:] {
+		require.Equal(s.T(), START+int64(i)*1000*MINUTE, page.StartTime)
+		require.Equal(s.T(), MAX_PAGE_LIMIT, page.Limit)
+		require.LessOrEqual(s.T(), page.EndTime, request.EndTime)
+	}
+
+	// Every bar once, in order, the bars preceding later pages dropped.
+	require.Len(s.T(), klines, 2494)
+	require.Equal(s.T(), int64(2494), report.Klines)
+	require.Equal(s.T(), int64(2), report.Duplicates)
+	for i := 1; i < len(klines); i++ {
+		require.Greater(s.T(), klines[i].OpenTime, klines[i-1].OpenTime)
+	}
+
+	require.Equal(s.T(), []MissingRange{
+		{From: START + 1200*MINUTE, To: START + 1202*MINUTE, Bars: 3},
+		{From: START + 2497*MINUTE, To: START + 2499*MINUTE, Bars: 3},
+	}, report.Missing)
+}
+
+func (s *BackfillUnitTestSuite) TestUnit_Stream_Empty() {
+	backfiller := s.newBackfiller(&stubFetcher{})
+	report, err := backfiller.Stream(context.Background(), s.request(10), func(kline types.Kline) error {
+		s.T().Fatal("unexpected kline")
+		return nil
+	})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []MissingRange{{From: START, To: START + 9*MINUTE, Bars: 10}}, report.Missing)
+}
+
+func (s *BackfillUnitTestSuite) TestUnit_Stream_Errors() {
+	ctx := context.Background()
+	backfiller := s.newBackfiller(&stubFetcher{openTimes: minutes(10)})
+	onKline := func(kline types.Kline) error { return nil }
+
+	_, err := backfiller.Stream(ctx, &BackfillRequest{Interval: constants.INTERVAL_1M}, onKline)
+	require.ErrorContains(s.T(), err, "product")
+
+	request := s.request(10)
+	request.Interval = "2m"
+	_, err = backfiller.Stream(ctx, request, onKline)
+	require.ErrorContains(s.T(), err, "invalid interval")
+
+	request = s.request(10)
+	request.EndTime = START - MINUTE
+	_, err = backfiller.Stream(ctx, request, onKline)
+	require.ErrorContains(s.T(), err, "before start time")
+
+	// Client errors are not retried.
+	fetcher := &stubFetcher{statuses: []int{http.StatusBadRequest}}
+	_, err = s.newBackfiller(fetcher).Stream(ctx, s.request(10), onKline)
+	require.ErrorContains(s.T(), err, "400")
+	require.Len(s.T(), fetcher.requests, 1)
+
+	// Server errors are retried up to MaxRetries.
+	fetcher = &stubFetcher{statuses: []int{500, 502, 503}}
+	backfiller, err = NewBackfiller(&BackfillerConfiguration{Client: fetcher, RequestsPerSecond: 1000, MaxRetries: 2, RetryDelay: time.Millisecond})
+	require.NoError(s.T(), err)
+	_, err = backfiller.Stream(ctx, s.request(10), onKline)
+	require.ErrorContains(s.T(), err, "503")
+	require.Len(s.T(), fetcher.requests, 3)
+
+	// Callback errors stop the backfill with the progress made.
+	failure := io.ErrClosedPipe
+	count := 0
+	report, err := s.newBackfiller(&stubFetcher{openTimes: minutes(10)}).Stream(ctx, s.request(10), func(kline types.Kline) error {
+		count++
+		if count == 3 {
+			return failure
+		}
+		return nil
+	})
+	require.ErrorIs(s.T(), err, failure)
+	require.Equal(s.T(), int64(2), report.Klines)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = s.newBackfiller(&stubFetcher{}).Stream(cancelled, s.request(10), onKline)
+	require.ErrorIs(s.T(), err, context.Canceled)
+}
+
+func (s *BackfillUnitTestSuite) TestUnit_Stream_CancelledRequest() {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := s.newBackfiller(&stubFetcher{blocking: true}).Stream(ctx, s.request(10), func(types.Kline) error {
+		return nil
+	})
+	require.ErrorContains(s.T(), err, context.DeadlineExceeded.Error())
+}
+
+func (s *BackfillUnitTestSuite) TestUnit_Stream_RateLimit() {
+	fetcher := &stubFetcher{openTimes: minutes(30)}
+	backfiller, err := NewBackfiller(&BackfillerConfiguration{Client: fetcher, PageLimit: 10, RequestsPerSecond: 20})
+	require.NoError(s.T(), err)
+
+	started := time.Now()
+	_, report, err := backfiller.Fetch(context.Background(), s.request(30))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), int64(3), report.Requests)
+	require.GreaterOrEqual(s.T(), time.Since(started), 100*time.Millisecond)
+}
+
+func (s *BackfillUnitTestSuite) TestUnit_Output() {
+	klines, _, err := s.newBackfiller(&stubFetcher{openTimes: minutes(3)}).Fetch(context.Background(), s.request(3))
+	require.NoError(s.T(), err)
+
+	// CSV rows follow a header.
+	var output bytes.Buffer
+	writer := NewCSVWriter(&output)
+	for _, kline := range klines {
+		require.NoError(s.T(), writer.Write(kline))
+	}
+	require.NoError(s.T(), writer.Flush())
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	require.Len(s.T(), lines, 4)
+	require.Equal(s.T(), strings.Join(CSV_HEADER, ","), lines[0])
+	require.Equal(s.T(), "ethperp,1m,"+strconv.FormatInt(START, 10)+","+strconv.FormatInt(START+MINUTE-1, 10)+",,,,"+strconv.FormatInt(START, 10)+",,0", lines[1])
+
+	// Columns round trip.
+	columns := &Columns{}
+	for _, kline := range klines {
+		require.NoError(s.T(), columns.Append(kline))
+	}
+	output.Reset()
+	require.NoError(s.T(), columns.WriteJSON(&output))
+	read, err := ReadColumns(&output)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 3, read.Len())
+	for i, kline := range klines {
+		require.Equal(s.T(), kline, read.Kline(i))
+	}
+
+	_, err = ReadColumns(strings.NewReader(`{"openTime":[1],"close":[]}`))
+	require.Error(s.T(), err)
+}
+
+func (s *BackfillUnitTestSuite) TestUnit_Fetch_APIClient() {
+	server, err := ryskfake.NewServer(&ryskfake.ServerConfiguration{})
+	require.NoError(s.T(), err)
+	defer server.Close()
+
+	newAPIClient := func() *api_client.RyskV2APIClient {
+		privateKey, err := crypto.GenerateKey()
+		require.NoError(s.T(), err)
+		client, err := api_client.NewRyskV2APIClient(&api_client.RyskV2APIClientConfiguration{
+			Env:        constants.ENVIRONMENT_TESTNET,
+			PrivateKey: hex.EncodeToString(crypto.FromECDSA(privateKey)),
+			RpcUrl:     server.URL(),
+			BaseUrl:    server.URL(),
+		})
+		require.NoError(s.T(), err)
+		return client
+	}
+
+	// Trade once.
+	for i, client := range []*api_client.RyskV2APIClient{newAPIClient(), newAPIClient()} {
+		_, err := client.NewOrder(&types.NewOrderRequest{
+			Product:     &constants.PRODUCT_ETH_PERP,
+			IsBuy:       i == 0,
+			OrderType:   constants.ORDER_TYPE_LIMIT,
+			TimeInForce: constants.TIME_IN_FORCE_GTC,
+			Price:       new(big.Int).Mul(big.NewInt(3000), constants.E18).String(),
+			Quantity:    constants.E18.String(),
+			Expiration:  time.Now().Add(time.Hour).UnixMilli(),
+			Nonce:       int64(i + 1),
+		})
+		require.NoError(s.T(), err)
+	}
+
+	backfiller := s.newBackfiller(newAPIClient())
+	klines, report, err := backfiller.Fetch(context.Background(), &BackfillRequest{
+		Product:   &constants.PRODUCT_ETH_PERP,
+		Interval:  constants.INTERVAL_1H,
+		StartTime: time.Now().Add(-24 * time.Hour).UnixMilli(),
+	})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), klines, 1)
+	require.Equal(s.T(), constants.E18.String(), klines[0].Volume)
+	require.Equal(s.T(), int64(1), report.Requests)
+	require.Len(s.T(), report.Missing, 1)
+	require.Equal(s.T(), int64(24), report.Missing[0].Bars)
+}
@@ -0,0 +1,170 @@
+package backfill
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+// CSV_HEADER is the header row written by CSVWriter.
+var CSV_HEADER = []string{"symbol", "interval", "openTime", "closeTime", "open", "high", "low", "close", "volume", "trades"}
+
+// CSVWriter writes klines as CSV rows, prices and volumes in wei (e18).
+type CSVWriter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+// NewCSVWriter creates a new CSVWriter instance.
+//
+// Parameters:
+//   - writer: The output, the header row is written before the first kline.
+//
+// Returns:
+//   - A pointer to CSVWriter, to be flushed with `Flush`.
+func NewCSVWriter(writer io.Writer) *CSVWriter {
+	return &CSVWriter{writer: csv.NewWriter(writer)}
+}
+
+// Write appends a kline row. It can be passed to `Backfiller.Stream` as callback.
+//
+// Parameters:
+//   - kline: The kline.
+//
+// Returns:
+//   - An error if the row cannot be written.
+func (writer *CSVWriter) Write(kline types.Kline) error {
+	if !writer.headerWritten {
+		if err := writer.writer.Write(CSV_HEADER); err != nil {
+			return fmt.Errorf("failed to write header: %v", err)
+		}
+		writer.headerWritten = true
+	}
+	row := []string{
+		kline.Symbol,
+		string(kline.Interval),
+		strconv.FormatInt(kline.OpenTime, 10),
+		strconv.FormatInt(kline.CloseTime, 10),
+		kline.Open,
+		kline.High,
+		kline.Low,
+		kline.Close,
+		kline.Volume,
+		strconv.FormatInt(kline.Trades, 10),
+	}
+	if err := writer.writer.Write(row); err != nil {
+		return fmt.Errorf("failed to write kline: %v", err)
+	}
+	return nil
+}
+
+// Flush writes buffered rows to the output.
+//
+// Returns:
+//   - An error if the rows cannot be written.
+func (writer *CSVWriter) Flush() error {
+	writer.writer.Flush()
+	return writer.writer.Error()
+}
+
+// Columns holds klines column by column, one slice per field, as columnar formats store them.
+type Columns struct {
+	Symbol    []string         `json:"symbol"`
+	Interval  []types.Interval `json:"interval"`
+	OpenTime  []int64          `json:"openTime"`
+	CloseTime []int64          `json:"closeTime"`
+	Open      []string         `json:"open"`
+	High      []string         `json:"high"`
+	Low       []string         `json:"low"`
+	Close     []string         `json:"close"`
+	Volume    []string         `json:"volume"`
+	Trades    []int64          `json:"trades"`
+}
+
+// Append adds a kline to every column. It can be passed to `Backfiller.Stream` as callback.
+//
+// Parameters:
+//   - kline: The kline.
+//
+// Returns:
+//   - Always nil.
+func (columns *Columns) Append(kline types.Kline) error {
+	columns.Symbol = append(columns.Symbol, kline.Symbol)
+	columns.Interval = append(columns.Interval, kline.Interval)
+	columns.OpenTime = append(columns.OpenTime, kline.OpenTime)
+	columns.CloseTime = append(columns.CloseTime, kline.CloseTime)
+	columns.Open = append(columns.Open, kline.Open)
+	columns.High = append(columns.High, kline.High)
+	columns.Low = append(columns.Low, kline.Low)
+	columns.Close = append(columns.Close, kline.Close)
+	columns.Volume = append(columns.Volume, kline.Volume)
+	columns.Trades = append(columns.Trades, kline.Trades)
+	return nil
+}
+
+// Len returns the number of klines held.
+func (columns *Columns) Len() int {
+	return len(columns.OpenTime)
+}
+
+// Kline returns the kline at an index.
+//
+// Parameters:
+//   - index: The index, from 0 to `Len() - 1`.
+//
+// Returns:
+//   - The kline.
+func (columns *Columns) Kline(index int) types.Kline {
+	return types.Kline{
+		Symbol:    columns.Symbol[index],
+		Interval:  columns.Interval[index],
+		OpenTime:  columns.OpenTime[index],
+		CloseTime: columns.CloseTime[index],
+		Open:      columns.Open[index],
+		High:      columns.High[index],
+		Low:       columns.Low[index],
+		Close:     columns.Close[index],
+		Volume:    columns.Volume[index],
+		Trades:    columns.Trades[index],
+	}
+}
+
+// WriteJSON writes the columns as one JSON object of arrays, keyed by field name.
+//
+// Parameters:
+//   - writer: The output.
+//
+// Returns:
+//   - An error if the columns cannot be written.
+func (columns *Columns) WriteJSON(writer io.Writer) error {
+	if err := json.NewEncoder(writer).Encode(columns); err != nil {
+		return fmt.Errorf("failed to write columns: %v", err)
+	}
+	return nil
+}
+
+// ReadColumns reads columns written by `Columns.WriteJSON`.
+//
+// Parameters:
+//   - reader: The input.
+//
+// Returns:
+//   - A pointer to Columns.
+//   - An error if the input cannot be decoded or if its columns differ in length.
+func ReadColumns(reader io.Reader) (*Columns, error) {
+	var columns Columns
+	if err := json.NewDecoder(reader).Decode(&columns); err != nil {
+		return nil, fmt.Errorf("failed to read columns: %v", err)
+	}
+	length := len(columns.OpenTime)
+	for _, columnLength := range []int{len(columns.Symbol), len(columns.Interval), len(columns.CloseTime), len(columns.Open), len(columns.High), len(columns.Low), len(columns.Close), len(columns.Volume), len(columns.Trades)} {
+		if columnLength != length {
+			return nil, fmt.Errorf("columns differ in length")
+		}
+	}
+	return &columns, nil
+}
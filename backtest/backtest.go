@@ -0,0 +1,370 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/trading"
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+const (
+	DEFAULT_EQUITY_INTERVAL time.Duration = time.Hour
+	YEAR                    time.Duration = 365 * 24 * time.Hour
+)
+
+// BacktestConfiguration holds the configuration for a backtest.
+type BacktestConfiguration struct {
+	Source         IEventSource                      // Market data replayed in event-time order.
+	Strategy       trading.IStrategy                 // Strategy under test.
+	Paper          *trading.PaperTraderConfiguration // Optional simulator settings: fill model, fees, slippage, initial balance and products. `Now` and `OnFill` are set by the backtest.
+	Latency        time.Duration                     // Delay between a strategy action and its arrival at the simulated exchange.
+	EquityInterval time.Duration                     // Minimum interval between equity curve points. Defaults to `DEFAULT_EQUITY_INTERVAL`.
+}
+
+// EquityPoint is a point of the equity curve.
+type EquityPoint struct {
+	Time   int64    `json:"time"`   // UNIX timestamp (in ms) of the point.
+	Equity *big.Int `json:"equity"` // Collateral balance plus unrealized PnL at the last marks in wei (e18).
+}
+
+// Metrics summarizes a backtest.
+type Metrics struct {
+	TotalReturn float64  `json:"totalReturn"` // Final equity over initial equity, minus one.
+	SharpeRatio float64  `json:"sharpeRatio"` // Annualized mean over standard deviation of equity curve returns, without risk-free rate.
+	MaxDrawdown float64  `json:"maxDrawdown"` // Largest fall of the equity curve from a peak, as a fraction of the peak.
+	Turnover    float64  `json:"turnover"`    // Traded notional over initial equity.
+	Volume      *big.Int `json:"volume"`      // Traded notional in wei (e18).
+	Fees        *big.Int `json:"fees"`        // Fees paid in wei (e18), net of rebates.
+	Fills       int      `json:"fills"`       // Number of fills.
+}
+
+// Result holds the outcome of a backtest.
+type Result struct {
+	Equity  []EquityPoint  `json:"equity"`  // Equity curve.
+	Fills   []trading.Fill `json:"fills"`   // Simulated fills in execution order.
+	Metrics Metrics        `json:"metrics"` // Summary metrics.
+}
+
+// Backtest replays market data to a strategy trading against a simulated exchange.
+type Backtest struct {
+	source         IEventSource
+	strategy       trading.IStrategy
+	latency        time.Duration
+	equityInterval time.Duration
+	simulator      *trading.PaperTrader
+	trader         *backtestTrader
+	symbols        map[string]int64   // symbols maps product symbols to IDs.
+	clock          int64              // clock is the simulated time in ms.
+	lastTime       int64              // lastTime is the time of the last event read from the source.
+	pending        []*Event           // pending holds events read ahead of the strategy.
+	applied        int                // applied is the number of pending events already applied to the simulator.
+	exhausted      bool               // exhausted is set once the source returned `io.EOF`.
+	marks          map[int64]*big.Int // marks holds the last price per product ID.
+	fills          []trading.Fill
+	equity         []EquityPoint
+}
+
+// backtestTrader is the ITrader handed to the strategy. Actions reach the simulator after the configured latency.
+type backtestTrader struct {
+	backtest *Backtest
+}
+
+// NewBacktest creates a new Backtest instance.
+//
+// Parameters:
+//   - config: A pointer to BacktestConfiguration containing the configuration settings.
+//
+// Returns:
+//   - A pointer to Backtest.
+//   - An error if the source or the strategy is missing, or if the simulator cannot be created.
+func NewBacktest(config *BacktestConfiguration) (*Backtest, error) {
+	if config.Source == nil {
+		return nil, fmt.Errorf("backtest requires an event source")
+	}
+	if config.Strategy == nil {
+		return nil, fmt.Errorf("backtest requires a strategy")
+	}
+
+	backtest := &Backtest{
+		source:         config.Source,
+		strategy:       config.Strategy,
+		latency:        config.Latency,
+		equityInterval: config.EquityInterval,
+		symbols:        make(map[string]int64),
+		marks:          make(map[int64]*big.Int),
+	}
+	if backtest.equityInterval == 0 {
+		backtest.equityInterval = DEFAULT_EQUITY_INTERVAL
+	}
+	backtest.trader = &backtestTrader{backtest: backtest}
+
+	// Drive the simulator with the replay clock.
+	paperConfig := trading.PaperTraderConfiguration{}
+	if config.Paper != nil {
+		paperConfig = *config.Paper
+	}
+	paperConfig.Now = func() time.Time { return time.UnixMilli(backtest.clock) }
+	paperConfig.OnFill = func(fill *trading.Fill) { backtest.fills = append(backtest.fills, *fill) }
+	simulator, err := trading.NewPaperTrader(&paperConfig)
+	if err != nil {
+		return nil, err
+	}
+	backtest.simulator = simulator
+
+	products := paperConfig.Products
+	if len(products) == 0 {
+		products = trading.DEFAULT_PRODUCTS
+	}
+	for _, product := range products {
+		backtest.symbols[product.Symbol] = product.Id
+	}
+	return backtest, nil
+}
+
+// Run replays every event of the source to the strategy and summarizes the outcome. A backtest runs once.
+//
+// Returns:
+//   - A pointer to the Result.
+//   - An error if the source fails, if an event cannot be applied or if the strategy returns an error.
+func (backtest *Backtest) Run() (*Result, error) {
+	for {
+		// Read and apply the next event unless an earlier strategy action already did.
+		if len(backtest.pending) == 0 {
+			if err := backtest.read(); err != nil {
+				return nil, err
+			}
+			if backtest.exhausted {
+				break
+			}
+		}
+		if backtest.applied == 0 {
+			if err := backtest.apply(backtest.pending[0]); err != nil {
+				return nil, err
+			}
+			backtest.applied++
+		}
+		event := backtest.pending[0]
+		backtest.pending = backtest.pending[1:]
+		backtest.applied--
+		backtest.clock = max(backtest.clock, event.Time)
+
+		// Sample equity as of the event, then let the strategy act on it.
+		if err := backtest.sample(false); err != nil {
+			return nil, err
+		}
+		if err := backtest.strategy.OnMarketEvent(context.Background(), backtest.trader, &event.MarketEvent); err != nil {
+			return nil, fmt.Errorf("strategy failed at %d: %w", event.Time, err)
+		}
+	}
+	if err := backtest.sample(true); err != nil {
+		return nil, err
+	}
+	return &Result{
+		Equity:  backtest.equity,
+		Fills:   backtest.fills,
+		Metrics: computeMetrics(backtest.equity, backtest.fills, backtest.equityInterval),
+	}, nil
+}
+
+// read reads the next event from the source into the pending queue.
+func (backtest *Backtest) read() error {
+	event, err := backtest.source.Next()
+	if errors.Is(err, io.EOF) {
+		backtest.exhausted = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read event: %v", err)
+	}
+	if event.Time < backtest.lastTime {
+		return fmt.Errorf("event at %d is older than previous event at %d", event.Time, backtest.lastTime)
+	}
+	backtest.lastTime = event.Time
+	backtest.pending = append(backtest.pending, event)
+	return nil
+}
+
+// apply feeds an event to the simulator and updates the marks.
+func (backtest *Backtest) apply(event *Event) error {
+	backtest.clock = max(backtest.clock, event.Time)
+	if err := backtest.simulator.HandleMarketEvent(&event.MarketEvent); err != nil {
+		return fmt.Errorf("failed to apply event at %d: %v", event.Time, err)
+	}
+
+	var symbol, mark string
+	switch {
+	case event.Trade != nil:
+		symbol, mark = event.Trade.Symbol, event.Trade.Price
+	case event.Kline != nil:
+		symbol, mark = event.Kline.Symbol, event.Kline.Close
+	case event.Depth != nil:
+		symbol, mark = event.Depth.Symbol, midPrice(event.Depth)
+	}
+	if price, ok := new(big.Int).SetString(mark, 10); ok {
+		if productId, ok := backtest.symbols[symbol]; ok {
+			backtest.marks[productId] = price
+		} else if event.Trade != nil {
+			backtest.marks[event.Trade.ProductId] = price
+		}
+	}
+	return nil
+}
+
+// advance applies pending and upcoming events up to time, moving the market while an action is in flight.
+func (backtest *Backtest) advance(time int64) error {
+	for {
+		if backtest.applied == len(backtest.pending) {
+			if backtest.exhausted {
+				return nil
+			}
+			if err := backtest.read(); err != nil {
+				return err
+			}
+			if backtest.exhausted {
+				return nil
+			}
+		}
+		event := backtest.pending[backtest.applied]
+		if event.Time > time {
+			return nil
+		}
+		if err := backtest.apply(event); err != nil {
+			return err
+		}
+		backtest.applied++
+	}
+}
+
+// arrive moves the simulation to the arrival time of an action sent now.
+func (backtest *Backtest) arrive() error {
+	arrival := backtest.clock + backtest.latency.Milliseconds()
+	if err := backtest.advance(arrival); err != nil {
+		return err
+	}
+	backtest.clock = max(backtest.clock, arrival)
+	return nil
+}
+
+// sample appends an equity point when the equity interval elapsed, or unconditionally when final.
+func (backtest *Backtest) sample(final bool) error {
+	if len(backtest.equity) > 0 {
+		elapsed := backtest.clock - backtest.equity[len(backtest.equity)-1].Time
+		if (final && elapsed == 0) || (!final && elapsed < backtest.equityInterval.Milliseconds()) {
+			return nil
+		}
+	}
+
+	equity, err := backtest.currentEquity()
+	if err != nil {
+		return err
+	}
+	backtest.equity = append(backtest.equity, EquityPoint{Time: backtest.clock, Equity: equity})
+	return nil
+}
+
+// currentEquity values the simulated account at the last marks.
+func (backtest *Backtest) currentEquity() (*big.Int, error) {
+	equity := new(big.Int)
+	balances, _ := backtest.simulator.SpotBalances(context.Background())
+	for _, balance := range balances {
+		quantity, ok := new(big.Int).SetString(balance.Quantity, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid balance %q", balance.Quantity)
+		}
+		equity.Add(equity, quantity)
+	}
+
+	positions, _ := backtest.simulator.PerpetualPositions(context.Background())
+	for _, position := range positions {
+		mark, ok := backtest.marks[position.ProductId]
+		if !ok {
+			continue
+		}
+		quantity, ok := new(big.Int).SetString(position.Quantity, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid position quantity %q", position.Quantity)
+		}
+		entry, ok := new(big.Int).SetString(position.AvgEntryPrice, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid position entry price %q", position.AvgEntryPrice)
+		}
+		unrealized := new(big.Int).Sub(mark, entry)
+		unrealized.Mul(unrealized, quantity)
+		equity.Add(equity, unrealized.Quo(unrealized, constants.E18))
+	}
+	return equity, nil
+}
+
+// NewOrder sends a new order to the simulator after the latency.
+func (trader *backtestTrader) NewOrder(ctx context.Context, params *types.NewOrderRequest) (*types.Order, error) {
+	if err := trader.backtest.arrive(); err != nil {
+		return nil, err
+	}
+	return trader.backtest.simulator.NewOrder(ctx, params)
+}
+
+// CancelOrderAndReplace sends a replacement to the simulator after the latency.
+func (trader *backtestTrader) CancelOrderAndReplace(ctx context.Context, params *types.CancelOrderAndReplaceRequest) (*types.Order, error) {
+	if err := trader.backtest.arrive(); err != nil {
+		return nil, err
+	}
+	return trader.backtest.simulator.CancelOrderAndReplace(ctx, params)
+}
+
+// CancelOrder sends a cancellation to the simulator after the latency.
+func (trader *backtestTrader) CancelOrder(ctx context.Context, params *types.CancelOrderRequest) (*types.Order, error) {
+	if err := trader.backtest.arrive(); err != nil {
+		return nil, err
+	}
+	return trader.backtest.simulator.CancelOrder(ctx, params)
+}
+
+// CancelAllOpenOrders sends a mass cancellation to the simulator after the latency.
+func (trader *backtestTrader) CancelAllOpenOrders(ctx context.Context, product *types.Product) ([]types.Order, error) {
+	if err := trader.backtest.arrive(); err != nil {
+		return nil, err
+	}
+	return trader.backtest.simulator.CancelAllOpenOrders(ctx, product)
+}
+
+// ListOpenOrders returns the simulated open orders.
+func (trader *backtestTrader) ListOpenOrders(ctx context.Context, product *types.Product) ([]types.Order, error) {
+	return trader.backtest.simulator.ListOpenOrders(ctx, product)
+}
+
+// PerpetualPositions returns the simulated positions.
+func (trader *backtestTrader) PerpetualPositions(ctx context.Context) ([]types.PerpetualPosition, error) {
+	return trader.backtest.simulator.PerpetualPositions(ctx)
+}
+
+// SpotBalances returns the simulated collateral balance.
+func (trader *backtestTrader) SpotBalances(ctx context.Context) ([]types.SpotBalance, error) {
+	return trader.backtest.simulator.SpotBalances(ctx)
+}
+
+// midPrice returns the mid of the best bid and ask, or the only side quoted, as a decimal string.
+func midPrice(depth *types.OrderBookDepth) string {
+	var bid, ask *big.Int
+	if len(depth.Bids) > 0 {
+		bid, _ = new(big.Int).SetString(depth.Bids[0][0], 10)
+	}
+	if len(depth.Asks) > 0 {
+		ask, _ = new(big.Int).SetString(depth.Asks[0][0], 10)
+	}
+	switch {
+	case bid != nil && ask != nil:
+		mid := new(big.Int).Add(bid, ask)
+		return mid.Quo(mid, big.NewInt(2)).String()
+	case bid != nil:
+		return bid.String()
+	case ask != nil:
+		return ask.String()
+	}
+	return ""
+}
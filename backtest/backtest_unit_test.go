@@ -0,0 +1,254 @@
+//go:build !integration
+// +build !integration
+
+package backtest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/trading"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const START int64 = 1700000000000
+
+type BacktestUnitTestSuite struct {
+	suite.Suite
+}
+
+func TestRunSuiteUnit_BacktestUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(BacktestUnitTestSuite))
+}
+
+// reversedSource yields events with decreasing times.
+type reversedSource struct {
+	times []int64
+}
+
+func (source *reversedSource) Next() (*Event, error) {
+	if len(source.times) == 0 {
+		return nil, io.EOF
+	}
+	time := source.times[0]
+	source.times = source.times[1:]
+	return &Event{MarketEvent: trading.MarketEvent{Trade: &types.Trade{Symbol: constants.PRODUCT_ETH_PERP.Symbol, Price: "1", Quantity: "1"}}, Time: time}, nil
+}
+
+func price(units int64) string {
+	return new(big.Int).Mul(big.NewInt(units), constants.E18).String()
+}
+
+func kline(hour int64, closePrice int64) types.Kline {
+	return types.Kline{
+		Symbol:    constants.PRODUCT_ETH_PERP.Symbol,
+		Interval:  constants.INTERVAL_1H,
+		OpenTime:  START + hour*time.Hour.Milliseconds(),
+		CloseTime: START + (hour+1)*time.Hour.Milliseconds() - 1,
+		Open:      price(closePrice),
+		High:      price(closePrice),
+		Low:       price(closePrice),
+		Close:     price(closePrice),
+		Volume:    constants.E19.String(),
+	}
+}
+
+func marketOrder(isBuy bool) *types.NewOrderRequest {
+	return &types.NewOrderRequest{
+		Product:     &constants.PRODUCT_ETH_PERP,
+		IsBuy:       isBuy,
+		OrderType:   constants.ORDER_TYPE_MARKET,
+		TimeInForce: constants.TIME_IN_FORCE_IOC,
+		Quantity:    constants.E18.String(),
+	}
+}
+
+// buyOnce buys one unit on the first event.
+func buyOnce() trading.IStrategy {
+	bought := false
+	return trading.StrategyFunc(func(ctx context.Context, trader trading.ITrader, event *trading.MarketEvent) error {
+		if bought {
+			return nil
+		}
+		bought = true
+		_, err := trader.NewOrder(ctx, marketOrder(true))
+		return err
+	})
+}
+
+func (s *BacktestUnitTestSuite) TestUnit_NewBacktest_Invalid() {
+	_, err := NewBacktest(&BacktestConfiguration{Strategy: buyOnce()})
+	require.Error(s.T(), err)
+
+	_, err = NewBacktest(&BacktestConfiguration{Source: NewSliceSource()})
+	require.Error(s.T(), err)
+
+	_, err = NewBacktest(&BacktestConfiguration{Source: NewSliceSource(), Strategy: buyOnce(), Paper: &trading.PaperTraderConfiguration{FillModel: "optimistic"}})
+	require.Error(s.T(), err)
+}
+
+func (s *BacktestUnitTestSuite) TestUnit_NewSliceSource() {
+	source := NewSliceSource(
+		KlineEvents([]types.Kline{kline(0, 3000), kline(1, 3100)}),
+		TradeEvents([]types.Trade{{Time: START + time.Hour.Milliseconds() - 1}, {Time: START}}),
+		[]Event{DepthEvent(START, types.OrderBookDepth{})},
+	)
+
+	var times []int64
+	var kinds []string
+	for {
+		event, err := source.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(s.T(), err)
+		times = append(times, event.Time)
+		switch {
+		case event.Kline != nil:
+			kinds = append(kinds, "kline")
+		case event.Trade != nil:
+			kinds = append(kinds, "trade")
+		case event.Depth != nil:
+			kinds = append(kinds, "depth")
+		}
+	}
+	hour := time.Hour.Milliseconds()
+	require.Equal(s.T(), []int64{START, START, START + hour - 1, START + hour - 1, START + 2*hour - 1}, times)
+	require.Equal(s.T(), []string{"trade", "depth", "kline", "trade", "kline"}, kinds)
+}
+
+func (s *BacktestUnitTestSuite) TestUnit_Run_Klines() {
+	backtest, err := NewBacktest(&BacktestConfiguration{
+		Source:   NewSliceSource(KlineEvents([]types.Kline{kline(0, 3000), kline(1, 3100), kline(2, 3050), kline(3, 3200)})),
+		Strategy: buyOnce(),
+		Paper:    &trading.PaperTraderConfiguration{InitialBalance: constants.E22, TakerFeeBps: 10},
+	})
+	require.NoError(s.T(), err)
+
+	result, err := backtest.Run()
+	require.NoError(s.T(), err)
+
+	// One unit bought at the first close, paying a 3 fee.
+	require.Len(s.T(), result.Fills, 1)
+	require.Equal(s.T(), price(3000), result.Fills[0].Price)
+	require.Equal(s.T(), price(3), result.Fills[0].Fee)
+	require.False(s.T(), result.Fills[0].IsMaker)
+
+	var equity []string
+	for _, point := range result.Equity {
+		equity = append(equity, point.Equity.String())
+	}
+	require.Equal(s.T(), []string{price(10000), price(10097), price(10047), price(10197)}, equity)
+
+	require.InDelta(s.T(), 0.0197, result.Metrics.TotalReturn, 1e-9)
+	require.InDelta(s.T(), 50.0/10097.0, result.Metrics.MaxDrawdown, 1e-9)
+	require.InDelta(s.T(), 0.3, result.Metrics.Turnover, 1e-9)
+	require.Equal(s.T(), price(3000), result.Metrics.Volume.String())
+	require.Equal(s.T(), price(3), result.Metrics.Fees.String())
+	require.Equal(s.T(), 1, result.Metrics.Fills)
+	require.NotZero(s.T(), result.Metrics.SharpeRatio)
+}
+
+func (s *BacktestUnitTestSuite) TestUnit_Run_Latency() {
+	events := []Event{
+		DepthEvent(START, types.OrderBookDepth{Symbol: constants.PRODUCT_ETH_PERP.Symbol, Asks: [][2]string{{price(3000), constants.E18.String()}}}),
+		DepthEvent(START+100, types.OrderBookDepth{Symbol: constants.PRODUCT_ETH_PERP.Symbol, Asks: [][2]string{{price(3020), constants.E18.String()}}}),
+		DepthEvent(START+300, types.OrderBookDepth{Symbol: constants.PRODUCT_ETH_PERP.Symbol, Asks: [][2]string{{price(3040), constants.E18.String()}}}),
+	}
+
+	run := func(latency time.Duration) (*Result, int) {
+		seen := 0
+		bought := false
+		strategy := trading.StrategyFunc(func(ctx context.Context, trader trading.ITrader, event *trading.MarketEvent) error {
+			seen++
+			if !bought {
+				bought = true
+				_, err := trader.NewOrder(ctx, marketOrder(true))
+				return err
+			}
+			return nil
+		})
+		backtest, err := NewBacktest(&BacktestConfiguration{Source: NewSliceSource(events), Strategy: strategy, Latency: latency})
+		require.NoError(s.T(), err)
+		result, err := backtest.Run()
+		require.NoError(s.T(), err)
+		return result, seen
+	}
+
+	result, seen := run(0)
+	require.Equal(s.T(), price(3000), result.Fills[0].Price)
+	require.Equal(s.T(), START, result.Fills[0].Time)
+	require.Equal(s.T(), 3, seen)
+
+	// The book moves while the order is in flight, the strategy still sees every event.
+	result, seen = run(200 * time.Millisecond)
+	require.Equal(s.T(), price(3020), result.Fills[0].Price)
+	require.Equal(s.T(), START+200, result.Fills[0].Time)
+	require.Equal(s.T(), 3, seen)
+}
+
+func (s *BacktestUnitTestSuite) TestUnit_Run_MakerFill() {
+	placed := false
+	strategy := trading.StrategyFunc(func(ctx context.Context, trader trading.ITrader, event *trading.MarketEvent) error {
+		if placed {
+			return nil
+		}
+		placed = true
+		_, err := trader.NewOrder(ctx, &types.NewOrderRequest{
+			Product:     &constants.PRODUCT_ETH_PERP,
+			IsBuy:       true,
+			OrderType:   constants.ORDER_TYPE_LIMIT,
+			TimeInForce: constants.TIME_IN_FORCE_GTC,
+			Price:       price(2990),
+			Quantity:    constants.E18.String(),
+		})
+		return err
+	})
+	trades := []types.Trade{
+		{Symbol: constants.PRODUCT_ETH_PERP.Symbol, Price: price(3000), Quantity: constants.E18.String(), Time: START},
+		{Symbol: constants.PRODUCT_ETH_PERP.Symbol, Price: price(2990), Quantity: constants.E18.String(), Time: START + 1000},
+	}
+	backtest, err := NewBacktest(&BacktestConfiguration{
+		Source:   NewSliceSource(TradeEvents(trades)),
+		Strategy: strategy,
+		Paper:    &trading.PaperTraderConfiguration{InitialBalance: constants.E22, MakerFeeBps: -1},
+	})
+	require.NoError(s.T(), err)
+
+	result, err := backtest.Run()
+	require.NoError(s.T(), err)
+	require.Len(s.T(), result.Fills, 1)
+	require.True(s.T(), result.Fills[0].IsMaker)
+	require.Equal(s.T(), START+1000, result.Fills[0].Time)
+	require.Equal(s.T(), "-299000000000000000", result.Metrics.Fees.String())
+}
+
+func (s *BacktestUnitTestSuite) TestUnit_Run_Errors() {
+	backtest, err := NewBacktest(&BacktestConfiguration{Source: &reversedSource{times: []int64{START + 1, START}}, Strategy: buyOnce()})
+	require.NoError(s.T(), err)
+	_, err = backtest.Run()
+	require.ErrorContains(s.T(), err, "older than previous event")
+
+	failure := errors.New("strategy failure")
+	strategy := trading.StrategyFunc(func(ctx context.Context, trader trading.ITrader, event *trading.MarketEvent) error {
+		return failure
+	})
+	backtest, err = NewBacktest(&BacktestConfiguration{Source: NewSliceSource(KlineEvents([]types.Kline{kline(0, 3000)})), Strategy: strategy})
+	require.NoError(s.T(), err)
+	_, err = backtest.Run()
+	require.ErrorIs(s.T(), err, failure)
+
+	unknown := kline(0, 3000)
+	unknown.Symbol = "dogeperp"
+	backtest, err = NewBacktest(&BacktestConfiguration{Source: NewSliceSource(KlineEvents([]types.Kline{unknown})), Strategy: buyOnce()})
+	require.NoError(s.T(), err)
+	_, err = backtest.Run()
+	require.ErrorContains(s.T(), err, "unknown product")
+}
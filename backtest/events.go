@@ -0,0 +1,97 @@
+package backtest
+
+import (
+	"io"
+	"sort"
+
+	"github.com/rysk-finance/v2_client_go/trading"
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+// Event is a market data event replayed at its event time.
+type Event struct {
+	trading.MarketEvent
+	Time int64 `json:"time"` // UNIX timestamp (in ms) at which the event becomes visible.
+}
+
+// IEventSource yields events in non-decreasing event time, and `io.EOF` once exhausted.
+type IEventSource interface {
+	Next() (*Event, error)
+}
+
+// sliceSource replays events held in memory.
+type sliceSource struct {
+	events []Event
+	index  int
+}
+
+// NewSliceSource merges event slices into a source ordered by event time.
+// Events sharing a time keep the order in which they were given.
+//
+// Parameters:
+//   - events: The event slices, e.g. built with `KlineEvents`, `TradeEvents` and `DepthEvent`.
+//
+// Returns:
+//   - An IEventSource replaying every event.
+func NewSliceSource(events ...[]Event) IEventSource {
+	var merged []Event
+	for _, slice := range events {
+		merged = append(merged, slice...)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Time < merged[j].Time
+	})
+	return &sliceSource{events: merged}
+}
+
+func (source *sliceSource) Next() (*Event, error) {
+	if source.index >= len(source.events) {
+		return nil, io.EOF
+	}
+	source.index++
+	return &source.events[source.index-1], nil
+}
+
+// KlineEvents converts klines, e.g. fetched with `backfill.Backfiller.Fetch`, into events visible at their close time.
+//
+// Parameters:
+//   - klines: The klines.
+//
+// Returns:
+//   - A slice of Event.
+func KlineEvents(klines []types.Kline) []Event {
+	events := make([]Event, 0, len(klines))
+	for i := range klines {
+		kline := klines[i]
+		events = append(events, Event{MarketEvent: trading.MarketEvent{Kline: &kline}, Time: kline.CloseTime})
+	}
+	return events
+}
+
+// TradeEvents converts public trades into events visible at their trade time.
+//
+// Parameters:
+//   - trades: The trades.
+//
+// Returns:
+//   - A slice of Event.
+func TradeEvents(trades []types.Trade) []Event {
+	events := make([]Event, 0, len(trades))
+	for i := range trades {
+		trade := trades[i]
+		events = append(events, Event{MarketEvent: trading.MarketEvent{Trade: &trade}, Time: trade.Time})
+	}
+	return events
+}
+
+// DepthEvent converts a depth snapshot, which carries no timestamp, into an event visible at time.
+//
+// Parameters:
+//   - time: UNIX timestamp (in ms) at which the snapshot was taken.
+//   - depth: The depth snapshot.
+//
+// Returns:
+//   - An Event.
+func DepthEvent(time int64, depth types.OrderBookDepth) Event {
+	return Event{MarketEvent: trading.MarketEvent{Depth: &depth}, Time: time}
+}
@@ -0,0 +1,96 @@
+package backtest
+
+import (
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/trading"
+)
+
+// computeMetrics summarizes an equity curve and its fills.
+func computeMetrics(equity []EquityPoint, fills []trading.Fill, interval time.Duration) Metrics {
+	metrics := Metrics{
+		Volume: new(big.Int),
+		Fees:   new(big.Int),
+		Fills:  len(fills),
+	}
+
+	// Traded notional and fees.
+	for _, fill := range fills {
+		price, _ := new(big.Int).SetString(fill.Price, 10)
+		quantity, _ := new(big.Int).SetString(fill.Quantity, 10)
+		fee, _ := new(big.Int).SetString(fill.Fee, 10)
+		if price == nil || quantity == nil || fee == nil {
+			continue
+		}
+		notional := new(big.Int).Mul(price, quantity)
+		metrics.Volume.Add(metrics.Volume, notional.Quo(notional, constants.E18))
+		metrics.Fees.Add(metrics.Fees, fee)
+	}
+	if len(equity) == 0 {
+		return metrics
+	}
+
+	initial := equity[0].Equity
+	final := equity[len(equity)-1].Equity
+	if initial.Sign() > 0 {
+		metrics.TotalReturn = ratio(final, initial) - 1
+		metrics.Turnover = ratio(metrics.Volume, initial)
+	}
+	metrics.MaxDrawdown = maxDrawdown(equity)
+	metrics.SharpeRatio = sharpeRatio(equity, interval)
+	return metrics
+}
+
+// maxDrawdown returns the largest fall of the curve from a peak, as a fraction of the peak.
+func maxDrawdown(equity []EquityPoint) float64 {
+	var drawdown float64
+	peak := equity[0].Equity
+	for _, point := range equity {
+		if point.Equity.Cmp(peak) > 0 {
+			peak = point.Equity
+		}
+		if peak.Sign() <= 0 {
+			continue
+		}
+		fall := new(big.Int).Sub(peak, point.Equity)
+		drawdown = math.Max(drawdown, ratio(fall, peak))
+	}
+	return drawdown
+}
+
+// sharpeRatio returns the annualized mean over standard deviation of the returns between curve points.
+func sharpeRatio(equity []EquityPoint, interval time.Duration) float64 {
+	var returns []float64
+	for i := 1; i < len(equity); i++ {
+		if equity[i-1].Equity.Sign() > 0 {
+			returns = append(returns, ratio(equity[i].Equity, equity[i-1].Equity)-1)
+		}
+	}
+	if len(returns) < 2 {
+		return 0
+	}
+
+	var mean float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+	var variance float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	variance /= float64(len(returns) - 1)
+	if variance == 0 {
+		return 0
+	}
+	return mean / math.Sqrt(variance) * math.Sqrt(float64(YEAR)/float64(interval))
+}
+
+// ratio divides two integers as floats.
+func ratio(numerator *big.Int, denominator *big.Int) float64 {
+	result, _ := new(big.Float).Quo(new(big.Float).SetInt(numerator), new(big.Float).SetInt(denominator)).Float64()
+	return result
+}
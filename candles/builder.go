@@ -0,0 +1,359 @@
+package candles
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+type BarType string
+
+const (
+	BAR_TYPE_TIME   BarType = "time"   // Bars covering fixed time intervals.
+	BAR_TYPE_TICK   BarType = "tick"   // Bars of a fixed number of trades.
+	BAR_TYPE_VOLUME BarType = "volume" // Bars closing once their traded quantity reaches a threshold.
+	BAR_TYPE_DOLLAR BarType = "dollar" // Bars closing once their traded notional reaches a threshold.
+)
+
+// BuilderConfiguration holds the configuration for a bar builder.
+type BuilderConfiguration struct {
+	Symbol    string                   // Symbol of the product, trades of other products are ignored. Defaults to the symbol of the first trade.
+	Type      BarType                  // The bar type.
+	Interval  time.Duration            // Bar duration of `BAR_TYPE_TIME` bars, a multiple of a millisecond.
+	Ticks     int64                    // Number of trades of `BAR_TYPE_TICK` bars.
+	Threshold *big.Int                 // Quantity of `BAR_TYPE_VOLUME` bars, or notional of `BAR_TYPE_DOLLAR` bars, in wei (e18).
+	Lateness  time.Duration            // How long trades are held back to restore their time order. Trades older than the most recent time minus Lateness are late.
+	OnBar     func(bar *types.Kline)   // Callback invoked with each completed bar.
+	OnLate    func(trade *types.Trade) // Optional callback invoked with each trade dropped for being late.
+}
+
+// pendingTrade is a trade held back until it can no longer be overtaken.
+type pendingTrade struct {
+	time     int64
+	price    *big.Int
+	quantity *big.Int
+}
+
+// bar is a bar being built.
+type bar struct {
+	openTime  int64
+	closeTime int64
+	open      *big.Int
+	high      *big.Int
+	low       *big.Int
+	close     *big.Int
+	volume    *big.Int
+	notional  *big.Int
+	trades    int64
+}
+
+// Builder aggregates trades into bars. Trades may arrive out of order by up to `Lateness`:
+// they are held until the most recent trade time, or the time passed to `Advance`, is `Lateness` ahead,
+// then aggregated in time order.
+type Builder struct {
+	mutex     sync.Mutex
+	symbol    string
+	barType   BarType
+	interval  int64
+	ticks     int64
+	threshold *big.Int
+	lateness  int64
+	onBar     func(bar *types.Kline)
+	onLate    func(trade *types.Trade)
+	pending   []*pendingTrade  // pending holds the trades held back, by time then arrival.
+	seen      map[string]int64 // seen maps the IDs of trades not yet final to their time, to drop duplicates.
+	watermark int64            // watermark is the most recent time seen.
+	released  int64            // released is the time up to which trades were aggregated, older trades are late.
+	current   *bar             // current is the bar being built, nil between bars.
+	late      int64            // late counts late trades.
+}
+
+// NewBuilder creates a new Builder instance.
+//
+// Parameters:
+//   - config: A pointer to BuilderConfiguration containing the configuration settings.
+//
+// Returns:
+//   - A pointer to Builder.
+//   - An error if the bar type or its size is invalid, or if no OnBar callback is provided.
+func NewBuilder(config *BuilderConfiguration) (*Builder, error) {
+	if config.OnBar == nil {
+		return nil, fmt.Errorf("builder requires an OnBar callback")
+	}
+	switch config.Type {
+	case BAR_TYPE_TIME:
+		if config.Interval < time.Millisecond || config.Interval%time.Millisecond != 0 {
+			return nil, fmt.Errorf("invalid interval %v", config.Interval)
+		}
+	case BAR_TYPE_TICK:
+		if config.Ticks <= 0 {
+			return nil, fmt.Errorf("invalid ticks %d", config.Ticks)
+		}
+	case BAR_TYPE_VOLUME, BAR_TYPE_DOLLAR:
+		if config.Threshold == nil || config.Threshold.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid threshold %v", config.Threshold)
+		}
+	default:
+		return nil, fmt.Errorf("unknown bar type %q", config.Type)
+	}
+	if config.Lateness < 0 {
+		return nil, fmt.Errorf("invalid lateness %v", config.Lateness)
+	}
+
+	return &Builder{
+		symbol:    config.Symbol,
+		barType:   config.Type,
+		interval:  config.Interval.Milliseconds(),
+		ticks:     config.Ticks,
+		threshold: config.Threshold,
+		lateness:  config.Lateness.Milliseconds(),
+		onBar:     config.OnBar,
+		onLate:    config.OnLate,
+		seen:      make(map[string]int64),
+		released:  -1,
+	}, nil
+}
+
+// AddTrade adds a trade, e.g. from a `@trade` or `@aggTrade` stream or a recording. Trades are
+// dropped as duplicates when their ID was already added, so a builder should be fed one of the streams.
+//
+// Parameters:
+//   - trade: The trade.
+//
+// Returns:
+//   - An error if the trade price or quantity is invalid.
+func (builder *Builder) AddTrade(trade *types.Trade) error {
+	price, ok := new(big.Int).SetString(trade.Price, 10)
+	if !ok {
+		return fmt.Errorf("invalid trade price %q", trade.Price)
+	}
+	quantity, ok := new(big.Int).SetString(trade.Quantity, 10)
+	if !ok {
+		return fmt.Errorf("invalid trade quantity %q", trade.Quantity)
+	}
+
+	builder.mutex.Lock()
+	var bars []*types.Kline
+	var lateTrade *types.Trade
+	defer func() {
+		builder.mutex.Unlock()
+		if lateTrade != nil && builder.onLate != nil {
+			builder.onLate(lateTrade)
+		}
+		for _, bar := range bars {
+			builder.onBar(bar)
+		}
+	}()
+
+	// Filter trades.
+	if builder.symbol == "" {
+		builder.symbol = trade.Symbol
+	}
+	if trade.Symbol != "" && trade.Symbol != builder.symbol {
+		return nil
+	}
+	if trade.Id != "" {
+		if _, ok := builder.seen[trade.Id]; ok {
+			return nil
+		}
+	}
+	if trade.Time < builder.released {
+		builder.late++
+		lateTrade = trade
+		return nil
+	}
+	if trade.Id != "" {
+		builder.seen[trade.Id] = trade.Time
+	}
+
+	// Hold the trade back in time order.
+	pending := &pendingTrade{time: trade.Time, price: price, quantity: quantity}
+	index := sort.Search(len(builder.pending), func(i int) bool {
+		return builder.pending[i].time > pending.time
+	})
+	builder.pending = append(builder.pending, nil)
+	copy(builder.pending[index+1:], builder.pending[index:])
+	builder.pending[index] = pending
+
+	builder.watermark = max(builder.watermark, trade.Time)
+	bars = builder.release(builder.watermark - builder.lateness)
+	return nil
+}
+
+// HandleStreamMessage adds the trade of a raw `@trade` or `@aggTrade` stream message, other messages are ignored.
+//
+// Parameters:
+//   - body: The JSON message, `{"stream": "<symbol>@<topic>", "data": ...}`.
+//
+// Returns:
+//   - An error if the trade cannot be decoded or is invalid.
+func (builder *Builder) HandleStreamMessage(body []byte) error {
+	var message struct {
+		Stream string          `json:"stream"`
+		Data   json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &message); err != nil {
+		return nil
+	}
+	symbol, topic, _ := strings.Cut(message.Stream, "@")
+	if topic != "trade" && topic != "aggTrade" {
+		return nil
+	}
+	var trade types.Trade
+	if err := json.Unmarshal(message.Data, &trade); err != nil {
+		return fmt.Errorf("failed to decode trade: %v", err)
+	}
+	if trade.Symbol == "" {
+		trade.Symbol = symbol
+	}
+	return builder.AddTrade(&trade)
+}
+
+// Advance moves time forward without a trade, e.g. on a clock tick, completing the time bars
+// that can no longer receive trades.
+//
+// Parameters:
+//   - now: UNIX timestamp (in ms) of the current time.
+func (builder *Builder) Advance(now int64) {
+	builder.mutex.Lock()
+	builder.watermark = max(builder.watermark, now)
+	bars := builder.release(builder.watermark - builder.lateness)
+	builder.mutex.Unlock()
+
+	for _, bar := range bars {
+		builder.onBar(bar)
+	}
+}
+
+// Flush aggregates every trade held back and completes the bar being built, even if partial.
+func (builder *Builder) Flush() {
+	builder.mutex.Lock()
+	bars := builder.release(builder.watermark)
+	if builder.current != nil {
+		bars = append(bars, builder.complete())
+	}
+	builder.mutex.Unlock()
+
+	for _, bar := range bars {
+		builder.onBar(bar)
+	}
+}
+
+// Late returns the number of trades dropped for being late.
+func (builder *Builder) Late() int64 {
+	builder.mutex.Lock()
+	defer builder.mutex.Unlock()
+	return builder.late
+}
+
+// release aggregates the trades up to a time, which later trades may no longer precede, and returns the
+// completed bars. The caller must hold the mutex.
+func (builder *Builder) release(until int64) []*types.Kline {
+	if until < builder.released {
+		return nil
+	}
+
+	var bars []*types.Kline
+	count := 0
+	for _, pending := range builder.pending {
+		if pending.time > until {
+			break
+		}
+		count++
+		bars = append(bars, builder.aggregate(pending)...)
+	}
+	builder.pending = builder.pending[count:]
+	builder.released = until
+
+	// Time bars ending before the released time are complete.
+	if builder.barType == BAR_TYPE_TIME && builder.current != nil && builder.current.closeTime < until {
+		bars = append(bars, builder.complete())
+	}
+
+	// Trades before the released time are final, duplicates would be late.
+	for id, time := range builder.seen {
+		if time < until {
+			delete(builder.seen, id)
+		}
+	}
+	return bars
+}
+
+// aggregate adds a trade to the bar being built and returns the completed bars. The caller must hold the mutex.
+func (builder *Builder) aggregate(trade *pendingTrade) []*types.Kline {
+	var bars []*types.Kline
+
+	// Time bars complete when a trade falls in a later interval.
+	openTime, closeTime := trade.time, trade.time
+	if builder.barType == BAR_TYPE_TIME {
+		openTime = trade.time - trade.time%builder.interval
+		closeTime = openTime + builder.interval - 1
+		if builder.current != nil && builder.current.openTime != openTime {
+			bars = append(bars, builder.complete())
+		}
+	}
+
+	// Add the trade.
+	current := builder.current
+	if current == nil {
+		current = &bar{
+			openTime: openTime,
+			open:     trade.price,
+			high:     trade.price,
+			low:      trade.price,
+			volume:   new(big.Int),
+			notional: new(big.Int),
+		}
+		builder.current = current
+	}
+	current.closeTime = closeTime
+	if trade.price.Cmp(current.high) > 0 {
+		current.high = trade.price
+	}
+	if trade.price.Cmp(current.low) < 0 {
+		current.low = trade.price
+	}
+	current.close = trade.price
+	current.volume.Add(current.volume, trade.quantity)
+	notional := new(big.Int).Mul(trade.price, trade.quantity)
+	current.notional.Add(current.notional, notional.Quo(notional, constants.E18))
+	current.trades++
+
+	// Other bars complete once their size is reached.
+	switch {
+	case builder.barType == BAR_TYPE_TICK && current.trades >= builder.ticks,
+		builder.barType == BAR_TYPE_VOLUME && current.volume.Cmp(builder.threshold) >= 0,
+		builder.barType == BAR_TYPE_DOLLAR && current.notional.Cmp(builder.threshold) >= 0:
+		bars = append(bars, builder.complete())
+	}
+	return bars
+}
+
+// complete returns the bar being built as a kline and starts a new one. The caller must hold the mutex.
+func (builder *Builder) complete() *types.Kline {
+	current := builder.current
+	builder.current = nil
+
+	kline := &types.Kline{
+		Symbol:    builder.symbol,
+		OpenTime:  current.openTime,
+		CloseTime: current.closeTime,
+		Open:      current.open.String(),
+		High:      current.high.String(),
+		Low:       current.low.String(),
+		Close:     current.close.String(),
+		Volume:    current.volume.String(),
+		Trades:    current.trades,
+	}
+	if builder.barType == BAR_TYPE_TIME {
+		kline.Interval = FormatInterval(time.Duration(builder.interval) * time.Millisecond)
+	}
+	return kline
+}
@@ -0,0 +1,259 @@
+//go:build !integration
+// +build !integration
+
+package candles
+
+import (
+	"math/big"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const START int64 = 1700000040000 // START is aligned to the minute.
+
+type CandlesUnitTestSuite struct {
+	suite.Suite
+	bars []*types.Kline
+	late []*types.Trade
+}
+
+func (s *CandlesUnitTestSuite) SetupTest() {
+	s.bars = nil
+	s.late = nil
+}
+
+func TestRunSuiteUnit_CandlesUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(CandlesUnitTestSuite))
+}
+
+func (s *CandlesUnitTestSuite) newBuilder(config BuilderConfiguration) *Builder {
+	config.OnBar = func(bar *types.Kline) { s.bars = append(s.bars, bar) }
+	config.OnLate = func(trade *types.Trade) { s.late = append(s.late, trade) }
+	builder, err := NewBuilder(&config)
+	require.NoError(s.T(), err)
+	return builder
+}
+
+func price(units int64) string {
+	return new(big.Int).Mul(big.NewInt(units), constants.E18).String()
+}
+
+func trade(id string, at int64, tradePrice int64, quantity int64) *types.Trade {
+	return &types.Trade{
+		Id:       id,
+		Symbol:   constants.PRODUCT_ETH_PERP.Symbol,
+		Price:    price(tradePrice),
+		Quantity: new(big.Int).Mul(big.NewInt(quantity), constants.E18).String(),
+		Time:     at,
+	}
+}
+
+func (s *CandlesUnitTestSuite) add(builder *Builder, trades ...*types.Trade) {
+	for _, trade := range trades {
+		require.NoError(s.T(), builder.AddTrade(trade))
+	}
+}
+
+func (s *CandlesUnitTestSuite) TestUnit_NewBuilder_Invalid() {
+	onBar := func(bar *types.Kline) {}
+	for _, config := range []BuilderConfiguration{
+		{Type: BAR_TYPE_TIME, Interval: time.Second},
+		{Type: BAR_TYPE_TIME, Interval: time.Microsecond, OnBar: onBar},
+		{Type: BAR_TYPE_TICK, OnBar: onBar},
+		{Type: BAR_TYPE_VOLUME, OnBar: onBar},
+		{Type: BAR_TYPE_DOLLAR, Threshold: big.NewInt(-1), OnBar: onBar},
+		{Type: "range", OnBar: onBar},
+		{Type: BAR_TYPE_TICK, Ticks: 1, Lateness: -time.Second, OnBar: onBar},
+	} {
+		_, err := NewBuilder(&config)
+		require.Error(s.T(), err, config)
+	}
+}
+
+func (s *CandlesUnitTestSuite) TestUnit_TimeBars() {
+	builder := s.newBuilder(BuilderConfiguration{Type: BAR_TYPE_TIME, Interval: 10 * time.Second})
+	s.add(builder,
+		trade("1", START, 3000, 1),
+		trade("2", START+4000, 3010, 2),
+		trade("3", START+9999, 2990, 1),
+	)
+	require.Empty(s.T(), s.bars)
+
+	// The bar completes with the first trade of the next interval, empty intervals are skipped.
+	s.add(builder, trade("4", START+25000, 3005, 1))
+	require.Len(s.T(), s.bars, 1)
+	require.Equal(s.T(), types.Kline{
+		Symbol:    constants.PRODUCT_ETH_PERP.Symbol,
+		Interval:  "10s",
+		OpenTime:  START,
+		CloseTime: START + 9999,
+		Open:      price(3000),
+		High:      price(3010),
+		Low:       price(2990),
+		Close:     price(2990),
+		Volume:    new(big.Int).Mul(big.NewInt(4), constants.E18).String(),
+		Trades:    3,
+	}, *s.bars[0])
+
+	// Advancing past the interval completes the bar without a trade.
+	builder.Advance(START + 29999)
+	require.Len(s.T(), s.bars, 1)
+	builder.Advance(START + 30000)
+	require.Len(s.T(), s.bars, 2)
+	require.Equal(s.T(), START+20000, s.bars[1].OpenTime)
+	require.Equal(s.T(), price(3005), s.bars[1].Close)
+}
+
+func (s *CandlesUnitTestSuite) TestUnit_OutOfOrderTrades() {
+	builder := s.newBuilder(BuilderConfiguration{Type: BAR_TYPE_TIME, Interval: time.Minute, Lateness: 5 * time.Second})
+
+	// Trades within the lateness are aggregated in time order.
+	s.add(builder,
+		trade("1", START+1000, 3000, 1),
+		trade("3", START+59000, 3020, 1),
+		trade("2", START+56000, 3050, 1),
+		trade("4", START+61000, 3100, 1),
+		trade("2", START+56000, 3050, 1),
+		trade("5", START+58000, 2900, 1),
+	)
+	require.Empty(s.T(), s.bars)
+
+	// The first bar completes once the lateness has passed its end.
+	s.add(builder, trade("6", START+65000, 3200, 1))
+	require.Len(s.T(), s.bars, 1)
+	require.Equal(s.T(), price(3000), s.bars[0].Open)
+	require.Equal(s.T(), price(3050), s.bars[0].High)
+	require.Equal(s.T(), price(2900), s.bars[0].Low)
+	require.Equal(s.T(), price(3020), s.bars[0].Close)
+	require.Equal(s.T(), int64(4), s.bars[0].Trades)
+
+	// Older trades are late.
+	s.add(builder, trade("7", START+59999, 1, 1))
+	require.Equal(s.T(), int64(1), builder.Late())
+	require.Len(s.T(), s.late, 1)
+	require.Equal(s.T(), "7", s.late[0].Id)
+
+	// Flush completes the partial bar.
+	builder.Flush()
+	require.Len(s.T(), s.bars, 2)
+	require.Equal(s.T(), price(3100), s.bars[1].Open)
+	require.Equal(s.T(), price(3200), s.bars[1].Close)
+}
+
+func (s *CandlesUnitTestSuite) TestUnit_TickVolumeDollarBars() {
+	// Tick bars.
+	builder := s.newBuilder(BuilderConfiguration{Type: BAR_TYPE_TICK, Ticks: 2})
+	for i := int64(0); i < 5; i++ {
+		s.add(builder, trade(strconv.FormatInt(i, 10), START+i, 3000+i, 1))
+	}
+	require.Len(s.T(), s.bars, 2)
+	require.Equal(s.T(), START, s.bars[0].OpenTime)
+	require.Equal(s.T(), START+1, s.bars[0].CloseTime)
+	require.Equal(s.T(), types.Interval(""), s.bars[0].Interval)
+	require.Equal(s.T(), price(3003), s.bars[1].Close)
+
+	// Volume bars, a trade crossing the threshold is not split.
+	s.SetupTest()
+	builder = s.newBuilder(BuilderConfiguration{Type: BAR_TYPE_VOLUME, Threshold: new(big.Int).Mul(big.NewInt(3), constants.E18)})
+	s.add(builder, trade("1", START, 3000, 1), trade("2", START+1, 3000, 1), trade("3", START+2, 3000, 5), trade("4", START+3, 3000, 1))
+	require.Len(s.T(), s.bars, 1)
+	require.Equal(s.T(), new(big.Int).Mul(big.NewInt(7), constants.E18).String(), s.bars[0].Volume)
+
+	// Dollar bars.
+	s.SetupTest()
+	builder = s.newBuilder(BuilderConfiguration{Type: BAR_TYPE_DOLLAR, Threshold: new(big.Int).Mul(big.NewInt(5000), constants.E18)})
+	s.add(builder, trade("1", START, 2000, 1), trade("2", START+1, 2000, 1), trade("3", START+2, 2000, 1), trade("4", START+3, 2000, 1))
+	require.Len(s.T(), s.bars, 1)
+	require.Equal(s.T(), int64(3), s.bars[0].Trades)
+
+	// Trades of other products and invalid trades are rejected.
+	s.add(builder, &types.Trade{Symbol: constants.PRODUCT_BTC_PERP.Symbol, Price: "1", Quantity: "1", Time: START + 4})
+	builder.Flush()
+	require.Len(s.T(), s.bars, 2)
+	require.Equal(s.T(), int64(1), s.bars[1].Trades)
+	require.Error(s.T(), builder.AddTrade(&types.Trade{Price: "x", Quantity: "1"}))
+	require.Error(s.T(), builder.AddTrade(&types.Trade{Price: "1", Quantity: "x"}))
+}
+
+func (s *CandlesUnitTestSuite) TestUnit_HandleStreamMessage() {
+	builder := s.newBuilder(BuilderConfiguration{Type: BAR_TYPE_TICK, Ticks: 1})
+	require.NoError(s.T(), builder.HandleStreamMessage([]byte(`{"stream":"ethperp@aggTrade","data":{"id":"1","price":"5","quantity":"1","time":1}}`)))
+	require.NoError(s.T(), builder.HandleStreamMessage([]byte(`{"stream":"ethperp@ticker","data":{}}`)))
+	require.NoError(s.T(), builder.HandleStreamMessage([]byte(`{"jsonrpc":"2.0","id":"1","success":true}`)))
+	require.Error(s.T(), builder.HandleStreamMessage([]byte(`{"stream":"ethperp@trade","data":[]}`)))
+	require.Len(s.T(), s.bars, 1)
+	require.Equal(s.T(), "ethperp", s.bars[0].Symbol)
+	require.Equal(s.T(), "5", s.bars[0].Close)
+}
+
+func (s *CandlesUnitTestSuite) TestUnit_FormatInterval() {
+	require.Equal(s.T(), types.Interval("10s"), FormatInterval(10*time.Second))
+	require.Equal(s.T(), types.Interval("3m"), FormatInterval(3*time.Minute))
+	require.Equal(s.T(), types.Interval("90m"), FormatInterval(90*time.Minute))
+	require.Equal(s.T(), constants.INTERVAL_D1, FormatInterval(24*time.Hour))
+	require.Equal(s.T(), constants.INTERVAL_1W, FormatInterval(7*24*time.Hour))
+	require.Equal(s.T(), types.Interval("250ms"), FormatInterval(250*time.Millisecond))
+}
+
+func (s *CandlesUnitTestSuite) TestUnit_Resample() {
+	kline := func(minute int64, open int64, high int64, low int64, closePrice int64) types.Kline {
+		openTime := START + minute*time.Minute.Milliseconds()
+		return types.Kline{
+			Symbol:    constants.PRODUCT_ETH_PERP.Symbol,
+			Interval:  constants.INTERVAL_1M,
+			OpenTime:  openTime,
+			CloseTime: openTime + time.Minute.Milliseconds() - 1,
+			Open:      price(open),
+			High:      price(high),
+			Low:       price(low),
+			Close:     price(closePrice),
+			Volume:    constants.E18.String(),
+			Trades:    2,
+		}
+	}
+
+	// START is minute 2 of a 3m interval. Given out of order with a duplicate.
+	resampled, err := Resample([]types.Kline{
+		kline(2, 3010, 3030, 3000, 3020),
+		kline(0, 3000, 3010, 2990, 3005),
+		kline(1, 3005, 3050, 3000, 3010),
+		kline(1, 3005, 3040, 3000, 3015),
+		kline(5, 3100, 3100, 3100, 3100),
+	}, 3*time.Minute)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), resampled, 3)
+
+	minute := time.Minute.Milliseconds()
+	require.Equal(s.T(), types.Kline{
+		Symbol:    constants.PRODUCT_ETH_PERP.Symbol,
+		Interval:  "3m",
+		OpenTime:  START - 2*minute,
+		CloseTime: START + minute - 1,
+		Open:      price(3000),
+		High:      price(3010),
+		Low:       price(2990),
+		Close:     price(3005),
+		Volume:    constants.E18.String(),
+		Trades:    2,
+	}, resampled[0])
+	require.Equal(s.T(), price(3005), resampled[1].Open)
+	require.Equal(s.T(), price(3040), resampled[1].High)
+	require.Equal(s.T(), price(3020), resampled[1].Close)
+	require.Equal(s.T(), new(big.Int).Mul(big.NewInt(2), constants.E18).String(), resampled[1].Volume)
+	require.Equal(s.T(), START+4*minute, resampled[2].OpenTime)
+
+	_, err = Resample([]types.Kline{kline(0, 1, 1, 1, 1)}, 90*time.Second)
+	require.Error(s.T(), err)
+	_, err = Resample(nil, 0)
+	require.Error(s.T(), err)
+	invalid := kline(0, 1, 1, 1, 1)
+	invalid.High = "x"
+	_, err = Resample([]types.Kline{invalid}, 3*time.Minute)
+	require.Error(s.T(), err)
+}
@@ -0,0 +1,137 @@
+package candles
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+// intervalUnits are the units of formatted intervals, largest first.
+var intervalUnits = []struct {
+	suffix   string
+	duration time.Duration
+}{
+	{"w", 7 * 24 * time.Hour},
+	{"d", 24 * time.Hour},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+	{"ms", time.Millisecond},
+}
+
+// FormatInterval formats a duration in the largest unit dividing it, e.g. `10s`, `3m` or `2d`,
+// as the intervals of `constants/orders.go`.
+//
+// Parameters:
+//   - duration: The duration, a multiple of a millisecond.
+//
+// Returns:
+//   - The interval.
+func FormatInterval(duration time.Duration) types.Interval {
+	for _, unit := range intervalUnits {
+		if duration >= unit.duration && duration%unit.duration == 0 {
+			return types.Interval(strconv.FormatInt(int64(duration/unit.duration), 10) + unit.suffix)
+		}
+	}
+	return types.Interval(duration.String())
+}
+
+// Resample aggregates klines into klines of a coarser interval, e.g. `GetKlineData` 1m klines into 3m klines.
+// Klines are sorted by open time, duplicates keep the last one given. Resampled klines are aligned to
+// UNIX time and only cover intervals holding at least one source kline.
+//
+// Parameters:
+//   - klines: The source klines, of one product and interval.
+//   - interval: The target interval, a multiple of the source interval.
+//
+// Returns:
+//   - The resampled klines, oldest first.
+//   - An error if the target interval is not a multiple of the source interval, or if a kline is invalid.
+func Resample(klines []types.Kline, interval time.Duration) ([]types.Kline, error) {
+	width := interval.Milliseconds()
+	if width <= 0 || interval%time.Millisecond != 0 {
+		return nil, fmt.Errorf("invalid interval %v", interval)
+	}
+
+	// Sort and dedupe source klines.
+	sorted := make([]types.Kline, 0, len(klines))
+	byOpenTime := make(map[int64]int)
+	for _, kline := range klines {
+		if index, ok := byOpenTime[kline.OpenTime]; ok {
+			sorted[index] = kline
+			continue
+		}
+		byOpenTime[kline.OpenTime] = len(sorted)
+		sorted = append(sorted, kline)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].OpenTime < sorted[j].OpenTime
+	})
+
+	var resampled []types.Kline
+	var current *bar
+	var symbol string
+	complete := func() {
+		resampled = append(resampled, types.Kline{
+			Symbol:    symbol,
+			Interval:  FormatInterval(interval),
+			OpenTime:  current.openTime,
+			CloseTime: current.closeTime,
+			Open:      current.open.String(),
+			High:      current.high.String(),
+			Low:       current.low.String(),
+			Close:     current.close.String(),
+			Volume:    current.volume.String(),
+			Trades:    current.trades,
+		})
+	}
+	for _, kline := range sorted {
+		sourceWidth := kline.CloseTime - kline.OpenTime + 1
+		if sourceWidth <= 0 || width%sourceWidth != 0 {
+			return nil, fmt.Errorf("interval %v is not a multiple of kline %d interval", interval, kline.OpenTime)
+		}
+		values := make([]*big.Int, 5)
+		for i, value := range []string{kline.Open, kline.High, kline.Low, kline.Close, kline.Volume} {
+			parsed, ok := new(big.Int).SetString(value, 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid value %q in kline %d", value, kline.OpenTime)
+			}
+			values[i] = parsed
+		}
+
+		// Start a new bar when the kline falls in a later interval.
+		openTime := kline.OpenTime - kline.OpenTime%width
+		if current != nil && current.openTime != openTime {
+			complete()
+			current = nil
+		}
+		if current == nil {
+			symbol = kline.Symbol
+			current = &bar{
+				openTime:  openTime,
+				closeTime: openTime + width - 1,
+				open:      values[0],
+				high:      values[1],
+				low:       values[2],
+				volume:    new(big.Int),
+			}
+		}
+		if values[1].Cmp(current.high) > 0 {
+			current.high = values[1]
+		}
+		if values[2].Cmp(current.low) < 0 {
+			current.low = values[2]
+		}
+		current.close = values[3]
+		current.volume.Add(current.volume, values[4])
+		current.trades += kline.Trades
+	}
+	if current != nil {
+		complete()
+	}
+	return resampled, nil
+}
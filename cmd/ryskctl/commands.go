@@ -0,0 +1,648 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rysk-finance/v2_client_go/api_client"
+	"github.com/rysk-finance/v2_client_go/audit"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+)
+
+// flagSet creates the flag set of a command, printing errors and usage to stderr.
+func (cli *ryskctl) flagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet("ryskctl "+name, flag.ContinueOnError)
+	flags.SetOutput(cli.stderr)
+	return flags
+}
+
+// parse parses the flags of a command and checks that the required ones are set.
+//
+// Returns:
+//   - `errUsage` if the flags are invalid or a required one is missing, the usage having been printed.
+func (cli *ryskctl) parse(flags *flag.FlagSet, args []string, required ...string) error {
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintf(cli.stderr, "%s: unexpected argument %q\n", flags.Name(), flags.Arg(0))
+		flags.Usage()
+		return errUsage
+	}
+	for _, name := range required {
+		if flags.Lookup(name).Value.String() == "" {
+			fmt.Fprintf(cli.stderr, "%s: -%s is required\n", flags.Name(), name)
+			flags.Usage()
+			return errUsage
+		}
+	}
+	return nil
+}
+
+// subcommand splits the subcommand from the arguments of a command.
+func (cli *ryskctl) subcommand(name string, args []string, subcommands ...string) (string, []string, error) {
+	if len(args) > 0 {
+		for _, subcommand := range subcommands {
+			if args[0] == subcommand {
+				return subcommand, args[1:], nil
+			}
+		}
+		fmt.Fprintf(cli.stderr, "ryskctl %s: unknown subcommand %q\n", name, args[0])
+	}
+	fmt.Fprintf(cli.stderr, "Usage: ryskctl %s %s\n", name, strings.Join(subcommands, "|"))
+	return "", nil, errUsage
+}
+
+// decode decodes the JSON body of a response.
+func decode[T any](res *http.Response, err error) (T, error) {
+	var result T
+	if err != nil {
+		return result, err
+	}
+	err = utils.DecodeHTTPResponse(res, &result)
+	return result, err
+}
+
+// product resolves a product by symbol.
+func product(ctx context.Context, client *api_client.RyskV2APIClient, symbol string) (*types.Product, error) {
+	product, err := decode[types.Product](client.GetProductCtx(ctx, symbol))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product %s: %v", symbol, err)
+	}
+	return &product, nil
+}
+
+func (cli *ryskctl) products(ctx context.Context, args []string) error {
+	if err := cli.parse(cli.flagSet("products"), args); err != nil {
+		return err
+	}
+	client, err := cli.client()
+	if err != nil {
+		return err
+	}
+	products, err := decode[[]map[string]interface{}](client.ListProductsCtx(ctx))
+	if err != nil {
+		return err
+	}
+
+	// Columns are the fields of the products, ID and symbol first.
+	fields := map[string]bool{}
+	for _, product := range products {
+		for field := range product {
+			fields[field] = true
+		}
+	}
+	header := []string{"id", "symbol"}
+	delete(fields, "id")
+	delete(fields, "symbol")
+	others := make([]string, 0, len(fields))
+	for field := range fields {
+		others = append(others, field)
+	}
+	sort.Strings(others)
+	header = append(header, others...)
+	rows := make([][]string, 0, len(products))
+	for _, product := range products {
+		row := make([]string, 0, len(header))
+		for _, field := range header {
+			row = append(row, fmt.Sprint(product[field]))
+		}
+		rows = append(rows, row)
+	}
+	for i := range header {
+		header[i] = strings.ToUpper(header[i])
+	}
+	return cli.print(products, header, rows)
+}
+
+func (cli *ryskctl) ticker(ctx context.Context, args []string) error {
+	flags := cli.flagSet("ticker")
+	symbol := flags.String("product", "", "product symbol, all products when empty")
+	if err := cli.parse(flags, args); err != nil {
+		return err
+	}
+	client, err := cli.client()
+	if err != nil {
+		return err
+	}
+
+	var tickers []types.Ticker
+	if *symbol == "" {
+		tickers, err = decode[[]types.Ticker](client.Get24hrPriceChangeStatisticsCtx(ctx, &types.Product{}))
+	} else {
+		var tickerProduct *types.Product
+		if tickerProduct, err = product(ctx, client, *symbol); err != nil {
+			return err
+		}
+		var ticker types.Ticker
+		ticker, err = decode[types.Ticker](client.Get24hrPriceChangeStatisticsCtx(ctx, tickerProduct))
+		tickers = []types.Ticker{ticker}
+	}
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(tickers))
+	for _, ticker := range tickers {
+		rows = append(rows, []string{
+			ticker.Symbol,
+			formatE18(ticker.LastPrice),
+			formatE18(ticker.OpenPrice),
+			formatE18(ticker.HighPrice),
+			formatE18(ticker.LowPrice),
+			formatE18(ticker.PriceChange),
+			formatE18(ticker.Volume),
+		})
+	}
+	return cli.print(tickers, []string{"SYMBOL", "LAST", "OPEN", "HIGH", "LOW", "CHANGE", "VOLUME"}, rows)
+}
+
+func (cli *ryskctl) book(ctx context.Context, args []string) error {
+	flags := cli.flagSet("book")
+	symbol := flags.String("product", "", "product symbol")
+	limit := flags.Int64("limit", int64(constants.LIMIT_TEN), "number of levels per side: 5, 10 or 20")
+	granularity := flags.Int64("granularity", 0, "number of decimals removed from prices")
+	if err := cli.parse(flags, args, "product"); err != nil {
+		return err
+	}
+	client, err := cli.client()
+	if err != nil {
+		return err
+	}
+	bookProduct, err := product(ctx, client, *symbol)
+	if err != nil {
+		return err
+	}
+	depth, err := decode[types.OrderBookDepth](client.OrderBookCtx(ctx, &types.OrderBookRequest{
+		Product:     bookProduct,
+		Granularity: *granularity,
+		Limit:       types.Limit(*limit),
+	}))
+	if err != nil {
+		return err
+	}
+
+	// Bids and asks side by side, best first.
+	rows := make([][]string, max(len(depth.Bids), len(depth.Asks)))
+	for i := range rows {
+		rows[i] = []string{"", "", "", ""}
+		if i < len(depth.Bids) {
+			rows[i][0], rows[i][1] = formatE18(depth.Bids[i][1]), formatE18(depth.Bids[i][0])
+		}
+		if i < len(depth.Asks) {
+			rows[i][2], rows[i][3] = formatE18(depth.Asks[i][0]), formatE18(depth.Asks[i][1])
+		}
+	}
+	return cli.print(depth, []string{"BID QUANTITY", "BID", "ASK", "ASK QUANTITY"}, rows)
+}
+
+func (cli *ryskctl) klines(ctx context.Context, args []string) error {
+	flags := cli.flagSet("klines")
+	symbol := flags.String("product", "", "product symbol")
+	interval := flags.String("interval", string(constants.INTERVAL_1H), "kline interval, e.g. 1m, 1h or 1d")
+	start := flags.String("start", "", "start time, as a UNIX timestamp in ms or an RFC 3339 time")
+	end := flags.String("end", "", "end time, as a UNIX timestamp in ms or an RFC 3339 time")
+	limit := flags.Int64("limit", 0, "number of klines, up to 1000")
+	if err := cli.parse(flags, args, "product"); err != nil {
+		return err
+	}
+	if _, ok := constants.INTERVAL_DURATIONS[types.Interval(*interval)]; !ok {
+		return fmt.Errorf("invalid interval %q", *interval)
+	}
+	startTime, err := parseTime(*start)
+	if err != nil {
+		return err
+	}
+	endTime, err := parseTime(*end)
+	if err != nil {
+		return err
+	}
+	client, err := cli.client()
+	if err != nil {
+		return err
+	}
+	klineProduct, err := product(ctx, client, *symbol)
+	if err != nil {
+		return err
+	}
+	klines, err := decode[[]types.Kline](client.GetKlineDataCtx(ctx, &types.KlineDataRequest{
+		Product:   klineProduct,
+		Interval:  types.Interval(*interval),
+		StartTime: startTime,
+		EndTime:   endTime,
+		Limit:     *limit,
+	}))
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(klines))
+	for _, kline := range klines {
+		rows = append(rows, []string{
+			formatTime(kline.OpenTime),
+			formatE18(kline.Open),
+			formatE18(kline.High),
+			formatE18(kline.Low),
+			formatE18(kline.Close),
+			formatE18(kline.Volume),
+			strconv.FormatInt(kline.Trades, 10),
+		})
+	}
+	return cli.print(klines, []string{"OPEN TIME", "OPEN", "HIGH", "LOW", "CLOSE", "VOLUME", "TRADES"}, rows)
+}
+
+func (cli *ryskctl) orders(ctx context.Context, args []string) error {
+	subcommand, args, err := cli.subcommand("orders", args, "list", "place", "cancel", "cancel-all")
+	if err != nil {
+		return err
+	}
+	flags := cli.flagSet("orders " + subcommand)
+	symbol := flags.String("product", "", "product symbol")
+	var required []string
+	var isBuy, orderType, timeInForce, price, quantity, id *string
+	var expiry *time.Duration
+	switch subcommand {
+	case "list":
+		flags.Lookup("product").Usage = "product symbol, all products when empty"
+	case "place":
+		isBuy = flags.String("side", "", "buy or sell")
+		orderType = flags.String("type", "limit", "order type: limit, limit-maker, market, stop-loss, stop-loss-limit, take-profit or take-profit-limit")
+		timeInForce = flags.String("tif", "gtc", "time in force: gtc, fok or ioc")
+		price = flags.String("price", "", "limit price, or worst price of market orders, in USDC")
+		quantity = flags.String("quantity", "", "quantity in product units")
+		expiry = flags.Duration("expiry", 24*time.Hour, "time after which the order is no longer active")
+		required = []string{"product", "side", "price", "quantity"}
+	case "cancel":
+		id = flags.String("id", "", "ID of the order")
+		required = []string{"product", "id"}
+	case "cancel-all":
+		required = []string{"product"}
+	}
+	if err := cli.parse(flags, args, required...); err != nil {
+		return err
+	}
+	client, err := cli.client()
+	if err != nil {
+		return err
+	}
+	var orderProduct *types.Product
+	if *symbol != "" {
+		if orderProduct, err = product(ctx, client, *symbol); err != nil {
+			return err
+		}
+	}
+
+	var orders []types.Order
+	switch subcommand {
+	case "list":
+		if orderProduct == nil {
+			orders, err = decode[[]types.Order](client.ListOpenOrdersAllProductsCtx(ctx))
+		} else {
+			orders, err = decode[[]types.Order](client.ListOpenOrdersCtx(ctx, orderProduct))
+		}
+
+	case "place":
+		request := &types.NewOrderRequest{Product: orderProduct, Expiration: time.Now().Add(*expiry).UnixMilli(), Nonce: time.Now().UnixMilli()}
+		if *isBuy != "buy" && *isBuy != "sell" {
+			return fmt.Errorf("invalid side %q: must be buy or sell", *isBuy)
+		}
+		request.IsBuy = *isBuy == "buy"
+		var ok bool
+		if request.OrderType, ok = ORDER_TYPES[*orderType]; !ok {
+			return fmt.Errorf("invalid order type %q", *orderType)
+		}
+		if request.TimeInForce, ok = TIMES_IN_FORCE[*timeInForce]; !ok {
+			return fmt.Errorf("invalid time in force %q", *timeInForce)
+		}
+		priceE18, priceErr := parseAmount(*price, 18)
+		if priceErr != nil {
+			return priceErr
+		}
+		quantityE18, quantityErr := parseAmount(*quantity, 18)
+		if quantityErr != nil {
+			return quantityErr
+		}
+		request.Price, request.Quantity = priceE18.String(), quantityE18.String()
+		if err := cli.confirm("Place %s %s order of %s %s at %s (%s)?", *isBuy, *orderType, *quantity, orderProduct.Symbol, *price, strings.ToUpper(*timeInForce)); err != nil {
+			return err
+		}
+		var order types.Order
+		order, err = decode[types.Order](client.NewOrderCtx(ctx, request))
+		orders = []types.Order{order}
+
+	case "cancel":
+		if err := cli.confirm("Cancel order %s on %s?", *id, orderProduct.Symbol); err != nil {
+			return err
+		}
+		var order types.Order
+		order, err = decode[types.Order](client.CancelOrderCtx(ctx, &types.CancelOrderRequest{Product: orderProduct, IdToCancel: *id}))
+		orders = []types.Order{order}
+
+	case "cancel-all":
+		if err := cli.confirm("Cancel all open orders on %s?", orderProduct.Symbol); err != nil {
+			return err
+		}
+		orders, err = decode[[]types.Order](client.CancelAllOpenOrdersCtx(ctx, orderProduct))
+	}
+	if err != nil {
+		return err
+	}
+
+	header, rows := orderRows(orders)
+	if subcommand == "place" || subcommand == "cancel" {
+		return cli.print(orders[0], header, rows)
+	}
+	return cli.print(orders, header, rows)
+}
+
+func (cli *ryskctl) positions(ctx context.Context, args []string) error {
+	if err := cli.parse(cli.flagSet("positions"), args); err != nil {
+		return err
+	}
+	client, err := cli.client()
+	if err != nil {
+		return err
+	}
+	positions, err := decode[[]types.PerpetualPosition](client.GetPerpetualPositionAllProductsCtx(ctx))
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(positions))
+	for _, position := range positions {
+		rows = append(rows, []string{
+			productSymbol(position.ProductId),
+			formatE18(position.Quantity),
+			formatE18(position.AvgEntryPrice),
+			formatE18(position.Margin),
+		})
+	}
+	return cli.print(positions, []string{"PRODUCT", "QUANTITY", "ENTRY PRICE", "MARGIN"}, rows)
+}
+
+func (cli *ryskctl) balances(ctx context.Context, args []string) error {
+	if err := cli.parse(cli.flagSet("balances"), args); err != nil {
+		return err
+	}
+	client, err := cli.client()
+	if err != nil {
+		return err
+	}
+	balances, err := decode[[]types.SpotBalance](client.GetSpotBalancesCtx(ctx))
+	if err != nil {
+		return err
+	}
+	header, rows := balanceRows(balances)
+	return cli.print(balances, header, rows)
+}
+
+// balanceRows returns the table of spot balances.
+func balanceRows(balances []types.SpotBalance) ([]string, [][]string) {
+	rows := make([][]string, 0, len(balances))
+	for _, balance := range balances {
+		rows = append(rows, []string{balance.Asset, formatE18(balance.Quantity), formatE18(balance.PendingWithdrawal)})
+	}
+	return []string{"ASSET", "QUANTITY", "PENDING WITHDRAWAL"}, rows
+}
+
+func (cli *ryskctl) signers(ctx context.Context, args []string) error {
+	subcommand, args, err := cli.subcommand("signers", args, "list", "approve", "revoke")
+	if err != nil {
+		return err
+	}
+	flags := cli.flagSet("signers " + subcommand)
+	var address *string
+	var required []string
+	if subcommand != "list" {
+		address = flags.String("address", "", "address of the signer")
+		required = []string{"address"}
+	}
+	if err := cli.parse(flags, args, required...); err != nil {
+		return err
+	}
+	if address != nil && !common.IsHexAddress(*address) {
+		return fmt.Errorf("invalid address %q", *address)
+	}
+	client, err := cli.client()
+	if err != nil {
+		return err
+	}
+
+	var signers []types.ApprovedSigner
+	switch subcommand {
+	case "list":
+		signers, err = decode[[]types.ApprovedSigner](client.ListApprovedSignersCtx(ctx))
+	case "approve", "revoke":
+		if err := cli.confirm("%s signer %s on sub-account %d?", strings.ToUpper(subcommand[:1])+subcommand[1:], *address, cli.config.SubAccountId); err != nil {
+			return err
+		}
+		request := &types.ApproveRevokeSignerRequest{ApprovedSigner: *address, Nonce: time.Now().UnixMilli()}
+		send := client.ApproveSignerCtx
+		if subcommand == "revoke" {
+			send = client.RevokeSignerCtx
+		}
+		var signer types.ApprovedSigner
+		signer, err = decode[types.ApprovedSigner](send(ctx, request))
+		signers = []types.ApprovedSigner{signer}
+	}
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(signers))
+	for _, signer := range signers {
+		rows = append(rows, []string{signer.Signer, strconv.FormatBool(signer.Approved)})
+	}
+	if subcommand != "list" {
+		return cli.print(signers[0], []string{"SIGNER", "APPROVED"}, rows)
+	}
+	return cli.print(signers, []string{"SIGNER", "APPROVED"}, rows)
+}
+
+func (cli *ryskctl) deposit(ctx context.Context, args []string) error {
+	flags := cli.flagSet("deposit")
+	amount := flags.String("amount", "", "amount of USDC")
+	if err := cli.parse(flags, args, "amount"); err != nil {
+		return err
+	}
+	client, err := cli.client()
+	if err != nil {
+		return err
+	}
+
+	// The amount is in USDC token units.
+	decimals, err := utils.GetDecimals(ctx, client.EthClient, client.USDCAddress())
+	if err != nil {
+		return fmt.Errorf("failed to get USDC decimals: %v", err)
+	}
+	quantity, err := parseAmount(*amount, decimals)
+	if err != nil {
+		return err
+	}
+	if err := cli.confirm("Deposit %s USDC from %s to sub-account %d on %s?", *amount, client.Address().Hex(), cli.config.SubAccountId, cli.config.Env); err != nil {
+		return err
+	}
+	result, err := client.Deposit(ctx, quantity)
+	if err != nil {
+		return err
+	}
+
+	rows := [][]string{}
+	if result.ApproveTransaction != nil {
+		rows = append(rows, []string{"approve", result.ApproveTransaction.Hash().Hex(), result.ApproveReceipt.BlockNumber.String()})
+	}
+	rows = append(rows, []string{"deposit", result.DepositTransaction.Hash().Hex(), result.DepositReceipt.BlockNumber.String()})
+	return cli.print(result, []string{"STEP", "TRANSACTION", "BLOCK"}, rows)
+}
+
+func (cli *ryskctl) withdraw(ctx context.Context, args []string) error {
+	flags := cli.flagSet("withdraw")
+	amount := flags.String("amount", "", "amount of USDC")
+	if err := cli.parse(flags, args, "amount"); err != nil {
+		return err
+	}
+	quantity, err := parseAmount(*amount, 18)
+	if err != nil {
+		return err
+	}
+	client, err := cli.client()
+	if err != nil {
+		return err
+	}
+	if err := cli.confirm("Withdraw %s USDC from sub-account %d to %s on %s?", *amount, cli.config.SubAccountId, client.Address().Hex(), cli.config.Env); err != nil {
+		return err
+	}
+	balance, err := decode[types.SpotBalance](client.WithdrawCtx(ctx, &types.WithdrawRequest{Quantity: quantity.String(), Nonce: time.Now().UnixMilli()}))
+	if err != nil {
+		return err
+	}
+	header, rows := balanceRows([]types.SpotBalance{balance})
+	return cli.print(balance, header, rows)
+}
+
+func (cli *ryskctl) stream(ctx context.Context, args []string) error {
+	flags := cli.flagSet("stream")
+	channel := flags.String("channel", "", "stream: trades, aggtrades, depth, ticker, klines or account")
+	symbols := flags.String("product", "", "comma separated product symbols, not used by account")
+	interval := flags.String("interval", string(constants.INTERVAL_1M), "kline interval of the klines stream")
+	limit := flags.Int64("limit", int64(constants.LIMIT_FIVE), "number of levels per side of the depth stream: 5, 10 or 20")
+	granularity := flags.Int64("granularity", 0, "number of decimals removed from prices of the depth stream")
+	count := flags.Int("count", 0, "number of messages to print before exiting, 0 to run until interrupted")
+	if err := cli.parse(flags, args, "channel"); err != nil {
+		return err
+	}
+	var products []*types.Product
+	if *channel != "account" {
+		if *symbols == "" {
+			fmt.Fprintf(cli.stderr, "%s: -product is required\n", flags.Name())
+			flags.Usage()
+			return errUsage
+		}
+		client, err := cli.client()
+		if err != nil {
+			return err
+		}
+		for _, symbol := range strings.Split(*symbols, ",") {
+			streamProduct, err := product(ctx, client, strings.TrimSpace(symbol))
+			if err != nil {
+				return err
+			}
+			products = append(products, streamProduct)
+		}
+	}
+
+	client, err := cli.wsClient()
+	if err != nil {
+		return err
+	}
+	defer client.RPCConnection.Close()
+	defer client.StreamConnection.Close()
+
+	// Subscribe, account updates coming from the RPC connection.
+	const messageId = "ryskctl"
+	connection := client.StreamConnection
+	switch *channel {
+	case "trades":
+		err = client.SubscribeSingleTradesCtx(ctx, messageId, products)
+	case "aggtrades":
+		err = client.SubscribeAggregateTradesCtx(ctx, messageId, products)
+	case "depth":
+		err = client.SubscribePartialBookDepthCtx(ctx, messageId, products, []types.Limit{types.Limit(*limit)}, []int64{*granularity})
+	case "ticker":
+		err = client.Subscribe24hrPriceChangeStatisticsCtx(ctx, messageId, products)
+	case "klines":
+		err = client.SubscribeKlineDataCtx(ctx, messageId, products, []types.Interval{types.Interval(*interval)})
+	case "account":
+		connection = client.RPCConnection
+		if err = client.LoginCtx(ctx, messageId); err == nil {
+			err = client.AccountUpdatesCtx(ctx, messageId)
+		}
+	default:
+		return fmt.Errorf("invalid channel %q", *channel)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to subscribe: %v", err)
+	}
+
+	// Unblock reads once the context is done.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			connection.Close()
+		case <-done:
+		}
+	}()
+
+	// Print messages, skipping responses to the subscription.
+	for printed := 0; *count == 0 || printed < *count; {
+		_, body, err := connection.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to read stream: %v", err)
+		}
+		var response types.WebsocketResponse
+		if err := json.Unmarshal(body, &response); err == nil && response.ID != "" {
+			if response.Error != nil {
+				return utils.NewRPCError(&response)
+			}
+			continue
+		}
+		fmt.Fprintln(cli.stdout, string(body))
+		printed++
+	}
+	return nil
+}
+
+func (cli *ryskctl) audit(ctx context.Context, args []string) error {
+	_, args, err := cli.subcommand("audit", args, "verify")
+	if err != nil {
+		return err
+	}
+	flags := cli.flagSet("audit verify")
+	path := flags.String("file", cli.config.AuditLog, "audit log file, defaults to the configured one")
+	if err := cli.parse(flags, args, "file"); err != nil {
+		return err
+	}
+
+	// The log is read, not opened for appending, so that verifying it never changes it.
+	summary, err := audit.VerifyFile(*path)
+	if err != nil {
+		return fmt.Errorf("audit log %s is invalid: %v", *path, err)
+	}
+	rows := [][]string{{strconv.FormatUint(summary.Records, 10), strconv.FormatUint(summary.Actions, 10), strconv.FormatUint(summary.Responses, 10), summary.LastHash}}
+	return cli.print(summary, []string{"RECORDS", "ACTIONS", "RESPONSES", "LAST HASH"}, rows)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+// DEFAULT_CONFIG_FILE is the configuration file read from the home directory when neither `-config` nor `RYSKCTL_CONFIG` is set.
+const DEFAULT_CONFIG_FILE = ".ryskctl.json"
+
+// DEFAULT_RPC_URL holds the public Arbitrum RPC URL of each environment, used when none is configured.
+var DEFAULT_RPC_URL = map[types.Environment]string{
+	constants.ENVIRONMENT_MAINNET: "https://arb1.arbitrum.io/rpc",
+	constants.ENVIRONMENT_TESTNET: "https://arbitrum-sepolia.gateway.tenderly.co",
+}
+
+// Configuration holds the settings of ryskctl, read from a JSON file and overridden by environment variables.
+type Configuration struct {
+	Env          types.Environment `json:"env"`          // `testnet` (default) or `mainnet`. Overridden by `RYSK_ENV`.
+	PrivateKey   string            `json:"privateKey"`   // Account private key with or without `0x` prefix. Overridden by `RYSK_PRIVATE_KEY`.
+	RpcUrl       string            `json:"rpcUrl"`       // RPC URL of the Ethereum client, defaults to `DEFAULT_RPC_URL[Env]`. Overridden by `RYSK_RPC_URL`.
+	SubAccountId uint8             `json:"subAccountId"` // ID of the sub-account to act on. Overridden by `RYSK_SUB_ACCOUNT_ID`.
+	BaseUrl      string            `json:"baseUrl"`      // Optional REST API base URL. Overridden by `RYSK_BASE_URL`.
+	WSRpcUrl     string            `json:"wsRpcUrl"`     // Optional RPC websocket URL. Overridden by `RYSK_WS_RPC_URL`.
+	WSStreamUrl  string            `json:"wsStreamUrl"`  // Optional stream websocket URL. Overridden by `RYSK_WS_STREAM_URL`.
+	AuditLog     string            `json:"auditLog"`     // Optional audit log file of signed actions. Overridden by `RYSK_AUDIT_LOG`.
+}
+
+// loadConfiguration reads the configuration file, applies the environment variables and fills in the defaults.
+//
+// Parameters:
+//   - path: The configuration file. Empty reads `RYSKCTL_CONFIG`, then `DEFAULT_CONFIG_FILE` in the home directory if it exists.
+//   - getenv: Function reading environment variables, e.g. `os.Getenv`.
+//
+// Returns:
+//   - A pointer to Configuration.
+//   - An error if the file cannot be read or a setting is invalid.
+func loadConfiguration(path string, getenv func(string) string) (*Configuration, error) {
+	config := &Configuration{}
+
+	// Read the file, the default one being optional.
+	if path == "" {
+		path = getenv("RYSKCTL_CONFIG")
+	}
+	optional := false
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err == nil {
+			path, optional = filepath.Join(home, DEFAULT_CONFIG_FILE), true
+		}
+	}
+	if path != "" {
+		body, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(body, config); err != nil {
+				return nil, fmt.Errorf("failed to parse configuration file %s: %v", path, err)
+			}
+		case !optional || !errors.Is(err, os.ErrNotExist):
+			return nil, fmt.Errorf("failed to read configuration file: %v", err)
+		}
+	}
+
+	// Override with environment variables.
+	for variable, setting := range map[string]*string{
+		"RYSK_PRIVATE_KEY":   &config.PrivateKey,
+		"RYSK_RPC_URL":       &config.RpcUrl,
+		"RYSK_BASE_URL":      &config.BaseUrl,
+		"RYSK_WS_RPC_URL":    &config.WSRpcUrl,
+		"RYSK_WS_STREAM_URL": &config.WSStreamUrl,
+		"RYSK_AUDIT_LOG":     &config.AuditLog,
+	} {
+		if value := getenv(variable); value != "" {
+			*setting = value
+		}
+	}
+	if env := getenv("RYSK_ENV"); env != "" {
+		config.Env = types.Environment(env)
+	}
+	if subAccountId := getenv("RYSK_SUB_ACCOUNT_ID"); subAccountId != "" {
+		id, err := strconv.ParseUint(subAccountId, 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RYSK_SUB_ACCOUNT_ID %q", subAccountId)
+		}
+		config.SubAccountId = uint8(id)
+	}
+	return config, nil
+}
+
+// validate checks the environment and fills in the defaults depending on it.
+func (config *Configuration) validate() error {
+	if config.Env == "" {
+		config.Env = constants.ENVIRONMENT_TESTNET
+	}
+	if config.Env != constants.ENVIRONMENT_TESTNET && config.Env != constants.ENVIRONMENT_MAINNET {
+		return fmt.Errorf("invalid environment %q: must be %s or %s", config.Env, constants.ENVIRONMENT_TESTNET, constants.ENVIRONMENT_MAINNET)
+	}
+	if config.RpcUrl == "" {
+		config.RpcUrl = DEFAULT_RPC_URL[config.Env]
+	}
+	return nil
+}
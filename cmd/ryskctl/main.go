@@ -0,0 +1,272 @@
+// Command ryskctl inspects and operates a Rysk V2 account from the command line.
+//
+// Usage:
+//
+//	ryskctl [global flags] <command> [subcommand] [flags]
+//
+// The private key and environment are read from a JSON configuration file (`-config`, `RYSKCTL_CONFIG`
+// or `~/.ryskctl.json`) and overridden by environment variables (`RYSK_PRIVATE_KEY`, `RYSK_ENV`, ...), which
+// may be set in a `.env` file. Mutating commands ask for confirmation unless `-yes` is set.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/rysk-finance/v2_client_go/api_client"
+	"github.com/rysk-finance/v2_client_go/audit"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/ws_client"
+	"golang.org/x/term"
+)
+
+// DEFAULT_TIMEOUT is the default deadline of a command, streams excepted.
+const DEFAULT_TIMEOUT time.Duration = 30 * time.Second
+
+// errAborted reports a mutating command declined at the confirmation prompt.
+var errAborted = errors.New("aborted")
+
+// errUsage reports invalid arguments, the usage having been printed.
+var errUsage = errors.New("invalid usage")
+
+// command is a ryskctl command.
+type command struct {
+	usage     string                                                       // Arguments of the command.
+	summary   string                                                       // One line description of the command.
+	streaming bool                                                         // Whether the command runs until interrupted, without deadline.
+	run       func(cli *ryskctl, ctx context.Context, args []string) error // Runs the command with its arguments.
+}
+
+// COMMANDS holds the commands by name.
+var COMMANDS = map[string]*command{
+	"products":  {usage: "", summary: "List products", run: (*ryskctl).products},
+	"ticker":    {usage: "[-product SYMBOL]", summary: "Show 24 hour price change statistics", run: (*ryskctl).ticker},
+	"book":      {usage: "-product SYMBOL [-limit 5|10|20] [-granularity N]", summary: "Show the order book", run: (*ryskctl).book},
+	"klines":    {usage: "-product SYMBOL [-interval 1h] [-start TIME] [-end TIME] [-limit N]", summary: "Show klines", run: (*ryskctl).klines},
+	"orders":    {usage: "list|place|cancel|cancel-all [flags]", summary: "List, place and cancel orders", run: (*ryskctl).orders},
+	"positions": {usage: "", summary: "List perpetual positions", run: (*ryskctl).positions},
+	"balances":  {usage: "", summary: "List spot balances", run: (*ryskctl).balances},
+	"signers":   {usage: "list|approve|revoke [-address ADDRESS]", summary: "List, approve and revoke signers", run: (*ryskctl).signers},
+	"deposit":   {usage: "-amount USDC", summary: "Deposit USDC from the wallet, approving it first if needed", run: (*ryskctl).deposit},
+	"withdraw":  {usage: "-amount USDC", summary: "Withdraw USDC to the wallet", run: (*ryskctl).withdraw},
+	"stream":    {usage: "-channel trades|aggtrades|depth|ticker|klines|account [-product SYMBOL,...] [-count N]", summary: "Print stream messages as JSON lines", streaming: true, run: (*ryskctl).stream},
+	"audit":     {usage: "verify [-file PATH]", summary: "Verify the hash chain of an audit log, the configured one by default", run: (*ryskctl).audit},
+	"watch":     {usage: "-product SYMBOL [-limit 5|10|20] [-trades N] [-slippage FRACTION]", summary: "Watch the book, trades, open orders and position, cancelling and flattening with keys", streaming: true, run: (*ryskctl).watch},
+}
+
+// ryskctl holds the state of an invocation.
+type ryskctl struct {
+	stdin     *bufio.Reader               // stdin reads confirmations and keys.
+	terminal  *os.File                    // terminal is the terminal of stdin, nil if stdin is not a terminal.
+	stdout    io.Writer                   // stdout receives results.
+	stderr    io.Writer                   // stderr receives prompts, usage and errors.
+	getenv    func(string) string         // getenv reads environment variables.
+	config    *Configuration              // config is the configuration, once the global flags are parsed.
+	output    string                      // output is the output format, `OUTPUT_TABLE` or `OUTPUT_JSON`.
+	yes       bool                        // yes skips confirmations.
+	apiClient *api_client.RyskV2APIClient // apiClient is created on first use.
+	auditLog  *audit.Log                  // auditLog is opened on first use when configured.
+}
+
+func main() {
+	// The `.env` file is optional.
+	godotenv.Load()
+
+	cli := &ryskctl{stdin: bufio.NewReader(os.Stdin), stdout: os.Stdout, stderr: os.Stderr, getenv: os.Getenv}
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		cli.terminal = os.Stdin
+	}
+	os.Exit(cli.run(context.Background(), os.Args[1:]))
+}
+
+// run parses the global flags and runs a command.
+//
+// Parameters:
+//   - ctx: The context of the command, cancelled on interrupt.
+//   - args: The command line arguments, without the program name.
+//
+// Returns:
+//   - The exit code: 0 on success, 1 on failure and 2 on invalid usage.
+func (cli *ryskctl) run(ctx context.Context, args []string) int {
+	flags := flag.NewFlagSet("ryskctl", flag.ContinueOnError)
+	flags.SetOutput(cli.stderr)
+	flags.Usage = func() { cli.usage(flags) }
+	configPath := flags.String("config", "", "configuration file, defaults to $RYSKCTL_CONFIG or ~/"+DEFAULT_CONFIG_FILE)
+	env := flags.String("env", "", "environment, testnet or mainnet, overriding the configuration")
+	subAccountId := flags.Int("subaccount", -1, "sub-account ID, overriding the configuration")
+	flags.StringVar(&cli.output, "output", OUTPUT_TABLE, "output format, table or json")
+	flags.BoolVar(&cli.yes, "yes", false, "skip confirmation of mutating commands")
+	timeout := flags.Duration("timeout", DEFAULT_TIMEOUT, "deadline of the command, streams excepted")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		cli.usage(flags)
+		return 2
+	}
+	if cli.output != OUTPUT_TABLE && cli.output != OUTPUT_JSON {
+		fmt.Fprintf(cli.stderr, "ryskctl: invalid output %q: must be %s or %s\n", cli.output, OUTPUT_TABLE, OUTPUT_JSON)
+		return 2
+	}
+	command, ok := COMMANDS[flags.Arg(0)]
+	if !ok {
+		fmt.Fprintf(cli.stderr, "ryskctl: unknown command %q\n", flags.Arg(0))
+		cli.usage(flags)
+		return 2
+	}
+
+	// Load the configuration, flags taking precedence.
+	config, err := loadConfiguration(*configPath, cli.getenv)
+	if err != nil {
+		fmt.Fprintf(cli.stderr, "ryskctl: %v\n", err)
+		return 1
+	}
+	if *env != "" {
+		config.Env = types.Environment(*env)
+	}
+	if *subAccountId >= 0 {
+		if *subAccountId > 255 {
+			fmt.Fprintf(cli.stderr, "ryskctl: invalid sub-account ID %d\n", *subAccountId)
+			return 2
+		}
+		config.SubAccountId = uint8(*subAccountId)
+	}
+	if err := config.validate(); err != nil {
+		fmt.Fprintf(cli.stderr, "ryskctl: %v\n", err)
+		return 1
+	}
+	cli.config = config
+
+	// Run the command until interrupted or past its deadline.
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	if !command.streaming {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	defer cli.closeAuditLog()
+	switch err := command.run(cli, ctx, flags.Args()[1:]); {
+	case errors.Is(err, errUsage):
+		return 2
+	case err != nil:
+		fmt.Fprintf(cli.stderr, "ryskctl: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// usage prints the commands and the global flags.
+func (cli *ryskctl) usage(flags *flag.FlagSet) {
+	fmt.Fprintln(cli.stderr, "Usage: ryskctl [global flags] <command> [subcommand] [flags]")
+	fmt.Fprintln(cli.stderr, "\nCommands:")
+	names := make([]string, 0, len(COMMANDS))
+	for name := range COMMANDS {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(cli.stderr, "  %-10s %s\n", name, COMMANDS[name].summary)
+		if COMMANDS[name].usage != "" {
+			fmt.Fprintf(cli.stderr, "  %-10s   %s %s\n", "", name, COMMANDS[name].usage)
+		}
+	}
+	fmt.Fprintln(cli.stderr, "\nGlobal flags:")
+	flags.PrintDefaults()
+}
+
+// confirm asks to confirm a mutating action, unless confirmations are skipped.
+//
+// Returns:
+//   - `errAborted` unless the answer is yes.
+func (cli *ryskctl) confirm(format string, args ...interface{}) error {
+	if cli.yes {
+		return nil
+	}
+	fmt.Fprintf(cli.stderr, format+" [y/N] ", args...)
+	answer, _ := cli.stdin.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return errAborted
+}
+
+// client returns the REST client of the configured account, creating it on first use.
+func (cli *ryskctl) client() (*api_client.RyskV2APIClient, error) {
+	if cli.apiClient != nil {
+		return cli.apiClient, nil
+	}
+	if cli.config.PrivateKey == "" {
+		return nil, fmt.Errorf("no private key configured: set privateKey in the configuration file or RYSK_PRIVATE_KEY")
+	}
+	auditLog, err := cli.openAuditLog()
+	if err != nil {
+		return nil, err
+	}
+	apiClient, err := api_client.NewRyskV2APIClient(&api_client.RyskV2APIClientConfiguration{
+		Env:          cli.config.Env,
+		PrivateKey:   cli.config.PrivateKey,
+		RpcUrl:       cli.config.RpcUrl,
+		SubAccountId: cli.config.SubAccountId,
+		BaseUrl:      cli.config.BaseUrl,
+		AuditLog:     auditLog,
+	})
+	if err != nil {
+		return nil, err
+	}
+	cli.apiClient = apiClient
+	return apiClient, nil
+}
+
+// wsClient connects a websocket client for the configured account.
+func (cli *ryskctl) wsClient() (*ws_client.RyskV2WSClient, error) {
+	if cli.config.PrivateKey == "" {
+		return nil, fmt.Errorf("no private key configured: set privateKey in the configuration file or RYSK_PRIVATE_KEY")
+	}
+	auditLog, err := cli.openAuditLog()
+	if err != nil {
+		return nil, err
+	}
+	return ws_client.NewRyskV2WSClient(&ws_client.RyskV2WSClientConfiguration{
+		Env:          cli.config.Env,
+		PrivateKey:   cli.config.PrivateKey,
+		RpcUrl:       cli.config.RpcUrl,
+		SubAccountId: cli.config.SubAccountId,
+		BaseUrl:      cli.config.BaseUrl,
+		WSRpcUrl:     cli.config.WSRpcUrl,
+		WSStreamUrl:  cli.config.WSStreamUrl,
+		AuditLog:     auditLog,
+	})
+}
+
+// openAuditLog opens the configured audit log on first use, returning nil when none is configured.
+func (cli *ryskctl) openAuditLog() (*audit.Log, error) {
+	if cli.auditLog != nil || cli.config.AuditLog == "" {
+		return cli.auditLog, nil
+	}
+	auditLog, err := audit.NewLog(&audit.LogConfiguration{Path: cli.config.AuditLog})
+	if err != nil {
+		return nil, err
+	}
+	cli.auditLog = auditLog
+	return auditLog, nil
+}
+
+// closeAuditLog closes the audit log if it was opened.
+func (cli *ryskctl) closeAuditLog() {
+	if cli.auditLog != nil {
+		cli.auditLog.Close()
+		cli.auditLog = nil
+	}
+}
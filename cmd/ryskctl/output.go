@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+const (
+	OUTPUT_TABLE string = "table" // OUTPUT_TABLE prints results as aligned columns with human readable amounts.
+	OUTPUT_JSON  string = "json"  // OUTPUT_JSON prints results as indented JSON, as returned by the exchange.
+)
+
+// ORDER_TYPES maps order type names to order types.
+var ORDER_TYPES = map[string]types.OrderType{
+	"limit":             constants.ORDER_TYPE_LIMIT,
+	"limit-maker":       constants.ORDER_TYPE_LIMIT_MAKER,
+	"market":            constants.ORDER_TYPE_MARKET,
+	"stop-loss":         constants.ORDER_TYPE_STOP_LOSS,
+	"stop-loss-limit":   constants.ORDER_TYPE_STOP_LOSS_LIMIT,
+	"take-profit":       constants.ORDER_TYPE_TAKE_PROFIT,
+	"take-profit-limit": constants.ORDER_TYPE_TAKE_PROFIT_LIMIT,
+}
+
+// TIMES_IN_FORCE maps time in force names to times in force.
+var TIMES_IN_FORCE = map[string]types.TimeInForce{
+	"gtc": constants.TIME_IN_FORCE_GTC,
+	"fok": constants.TIME_IN_FORCE_FOK,
+	"ioc": constants.TIME_IN_FORCE_IOC,
+}
+
+// print writes a result, as a table of rows or as JSON depending on the output format.
+func (cli *ryskctl) print(result interface{}, header []string, rows [][]string) error {
+	if cli.output == OUTPUT_JSON {
+		encoder := json.NewEncoder(cli.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	writer := tabwriter.NewWriter(cli.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	return writer.Flush()
+}
+
+// parseAmount parses a decimal amount, e.g. `0.5`, into an integer with the given decimals, e.g. wei for 18.
+func parseAmount(value string, decimals uint8) (*big.Int, error) {
+	amount, ok := new(big.Rat).SetString(value)
+	if !ok || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid amount %q: must be a positive decimal", value)
+	}
+	amount.Mul(amount, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)))
+	if !amount.IsInt() {
+		return nil, fmt.Errorf("invalid amount %q: more than %d decimals", value, decimals)
+	}
+	return amount.Num(), nil
+}
+
+// formatE18 formats an amount in wei (e18) as a decimal, returning other values unchanged.
+func formatE18(value string) string {
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return value
+	}
+	decimal := new(big.Rat).SetFrac(amount, constants.E18).FloatString(18)
+	decimal = strings.TrimRight(decimal, "0")
+	return strings.TrimSuffix(decimal, ".")
+}
+
+// formatTime formats a UNIX timestamp in ms, 0 being formatted as `-`.
+func formatTime(milliseconds int64) string {
+	if milliseconds == 0 {
+		return "-"
+	}
+	return time.UnixMilli(milliseconds).UTC().Format(time.RFC3339)
+}
+
+// parseTime parses a UNIX timestamp in ms or an RFC 3339 time into a UNIX timestamp in ms, empty being 0.
+func parseTime(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if milliseconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return milliseconds, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: must be a UNIX timestamp in ms or an RFC 3339 time", value)
+	}
+	return parsed.UnixMilli(), nil
+}
+
+// productSymbol returns the symbol of a known product, or its ID.
+func productSymbol(productId int64) string {
+	for _, product := range []types.Product{constants.PRODUCT_ETH_PERP, constants.PRODUCT_BTC_PERP, constants.PRODUCT_SOL_PERP} {
+		if product.Id == productId {
+			return product.Symbol
+		}
+	}
+	return strconv.FormatInt(productId, 10)
+}
+
+// side returns the side of an order.
+func side(isBuy bool) string {
+	if isBuy {
+		return "buy"
+	}
+	return "sell"
+}
+
+// name returns the name of a value in a map of names, or the value itself.
+func name[T comparable](names map[string]T, value T) string {
+	for name, named := range names {
+		if named == value {
+			return name
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+// orderRows returns the table of orders.
+func orderRows(orders []types.Order) ([]string, [][]string) {
+	header := []string{"ID", "PRODUCT", "SIDE", "TYPE", "TIF", "PRICE", "QUANTITY", "FILLED", "STATUS", "EXPIRATION"}
+	rows := make([][]string, 0, len(orders))
+	for _, order := range orders {
+		rows = append(rows, []string{
+			order.Id,
+			productSymbol(order.ProductId),
+			side(order.IsBuy),
+			name(ORDER_TYPES, order.OrderType),
+			name(TIMES_IN_FORCE, order.TimeInForce),
+			formatE18(order.Price),
+			formatE18(order.Quantity),
+			formatE18(order.Filled),
+			order.Status,
+			formatTime(order.Expiration),
+		})
+	}
+	return header, rows
+}
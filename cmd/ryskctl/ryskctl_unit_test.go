@@ -0,0 +1,461 @@
+//go:build !integration
+// +build !integration
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rysk-finance/v2_client_go/audit"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/ryskfake"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type RyskctlUnitTestSuite struct {
+	suite.Suite
+	Server     *ryskfake.Server
+	PrivateKey string
+	Env        map[string]string
+	stdout     bytes.Buffer
+	stderr     bytes.Buffer
+}
+
+func (s *RyskctlUnitTestSuite) SetupTest() {
+	server, err := ryskfake.NewServer(&ryskfake.ServerConfiguration{})
+	require.NoError(s.T(), err)
+	s.Server = server
+
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(s.T(), err)
+	s.PrivateKey = hex.EncodeToString(crypto.FromECDSA(privateKey))
+
+	// The configuration file selects the sub-account, the environment the servers.
+	configPath := filepath.Join(s.T().TempDir(), "ryskctl.json")
+	require.NoError(s.T(), os.WriteFile(configPath, []byte(`{"env":"testnet","subAccountId":1}`), 0600))
+	s.Env = map[string]string{
+		"RYSKCTL_CONFIG":     configPath,
+		"RYSK_PRIVATE_KEY":   s.PrivateKey,
+		"RYSK_RPC_URL":       server.URL(),
+		"RYSK_BASE_URL":      server.URL(),
+		"RYSK_WS_RPC_URL":    server.RPCURL(),
+		"RYSK_WS_STREAM_URL": server.StreamURL(),
+	}
+}
+
+func (s *RyskctlUnitTestSuite) TearDownTest() {
+	s.Server.Close()
+}
+
+func TestRunSuiteUnit_RyskctlUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(RyskctlUnitTestSuite))
+}
+
+// run runs ryskctl with the suite environment, answering confirmations with stdin.
+func (s *RyskctlUnitTestSuite) run(stdin string, args ...string) int {
+	s.stdout.Reset()
+	s.stderr.Reset()
+	cli := &ryskctl{
+		stdin:  bufio.NewReader(strings.NewReader(stdin)),
+		stdout: &s.stdout,
+		stderr: &s.stderr,
+		getenv: func(name string) string { return s.Env[name] },
+	}
+	return cli.run(context.Background(), args)
+}
+
+// address returns the address of the suite account.
+func (s *RyskctlUnitTestSuite) address() common.Address {
+	return common.HexToAddress(utils.AddressFromPrivateKey(s.PrivateKey))
+}
+
+func (s *RyskctlUnitTestSuite) TestUnit_Usage() {
+	require.Equal(s.T(), 2, s.run(""))
+	require.Contains(s.T(), s.stderr.String(), "Commands:")
+	require.Equal(s.T(), 2, s.run("", "unknown"))
+	require.Contains(s.T(), s.stderr.String(), `unknown command "unknown"`)
+	require.Equal(s.T(), 2, s.run("", "-output", "xml", "products"))
+	require.Equal(s.T(), 2, s.run("", "book"))
+	require.Contains(s.T(), s.stderr.String(), "-product is required")
+	require.Equal(s.T(), 2, s.run("", "orders", "amend"))
+	require.Contains(s.T(), s.stderr.String(), `unknown subcommand "amend"`)
+
+	// Invalid settings fail.
+	require.Equal(s.T(), 1, s.run("", "-env", "devnet", "products"))
+	require.Contains(s.T(), s.stderr.String(), `invalid environment "devnet"`)
+	delete(s.Env, "RYSK_PRIVATE_KEY")
+	require.Equal(s.T(), 1, s.run("", "products"))
+	require.Contains(s.T(), s.stderr.String(), "no private key configured")
+}
+
+func (s *RyskctlUnitTestSuite) TestUnit_LoadConfiguration() {
+	env := map[string]string{"RYSKCTL_CONFIG": s.Env["RYSKCTL_CONFIG"], "RYSK_SUB_ACCOUNT_ID": "3", "RYSK_BASE_URL": "http://localhost"}
+	config, err := loadConfiguration("", func(name string) string { return env[name] })
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), config.validate())
+	require.Equal(s.T(), constants.ENVIRONMENT_TESTNET, config.Env)
+	require.Equal(s.T(), uint8(3), config.SubAccountId)
+	require.Equal(s.T(), "http://localhost", config.BaseUrl)
+	require.Equal(s.T(), DEFAULT_RPC_URL[constants.ENVIRONMENT_TESTNET], config.RpcUrl)
+
+	// Files set explicitly must exist.
+	_, err = loadConfiguration(filepath.Join(s.T().TempDir(), "missing.json"), func(string) string { return "" })
+	require.Error(s.T(), err)
+	env["RYSK_SUB_ACCOUNT_ID"] = "256"
+	_, err = loadConfiguration("", func(name string) string { return env[name] })
+	require.Error(s.T(), err)
+}
+
+func (s *RyskctlUnitTestSuite) TestUnit_MarketData() {
+	require.Equal(s.T(), 0, s.run("", "products"), s.stderr.String())
+	require.Contains(s.T(), s.stdout.String(), "SYMBOL")
+	require.Contains(s.T(), s.stdout.String(), constants.PRODUCT_ETH_PERP.Symbol)
+
+	require.Equal(s.T(), 0, s.run("", "ticker", "-product", constants.PRODUCT_ETH_PERP.Symbol), s.stderr.String())
+	require.Contains(s.T(), s.stdout.String(), constants.PRODUCT_ETH_PERP.Symbol)
+	require.Equal(s.T(), 0, s.run("", "-output", "json", "ticker"), s.stderr.String())
+	var tickers []types.Ticker
+	require.NoError(s.T(), json.Unmarshal(s.stdout.Bytes(), &tickers))
+	require.Len(s.T(), tickers, 3)
+
+	// Resting orders show in the book.
+	require.Equal(s.T(), 0, s.run("", "-yes", "orders", "place", "-product", constants.PRODUCT_ETH_PERP.Symbol, "-side", "buy", "-price", "1000", "-quantity", "0.5"), s.stderr.String())
+	require.Equal(s.T(), 0, s.run("", "book", "-product", constants.PRODUCT_ETH_PERP.Symbol), s.stderr.String())
+	require.Contains(s.T(), s.stdout.String(), "BID QUANTITY")
+	require.Contains(s.T(), s.stdout.String(), "0.5")
+	require.Contains(s.T(), s.stdout.String(), "1000")
+
+	require.Equal(s.T(), 0, s.run("", "klines", "-product", constants.PRODUCT_ETH_PERP.Symbol, "-interval", "1m", "-start", "2024-01-01T00:00:00Z"), s.stderr.String())
+	require.Contains(s.T(), s.stdout.String(), "OPEN TIME")
+	require.Equal(s.T(), 1, s.run("", "klines", "-product", constants.PRODUCT_ETH_PERP.Symbol, "-interval", "7m"))
+	require.Equal(s.T(), 1, s.run("", "book", "-product", "unknown"))
+	require.Contains(s.T(), s.stderr.String(), "failed to get product unknown")
+}
+
+func (s *RyskctlUnitTestSuite) TestUnit_Orders() {
+	place := []string{"orders", "place", "-product", constants.PRODUCT_ETH_PERP.Symbol, "-side", "sell", "-price", "2500.5", "-quantity", "1", "-tif", "gtc"}
+
+	// Declined confirmations abort.
+	require.Equal(s.T(), 1, s.run("n\n", place...))
+	require.Contains(s.T(), s.stderr.String(), "Place sell limit order of 1 ethperp at 2500.5 (GTC)? [y/N]")
+	require.Contains(s.T(), s.stderr.String(), "aborted")
+	require.Equal(s.T(), 1, s.run("", place...))
+
+	require.Equal(s.T(), 0, s.run("y\n", place...), s.stderr.String())
+	require.Contains(s.T(), s.stdout.String(), "2500.5")
+	require.Equal(s.T(), 0, s.run("yes\n", place...), s.stderr.String())
+
+	require.Equal(s.T(), 0, s.run("", "-output", "json", "orders", "list"), s.stderr.String())
+	var orders []types.Order
+	require.NoError(s.T(), json.Unmarshal(s.stdout.Bytes(), &orders))
+	require.Len(s.T(), orders, 2)
+	require.Equal(s.T(), new(big.Int).Mul(big.NewInt(25005), constants.E17).String(), orders[0].Price)
+	require.Equal(s.T(), int64(1), orders[0].SubAccountId)
+
+	require.Equal(s.T(), 0, s.run("y\n", "orders", "cancel", "-product", constants.PRODUCT_ETH_PERP.Symbol, "-id", orders[0].Id), s.stderr.String())
+	require.Contains(s.T(), s.stdout.String(), orders[0].Id)
+	require.Equal(s.T(), 0, s.run("", "-yes", "-output", "json", "orders", "cancel-all", "-product", constants.PRODUCT_ETH_PERP.Symbol), s.stderr.String())
+	require.NoError(s.T(), json.Unmarshal(s.stdout.Bytes(), &orders))
+	require.Len(s.T(), orders, 1)
+	require.Equal(s.T(), 0, s.run("", "orders", "list", "-product", constants.PRODUCT_ETH_PERP.Symbol), s.stderr.String())
+	require.Equal(s.T(), 1, strings.Count(s.stdout.String(), "\n"))
+
+	// Rejections fail.
+	require.Equal(s.T(), 1, s.run("", "-yes", "orders", "cancel", "-product", constants.PRODUCT_ETH_PERP.Symbol, "-id", "unknown"))
+	require.Equal(s.T(), 1, s.run("", "-yes", "orders", "place", "-product", constants.PRODUCT_ETH_PERP.Symbol, "-side", "long", "-price", "1", "-quantity", "1"))
+}
+
+func (s *RyskctlUnitTestSuite) TestUnit_Account() {
+	s.Server.Credit(s.address(), 1, common.HexToAddress(constants.USDC_ADDRESS[constants.ENVIRONMENT_TESTNET]), new(big.Int).Mul(big.NewInt(100), constants.E18))
+
+	require.Equal(s.T(), 0, s.run("", "balances"), s.stderr.String())
+	require.Contains(s.T(), s.stdout.String(), "100")
+	require.Equal(s.T(), 1, s.run("", "-yes", "withdraw", "-amount", "1000"))
+	require.Contains(s.T(), s.stderr.String(), "insufficient balance")
+	require.Equal(s.T(), 0, s.run("y\n", "-output", "json", "withdraw", "-amount", "2.5"), s.stderr.String())
+	var balance types.SpotBalance
+	require.NoError(s.T(), json.Unmarshal(s.stdout.Bytes(), &balance))
+	require.Equal(s.T(), new(big.Int).Mul(big.NewInt(975), constants.E17).String(), balance.Quantity)
+
+	// Other sub-accounts are empty.
+	require.Equal(s.T(), 0, s.run("", "-subaccount", "2", "-output", "json", "balances"), s.stderr.String())
+	var balances []types.SpotBalance
+	require.NoError(s.T(), json.Unmarshal(s.stdout.Bytes(), &balances))
+	require.Empty(s.T(), balances)
+
+	require.Equal(s.T(), 0, s.run("", "positions"), s.stderr.String())
+	require.Contains(s.T(), s.stdout.String(), "ENTRY PRICE")
+
+	signer := common.HexToAddress("0x1234").Hex()
+	require.Equal(s.T(), 1, s.run("n\n", "signers", "approve", "-address", signer))
+	require.Equal(s.T(), 0, s.run("y\n", "signers", "approve", "-address", signer), s.stderr.String())
+	require.Equal(s.T(), 0, s.run("", "-output", "json", "signers", "list"), s.stderr.String())
+	var signers []types.ApprovedSigner
+	require.NoError(s.T(), json.Unmarshal(s.stdout.Bytes(), &signers))
+	require.Len(s.T(), signers, 1)
+	require.True(s.T(), signers[0].Approved)
+	require.Equal(s.T(), 0, s.run("", "-yes", "signers", "revoke", "-address", signer), s.stderr.String())
+	require.Contains(s.T(), s.stdout.String(), "false")
+	require.Equal(s.T(), 1, s.run("", "-yes", "signers", "approve", "-address", "0x12"))
+}
+
+func (s *RyskctlUnitTestSuite) TestUnit_Audit() {
+	// Without audit log, there is nothing to verify.
+	require.Equal(s.T(), 2, s.run("", "audit"))
+	require.Equal(s.T(), 2, s.run("", "audit", "verify"))
+	require.Contains(s.T(), s.stderr.String(), "-file is required")
+
+	// Signed actions are recorded in the configured audit log.
+	path := filepath.Join(s.T().TempDir(), "audit.jsonl")
+	s.Env["RYSK_AUDIT_LOG"] = path
+	signer := common.HexToAddress("0x1234").Hex()
+	require.Equal(s.T(), 0, s.run("", "-yes", "signers", "approve", "-address", signer), s.stderr.String())
+	require.Equal(s.T(), 1, s.run("", "-yes", "withdraw", "-amount", "1000"))
+	require.Equal(s.T(), 0, s.run("", "balances"), s.stderr.String())
+	require.Equal(s.T(), 0, s.run("", "-output", "json", "audit", "verify"), s.stderr.String())
+	var summary audit.Summary
+	require.NoError(s.T(), json.Unmarshal(s.stdout.Bytes(), &summary))
+	require.Equal(s.T(), uint64(4), summary.Records)
+	require.Equal(s.T(), uint64(2), summary.Actions)
+	delete(s.Env, "RYSK_AUDIT_LOG")
+	require.Equal(s.T(), 0, s.run("", "audit", "verify", "-file", path), s.stderr.String())
+	require.Contains(s.T(), s.stdout.String(), summary.LastHash)
+
+	// Edited logs are invalid, and refused by clients.
+	body, err := os.ReadFile(path)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), os.WriteFile(path, bytes.Replace(body, []byte(`"isApproved":true`), []byte(`"isApproved":false`), 1), 0600))
+	require.Equal(s.T(), 1, s.run("", "audit", "verify", "-file", path))
+	require.Contains(s.T(), s.stderr.String(), "line 1: hash")
+	s.Env["RYSK_AUDIT_LOG"] = path
+	require.Equal(s.T(), 1, s.run("", "balances"))
+	require.Contains(s.T(), s.stderr.String(), "failed to verify audit log")
+	require.Equal(s.T(), 1, s.run("", "audit", "verify", "-file", filepath.Join(s.T().TempDir(), "missing.jsonl")))
+}
+
+func (s *RyskctlUnitTestSuite) TestUnit_Stream() {
+	done := make(chan int)
+	go func() {
+		done <- s.run("", "stream", "-channel", "trades", "-product", constants.PRODUCT_ETH_PERP.Symbol, "-count", "1")
+	}()
+
+	// Trade against another account until the stream prints one.
+	maker := &ryskctl{stdin: bufio.NewReader(strings.NewReader("")), stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}, getenv: func(name string) string { return s.Env[name] }}
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(s.T(), err)
+	env := map[string]string{"RYSK_PRIVATE_KEY": hex.EncodeToString(crypto.FromECDSA(otherKey))}
+	taker := &ryskctl{stdin: bufio.NewReader(strings.NewReader("")), stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}, getenv: func(name string) string {
+		if value, ok := env[name]; ok {
+			return value
+		}
+		return s.Env[name]
+	}}
+	for {
+		require.Equal(s.T(), 0, maker.run(context.Background(), []string{"-yes", "orders", "place", "-product", constants.PRODUCT_ETH_PERP.Symbol, "-side", "sell", "-price", "1000", "-quantity", "1"}))
+		require.Equal(s.T(), 0, taker.run(context.Background(), []string{"-yes", "orders", "place", "-product", constants.PRODUCT_ETH_PERP.Symbol, "-side", "buy", "-price", "1000", "-quantity", "1"}))
+		select {
+		case code := <-done:
+			require.Equal(s.T(), 0, code, s.stderr.String())
+			require.Contains(s.T(), s.stdout.String(), constants.PRODUCT_ETH_PERP.Symbol+"@trade")
+			return
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// lockedBuffer is a buffer written and read by different goroutines.
+type lockedBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (buffer *lockedBuffer) Write(p []byte) (int, error) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	return buffer.buffer.Write(p)
+}
+
+func (buffer *lockedBuffer) String() string {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	return buffer.buffer.String()
+}
+
+func (s *RyskctlUnitTestSuite) TestUnit_Watch() {
+	require.Equal(s.T(), 0, s.run("", "-yes", "-output", "json", "orders", "place", "-product", constants.PRODUCT_ETH_PERP.Symbol, "-side", "buy", "-price", "1000", "-quantity", "0.5"), s.stderr.String())
+	var order types.Order
+	require.NoError(s.T(), json.Unmarshal(s.stdout.Bytes(), &order))
+
+	keys, stdin := io.Pipe()
+	defer stdin.Close()
+	stdout, stderr := &lockedBuffer{}, &lockedBuffer{}
+	cli := &ryskctl{stdin: bufio.NewReader(keys), stdout: stdout, stderr: stderr, getenv: func(name string) string { return s.Env[name] }}
+	done := make(chan int)
+	go func() {
+		done <- cli.run(context.Background(), []string{"watch", "-product", constants.PRODUCT_ETH_PERP.Symbol})
+	}()
+
+	// The open order and the book show, then the order is cancelled with keys.
+	require.Eventually(s.T(), func() bool {
+		return regexp.MustCompile(`bid\s+1000\s+0.5`).MatchString(stdout.String()) && strings.Contains(stdout.String(), "OPEN")
+	}, 5*time.Second, 10*time.Millisecond)
+	stdin.Write([]byte("c"))
+	require.Eventually(s.T(), func() bool { return strings.Contains(stdout.String(), "(y/n)") }, 5*time.Second, 10*time.Millisecond)
+	stdin.Write([]byte("y"))
+	require.Eventually(s.T(), func() bool { return strings.Contains(stdout.String(), "Cancelled order "+order.Id) }, 5*time.Second, 10*time.Millisecond)
+	require.Equal(s.T(), 0, s.run("", "orders", "list"), s.stderr.String())
+	require.NotContains(s.T(), s.stdout.String(), order.Id)
+
+	stdin.Write([]byte("q"))
+	select {
+	case code := <-done:
+		require.Equal(s.T(), 0, code, stderr.String())
+	case <-time.After(5 * time.Second):
+		s.T().Fatal("watch did not quit")
+	}
+
+	require.Equal(s.T(), 2, s.run("", "watch"))
+	require.Equal(s.T(), 1, s.run("", "watch", "-product", constants.PRODUCT_ETH_PERP.Symbol, "-slippage", "2"))
+}
+
+// recordingClient records the requests of a dashboard.
+type recordingClient struct {
+	requests []string
+	order    *types.NewOrderRequest
+}
+
+func (client *recordingClient) ListOpenOrdersCtx(ctx context.Context, messageId string, params *types.ListOrdersRequest) error {
+	client.requests = append(client.requests, messageId)
+	return nil
+}
+
+func (client *recordingClient) GetPerpetualPositionCtx(ctx context.Context, messageId string, products []*types.Product) error {
+	client.requests = append(client.requests, messageId)
+	return nil
+}
+
+func (client *recordingClient) OrderBookCtx(ctx context.Context, messageId string, params *types.OrderBookRequest) error {
+	client.requests = append(client.requests, messageId)
+	return nil
+}
+
+func (client *recordingClient) CancelOrderCtx(ctx context.Context, messageId string, params *types.CancelOrderRequest) error {
+	client.requests = append(client.requests, messageId+" "+params.IdToCancel)
+	return nil
+}
+
+func (client *recordingClient) CancelAllOpenOrdersCtx(ctx context.Context, messageId string, product *types.Product) error {
+	client.requests = append(client.requests, messageId)
+	return nil
+}
+
+func (client *recordingClient) NewOrderCtx(ctx context.Context, messageId string, params *types.NewOrderRequest) error {
+	client.requests = append(client.requests, messageId)
+	client.order = params
+	return nil
+}
+
+func (s *RyskctlUnitTestSuite) TestUnit_Dashboard() {
+	client := &recordingClient{}
+	product := constants.PRODUCT_ETH_PERP
+	board := &dashboard{client: client, product: &product, increment: constants.E16, title: "title", limit: constants.LIMIT_FIVE, maxTrades: 2, slippage: 0.05, orders: make(map[string]types.Order)}
+	e18 := func(value int64) string { return new(big.Int).Mul(big.NewInt(value), constants.E18).String() }
+
+	// Snapshots, stream messages and account updates.
+	board.handleRPC([]byte(fmt.Sprintf(`{"id":"watch-orders","result":[{"id":"b","productId":%d,"isBuy":true,"price":"%s","quantity":"%s","status":"OPEN","createdAt":2},{"id":"a","productId":%d,"price":"%s","quantity":"%s","status":"OPEN","createdAt":1}]}`, product.Id, e18(900), e18(1), product.Id, e18(1200), e18(1))))
+	board.handleRPC([]byte(fmt.Sprintf(`{"id":"watch-positions","result":[{"productId":%d,"quantity":"-%s","avgEntryPrice":"%s","margin":"%s"}]}`, product.Id, e18(2), e18(1100), e18(50))))
+	board.handleStream([]byte(fmt.Sprintf(`{"stream":"ethperp@depth5_0","data":{"bids":[["%s","%s"]],"asks":[["%s","%s"]]}}`, e18(990), e18(3), e18(1010), e18(4))))
+	for _, price := range []int64{1020, 1030, 1000} {
+		board.handleStream([]byte(fmt.Sprintf(`{"stream":"ethperp@trade","data":{"price":"%s","quantity":"%s","time":1704067200000}}`, e18(price), e18(1))))
+	}
+	require.Len(s.T(), board.trades, 2)
+	require.Equal(s.T(), e18(1000), board.trades[0].Price)
+	require.Equal(s.T(), e18(1000), board.markPrice().String())
+	require.Equal(s.T(), []string{"a", "b"}, []string{board.openOrders()[0].Id, board.openOrders()[1].Id})
+
+	frame := strings.Join(board.render(200, 100), "\n")
+	require.Regexp(s.T(), `ask\s+1010\s+4`, frame)
+	require.Regexp(s.T(), `bid\s+990\s+3`, frame)
+	require.Contains(s.T(), frame, ">  a")
+	require.Regexp(s.T(), `ethperp\s+-2\s+1100\s+1000\s+200\s+50`, frame)
+	require.Len(s.T(), board.render(10, 5), 5)
+
+	// Filled orders leave the table.
+	board.handleRPC([]byte(fmt.Sprintf(`{"method":"account.updates","params":{"type":"order","order":{"id":"b","productId":%d,"status":"FILLED"}}}`, product.Id)))
+	require.Len(s.T(), board.openOrders(), 1)
+
+	// Actions wait for confirmation.
+	require.False(s.T(), board.handleKey(keyDown))
+	require.False(s.T(), board.handleKey(keyCancel))
+	require.Contains(s.T(), board.status, "Cancel sell order a")
+	require.False(s.T(), board.handleKey('n'))
+	require.Equal(s.T(), "Aborted", board.status)
+	require.Empty(s.T(), client.requests)
+	board.handleKey(keyCancel)
+	board.handleKey('y')
+	board.handleKey(keyCancelAll)
+	board.handleKey('y')
+	board.handleKey(keyRefresh)
+	require.Equal(s.T(), []string{"watch-cancel a", "watch-cancel-all", "watch-orders", "watch-positions", "watch-depth"}, client.requests)
+
+	// Flattening the short buys up to the best ask plus the slippage.
+	board.yes = true
+	board.handleKey(keyFlatten)
+	require.NotNil(s.T(), client.order)
+	require.True(s.T(), client.order.IsBuy)
+	require.Equal(s.T(), constants.ORDER_TYPE_MARKET, client.order.OrderType)
+	require.Equal(s.T(), e18(2), client.order.Quantity)
+	require.Equal(s.T(), new(big.Int).Mul(big.NewInt(10605), constants.E17).String(), client.order.Price)
+
+	board.handleRPC([]byte(fmt.Sprintf(`{"method":"account.updates","params":{"type":"position","position":{"productId":%d,"quantity":"0"}}}`, product.Id)))
+	board.handleKey(keyFlatten)
+	require.Equal(s.T(), "no position to flatten", board.status)
+	board.handleRPC([]byte(`{"id":"watch-cancel","error":{"code":404,"message":"order not found"}}`))
+	require.Equal(s.T(), "cancel failed: order not found", board.status)
+	require.True(s.T(), board.handleKey(keyQuit))
+}
+
+func (s *RyskctlUnitTestSuite) TestUnit_Amounts() {
+	amount, err := parseAmount("1.5", 6)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), big.NewInt(1500000), amount)
+	_, err = parseAmount("0.0000001", 6)
+	require.Error(s.T(), err)
+	_, err = parseAmount("-1", 18)
+	require.Error(s.T(), err)
+	_, err = parseAmount("abc", 18)
+	require.Error(s.T(), err)
+
+	require.Equal(s.T(), "2500.5", formatE18(new(big.Int).Mul(big.NewInt(25005), constants.E17).String()))
+	require.Equal(s.T(), "-1", formatE18("-"+constants.E18.String()))
+	require.Equal(s.T(), "0", formatE18("0"))
+	require.Equal(s.T(), "n/a", formatE18("n/a"))
+
+	milliseconds, err := parseTime("2024-01-01T00:00:00Z")
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), int64(1704067200000), milliseconds)
+	milliseconds, err = parseTime("1704067200000")
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), int64(1704067200000), milliseconds)
+	_, err = parseTime("yesterday")
+	require.Error(s.T(), err)
+}
@@ -0,0 +1,627 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+	"golang.org/x/term"
+)
+
+const (
+	WATCH_RENDER_INTERVAL  time.Duration = 100 * time.Millisecond // WATCH_RENDER_INTERVAL is the shortest delay between two frames of the dashboard.
+	DEFAULT_WATCH_TRADES   int           = 10                     // DEFAULT_WATCH_TRADES is the default number of recent trades shown.
+	DEFAULT_WATCH_SLIPPAGE float64       = 0.05                   // DEFAULT_WATCH_SLIPPAGE is the default worst price of flattening orders, as a fraction of the best price.
+	DEFAULT_WATCH_WIDTH    int           = 100                    // DEFAULT_WATCH_WIDTH is the width of frames written to anything but a terminal.
+	DEFAULT_WATCH_HEIGHT   int           = 50                     // DEFAULT_WATCH_HEIGHT is the height of frames written to anything but a terminal.
+)
+
+// Message IDs of the dashboard requests.
+const (
+	watchLogin     = "watch-login"
+	watchProduct   = "watch-product"
+	watchOrders    = "watch-orders"
+	watchPositions = "watch-positions"
+	watchDepth     = "watch-depth"
+	watchCancel    = "watch-cancel"
+	watchCancelAll = "watch-cancel-all"
+	watchFlatten   = "watch-flatten"
+	watchSubscribe = "watch-subscribe"
+)
+
+// Keys handled by the dashboard, arrow keys being mapped to `j` and `k`.
+const (
+	keyUp        = 'k'
+	keyDown      = 'j'
+	keyCancel    = 'c'
+	keyCancelAll = 'a'
+	keyFlatten   = 'f'
+	keyRefresh   = 'r'
+	keyQuit      = 'q'
+	keyInterrupt = 3 // Ctrl-C, which raw terminals do not turn into a signal.
+)
+
+// watchClient is the part of `ws_client.RyskV2WSClient` used by the dashboard to load and act on the account.
+type watchClient interface {
+	ListOpenOrdersCtx(ctx context.Context, messageId string, params *types.ListOrdersRequest) error
+	GetPerpetualPositionCtx(ctx context.Context, messageId string, products []*types.Product) error
+	OrderBookCtx(ctx context.Context, messageId string, params *types.OrderBookRequest) error
+	CancelOrderCtx(ctx context.Context, messageId string, params *types.CancelOrderRequest) error
+	CancelAllOpenOrdersCtx(ctx context.Context, messageId string, product *types.Product) error
+	NewOrderCtx(ctx context.Context, messageId string, params *types.NewOrderRequest) error
+}
+
+// watchedProduct is a product as returned by the exchange, with its price increment.
+type watchedProduct struct {
+	Id        int64  `json:"id"`        // The ID of the product.
+	Symbol    string `json:"symbol"`    // The symbol of the product.
+	Increment string `json:"increment"` // Price increment in wei (e18).
+}
+
+// rpcMessage is a response or notification read from the RPC websocket.
+type rpcMessage struct {
+	ID     string                `json:"id"`
+	Method types.WSMethod        `json:"method"`
+	Result json.RawMessage       `json:"result"`
+	Params json.RawMessage       `json:"params"`
+	Error  *types.WebsocketError `json:"error"`
+}
+
+// streamMessage is a message read from the stream websocket.
+type streamMessage struct {
+	Stream string                `json:"stream"`
+	Data   json.RawMessage       `json:"data"`
+	Error  *types.WebsocketError `json:"error"`
+}
+
+// dashboard holds the state shown by `watch`: the book and recent trades of a product, and the open orders
+// and position of the account on it. It is updated from websocket messages and keys by a single goroutine.
+type dashboard struct {
+	client    watchClient
+	product   *types.Product
+	increment *big.Int
+	title     string
+	limit     types.Limit
+	maxTrades int
+	slippage  float64
+	yes       bool // yes runs actions without confirmation.
+
+	depth    types.OrderBookDepth
+	trades   []types.Trade            // trades holds the recent trades, newest first.
+	orders   map[string]types.Order   // orders holds the open orders by ID.
+	position *types.PerpetualPosition // position is the position on the product, nil if flat.
+	selected int                      // selected is the index of the selected open order.
+	status   string                   // status is the outcome of the last action or the pending confirmation.
+	pending  func() error             // pending is the action awaiting confirmation.
+}
+
+// refresh requests snapshots of the open orders, position and book, answered on the RPC websocket.
+func (board *dashboard) refresh(ctx context.Context) error {
+	if err := board.client.ListOpenOrdersCtx(ctx, watchOrders, &types.ListOrdersRequest{Product: board.product}); err != nil {
+		return err
+	}
+	if err := board.client.GetPerpetualPositionCtx(ctx, watchPositions, []*types.Product{board.product}); err != nil {
+		return err
+	}
+	return board.client.OrderBookCtx(ctx, watchDepth, &types.OrderBookRequest{Product: board.product, Limit: board.limit})
+}
+
+// handleStream applies a message of the depth or trade stream.
+func (board *dashboard) handleStream(body []byte) {
+	var message streamMessage
+	if err := json.Unmarshal(body, &message); err != nil {
+		return
+	}
+	if message.Error != nil {
+		board.status = fmt.Sprintf("subscribe failed: %s", message.Error.Message)
+		return
+	}
+	switch {
+	case strings.HasSuffix(message.Stream, "@trade"):
+		var trade types.Trade
+		if json.Unmarshal(message.Data, &trade) == nil {
+			board.trades = append([]types.Trade{trade}, board.trades[:min(len(board.trades), board.maxTrades-1)]...)
+		}
+	case strings.Contains(message.Stream, "@depth"):
+		json.Unmarshal(message.Data, &board.depth)
+	}
+}
+
+// handleRPC applies a response to a dashboard request or an account update.
+func (board *dashboard) handleRPC(body []byte) {
+	var message rpcMessage
+	if err := json.Unmarshal(body, &message); err != nil {
+		return
+	}
+	if message.Error != nil {
+		board.status = fmt.Sprintf("%s failed: %s", strings.TrimPrefix(message.ID, "watch-"), message.Error.Message)
+		return
+	}
+
+	switch message.ID {
+	case watchOrders:
+		var orders []types.Order
+		if json.Unmarshal(message.Result, &orders) == nil {
+			board.orders = make(map[string]types.Order, len(orders))
+			for _, order := range orders {
+				board.updateOrder(order)
+			}
+		}
+	case watchPositions:
+		var positions []types.PerpetualPosition
+		if json.Unmarshal(message.Result, &positions) == nil {
+			board.position = nil
+			for _, position := range positions {
+				board.updatePosition(position)
+			}
+		}
+	case watchDepth:
+		json.Unmarshal(message.Result, &board.depth)
+	case watchCancel:
+		var order types.Order
+		if json.Unmarshal(message.Result, &order) == nil {
+			board.status = fmt.Sprintf("Cancelled order %s", order.Id)
+		}
+	case watchCancelAll:
+		var orders []types.Order
+		if json.Unmarshal(message.Result, &orders) == nil {
+			board.status = fmt.Sprintf("Cancelled %d orders", len(orders))
+		}
+	case watchFlatten:
+		var order types.Order
+		if json.Unmarshal(message.Result, &order) == nil {
+			board.status = fmt.Sprintf("Flattening order %s %s, filled %s of %s", order.Id, order.Status, formatE18(order.Filled), formatE18(order.Quantity))
+		}
+	}
+
+	if message.Method == constants.WS_METHOD_ACCOUNT_UPDATES {
+		var update types.AccountUpdate
+		if json.Unmarshal(message.Params, &update) != nil {
+			return
+		}
+		if update.Order != nil {
+			board.updateOrder(*update.Order)
+		}
+		if update.Position != nil {
+			board.updatePosition(*update.Position)
+		}
+	}
+}
+
+// updateOrder keeps an order of the product while it is open.
+func (board *dashboard) updateOrder(order types.Order) {
+	if order.ProductId != board.product.Id {
+		return
+	}
+	if order.Status == constants.ORDER_STATUS_OPEN || order.Status == constants.ORDER_STATUS_PARTIALLY_FILLED {
+		board.orders[order.Id] = order
+	} else {
+		delete(board.orders, order.Id)
+	}
+}
+
+// updatePosition keeps the position of the product while it is not flat.
+func (board *dashboard) updatePosition(position types.PerpetualPosition) {
+	if position.ProductId != board.product.Id {
+		return
+	}
+	if quantity, ok := new(big.Int).SetString(position.Quantity, 10); ok && quantity.Sign() != 0 {
+		board.position = &position
+	} else {
+		board.position = nil
+	}
+}
+
+// openOrders returns the open orders, oldest first.
+func (board *dashboard) openOrders() []types.Order {
+	orders := make([]types.Order, 0, len(board.orders))
+	for _, order := range board.orders {
+		orders = append(orders, order)
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].CreatedAt != orders[j].CreatedAt {
+			return orders[i].CreatedAt < orders[j].CreatedAt
+		}
+		return orders[i].Id < orders[j].Id
+	})
+	board.selected = max(min(board.selected, len(orders)-1), 0)
+	return orders
+}
+
+// markPrice returns the last trade price, or the mid price of the book, nil if neither is known.
+func (board *dashboard) markPrice() *big.Int {
+	if len(board.trades) > 0 {
+		if price, ok := new(big.Int).SetString(board.trades[0].Price, 10); ok {
+			return price
+		}
+	}
+	if len(board.depth.Bids) == 0 || len(board.depth.Asks) == 0 {
+		return nil
+	}
+	bid, okBid := new(big.Int).SetString(board.depth.Bids[0][0], 10)
+	ask, okAsk := new(big.Int).SetString(board.depth.Asks[0][0], 10)
+	if !okBid || !okAsk {
+		return nil
+	}
+	return new(big.Int).Rsh(new(big.Int).Add(bid, ask), 1)
+}
+
+// handleKey runs the action of a key, or answers the pending confirmation.
+//
+// Returns:
+//   - Whether the key quits the dashboard.
+func (board *dashboard) handleKey(key byte) bool {
+	if board.pending != nil {
+		action := board.pending
+		board.pending = nil
+		board.status = "Aborted"
+		if key == 'y' || key == 'Y' {
+			board.run(action)
+		}
+		return false
+	}
+
+	orders := board.openOrders()
+	switch key {
+	case keyQuit, keyInterrupt:
+		return true
+	case keyUp:
+		board.selected = max(board.selected-1, 0)
+	case keyDown:
+		board.selected = min(board.selected+1, max(len(orders)-1, 0))
+	case keyCancel:
+		if len(orders) == 0 {
+			board.status = "No open order to cancel"
+			return false
+		}
+		order := orders[board.selected]
+		board.ask(fmt.Sprintf("Cancel %s order %s of %s at %s?", side(order.IsBuy), order.Id, formatE18(order.Quantity), formatE18(order.Price)), func() error {
+			return board.client.CancelOrderCtx(context.Background(), watchCancel, &types.CancelOrderRequest{Product: board.product, IdToCancel: order.Id})
+		})
+	case keyCancelAll:
+		board.ask(fmt.Sprintf("Cancel all %d open orders on %s?", len(orders), board.product.Symbol), func() error {
+			return board.client.CancelAllOpenOrdersCtx(context.Background(), watchCancelAll, board.product)
+		})
+	case keyFlatten:
+		request, err := board.flattenRequest()
+		if err != nil {
+			board.status = err.Error()
+			return false
+		}
+		board.ask(fmt.Sprintf("Flatten with a market %s of %s, worst price %s?", side(request.IsBuy), formatE18(request.Quantity), formatE18(request.Price)), func() error {
+			return board.client.NewOrderCtx(context.Background(), watchFlatten, request)
+		})
+	case keyRefresh:
+		board.run(func() error { return board.refresh(context.Background()) })
+	}
+	return false
+}
+
+// ask runs an action once confirmed, or at once when confirmations are skipped.
+func (board *dashboard) ask(prompt string, action func() error) {
+	if board.yes {
+		board.run(action)
+		return
+	}
+	board.status = prompt + " (y/n)"
+	board.pending = action
+}
+
+// run sends the request of an action, its response updating the status.
+func (board *dashboard) run(action func() error) {
+	board.status = "Sent"
+	if err := action(); err != nil {
+		board.status = err.Error()
+	}
+}
+
+// flattenRequest creates the market order closing the position, its worst price `slippage` away from the best price.
+func (board *dashboard) flattenRequest() (*types.NewOrderRequest, error) {
+	if board.position == nil {
+		return nil, fmt.Errorf("no position to flatten")
+	}
+	quantity, _ := new(big.Int).SetString(board.position.Quantity, 10)
+	isBuy := quantity.Sign() < 0
+	levels := board.depth.Bids
+	if isBuy {
+		levels = board.depth.Asks
+	}
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("no liquidity to flatten against")
+	}
+
+	// Worst price, rounded to the increment.
+	best, ok := new(big.Rat).SetString(levels[0][0])
+	if !ok {
+		return nil, fmt.Errorf("invalid book price %q", levels[0][0])
+	}
+	factor := 1 - board.slippage
+	if isBuy {
+		factor = 1 + board.slippage
+	}
+	worstRat := new(big.Rat).Mul(best, new(big.Rat).SetFloat64(factor))
+	worst := new(big.Int).Quo(worstRat.Num(), worstRat.Denom())
+	if board.increment != nil && board.increment.Sign() > 0 {
+		worst.Sub(worst, new(big.Int).Mod(worst, board.increment))
+	}
+	return &types.NewOrderRequest{
+		Product:     board.product,
+		IsBuy:       isBuy,
+		OrderType:   constants.ORDER_TYPE_MARKET,
+		TimeInForce: constants.TIME_IN_FORCE_IOC,
+		Price:       worst.String(),
+		Quantity:    new(big.Int).Abs(quantity).String(),
+		Expiration:  time.Now().Add(time.Minute).UnixMilli(),
+		Nonce:       time.Now().UnixMilli(),
+	}, nil
+}
+
+// render draws the dashboard as lines fitting the given size.
+func (board *dashboard) render(width int, height int) []string {
+	lines := []string{board.title, ""}
+
+	// Ladder, asks above bids, next to the recent trades.
+	ladderRows := [][]string{}
+	for i := len(board.depth.Asks) - 1; i >= 0; i-- {
+		ladderRows = append(ladderRows, []string{"ask", formatE18(board.depth.Asks[i][0]), formatE18(board.depth.Asks[i][1])})
+	}
+	for _, bid := range board.depth.Bids {
+		ladderRows = append(ladderRows, []string{"bid", formatE18(bid[0]), formatE18(bid[1])})
+	}
+	ladder := table([]string{"BOOK", "PRICE", "QUANTITY"}, ladderRows)
+	tradeRows := [][]string{}
+	for _, trade := range board.trades {
+		tradeRows = append(tradeRows, []string{time.UnixMilli(trade.Time).UTC().Format("15:04:05"), side(!trade.IsBuyerMaker), formatE18(trade.Price), formatE18(trade.Quantity)})
+	}
+	trades := table([]string{"TIME", "SIDE", "PRICE", "QUANTITY"}, tradeRows)
+	ladderWidth := 0
+	for _, line := range ladder {
+		ladderWidth = max(ladderWidth, len(line))
+	}
+	for i := 0; i < max(len(ladder), len(trades)); i++ {
+		left, right := "", ""
+		if i < len(ladder) {
+			left = ladder[i]
+		}
+		if i < len(trades) {
+			right = trades[i]
+		}
+		lines = append(lines, fmt.Sprintf("%-*s    %s", ladderWidth, left, right))
+	}
+
+	// Open orders, the selected one marked.
+	header, rows := orderRows(board.openOrders())
+	for i := range rows {
+		marker := " "
+		if i == board.selected {
+			marker = ">"
+		}
+		rows[i] = append([]string{marker}, rows[i]...)
+	}
+	lines = append(lines, "", "OPEN ORDERS")
+	lines = append(lines, table(append([]string{" "}, header...), rows)...)
+
+	// Position and its unrealised PnL at the mark price.
+	mark := board.markPrice()
+	positionRows := [][]string{}
+	if position := board.position; position != nil {
+		markPrice, pnl := "-", "-"
+		quantity, okQuantity := new(big.Int).SetString(position.Quantity, 10)
+		entry, okEntry := new(big.Int).SetString(position.AvgEntryPrice, 10)
+		if mark != nil && okQuantity && okEntry {
+			markPrice = formatE18(mark.String())
+			pnl = formatE18(new(big.Int).Quo(new(big.Int).Mul(new(big.Int).Sub(mark, entry), quantity), constants.E18).String())
+		}
+		positionRows = append(positionRows, []string{board.product.Symbol, formatE18(position.Quantity), formatE18(position.AvgEntryPrice), markPrice, pnl, formatE18(position.Margin)})
+	}
+	lines = append(lines, "", "POSITION")
+	lines = append(lines, table([]string{"PRODUCT", "QUANTITY", "ENTRY PRICE", "MARK PRICE", "UNREALISED PNL", "MARGIN"}, positionRows)...)
+
+	// Status and keys, kept at the bottom.
+	footer := []string{"", board.status, "↑/↓ select  c cancel  a cancel all  f flatten  r refresh  q quit"}
+	if len(lines)+len(footer) > height {
+		lines = lines[:max(height-len(footer), 0)]
+	}
+	lines = append(lines, footer...)
+	for i, line := range lines {
+		if runes := []rune(line); len(runes) > width {
+			lines[i] = string(runes[:width])
+		}
+	}
+	return lines
+}
+
+// table formats rows as aligned columns.
+func table(header []string, rows [][]string) []string {
+	var buffer bytes.Buffer
+	writer := tabwriter.NewWriter(&buffer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	writer.Flush()
+	return strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+}
+
+// watchEvent is a message, key or error received by the dashboard.
+type watchEvent struct {
+	stream []byte // stream is a stream websocket message.
+	rpc    []byte // rpc is an RPC websocket message.
+	key    byte   // key is a pressed key, when neither message is set.
+	err    error  // err ends the dashboard.
+}
+
+func (cli *ryskctl) watch(ctx context.Context, args []string) error {
+	flags := cli.flagSet("watch")
+	symbol := flags.String("product", "", "product symbol")
+	limit := flags.Int64("limit", int64(constants.LIMIT_TEN), "number of book levels per side: 5, 10 or 20")
+	maxTrades := flags.Int("trades", DEFAULT_WATCH_TRADES, "number of recent trades shown")
+	slippage := flags.Float64("slippage", DEFAULT_WATCH_SLIPPAGE, "worst price of flattening orders, as a fraction of the best price")
+	if err := cli.parse(flags, args, "product"); err != nil {
+		return err
+	}
+	if *maxTrades <= 0 || *slippage <= 0 || *slippage >= 1 {
+		return fmt.Errorf("invalid -trades or -slippage: trades must be positive and slippage between 0 and 1")
+	}
+	client, err := cli.wsClient()
+	if err != nil {
+		return err
+	}
+	defer client.RPCConnection.Close()
+	defer client.StreamConnection.Close()
+
+	// Log in and resolve the product before reading messages asynchronously.
+	if err := client.LoginCtx(ctx, watchLogin); err != nil {
+		return err
+	}
+	if _, err := utils.ReadRPCResponse(client.RPCConnection, watchLogin); err != nil {
+		return fmt.Errorf("failed to log in: %v", err)
+	}
+	if err := client.GetProductCtx(ctx, watchProduct, &types.Product{Symbol: *symbol}); err != nil {
+		return err
+	}
+	response, err := utils.ReadRPCResponse(client.RPCConnection, watchProduct)
+	if err != nil {
+		return fmt.Errorf("failed to get product %s: %v", *symbol, err)
+	}
+	var product watchedProduct
+	result, _ := json.Marshal(response.Result)
+	if err := json.Unmarshal(result, &product); err != nil {
+		return fmt.Errorf("failed to decode product %s: %v", *symbol, err)
+	}
+	increment, _ := new(big.Int).SetString(product.Increment, 10)
+
+	board := &dashboard{
+		client:    client,
+		product:   &types.Product{Id: product.Id, Symbol: product.Symbol},
+		increment: increment,
+		title:     fmt.Sprintf("ryskctl watch  %s  %s  sub-account %d", product.Symbol, cli.config.Env, cli.config.SubAccountId),
+		limit:     types.Limit(*limit),
+		maxTrades: *maxTrades,
+		slippage:  *slippage,
+		yes:       cli.yes,
+		orders:    make(map[string]types.Order),
+	}
+
+	// Subscribe to the book, trades and account updates, then load the snapshots.
+	if err := client.SubscribePartialBookDepthCtx(ctx, watchSubscribe, []*types.Product{board.product}, []types.Limit{board.limit}, []int64{0}); err != nil {
+		return fmt.Errorf("failed to subscribe: %v", err)
+	}
+	if err := client.SubscribeSingleTradesCtx(ctx, watchSubscribe, []*types.Product{board.product}); err != nil {
+		return fmt.Errorf("failed to subscribe: %v", err)
+	}
+	if err := client.AccountUpdatesCtx(ctx, watchSubscribe); err != nil {
+		return fmt.Errorf("failed to subscribe: %v", err)
+	}
+	if err := board.refresh(ctx); err != nil {
+		return err
+	}
+
+	// Draw on the alternate screen of raw terminals.
+	width, height := DEFAULT_WATCH_WIDTH, DEFAULT_WATCH_HEIGHT
+	if cli.terminal != nil {
+		state, err := term.MakeRaw(int(cli.terminal.Fd()))
+		if err != nil {
+			return fmt.Errorf("failed to set up terminal: %v", err)
+		}
+		defer term.Restore(int(cli.terminal.Fd()), state)
+		fmt.Fprint(cli.stdout, "\x1b[?1049h\x1b[?25l")
+		defer fmt.Fprint(cli.stdout, "\x1b[?25h\x1b[?1049l")
+	}
+
+	// Read messages and keys.
+	events := make(chan watchEvent, 256)
+	done := make(chan struct{})
+	defer close(done)
+	emit := func(event watchEvent) bool {
+		select {
+		case events <- event:
+			return true
+		case <-done:
+			return false
+		}
+	}
+	read := func(connection *websocket.Conn, isStream bool) {
+		for {
+			_, body, err := connection.ReadMessage()
+			if err != nil {
+				emit(watchEvent{err: fmt.Errorf("connection lost: %v", err)})
+				return
+			}
+			event := watchEvent{rpc: body}
+			if isStream {
+				event = watchEvent{stream: body}
+			}
+			if !emit(event) {
+				return
+			}
+		}
+	}
+	go read(client.StreamConnection, true)
+	go read(client.RPCConnection, false)
+	go func() {
+		for {
+			key, err := cli.stdin.ReadByte()
+			if err != nil {
+				return
+			}
+			// Arrow keys are escape sequences, written at once by terminals.
+			if key == 0x1b && cli.stdin.Buffered() >= 2 {
+				if next, _ := cli.stdin.Peek(2); len(next) == 2 && next[0] == '[' && (next[1] == 'A' || next[1] == 'B') {
+					cli.stdin.Discard(2)
+					key = map[byte]byte{'A': keyUp, 'B': keyDown}[next[1]]
+				}
+			}
+			if !emit(watchEvent{key: key}) {
+				return
+			}
+		}
+	}()
+
+	// Apply events, drawing at most once per interval.
+	ticker := time.NewTicker(WATCH_RENDER_INTERVAL)
+	defer ticker.Stop()
+	dirty := true
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-events:
+			switch {
+			case event.err != nil:
+				if ctx.Err() != nil {
+					return nil
+				}
+				return event.err
+			case event.stream != nil:
+				board.handleStream(event.stream)
+			case event.rpc != nil:
+				board.handleRPC(event.rpc)
+			case board.handleKey(event.key):
+				return nil
+			}
+			dirty = true
+		case <-ticker.C:
+			if !dirty {
+				continue
+			}
+			if cli.terminal != nil {
+				if terminalWidth, terminalHeight, err := term.GetSize(int(cli.terminal.Fd())); err == nil {
+					width, height = terminalWidth, terminalHeight
+				}
+			}
+			fmt.Fprint(cli.stdout, "\x1b[H\x1b[2J"+strings.Join(board.render(width, height), "\r\n"))
+			dirty = false
+		}
+	}
+}
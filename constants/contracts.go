@@ -8,6 +8,7 @@ const (
 	DOMAIN_NAME    string = "rysk"
 	DOMAIN_VERSION string = "0.0.0"
 	EIP_712_DOMAIN string = "EIP712Domain"
+	LOGIN_MESSAGE  string = "I want to log into rysk.finance"
 
 	PRIMARY_TYPE_LOGIN_MESSAGE         types.PrimaryType = "LoginMessage"
 	PRIMARY_TYPE_ORDER                 types.PrimaryType = "Order"
@@ -28,3 +28,5 @@ const (
 	API_ENDPOINT_LIST_ORDERS                            types.APIEndpoint = "/orders"
 	API_ENDPOINT_ADD_REFEREE                            types.APIEndpoint = "/referral/add-referee"
 )
+
+const HEADER_REQUEST_ID = "X-Request-Id" // Header carrying the ID the exchange assigned to a request.
@@ -2,6 +2,7 @@ package constants
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/rysk-finance/v2_client_go/types"
 )
@@ -30,6 +31,21 @@ const (
 	INTERVAL_1W  types.Interval = "1w"  // 1 week
 )
 
+// INTERVAL_DURATIONS maps kline intervals to their durations.
+var INTERVAL_DURATIONS = map[types.Interval]time.Duration{
+	INTERVAL_1M:  time.Minute,
+	INTERVAL_5M:  5 * time.Minute,
+	INTERVAL_15M: 15 * time.Minute,
+	INTERVAL_30M: 30 * time.Minute,
+	INTERVAL_1H:  time.Hour,
+	INTERVAL_2H:  2 * time.Hour,
+	INTERVAL_4H:  4 * time.Hour,
+	INTERVAL_8H:  8 * time.Hour,
+	INTERVAL_D1:  24 * time.Hour,
+	INTERVAL_D3:  3 * 24 * time.Hour,
+	INTERVAL_1W:  7 * 24 * time.Hour,
+}
+
 const (
 	LIMIT_FIVE   types.Limit = 5
 	LIMIT_TEN    types.Limit = 10
@@ -64,3 +80,17 @@ var (
 	E4  = big.NewInt(1e4)
 	E3  = big.NewInt(1e3)
 )
+
+const (
+	ORDER_STATUS_OPEN             = "OPEN"
+	ORDER_STATUS_PARTIALLY_FILLED = "PARTIALLY_FILLED"
+	ORDER_STATUS_FILLED           = "FILLED"
+	ORDER_STATUS_CANCELLED        = "CANCELLED"
+	ORDER_STATUS_EXPIRED          = "EXPIRED"
+)
+
+const (
+	ACCOUNT_UPDATE_ORDER    = "order"
+	ACCOUNT_UPDATE_POSITION = "position"
+	ACCOUNT_UPDATE_BALANCE  = "balance"
+)
@@ -0,0 +1,15 @@
+package constants
+
+import "github.com/rysk-finance/v2_client_go/types"
+
+const (
+	ENDPOINT_CLASS_PUBLIC       types.EndpointClass = "public"       // Public market data, e.g. products, order book and klines.
+	ENDPOINT_CLASS_PRIVATE_READ types.EndpointClass = "private_read" // Account reads, e.g. balances, positions and orders.
+	ENDPOINT_CLASS_ORDER_ENTRY  types.EndpointClass = "order_entry"  // Signed account writes, e.g. orders, cancels, signers and withdrawals.
+)
+
+const (
+	HEADER_RATE_LIMIT_LIMIT     = "X-RateLimit-Limit"     // Requests allowed per window.
+	HEADER_RATE_LIMIT_REMAINING = "X-RateLimit-Remaining" // Requests left in the current window.
+	HEADER_RATE_LIMIT_RESET     = "X-RateLimit-Reset"     // Seconds until the current window resets.
+)
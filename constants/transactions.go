@@ -0,0 +1,17 @@
+package constants
+
+import "github.com/rysk-finance/v2_client_go/types"
+
+const (
+	GAS_STRATEGY_SUGGESTED  types.GasStrategy = "suggested"  // Node suggested priority fee, max fee of twice the base fee plus priority fee.
+	GAS_STRATEGY_FIXED      types.GasStrategy = "fixed"      // Caller provided priority fee and max fee.
+	GAS_STRATEGY_MULTIPLIER types.GasStrategy = "multiplier" // Suggested fees scaled by a multiplier.
+	GAS_STRATEGY_CAP        types.GasStrategy = "cap"        // Suggested fees bounded by a maximum fee.
+)
+
+const BASE_FEE_MULTIPLIER int64 = 2
+
+const (
+	APPROVAL_MODE_EXACT types.ApprovalMode = "exact" // Approve exactly the deposited amount.
+	APPROVAL_MODE_MAX   types.ApprovalMode = "max"   // Approve the maximum uint256 amount once.
+)
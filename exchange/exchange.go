@@ -0,0 +1,238 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+// IExchange is the account surface of the exchange, independent of the transport carrying it.
+type IExchange interface {
+	NewOrder(ctx context.Context, params *types.NewOrderRequest) (*types.Order, error)
+	CancelOrderAndReplace(ctx context.Context, params *types.CancelOrderAndReplaceRequest) (*types.Order, error)
+	CancelOrder(ctx context.Context, params *types.CancelOrderRequest) (*types.Order, error)
+	CancelAllOpenOrders(ctx context.Context, product *types.Product) ([]types.Order, error)
+	ListOpenOrders(ctx context.Context, product *types.Product) ([]types.Order, error)
+	ApproveSigner(ctx context.Context, params *types.ApproveRevokeSignerRequest) (*types.ApprovedSigner, error)
+	RevokeSigner(ctx context.Context, params *types.ApproveRevokeSignerRequest) (*types.ApprovedSigner, error)
+	Withdraw(ctx context.Context, params *types.WithdrawRequest) (*types.SpotBalance, error)
+	PerpetualPositions(ctx context.Context) ([]types.PerpetualPosition, error)
+	SpotBalances(ctx context.Context) ([]types.SpotBalance, error)
+}
+
+// ErrUnsupported is wrapped in the TransportError of an operation the transport cannot carry, so a
+// FailoverExchange sends it through the fallback exchange.
+var ErrUnsupported = errors.New("operation not supported by the transport")
+
+// TransportError reports a request which failed before the exchange answered it, e.g. on a closed connection or a timeout.
+// Requests rejected by the exchange are not transport errors.
+type TransportError struct {
+	Sent bool  // Whether the request may have reached the exchange. A request that was not sent had no effect.
+	Err  error // The underlying error.
+}
+
+func (transportError *TransportError) Error() string {
+	return fmt.Sprintf("transport error: %v", transportError.Err)
+}
+
+func (transportError *TransportError) Unwrap() error {
+	return transportError.Err
+}
+
+// FailoverExchangeConfiguration holds the configuration for a failover exchange.
+type FailoverExchangeConfiguration struct {
+	Primary    IExchange                         // Exchange used first, e.g. a `WSExchange`.
+	Fallback   IExchange                         // Exchange used when the primary fails in transport, e.g. a `RESTExchange`.
+	OnFailover func(operation string, err error) // Optional callback invoked with the operation and primary error on each failover.
+}
+
+// FailoverExchange sends requests through a primary exchange and falls back to another one on transport errors.
+// Read-only operations fall back on any `TransportError`. Mutating operations only fall back when the request
+// was not sent, so an order is never placed twice; otherwise the primary error is returned.
+type FailoverExchange struct {
+	primary    IExchange
+	fallback   IExchange
+	onFailover func(operation string, err error)
+}
+
+// NewFailoverExchange creates a new FailoverExchange instance.
+//
+// Parameters:
+//   - config: A pointer to FailoverExchangeConfiguration containing the configuration settings.
+//
+// Returns:
+//   - A pointer to FailoverExchange.
+//   - An error if the primary or fallback exchange is missing.
+func NewFailoverExchange(config *FailoverExchangeConfiguration) (*FailoverExchange, error) {
+	if config.Primary == nil || config.Fallback == nil {
+		return nil, fmt.Errorf("failover exchange requires a primary and a fallback exchange")
+	}
+	return &FailoverExchange{
+		primary:    config.Primary,
+		fallback:   config.Fallback,
+		onFailover: config.OnFailover,
+	}, nil
+}
+
+// NewOrder creates a new order.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - params: The order parameters.
+//
+// Returns:
+//   - A pointer to the created types.Order.
+//   - An error if the request fails on both exchanges, or on the primary one once sent.
+func (exchange *FailoverExchange) NewOrder(ctx context.Context, params *types.NewOrderRequest) (*types.Order, error) {
+	return failover(exchange, "NewOrder", true, func(target IExchange) (*types.Order, error) {
+		return target.NewOrder(ctx, params)
+	})
+}
+
+// CancelOrderAndReplace cancels an order and creates a new one in its place.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - params: The cancellation and replacement parameters.
+//
+// Returns:
+//   - A pointer to the replacement types.Order.
+//   - An error if the request fails on both exchanges, or on the primary one once sent.
+func (exchange *FailoverExchange) CancelOrderAndReplace(ctx context.Context, params *types.CancelOrderAndReplaceRequest) (*types.Order, error) {
+	return failover(exchange, "CancelOrderAndReplace", true, func(target IExchange) (*types.Order, error) {
+		return target.CancelOrderAndReplace(ctx, params)
+	})
+}
+
+// CancelOrder cancels an order.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - params: The cancellation parameters.
+//
+// Returns:
+//   - A pointer to the cancelled types.Order.
+//   - An error if the request fails on both exchanges, or on the primary one once sent.
+func (exchange *FailoverExchange) CancelOrder(ctx context.Context, params *types.CancelOrderRequest) (*types.Order, error) {
+	return failover(exchange, "CancelOrder", true, func(target IExchange) (*types.Order, error) {
+		return target.CancelOrder(ctx, params)
+	})
+}
+
+// CancelAllOpenOrders cancels all open orders for a product.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - product: The product whose orders are cancelled.
+//
+// Returns:
+//   - A slice of the cancelled types.Order.
+//   - An error if the request fails on both exchanges, or on the primary one once sent.
+func (exchange *FailoverExchange) CancelAllOpenOrders(ctx context.Context, product *types.Product) ([]types.Order, error) {
+	return failover(exchange, "CancelAllOpenOrders", true, func(target IExchange) ([]types.Order, error) {
+		return target.CancelAllOpenOrders(ctx, product)
+	})
+}
+
+// ListOpenOrders returns the open orders for a product.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - product: The product whose orders are listed.
+//
+// Returns:
+//   - A slice of types.Order.
+//   - An error if the request fails on both exchanges.
+func (exchange *FailoverExchange) ListOpenOrders(ctx context.Context, product *types.Product) ([]types.Order, error) {
+	return failover(exchange, "ListOpenOrders", false, func(target IExchange) ([]types.Order, error) {
+		return target.ListOpenOrders(ctx, product)
+	})
+}
+
+// ApproveSigner approves a signer for the sub-account.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - params: The signer and nonce.
+//
+// Returns:
+//   - A pointer to the types.ApprovedSigner.
+//   - An error if the request fails on both exchanges, or on the primary one once sent.
+func (exchange *FailoverExchange) ApproveSigner(ctx context.Context, params *types.ApproveRevokeSignerRequest) (*types.ApprovedSigner, error) {
+	return failover(exchange, "ApproveSigner", true, func(target IExchange) (*types.ApprovedSigner, error) {
+		return target.ApproveSigner(ctx, params)
+	})
+}
+
+// RevokeSigner revokes a signer of the sub-account.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - params: The signer and nonce.
+//
+// Returns:
+//   - A pointer to the types.ApprovedSigner.
+//   - An error if the request fails on both exchanges, or on the primary one once sent.
+func (exchange *FailoverExchange) RevokeSigner(ctx context.Context, params *types.ApproveRevokeSignerRequest) (*types.ApprovedSigner, error) {
+	return failover(exchange, "RevokeSigner", true, func(target IExchange) (*types.ApprovedSigner, error) {
+		return target.RevokeSigner(ctx, params)
+	})
+}
+
+// Withdraw initiates a withdrawal of USDC.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - params: The withdrawal quantity and nonce.
+//
+// Returns:
+//   - A pointer to the types.SpotBalance after the withdrawal.
+//   - An error if the request fails on both exchanges, or on the primary one once sent.
+func (exchange *FailoverExchange) Withdraw(ctx context.Context, params *types.WithdrawRequest) (*types.SpotBalance, error) {
+	return failover(exchange, "Withdraw", true, func(target IExchange) (*types.SpotBalance, error) {
+		return target.Withdraw(ctx, params)
+	})
+}
+
+// PerpetualPositions returns the perpetual positions across all products.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//
+// Returns:
+//   - A slice of types.PerpetualPosition.
+//   - An error if the request fails on both exchanges.
+func (exchange *FailoverExchange) PerpetualPositions(ctx context.Context) ([]types.PerpetualPosition, error) {
+	return failover(exchange, "PerpetualPositions", false, func(target IExchange) ([]types.PerpetualPosition, error) {
+		return target.PerpetualPositions(ctx)
+	})
+}
+
+// SpotBalances returns the spot balances.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//
+// Returns:
+//   - A slice of types.SpotBalance.
+//   - An error if the request fails on both exchanges.
+func (exchange *FailoverExchange) SpotBalances(ctx context.Context) ([]types.SpotBalance, error) {
+	return failover(exchange, "SpotBalances", false, func(target IExchange) ([]types.SpotBalance, error) {
+		return target.SpotBalances(ctx)
+	})
+}
+
+// failover calls the primary exchange, then the fallback one if the primary failed in transport and retrying is safe.
+func failover[T any](exchange *FailoverExchange, operation string, mutating bool, call func(target IExchange) (T, error)) (T, error) {
+	result, err := call(exchange.primary)
+	var transportError *TransportError
+	if err == nil || !errors.As(err, &transportError) || (mutating && transportError.Sent) {
+		return result, err
+	}
+	if exchange.onFailover != nil {
+		exchange.onFailover(operation, err)
+	}
+	return call(exchange.fallback)
+}
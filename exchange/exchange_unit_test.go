@@ -0,0 +1,828 @@
+//go:build !integration
+// +build !integration
+
+package exchange
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	geth_types "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rysk-finance/v2_client_go/api_client"
+	"github.com/rysk-finance/v2_client_go/audit"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/metrics"
+	"github.com/rysk-finance/v2_client_go/ryskfake"
+	"github.com/rysk-finance/v2_client_go/tracing"
+	"github.com/rysk-finance/v2_client_go/typed_data"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+	"github.com/rysk-finance/v2_client_go/utils/mocks"
+	"github.com/rysk-finance/v2_client_go/ws_client"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ExchangeUnitTestSuite struct {
+	suite.Suite
+	Server     *ryskfake.Server
+	PrivateKey string
+	APIClient  *api_client.RyskV2APIClient
+	WSClient   *ws_client.RyskV2WSClient
+	nonce      int64
+}
+
+func (s *ExchangeUnitTestSuite) SetupTest() {
+	server, err := ryskfake.NewServer(&ryskfake.ServerConfiguration{})
+	require.NoError(s.T(), err)
+	s.Server = server
+
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(s.T(), err)
+	s.PrivateKey = hex.EncodeToString(crypto.FromECDSA(privateKey))
+	s.APIClient = s.newAPIClient(s.PrivateKey)
+	s.WSClient, err = ws_client.NewRyskV2WSClient(&ws_client.RyskV2WSClientConfiguration{
+		Env:          constants.ENVIRONMENT_TESTNET,
+		PrivateKey:   s.PrivateKey,
+		RpcUrl:       server.URL(),
+		BaseUrl:      server.URL(),
+		WSRpcUrl:     server.RPCURL(),
+		WSStreamUrl:  server.StreamURL(),
+		SubAccountId: 1,
+	})
+	require.NoError(s.T(), err)
+	s.nonce = time.Now().UnixMilli()
+}
+
+func (s *ExchangeUnitTestSuite) TearDownTest() {
+	s.Server.Close()
+}
+
+func TestRunSuiteUnit_ExchangeUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(ExchangeUnitTestSuite))
+}
+
+func (s *ExchangeUnitTestSuite) newAPIClient(privateKey string) *api_client.RyskV2APIClient {
+	apiClient, err := api_client.NewRyskV2APIClient(&api_client.RyskV2APIClientConfiguration{
+		Env:          constants.ENVIRONMENT_TESTNET,
+		PrivateKey:   privateKey,
+		RpcUrl:       s.Server.URL(),
+		BaseUrl:      s.Server.URL(),
+		SubAccountId: 1,
+	})
+	require.NoError(s.T(), err)
+	return apiClient
+}
+
+func (s *ExchangeUnitTestSuite) nextNonce() int64 {
+	s.nonce++
+	return s.nonce
+}
+
+func (s *ExchangeUnitTestSuite) limitOrder(isBuy bool, price int64) *types.NewOrderRequest {
+	return &types.NewOrderRequest{
+		Product:     &constants.PRODUCT_ETH_PERP,
+		IsBuy:       isBuy,
+		OrderType:   constants.ORDER_TYPE_LIMIT,
+		TimeInForce: constants.TIME_IN_FORCE_GTC,
+		Price:       new(big.Int).Mul(big.NewInt(price), constants.E18).String(),
+		Quantity:    constants.E18.String(),
+		Expiration:  time.Now().Add(time.Hour).UnixMilli(),
+		Nonce:       s.nextNonce(),
+	}
+}
+
+// exercise runs every operation of an exchange acting on the suite account.
+func (s *ExchangeUnitTestSuite) exercise(exchange IExchange) {
+	ctx := context.Background()
+
+	// Orders.
+	order, err := exchange.NewOrder(ctx, s.limitOrder(true, 1000))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), constants.PRODUCT_ETH_PERP.Id, order.ProductId)
+	_, err = exchange.NewOrder(ctx, s.limitOrder(true, 1001))
+	require.NoError(s.T(), err)
+	_, err = exchange.NewOrder(ctx, s.limitOrder(true, 1002))
+	require.NoError(s.T(), err)
+
+	orders, err := exchange.ListOpenOrders(ctx, &constants.PRODUCT_ETH_PERP)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), orders, 3)
+
+	cancelled, err := exchange.CancelOrder(ctx, &types.CancelOrderRequest{Product: &constants.PRODUCT_ETH_PERP, IdToCancel: order.Id})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), order.Id, cancelled.Id)
+	cancelledOrders, err := exchange.CancelAllOpenOrders(ctx, &constants.PRODUCT_ETH_PERP)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), cancelledOrders, 2)
+	orders, err = exchange.ListOpenOrders(ctx, &constants.PRODUCT_ETH_PERP)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), orders)
+
+	// Rejections are not transport errors.
+	_, err = exchange.CancelOrder(ctx, &types.CancelOrderRequest{Product: &constants.PRODUCT_ETH_PERP, IdToCancel: "unknown"})
+	require.Error(s.T(), err)
+	var transportError *TransportError
+	require.False(s.T(), errors.As(err, &transportError))
+
+	// Positions, filled against another account.
+	maker, err := NewRESTExchange(s.newAPIClient(hex.EncodeToString(crypto.FromECDSA(must(crypto.GenerateKey())))))
+	require.NoError(s.T(), err)
+	_, err = maker.NewOrder(ctx, s.limitOrder(false, 1000))
+	require.NoError(s.T(), err)
+	_, err = exchange.NewOrder(ctx, s.limitOrder(true, 1000))
+	require.NoError(s.T(), err)
+	positions, err := exchange.PerpetualPositions(ctx)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), positions, 1)
+	require.Equal(s.T(), constants.E18.String(), positions[0].Quantity)
+
+	// Signers.
+	signer := common.HexToAddress("0x1234").Hex()
+	approved, err := exchange.ApproveSigner(ctx, &types.ApproveRevokeSignerRequest{ApprovedSigner: signer, Nonce: s.nextNonce()})
+	require.NoError(s.T(), err)
+	require.True(s.T(), approved.Approved)
+	revoked, err := exchange.RevokeSigner(ctx, &types.ApproveRevokeSignerRequest{ApprovedSigner: signer, Nonce: s.nextNonce()})
+	require.NoError(s.T(), err)
+	require.False(s.T(), revoked.Approved)
+
+	// Balances.
+	s.Server.Credit(s.APIClient.Address(), 1, s.APIClient.USDCAddress(), new(big.Int).Mul(big.NewInt(100), constants.E18))
+	nonce := s.nextNonce()
+	balance, err := exchange.Withdraw(ctx, &types.WithdrawRequest{Quantity: constants.E18.String(), Nonce: nonce})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), new(big.Int).Mul(big.NewInt(99), constants.E18).String(), balance.Quantity)
+
+	// Rejections map to specific errors.
+	_, err = exchange.Withdraw(ctx, &types.WithdrawRequest{Quantity: new(big.Int).Mul(big.NewInt(1000), constants.E18).String(), Nonce: s.nextNonce()})
+	var insufficientBalanceError *utils.InsufficientBalanceError
+	require.ErrorAs(s.T(), err, &insufficientBalanceError)
+	_, err = exchange.Withdraw(ctx, &types.WithdrawRequest{Quantity: constants.E18.String(), Nonce: nonce})
+	var validationError *utils.ValidationError
+	require.ErrorAs(s.T(), err, &validationError)
+
+	balances, err := exchange.SpotBalances(ctx)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), balances, 1)
+}
+
+func must[T any](value T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func (s *ExchangeUnitTestSuite) TestUnit_RESTExchange() {
+	_, err := NewRESTExchange(nil)
+	require.Error(s.T(), err)
+
+	exchange, err := NewRESTExchange(s.APIClient)
+	require.NoError(s.T(), err)
+	s.exercise(exchange)
+
+	order, err := exchange.NewOrder(context.Background(), s.limitOrder(true, 900))
+	require.NoError(s.T(), err)
+	replacement, err := exchange.CancelOrderAndReplace(context.Background(), &types.CancelOrderAndReplaceRequest{IdToCancel: order.Id, NewOrder: s.limitOrder(true, 901)})
+	require.NoError(s.T(), err)
+	require.NotEqual(s.T(), order.Id, replacement.Id)
+
+	// Done contexts are not sent.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = exchange.SpotBalances(ctx)
+	require.ErrorIs(s.T(), err, context.Canceled)
+
+	// Unreachable servers are transport errors.
+	s.Server.Close()
+	_, err = exchange.SpotBalances(context.Background())
+	var transportError *TransportError
+	require.ErrorAs(s.T(), err, &transportError)
+	require.False(s.T(), transportError.Sent)
+}
+
+func (s *ExchangeUnitTestSuite) TestUnit_WSExchange() {
+	_, err := NewWSExchange(&WSExchangeConfiguration{})
+	require.Error(s.T(), err)
+
+	messages := make(chan []byte, 100)
+	exchange, err := NewWSExchange(&WSExchangeConfiguration{
+		Client:    s.WSClient,
+		OnMessage: func(body []byte) { messages <- body },
+	})
+	require.NoError(s.T(), err)
+	s.exercise(exchange)
+
+	// Cancel and replace is not carried by the websocket.
+	_, err = exchange.CancelOrderAndReplace(context.Background(), &types.CancelOrderAndReplaceRequest{IdToCancel: "unknown", NewOrder: s.limitOrder(true, 900)})
+	require.ErrorIs(s.T(), err, ErrUnsupported)
+	var transportError *TransportError
+	require.ErrorAs(s.T(), err, &transportError)
+	require.False(s.T(), transportError.Sent)
+
+	// Concurrent requests are matched to their responses.
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		go func() {
+			_, err := exchange.SpotBalances(context.Background())
+			errs <- err
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		require.NoError(s.T(), <-errs)
+	}
+
+	// Responses to requests sent outside the exchange are passed to OnMessage.
+	require.NoError(s.T(), s.WSClient.ServerTime("time"))
+	select {
+	case body := <-messages:
+		require.Contains(s.T(), string(body), `"id":"time"`)
+	case <-time.After(5 * time.Second):
+		s.T().Fatal("no message")
+	}
+
+	// Requests fail once the connection is closed, without being sent.
+	s.Server.Disconnect()
+	require.Eventually(s.T(), func() bool {
+		_, err := exchange.SpotBalances(context.Background())
+		var transportError *TransportError
+		return errors.As(err, &transportError) && !transportError.Sent
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func (s *ExchangeUnitTestSuite) TestUnit_Metrics() {
+	registry := prometheus.NewRegistry()
+	clientMetrics, err := metrics.NewMetrics(&metrics.MetricsConfiguration{Registerer: registry})
+	require.NoError(s.T(), err)
+	apiClient, err := api_client.NewRyskV2APIClient(&api_client.RyskV2APIClientConfiguration{
+		Env:          constants.ENVIRONMENT_TESTNET,
+		PrivateKey:   s.PrivateKey,
+		RpcUrl:       s.Server.URL(),
+		BaseUrl:      s.Server.URL(),
+		SubAccountId: 1,
+		Metrics:      clientMetrics,
+	})
+	require.NoError(s.T(), err)
+	wsClient, err := ws_client.NewRyskV2WSClient(&ws_client.RyskV2WSClientConfiguration{
+		Env:          constants.ENVIRONMENT_TESTNET,
+		PrivateKey:   s.PrivateKey,
+		RpcUrl:       s.Server.URL(),
+		BaseUrl:      s.Server.URL(),
+		WSRpcUrl:     s.Server.RPCURL(),
+		WSStreamUrl:  s.Server.StreamURL(),
+		SubAccountId: 1,
+		Metrics:      clientMetrics,
+	})
+	require.NoError(s.T(), err)
+	wsExchange := must(NewWSExchange(&WSExchangeConfiguration{Client: wsClient}))
+	restExchange := must(NewRESTExchange(apiClient))
+
+	// Orders reusing a nonce are rejected over both transports.
+	ctx := context.Background()
+	order := s.limitOrder(true, 1000)
+	_, err = wsExchange.NewOrder(ctx, order)
+	require.NoError(s.T(), err)
+	_, err = wsExchange.NewOrder(ctx, order)
+	require.Error(s.T(), err)
+	_, err = restExchange.NewOrder(ctx, order)
+	require.Error(s.T(), err)
+	_, err = restExchange.ListOpenOrders(ctx, &constants.PRODUCT_ETH_PERP)
+	require.NoError(s.T(), err)
+
+	require.NoError(s.T(), testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP rysk_order_rejects_total Orders rejected by the exchange, by reason.
+# TYPE rysk_order_rejects_total counter
+rysk_order_rejects_total{reason="invalid_request"} 2
+`), "rysk_order_rejects_total"))
+	require.NoError(s.T(), testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP rysk_rest_requests_total REST requests sent, by HTTP method, endpoint and status code, `+"`error`"+` when no response was received.
+# TYPE rysk_rest_requests_total counter
+rysk_rest_requests_total{endpoint="/openOrders",method="GET",status="200"} 1
+rysk_rest_requests_total{endpoint="/order",method="POST",status="400"} 1
+rysk_rest_requests_total{endpoint="/referral/add-referee",method="POST",status="200"} 1
+`), "rysk_rest_requests_total"))
+
+	// Login, orders and signatures are timed.
+	count, err := testutil.GatherAndCount(registry, "rysk_rpc_duration_seconds", "rysk_signing_duration_seconds")
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 6, count)
+}
+
+func (s *ExchangeUnitTestSuite) TestUnit_Tracing() {
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := tracing.NewTracer(&tracing.TracingConfiguration{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)),
+	})
+	apiClient, err := api_client.NewRyskV2APIClient(&api_client.RyskV2APIClientConfiguration{
+		Env:          constants.ENVIRONMENT_TESTNET,
+		PrivateKey:   s.PrivateKey,
+		RpcUrl:       s.Server.URL(),
+		BaseUrl:      s.Server.URL(),
+		SubAccountId: 1,
+		Tracer:       tracer,
+	})
+	require.NoError(s.T(), err)
+	wsClient, err := ws_client.NewRyskV2WSClient(&ws_client.RyskV2WSClientConfiguration{
+		Env:          constants.ENVIRONMENT_TESTNET,
+		PrivateKey:   s.PrivateKey,
+		RpcUrl:       s.Server.URL(),
+		BaseUrl:      s.Server.URL(),
+		WSRpcUrl:     s.Server.RPCURL(),
+		WSStreamUrl:  s.Server.StreamURL(),
+		SubAccountId: 1,
+		Tracer:       tracer,
+	})
+	require.NoError(s.T(), err)
+	wsExchange := must(NewWSExchange(&WSExchangeConfiguration{Client: wsClient}))
+	restExchange := must(NewRESTExchange(apiClient))
+	ctx := context.Background()
+	order := s.limitOrder(true, 1000)
+
+	// spans returns the spans named after a client method, and their children by name.
+	spans := func(name string) []map[string]tracetest.SpanStub {
+		var result []map[string]tracetest.SpanStub
+		for _, parent := range exporter.GetSpans() {
+			if parent.Name != name {
+				continue
+			}
+			children := map[string]tracetest.SpanStub{name: parent}
+			for _, child := range exporter.GetSpans() {
+				if child.Parent.SpanID() == parent.SpanContext.SpanID() {
+					require.Equal(s.T(), parent.SpanContext.TraceID(), child.SpanContext.TraceID())
+					children[child.Name] = child
+				}
+			}
+			result = append(result, children)
+		}
+		return result
+	}
+
+	// Websocket orders are traced from signing to their response.
+	_, err = wsExchange.NewOrder(ctx, order)
+	require.NoError(s.T(), err)
+	_, err = wsExchange.NewOrder(ctx, order)
+	require.Error(s.T(), err)
+	orders := spans("RyskV2WSClient.NewOrder")
+	require.Len(s.T(), orders, 2)
+	for _, order := range orders {
+		require.Len(s.T(), order, 4)
+		require.Contains(s.T(), order, tracing.SPAN_SIGN)
+		require.Contains(s.T(), order, tracing.SPAN_WEBSOCKET_WRITE)
+		require.Contains(s.T(), order, tracing.SPAN_RPC_RESPONSE)
+		require.Contains(s.T(), order["RyskV2WSClient.NewOrder"].Attributes, tracing.Product(&constants.PRODUCT_ETH_PERP))
+		require.Contains(s.T(), order["RyskV2WSClient.NewOrder"].Attributes, tracing.OrderType(constants.ORDER_TYPE_LIMIT))
+		require.Contains(s.T(), order[tracing.SPAN_SIGN].Attributes, tracing.PrimaryType(constants.PRIMARY_TYPE_ORDER))
+	}
+	require.Equal(s.T(), codes.Unset, orders[0][tracing.SPAN_RPC_RESPONSE].Status.Code)
+	require.Equal(s.T(), codes.Error, orders[1][tracing.SPAN_RPC_RESPONSE].Status.Code)
+	require.Contains(s.T(), orders[1][tracing.SPAN_RPC_RESPONSE].Attributes, tracing.ATTRIBUTE_ERROR_CODE.Int(http.StatusBadRequest))
+	require.Len(s.T(), spans("RyskV2WSClient.Login"), 1)
+
+	// REST orders are traced from signing to the HTTP response.
+	_, err = restExchange.NewOrder(ctx, order)
+	require.Error(s.T(), err)
+	orders = spans("RyskV2APIClient.NewOrder")
+	require.Len(s.T(), orders, 1)
+	require.Len(s.T(), orders[0], 3)
+	require.Contains(s.T(), orders[0], tracing.SPAN_SIGN)
+	request := orders[0]["POST /order"]
+	require.Equal(s.T(), trace.SpanKindClient, request.SpanKind)
+	require.Equal(s.T(), codes.Error, request.Status.Code)
+	require.Contains(s.T(), request.Attributes, tracing.ATTRIBUTE_HTTP_STATUS.Int(400))
+	require.Contains(s.T(), orders[0]["RyskV2APIClient.NewOrder"].Attributes, tracing.SubAccountId(1))
+}
+
+// logOutput is a log destination safe for concurrent writes and reads.
+type logOutput struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (output *logOutput) Write(p []byte) (int, error) {
+	output.mutex.Lock()
+	defer output.mutex.Unlock()
+	return output.buffer.Write(p)
+}
+
+func (output *logOutput) String() string {
+	output.mutex.Lock()
+	defer output.mutex.Unlock()
+	return output.buffer.String()
+}
+
+func (s *ExchangeUnitTestSuite) TestUnit_Logging() {
+	output := &logOutput{}
+	logger := slog.New(slog.NewJSONHandler(output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	apiClient, err := api_client.NewRyskV2APIClient(&api_client.RyskV2APIClientConfiguration{
+		Env:          constants.ENVIRONMENT_TESTNET,
+		PrivateKey:   s.PrivateKey,
+		RpcUrl:       s.Server.URL(),
+		BaseUrl:      s.Server.URL(),
+		SubAccountId: 1,
+		Logger:       logger,
+	})
+	require.NoError(s.T(), err)
+	wsClient, err := ws_client.NewRyskV2WSClient(&ws_client.RyskV2WSClientConfiguration{
+		Env:          constants.ENVIRONMENT_TESTNET,
+		PrivateKey:   s.PrivateKey,
+		RpcUrl:       s.Server.URL(),
+		BaseUrl:      s.Server.URL(),
+		WSRpcUrl:     s.Server.RPCURL(),
+		WSStreamUrl:  s.Server.StreamURL(),
+		SubAccountId: 1,
+		Logger:       logger,
+	})
+	require.NoError(s.T(), err)
+	wsExchange := must(NewWSExchange(&WSExchangeConfiguration{Client: wsClient}))
+	restExchange := must(NewRESTExchange(apiClient))
+
+	// Connections, subscriptions, requests and responses are logged.
+	ctx := context.Background()
+	_, err = wsExchange.NewOrder(ctx, s.limitOrder(true, 1000))
+	require.NoError(s.T(), err)
+	_, err = restExchange.NewOrder(ctx, s.limitOrder(true, 1000))
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), wsClient.SubscribeAggregateTrades("trades", []*types.Product{&constants.PRODUCT_ETH_PERP}))
+	logs := output.String()
+	for _, message := range []string{"websocket connected", "websocket subscription", "websocket request", "websocket message", "rest request", "rest response"} {
+		require.Contains(s.T(), logs, `"msg":"`+message+`"`)
+	}
+
+	// Signatures and keys are redacted.
+	require.Contains(s.T(), logs, `\"signature\":\"[REDACTED]\"`)
+	require.NotContains(s.T(), logs, s.PrivateKey)
+	require.NotRegexp(s.T(), `0x[0-9a-fA-F]{130}`, logs)
+
+	// On-chain transactions are logged when sent and confirmed.
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(7), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
+	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
+	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
+	mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+	mockEthClient.On("TransactionReceipt", mock.Anything, mock.Anything).Return(&geth_types.Receipt{Status: geth_types.ReceiptStatusSuccessful}, nil)
+	apiClient.EthClient = mockEthClient
+	transaction, err := apiClient.ApproveUSDC(ctx, big.NewInt(1))
+	require.NoError(s.T(), err)
+	_, err = apiClient.WaitTransaction(ctx, transaction)
+	require.NoError(s.T(), err)
+	logs = output.String()
+	require.Contains(s.T(), logs, `"msg":"transaction sent","hash":"`+transaction.Hash().Hex()+`"`)
+	require.Contains(s.T(), logs, `"msg":"transaction confirmed","hash":"`+transaction.Hash().Hex()+`"`)
+
+	// Retries are logged before the configured callback.
+	failures := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet && failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	retries := 0
+	apiClient, err = api_client.NewRyskV2APIClient(&api_client.RyskV2APIClientConfiguration{
+		Env:          constants.ENVIRONMENT_TESTNET,
+		PrivateKey:   s.PrivateKey,
+		RpcUrl:       s.Server.URL(),
+		BaseUrl:      server.URL,
+		SubAccountId: 1,
+		Retry: &utils.RetryConfiguration{
+			InitialBackoff: time.Millisecond,
+			OnRetry:        func(request *http.Request, attempt int, err error, delay time.Duration) { retries++ },
+		},
+		Logger: logger,
+	})
+	require.NoError(s.T(), err)
+	res, err := apiClient.ServerTime()
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), http.StatusOK, res.StatusCode)
+	require.Equal(s.T(), 1, retries)
+	require.Contains(s.T(), output.String(), `"msg":"retrying rest request"`)
+}
+
+func (s *ExchangeUnitTestSuite) TestUnit_Audit() {
+	path := filepath.Join(s.T().TempDir(), "audit.jsonl")
+	auditLog, err := audit.NewLog(&audit.LogConfiguration{Path: path})
+	require.NoError(s.T(), err)
+	defer auditLog.Close()
+	apiClient, err := api_client.NewRyskV2APIClient(&api_client.RyskV2APIClientConfiguration{
+		Env:          constants.ENVIRONMENT_TESTNET,
+		PrivateKey:   s.PrivateKey,
+		RpcUrl:       s.Server.URL(),
+		BaseUrl:      s.Server.URL(),
+		SubAccountId: 1,
+		AuditLog:     auditLog,
+	})
+	require.NoError(s.T(), err)
+	wsClient, err := ws_client.NewRyskV2WSClient(&ws_client.RyskV2WSClientConfiguration{
+		Env:          constants.ENVIRONMENT_TESTNET,
+		PrivateKey:   s.PrivateKey,
+		RpcUrl:       s.Server.URL(),
+		BaseUrl:      s.Server.URL(),
+		WSRpcUrl:     s.Server.RPCURL(),
+		WSStreamUrl:  s.Server.StreamURL(),
+		SubAccountId: 1,
+		AuditLog:     auditLog,
+	})
+	require.NoError(s.T(), err)
+	wsExchange := must(NewWSExchange(&WSExchangeConfiguration{Client: wsClient}))
+	restExchange := must(NewRESTExchange(apiClient))
+	ctx := context.Background()
+
+	// Signed actions of both clients are recorded with their responses, reads are not.
+	for _, exchange := range []IExchange{wsExchange, restExchange} {
+		order, err := exchange.NewOrder(ctx, s.limitOrder(true, 1000))
+		require.NoError(s.T(), err)
+		_, err = exchange.CancelOrder(ctx, &types.CancelOrderRequest{Product: &constants.PRODUCT_ETH_PERP, IdToCancel: order.Id})
+		require.NoError(s.T(), err)
+		_, err = exchange.Withdraw(ctx, &types.WithdrawRequest{Quantity: constants.E18.String(), Nonce: s.nextNonce()})
+		var insufficientBalanceError *utils.InsufficientBalanceError
+		require.ErrorAs(s.T(), err, &insufficientBalanceError)
+		_, err = exchange.ListOpenOrders(ctx, &constants.PRODUCT_ETH_PERP)
+		require.NoError(s.T(), err)
+	}
+	summary, err := audit.VerifyFile(path)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), uint64(6), summary.Actions)
+	require.Equal(s.T(), uint64(6), summary.Responses)
+
+	// Actions hold the typed data, digest and signature of the account, and responses the server reply.
+	body, err := os.ReadFile(path)
+	require.NoError(s.T(), err)
+	var transports, primaryTypes, responses []string
+	for _, line := range strings.Split(strings.TrimSpace(string(body)), "\n") {
+		var entry struct {
+			Record audit.Record `json:"record"`
+		}
+		require.NoError(s.T(), json.Unmarshal([]byte(line), &entry))
+		record := entry.Record
+		if record.Type == audit.RECORD_TYPE_RESPONSE {
+			responses = append(responses, string(record.Response))
+			continue
+		}
+		transports = append(transports, record.Transport)
+		primaryTypes = append(primaryTypes, record.TypedData.PrimaryType)
+		signature := common.FromHex(record.Signature)
+		signature[crypto.RecoveryIDOffset] -= 27
+		publicKey, err := crypto.SigToPub(common.FromHex(record.Digest), signature)
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), apiClient.Address(), crypto.PubkeyToAddress(*publicKey))
+		require.Contains(s.T(), string(record.Request), record.Signature)
+	}
+	require.Equal(s.T(), []string{"websocket", "websocket", "websocket", "rest", "rest", "rest"}, transports)
+	require.Equal(s.T(), []string{"Order", "CancelOrder", "Withdraw", "Order", "CancelOrder", "Withdraw"}, primaryTypes)
+	require.Contains(s.T(), responses[2], "error")
+
+	// Actions are not sent once the log cannot be written.
+	require.NoError(s.T(), auditLog.Close())
+	_, err = restExchange.NewOrder(ctx, s.limitOrder(true, 1000))
+	require.Error(s.T(), err)
+	_, err = wsExchange.NewOrder(ctx, s.limitOrder(true, 1000))
+	require.Error(s.T(), err)
+	orders, err := restExchange.ListOpenOrders(ctx, &constants.PRODUCT_ETH_PERP)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), orders)
+}
+
+func (s *ExchangeUnitTestSuite) TestUnit_TypedData() {
+	// The account signs externally, from the JSON typed data, while the clients hold another key.
+	walletKey, err := crypto.GenerateKey()
+	require.NoError(s.T(), err)
+	account := crypto.PubkeyToAddress(walletKey.PublicKey)
+	builder, err := typed_data.NewBuilder(&typed_data.BuilderConfiguration{Env: constants.ENVIRONMENT_TESTNET, Account: account.Hex(), SubAccountId: 1})
+	require.NoError(s.T(), err)
+	sign := func(typedData *apitypes.TypedData, err error) (*apitypes.TypedData, string) {
+		require.NoError(s.T(), err)
+		imported := must(typed_data.Unmarshal(must(typed_data.Marshal(typedData))))
+		digest, _, err := apitypes.TypedDataAndHash(*imported)
+		require.NoError(s.T(), err)
+		signature := must(crypto.Sign(digest, walletKey))
+		signature[crypto.RecoveryIDOffset] += 27
+		return imported, hexutil.Encode(signature)
+	}
+	ctx := context.Background()
+	s.Server.Credit(account, 1, s.APIClient.USDCAddress(), new(big.Int).Mul(big.NewInt(100), constants.E18))
+
+	// Actions are submitted over REST for the account of the typed data.
+	order, err := send[*types.Order](ctx, func() (*http.Response, error) {
+		return s.APIClient.SubmitTypedData(sign(builder.NewOrder(s.limitOrder(true, 1000))))
+	})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), strings.ToLower(account.Hex()), strings.ToLower(order.Account))
+	_, err = send[*types.Order](ctx, func() (*http.Response, error) {
+		return s.APIClient.SubmitTypedData(sign(builder.CancelOrder(&types.CancelOrderRequest{Product: &constants.PRODUCT_ETH_PERP, IdToCancel: order.Id})))
+	})
+	require.NoError(s.T(), err)
+	_, err = send[[]types.Order](ctx, func() (*http.Response, error) {
+		return s.APIClient.SubmitTypedData(sign(builder.CancelAllOpenOrders(&constants.PRODUCT_ETH_PERP)))
+	})
+	require.NoError(s.T(), err)
+	_, err = send[json.RawMessage](ctx, func() (*http.Response, error) {
+		return s.APIClient.SubmitTypedData(sign(builder.ApproveSigner(&types.ApproveRevokeSignerRequest{ApprovedSigner: s.APIClient.Address().Hex(), Nonce: s.nextNonce()})))
+	})
+	require.NoError(s.T(), err)
+	_, err = send[json.RawMessage](ctx, func() (*http.Response, error) {
+		return s.APIClient.SubmitTypedData(sign(builder.Withdraw(&types.WithdrawRequest{Quantity: constants.E18.String(), Nonce: s.nextNonce()})))
+	})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), new(big.Int).Mul(big.NewInt(99), constants.E18), s.Server.Balance(account, 1, s.APIClient.USDCAddress()))
+	_, err = send[json.RawMessage](ctx, func() (*http.Response, error) {
+		return s.APIClient.SubmitTypedData(sign(builder.Referral("CODE")))
+	})
+	require.NoError(s.T(), err)
+	_, err = s.APIClient.SubmitTypedData(sign(builder.Login(uint64(time.Now().UnixMilli()))))
+	require.ErrorContains(s.T(), err, "cannot be submitted over REST")
+
+	// Withdrawals signed by the approved signer are rejected by the exchange, invalid signatures before sending.
+	typedData, _ := sign(builder.Withdraw(&types.WithdrawRequest{Quantity: constants.E18.String(), Nonce: s.nextNonce()}))
+	signature := must(utils.SignMessage(typed_data.Domain(constants.ENVIRONMENT_TESTNET), s.PrivateKey, constants.PRIMARY_TYPE_WITHDRAW, typedData.Message))
+	_, err = send[json.RawMessage](ctx, func() (*http.Response, error) {
+		return s.APIClient.SubmitTypedData(typedData, signature)
+	})
+	var apiError *utils.APIError
+	require.ErrorAs(s.T(), err, &apiError)
+	var signatureError *utils.SignatureError
+	_, err = s.APIClient.SubmitTypedData(typedData, "0x")
+	require.ErrorAs(s.T(), err, &signatureError)
+
+	// The websocket session logs in as the account, then submits its actions.
+	submit := func(id string, typedData *apitypes.TypedData) string {
+		typedData, signature := sign(typedData, nil)
+		require.NoError(s.T(), s.WSClient.SubmitTypedData(id, typedData, signature))
+		for {
+			_, body, err := s.WSClient.RPCReader().ReadMessage()
+			require.NoError(s.T(), err)
+			if strings.Contains(string(body), `"id":"`+id+`"`) {
+				require.NotContains(s.T(), string(body), `"error"`)
+				return string(body)
+			}
+		}
+	}
+	require.Contains(s.T(), submit("login", must(builder.Login(uint64(time.Now().Add(10*time.Second).UnixMilli())))), `"authenticated":true`)
+	var orderResponse struct {
+		Result types.Order `json:"result"`
+	}
+	require.NoError(s.T(), json.Unmarshal([]byte(submit("order", must(builder.NewOrder(s.limitOrder(true, 1000))))), &orderResponse))
+	submit("cancel", must(builder.CancelOrder(&types.CancelOrderRequest{Product: &constants.PRODUCT_ETH_PERP, IdToCancel: orderResponse.Result.Id})))
+	submit("withdraw", must(builder.Withdraw(&types.WithdrawRequest{Quantity: constants.E18.String(), Nonce: s.nextNonce()})))
+	require.Equal(s.T(), new(big.Int).Mul(big.NewInt(98), constants.E18), s.Server.Balance(account, 1, s.APIClient.USDCAddress()))
+	typedData, signature = sign(builder.Referral("CODE"))
+	require.ErrorContains(s.T(), s.WSClient.SubmitTypedData("referral", typedData, signature), "cannot be submitted over websocket")
+}
+
+func (s *ExchangeUnitTestSuite) TestUnit_ContextCancelled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The clients abort signing and sending.
+	_, err := s.APIClient.NewOrderCtx(ctx, s.limitOrder(true, 1000))
+	require.ErrorIs(s.T(), err, context.Canceled)
+	_, err = s.APIClient.ListOpenOrdersCtx(ctx, &constants.PRODUCT_ETH_PERP)
+	require.ErrorIs(s.T(), err, context.Canceled)
+	require.ErrorIs(s.T(), s.WSClient.NewOrderCtx(ctx, "1", s.limitOrder(true, 1000)), context.Canceled)
+	require.ErrorIs(s.T(), s.WSClient.ListProductsCtx(ctx, "2"), context.Canceled)
+
+	// So do the exchanges, without failing over.
+	wsExchange := must(NewWSExchange(&WSExchangeConfiguration{Client: s.WSClient}))
+	failovers := 0
+	failoverExchange := must(NewFailoverExchange(&FailoverExchangeConfiguration{
+		Primary:    wsExchange,
+		Fallback:   must(NewRESTExchange(s.APIClient)),
+		OnFailover: func(operation string, err error) { failovers++ },
+	}))
+	_, err = failoverExchange.NewOrder(ctx, s.limitOrder(true, 1000))
+	require.ErrorIs(s.T(), err, context.Canceled)
+	_, err = failoverExchange.SpotBalances(ctx)
+	require.ErrorIs(s.T(), err, context.Canceled)
+	require.Zero(s.T(), failovers)
+
+	// Nothing reached the exchange.
+	orders, err := must(NewRESTExchange(s.APIClient)).ListOpenOrders(context.Background(), &constants.PRODUCT_ETH_PERP)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), orders)
+}
+
+func (s *ExchangeUnitTestSuite) TestUnit_WSExchange_Timeout() {
+	exchange, err := NewWSExchange(&WSExchangeConfiguration{Client: s.WSClient, Timeout: time.Nanosecond})
+	require.NoError(s.T(), err)
+
+	// Requests without a response in time may have been sent.
+	_, err = exchange.SpotBalances(context.Background())
+	var transportError *TransportError
+	require.ErrorAs(s.T(), err, &transportError)
+	require.True(s.T(), transportError.Sent)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = exchange.SpotBalances(ctx)
+	require.ErrorIs(s.T(), err, context.Canceled)
+}
+
+// stubExchange fails every order and listing with an error.
+type stubExchange struct {
+	IExchange
+	err   error
+	calls int
+}
+
+func (exchange *stubExchange) NewOrder(ctx context.Context, params *types.NewOrderRequest) (*types.Order, error) {
+	exchange.calls++
+	return nil, exchange.err
+}
+
+func (exchange *stubExchange) ListOpenOrders(ctx context.Context, product *types.Product) ([]types.Order, error) {
+	exchange.calls++
+	return nil, exchange.err
+}
+
+func (s *ExchangeUnitTestSuite) TestUnit_FailoverExchange() {
+	_, err := NewFailoverExchange(&FailoverExchangeConfiguration{})
+	require.Error(s.T(), err)
+
+	wsExchange, err := NewWSExchange(&WSExchangeConfiguration{Client: s.WSClient})
+	require.NoError(s.T(), err)
+	restExchange, err := NewRESTExchange(s.APIClient)
+	require.NoError(s.T(), err)
+	var failovers []string
+	exchange, err := NewFailoverExchange(&FailoverExchangeConfiguration{
+		Primary:    wsExchange,
+		Fallback:   restExchange,
+		OnFailover: func(operation string, err error) { failovers = append(failovers, operation) },
+	})
+	require.NoError(s.T(), err)
+	s.exercise(exchange)
+	require.Empty(s.T(), failovers)
+
+	// Cancel and replace is sent over REST.
+	order, err := exchange.NewOrder(context.Background(), s.limitOrder(true, 900))
+	require.NoError(s.T(), err)
+	replacement, err := exchange.CancelOrderAndReplace(context.Background(), &types.CancelOrderAndReplaceRequest{IdToCancel: order.Id, NewOrder: s.limitOrder(true, 901)})
+	require.NoError(s.T(), err)
+	_, err = exchange.CancelOrder(context.Background(), &types.CancelOrderRequest{Product: &constants.PRODUCT_ETH_PERP, IdToCancel: replacement.Id})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []string{"CancelOrderAndReplace"}, failovers)
+	failovers = nil
+
+	// Requests fall back to REST once the websocket is down.
+	s.Server.Disconnect()
+	require.Eventually(s.T(), func() bool {
+		_, err := wsExchange.SpotBalances(context.Background())
+		return err != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	_, err = exchange.NewOrder(context.Background(), s.limitOrder(true, 900))
+	require.NoError(s.T(), err)
+	orders, err := exchange.ListOpenOrders(context.Background(), &constants.PRODUCT_ETH_PERP)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), orders, 1)
+	require.Equal(s.T(), []string{"NewOrder", "ListOpenOrders"}, failovers)
+}
+
+func (s *ExchangeUnitTestSuite) TestUnit_FailoverExchange_Sent() {
+	primary := &stubExchange{err: &TransportError{Sent: true, Err: errors.New("no response")}}
+	fallback := &stubExchange{}
+	exchange, err := NewFailoverExchange(&FailoverExchangeConfiguration{Primary: primary, Fallback: fallback})
+	require.NoError(s.T(), err)
+
+	// Sent orders may have been placed, they do not fall back.
+	_, err = exchange.NewOrder(context.Background(), s.limitOrder(true, 900))
+	require.ErrorIs(s.T(), err, primary.err)
+	require.Equal(s.T(), 0, fallback.calls)
+
+	// Reads fall back.
+	_, err = exchange.ListOpenOrders(context.Background(), &constants.PRODUCT_ETH_PERP)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 1, fallback.calls)
+
+	// Rejections do not fall back.
+	primary.err = errors.New("rejected")
+	_, err = exchange.ListOpenOrders(context.Background(), &constants.PRODUCT_ETH_PERP)
+	require.ErrorIs(s.T(), err, primary.err)
+	require.Equal(s.T(), 1, fallback.calls)
+}
@@ -0,0 +1,207 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/rysk-finance/v2_client_go/api_client"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+)
+
+// RESTExchange implements IExchange over the REST API.
+type RESTExchange struct {
+	apiClient *api_client.RyskV2APIClient
+}
+
+// NewRESTExchange creates a new RESTExchange instance.
+//
+// Parameters:
+//   - apiClient: The REST client acting on the sub-account.
+//
+// Returns:
+//   - A pointer to RESTExchange.
+//   - An error if no REST client is provided.
+func NewRESTExchange(apiClient *api_client.RyskV2APIClient) (*RESTExchange, error) {
+	if apiClient == nil {
+		return nil, fmt.Errorf("REST exchange requires an API client")
+	}
+	return &RESTExchange{apiClient: apiClient}, nil
+}
+
+// NewOrder creates a new order.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - params: The order parameters.
+//
+// Returns:
+//   - A pointer to the created types.Order.
+//   - An error if the request fails or if the response cannot be decoded.
+func (exchange *RESTExchange) NewOrder(ctx context.Context, params *types.NewOrderRequest) (*types.Order, error) {
+	return send[*types.Order](ctx, func() (*http.Response, error) {
+		return exchange.apiClient.NewOrderCtx(ctx, params)
+	})
+}
+
+// CancelOrderAndReplace cancels an order and creates a new one in its place.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - params: The cancellation and replacement parameters.
+//
+// Returns:
+//   - A pointer to the replacement types.Order.
+//   - An error if the request fails or if the response cannot be decoded.
+func (exchange *RESTExchange) CancelOrderAndReplace(ctx context.Context, params *types.CancelOrderAndReplaceRequest) (*types.Order, error) {
+	return send[*types.Order](ctx, func() (*http.Response, error) {
+		return exchange.apiClient.CancelOrderAndReplaceCtx(ctx, params)
+	})
+}
+
+// CancelOrder cancels an order.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - params: The cancellation parameters.
+//
+// Returns:
+//   - A pointer to the cancelled types.Order.
+//   - An error if the request fails or if the response cannot be decoded.
+func (exchange *RESTExchange) CancelOrder(ctx context.Context, params *types.CancelOrderRequest) (*types.Order, error) {
+	return send[*types.Order](ctx, func() (*http.Response, error) {
+		return exchange.apiClient.CancelOrderCtx(ctx, params)
+	})
+}
+
+// CancelAllOpenOrders cancels all open orders for a product.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - product: The product whose orders are cancelled.
+//
+// Returns:
+//   - A slice of the cancelled types.Order.
+//   - An error if the request fails or if the response cannot be decoded.
+func (exchange *RESTExchange) CancelAllOpenOrders(ctx context.Context, product *types.Product) ([]types.Order, error) {
+	return send[[]types.Order](ctx, func() (*http.Response, error) {
+		return exchange.apiClient.CancelAllOpenOrdersCtx(ctx, product)
+	})
+}
+
+// ListOpenOrders returns the open orders for a product.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - product: The product whose orders are listed.
+//
+// Returns:
+//   - A slice of types.Order.
+//   - An error if the request fails or if the response cannot be decoded.
+func (exchange *RESTExchange) ListOpenOrders(ctx context.Context, product *types.Product) ([]types.Order, error) {
+	return send[[]types.Order](ctx, func() (*http.Response, error) {
+		return exchange.apiClient.ListOpenOrdersCtx(ctx, product)
+	})
+}
+
+// ApproveSigner approves a signer for the sub-account.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - params: The signer and nonce.
+//
+// Returns:
+//   - A pointer to the types.ApprovedSigner.
+//   - An error if the request fails or if the response cannot be decoded.
+func (exchange *RESTExchange) ApproveSigner(ctx context.Context, params *types.ApproveRevokeSignerRequest) (*types.ApprovedSigner, error) {
+	return send[*types.ApprovedSigner](ctx, func() (*http.Response, error) {
+		return exchange.apiClient.ApproveSignerCtx(ctx, params)
+	})
+}
+
+// RevokeSigner revokes a signer of the sub-account.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - params: The signer and nonce.
+//
+// Returns:
+//   - A pointer to the types.ApprovedSigner.
+//   - An error if the request fails or if the response cannot be decoded.
+func (exchange *RESTExchange) RevokeSigner(ctx context.Context, params *types.ApproveRevokeSignerRequest) (*types.ApprovedSigner, error) {
+	return send[*types.ApprovedSigner](ctx, func() (*http.Response, error) {
+		return exchange.apiClient.RevokeSignerCtx(ctx, params)
+	})
+}
+
+// Withdraw initiates a withdrawal of USDC.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - params: The withdrawal quantity and nonce.
+//
+// Returns:
+//   - A pointer to the types.SpotBalance after the withdrawal.
+//   - An error if the request fails or if the response cannot be decoded.
+func (exchange *RESTExchange) Withdraw(ctx context.Context, params *types.WithdrawRequest) (*types.SpotBalance, error) {
+	return send[*types.SpotBalance](ctx, func() (*http.Response, error) {
+		return exchange.apiClient.WithdrawCtx(ctx, params)
+	})
+}
+
+// PerpetualPositions returns the perpetual positions across all products.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//
+// Returns:
+//   - A slice of types.PerpetualPosition.
+//   - An error if the request fails or if the response cannot be decoded.
+func (exchange *RESTExchange) PerpetualPositions(ctx context.Context) ([]types.PerpetualPosition, error) {
+	return send[[]types.PerpetualPosition](ctx, func() (*http.Response, error) {
+		return exchange.apiClient.GetPerpetualPositionAllProductsCtx(ctx)
+	})
+}
+
+// SpotBalances returns the spot balances.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//
+// Returns:
+//   - A slice of types.SpotBalance.
+//   - An error if the request fails or if the response cannot be decoded.
+func (exchange *RESTExchange) SpotBalances(ctx context.Context) ([]types.SpotBalance, error) {
+	return send[[]types.SpotBalance](ctx, func() (*http.Response, error) {
+		return exchange.apiClient.GetSpotBalancesCtx(ctx)
+	})
+}
+
+// send sends a REST request unless the context is done, and decodes its JSON response.
+// Failures to reach the server are reported as TransportError.
+func send[T any](ctx context.Context, request func() (*http.Response, error)) (T, error) {
+	var result T
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+	res, err := request()
+	if err != nil {
+		// Requests aborted by the context are not transport errors.
+		var urlError *url.Error
+		if errors.As(err, &urlError) && ctx.Err() == nil {
+			// Requests failing to dial were not sent.
+			var opError *net.OpError
+			return result, &TransportError{Sent: !errors.As(err, &opError) || opError.Op != "dial", Err: err}
+		}
+		return result, err
+	}
+	if err := utils.DecodeHTTPResponse(res, &result); err != nil {
+		var zero T
+		return zero, err
+	}
+	return result, nil
+}
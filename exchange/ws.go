@@ -0,0 +1,389 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+	"github.com/rysk-finance/v2_client_go/ws_client"
+)
+
+const (
+	DEFAULT_TIMEOUT   = 10 * time.Second // DEFAULT_TIMEOUT is how long a request waits for its response by default, as the REST client.
+	MESSAGE_ID_PREFIX = "exchange-"      // MESSAGE_ID_PREFIX prefixes the IDs of requests sent by WSExchange.
+)
+
+// WSExchangeConfiguration holds the configuration for a websocket exchange.
+type WSExchangeConfiguration struct {
+	Client    *ws_client.RyskV2WSClient // WebSocket client acting on the sub-account. Its RPC connection is read by the exchange only.
+	Timeout   time.Duration             // How long a request waits for its response. Defaults to `DEFAULT_TIMEOUT`.
+	OnMessage func(body []byte)         // Optional callback invoked with RPC messages answering no pending request, e.g. `account.updates` notifications.
+}
+
+// WSExchange implements IExchange over the JSON-RPC websocket. It reads the RPC connection in the background,
+// matching responses to requests by message ID, and logs in before the first private request.
+type WSExchange struct {
+	client     *ws_client.RyskV2WSClient
+	timeout    time.Duration
+	onMessage  func(body []byte)
+	writeMutex sync.Mutex // writeMutex serialises writes to the RPC connection.
+	loginMutex sync.Mutex // loginMutex serialises logins.
+	loggedIn   bool       // loggedIn is set once the session is logged in, guarded by loginMutex.
+
+	mutex    sync.Mutex                               // mutex guards the fields below.
+	pending  map[string]chan *types.WebsocketResponse // pending holds the response channels by message ID.
+	sequence int64                                    // sequence numbers message IDs.
+	err      error                                    // err is the read error which closed the connection, nil while open.
+}
+
+// NewWSExchange creates a new WSExchange instance and starts reading the RPC connection.
+//
+// Parameters:
+//   - config: A pointer to WSExchangeConfiguration containing the configuration settings.
+//
+// Returns:
+//   - A pointer to WSExchange, reading until the RPC connection closes.
+//   - An error if no WebSocket client is provided.
+func NewWSExchange(config *WSExchangeConfiguration) (*WSExchange, error) {
+	if config.Client == nil {
+		return nil, fmt.Errorf("websocket exchange requires a WebSocket client")
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DEFAULT_TIMEOUT
+	}
+
+	exchange := &WSExchange{
+		client:    config.Client,
+		timeout:   timeout,
+		onMessage: config.OnMessage,
+		pending:   make(map[string]chan *types.WebsocketResponse),
+	}
+	go exchange.read()
+	return exchange, nil
+}
+
+// NewOrder creates a new order.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - params: The order parameters.
+//
+// Returns:
+//   - A pointer to the created types.Order.
+//   - An error if the request fails or if the response cannot be decoded.
+func (exchange *WSExchange) NewOrder(ctx context.Context, params *types.NewOrderRequest) (*types.Order, error) {
+	var order *types.Order
+	err := exchange.privateCall(ctx, func(messageId string) error {
+		return exchange.client.NewOrderCtx(ctx, messageId, params)
+	}, &order)
+	return order, err
+}
+
+// CancelOrderAndReplace is not carried by the JSON-RPC websocket, which has no cancel and replace method.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - params: The cancellation and replacement parameters.
+//
+// Returns:
+//   - A nil types.Order.
+//   - A TransportError wrapping `ErrUnsupported`, the request is not sent.
+func (exchange *WSExchange) CancelOrderAndReplace(ctx context.Context, params *types.CancelOrderAndReplaceRequest) (*types.Order, error) {
+	return nil, &TransportError{Err: fmt.Errorf("cancel and replace: %w", ErrUnsupported)}
+}
+
+// CancelOrder cancels an order.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - params: The cancellation parameters.
+//
+// Returns:
+//   - A pointer to the cancelled types.Order.
+//   - An error if the request fails or if the response cannot be decoded.
+func (exchange *WSExchange) CancelOrder(ctx context.Context, params *types.CancelOrderRequest) (*types.Order, error) {
+	var order *types.Order
+	err := exchange.privateCall(ctx, func(messageId string) error {
+		return exchange.client.CancelOrderCtx(ctx, messageId, params)
+	}, &order)
+	return order, err
+}
+
+// CancelAllOpenOrders cancels all open orders for a product.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - product: The product whose orders are cancelled.
+//
+// Returns:
+//   - A slice of the cancelled types.Order.
+//   - An error if the request fails or if the response cannot be decoded.
+func (exchange *WSExchange) CancelAllOpenOrders(ctx context.Context, product *types.Product) ([]types.Order, error) {
+	var orders []types.Order
+	err := exchange.privateCall(ctx, func(messageId string) error {
+		return exchange.client.CancelAllOpenOrdersCtx(ctx, messageId, product)
+	}, &orders)
+	return orders, err
+}
+
+// ListOpenOrders returns the open orders for a product. `order.list` lists orders of any status,
+// those no longer open are filtered out.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - product: The product whose orders are listed.
+//
+// Returns:
+//   - A slice of types.Order.
+//   - An error if the request fails or if the response cannot be decoded.
+func (exchange *WSExchange) ListOpenOrders(ctx context.Context, product *types.Product) ([]types.Order, error) {
+	var orders []types.Order
+	err := exchange.privateCall(ctx, func(messageId string) error {
+		return exchange.client.ListOpenOrdersCtx(ctx, messageId, &types.ListOrdersRequest{Product: product})
+	}, &orders)
+	if err != nil {
+		return nil, err
+	}
+	openOrders := []types.Order{}
+	for _, order := range orders {
+		if order.Status == constants.ORDER_STATUS_OPEN || order.Status == constants.ORDER_STATUS_PARTIALLY_FILLED {
+			openOrders = append(openOrders, order)
+		}
+	}
+	return openOrders, nil
+}
+
+// ApproveSigner approves a signer for the sub-account.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - params: The signer and nonce.
+//
+// Returns:
+//   - A pointer to the types.ApprovedSigner.
+//   - An error if the request fails or if the response cannot be decoded.
+func (exchange *WSExchange) ApproveSigner(ctx context.Context, params *types.ApproveRevokeSignerRequest) (*types.ApprovedSigner, error) {
+	var signer *types.ApprovedSigner
+	err := exchange.privateCall(ctx, func(messageId string) error {
+		return exchange.client.ApproveSignerCtx(ctx, messageId, params)
+	}, &signer)
+	return signer, err
+}
+
+// RevokeSigner revokes a signer of the sub-account.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - params: The signer and nonce.
+//
+// Returns:
+//   - A pointer to the types.ApprovedSigner.
+//   - An error if the request fails or if the response cannot be decoded.
+func (exchange *WSExchange) RevokeSigner(ctx context.Context, params *types.ApproveRevokeSignerRequest) (*types.ApprovedSigner, error) {
+	var signer *types.ApprovedSigner
+	err := exchange.privateCall(ctx, func(messageId string) error {
+		return exchange.client.RevokeSignerCtx(ctx, messageId, params)
+	}, &signer)
+	return signer, err
+}
+
+// Withdraw initiates a withdrawal of USDC.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - params: The withdrawal quantity and nonce.
+//
+// Returns:
+//   - A pointer to the types.SpotBalance after the withdrawal.
+//   - An error if the request fails or if the response cannot be decoded.
+func (exchange *WSExchange) Withdraw(ctx context.Context, params *types.WithdrawRequest) (*types.SpotBalance, error) {
+	var balance *types.SpotBalance
+	err := exchange.privateCall(ctx, func(messageId string) error {
+		return exchange.client.WithdrawCtx(ctx, messageId, params)
+	}, &balance)
+	return balance, err
+}
+
+// PerpetualPositions returns the perpetual positions across all products.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//
+// Returns:
+//   - A slice of types.PerpetualPosition.
+//   - An error if the request fails or if the response cannot be decoded.
+func (exchange *WSExchange) PerpetualPositions(ctx context.Context) ([]types.PerpetualPosition, error) {
+	var positions []types.PerpetualPosition
+	err := exchange.privateCall(ctx, func(messageId string) error {
+		return exchange.client.GetPerpetualPositionCtx(ctx, messageId, nil)
+	}, &positions)
+	return positions, err
+}
+
+// SpotBalances returns the spot balances.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//
+// Returns:
+//   - A slice of types.SpotBalance.
+//   - An error if the request fails or if the response cannot be decoded.
+func (exchange *WSExchange) SpotBalances(ctx context.Context) ([]types.SpotBalance, error) {
+	var balances []types.SpotBalance
+	err := exchange.privateCall(ctx, func(messageId string) error {
+		return exchange.client.GetSpotBalancesCtx(ctx, messageId, nil)
+	}, &balances)
+	return balances, err
+}
+
+// SubAccounts lists the sub-accounts of the account. It is not part of IExchange, and lets
+// `sub_accounts.SubAccountManager` list sub-accounts without reading the RPC connection itself.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//
+// Returns:
+//   - A slice of types.SubAccount.
+//   - An error if the request fails or if the response cannot be decoded.
+func (exchange *WSExchange) SubAccounts(ctx context.Context) ([]types.SubAccount, error) {
+	var subAccounts []types.SubAccount
+	err := exchange.privateCall(ctx, func(messageId string) error {
+		return exchange.client.SubAccountListCtx(ctx, messageId)
+	}, &subAccounts)
+	return subAccounts, err
+}
+
+// privateCall logs in if needed, then sends a request and decodes its response.
+func (exchange *WSExchange) privateCall(ctx context.Context, request func(messageId string) error, result interface{}) error {
+	if err := exchange.login(ctx); err != nil {
+		return err
+	}
+	return exchange.call(ctx, request, result)
+}
+
+// login logs the session in once.
+func (exchange *WSExchange) login(ctx context.Context) error {
+	exchange.loginMutex.Lock()
+	defer exchange.loginMutex.Unlock()
+
+	if exchange.loggedIn {
+		return nil
+	}
+	err := exchange.call(ctx, func(messageId string) error {
+		return exchange.client.LoginCtx(ctx, messageId)
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to login: %w", err)
+	}
+	exchange.loggedIn = true
+	return nil
+}
+
+// call sends a request under a new message ID and waits for its response, decoding its result if not nil.
+func (exchange *WSExchange) call(ctx context.Context, request func(messageId string) error, result interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Register the request.
+	exchange.mutex.Lock()
+	if exchange.err != nil {
+		err := exchange.err
+		exchange.mutex.Unlock()
+		return &TransportError{Err: fmt.Errorf("connection closed: %v", err)}
+	}
+	exchange.sequence++
+	messageId := MESSAGE_ID_PREFIX + strconv.FormatInt(exchange.sequence, 10)
+	responses := make(chan *types.WebsocketResponse, 1)
+	exchange.pending[messageId] = responses
+	exchange.mutex.Unlock()
+	defer func() {
+		exchange.mutex.Lock()
+		delete(exchange.pending, messageId)
+		exchange.mutex.Unlock()
+	}()
+
+	// Send it.
+	exchange.writeMutex.Lock()
+	err := request(messageId)
+	exchange.writeMutex.Unlock()
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return &TransportError{Err: fmt.Errorf("failed to send request: %v", err)}
+	}
+
+	// Wait for its response.
+	timer := time.NewTimer(exchange.timeout)
+	defer timer.Stop()
+	select {
+	case response, ok := <-responses:
+		if !ok {
+			return &TransportError{Sent: true, Err: fmt.Errorf("connection closed: %v", exchange.closeError())}
+		}
+		if response.Error != nil {
+			return utils.NewRPCError(response)
+		}
+		if result != nil {
+			if err := utils.DecodeRPCResult(response, result); err != nil {
+				return fmt.Errorf("failed to decode result: %v", err)
+			}
+		}
+		return nil
+	case <-timer.C:
+		return &TransportError{Sent: true, Err: fmt.Errorf("no response after %v", exchange.timeout)}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// read dispatches RPC messages to pending requests until the connection closes, then fails the pending requests.
+func (exchange *WSExchange) read() {
+	reader := exchange.client.RPCReader()
+	for {
+		_, body, err := reader.ReadMessage()
+		if err != nil {
+			exchange.mutex.Lock()
+			exchange.err = err
+			for messageId, responses := range exchange.pending {
+				close(responses)
+				delete(exchange.pending, messageId)
+			}
+			exchange.mutex.Unlock()
+			return
+		}
+
+		// Deliver responses to their request, other messages to the callback.
+		var response types.WebsocketResponse
+		if err := json.Unmarshal(body, &response); err == nil && response.ID != "" {
+			exchange.mutex.Lock()
+			responses, ok := exchange.pending[response.ID]
+			if ok {
+				responses <- &response
+				delete(exchange.pending, response.ID)
+			}
+			exchange.mutex.Unlock()
+			if ok {
+				continue
+			}
+		}
+		if exchange.onMessage != nil {
+			exchange.onMessage(body)
+		}
+	}
+}
+
+// closeError returns the read error which closed the connection.
+func (exchange *WSExchange) closeError() error {
+	exchange.mutex.Lock()
+	defer exchange.mutex.Unlock()
+	return exchange.err
+}
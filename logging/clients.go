@@ -0,0 +1,118 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+// HTTPClient is a `types.IHTTPClient` logging the requests of another client and their responses, with redacted bodies.
+type HTTPClient struct {
+	client types.IHTTPClient
+	logger *slog.Logger
+}
+
+// NewHTTPClient creates a new HTTPClient instance.
+//
+// Parameters:
+//   - client: The HTTP client sending the requests, e.g. from `utils.GetHTTPClient`.
+//   - logger: The logger of the requests.
+//
+// Returns:
+//   - A pointer to HTTPClient.
+func NewHTTPClient(client types.IHTTPClient, logger *slog.Logger) *HTTPClient {
+	return &HTTPClient{client: client, logger: OrDiscard(logger)}
+}
+
+// Do sends the request, logging it at debug level, its response at debug level or warn level for error statuses,
+// and transport failures at warn level.
+//
+// Parameters:
+//   - req: HTTP request instance to be sent.
+//
+// Returns:
+//   - *http.Response: HTTP response received from the server.
+//   - error: The error of the client.
+func (client *HTTPClient) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if !client.logger.Enabled(ctx, slog.LevelWarn) {
+		return client.client.Do(req)
+	}
+
+	// Log the request, reading its body from a copy.
+	attributes := []any{"method", req.Method, "url", req.URL.String()}
+	if client.logger.Enabled(ctx, slog.LevelDebug) {
+		requestAttributes := attributes
+		if req.GetBody != nil {
+			if body, err := req.GetBody(); err == nil {
+				content, _ := io.ReadAll(body)
+				body.Close()
+				requestAttributes = append(requestAttributes, "body", Redact(content))
+			}
+		}
+		client.logger.DebugContext(ctx, "rest request", requestAttributes...)
+	}
+
+	start := time.Now()
+	res, err := client.client.Do(req)
+	attributes = append(attributes, "duration", time.Since(start))
+	if err != nil {
+		client.logger.WarnContext(ctx, "rest request failed", append(attributes, "error", err)...)
+		return nil, err
+	}
+
+	// Log the response, leaving the body to the caller.
+	level := slog.LevelDebug
+	if res.StatusCode >= http.StatusBadRequest {
+		level = slog.LevelWarn
+	}
+	if client.logger.Enabled(ctx, level) {
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		res.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		client.logger.Log(ctx, level, "rest response", append(attributes, "status", res.StatusCode, "body", Redact(body))...)
+	}
+	return res, nil
+}
+
+// Reader is a `types.IWSReader` logging the messages read from a websocket at debug level, with redacted bodies.
+type Reader struct {
+	reader     types.IWSReader
+	logger     *slog.Logger
+	connection string
+}
+
+// NewReader creates a new Reader instance.
+//
+// Parameters:
+//   - reader: The websocket connection.
+//   - logger: The logger of the messages.
+//   - connection: The name of the connection, logged with each message.
+//
+// Returns:
+//   - A pointer to Reader.
+func NewReader(reader types.IWSReader, logger *slog.Logger, connection string) *Reader {
+	return &Reader{reader: reader, logger: OrDiscard(logger), connection: connection}
+}
+
+// ReadMessage reads the next message and logs it.
+//
+// Returns:
+//   - messageType: The websocket message type.
+//   - body: The message.
+//   - err: The error of the connection.
+func (reader *Reader) ReadMessage() (int, []byte, error) {
+	messageType, body, err := reader.reader.ReadMessage()
+	if err == nil && reader.logger.Enabled(context.Background(), slog.LevelDebug) {
+		reader.logger.Debug("websocket message", "connection", reader.connection, "body", Redact(body))
+	}
+	return messageType, body, err
+}
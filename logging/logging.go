@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+)
+
+const (
+	REDACTED        string = "[REDACTED]" // REDACTED replaces the value of redacted fields.
+	MAX_BODY_LENGTH int    = 4096         // MAX_BODY_LENGTH is the number of bytes of a request or message body logged, longer bodies are truncated.
+)
+
+// REDACTED_FIELDS lists the JSON fields whose value is never logged, compared case-insensitively.
+var REDACTED_FIELDS = []string{"signature", "privateKey", "private_key", "secret", "apiKey", "api_key", "password"}
+
+// discardHandler is a slog.Handler dropping every record.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool   { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error  { return nil }
+func (handler discardHandler) WithAttrs([]slog.Attr) slog.Handler { return handler }
+func (handler discardHandler) WithGroup(string) slog.Handler      { return handler }
+
+// discard is the logger of `OrDiscard`.
+var discard = slog.New(discardHandler{})
+
+// OrDiscard returns a logger, or one discarding every record when it is nil.
+//
+// Parameters:
+//   - logger: The optional logger.
+//
+// Returns:
+//   - The logger, never nil.
+func OrDiscard(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return discard
+	}
+	return logger
+}
+
+// Redact returns a request or message body as logged: the value of `REDACTED_FIELDS` replaced with `REDACTED` at
+// any depth of JSON bodies, and truncated to `MAX_BODY_LENGTH` bytes.
+//
+// Parameters:
+//   - body: The body, JSON or not.
+//
+// Returns:
+//   - The redacted body.
+func Redact(body []byte) string {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err == nil && !decoder.More() {
+		if redacted, err := json.Marshal(redact(value)); err == nil {
+			body = redacted
+		}
+	}
+	if len(body) > MAX_BODY_LENGTH {
+		return string(body[:MAX_BODY_LENGTH]) + "..."
+	}
+	return string(body)
+}
+
+// redact replaces the value of redacted fields in a decoded JSON value.
+func redact(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if isRedacted(key) {
+				value[key] = REDACTED
+			} else {
+				value[key] = redact(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = redact(item)
+		}
+	}
+	return value
+}
+
+// isRedacted returns whether a JSON field is one of `REDACTED_FIELDS`.
+func isRedacted(key string) bool {
+	for _, field := range REDACTED_FIELDS {
+		if strings.EqualFold(key, field) {
+			return true
+		}
+	}
+	return false
+}
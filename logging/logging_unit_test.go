@@ -0,0 +1,172 @@
+//go:build !integration
+// +build !integration
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type LoggingUnitTestSuite struct {
+	suite.Suite
+	Output *bytes.Buffer
+	Logger *slog.Logger
+}
+
+func (s *LoggingUnitTestSuite) SetupTest() {
+	s.Output = &bytes.Buffer{}
+	s.Logger = slog.New(slog.NewJSONHandler(s.Output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func TestRunSuiteUnit_LoggingUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(LoggingUnitTestSuite))
+}
+
+// records returns the records logged since the last call.
+func (s *LoggingUnitTestSuite) records() []map[string]interface{} {
+	var records []map[string]interface{}
+	decoder := json.NewDecoder(s.Output)
+	for decoder.More() {
+		var record map[string]interface{}
+		require.NoError(s.T(), decoder.Decode(&record))
+		records = append(records, record)
+	}
+	return records
+}
+
+// messages is a websocket reader returning messages in order, then an error.
+type messages []string
+
+func (reader *messages) ReadMessage() (int, []byte, error) {
+	if len(*reader) == 0 {
+		return 0, nil, io.EOF
+	}
+	body := (*reader)[0]
+	*reader = (*reader)[1:]
+	return 1, []byte(body), nil
+}
+
+func (s *LoggingUnitTestSuite) TestUnit_Redact() {
+	for body, redacted := range map[string]string{
+		`{"account":"0x1","signature":"0xabc","nonce":1718000000000000001}`:   `{"account":"0x1","nonce":1718000000000000001,"signature":"[REDACTED]"}`,
+		`{"params":{"Signature":"0xabc","orders":[{"PrivateKey":"2638b4"}]}}`: `{"params":{"Signature":"[REDACTED]","orders":[{"PrivateKey":"[REDACTED]"}]}}`,
+		`[{"signature":null},"signature"]`:                                    `[{"signature":"[REDACTED]"},"signature"]`,
+		`not json`:                                                            `not json`,
+		`{"signature":"0xabc"} trailing`:                                      `{"signature":"0xabc"} trailing`,
+		``:                                                                    ``,
+	} {
+		require.Equal(s.T(), redacted, Redact([]byte(body)), body)
+	}
+
+	// Long bodies are truncated.
+	long := Redact([]byte(strings.Repeat("x", 2*MAX_BODY_LENGTH)))
+	require.Len(s.T(), long, MAX_BODY_LENGTH+3)
+	require.True(s.T(), strings.HasSuffix(long, "..."))
+}
+
+func (s *LoggingUnitTestSuite) TestUnit_OrDiscard() {
+	require.Equal(s.T(), s.Logger, OrDiscard(s.Logger))
+	logger := OrDiscard(nil)
+	require.NotNil(s.T(), logger)
+	require.False(s.T(), logger.Enabled(context.Background(), slog.LevelError))
+	logger.With("key", "value").WithGroup("group").Error("dropped")
+}
+
+func (s *LoggingUnitTestSuite) TestUnit_HTTPClient() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":400,"message":"nonce used"}`))
+			return
+		}
+		w.Write([]byte(`{"serverTime":1}`))
+	}))
+	defer server.Close()
+	client := NewHTTPClient(http.DefaultClient, s.Logger)
+
+	// Requests and responses are logged at debug level, with redacted bodies left to the caller.
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/order", strings.NewReader(`{"nonce":1,"signature":"0xabc"}`))
+	res, err := client.Do(req)
+	require.NoError(s.T(), err)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), `{"code":400,"message":"nonce used"}`, string(body))
+	records := s.records()
+	require.Len(s.T(), records, 2)
+	require.Equal(s.T(), "rest request", records[0]["msg"])
+	require.Equal(s.T(), "DEBUG", records[0]["level"])
+	require.Equal(s.T(), `{"nonce":1,"signature":"[REDACTED]"}`, records[0]["body"])
+	require.Equal(s.T(), "rest response", records[1]["msg"])
+	require.Equal(s.T(), "WARN", records[1]["level"])
+	require.Equal(s.T(), float64(http.StatusBadRequest), records[1]["status"])
+	require.Equal(s.T(), server.URL+"/order", records[1]["url"])
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/time", nil)
+	res, err = client.Do(req)
+	require.NoError(s.T(), err)
+	res.Body.Close()
+	records = s.records()
+	require.Len(s.T(), records, 2)
+	require.NotContains(s.T(), records[0], "body")
+	require.Equal(s.T(), "DEBUG", records[1]["level"])
+
+	// Transport failures are logged at warn level.
+	req, _ = http.NewRequest(http.MethodGet, "http://127.0.0.1:1/time", nil)
+	_, err = client.Do(req)
+	require.Error(s.T(), err)
+	records = s.records()
+	require.Len(s.T(), records, 2)
+	require.Equal(s.T(), "rest request failed", records[1]["msg"])
+	require.Equal(s.T(), "WARN", records[1]["level"])
+
+	// Loggers above debug level only log failures.
+	client = NewHTTPClient(http.DefaultClient, slog.New(slog.NewJSONHandler(s.Output, nil)))
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/time", nil)
+	res, err = client.Do(req)
+	require.NoError(s.T(), err)
+	res.Body.Close()
+	req, _ = http.NewRequest(http.MethodPost, server.URL+"/order", strings.NewReader(`{}`))
+	res, err = client.Do(req)
+	require.NoError(s.T(), err)
+	res.Body.Close()
+	records = s.records()
+	require.Len(s.T(), records, 1)
+	require.Equal(s.T(), "rest response", records[0]["msg"])
+
+	// Clients without logger log nothing.
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/time", nil)
+	res, err = NewHTTPClient(http.DefaultClient, nil).Do(req)
+	require.NoError(s.T(), err)
+	res.Body.Close()
+	require.Empty(s.T(), s.records())
+}
+
+func (s *LoggingUnitTestSuite) TestUnit_Reader() {
+	reader := NewReader(&messages{
+		`{"id":"1","result":{"signature":"0xabc"}}`,
+		`{"stream":"ethperp@trade","data":{}}`,
+	}, s.Logger, "rpc")
+	for {
+		if _, _, err := reader.ReadMessage(); err != nil {
+			require.ErrorIs(s.T(), err, io.EOF)
+			break
+		}
+	}
+	records := s.records()
+	require.Len(s.T(), records, 2)
+	require.Equal(s.T(), "websocket message", records[0]["msg"])
+	require.Equal(s.T(), "rpc", records[0]["connection"])
+	require.Equal(s.T(), `{"id":"1","result":{"signature":"[REDACTED]"}}`, records[0]["body"])
+	require.Equal(s.T(), `{"data":{},"stream":"ethperp@trade"}`, records[1]["body"])
+}
@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+)
+
+// ENDPOINT_OTHER labels REST requests to unknown endpoints.
+const ENDPOINT_OTHER string = "other"
+
+// API_ENDPOINTS lists the REST endpoints labelled by their path, longest first so that the most specific one matches.
+var API_ENDPOINTS = sortEndpoints([]types.APIEndpoint{
+	constants.API_ENDPOINT_GET_24H_TICKER_PRICE_CHANGE_STATISTICS,
+	constants.API_ENDPOINT_GET_PRODUCT_BY_ID,
+	constants.API_ENDPOINT_GET_KLINE_DATA,
+	constants.API_ENDPOINT_LIST_PRODUCTS,
+	constants.API_ENDPOINT_ORDER_BOOK,
+	constants.API_ENDPOINT_SERVER_TIME,
+	constants.API_ENDPOINT_APPROVE_REVOKE_SIGNER,
+	constants.API_ENDPOINT_WITHDRAW,
+	constants.API_ENDPOINT_NEW_ORDER,
+	constants.API_ENDPOINT_CANCEL_REPLACE_ORDER,
+	constants.API_ENDPOINT_LIST_OPEN_ORDERS,
+	constants.API_ENDPOINT_GET_SPOT_BALANCES,
+	constants.API_ENDPOINT_GET_PERPETUAL_POSITION,
+	constants.API_ENDPOINT_LIST_ORDERS,
+	constants.API_ENDPOINT_ADD_REFEREE,
+})
+
+// sortEndpoints sorts endpoints longest first, without trailing slash.
+func sortEndpoints(endpoints []types.APIEndpoint) []types.APIEndpoint {
+	for i, endpoint := range endpoints {
+		endpoints[i] = types.APIEndpoint(strings.TrimSuffix(string(endpoint), "/"))
+	}
+	sort.SliceStable(endpoints, func(i, j int) bool { return len(endpoints[i]) > len(endpoints[j]) })
+	return endpoints
+}
+
+// Endpoint returns the `endpoint` label of a REST request: the path of the endpoint it targets, without the base path
+// or path parameters such as a product symbol, or `ENDPOINT_OTHER` if it targets none of `API_ENDPOINTS`.
+//
+// Parameters:
+//   - req: The HTTP request.
+//
+// Returns:
+//   - The endpoint label.
+func Endpoint(req *http.Request) string {
+	// Match endpoints as whole path segments, below any base path.
+	path := req.URL.Path + "/"
+	for _, endpoint := range API_ENDPOINTS {
+		if strings.Contains(path, string(endpoint)+"/") {
+			return string(endpoint)
+		}
+	}
+	return ENDPOINT_OTHER
+}
+
+// HTTPClient is a `types.IHTTPClient` recording the requests of another client, and the reason of rejected orders.
+type HTTPClient struct {
+	client  types.IHTTPClient
+	metrics *Metrics
+}
+
+// NewHTTPClient creates a new HTTPClient instance.
+//
+// Parameters:
+//   - client: The HTTP client sending the requests, e.g. from `utils.GetHTTPClient`.
+//   - metrics: The metrics recording the requests.
+//
+// Returns:
+//   - A pointer to HTTPClient.
+func NewHTTPClient(client types.IHTTPClient, metrics *Metrics) *HTTPClient {
+	return &HTTPClient{client: client, metrics: metrics}
+}
+
+// Do sends the request and records its latency and status.
+//
+// Parameters:
+//   - req: HTTP request instance to be sent.
+//
+// Returns:
+//   - *http.Response: HTTP response received from the server.
+//   - error: The error of the client.
+func (client *HTTPClient) Do(req *http.Request) (*http.Response, error) {
+	endpoint := Endpoint(req)
+	start := time.Now()
+	res, err := client.client.Do(req)
+	if err != nil {
+		client.metrics.ObserveRESTRequest(req.Method, endpoint, 0, time.Since(start))
+		return nil, err
+	}
+	client.metrics.ObserveRESTRequest(req.Method, endpoint, res.StatusCode, time.Since(start))
+
+	// Read the reason of rejected orders, leaving the body to the caller.
+	isOrder := endpoint == string(constants.API_ENDPOINT_NEW_ORDER) || endpoint == string(constants.API_ENDPOINT_CANCEL_REPLACE_ORDER)
+	if isOrder && req.Method == http.MethodPost && res.StatusCode >= http.StatusBadRequest {
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		res.Body = io.NopCloser(bytes.NewReader(body))
+		if err == nil {
+			client.metrics.ObserveOrderReject(utils.NewAPIError(res, body))
+		}
+	}
+	return res, nil
+}
+
+// Reader is a `types.IWSReader` recording the messages read from a websocket.
+type Reader struct {
+	reader  types.IWSReader
+	observe func(body []byte)
+}
+
+// NewRPCReader creates a Reader recording the messages of a JSON RPC websocket with `ObserveRPCMessage`.
+//
+// Parameters:
+//   - reader: The websocket connection.
+//   - metrics: The metrics recording the messages.
+//
+// Returns:
+//   - A pointer to Reader.
+func NewRPCReader(reader types.IWSReader, metrics *Metrics) *Reader {
+	return &Reader{reader: reader, observe: metrics.ObserveRPCMessage}
+}
+
+// NewStreamReader creates a Reader recording the messages of a stream websocket with `ObserveStreamMessage`.
+//
+// Parameters:
+//   - reader: The websocket connection.
+//   - metrics: The metrics recording the messages.
+//
+// Returns:
+//   - A pointer to Reader.
+func NewStreamReader(reader types.IWSReader, metrics *Metrics) *Reader {
+	return &Reader{reader: reader, observe: metrics.ObserveStreamMessage}
+}
+
+// ReadMessage reads the next message and records it.
+//
+// Returns:
+//   - messageType: The websocket message type.
+//   - body: The message.
+//   - err: The error of the connection.
+func (reader *Reader) ReadMessage() (int, []byte, error) {
+	messageType, body, err := reader.reader.ReadMessage()
+	if err == nil {
+		reader.observe(body)
+	}
+	return messageType, body, err
+}
@@ -0,0 +1,359 @@
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	geth_types "github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+)
+
+const (
+	DEFAULT_NAMESPACE        string = "rysk" // DEFAULT_NAMESPACE is the default prefix of metric names.
+	MAX_PENDING_RPC_REQUESTS int    = 4096   // MAX_PENDING_RPC_REQUESTS is the number of RPC requests awaiting a response tracked at once, later requests are not timed.
+)
+
+const (
+	CONNECTION_RPC    string = "rpc"    // CONNECTION_RPC labels the JSON RPC websocket.
+	CONNECTION_STREAM string = "stream" // CONNECTION_STREAM labels the market data stream websocket.
+)
+
+const (
+	STATUS_OK         string = "ok"         // STATUS_OK labels RPC requests answered with a result and transactions which succeeded.
+	STATUS_ERROR      string = "error"      // STATUS_ERROR labels RPC requests answered with an error and transactions which could not be waited for.
+	STATUS_SEND_ERROR string = "send_error" // STATUS_SEND_ERROR labels RPC requests which could not be sent.
+	STATUS_REVERTED   string = "reverted"   // STATUS_REVERTED labels transactions mined but reverted.
+)
+
+const (
+	REASON_INVALID_REQUEST      string = "invalid_request"      // REASON_INVALID_REQUEST labels order rejects reported as a `*utils.ValidationError`.
+	REASON_SIGNATURE            string = "signature"            // REASON_SIGNATURE labels order rejects reported as a `*utils.SignatureError`.
+	REASON_RATE_LIMITED         string = "rate_limited"         // REASON_RATE_LIMITED labels order rejects reported as a `*utils.RateLimitError`.
+	REASON_INSUFFICIENT_BALANCE string = "insufficient_balance" // REASON_INSUFFICIENT_BALANCE labels order rejects reported as a `*utils.InsufficientBalanceError`.
+	REASON_OTHER                string = "other"                // REASON_OTHER labels order rejects with an unknown cause.
+)
+
+// DEFAULT_CONFIRMATION_BUCKETS holds the default buckets of transaction confirmation times, in seconds.
+var DEFAULT_CONFIRMATION_BUCKETS = []float64{1, 2, 5, 10, 20, 30, 60, 120, 300, 600}
+
+// MetricsConfiguration holds the configuration for client metrics.
+type MetricsConfiguration struct {
+	Registerer          prometheus.Registerer // Registerer of the collectors, e.g. `prometheus.DefaultRegisterer`. Metrics are recorded but not exported when nil.
+	Namespace           string                // Prefix of metric names. Defaults to `DEFAULT_NAMESPACE`.
+	Buckets             []float64             // Buckets of request and signing latencies, in seconds. Defaults to `prometheus.DefBuckets`.
+	ConfirmationBuckets []float64             // Buckets of transaction confirmation times, in seconds. Defaults to `DEFAULT_CONFIRMATION_BUCKETS`.
+}
+
+// Metrics records the operations of the REST and websocket clients as Prometheus counters and histograms.
+// One instance can be shared by the clients of several accounts. A nil *Metrics records nothing.
+type Metrics struct {
+	restRequests         *prometheus.CounterVec
+	restDuration         *prometheus.HistogramVec
+	rpcDuration          *prometheus.HistogramVec
+	reconnects           *prometheus.CounterVec
+	streamMessages       *prometheus.CounterVec
+	signingDuration      *prometheus.HistogramVec
+	orderRejects         *prometheus.CounterVec
+	confirmationDuration *prometheus.HistogramVec
+
+	mutex   sync.Mutex            // mutex guards pending.
+	pending map[string]rpcRequest // pending holds the RPC requests awaiting a response by message ID.
+}
+
+// rpcRequest is an RPC request awaiting its response.
+type rpcRequest struct {
+	method types.WSMethod
+	sentAt time.Time
+}
+
+// NewMetrics creates a new Metrics instance and registers its collectors.
+//
+// Parameters:
+//   - config: A pointer to MetricsConfiguration containing the configuration settings.
+//
+// Returns:
+//   - A pointer to Metrics.
+//   - An error if a collector cannot be registered, e.g. because another Metrics registered it with the same namespace.
+func NewMetrics(config *MetricsConfiguration) (*Metrics, error) {
+	namespace := config.Namespace
+	if namespace == "" {
+		namespace = DEFAULT_NAMESPACE
+	}
+	buckets := config.Buckets
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+	confirmationBuckets := config.ConfirmationBuckets
+	if len(confirmationBuckets) == 0 {
+		confirmationBuckets = DEFAULT_CONFIRMATION_BUCKETS
+	}
+
+	metrics := &Metrics{
+		restRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rest_requests_total",
+			Help:      "REST requests sent, by HTTP method, endpoint and status code, `error` when no response was received.",
+		}, []string{"method", "endpoint", "status"}),
+		restDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "rest_request_duration_seconds",
+			Help:      "Latency of REST requests, by HTTP method and endpoint.",
+			Buckets:   buckets,
+		}, []string{"method", "endpoint"}),
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "rpc_duration_seconds",
+			Help:      "Round-trip latency of websocket requests, by method and status.",
+			Buckets:   buckets,
+		}, []string{"method", "status"}),
+		reconnects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "websocket_reconnects_total",
+			Help:      "Websocket reconnections, by connection.",
+		}, []string{"connection"}),
+		streamMessages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "stream_messages_total",
+			Help:      "Market data stream messages received, by topic.",
+		}, []string{"topic"}),
+		signingDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "signing_duration_seconds",
+			Help:      "Latency of EIP-712 signatures, by primary type.",
+			Buckets:   buckets,
+		}, []string{"primary_type"}),
+		orderRejects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "order_rejects_total",
+			Help:      "Orders rejected by the exchange, by reason.",
+		}, []string{"reason"}),
+		confirmationDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "transaction_confirmation_duration_seconds",
+			Help:      "Time waited for on-chain transactions to be confirmed, by status.",
+			Buckets:   confirmationBuckets,
+		}, []string{"status"}),
+		pending: make(map[string]rpcRequest),
+	}
+
+	// Register the collectors.
+	if config.Registerer != nil {
+		for _, collector := range metrics.collectors() {
+			if err := config.Registerer.Register(collector); err != nil {
+				return nil, fmt.Errorf("failed to register metrics: %v", err)
+			}
+		}
+	}
+	return metrics, nil
+}
+
+// collectors returns the collectors of the metrics.
+func (metrics *Metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		metrics.restRequests,
+		metrics.restDuration,
+		metrics.rpcDuration,
+		metrics.reconnects,
+		metrics.streamMessages,
+		metrics.signingDuration,
+		metrics.orderRejects,
+		metrics.confirmationDuration,
+	}
+}
+
+// ObserveRESTRequest records a REST request.
+//
+// Parameters:
+//   - method: The HTTP method.
+//   - endpoint: The endpoint, see `Endpoint`.
+//   - statusCode: The HTTP status code, 0 if no response was received.
+//   - duration: The latency of the request.
+func (metrics *Metrics) ObserveRESTRequest(method string, endpoint string, statusCode int, duration time.Duration) {
+	if metrics == nil {
+		return
+	}
+	status := STATUS_ERROR
+	if statusCode != 0 {
+		status = strconv.Itoa(statusCode)
+	}
+	metrics.restRequests.WithLabelValues(method, endpoint, status).Inc()
+	metrics.restDuration.WithLabelValues(method, endpoint).Observe(duration.Seconds())
+}
+
+// RPCSent starts timing a websocket request, until its response is passed to `ObserveRPCMessage` or `ObserveStreamMessage`.
+// Requests reusing the message ID of a request awaiting its response replace it.
+//
+// Parameters:
+//   - messageId: The message ID of the request.
+//   - method: The method of the request.
+func (metrics *Metrics) RPCSent(messageId string, method types.WSMethod) {
+	if metrics == nil || messageId == "" {
+		return
+	}
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+	if _, ok := metrics.pending[messageId]; ok || len(metrics.pending) < MAX_PENDING_RPC_REQUESTS {
+		metrics.pending[messageId] = rpcRequest{method: method, sentAt: time.Now()}
+	}
+}
+
+// RPCFailed records a websocket request which could not be sent, and stops timing it.
+//
+// Parameters:
+//   - messageId: The message ID of the request.
+func (metrics *Metrics) RPCFailed(messageId string) {
+	if metrics == nil {
+		return
+	}
+	if request, ok := metrics.takePending(messageId); ok {
+		metrics.rpcDuration.WithLabelValues(string(request.method), STATUS_SEND_ERROR).Observe(time.Since(request.sentAt).Seconds())
+	}
+}
+
+// ObserveRPCMessage records a message read from the RPC websocket: the round-trip latency of responses to
+// timed requests, and the reason of rejected orders.
+//
+// Parameters:
+//   - body: The message.
+func (metrics *Metrics) ObserveRPCMessage(body []byte) {
+	if metrics == nil {
+		return
+	}
+	var response types.WebsocketResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return
+	}
+	metrics.observeResponse(&response)
+}
+
+// ObserveStreamMessage records a message read from the stream websocket: the topic of stream messages,
+// and the round-trip latency of responses to timed requests.
+//
+// Parameters:
+//   - body: The message.
+func (metrics *Metrics) ObserveStreamMessage(body []byte) {
+	if metrics == nil {
+		return
+	}
+	var message struct {
+		types.WebsocketResponse
+		Stream string `json:"stream"`
+	}
+	if err := json.Unmarshal(body, &message); err != nil {
+		return
+	}
+	if message.Stream != "" {
+		metrics.streamMessages.WithLabelValues(message.Stream).Inc()
+		return
+	}
+	metrics.observeResponse(&message.WebsocketResponse)
+}
+
+// observeResponse records the response to a timed request.
+func (metrics *Metrics) observeResponse(response *types.WebsocketResponse) {
+	request, ok := metrics.takePending(response.ID)
+	if !ok {
+		return
+	}
+	status := STATUS_OK
+	if response.Error != nil {
+		status = STATUS_ERROR
+		if request.method == constants.WS_METHOD_NEW_ORDER {
+			metrics.ObserveOrderReject(utils.NewRPCError(response))
+		}
+	}
+	metrics.rpcDuration.WithLabelValues(string(request.method), status).Observe(time.Since(request.sentAt).Seconds())
+}
+
+// takePending removes and returns a timed request.
+func (metrics *Metrics) takePending(messageId string) (rpcRequest, bool) {
+	if messageId == "" {
+		return rpcRequest{}, false
+	}
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+	request, ok := metrics.pending[messageId]
+	delete(metrics.pending, messageId)
+	return request, ok
+}
+
+// ObserveOrderReject records an order rejected by the exchange.
+//
+// Parameters:
+//   - err: The error reporting the rejection, an *utils.APIError or *utils.RPCError possibly wrapped,
+//     the specific error wrapping it giving the reason. Other errors are not recorded.
+func (metrics *Metrics) ObserveOrderReject(err error) {
+	if metrics == nil {
+		return
+	}
+	var apiError *utils.APIError
+	var rpcError *utils.RPCError
+	if !errors.As(err, &apiError) && !errors.As(err, &rpcError) {
+		return
+	}
+	var validationError *utils.ValidationError
+	var signatureError *utils.SignatureError
+	var rateLimitError *utils.RateLimitError
+	var insufficientBalanceError *utils.InsufficientBalanceError
+	reason := REASON_OTHER
+	switch {
+	case errors.As(err, &validationError):
+		reason = REASON_INVALID_REQUEST
+	case errors.As(err, &signatureError):
+		reason = REASON_SIGNATURE
+	case errors.As(err, &rateLimitError):
+		reason = REASON_RATE_LIMITED
+	case errors.As(err, &insufficientBalanceError):
+		reason = REASON_INSUFFICIENT_BALANCE
+	}
+	metrics.orderRejects.WithLabelValues(reason).Inc()
+}
+
+// Reconnected records a websocket reconnection.
+//
+// Parameters:
+//   - connection: The reconnected websocket, `CONNECTION_RPC` or `CONNECTION_STREAM`.
+func (metrics *Metrics) Reconnected(connection string) {
+	if metrics == nil {
+		return
+	}
+	metrics.reconnects.WithLabelValues(connection).Inc()
+}
+
+// ObserveSigning records the latency of an EIP-712 signature.
+//
+// Parameters:
+//   - primaryType: The primary type of the signed message.
+//   - duration: The time taken to sign it.
+func (metrics *Metrics) ObserveSigning(primaryType types.PrimaryType, duration time.Duration) {
+	if metrics == nil {
+		return
+	}
+	metrics.signingDuration.WithLabelValues(string(primaryType)).Observe(duration.Seconds())
+}
+
+// ObserveTransaction records the time waited for an on-chain transaction to be confirmed.
+//
+// Parameters:
+//   - receipt: The receipt of the transaction, nil if waiting failed.
+//   - duration: The time waited.
+func (metrics *Metrics) ObserveTransaction(receipt *geth_types.Receipt, duration time.Duration) {
+	if metrics == nil {
+		return
+	}
+	status := STATUS_OK
+	switch {
+	case receipt == nil:
+		status = STATUS_ERROR
+	case receipt.Status != geth_types.ReceiptStatusSuccessful:
+		status = STATUS_REVERTED
+	}
+	metrics.confirmationDuration.WithLabelValues(status).Observe(duration.Seconds())
+}
@@ -0,0 +1,228 @@
+//go:build !integration
+// +build !integration
+
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	geth_types "github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/utils"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type MetricsUnitTestSuite struct {
+	suite.Suite
+	Registry *prometheus.Registry
+	Metrics  *Metrics
+}
+
+func (s *MetricsUnitTestSuite) SetupTest() {
+	s.Registry = prometheus.NewRegistry()
+	metrics, err := NewMetrics(&MetricsConfiguration{Registerer: s.Registry})
+	require.NoError(s.T(), err)
+	s.Metrics = metrics
+}
+
+func TestRunSuiteUnit_MetricsUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(MetricsUnitTestSuite))
+}
+
+// messages is a websocket reader returning messages in order, then an error.
+type messages []string
+
+func (reader *messages) ReadMessage() (int, []byte, error) {
+	if len(*reader) == 0 {
+		return 0, nil, io.EOF
+	}
+	body := (*reader)[0]
+	*reader = (*reader)[1:]
+	return 1, []byte(body), nil
+}
+
+// samples returns the number of observations of a histogram.
+func (s *MetricsUnitTestSuite) samples(observer prometheus.Observer) uint64 {
+	metric := &dto.Metric{}
+	require.NoError(s.T(), observer.(prometheus.Metric).Write(metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func (s *MetricsUnitTestSuite) TestUnit_NewMetrics() {
+	count, err := testutil.GatherAndCount(s.Registry)
+	require.NoError(s.T(), err)
+	require.Zero(s.T(), count)
+
+	// Collectors are registered once per namespace.
+	_, err = NewMetrics(&MetricsConfiguration{Registerer: s.Registry})
+	require.Error(s.T(), err)
+	_, err = NewMetrics(&MetricsConfiguration{Registerer: s.Registry, Namespace: "other"})
+	require.NoError(s.T(), err)
+
+	// Metrics without registerer or instance record nothing visible.
+	unregistered, err := NewMetrics(&MetricsConfiguration{})
+	require.NoError(s.T(), err)
+	unregistered.Reconnected(CONNECTION_RPC)
+	var metrics *Metrics
+	metrics.Reconnected(CONNECTION_RPC)
+	metrics.RPCSent("1", constants.WS_METHOD_LOGIN)
+	metrics.ObserveRPCMessage([]byte(`{"id":"1"}`))
+	metrics.ObserveStreamMessage([]byte(`{"stream":"ethperp@trade"}`))
+	metrics.ObserveSigning(constants.PRIMARY_TYPE_ORDER, time.Second)
+	metrics.ObserveTransaction(nil, time.Second)
+	metrics.ObserveOrderReject(&utils.APIError{Code: 400})
+	count, err = testutil.GatherAndCount(s.Registry)
+	require.NoError(s.T(), err)
+	require.Zero(s.T(), count)
+}
+
+func (s *MetricsUnitTestSuite) TestUnit_Endpoint() {
+	for path, endpoint := range map[string]string{
+		"/api/products":                  "/products",
+		"/api/products/ethperp":          "/products",
+		"/api/products/product-by-id/1":  "/products/product-by-id",
+		"/api/order":                     "/order",
+		"/api/order/cancel-and-replace":  "/order/cancel-and-replace",
+		"/api/orders":                    "/orders",
+		"/api/openOrders":                "/openOrders",
+		"/api/ticker/24hr":               "/ticker/24hr",
+		"/api/referral/add-referee":      "/referral/add-referee",
+		"/api/unknown":                   ENDPOINT_OTHER,
+		"/api/orderbook":                 ENDPOINT_OTHER,
+		"/api/approved-signers?signer=1": "/approved-signers",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost"+path, nil)
+		require.Equal(s.T(), endpoint, Endpoint(req), path)
+	}
+}
+
+func (s *MetricsUnitTestSuite) TestUnit_HTTPClient() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":400,"message":"insufficient balance"}`))
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+	client := NewHTTPClient(http.DefaultClient, s.Metrics)
+
+	// Requests are recorded by endpoint and status.
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/products/ethperp", nil)
+	res, err := client.Do(req)
+	require.NoError(s.T(), err)
+	res.Body.Close()
+	require.Equal(s.T(), float64(1), testutil.ToFloat64(s.Metrics.restRequests.WithLabelValues(http.MethodGet, "/products", "200")))
+	require.Equal(s.T(), 1, testutil.CollectAndCount(s.Metrics.restDuration))
+
+	// Rejected orders are recorded by reason, the body is left to the caller.
+	req, _ = http.NewRequest(http.MethodPost, server.URL+"/order", strings.NewReader(`{}`))
+	res, err = client.Do(req)
+	require.NoError(s.T(), err)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(s.T(), err)
+	require.Contains(s.T(), string(body), "insufficient balance")
+	require.Equal(s.T(), float64(1), testutil.ToFloat64(s.Metrics.orderRejects.WithLabelValues("insufficient_balance")))
+
+	// Other failed requests are not rejects.
+	req, _ = http.NewRequest(http.MethodPost, server.URL+"/withdraw", strings.NewReader(`{}`))
+	res, err = client.Do(req)
+	require.NoError(s.T(), err)
+	res.Body.Close()
+	require.Equal(s.T(), 1, testutil.CollectAndCount(s.Metrics.orderRejects))
+
+	// Transport failures are recorded as errors.
+	req, _ = http.NewRequest(http.MethodGet, "http://127.0.0.1:1/time", nil)
+	_, err = client.Do(req)
+	require.Error(s.T(), err)
+	require.Equal(s.T(), float64(1), testutil.ToFloat64(s.Metrics.restRequests.WithLabelValues(http.MethodGet, "/time", STATUS_ERROR)))
+}
+
+func (s *MetricsUnitTestSuite) TestUnit_RPC() {
+	s.Metrics.RPCSent("login", constants.WS_METHOD_LOGIN)
+	s.Metrics.RPCSent("order", constants.WS_METHOD_NEW_ORDER)
+	s.Metrics.RPCSent("cancel", constants.WS_METHOD_CANCEL_ORDER)
+	s.Metrics.RPCSent("subscribe", constants.WS_METHOD_MARKET_DATA_STREAMS_SUBSCRIBE)
+	s.Metrics.RPCFailed("cancel")
+
+	// Responses are timed once, notifications and unknown IDs are ignored.
+	reader := NewRPCReader(&messages{
+		`{"id":"login","result":true}`,
+		`{"id":"login","result":true}`,
+		`{"id":"order","error":{"code":400,"message":"nonce used"}}`,
+		`{"method":"account.updates","params":{}}`,
+		`not json`,
+	}, s.Metrics)
+	for {
+		if _, _, err := reader.ReadMessage(); err != nil {
+			require.ErrorIs(s.T(), err, io.EOF)
+			break
+		}
+	}
+	require.Equal(s.T(), uint64(1), s.samples(s.Metrics.rpcDuration.WithLabelValues(string(constants.WS_METHOD_LOGIN), STATUS_OK)))
+	require.Equal(s.T(), uint64(1), s.samples(s.Metrics.rpcDuration.WithLabelValues(string(constants.WS_METHOD_CANCEL_ORDER), STATUS_SEND_ERROR)))
+	require.Equal(s.T(), float64(1), testutil.ToFloat64(s.Metrics.orderRejects.WithLabelValues(REASON_INVALID_REQUEST)))
+	require.Equal(s.T(), 3, testutil.CollectAndCount(s.Metrics.rpcDuration))
+	require.Len(s.T(), s.Metrics.pending, 1)
+
+	// Stream messages are counted by topic, subscription responses timed.
+	reader = NewStreamReader(&messages{
+		`{"id":"subscribe","result":null}`,
+		`{"stream":"ethperp@trade","data":{}}`,
+		`{"stream":"ethperp@trade","data":{}}`,
+		`{"stream":"btcperp@depth5_0","data":{}}`,
+	}, s.Metrics)
+	for i := 0; i < 4; i++ {
+		_, _, err := reader.ReadMessage()
+		require.NoError(s.T(), err)
+	}
+	require.Equal(s.T(), float64(2), testutil.ToFloat64(s.Metrics.streamMessages.WithLabelValues("ethperp@trade")))
+	require.Equal(s.T(), float64(1), testutil.ToFloat64(s.Metrics.streamMessages.WithLabelValues("btcperp@depth5_0")))
+	require.Empty(s.T(), s.Metrics.pending)
+	require.Equal(s.T(), 4, testutil.CollectAndCount(s.Metrics.rpcDuration))
+
+	// Pending requests are bounded.
+	for i := 0; i <= MAX_PENDING_RPC_REQUESTS; i++ {
+		s.Metrics.RPCSent(strings.Repeat("x", i+1), constants.WS_METHOD_LOGIN)
+	}
+	require.Len(s.T(), s.Metrics.pending, MAX_PENDING_RPC_REQUESTS)
+}
+
+func (s *MetricsUnitTestSuite) TestUnit_Observe() {
+	s.Metrics.Reconnected(CONNECTION_STREAM)
+	s.Metrics.Reconnected(CONNECTION_STREAM)
+	require.Equal(s.T(), float64(2), testutil.ToFloat64(s.Metrics.reconnects.WithLabelValues(CONNECTION_STREAM)))
+
+	s.Metrics.ObserveSigning(constants.PRIMARY_TYPE_ORDER, time.Millisecond)
+	require.Equal(s.T(), uint64(1), s.samples(s.Metrics.signingDuration.WithLabelValues(string(constants.PRIMARY_TYPE_ORDER))))
+
+	s.Metrics.ObserveTransaction(&geth_types.Receipt{Status: geth_types.ReceiptStatusSuccessful}, time.Second)
+	s.Metrics.ObserveTransaction(&geth_types.Receipt{Status: geth_types.ReceiptStatusFailed}, time.Second)
+	s.Metrics.ObserveTransaction(nil, time.Second)
+	require.Equal(s.T(), 3, testutil.CollectAndCount(s.Metrics.confirmationDuration))
+
+	// Rejects need an exchange error, classified by its cause.
+	s.Metrics.ObserveOrderReject(&utils.ValidationError{Err: &utils.RPCError{Code: 999}})
+	s.Metrics.ObserveOrderReject(&utils.RPCError{Code: 999})
+	s.Metrics.ObserveOrderReject(&utils.ValidationError{Message: "invalid quantity"})
+	s.Metrics.ObserveOrderReject(errors.New("timeout"))
+	require.Equal(s.T(), float64(1), testutil.ToFloat64(s.Metrics.orderRejects.WithLabelValues(REASON_INVALID_REQUEST)))
+	require.Equal(s.T(), float64(1), testutil.ToFloat64(s.Metrics.orderRejects.WithLabelValues(REASON_OTHER)))
+	require.Equal(s.T(), 2, testutil.CollectAndCount(s.Metrics.orderRejects))
+
+	// Metric names are prefixed with the namespace.
+	count, err := testutil.GatherAndCount(s.Registry, "rysk_websocket_reconnects_total", "rysk_transaction_confirmation_duration_seconds")
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 4, count)
+}
@@ -0,0 +1,116 @@
+package ratelimit
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+// PUBLIC_API_ENDPOINTS lists the REST endpoints serving public market data.
+var PUBLIC_API_ENDPOINTS = []types.APIEndpoint{
+	constants.API_ENDPOINT_GET_24H_TICKER_PRICE_CHANGE_STATISTICS,
+	constants.API_ENDPOINT_LIST_PRODUCTS,
+	constants.API_ENDPOINT_GET_KLINE_DATA,
+	constants.API_ENDPOINT_ORDER_BOOK,
+	constants.API_ENDPOINT_SERVER_TIME,
+}
+
+// WS_METHOD_CLASSES maps the JSON RPC websocket methods to their endpoint class.
+var WS_METHOD_CLASSES = map[types.WSMethod]types.EndpointClass{
+	constants.WS_METHOD_LIST_PRODUCTS:                   constants.ENDPOINT_CLASS_PUBLIC,
+	constants.WS_METHOD_GET_PRODUCT:                     constants.ENDPOINT_CLASS_PUBLIC,
+	constants.WS_METHOD_SERVER_TIME:                     constants.ENDPOINT_CLASS_PUBLIC,
+	constants.WS_METHOD_ORDER_BOOK_DEPTH:                constants.ENDPOINT_CLASS_PUBLIC,
+	constants.WS_METHOD_MARKET_DATA_STREAMS_SUBSCRIBE:   constants.ENDPOINT_CLASS_PUBLIC,
+	constants.WS_METHOD_MARKET_DATA_STREAMS_UNSUBSCRIBE: constants.ENDPOINT_CLASS_PUBLIC,
+	constants.WS_METHOD_LOGIN:                           constants.ENDPOINT_CLASS_PRIVATE_READ,
+	constants.WS_METHOD_SESSION_STATUS:                  constants.ENDPOINT_CLASS_PRIVATE_READ,
+	constants.WS_METHOD_SUB_ACCOUNT_LIST:                constants.ENDPOINT_CLASS_PRIVATE_READ,
+	constants.WS_METHOD_ORDER_LIST:                      constants.ENDPOINT_CLASS_PRIVATE_READ,
+	constants.WS_METHOD_GET_PERPETUAL_POSITION:          constants.ENDPOINT_CLASS_PRIVATE_READ,
+	constants.WS_METHOD_GET_SPOT_BALANCES:               constants.ENDPOINT_CLASS_PRIVATE_READ,
+	constants.WS_METHOD_ACCOUNT_UPDATES:                 constants.ENDPOINT_CLASS_PRIVATE_READ,
+	constants.WS_METHOD_NEW_ORDER:                       constants.ENDPOINT_CLASS_ORDER_ENTRY,
+	constants.WS_METHOD_CANCEL_ORDER:                    constants.ENDPOINT_CLASS_ORDER_ENTRY,
+	constants.WS_METHOD_CANCEL_ALL_OPEN_ORDERS:          constants.ENDPOINT_CLASS_ORDER_ENTRY,
+	constants.WS_METHOD_APPROVE_REVOKE_SIGNER:           constants.ENDPOINT_CLASS_ORDER_ENTRY,
+	constants.WS_METHOD_WITHDRAW:                        constants.ENDPOINT_CLASS_ORDER_ENTRY,
+}
+
+// ClassifyHTTPRequest returns the endpoint class of a REST request.
+// Writes are order entry, reads of `PUBLIC_API_ENDPOINTS` are public and other reads are private.
+//
+// Parameters:
+//   - req: The HTTP request.
+//
+// Returns:
+//   - The endpoint class of the request.
+func ClassifyHTTPRequest(req *http.Request) types.EndpointClass {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return constants.ENDPOINT_CLASS_ORDER_ENTRY
+	}
+	// Match endpoints as whole path segments, below any base path.
+	path := req.URL.Path + "/"
+	for _, endpoint := range PUBLIC_API_ENDPOINTS {
+		if strings.Contains(path, strings.TrimSuffix(string(endpoint), "/")+"/") {
+			return constants.ENDPOINT_CLASS_PUBLIC
+		}
+	}
+	return constants.ENDPOINT_CLASS_PRIVATE_READ
+}
+
+// ClassifyWSMethod returns the endpoint class of a JSON RPC websocket method.
+//
+// Parameters:
+//   - method: The websocket method.
+//
+// Returns:
+//   - The endpoint class of the method, `constants.ENDPOINT_CLASS_PRIVATE_READ` for unknown methods.
+func ClassifyWSMethod(method types.WSMethod) types.EndpointClass {
+	if class, ok := WS_METHOD_CLASSES[method]; ok {
+		return class
+	}
+	return constants.ENDPOINT_CLASS_PRIVATE_READ
+}
+
+// HTTPClient is a `types.IHTTPClient` sending requests of another client within the budgets of a rate limiter,
+// and adjusting the budgets from the rate limit headers of the responses.
+type HTTPClient struct {
+	client  types.IHTTPClient
+	limiter *RateLimiter
+}
+
+// NewHTTPClient creates a new HTTPClient instance.
+//
+// Parameters:
+//   - client: The HTTP client sending the requests, e.g. from `utils.GetHTTPClient`.
+//   - limiter: The rate limiter holding the budgets.
+//
+// Returns:
+//   - A pointer to HTTPClient.
+func NewHTTPClient(client types.IHTTPClient, limiter *RateLimiter) *HTTPClient {
+	return &HTTPClient{client: client, limiter: limiter}
+}
+
+// Do waits for the budget of the request class, then sends the request.
+//
+// Parameters:
+//   - req: HTTP request instance to be sent.
+//
+// Returns:
+//   - *http.Response: HTTP response received from the server.
+//   - error: A *utils.RateLimitError when failing fast, the context error, or the error of the client.
+func (client *HTTPClient) Do(req *http.Request) (*http.Response, error) {
+	class := ClassifyHTTPRequest(req)
+	if err := client.limiter.Wait(req.Context(), class); err != nil {
+		return nil, err
+	}
+	res, err := client.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	client.limiter.AdjustFromResponse(class, res)
+	return res, nil
+}
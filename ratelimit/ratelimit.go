@@ -0,0 +1,247 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+)
+
+// DEFAULT_BUDGETS holds the budgets of the endpoint classes missing from a configuration.
+var DEFAULT_BUDGETS = map[types.EndpointClass]Budget{
+	constants.ENDPOINT_CLASS_PUBLIC:       {Rate: 20, Burst: 40},
+	constants.ENDPOINT_CLASS_PRIVATE_READ: {Rate: 10, Burst: 20},
+	constants.ENDPOINT_CLASS_ORDER_ENTRY:  {Rate: 10, Burst: 20},
+}
+
+// ErrRateLimited reports a request rejected by a fail-fast rate limiter because its budget is exhausted.
+var ErrRateLimited = errors.New("rate limited")
+
+// Budget is the token bucket of an endpoint class.
+type Budget struct {
+	Rate  float64 // Requests per second refilling the bucket.
+	Burst int     // Bucket capacity, the number of requests which can be sent at once.
+}
+
+// Usage reports the state of an endpoint class budget.
+type Usage struct {
+	Budget    Budget        // Current budget, as configured or adjusted by rate limit response headers.
+	Available float64       // Requests which can be sent now, negative while requests wait for the budget.
+	Requests  int64         // Number of requests admitted.
+	Delayed   int64         // Number of admitted requests which waited for the budget.
+	Rejected  int64         // Number of requests rejected by a fail-fast limiter or cancelled while waiting.
+	Waited    time.Duration // Total time admitted requests waited for the budget.
+}
+
+// RateLimiterConfiguration holds the configuration for a rate limiter.
+type RateLimiterConfiguration struct {
+	Budgets  map[types.EndpointClass]Budget // Budgets by endpoint class. Classes missing use `DEFAULT_BUDGETS`.
+	FailFast bool                           // Whether requests over budget fail with `ErrRateLimited` instead of waiting.
+}
+
+// RateLimiter is a client-side token bucket rate limiter with a budget per endpoint class.
+// One limiter can be shared by the REST and websocket clients of an account so that they draw from the same budgets.
+type RateLimiter struct {
+	failFast bool
+	mutex    sync.Mutex                      // mutex guards the buckets.
+	buckets  map[types.EndpointClass]*bucket // buckets holds the state of each endpoint class.
+}
+
+// bucket is the token bucket of an endpoint class.
+type bucket struct {
+	budget       Budget
+	tokens       float64   // tokens available at `updated`, negative when reserved by waiting requests.
+	updated      time.Time // updated is when tokens were last refilled.
+	blockedUntil time.Time // blockedUntil is when the server allows requests again, after exhausting its limit.
+	usage        Usage
+}
+
+// NewRateLimiter creates a new RateLimiter instance with full buckets.
+//
+// Parameters:
+//   - config: A pointer to RateLimiterConfiguration containing the configuration settings.
+//
+// Returns:
+//   - A pointer to RateLimiter.
+//   - An error if a budget has no positive rate or burst.
+func NewRateLimiter(config *RateLimiterConfiguration) (*RateLimiter, error) {
+	limiter := &RateLimiter{
+		failFast: config.FailFast,
+		buckets:  make(map[types.EndpointClass]*bucket),
+	}
+	for class, budget := range DEFAULT_BUDGETS {
+		limiter.buckets[class] = newBucket(budget)
+	}
+	for class, budget := range config.Budgets {
+		if budget.Rate <= 0 || budget.Burst <= 0 {
+			return nil, fmt.Errorf("invalid budget for endpoint class %q: rate and burst must be positive", class)
+		}
+		limiter.buckets[class] = newBucket(budget)
+	}
+	return limiter, nil
+}
+
+// newBucket creates a full bucket.
+func newBucket(budget Budget) *bucket {
+	return &bucket{budget: budget, tokens: float64(budget.Burst), updated: time.Now()}
+}
+
+// refill adds the tokens earned since the last refill.
+func (bucket *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(bucket.updated); elapsed > 0 {
+		bucket.tokens = min(bucket.tokens+elapsed.Seconds()*bucket.budget.Rate, float64(bucket.budget.Burst))
+		bucket.updated = now
+	}
+}
+
+// Wait takes a request from the budget of an endpoint class, waiting until the budget allows it.
+// Classes without a budget are not limited.
+//
+// Parameters:
+//   - ctx: Context of the request, cancelling the wait.
+//   - class: The endpoint class of the request.
+//
+// Returns:
+//   - A *utils.RateLimitError wrapping `ErrRateLimited` if the limiter fails fast and the budget is exhausted,
+//     or the context error if the context is done first.
+func (limiter *RateLimiter) Wait(ctx context.Context, class types.EndpointClass) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Reserve a token.
+	limiter.mutex.Lock()
+	bucket, ok := limiter.buckets[class]
+	if !ok {
+		limiter.mutex.Unlock()
+		return nil
+	}
+	now := time.Now()
+	bucket.refill(now)
+	var wait time.Duration
+	if bucket.tokens < 1 {
+		wait = time.Duration((1 - bucket.tokens) / bucket.budget.Rate * float64(time.Second))
+	}
+	wait = max(wait, bucket.blockedUntil.Sub(now))
+	if wait > 0 && limiter.failFast {
+		bucket.usage.Rejected++
+		limiter.mutex.Unlock()
+		return &utils.RateLimitError{Message: fmt.Sprintf("%s budget exhausted for %v", class, wait), RetryAfter: wait, Err: ErrRateLimited}
+	}
+	bucket.tokens--
+	bucket.usage.Requests++
+	if wait > 0 {
+		bucket.usage.Delayed++
+		bucket.usage.Waited += wait
+	}
+	limiter.mutex.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+
+	// Wait for it, handing it back if cancelled.
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		limiter.mutex.Lock()
+		bucket.tokens = min(bucket.tokens+1, float64(bucket.budget.Burst))
+		bucket.usage.Requests--
+		bucket.usage.Delayed--
+		bucket.usage.Waited -= wait
+		bucket.usage.Rejected++
+		limiter.mutex.Unlock()
+		return ctx.Err()
+	}
+}
+
+// SetBudget replaces the budget of an endpoint class, keeping the requests available within the new burst.
+//
+// Parameters:
+//   - class: The endpoint class.
+//   - budget: The new budget.
+//
+// Returns:
+//   - An error if the budget has no positive rate or burst.
+func (limiter *RateLimiter) SetBudget(class types.EndpointClass, budget Budget) error {
+	if budget.Rate <= 0 || budget.Burst <= 0 {
+		return fmt.Errorf("invalid budget for endpoint class %q: rate and burst must be positive", class)
+	}
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	bucket, ok := limiter.buckets[class]
+	if !ok {
+		limiter.buckets[class] = newBucket(budget)
+		return nil
+	}
+	bucket.refill(time.Now())
+	bucket.budget = budget
+	bucket.tokens = min(bucket.tokens, float64(budget.Burst))
+	return nil
+}
+
+// AdjustFromResponse aligns the budget of an endpoint class with the rate limit the server reports.
+// `X-RateLimit-Limit` sets the burst, `X-RateLimit-Remaining` caps the requests available, and an exhausted
+// limit blocks the class until `X-RateLimit-Reset`. `429 Too Many Requests` responses block the class
+// until `Retry-After`. Responses without these headers leave the budget unchanged.
+//
+// Parameters:
+//   - class: The endpoint class of the request.
+//   - res: HTTP response received from the server.
+func (limiter *RateLimiter) AdjustFromResponse(class types.EndpointClass, res *http.Response) {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	bucket, ok := limiter.buckets[class]
+	if !ok {
+		return
+	}
+	now := time.Now()
+	bucket.refill(now)
+	if limit, err := strconv.Atoi(res.Header.Get(constants.HEADER_RATE_LIMIT_LIMIT)); err == nil && limit > 0 {
+		bucket.budget.Burst = limit
+		bucket.tokens = min(bucket.tokens, float64(limit))
+	}
+	if remaining, err := strconv.Atoi(res.Header.Get(constants.HEADER_RATE_LIMIT_REMAINING)); err == nil && remaining >= 0 {
+		bucket.tokens = min(bucket.tokens, float64(remaining))
+		if reset, err := strconv.Atoi(res.Header.Get(constants.HEADER_RATE_LIMIT_RESET)); err == nil && remaining == 0 && reset > 0 {
+			bucket.blockedUntil = now.Add(time.Duration(reset) * time.Second)
+		}
+	}
+	if res.StatusCode == http.StatusTooManyRequests {
+		bucket.tokens = min(bucket.tokens, 0)
+		if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			bucket.blockedUntil = now.Add(time.Duration(seconds) * time.Second)
+		}
+	}
+}
+
+// Usage returns the state of the budget of each endpoint class.
+//
+// Returns:
+//   - A map of Usage by endpoint class.
+func (limiter *RateLimiter) Usage() map[types.EndpointClass]Usage {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	now := time.Now()
+	usages := make(map[types.EndpointClass]Usage, len(limiter.buckets))
+	for class, bucket := range limiter.buckets {
+		bucket.refill(now)
+		usage := bucket.usage
+		usage.Budget = bucket.budget
+		usage.Available = bucket.tokens
+		usages[class] = usage
+	}
+	return usages
+}
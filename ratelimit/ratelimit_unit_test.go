@@ -0,0 +1,234 @@
+//go:build !integration
+// +build !integration
+
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type RateLimitUnitTestSuite struct {
+	suite.Suite
+}
+
+func TestRunSuiteUnit_RateLimitUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(RateLimitUnitTestSuite))
+}
+
+func (s *RateLimitUnitTestSuite) newLimiter(failFast bool, budget Budget) *RateLimiter {
+	limiter, err := NewRateLimiter(&RateLimiterConfiguration{
+		Budgets:  map[types.EndpointClass]Budget{constants.ENDPOINT_CLASS_ORDER_ENTRY: budget},
+		FailFast: failFast,
+	})
+	require.NoError(s.T(), err)
+	return limiter
+}
+
+func (s *RateLimitUnitTestSuite) TestUnit_NewRateLimiter() {
+	limiter, err := NewRateLimiter(&RateLimiterConfiguration{})
+	require.NoError(s.T(), err)
+	usage := limiter.Usage()
+	require.Len(s.T(), usage, 3)
+	require.Equal(s.T(), DEFAULT_BUDGETS[constants.ENDPOINT_CLASS_PUBLIC], usage[constants.ENDPOINT_CLASS_PUBLIC].Budget)
+	require.Equal(s.T(), float64(DEFAULT_BUDGETS[constants.ENDPOINT_CLASS_PUBLIC].Burst), usage[constants.ENDPOINT_CLASS_PUBLIC].Available)
+
+	_, err = NewRateLimiter(&RateLimiterConfiguration{
+		Budgets: map[types.EndpointClass]Budget{constants.ENDPOINT_CLASS_PUBLIC: {Rate: 0, Burst: 1}},
+	})
+	require.Error(s.T(), err)
+}
+
+func (s *RateLimitUnitTestSuite) TestUnit_Wait_Block() {
+	limiter := s.newLimiter(false, Budget{Rate: 50, Burst: 2})
+	ctx := context.Background()
+
+	// The burst is sent at once, then requests wait for the refill.
+	start := time.Now()
+	require.NoError(s.T(), limiter.Wait(ctx, constants.ENDPOINT_CLASS_ORDER_ENTRY))
+	require.NoError(s.T(), limiter.Wait(ctx, constants.ENDPOINT_CLASS_ORDER_ENTRY))
+	require.Less(s.T(), time.Since(start), 15*time.Millisecond)
+	require.NoError(s.T(), limiter.Wait(ctx, constants.ENDPOINT_CLASS_ORDER_ENTRY))
+	require.GreaterOrEqual(s.T(), time.Since(start), 15*time.Millisecond)
+
+	usage := limiter.Usage()[constants.ENDPOINT_CLASS_ORDER_ENTRY]
+	require.Equal(s.T(), int64(3), usage.Requests)
+	require.Equal(s.T(), int64(1), usage.Delayed)
+	require.Zero(s.T(), usage.Rejected)
+	require.Greater(s.T(), usage.Waited, time.Duration(0))
+
+	// Other classes have their own budget.
+	require.Equal(s.T(), int64(0), limiter.Usage()[constants.ENDPOINT_CLASS_PUBLIC].Requests)
+	require.NoError(s.T(), limiter.Wait(ctx, "unknown"))
+}
+
+func (s *RateLimitUnitTestSuite) TestUnit_Wait_FailFast() {
+	limiter := s.newLimiter(true, Budget{Rate: 1, Burst: 1})
+
+	require.NoError(s.T(), limiter.Wait(context.Background(), constants.ENDPOINT_CLASS_ORDER_ENTRY))
+	err := limiter.Wait(context.Background(), constants.ENDPOINT_CLASS_ORDER_ENTRY)
+	require.ErrorIs(s.T(), err, ErrRateLimited)
+
+	usage := limiter.Usage()[constants.ENDPOINT_CLASS_ORDER_ENTRY]
+	require.Equal(s.T(), int64(1), usage.Requests)
+	require.Equal(s.T(), int64(1), usage.Rejected)
+}
+
+func (s *RateLimitUnitTestSuite) TestUnit_Wait_Cancel() {
+	limiter := s.newLimiter(false, Budget{Rate: 1, Burst: 1})
+	require.NoError(s.T(), limiter.Wait(context.Background(), constants.ENDPOINT_CLASS_ORDER_ENTRY))
+
+	// The reservation is handed back.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := limiter.Wait(ctx, constants.ENDPOINT_CLASS_ORDER_ENTRY)
+	require.ErrorIs(s.T(), err, context.DeadlineExceeded)
+
+	usage := limiter.Usage()[constants.ENDPOINT_CLASS_ORDER_ENTRY]
+	require.Equal(s.T(), int64(1), usage.Requests)
+	require.Zero(s.T(), usage.Delayed)
+	require.Equal(s.T(), int64(1), usage.Rejected)
+	require.GreaterOrEqual(s.T(), usage.Available, 0.0)
+}
+
+func (s *RateLimitUnitTestSuite) TestUnit_SetBudget() {
+	limiter := s.newLimiter(true, Budget{Rate: 1, Burst: 10})
+
+	require.NoError(s.T(), limiter.SetBudget(constants.ENDPOINT_CLASS_ORDER_ENTRY, Budget{Rate: 1, Burst: 1}))
+	require.NoError(s.T(), limiter.Wait(context.Background(), constants.ENDPOINT_CLASS_ORDER_ENTRY))
+	require.ErrorIs(s.T(), limiter.Wait(context.Background(), constants.ENDPOINT_CLASS_ORDER_ENTRY), ErrRateLimited)
+
+	require.NoError(s.T(), limiter.SetBudget("custom", Budget{Rate: 1, Burst: 1}))
+	require.Contains(s.T(), limiter.Usage(), types.EndpointClass("custom"))
+	require.Error(s.T(), limiter.SetBudget("custom", Budget{Rate: 1, Burst: 0}))
+}
+
+func (s *RateLimitUnitTestSuite) TestUnit_AdjustFromResponse() {
+	limiter := s.newLimiter(true, Budget{Rate: 1000, Burst: 100})
+
+	// The server limit sets the burst.
+	res := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+	res.Header.Set(constants.HEADER_RATE_LIMIT_LIMIT, "5")
+	res.Header.Set(constants.HEADER_RATE_LIMIT_REMAINING, "3")
+	limiter.AdjustFromResponse(constants.ENDPOINT_CLASS_ORDER_ENTRY, res)
+	usage := limiter.Usage()[constants.ENDPOINT_CLASS_ORDER_ENTRY]
+	require.Equal(s.T(), 5, usage.Budget.Burst)
+	require.LessOrEqual(s.T(), usage.Available, 5.0)
+
+	// An exhausted limit blocks until it resets.
+	res.Header.Set(constants.HEADER_RATE_LIMIT_REMAINING, "0")
+	res.Header.Set(constants.HEADER_RATE_LIMIT_RESET, "60")
+	limiter.AdjustFromResponse(constants.ENDPOINT_CLASS_ORDER_ENTRY, res)
+	require.ErrorIs(s.T(), limiter.Wait(context.Background(), constants.ENDPOINT_CLASS_ORDER_ENTRY), ErrRateLimited)
+
+	// So does a rate limited response.
+	res = &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"60"}}}
+	limiter.AdjustFromResponse(constants.ENDPOINT_CLASS_PUBLIC, res)
+	require.ErrorIs(s.T(), limiter.Wait(context.Background(), constants.ENDPOINT_CLASS_PUBLIC), ErrRateLimited)
+}
+
+func (s *RateLimitUnitTestSuite) TestUnit_ClassifyHTTPRequest() {
+	classify := func(method string, path string) types.EndpointClass {
+		return ClassifyHTTPRequest(&http.Request{Method: method, URL: &url.URL{Path: path}})
+	}
+	require.Equal(s.T(), constants.ENDPOINT_CLASS_PUBLIC, classify(http.MethodGet, "/v1"+string(constants.API_ENDPOINT_LIST_PRODUCTS)))
+	require.Equal(s.T(), constants.ENDPOINT_CLASS_PUBLIC, classify(http.MethodGet, "/v1"+string(constants.API_ENDPOINT_GET_PRODUCT)+"ETH-PERP"))
+	require.Equal(s.T(), constants.ENDPOINT_CLASS_PUBLIC, classify(http.MethodGet, "/v1"+string(constants.API_ENDPOINT_ORDER_BOOK)))
+	require.Equal(s.T(), constants.ENDPOINT_CLASS_PRIVATE_READ, classify(http.MethodGet, "/v1"+string(constants.API_ENDPOINT_GET_SPOT_BALANCES)))
+	require.Equal(s.T(), constants.ENDPOINT_CLASS_PRIVATE_READ, classify(http.MethodGet, "/v1"+string(constants.API_ENDPOINT_LIST_OPEN_ORDERS)))
+	require.Equal(s.T(), constants.ENDPOINT_CLASS_ORDER_ENTRY, classify(http.MethodPost, "/v1"+string(constants.API_ENDPOINT_NEW_ORDER)))
+	require.Equal(s.T(), constants.ENDPOINT_CLASS_ORDER_ENTRY, classify(http.MethodDelete, "/v1"+string(constants.API_ENDPOINT_CANCEL_ALL_OPEN_ORDERS)))
+}
+
+func (s *RateLimitUnitTestSuite) TestUnit_ClassifyWSMethod() {
+	require.Equal(s.T(), constants.ENDPOINT_CLASS_PUBLIC, ClassifyWSMethod(constants.WS_METHOD_ORDER_BOOK_DEPTH))
+	require.Equal(s.T(), constants.ENDPOINT_CLASS_PRIVATE_READ, ClassifyWSMethod(constants.WS_METHOD_ORDER_LIST))
+	require.Equal(s.T(), constants.ENDPOINT_CLASS_ORDER_ENTRY, ClassifyWSMethod(constants.WS_METHOD_NEW_ORDER))
+	require.Equal(s.T(), constants.ENDPOINT_CLASS_PRIVATE_READ, ClassifyWSMethod("unknown"))
+}
+
+func (s *RateLimitUnitTestSuite) TestUnit_HTTPClient() {
+	remaining := "1"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(constants.HEADER_RATE_LIMIT_REMAINING, remaining)
+		w.Header().Set(constants.HEADER_RATE_LIMIT_RESET, "60")
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+	limiter := s.newLimiter(true, Budget{Rate: 1000, Burst: 100})
+	client := NewHTTPClient(utils.GetHTTPClient(time.Second), limiter)
+
+	// Requests draw from the budget of their class.
+	request, err := utils.CreateHTTPRequestWithBody(http.MethodPost, server.URL+string(constants.API_ENDPOINT_NEW_ORDER), map[string]string{})
+	require.NoError(s.T(), err)
+	res, err := utils.SendHTTPRequest(client, request)
+	require.NoError(s.T(), err)
+	res.Body.Close()
+	require.Equal(s.T(), int64(1), limiter.Usage()[constants.ENDPOINT_CLASS_ORDER_ENTRY].Requests)
+
+	// Until the server reports the limit exhausted.
+	remaining = "0"
+	request, err = utils.CreateHTTPRequestWithBody(http.MethodPost, server.URL+string(constants.API_ENDPOINT_NEW_ORDER), map[string]string{})
+	require.NoError(s.T(), err)
+	res, err = utils.SendHTTPRequest(client, request)
+	require.NoError(s.T(), err)
+	res.Body.Close()
+	_, err = utils.SendHTTPRequest(client, request)
+	require.ErrorIs(s.T(), err, ErrRateLimited)
+
+	// Public requests are unaffected.
+	request, err = http.NewRequest(http.MethodGet, server.URL+string(constants.API_ENDPOINT_SERVER_TIME), nil)
+	require.NoError(s.T(), err)
+	res, err = utils.SendHTTPRequest(client, request)
+	require.NoError(s.T(), err)
+	res.Body.Close()
+}
+
+func (s *RateLimitUnitTestSuite) TestUnit_HTTPClient_Retry() {
+	sent := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		w.Header().Set(constants.HEADER_RATE_LIMIT_REMAINING, "0")
+		w.Header().Set(constants.HEADER_RATE_LIMIT_RESET, "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	limiter := s.newLimiter(true, Budget{Rate: 1000, Burst: 100})
+	retries := 0
+	client := utils.NewRetryHTTPClient(NewHTTPClient(utils.GetHTTPClient(time.Second), limiter), &utils.RetryConfiguration{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		OnRetry: func(*http.Request, int, error, time.Duration) {
+			retries++
+		},
+	})
+
+	// The limiter rejects the retry of a signed request, its outcome is unknown after the first attempt.
+	request, err := utils.CreateHTTPRequestWithBody(http.MethodPost, server.URL+string(constants.API_ENDPOINT_NEW_ORDER), map[string]interface{}{"nonce": 1, "signature": "0x01"})
+	require.NoError(s.T(), err)
+	_, err = client.Do(request)
+	require.ErrorIs(s.T(), err, utils.ErrOutcomeUnknown)
+	require.ErrorIs(s.T(), err, ErrRateLimited)
+	require.Equal(s.T(), 1, sent)
+	require.Equal(s.T(), 1, retries)
+
+	// A request rejected before being sent returns at once, without retrying.
+	request, err = utils.CreateHTTPRequestWithBody(http.MethodPost, server.URL+string(constants.API_ENDPOINT_NEW_ORDER), map[string]interface{}{"nonce": 2, "signature": "0x01"})
+	require.NoError(s.T(), err)
+	_, err = client.Do(request)
+	var rateLimitError *utils.RateLimitError
+	require.ErrorAs(s.T(), err, &rateLimitError)
+	require.NotErrorIs(s.T(), err, utils.ErrOutcomeUnknown)
+	require.Equal(s.T(), 1, sent)
+	require.Equal(s.T(), 1, retries)
+}
@@ -0,0 +1,451 @@
+package rebalancer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/sub_accounts"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	geth_types "github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	DEFAULT_MAX_RETRIES        int           = 3
+	DEFAULT_RETRY_DELAY        time.Duration = 5 * time.Second
+	DEFAULT_POLL_INTERVAL      time.Duration = 5 * time.Second
+	DEFAULT_WITHDRAWAL_TIMEOUT time.Duration = 10 * time.Minute
+)
+
+type TransferStatus string
+type TransferMethod string
+
+const (
+	TRANSFER_STATUS_PENDING   TransferStatus = "pending"
+	TRANSFER_STATUS_WITHDRAWN TransferStatus = "withdrawn"
+	TRANSFER_STATUS_DEPOSITED TransferStatus = "deposited"
+	TRANSFER_STATUS_COMPLETED TransferStatus = "completed"
+	TRANSFER_STATUS_FAILED    TransferStatus = "failed"
+
+	TRANSFER_METHOD_INTERNAL         TransferMethod = "internal"
+	TRANSFER_METHOD_WITHDRAW_DEPOSIT TransferMethod = "withdraw_deposit"
+)
+
+// ErrTransferUnsupported is returned by an ITransferer when it cannot move collateral internally,
+// the rebalancer then falls back to withdraw and deposit.
+var ErrTransferUnsupported = errors.New("internal transfer unsupported")
+
+// ITransferer moves collateral between two sub-accounts without going through the chain.
+type ITransferer interface {
+	Transfer(ctx context.Context, from *sub_accounts.SubAccount, to *sub_accounts.SubAccount, quantity *big.Int) error
+}
+
+// RebalancerConfiguration holds the configuration for the rebalancer.
+type RebalancerConfiguration struct {
+	Manager           *sub_accounts.SubAccountManager // Sub-account manager providing the sub-account handles.
+	Transferer        ITransferer                     // Optional internal transfer primitive, withdraw and deposit is used when nil or unsupported.
+	MinTransfer       *big.Int                        // Moves below this quantity in wei (e18) are not planned. Defaults to zero.
+	MaxRetries        int                             // Number of retries of a failed transfer. Defaults to `DEFAULT_MAX_RETRIES`.
+	RetryDelay        time.Duration                   // Delay between retries. Defaults to `DEFAULT_RETRY_DELAY`.
+	PollInterval      time.Duration                   // Interval between wallet balance polls while waiting for a withdrawal. Defaults to `DEFAULT_POLL_INTERVAL`.
+	WithdrawalTimeout time.Duration                   // Maximum time to wait for a withdrawal to reach the wallet. Defaults to `DEFAULT_WITHDRAWAL_TIMEOUT`.
+	OnProgress        func(transfer *Transfer)        // Optional callback invoked whenever a transfer changes.
+}
+
+// Transfer is a planned move of collateral between two sub-accounts.
+type Transfer struct {
+	From          uint8          `json:"from"`                    // ID of the sub-account sending collateral.
+	To            uint8          `json:"to"`                      // ID of the sub-account receiving collateral.
+	Quantity      *big.Int       `json:"quantity"`                // Quantity in wei (e18).
+	Status        TransferStatus `json:"status"`                  // Progress of the transfer.
+	Method        TransferMethod `json:"method,omitempty"`        // Method used to move the collateral, set once started.
+	Attempts      int            `json:"attempts"`                // Number of attempts made.
+	Error         string         `json:"error,omitempty"`         // Last error encountered.
+	WithdrawNonce int64          `json:"withdrawNonce,omitempty"` // Nonce of the withdrawal, reused on retry so the exchange never executes it twice.
+	WalletBalance *big.Int       `json:"walletBalance,omitempty"` // Wallet token balance before the withdrawal, used to detect its arrival.
+	DepositHash   *common.Hash   `json:"depositHash,omitempty"`   // Hash of the deposit transaction, awaited on retry instead of depositing again.
+}
+
+// Plan is the set of transfers bringing sub-accounts to their target collateral.
+type Plan struct {
+	Balances  map[uint8]*big.Int `json:"balances"`  // Collateral per sub-account in wei (e18) when planned.
+	Targets   map[uint8]*big.Int `json:"targets"`   // Target collateral per sub-account in wei (e18).
+	Transfers []*Transfer        `json:"transfers"` // Transfers to execute in order.
+}
+
+// Rebalancer moves USDC collateral between sub-accounts of one account.
+type Rebalancer struct {
+	manager           *sub_accounts.SubAccountManager
+	transferer        ITransferer
+	minTransfer       *big.Int
+	maxRetries        int
+	retryDelay        time.Duration
+	pollInterval      time.Duration
+	withdrawalTimeout time.Duration
+	onProgress        func(transfer *Transfer)
+}
+
+// NewRebalancer creates a new Rebalancer instance.
+//
+// Parameters:
+//   - config: A pointer to RebalancerConfiguration containing the configuration settings.
+//
+// Returns:
+//   - A pointer to Rebalancer.
+//   - An error if no sub-account manager is provided.
+func NewRebalancer(config *RebalancerConfiguration) (*Rebalancer, error) {
+	if config.Manager == nil {
+		return nil, fmt.Errorf("rebalancer requires a sub-account manager")
+	}
+
+	rebalancer := &Rebalancer{
+		manager:           config.Manager,
+		transferer:        config.Transferer,
+		minTransfer:       config.MinTransfer,
+		maxRetries:        config.MaxRetries,
+		retryDelay:        config.RetryDelay,
+		pollInterval:      config.PollInterval,
+		withdrawalTimeout: config.WithdrawalTimeout,
+		onProgress:        config.OnProgress,
+	}
+	if rebalancer.minTransfer == nil {
+		rebalancer.minTransfer = new(big.Int)
+	}
+	if rebalancer.maxRetries == 0 {
+		rebalancer.maxRetries = DEFAULT_MAX_RETRIES
+	}
+	if rebalancer.retryDelay == 0 {
+		rebalancer.retryDelay = DEFAULT_RETRY_DELAY
+	}
+	if rebalancer.pollInterval == 0 {
+		rebalancer.pollInterval = DEFAULT_POLL_INTERVAL
+	}
+	if rebalancer.withdrawalTimeout == 0 {
+		rebalancer.withdrawalTimeout = DEFAULT_WITHDRAWAL_TIMEOUT
+	}
+	return rebalancer, nil
+}
+
+// Plan computes the transfers bringing each sub-account to its target collateral without executing them.
+// Surpluses are matched against deficits in sub-account ID order.
+//
+// Parameters:
+//   - ctx: Context of the balance requests, planning stops once it is done.
+//   - targets: Target USDC collateral in wei (e18) by sub-account ID.
+//
+// Returns:
+//   - A pointer to the Plan.
+//   - An error if balances cannot be retrieved or if the targets exceed the available collateral.
+func (rebalancer *Rebalancer) Plan(ctx context.Context, targets map[uint8]*big.Int) (*Plan, error) {
+	plan := &Plan{
+		Balances: make(map[uint8]*big.Int, len(targets)),
+		Targets:  targets,
+	}
+
+	// Get current collateral.
+	ids := make([]uint8, 0, len(targets))
+	total := new(big.Int)
+	totalTarget := new(big.Int)
+	for id, target := range targets {
+		subAccount := rebalancer.manager.SubAccount(id)
+		balance, err := collateral(ctx, subAccount)
+		if err != nil {
+			return nil, fmt.Errorf("sub-account %d: %w", id, err)
+		}
+		plan.Balances[id] = balance
+		total.Add(total, balance)
+		totalTarget.Add(totalTarget, target)
+		ids = append(ids, id)
+	}
+	if totalTarget.Cmp(total) > 0 {
+		return nil, fmt.Errorf("targets %s exceed available collateral %s", totalTarget, total)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	// Split sub-accounts into surpluses and deficits.
+	type delta struct {
+		id       uint8
+		quantity *big.Int
+	}
+	var surpluses, deficits []*delta
+	for _, id := range ids {
+		difference := new(big.Int).Sub(plan.Balances[id], targets[id])
+		switch difference.Sign() {
+		case 1:
+			surpluses = append(surpluses, &delta{id, difference})
+		case -1:
+			deficits = append(deficits, &delta{id, difference.Neg(difference)})
+		}
+	}
+
+	// Match surpluses against deficits.
+	for len(surpluses) > 0 && len(deficits) > 0 {
+		surplus, deficit := surpluses[0], deficits[0]
+		quantity := new(big.Int).Set(surplus.quantity)
+		if deficit.quantity.Cmp(quantity) < 0 {
+			quantity.Set(deficit.quantity)
+		}
+		if quantity.Cmp(rebalancer.minTransfer) >= 0 {
+			plan.Transfers = append(plan.Transfers, &Transfer{
+				From:     surplus.id,
+				To:       deficit.id,
+				Quantity: quantity,
+				Status:   TRANSFER_STATUS_PENDING,
+			})
+		}
+		surplus.quantity.Sub(surplus.quantity, quantity)
+		deficit.quantity.Sub(deficit.quantity, quantity)
+		if surplus.quantity.Sign() == 0 {
+			surpluses = surpluses[1:]
+		}
+		if deficit.quantity.Sign() == 0 {
+			deficits = deficits[1:]
+		}
+	}
+	return plan, nil
+}
+
+// Execute performs the transfers of a plan in order, retrying failed transfers.
+// Completed transfers are skipped and failed transfers retried from where they stopped, so a plan can be executed
+// again to resume after an error.
+//
+// Parameters:
+//   - ctx: The context for the transfers.
+//   - plan: The plan returned by `Plan`.
+//
+// Returns:
+//   - An error if a transfer still fails after all retries, the plan then holds the progress made.
+func (rebalancer *Rebalancer) Execute(ctx context.Context, plan *Plan) error {
+	for _, transfer := range plan.Transfers {
+		if transfer.Status == TRANSFER_STATUS_COMPLETED {
+			continue
+		}
+		// Transfers fail before withdrawing, a kept withdrawal nonce replays the same withdrawal.
+		if transfer.Status == TRANSFER_STATUS_FAILED {
+			transfer.Status = TRANSFER_STATUS_PENDING
+		}
+		if err := rebalancer.executeWithRetries(ctx, transfer); err != nil {
+			return fmt.Errorf("transfer from sub-account %d to %d: %w", transfer.From, transfer.To, err)
+		}
+	}
+	return nil
+}
+
+// executeWithRetries performs a transfer, retrying it until it completes or retries run out.
+func (rebalancer *Rebalancer) executeWithRetries(ctx context.Context, transfer *Transfer) error {
+	var err error
+	for attempt := 0; attempt <= rebalancer.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(rebalancer.retryDelay):
+			}
+		}
+
+		transfer.Attempts++
+		err = rebalancer.execute(ctx, transfer)
+		if err == nil {
+			transfer.Status = TRANSFER_STATUS_COMPLETED
+			transfer.Error = ""
+			rebalancer.progress(transfer)
+			return nil
+		}
+		transfer.Error = err.Error()
+		rebalancer.progress(transfer)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if transfer.Status == TRANSFER_STATUS_PENDING {
+		transfer.Status = TRANSFER_STATUS_FAILED
+		rebalancer.progress(transfer)
+	}
+	return err
+}
+
+// execute performs a single transfer attempt, resuming a withdrawal or deposit already made.
+func (rebalancer *Rebalancer) execute(ctx context.Context, transfer *Transfer) error {
+	from := rebalancer.manager.SubAccount(transfer.From)
+	to := rebalancer.manager.SubAccount(transfer.To)
+
+	// Prefer the internal transfer primitive.
+	if transfer.Status == TRANSFER_STATUS_PENDING && transfer.Method != TRANSFER_METHOD_WITHDRAW_DEPOSIT && rebalancer.transferer != nil {
+		transfer.Method = TRANSFER_METHOD_INTERNAL
+		err := rebalancer.transferer.Transfer(ctx, from, to, transfer.Quantity)
+		if !errors.Is(err, ErrTransferUnsupported) {
+			return err
+		}
+	}
+	transfer.Method = TRANSFER_METHOD_WITHDRAW_DEPOSIT
+
+	apiClient := from.APIClient
+	token := apiClient.USDCAddress()
+	decimals, err := utils.GetDecimals(ctx, apiClient.EthClient, token)
+	if err != nil {
+		return fmt.Errorf("failed to get USDC decimals: %v", err)
+	}
+	amount := toTokenUnits(transfer.Quantity, decimals)
+
+	// Withdraw from the source sub-account.
+	if transfer.Status == TRANSFER_STATUS_PENDING {
+		// Fix the nonce and wallet balance once, a retry then replays the same withdrawal.
+		if transfer.WithdrawNonce == 0 {
+			walletBalance, err := utils.GetBalanceOf(ctx, apiClient.EthClient, token, apiClient.Address())
+			if err != nil {
+				return fmt.Errorf("failed to get wallet balance: %v", err)
+			}
+			transfer.WalletBalance = walletBalance
+			transfer.WithdrawNonce = time.Now().UnixMilli()
+		}
+		if err := rebalancer.withdraw(ctx, from, transfer); err != nil {
+			return err
+		}
+		transfer.Status = TRANSFER_STATUS_WITHDRAWN
+		rebalancer.progress(transfer)
+	}
+
+	// Wait for the withdrawal to reach the wallet, then deposit into the destination sub-account.
+	if transfer.Status == TRANSFER_STATUS_WITHDRAWN {
+		if err := rebalancer.waitWalletBalance(ctx, from, new(big.Int).Add(transfer.WalletBalance, amount)); err != nil {
+			return err
+		}
+		result, err := to.APIClient.Deposit(ctx, amount)
+		if err != nil && result != nil && result.DepositTransaction != nil && result.DepositReceipt == nil {
+			// Remember a deposit sent but not awaited, so a retry waits for it instead of depositing twice.
+			hash := result.DepositTransaction.Hash()
+			transfer.DepositHash = &hash
+			transfer.Status = TRANSFER_STATUS_DEPOSITED
+			rebalancer.progress(transfer)
+		}
+		return err
+	}
+
+	// Wait for a deposit sent by a previous attempt.
+	return rebalancer.waitDeposit(ctx, to, transfer)
+}
+
+// withdraw sends the withdrawal of a transfer, treating a rejection of its reused nonce as already withdrawn.
+func (rebalancer *Rebalancer) withdraw(ctx context.Context, subAccount *sub_accounts.SubAccount, transfer *Transfer) error {
+	res, err := subAccount.APIClient.WithdrawCtx(ctx, &types.WithdrawRequest{
+		Quantity: transfer.Quantity.String(),
+		Nonce:    transfer.WithdrawNonce,
+	})
+	if err == nil {
+		var result interface{}
+		err = utils.DecodeHTTPResponse(res, &result)
+	}
+	if err != nil && !(transfer.Attempts > 1 && nonceUsed(err)) {
+		return fmt.Errorf("failed to withdraw: %w", err)
+	}
+	return nil
+}
+
+// waitDeposit waits for the receipt of a deposit sent by a previous attempt.
+// A reverted deposit leaves the withdrawal in the wallet, so the transfer goes back to depositing it.
+func (rebalancer *Rebalancer) waitDeposit(ctx context.Context, subAccount *sub_accounts.SubAccount, transfer *Transfer) error {
+	if transfer.DepositHash == nil {
+		return fmt.Errorf("transfer %s without deposit to wait for", transfer.Status)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, rebalancer.withdrawalTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(rebalancer.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		receipt, err := subAccount.APIClient.EthClient.TransactionReceipt(ctx, *transfer.DepositHash)
+		switch {
+		case err == nil && receipt.Status == geth_types.ReceiptStatusSuccessful:
+			return nil
+		case err == nil:
+			transfer.DepositHash = nil
+			transfer.Status = TRANSFER_STATUS_WITHDRAWN
+			rebalancer.progress(transfer)
+			return fmt.Errorf("deposit %s reverted", receipt.TxHash.Hex())
+		case !errors.Is(err, ethereum.NotFound):
+			return fmt.Errorf("failed to get deposit receipt: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("deposit not mined: %v", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitWalletBalance polls the wallet token balance until it reaches `expected`.
+func (rebalancer *Rebalancer) waitWalletBalance(ctx context.Context, subAccount *sub_accounts.SubAccount, expected *big.Int) error {
+	ctx, cancel := context.WithTimeout(ctx, rebalancer.withdrawalTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(rebalancer.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		balance, err := utils.GetBalanceOf(ctx, subAccount.APIClient.EthClient, subAccount.APIClient.USDCAddress(), subAccount.APIClient.Address())
+		if err == nil && balance.Cmp(expected) >= 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("withdrawal not received: %v", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// progress reports a transfer change to the configured callback.
+func (rebalancer *Rebalancer) progress(transfer *Transfer) {
+	if rebalancer.onProgress != nil {
+		rebalancer.onProgress(transfer)
+	}
+}
+
+// nonceUsed reports whether the exchange rejected a request because its nonce was already used.
+func nonceUsed(err error) bool {
+	var apiError *utils.APIError
+	if !errors.As(err, &apiError) {
+		return false
+	}
+	message := strings.ToLower(apiError.Message)
+	return strings.Contains(message, "nonce") && strings.Contains(message, "used")
+}
+
+// collateral returns the USDC spot balance of a sub-account in wei (e18).
+func collateral(ctx context.Context, subAccount *sub_accounts.SubAccount) (*big.Int, error) {
+	balances, err := subAccount.SpotBalancesCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	usdc := subAccount.APIClient.USDCAddress().Hex()
+	for _, balance := range balances {
+		if strings.EqualFold(balance.Asset, usdc) {
+			quantity, ok := new(big.Int).SetString(balance.Quantity, 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid quantity %q", balance.Quantity)
+			}
+			return quantity, nil
+		}
+	}
+	return new(big.Int), nil
+}
+
+// toTokenUnits converts a quantity in wei (e18) to token units, rounding down.
+func toTokenUnits(quantity *big.Int, decimals uint8) *big.Int {
+	amount := new(big.Int).Mul(quantity, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	return amount.Quo(amount, constants.E18)
+}
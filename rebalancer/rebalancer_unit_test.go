@@ -0,0 +1,353 @@
+//go:build !integration
+// +build !integration
+
+package rebalancer
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	geth_types "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rysk-finance/v2_client_go/api_client"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/sub_accounts"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type RebalancerUnitTestSuite struct {
+	suite.Suite
+	APIClient *api_client.RyskV2APIClient
+	Server    *httptest.Server
+
+	mutex            sync.Mutex
+	balances         map[string]string
+	withdraws        []string
+	withdrawNonces   []int64
+	withdrawFailures int
+}
+
+// redirectTransport sends every request to the test server.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (transport *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = transport.target.Scheme
+	req.URL.Host = transport.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// mockTransferer is an ITransferer returning queued errors.
+type mockTransferer struct {
+	errs  []error
+	calls int
+}
+
+func (transferer *mockTransferer) Transfer(ctx context.Context, from *sub_accounts.SubAccount, to *sub_accounts.SubAccount, quantity *big.Int) error {
+	transferer.calls++
+	if len(transferer.errs) == 0 {
+		return nil
+	}
+	err := transferer.errs[0]
+	transferer.errs = transferer.errs[1:]
+	return err
+}
+
+func (s *RebalancerUnitTestSuite) SetupSuite() {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(s.T(), err)
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		switch {
+		case strings.HasSuffix(req.URL.Path, string(constants.API_ENDPOINT_GET_SPOT_BALANCES)):
+			json.NewEncoder(w).Encode([]types.SpotBalance{{
+				Asset:    strings.ToLower(constants.USDC_ADDRESS[constants.ENVIRONMENT_TESTNET]),
+				Quantity: s.balances[req.URL.Query().Get("subAccountId")],
+			}})
+		case strings.HasSuffix(req.URL.Path, string(constants.API_ENDPOINT_WITHDRAW)):
+			var body struct {
+				SubAccountId int64  `json:"subAccountId"`
+				Quantity     string `json:"quantity"`
+				Nonce        int64  `json:"nonce"`
+			}
+			json.NewDecoder(req.Body).Decode(&body)
+			s.withdrawNonces = append(s.withdrawNonces, body.Nonce)
+			if len(s.withdrawNonces) > 1 && s.withdrawNonces[0] == body.Nonce {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]interface{}{"code": http.StatusBadRequest, "message": fmt.Sprintf("nonce %d already used", body.Nonce)})
+				return
+			}
+			s.withdraws = append(s.withdraws, body.Quantity)
+			if s.withdrawFailures > 0 {
+				// The withdrawal is executed but its response is lost.
+				s.withdrawFailures--
+				w.WriteHeader(http.StatusGatewayTimeout)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]bool{"success": true})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	target, err := url.Parse(s.Server.URL)
+	require.NoError(s.T(), err)
+
+	s.APIClient, err = api_client.NewRyskV2APIClient(&api_client.RyskV2APIClientConfiguration{
+		Env:        constants.ENVIRONMENT_TESTNET,
+		PrivateKey: hex.EncodeToString(crypto.FromECDSA(privateKey)),
+		RpcUrl:     s.Server.URL,
+	})
+	require.NoError(s.T(), err)
+	s.APIClient.HttpClient = &http.Client{Transport: &redirectTransport{target: target}}
+}
+
+func (s *RebalancerUnitTestSuite) SetupTest() {
+	s.balances = map[string]string{"1": "300", "2": "0", "3": "100"}
+	s.withdraws = nil
+	s.withdrawNonces = nil
+	s.withdrawFailures = 0
+	s.APIClient.EthClient = newWithdrawDepositMockEthClient()
+}
+
+func (s *RebalancerUnitTestSuite) TearDownSuite() {
+	s.Server.Close()
+}
+
+func TestRunSuiteUnit_RebalancerUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(RebalancerUnitTestSuite))
+}
+
+// newWithdrawDepositMockEthClient mocks a 6 decimals USDC whose wallet balance rises after the first read.
+func newWithdrawDepositMockEthClient() *mocks.MockEthClient {
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.OnCallContractMethod(constants.ERC20_ABI, "decimals", uint8(6))
+	mockEthClient.OnCallContractMethod(constants.ERC20_ABI, "balanceOf", big.NewInt(0)).Once()
+	mockEthClient.OnCallContractMethod(constants.ERC20_ABI, "balanceOf", big.NewInt(1000000000))
+	mockEthClient.OnCallContractMethod(constants.CIAO_ABI, "minDepositAmount", big.NewInt(1))
+	mockEthClient.OnCallContractMethod(constants.ERC20_ABI, "allowance", big.NewInt(1000000000))
+	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
+	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
+	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
+	mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+	mockEthClient.On("TransactionReceipt", mock.Anything, mock.Anything).Return(&geth_types.Receipt{Status: geth_types.ReceiptStatusSuccessful}, nil)
+	return mockEthClient
+}
+
+func (s *RebalancerUnitTestSuite) newRebalancer(transferer ITransferer) *Rebalancer {
+	manager, err := sub_accounts.NewSubAccountManager(&sub_accounts.SubAccountManagerConfiguration{APIClient: s.APIClient})
+	require.NoError(s.T(), err)
+	rebalancer, err := NewRebalancer(&RebalancerConfiguration{
+		Manager:      manager,
+		Transferer:   transferer,
+		MaxRetries:   2,
+		RetryDelay:   time.Millisecond,
+		PollInterval: time.Millisecond,
+	})
+	require.NoError(s.T(), err)
+	return rebalancer
+}
+
+func (s *RebalancerUnitTestSuite) TestUnit_NewRebalancer_NoManager() {
+	rebalancer, err := NewRebalancer(&RebalancerConfiguration{})
+	require.Error(s.T(), err)
+	require.Nil(s.T(), rebalancer)
+}
+
+func (s *RebalancerUnitTestSuite) TestUnit_Plan() {
+	plan, err := s.newRebalancer(nil).Plan(context.Background(), map[uint8]*big.Int{
+		1: big.NewInt(100),
+		2: big.NewInt(150),
+		3: big.NewInt(150),
+	})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), big.NewInt(300), plan.Balances[1])
+	require.Len(s.T(), plan.Transfers, 2)
+	require.Equal(s.T(), &Transfer{From: 1, To: 2, Quantity: big.NewInt(150), Status: TRANSFER_STATUS_PENDING}, plan.Transfers[0])
+	require.Equal(s.T(), &Transfer{From: 1, To: 3, Quantity: big.NewInt(50), Status: TRANSFER_STATUS_PENDING}, plan.Transfers[1])
+	require.Empty(s.T(), s.withdraws)
+}
+
+func (s *RebalancerUnitTestSuite) TestUnit_Plan_MinTransfer() {
+	rebalancer := s.newRebalancer(nil)
+	rebalancer.minTransfer = big.NewInt(100)
+
+	plan, err := rebalancer.Plan(context.Background(), map[uint8]*big.Int{
+		1: big.NewInt(100),
+		2: big.NewInt(150),
+		3: big.NewInt(150),
+	})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), plan.Transfers, 1)
+	require.Equal(s.T(), uint8(2), plan.Transfers[0].To)
+}
+
+func (s *RebalancerUnitTestSuite) TestUnit_Plan_TargetsExceedCollateral() {
+	plan, err := s.newRebalancer(nil).Plan(context.Background(), map[uint8]*big.Int{
+		1: big.NewInt(300),
+		2: big.NewInt(300),
+	})
+	require.Error(s.T(), err)
+	require.Nil(s.T(), plan)
+}
+
+func (s *RebalancerUnitTestSuite) TestUnit_Plan_ContextCancelled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	plan, err := s.newRebalancer(nil).Plan(ctx, map[uint8]*big.Int{
+		1: big.NewInt(100),
+	})
+	require.ErrorIs(s.T(), err, context.Canceled)
+	require.Nil(s.T(), plan)
+}
+
+func (s *RebalancerUnitTestSuite) TestUnit_Execute_WithdrawDeposit() {
+	var progress []TransferStatus
+	rebalancer := s.newRebalancer(nil)
+	rebalancer.onProgress = func(transfer *Transfer) { progress = append(progress, transfer.Status) }
+	plan := &Plan{Transfers: []*Transfer{{From: 1, To: 2, Quantity: big.NewInt(2000000000000000000), Status: TRANSFER_STATUS_PENDING}}}
+
+	err := rebalancer.Execute(context.Background(), plan)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []string{"2000000000000000000"}, s.withdraws)
+	require.Equal(s.T(), TRANSFER_STATUS_COMPLETED, plan.Transfers[0].Status)
+	require.Equal(s.T(), TRANSFER_METHOD_WITHDRAW_DEPOSIT, plan.Transfers[0].Method)
+	require.Equal(s.T(), []TransferStatus{TRANSFER_STATUS_WITHDRAWN, TRANSFER_STATUS_COMPLETED}, progress)
+
+	// The deposit of 2 USDC (6 decimals) goes to sub-account 2.
+	mockEthClient := s.APIClient.EthClient.(*mocks.MockEthClient)
+	mockEthClient.AssertNumberOfCalls(s.T(), "SendTransaction", 1)
+	sentTransaction := mockEthClient.Calls[len(mockEthClient.Calls)-2].Arguments.Get(1).(*geth_types.Transaction)
+	require.Equal(s.T(), big.NewInt(2), new(big.Int).SetBytes(sentTransaction.Data()[4+32:4+64]))
+	require.Equal(s.T(), big.NewInt(2000000), new(big.Int).SetBytes(sentTransaction.Data()[4+64:4+96]))
+}
+
+func (s *RebalancerUnitTestSuite) TestUnit_Execute_ResumeWithdrawn() {
+	plan := &Plan{Transfers: []*Transfer{{From: 1, To: 2, Quantity: big.NewInt(1000000000000000000), Status: TRANSFER_STATUS_WITHDRAWN, WalletBalance: big.NewInt(0)}}}
+
+	err := s.newRebalancer(nil).Execute(context.Background(), plan)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), s.withdraws)
+	require.Equal(s.T(), TRANSFER_STATUS_COMPLETED, plan.Transfers[0].Status)
+}
+
+func (s *RebalancerUnitTestSuite) TestUnit_Execute_WithdrawRetryReusesNonce() {
+	s.withdrawFailures = 1
+	plan := &Plan{Transfers: []*Transfer{{From: 1, To: 2, Quantity: big.NewInt(1000000000000000000), Status: TRANSFER_STATUS_PENDING}}}
+
+	err := s.newRebalancer(nil).Execute(context.Background(), plan)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), s.withdrawNonces, 2)
+	require.Equal(s.T(), s.withdrawNonces[0], s.withdrawNonces[1])
+	require.Equal(s.T(), s.withdrawNonces[0], plan.Transfers[0].WithdrawNonce)
+	require.Equal(s.T(), []string{"1000000000000000000"}, s.withdraws)
+	require.Equal(s.T(), 2, plan.Transfers[0].Attempts)
+	require.Equal(s.T(), TRANSFER_STATUS_COMPLETED, plan.Transfers[0].Status)
+}
+
+func (s *RebalancerUnitTestSuite) TestUnit_Execute_ResumeDeposited() {
+	hash := common.HexToHash("0x01")
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.OnCallContractMethod(constants.ERC20_ABI, "decimals", uint8(6))
+	mockEthClient.On("TransactionReceipt", mock.Anything, hash).Return((*geth_types.Receipt)(nil), ethereum.NotFound).Once()
+	mockEthClient.On("TransactionReceipt", mock.Anything, hash).Return(&geth_types.Receipt{TxHash: hash, Status: geth_types.ReceiptStatusSuccessful}, nil)
+	s.APIClient.EthClient = mockEthClient
+	plan := &Plan{Transfers: []*Transfer{{From: 1, To: 2, Quantity: big.NewInt(1000000000000000000), Status: TRANSFER_STATUS_DEPOSITED, Method: TRANSFER_METHOD_WITHDRAW_DEPOSIT, WalletBalance: big.NewInt(0), DepositHash: &hash}}}
+
+	err := s.newRebalancer(nil).Execute(context.Background(), plan)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), s.withdraws)
+	require.Equal(s.T(), TRANSFER_STATUS_COMPLETED, plan.Transfers[0].Status)
+	mockEthClient.AssertNotCalled(s.T(), "SendTransaction", mock.Anything, mock.Anything)
+	mockEthClient.AssertNumberOfCalls(s.T(), "TransactionReceipt", 2)
+}
+
+func (s *RebalancerUnitTestSuite) TestUnit_Execute_SkipsCompleted() {
+	plan := &Plan{Transfers: []*Transfer{{From: 1, To: 2, Quantity: big.NewInt(100), Status: TRANSFER_STATUS_COMPLETED}}}
+
+	err := s.newRebalancer(nil).Execute(context.Background(), plan)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), s.withdraws)
+}
+
+func (s *RebalancerUnitTestSuite) TestUnit_Execute_InternalTransfer() {
+	transferer := &mockTransferer{}
+	plan := &Plan{Transfers: []*Transfer{{From: 1, To: 2, Quantity: big.NewInt(100), Status: TRANSFER_STATUS_PENDING}}}
+
+	err := s.newRebalancer(transferer).Execute(context.Background(), plan)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 1, transferer.calls)
+	require.Empty(s.T(), s.withdraws)
+	require.Equal(s.T(), TRANSFER_METHOD_INTERNAL, plan.Transfers[0].Method)
+}
+
+func (s *RebalancerUnitTestSuite) TestUnit_Execute_InternalTransferUnsupported() {
+	transferer := &mockTransferer{errs: []error{ErrTransferUnsupported}}
+	plan := &Plan{Transfers: []*Transfer{{From: 1, To: 2, Quantity: big.NewInt(1000000000000000000), Status: TRANSFER_STATUS_PENDING}}}
+
+	err := s.newRebalancer(transferer).Execute(context.Background(), plan)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), s.withdraws, 1)
+	require.Equal(s.T(), TRANSFER_METHOD_WITHDRAW_DEPOSIT, plan.Transfers[0].Method)
+}
+
+func (s *RebalancerUnitTestSuite) TestUnit_Execute_Retries() {
+	transferer := &mockTransferer{errs: []error{errors.New("busy"), errors.New("busy")}}
+	plan := &Plan{Transfers: []*Transfer{{From: 1, To: 2, Quantity: big.NewInt(100), Status: TRANSFER_STATUS_PENDING}}}
+
+	err := s.newRebalancer(transferer).Execute(context.Background(), plan)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 3, plan.Transfers[0].Attempts)
+	require.Equal(s.T(), TRANSFER_STATUS_COMPLETED, plan.Transfers[0].Status)
+	require.Empty(s.T(), plan.Transfers[0].Error)
+}
+
+func (s *RebalancerUnitTestSuite) TestUnit_Execute_RetriesExhausted() {
+	transferer := &mockTransferer{errs: []error{errors.New("busy"), errors.New("busy"), errors.New("busy")}}
+	plan := &Plan{Transfers: []*Transfer{
+		{From: 1, To: 2, Quantity: big.NewInt(100), Status: TRANSFER_STATUS_PENDING},
+		{From: 1, To: 3, Quantity: big.NewInt(100), Status: TRANSFER_STATUS_PENDING},
+	}}
+
+	err := s.newRebalancer(transferer).Execute(context.Background(), plan)
+	require.Error(s.T(), err)
+	require.Equal(s.T(), 3, plan.Transfers[0].Attempts)
+	require.Equal(s.T(), TRANSFER_STATUS_FAILED, plan.Transfers[0].Status)
+	require.Equal(s.T(), "busy", plan.Transfers[0].Error)
+	require.Equal(s.T(), TRANSFER_STATUS_PENDING, plan.Transfers[1].Status)
+
+	// Executing the plan again resumes the failed transfer.
+	err = s.newRebalancer(transferer).Execute(context.Background(), plan)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 4, plan.Transfers[0].Attempts)
+	require.Equal(s.T(), TRANSFER_STATUS_COMPLETED, plan.Transfers[0].Status)
+	require.Equal(s.T(), TRANSFER_STATUS_COMPLETED, plan.Transfers[1].Status)
+}
+
+func (s *RebalancerUnitTestSuite) TestUnit_ToTokenUnits() {
+	require.Equal(s.T(), big.NewInt(1500000), toTokenUnits(big.NewInt(1500000000000000000), 6))
+	require.Zero(s.T(), toTokenUnits(big.NewInt(999999999999), 6).Sign())
+	require.Equal(s.T(), big.NewInt(42), toTokenUnits(big.NewInt(42), 18))
+}
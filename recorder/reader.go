@@ -0,0 +1,217 @@
+package recorder
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/rysk-finance/v2_client_go/backtest"
+	"github.com/rysk-finance/v2_client_go/trading"
+)
+
+// ListFiles lists the recording files of a directory in time order.
+//
+// Parameters:
+//   - directory: The directory the files were written to.
+//   - prefix: The file name prefix, `DEFAULT_PREFIX` if empty.
+//
+// Returns:
+//   - The file paths, sorted by name.
+//   - An error if the directory cannot be read.
+func ListFiles(directory string, prefix string) ([]string, error) {
+	if prefix == "" {
+		prefix = DEFAULT_PREFIX
+	}
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %v", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix+"-") {
+			continue
+		}
+		if _, err := compressionOf(name); err == nil {
+			files = append(files, filepath.Join(directory, name))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// compressionOf returns the compression of a file from its extension.
+func compressionOf(path string) (Compression, error) {
+	for _, compression := range []Compression{COMPRESSION_GZIP, COMPRESSION_ZSTD, COMPRESSION_NONE} {
+		if strings.HasSuffix(path, EXTENSIONS[compression]) {
+			return compression, nil
+		}
+	}
+	return "", fmt.Errorf("unknown extension of file %s", path)
+}
+
+// Reader iterates the records of recording files in order.
+type Reader struct {
+	files        []string
+	index        int           // index is the index of the next file to open.
+	file         *os.File      // file is the current file, nil between files.
+	decompressor io.Closer     // decompressor decompresses file, nil without compression.
+	lines        *bufio.Reader // lines reads the decompressed lines of file.
+	line         int           // line is the number of the last line read from file.
+}
+
+// NewReader creates a new Reader over recording files, e.g. as listed by `ListFiles`.
+//
+// Parameters:
+//   - files: The file paths, read in the given order.
+//
+// Returns:
+//   - A pointer to Reader, to be closed with `Close`.
+func NewReader(files ...string) *Reader {
+	return &Reader{files: files}
+}
+
+// Next returns the next record.
+//
+// Returns:
+//   - A pointer to the Record.
+//   - `io.EOF` once every file was read, or an error if a file cannot be read or decoded.
+func (reader *Reader) Next() (*Record, error) {
+	for {
+		// Open the next file.
+		if reader.file == nil {
+			if reader.index >= len(reader.files) {
+				return nil, io.EOF
+			}
+			if err := reader.open(reader.files[reader.index]); err != nil {
+				return nil, err
+			}
+			reader.index++
+		}
+
+		// Read a line, moving to the next file at the end of this one.
+		line, err := reader.lines.ReadBytes('\n')
+		if errors.Is(err, io.EOF) && len(line) == 0 {
+			if err := reader.Close(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read %s: %v", reader.file.Name(), err)
+		}
+		reader.line++
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, fmt.Errorf("failed to decode %s line %d: %v", reader.file.Name(), reader.line, err)
+		}
+		return &record, nil
+	}
+}
+
+// Close closes the current file. Reading resumes with the next file.
+//
+// Returns:
+//   - An error if the file cannot be closed.
+func (reader *Reader) Close() error {
+	if reader.file == nil {
+		return nil
+	}
+	if reader.decompressor != nil {
+		reader.decompressor.Close()
+		reader.decompressor = nil
+	}
+	err := reader.file.Close()
+	reader.file = nil
+	reader.lines = nil
+	if err != nil {
+		return fmt.Errorf("failed to close file: %v", err)
+	}
+	return nil
+}
+
+// open opens a file and its decompressor.
+func (reader *Reader) open(path string) error {
+	compression, err := compressionOf(path)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %v", err)
+	}
+
+	var input io.Reader = file
+	switch compression {
+	case COMPRESSION_GZIP:
+		decompressor, err := gzip.NewReader(file)
+		if err != nil {
+			file.Close()
+			return fmt.Errorf("failed to read %s: %v", path, err)
+		}
+		reader.decompressor = decompressor
+		input = decompressor
+	case COMPRESSION_ZSTD:
+		decoder, err := zstd.NewReader(file)
+		if err != nil {
+			file.Close()
+			return fmt.Errorf("failed to read %s: %v", path, err)
+		}
+		reader.decompressor = decoder.IOReadCloser()
+		input = decoder
+	}
+	reader.file = file
+	reader.lines = bufio.NewReader(input)
+	reader.line = 0
+	return nil
+}
+
+// eventSource replays the trades, depth snapshots and klines of a recording.
+type eventSource struct {
+	reader *Reader
+}
+
+// NewEventSource adapts a Reader to a backtest event source. Trades, depth snapshots and klines
+// become visible at their receive time, other records are skipped. Klines are replayed as streamed,
+// including the updates of unfinished klines.
+//
+// Parameters:
+//   - reader: The recording reader.
+//
+// Returns:
+//   - A backtest.IEventSource.
+func NewEventSource(reader *Reader) backtest.IEventSource {
+	return &eventSource{reader: reader}
+}
+
+func (source *eventSource) Next() (*backtest.Event, error) {
+	for {
+		record, err := source.reader.Next()
+		if err != nil {
+			return nil, err
+		}
+		switch record.Type {
+		case RECORD_TYPE_TRADE:
+			return &backtest.Event{MarketEvent: trading.MarketEvent{Trade: record.Trade}, Time: record.ReceivedAt}, nil
+		case RECORD_TYPE_DEPTH:
+			return &backtest.Event{MarketEvent: trading.MarketEvent{Depth: record.Depth}, Time: record.ReceivedAt}, nil
+		case RECORD_TYPE_KLINE:
+			return &backtest.Event{MarketEvent: trading.MarketEvent{Kline: record.Kline}, Time: record.ReceivedAt}, nil
+		}
+	}
+}
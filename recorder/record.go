@@ -0,0 +1,110 @@
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+type RecordType string
+
+const (
+	RECORD_TYPE_TRADE     RecordType = "trade"    // Public trade from a `@trade` stream.
+	RECORD_TYPE_AGG_TRADE RecordType = "aggTrade" // Aggregate trade from an `@aggTrade` stream.
+	RECORD_TYPE_DEPTH     RecordType = "depth"    // Depth snapshot from a `@depth*` stream.
+	RECORD_TYPE_TICKER    RecordType = "ticker"   // 24hr statistics from a `@ticker` stream.
+	RECORD_TYPE_KLINE     RecordType = "kline"    // Kline from a `@klines_*` stream.
+	RECORD_TYPE_GAP       RecordType = "gap"      // Period without data while the stream was disconnected.
+)
+
+// Record is a normalized market data event, one JSON line in a recording.
+// Exactly one of Trade, Depth, Ticker, Kline and Gap is set, according to Type.
+type Record struct {
+	Type       RecordType            `json:"type"`             // The record type.
+	Stream     string                `json:"stream,omitempty"` // The stream the event was received on, e.g. `ethperp@depth5_0`.
+	Symbol     string                `json:"symbol,omitempty"` // The symbol of the product.
+	ReceivedAt int64                 `json:"receivedAt"`       // UNIX timestamp (in ms) at which the event was received.
+	Trade      *types.Trade          `json:"trade,omitempty"`  // Trade of `trade` and `aggTrade` records.
+	Depth      *types.OrderBookDepth `json:"depth,omitempty"`  // Depth snapshot of `depth` records.
+	Ticker     *types.Ticker         `json:"ticker,omitempty"` // Statistics of `ticker` records.
+	Kline      *types.Kline          `json:"kline,omitempty"`  // Kline of `kline` records.
+	Gap        *Gap                  `json:"gap,omitempty"`    // Gap of `gap` records.
+}
+
+// Gap marks a period during which events may have been missed.
+type Gap struct {
+	From   int64  `json:"from"`   // UNIX timestamp (in ms) of the last message received, or of the subscription, before the disconnection.
+	To     int64  `json:"to"`     // UNIX timestamp (in ms) at which the stream was subscribed again.
+	Reason string `json:"reason"` // The error that dropped the connection.
+}
+
+// DecodeRecord normalizes a raw market data stream message into a Record.
+// Symbols, and kline intervals, missing from the data are taken from the stream name.
+//
+// Parameters:
+//   - body: The JSON message, `{"stream": "<symbol>@<topic>", "data": ...}`.
+//   - receivedAt: UNIX timestamp (in ms) at which the message was received.
+//
+// Returns:
+//   - A pointer to the Record, nil for messages that are not market data, e.g. subscription responses.
+//   - An error if the message data cannot be decoded.
+func DecodeRecord(body []byte, receivedAt int64) (*Record, error) {
+	var message struct {
+		Stream string          `json:"stream"`
+		Data   json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &message); err != nil || message.Stream == "" {
+		return nil, nil
+	}
+	symbol, topic, _ := strings.Cut(message.Stream, "@")
+	record := &Record{Stream: message.Stream, Symbol: symbol, ReceivedAt: receivedAt}
+
+	// Decode data according to the topic.
+	var data interface{}
+	switch {
+	case topic == "trade" || topic == "aggTrade":
+		record.Type = RECORD_TYPE_TRADE
+		if topic == "aggTrade" {
+			record.Type = RECORD_TYPE_AGG_TRADE
+		}
+		record.Trade = &types.Trade{}
+		data = record.Trade
+	case strings.HasPrefix(topic, "depth"):
+		record.Type = RECORD_TYPE_DEPTH
+		record.Depth = &types.OrderBookDepth{}
+		data = record.Depth
+	case topic == "ticker":
+		record.Type = RECORD_TYPE_TICKER
+		record.Ticker = &types.Ticker{}
+		data = record.Ticker
+	case strings.HasPrefix(topic, "klines_"):
+		record.Type = RECORD_TYPE_KLINE
+		record.Kline = &types.Kline{}
+		data = record.Kline
+	default:
+		return nil, nil
+	}
+	if err := json.Unmarshal(message.Data, data); err != nil {
+		return nil, fmt.Errorf("failed to decode %s message: %v", message.Stream, err)
+	}
+
+	// Fill fields from the stream name.
+	switch {
+	case record.Trade != nil && record.Trade.Symbol == "":
+		record.Trade.Symbol = symbol
+	case record.Depth != nil && record.Depth.Symbol == "":
+		record.Depth.Symbol = symbol
+	case record.Ticker != nil && record.Ticker.Symbol == "":
+		record.Ticker.Symbol = symbol
+	case record.Kline != nil:
+		if record.Kline.Symbol == "" {
+			record.Kline.Symbol = symbol
+		}
+		if record.Kline.Interval == "" {
+			record.Kline.Interval = types.Interval(strings.TrimPrefix(topic, "klines_"))
+		}
+	}
+	return record, nil
+}
@@ -0,0 +1,278 @@
+package recorder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/logging"
+	"github.com/rysk-finance/v2_client_go/metrics"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/ws_client"
+)
+
+const (
+	DEFAULT_RECONNECT_DELAY     time.Duration = time.Second
+	DEFAULT_MAX_RECONNECT_DELAY time.Duration = time.Minute
+)
+
+var (
+	DEFAULT_INTERVALS           = []types.Interval{constants.INTERVAL_1M}
+	DEFAULT_DEPTH_LIMITS        = []types.Limit{constants.LIMIT_FIVE}
+	DEFAULT_DEPTH_GRANULARITIES = []int64{0}
+)
+
+// RecorderConfiguration holds the configuration for the market data recorder.
+type RecorderConfiguration struct {
+	Client             *ws_client.RyskV2WSClientConfiguration // Websocket client settings, a new client is created on every (re)connection.
+	Products           []*types.Product                       // Products to record.
+	Intervals          []types.Interval                       // Kline intervals to record. Defaults to `DEFAULT_INTERVALS`.
+	DepthLimits        []types.Limit                          // Depth limits to record. Defaults to `DEFAULT_DEPTH_LIMITS`.
+	DepthGranularities []int64                                // Depth price granularities to record. Defaults to `DEFAULT_DEPTH_GRANULARITIES`.
+	Writer             *WriterConfiguration                   // Output files settings.
+	ReconnectDelay     time.Duration                          // Delay before the first reconnection attempt, doubled after each failure. Defaults to `DEFAULT_RECONNECT_DELAY`.
+	MaxReconnectDelay  time.Duration                          // Maximum delay between reconnection attempts. Defaults to `DEFAULT_MAX_RECONNECT_DELAY`.
+	OnRecord           func(record *Record)                   // Optional callback invoked with every record written.
+	OnError            func(err error)                        // Optional callback invoked when the connection drops or a reconnection attempt fails.
+}
+
+// Recorder subscribes to the market data streams of products and writes every event to a recording.
+type Recorder struct {
+	client             *ws_client.RyskV2WSClientConfiguration
+	products           []*types.Product
+	intervals          []types.Interval
+	depthLimits        []types.Limit
+	depthGranularities []int64
+	writer             *Writer
+	reconnectDelay     time.Duration
+	maxReconnectDelay  time.Duration
+	onRecord           func(record *Record)
+	onError            func(err error)
+	logger             *slog.Logger
+}
+
+// NewRecorder creates a new Recorder instance.
+//
+// Parameters:
+//   - config: A pointer to RecorderConfiguration containing the configuration settings.
+//
+// Returns:
+//   - A pointer to Recorder.
+//   - An error if the client, products or writer settings are missing or invalid.
+func NewRecorder(config *RecorderConfiguration) (*Recorder, error) {
+	if config.Client == nil {
+		return nil, fmt.Errorf("recorder requires websocket client settings")
+	}
+	if len(config.Products) == 0 {
+		return nil, fmt.Errorf("recorder requires at least one product")
+	}
+	if config.Writer == nil {
+		return nil, fmt.Errorf("recorder requires writer settings")
+	}
+	writer, err := NewWriter(config.Writer)
+	if err != nil {
+		return nil, err
+	}
+
+	recorder := &Recorder{
+		client:             config.Client,
+		products:           config.Products,
+		intervals:          config.Intervals,
+		depthLimits:        config.DepthLimits,
+		depthGranularities: config.DepthGranularities,
+		writer:             writer,
+		reconnectDelay:     config.ReconnectDelay,
+		maxReconnectDelay:  config.MaxReconnectDelay,
+		onRecord:           config.OnRecord,
+		onError:            config.OnError,
+		logger:             logging.OrDiscard(config.Client.Logger),
+	}
+	if len(recorder.intervals) == 0 {
+		recorder.intervals = DEFAULT_INTERVALS
+	}
+	if len(recorder.depthLimits) == 0 {
+		recorder.depthLimits = DEFAULT_DEPTH_LIMITS
+	}
+	if len(recorder.depthGranularities) == 0 {
+		recorder.depthGranularities = DEFAULT_DEPTH_GRANULARITIES
+	}
+	if recorder.reconnectDelay == 0 {
+		recorder.reconnectDelay = DEFAULT_RECONNECT_DELAY
+	}
+	if recorder.maxReconnectDelay == 0 {
+		recorder.maxReconnectDelay = DEFAULT_MAX_RECONNECT_DELAY
+	}
+	return recorder, nil
+}
+
+// Run records market data until the context is done. Dropped connections are re-established
+// with exponential backoff and the period without data is written as a `gap` record.
+//
+// Parameters:
+//   - ctx: The context stopping the recording.
+//
+// Returns:
+//   - An error if the first connection fails or if a record cannot be written, nil once the context is done.
+func (recorder *Recorder) Run(ctx context.Context) error {
+	client, err := recorder.connect()
+	if err != nil {
+		recorder.writer.Close()
+		return err
+	}
+
+	for {
+		lastReceivedAt, err := recorder.record(ctx, client)
+		if ctx.Err() != nil {
+			return recorder.writer.Close()
+		}
+		var writeErr *writeError
+		if errors.As(err, &writeErr) {
+			recorder.writer.Close()
+			return writeErr.err
+		}
+		reason := err.Error()
+		recorder.logger.WarnContext(ctx, "stream disconnected", "error", err)
+		recorder.report(fmt.Errorf("stream disconnected: %v", err))
+
+		// Reconnect with exponential backoff.
+		delay := recorder.reconnectDelay
+		for {
+			select {
+			case <-ctx.Done():
+				return recorder.writer.Close()
+			case <-time.After(delay):
+			}
+			if client, err = recorder.connect(); err == nil {
+				recorder.logger.InfoContext(ctx, "stream reconnected", "gap", time.Since(time.UnixMilli(lastReceivedAt)))
+				recorder.client.Metrics.Reconnected(metrics.CONNECTION_STREAM)
+				break
+			}
+			recorder.logger.WarnContext(ctx, "stream reconnection failed", "error", err, "delay", min(2*delay, recorder.maxReconnectDelay))
+			recorder.report(err)
+			delay = min(2*delay, recorder.maxReconnectDelay)
+		}
+
+		// Mark the period without data.
+		now := time.Now().UnixMilli()
+		gap := &Record{Type: RECORD_TYPE_GAP, ReceivedAt: now, Gap: &Gap{From: lastReceivedAt, To: now, Reason: reason}}
+		if err := recorder.write(gap); err != nil {
+			closeClient(client)
+			recorder.writer.Close()
+			return err
+		}
+	}
+}
+
+// writeError wraps errors writing records, which stop the recording.
+type writeError struct {
+	err error
+}
+
+func (err *writeError) Error() string {
+	return err.err.Error()
+}
+
+// record writes the stream messages of a client until reading fails, then closes the client.
+// It returns the time of the last message received.
+func (recorder *Recorder) record(ctx context.Context, client *ws_client.RyskV2WSClient) (int64, error) {
+	lastReceivedAt := time.Now().UnixMilli()
+
+	// Unblock reads once the context is done.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			client.StreamConnection.Close()
+		case <-done:
+		}
+	}()
+	defer closeClient(client)
+
+	reader := client.StreamReader()
+	for {
+		_, body, err := reader.ReadMessage()
+		if err != nil {
+			return lastReceivedAt, err
+		}
+		lastReceivedAt = time.Now().UnixMilli()
+
+		record, err := DecodeRecord(body, lastReceivedAt)
+		if err != nil {
+			recorder.report(err)
+			continue
+		}
+		if record == nil {
+			recorder.checkResponse(body)
+			continue
+		}
+		if err := recorder.write(record); err != nil {
+			return lastReceivedAt, &writeError{err: err}
+		}
+	}
+}
+
+// connect creates a websocket client and subscribes to every stream.
+func (recorder *Recorder) connect() (*ws_client.RyskV2WSClient, error) {
+	client, err := ws_client.NewRyskV2WSClient(recorder.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %v", err)
+	}
+
+	// Subscribe to streams.
+	subscriptions := []func() error{
+		func() error { return client.SubscribeSingleTrades("recorder-trade", recorder.products) },
+		func() error { return client.SubscribeAggregateTrades("recorder-aggTrade", recorder.products) },
+		func() error {
+			return client.SubscribePartialBookDepth("recorder-depth", recorder.products, recorder.depthLimits, recorder.depthGranularities)
+		},
+		func() error { return client.Subscribe24hrPriceChangeStatistics("recorder-ticker", recorder.products) },
+		func() error {
+			return client.SubscribeKlineData("recorder-klines", recorder.products, recorder.intervals)
+		},
+	}
+	for _, subscribe := range subscriptions {
+		if err := subscribe(); err != nil {
+			closeClient(client)
+			return nil, fmt.Errorf("failed to subscribe: %v", err)
+		}
+	}
+	return client, nil
+}
+
+// write writes a record and passes it to the OnRecord callback, if any.
+func (recorder *Recorder) write(record *Record) error {
+	if err := recorder.writer.Write(record); err != nil {
+		return err
+	}
+	if recorder.onRecord != nil {
+		recorder.onRecord(record)
+	}
+	return nil
+}
+
+// checkResponse reports failed subscription responses.
+func (recorder *Recorder) checkResponse(body []byte) {
+	var response types.WebsocketResponse
+	if err := json.Unmarshal(body, &response); err != nil || response.Error == nil {
+		return
+	}
+	recorder.report(fmt.Errorf("request %s failed: %s", response.ID, response.Error.Message))
+}
+
+// report passes an error to the OnError callback, if any.
+func (recorder *Recorder) report(err error) {
+	if recorder.onError != nil {
+		recorder.onError(err)
+	}
+}
+
+// closeClient closes the websocket connections of a client.
+func closeClient(client *ws_client.RyskV2WSClient) {
+	client.RPCConnection.Close()
+	client.StreamConnection.Close()
+}
@@ -0,0 +1,373 @@
+//go:build !integration
+// +build !integration
+
+package recorder
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rysk-finance/v2_client_go/api_client"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/metrics"
+	"github.com/rysk-finance/v2_client_go/ryskfake"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/ws_client"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const START int64 = 1700000000000
+
+type RecorderUnitTestSuite struct {
+	suite.Suite
+	Directory string
+}
+
+func (s *RecorderUnitTestSuite) SetupTest() {
+	s.Directory = s.T().TempDir()
+}
+
+func TestRunSuiteUnit_RecorderUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(RecorderUnitTestSuite))
+}
+
+func newPrivateKey(t *testing.T) string {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return hex.EncodeToString(crypto.FromECDSA(privateKey))
+}
+
+func trade(receivedAt int64, tradePrice string) *Record {
+	return &Record{
+		Type:       RECORD_TYPE_TRADE,
+		Stream:     "ethperp@trade",
+		Symbol:     constants.PRODUCT_ETH_PERP.Symbol,
+		ReceivedAt: receivedAt,
+		Trade:      &types.Trade{Symbol: constants.PRODUCT_ETH_PERP.Symbol, Price: tradePrice, Quantity: "1", Time: receivedAt},
+	}
+}
+
+// readAll reads every record of the recording directory.
+func (s *RecorderUnitTestSuite) readAll() []*Record {
+	files, err := ListFiles(s.Directory, "")
+	require.NoError(s.T(), err)
+	reader := NewReader(files...)
+	defer reader.Close()
+
+	var records []*Record
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return records
+		}
+		require.NoError(s.T(), err)
+		records = append(records, record)
+	}
+}
+
+func (s *RecorderUnitTestSuite) TestUnit_DecodeRecord() {
+	record, err := DecodeRecord([]byte(`{"stream":"ethperp@aggTrade","data":{"price":"1"}}`), START)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), RECORD_TYPE_AGG_TRADE, record.Type)
+	require.Equal(s.T(), START, record.ReceivedAt)
+	require.Equal(s.T(), "ethperp", record.Trade.Symbol)
+
+	record, err = DecodeRecord([]byte(`{"stream":"btcperp@klines_5m","data":{"close":"2"}}`), START)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), RECORD_TYPE_KLINE, record.Type)
+	require.Equal(s.T(), "btcperp", record.Kline.Symbol)
+	require.Equal(s.T(), constants.INTERVAL_5M, record.Kline.Interval)
+
+	record, err = DecodeRecord([]byte(`{"stream":"solperp@ticker","data":{"lastPrice":"3"}}`), START)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), RECORD_TYPE_TICKER, record.Type)
+	require.Equal(s.T(), "3", record.Ticker.LastPrice)
+
+	record, err = DecodeRecord([]byte(`{"stream":"ethperp@depth5_0","data":{"bids":[["1","2"]]}}`), START)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), RECORD_TYPE_DEPTH, record.Type)
+	require.Equal(s.T(), "ethperp@depth5_0", record.Stream)
+
+	// Responses and unknown topics are not market data.
+	record, err = DecodeRecord([]byte(`{"jsonrpc":"2.0","id":"1","success":true}`), START)
+	require.NoError(s.T(), err)
+	require.Nil(s.T(), record)
+	record, err = DecodeRecord([]byte(`{"stream":"ethperp@unknown","data":{}}`), START)
+	require.NoError(s.T(), err)
+	require.Nil(s.T(), record)
+
+	_, err = DecodeRecord([]byte(`{"stream":"ethperp@trade","data":[]}`), START)
+	require.Error(s.T(), err)
+}
+
+func (s *RecorderUnitTestSuite) TestUnit_NewWriter_UnknownCompression() {
+	writer, err := NewWriter(&WriterConfiguration{Directory: s.Directory, Compression: "lz4"})
+	require.Error(s.T(), err)
+	require.Nil(s.T(), writer)
+}
+
+func (s *RecorderUnitTestSuite) TestUnit_Writer_Rotation() {
+	for _, compression := range []Compression{COMPRESSION_NONE, COMPRESSION_GZIP, COMPRESSION_ZSTD} {
+		s.Directory = s.T().TempDir()
+		writer, err := NewWriter(&WriterConfiguration{Directory: s.Directory, Compression: compression, RotateInterval: time.Minute, MaxFileSize: 300})
+		require.NoError(s.T(), err)
+
+		// Two records per file by size, then a new file for the next minute.
+		minute := time.Minute.Milliseconds()
+		window := START - START%minute
+		receivedAt := []int64{window, window + 1, window + 2, window + minute}
+		for i, at := range receivedAt {
+			require.NoError(s.T(), writer.Write(trade(at, big.NewInt(int64(i)).String())))
+		}
+		require.NoError(s.T(), writer.Close())
+
+		files, err := ListFiles(s.Directory, "")
+		require.NoError(s.T(), err)
+		require.Len(s.T(), files, 3, compression)
+		require.Equal(s.T(), "market-20231114T221300.000Z"+EXTENSIONS[compression], filepath.Base(files[0]))
+		for _, file := range files {
+			require.True(s.T(), strings.HasSuffix(file, EXTENSIONS[compression]))
+		}
+
+		records := s.readAll()
+		require.Len(s.T(), records, 4)
+		for i, record := range records {
+			require.Equal(s.T(), receivedAt[i], record.ReceivedAt)
+			require.Equal(s.T(), big.NewInt(int64(i)).String(), record.Trade.Price)
+		}
+	}
+}
+
+func (s *RecorderUnitTestSuite) TestUnit_Writer_Flush() {
+	writer, err := NewWriter(&WriterConfiguration{Directory: s.Directory, Compression: COMPRESSION_NONE, FlushInterval: time.Hour})
+	require.NoError(s.T(), err)
+	defer writer.Close()
+
+	// Records stay buffered until flushed.
+	require.NoError(s.T(), writer.Write(trade(START, "1")))
+	require.Empty(s.T(), s.readAll())
+	require.NoError(s.T(), writer.Flush())
+	require.Len(s.T(), s.readAll(), 1)
+}
+
+func (s *RecorderUnitTestSuite) TestUnit_Reader_Errors() {
+	require.NoError(s.T(), os.WriteFile(filepath.Join(s.Directory, "market-1.jsonl"), []byte("{\"type\":\"trade\"}\n\nnot json\n"), 0o644))
+	require.NoError(s.T(), os.WriteFile(filepath.Join(s.Directory, "market-2.jsonl.gz"), []byte("not gzip"), 0o644))
+	require.NoError(s.T(), os.WriteFile(filepath.Join(s.Directory, "market-3.csv"), nil, 0o644))
+	require.NoError(s.T(), os.WriteFile(filepath.Join(s.Directory, "other-1.jsonl"), nil, 0o644))
+
+	files, err := ListFiles(s.Directory, "")
+	require.NoError(s.T(), err)
+	require.Len(s.T(), files, 2)
+
+	reader := NewReader(files...)
+	record, err := reader.Next()
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), RECORD_TYPE_TRADE, record.Type)
+	_, err = reader.Next()
+	require.ErrorContains(s.T(), err, "line 3")
+	require.NoError(s.T(), reader.Close())
+	_, err = reader.Next()
+	require.Error(s.T(), err)
+
+	_, err = ListFiles(filepath.Join(s.Directory, "missing"), "")
+	require.Error(s.T(), err)
+}
+
+func (s *RecorderUnitTestSuite) TestUnit_NewEventSource() {
+	writer, err := NewWriter(&WriterConfiguration{Directory: s.Directory})
+	require.NoError(s.T(), err)
+	records := []*Record{
+		trade(START, "1"),
+		{Type: RECORD_TYPE_AGG_TRADE, ReceivedAt: START, Trade: &types.Trade{}},
+		{Type: RECORD_TYPE_TICKER, ReceivedAt: START + 1, Ticker: &types.Ticker{}},
+		{Type: RECORD_TYPE_GAP, ReceivedAt: START + 2, Gap: &Gap{From: START + 1, To: START + 2}},
+		{Type: RECORD_TYPE_DEPTH, ReceivedAt: START + 3, Depth: &types.OrderBookDepth{}},
+		{Type: RECORD_TYPE_KLINE, ReceivedAt: START + 4, Kline: &types.Kline{}},
+	}
+	for _, record := range records {
+		require.NoError(s.T(), writer.Write(record))
+	}
+	require.NoError(s.T(), writer.Close())
+
+	files, err := ListFiles(s.Directory, "")
+	require.NoError(s.T(), err)
+	source := NewEventSource(NewReader(files...))
+
+	event, err := source.Next()
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "1", event.Trade.Price)
+	require.Equal(s.T(), START, event.Time)
+	event, err = source.Next()
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), event.Depth)
+	require.Equal(s.T(), START+3, event.Time)
+	event, err = source.Next()
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), event.Kline)
+	_, err = source.Next()
+	require.ErrorIs(s.T(), err, io.EOF)
+}
+
+func (s *RecorderUnitTestSuite) TestUnit_NewRecorder_Invalid() {
+	client := &ws_client.RyskV2WSClientConfiguration{}
+	writer := &WriterConfiguration{Directory: s.Directory}
+	products := []*types.Product{&constants.PRODUCT_ETH_PERP}
+
+	_, err := NewRecorder(&RecorderConfiguration{Products: products, Writer: writer})
+	require.Error(s.T(), err)
+	_, err = NewRecorder(&RecorderConfiguration{Client: client, Writer: writer})
+	require.Error(s.T(), err)
+	_, err = NewRecorder(&RecorderConfiguration{Client: client, Products: products})
+	require.Error(s.T(), err)
+	_, err = NewRecorder(&RecorderConfiguration{Client: client, Products: products, Writer: &WriterConfiguration{Directory: s.Directory, Compression: "lz4"}})
+	require.Error(s.T(), err)
+}
+
+func (s *RecorderUnitTestSuite) TestUnit_Recorder() {
+	server, err := ryskfake.NewServer(&ryskfake.ServerConfiguration{})
+	require.NoError(s.T(), err)
+	defer server.Close()
+
+	// Trades are made between two accounts.
+	newAPIClient := func() *api_client.RyskV2APIClient {
+		client, err := api_client.NewRyskV2APIClient(&api_client.RyskV2APIClientConfiguration{
+			Env:        constants.ENVIRONMENT_TESTNET,
+			PrivateKey: newPrivateKey(s.T()),
+			RpcUrl:     server.URL(),
+			BaseUrl:    server.URL(),
+		})
+		require.NoError(s.T(), err)
+		return client
+	}
+	maker, taker := newAPIClient(), newAPIClient()
+	nonce := int64(0)
+	makeTrade := func() {
+		for _, order := range []struct {
+			client    *api_client.RyskV2APIClient
+			isBuy     bool
+			orderType types.OrderType
+		}{{maker, true, constants.ORDER_TYPE_LIMIT}, {taker, false, constants.ORDER_TYPE_MARKET}} {
+			nonce++
+			_, err := order.client.NewOrder(&types.NewOrderRequest{
+				Product:     &constants.PRODUCT_ETH_PERP,
+				IsBuy:       order.isBuy,
+				OrderType:   order.orderType,
+				TimeInForce: constants.TIME_IN_FORCE_GTC,
+				Price:       new(big.Int).Mul(big.NewInt(3000), constants.E18).String(),
+				Quantity:    constants.E17.String(),
+				Expiration:  time.Now().Add(time.Hour).UnixMilli(),
+				Nonce:       nonce,
+			})
+			require.NoError(s.T(), err)
+		}
+	}
+
+	registry := prometheus.NewRegistry()
+	clientMetrics, err := metrics.NewMetrics(&metrics.MetricsConfiguration{Registerer: registry})
+	require.NoError(s.T(), err)
+	records := make(chan *Record, 100)
+	recorder, err := NewRecorder(&RecorderConfiguration{
+		Client: &ws_client.RyskV2WSClientConfiguration{
+			Env:         constants.ENVIRONMENT_TESTNET,
+			PrivateKey:  newPrivateKey(s.T()),
+			RpcUrl:      server.URL(),
+			BaseUrl:     server.URL(),
+			WSRpcUrl:    server.RPCURL(),
+			WSStreamUrl: server.StreamURL(),
+			Metrics:     clientMetrics,
+		},
+		Products:       []*types.Product{&constants.PRODUCT_ETH_PERP},
+		Writer:         &WriterConfiguration{Directory: s.Directory, Compression: COMPRESSION_ZSTD},
+		ReconnectDelay: 10 * time.Millisecond,
+		OnRecord:       func(record *Record) { records <- record },
+	})
+	require.NoError(s.T(), err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- recorder.Run(ctx) }()
+
+	// waitFor trades until a record of the type is written.
+	waitFor := func(recordType RecordType) map[RecordType]bool {
+		seen := make(map[RecordType]bool)
+		deadline := time.After(5 * time.Second)
+		for !seen[recordType] {
+			if recordType == RECORD_TYPE_TRADE {
+				makeTrade()
+			}
+			select {
+			case record := <-records:
+				seen[record.Type] = true
+			case <-time.After(50 * time.Millisecond):
+			case <-deadline:
+				s.T().Fatalf("no %s record", recordType)
+			}
+		}
+		return seen
+	}
+
+	// Every stream is recorded once subscribed.
+	waitFor(RECORD_TYPE_TRADE)
+	seen := make(map[RecordType]bool)
+	for len(seen) < 5 {
+		select {
+		case record := <-records:
+			seen[record.Type] = true
+		case <-time.After(5 * time.Second):
+			s.T().Fatalf("recorded %v", seen)
+		}
+		if len(seen) < 5 {
+			makeTrade()
+		}
+	}
+
+	// A dropped connection is marked by a gap and recording resumes.
+	server.Disconnect()
+	waitFor(RECORD_TYPE_GAP)
+	waitFor(RECORD_TYPE_TRADE)
+
+	cancel()
+	require.NoError(s.T(), <-done)
+
+	// The files hold the same records in order.
+	recorded := s.readAll()
+	gaps, tradesAfterGap := 0, 0
+	for i, record := range recorded {
+		if i > 0 {
+			require.GreaterOrEqual(s.T(), record.ReceivedAt, recorded[i-1].ReceivedAt)
+		}
+		switch {
+		case record.Type == RECORD_TYPE_GAP:
+			gaps++
+			require.LessOrEqual(s.T(), record.Gap.From, record.Gap.To)
+			require.NotEmpty(s.T(), record.Gap.Reason)
+		case record.Type == RECORD_TYPE_TRADE && gaps > 0:
+			tradesAfterGap++
+		}
+	}
+	require.Equal(s.T(), 1, gaps)
+	require.NotZero(s.T(), tradesAfterGap)
+
+	// The reconnection and the messages of every stream are recorded.
+	require.NoError(s.T(), testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP rysk_websocket_reconnects_total Websocket reconnections, by connection.
+# TYPE rysk_websocket_reconnects_total counter
+rysk_websocket_reconnects_total{connection="stream"} 1
+`), "rysk_websocket_reconnects_total"))
+	topics, err := testutil.GatherAndCount(registry, "rysk_stream_messages_total")
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 5, topics)
+}
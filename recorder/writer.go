@@ -0,0 +1,250 @@
+package recorder
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	DEFAULT_PREFIX          string        = "market"
+	DEFAULT_ROTATE_INTERVAL time.Duration = time.Hour
+	DEFAULT_FLUSH_INTERVAL  time.Duration = time.Second
+	FILE_TIME_FORMAT        string        = "20060102T150405.000Z"
+)
+
+type Compression string
+
+const (
+	COMPRESSION_NONE Compression = "none"
+	COMPRESSION_GZIP Compression = "gzip"
+	COMPRESSION_ZSTD Compression = "zstd"
+)
+
+// EXTENSIONS maps each compression to the extension of the files it writes.
+var EXTENSIONS = map[Compression]string{
+	COMPRESSION_NONE: ".jsonl",
+	COMPRESSION_GZIP: ".jsonl.gz",
+	COMPRESSION_ZSTD: ".jsonl.zst",
+}
+
+// WriterConfiguration holds the configuration for a recording writer.
+type WriterConfiguration struct {
+	Directory      string        // Directory the files are written to, created if missing.
+	Prefix         string        // File name prefix. Defaults to `DEFAULT_PREFIX`.
+	Compression    Compression   // File compression. Defaults to `COMPRESSION_GZIP`.
+	RotateInterval time.Duration // Files hold the records received within one interval, aligned to UNIX time. Defaults to `DEFAULT_ROTATE_INTERVAL`.
+	MaxFileSize    int64         // Optional size in bytes, before compression, after which a new file is started.
+	FlushInterval  time.Duration // Maximum time records stay buffered in memory. Defaults to `DEFAULT_FLUSH_INTERVAL`.
+}
+
+// flusher is implemented by the gzip and zstd writers.
+type flusher interface {
+	Flush() error
+}
+
+// Writer appends records to rotating, optionally compressed, JSON lines files named
+// `<prefix>-<time of the first record><extension>`, so that file names sort in time order.
+type Writer struct {
+	mutex          sync.Mutex
+	directory      string
+	prefix         string
+	compression    Compression
+	rotateInterval time.Duration
+	maxFileSize    int64
+	flushInterval  time.Duration
+	file           *os.File       // file is the current file, nil before the first record.
+	compressor     io.WriteCloser // compressor compresses into file, nil without compression.
+	buffer         *bufio.Writer  // buffer buffers writes to the compressor or file.
+	window         int64          // window is the start of the rotation interval of the current file.
+	size           int64          // size is the number of bytes written to the current file before compression.
+	flushedAt      time.Time      // flushedAt is the time of the last flush.
+}
+
+// NewWriter creates a new Writer instance.
+//
+// Parameters:
+//   - config: A pointer to WriterConfiguration containing the configuration settings.
+//
+// Returns:
+//   - A pointer to Writer, to be closed with `Close`.
+//   - An error if the compression is unknown or if the directory cannot be created.
+func NewWriter(config *WriterConfiguration) (*Writer, error) {
+	writer := &Writer{
+		directory:      config.Directory,
+		prefix:         config.Prefix,
+		compression:    config.Compression,
+		rotateInterval: config.RotateInterval,
+		maxFileSize:    config.MaxFileSize,
+		flushInterval:  config.FlushInterval,
+	}
+	if writer.prefix == "" {
+		writer.prefix = DEFAULT_PREFIX
+	}
+	if writer.compression == "" {
+		writer.compression = COMPRESSION_GZIP
+	}
+	if _, ok := EXTENSIONS[writer.compression]; !ok {
+		return nil, fmt.Errorf("unknown compression %q", writer.compression)
+	}
+	if writer.rotateInterval == 0 {
+		writer.rotateInterval = DEFAULT_ROTATE_INTERVAL
+	}
+	if writer.flushInterval == 0 {
+		writer.flushInterval = DEFAULT_FLUSH_INTERVAL
+	}
+	if err := os.MkdirAll(writer.directory, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %v", err)
+	}
+	return writer, nil
+}
+
+// Write appends a record, starting a new file when the record falls in another rotation interval
+// or the current file reached `MaxFileSize`.
+//
+// Parameters:
+//   - record: The record.
+//
+// Returns:
+//   - An error if the record cannot be encoded or written.
+func (writer *Writer) Write(record *Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode record: %v", err)
+	}
+	line = append(line, '\n')
+
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	// Rotate file.
+	window := record.ReceivedAt - record.ReceivedAt%writer.rotateInterval.Milliseconds()
+	if writer.file == nil || window != writer.window || (writer.maxFileSize > 0 && writer.size >= writer.maxFileSize) {
+		if err := writer.close(); err != nil {
+			return err
+		}
+		if err := writer.open(record.ReceivedAt); err != nil {
+			return err
+		}
+		writer.window = window
+	}
+
+	// Append line.
+	if _, err := writer.buffer.Write(line); err != nil {
+		return fmt.Errorf("failed to write record: %v", err)
+	}
+	writer.size += int64(len(line))
+	if time.Since(writer.flushedAt) >= writer.flushInterval {
+		return writer.flush()
+	}
+	return nil
+}
+
+// Flush writes buffered records through to the current file.
+//
+// Returns:
+//   - An error if the records cannot be written.
+func (writer *Writer) Flush() error {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	return writer.flush()
+}
+
+// Close flushes and closes the current file.
+//
+// Returns:
+//   - An error if the file cannot be written or closed.
+func (writer *Writer) Close() error {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	return writer.close()
+}
+
+// open creates a new file named after the time of its first record.
+func (writer *Writer) open(firstRecordAt int64) error {
+	// Find a free name, a file may have been started in the same millisecond.
+	var path string
+	for at := firstRecordAt; ; at++ {
+		name := writer.prefix + "-" + time.UnixMilli(at).UTC().Format(FILE_TIME_FORMAT) + EXTENSIONS[writer.compression]
+		path = filepath.Join(writer.directory, name)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+
+	var output io.Writer = file
+	switch writer.compression {
+	case COMPRESSION_GZIP:
+		writer.compressor = gzip.NewWriter(file)
+		output = writer.compressor
+	case COMPRESSION_ZSTD:
+		encoder, err := zstd.NewWriter(file)
+		if err != nil {
+			file.Close()
+			return fmt.Errorf("failed to create zstd encoder: %v", err)
+		}
+		writer.compressor = encoder
+		output = encoder
+	}
+	writer.file = file
+	writer.buffer = bufio.NewWriter(output)
+	writer.size = 0
+	writer.flushedAt = time.Now()
+	return nil
+}
+
+// flush writes the buffer through the compressor to the file.
+func (writer *Writer) flush() error {
+	if writer.file == nil {
+		return nil
+	}
+	if err := writer.buffer.Flush(); err != nil {
+		return fmt.Errorf("failed to flush records: %v", err)
+	}
+	if compressor, ok := writer.compressor.(flusher); ok {
+		if err := compressor.Flush(); err != nil {
+			return fmt.Errorf("failed to flush records: %v", err)
+		}
+	}
+	writer.flushedAt = time.Now()
+	return nil
+}
+
+// close flushes and closes the current file, if any.
+func (writer *Writer) close() error {
+	if writer.file == nil {
+		return nil
+	}
+	file := writer.file
+	writer.file = nil
+
+	if err := writer.buffer.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to flush records: %v", err)
+	}
+	if writer.compressor != nil {
+		err := writer.compressor.Close()
+		writer.compressor = nil
+		if err != nil {
+			file.Close()
+			return fmt.Errorf("failed to close compressor: %v", err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package ryskfake
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+)
+
+// delegatedPrimaryTypes are the primary types approved signers may sign on behalf of an account.
+var delegatedPrimaryTypes = map[types.PrimaryType]bool{
+	constants.PRIMARY_TYPE_ORDER:                 true,
+	constants.PRIMARY_TYPE_CANCEL_ORDER:          true,
+	constants.PRIMARY_TYPE_CANCEL_ORDERS:         true,
+	constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION: true,
+}
+
+// orderRequest is the body of `order` and `order.place` requests.
+type orderRequest struct {
+	Account      string `json:"account"`
+	SubAccountId int64  `json:"subAccountId"`
+	ProductId    int64  `json:"productId"`
+	IsBuy        bool   `json:"isBuy"`
+	OrderType    int64  `json:"orderType"`
+	TimeInForce  int64  `json:"timeInForce"`
+	Expiration   int64  `json:"expiration"`
+	Price        string `json:"price"`
+	Quantity     string `json:"quantity"`
+	Nonce        int64  `json:"nonce"`
+	Signature    string `json:"signature"`
+}
+
+func (request *orderRequest) message() apitypes.TypedDataMessage {
+	return apitypes.TypedDataMessage{
+		"account":      request.Account,
+		"subAccountId": strconv.FormatInt(request.SubAccountId, 10),
+		"productId":    strconv.FormatInt(request.ProductId, 10),
+		"isBuy":        request.IsBuy,
+		"orderType":    strconv.FormatInt(request.OrderType, 10),
+		"timeInForce":  strconv.FormatInt(request.TimeInForce, 10),
+		"expiration":   strconv.FormatInt(request.Expiration, 10),
+		"price":        request.Price,
+		"quantity":     request.Quantity,
+		"nonce":        strconv.FormatInt(request.Nonce, 10),
+	}
+}
+
+// cancelOrderRequest is the body of `order` deletion and `order.cancel` requests.
+type cancelOrderRequest struct {
+	Account      string `json:"account"`
+	SubAccountId int64  `json:"subAccountId"`
+	ProductId    int64  `json:"productId"`
+	OrderId      string `json:"orderId"`
+	Signature    string `json:"signature"`
+}
+
+func (request *cancelOrderRequest) message() apitypes.TypedDataMessage {
+	return apitypes.TypedDataMessage{
+		"account":      request.Account,
+		"subAccountId": strconv.FormatInt(request.SubAccountId, 10),
+		"productId":    strconv.FormatInt(request.ProductId, 10),
+		"orderId":      request.OrderId,
+	}
+}
+
+// cancelOrdersRequest is the body of `openOrders` deletion and `order.cancelOpen` requests.
+type cancelOrdersRequest struct {
+	Account      string `json:"account"`
+	SubAccountId int64  `json:"subAccountId"`
+	ProductId    int64  `json:"productId"`
+	Signature    string `json:"signature"`
+}
+
+func (request *cancelOrdersRequest) message() apitypes.TypedDataMessage {
+	return apitypes.TypedDataMessage{
+		"account":      request.Account,
+		"subAccountId": strconv.FormatInt(request.SubAccountId, 10),
+		"productId":    strconv.FormatInt(request.ProductId, 10),
+	}
+}
+
+// signerRequest is the body of `approved-signers` and `signer.set` requests.
+type signerRequest struct {
+	Account        string `json:"account"`
+	SubAccountId   int64  `json:"subAccountId"`
+	ApprovedSigner string `json:"approvedSigner"`
+	IsApproved     bool   `json:"isApproved"`
+	Nonce          int64  `json:"nonce"`
+	Signature      string `json:"signature"`
+}
+
+func (request *signerRequest) message() apitypes.TypedDataMessage {
+	return apitypes.TypedDataMessage{
+		"account":        request.Account,
+		"subAccountId":   strconv.FormatInt(request.SubAccountId, 10),
+		"approvedSigner": request.ApprovedSigner,
+		"isApproved":     request.IsApproved,
+		"nonce":          strconv.FormatInt(request.Nonce, 10),
+	}
+}
+
+// withdrawRequest is the body of `withdraw` requests.
+type withdrawRequest struct {
+	Account      string `json:"account"`
+	SubAccountId int64  `json:"subAccountId"`
+	Asset        string `json:"asset"`
+	Quantity     string `json:"quantity"`
+	Nonce        int64  `json:"nonce"`
+	Signature    string `json:"signature"`
+}
+
+func (request *withdrawRequest) message() apitypes.TypedDataMessage {
+	return apitypes.TypedDataMessage{
+		"account":      request.Account,
+		"subAccountId": strconv.FormatInt(request.SubAccountId, 10),
+		"asset":        request.Asset,
+		"quantity":     request.Quantity,
+		"nonce":        strconv.FormatInt(request.Nonce, 10),
+	}
+}
+
+// referralRequest is the body of `referral/add-referee` requests.
+type referralRequest struct {
+	Account   string `json:"account"`
+	Code      string `json:"code"`
+	Signature string `json:"signature"`
+}
+
+func (request *referralRequest) message() apitypes.TypedDataMessage {
+	return apitypes.TypedDataMessage{
+		"account": request.Account,
+		"code":    request.Code,
+	}
+}
+
+// loginRequest is the body of `session.login` requests.
+type loginRequest struct {
+	Account   string `json:"account"`
+	Message   string `json:"message"`
+	Timestamp uint64 `json:"timestamp"`
+	Signature string `json:"signature"`
+}
+
+func (request *loginRequest) message() apitypes.TypedDataMessage {
+	return apitypes.TypedDataMessage{
+		"account":   request.Account,
+		"message":   request.Message,
+		"timestamp": strconv.FormatUint(request.Timestamp, 10),
+	}
+}
+
+// authenticate checks that a signature over message was produced by the account,
+// or by one of its approved signers for delegated primary types.
+//
+// Parameters:
+//   - primaryType: The EIP-712 primary type of the message.
+//   - message: The signed message.
+//   - signature: The hex signature with `0x` prefix.
+//   - accountAddress: The account the request acts on.
+//   - subAccountId: The sub-account the request acts on.
+//
+// Returns:
+//   - An error wrapping `errInvalidSignature` if the signature is invalid, or `errUnauthorized` if the signer
+//     is not allowed to act on the sub-account.
+func (server *Server) authenticate(primaryType types.PrimaryType, message apitypes.TypedDataMessage, signature string, accountAddress string, subAccountId int64) error {
+	var approvedSigners []common.Address
+	if delegatedPrimaryTypes[primaryType] {
+		server.mutex.Lock()
+		for signer, approved := range server.state(subAccountKey{account: normalizeAddress(accountAddress), subAccountId: subAccountId}).signers {
+			if approved {
+				approvedSigners = append(approvedSigners, common.HexToAddress(signer))
+			}
+		}
+		server.mutex.Unlock()
+	}
+
+	_, err := utils.VerifySignature(server.domain, primaryType, message, signature, common.HexToAddress(accountAddress), approvedSigners...)
+	if errors.Is(err, utils.ErrUnexpectedSigner) {
+		return fmt.Errorf("%w: %v", errUnauthorized, err)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", errInvalidSignature, err)
+	}
+	return nil
+}
+
+// normalizeAddress lowercases an address, as the exchange reports them.
+func normalizeAddress(address string) string {
+	return strings.ToLower(address)
+}
@@ -0,0 +1,706 @@
+package ryskfake
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+var (
+	errNotFound            = errors.New("not found")
+	errUnauthorized        = errors.New("unauthorized")
+	errInvalidSignature    = fmt.Errorf("%w: invalid signature", errUnauthorized)
+	errNonceUsed           = errors.New("already used")
+	errInsufficientBalance = errors.New("insufficient balance")
+)
+
+// order is an order with its working quantities.
+type order struct {
+	types.Order
+	key       subAccountKey // key is the sub-account owning the order.
+	sequence  int64         // sequence orders orders by creation.
+	price     *big.Int      // price is the limit price, nil for market orders.
+	remaining *big.Int      // remaining is the unfilled quantity.
+	filled    *big.Int      // filled is the filled quantity.
+}
+
+// book is the order book of a product.
+type book struct {
+	bids []*order // bids are ordered by descending price, then by time.
+	asks []*order // asks are ordered by ascending price, then by time.
+}
+
+// position is a perpetual position.
+type position struct {
+	quantity      *big.Int // quantity is the signed position size.
+	avgEntryPrice *big.Int // avgEntryPrice is the average entry price.
+}
+
+// changes collects the effects of an operation to publish once the mutex is released.
+type changes struct {
+	products map[int64]bool                          // products holds the IDs of products whose book or trades changed.
+	trades   []types.Trade                           // trades holds the executed trades in order.
+	updates  map[subAccountKey][]types.AccountUpdate // updates holds the account updates by sub-account.
+}
+
+func newChanges() *changes {
+	return &changes{
+		products: make(map[int64]bool),
+		updates:  make(map[subAccountKey][]types.AccountUpdate),
+	}
+}
+
+// order records an order update.
+func (changes *changes) order(order *order) {
+	update := order.Order
+	changes.products[order.ProductId] = true
+	changes.updates[order.key] = append(changes.updates[order.key], types.AccountUpdate{
+		Type:  constants.ACCOUNT_UPDATE_ORDER,
+		Order: &update,
+	})
+}
+
+// side returns the orders resting on one side of the book.
+func (book *book) side(isBuy bool) *[]*order {
+	if isBuy {
+		return &book.bids
+	}
+	return &book.asks
+}
+
+// insert rests an order behind every order at a better or equal price.
+func (book *book) insert(resting *order) {
+	side := book.side(resting.IsBuy)
+	index := sort.Search(len(*side), func(i int) bool {
+		if resting.IsBuy {
+			return (*side)[i].price.Cmp(resting.price) < 0
+		}
+		return (*side)[i].price.Cmp(resting.price) > 0
+	})
+	*side = append(*side, nil)
+	copy((*side)[index+1:], (*side)[index:])
+	(*side)[index] = resting
+}
+
+// remove removes a resting order from the book.
+func (book *book) remove(resting *order) {
+	side := book.side(resting.IsBuy)
+	for i, candidate := range *side {
+		if candidate == resting {
+			*side = append((*side)[:i], (*side)[i+1:]...)
+			return
+		}
+	}
+}
+
+// crosses reports whether an incoming order can match a resting one.
+func crosses(incoming *order, resting *order) bool {
+	if incoming.price == nil {
+		return true
+	}
+	if incoming.IsBuy {
+		return incoming.price.Cmp(resting.price) >= 0
+	}
+	return incoming.price.Cmp(resting.price) <= 0
+}
+
+// expireOrders removes expired orders from every book. The caller must hold the mutex.
+func (server *Server) expireOrders(now int64, changes *changes) {
+	for _, book := range server.books {
+		for _, side := range []*[]*order{&book.bids, &book.asks} {
+			kept := (*side)[:0]
+			for _, resting := range *side {
+				if resting.Expiration != 0 && resting.Expiration <= now {
+					resting.Status = constants.ORDER_STATUS_EXPIRED
+					changes.order(resting)
+					continue
+				}
+				kept = append(kept, resting)
+			}
+			*side = kept
+		}
+	}
+}
+
+// newOrder validates, matches and rests an order. The caller must hold the mutex.
+func (server *Server) newOrder(request *orderRequest, changes *changes) (*types.Order, error) {
+	product, ok := server.products[request.ProductId]
+	if !ok {
+		return nil, fmt.Errorf("product %d %w", request.ProductId, errNotFound)
+	}
+	if !product.IsActive {
+		return nil, fmt.Errorf("product %s is not active", product.Symbol)
+	}
+	now := time.Now().UnixMilli()
+	if request.Expiration != 0 && request.Expiration <= now {
+		return nil, fmt.Errorf("order expired")
+	}
+
+	// Validate order type, time in force, quantity and price.
+	orderType := types.OrderType(request.OrderType)
+	switch orderType {
+	case constants.ORDER_TYPE_LIMIT, constants.ORDER_TYPE_LIMIT_MAKER, constants.ORDER_TYPE_MARKET:
+	default:
+		return nil, fmt.Errorf("order type %d is not supported", request.OrderType)
+	}
+	timeInForce := types.TimeInForce(request.TimeInForce)
+	switch timeInForce {
+	case constants.TIME_IN_FORCE_GTC, constants.TIME_IN_FORCE_FOK, constants.TIME_IN_FORCE_IOC:
+	default:
+		return nil, fmt.Errorf("time in force %d is not supported", request.TimeInForce)
+	}
+	quantity, ok := new(big.Int).SetString(request.Quantity, 10)
+	if !ok || quantity.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid quantity %q", request.Quantity)
+	}
+	var price *big.Int
+	if orderType != constants.ORDER_TYPE_MARKET {
+		price, ok = new(big.Int).SetString(request.Price, 10)
+		if !ok || price.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid price %q", request.Price)
+		}
+		increment, _ := new(big.Int).SetString(product.Increment, 10)
+		if new(big.Int).Mod(price, increment).Sign() != 0 {
+			return nil, fmt.Errorf("price %s is not a multiple of increment %s", request.Price, product.Increment)
+		}
+	}
+
+	// Consume nonce.
+	key := subAccountKey{account: request.Account, subAccountId: request.SubAccountId}
+	state := server.state(key)
+	if state.nonces[request.Nonce] {
+		return nil, fmt.Errorf("nonce %d %w", request.Nonce, errNonceUsed)
+	}
+	state.nonces[request.Nonce] = true
+
+	// Create order.
+	server.sequence++
+	incoming := &order{
+		Order: types.Order{
+			Id:           strconv.FormatInt(server.sequence, 10),
+			Account:      request.Account,
+			SubAccountId: request.SubAccountId,
+			ProductId:    request.ProductId,
+			IsBuy:        request.IsBuy,
+			OrderType:    orderType,
+			TimeInForce:  timeInForce,
+			Price:        request.Price,
+			Quantity:     quantity.String(),
+			Filled:       "0",
+			Status:       constants.ORDER_STATUS_OPEN,
+			Expiration:   request.Expiration,
+			Nonce:        request.Nonce,
+			CreatedAt:    now,
+		},
+		key:       key,
+		sequence:  server.sequence,
+		price:     price,
+		remaining: quantity,
+		filled:    new(big.Int),
+	}
+	server.orders[incoming.Id] = incoming
+
+	server.expireOrders(now, changes)
+	book := server.books[product.Id]
+	contra := book.side(!incoming.IsBuy)
+
+	// Reject crossing post-only orders and kill unfillable fill-or-kill orders.
+	if orderType == constants.ORDER_TYPE_LIMIT_MAKER && len(*contra) > 0 && crosses(incoming, (*contra)[0]) {
+		delete(server.orders, incoming.Id)
+		return nil, fmt.Errorf("limit maker order would match immediately")
+	}
+	if timeInForce == constants.TIME_IN_FORCE_FOK {
+		available := new(big.Int)
+		for _, resting := range *contra {
+			if !crosses(incoming, resting) {
+				break
+			}
+			available.Add(available, resting.remaining)
+		}
+		if available.Cmp(quantity) < 0 {
+			incoming.Status = constants.ORDER_STATUS_EXPIRED
+			changes.order(incoming)
+			return &incoming.Order, nil
+		}
+	}
+
+	// Match against the book at resting prices.
+	for incoming.remaining.Sign() > 0 && len(*contra) > 0 && crosses(incoming, (*contra)[0]) {
+		resting := (*contra)[0]
+		fillQuantity := new(big.Int).Set(incoming.remaining)
+		if resting.remaining.Cmp(fillQuantity) < 0 {
+			fillQuantity.Set(resting.remaining)
+		}
+		server.fill(product, resting, incoming, fillQuantity, now, changes)
+		if resting.remaining.Sign() == 0 {
+			*contra = (*contra)[1:]
+		}
+	}
+
+	// Rest or cancel the remainder.
+	switch {
+	case incoming.remaining.Sign() == 0:
+	case orderType == constants.ORDER_TYPE_MARKET || timeInForce != constants.TIME_IN_FORCE_GTC:
+		incoming.Status = constants.ORDER_STATUS_CANCELLED
+	default:
+		book.insert(incoming)
+	}
+	changes.order(incoming)
+	return &incoming.Order, nil
+}
+
+// fill executes a trade between a resting and an incoming order at the resting price. The caller must hold the mutex.
+func (server *Server) fill(product *Product, resting *order, incoming *order, quantity *big.Int, now int64, changes *changes) {
+	for _, filled := range []*order{resting, incoming} {
+		filled.remaining.Sub(filled.remaining, quantity)
+		filled.filled.Add(filled.filled, quantity)
+		filled.Filled = filled.filled.String()
+		filled.Status = constants.ORDER_STATUS_PARTIALLY_FILLED
+		if filled.remaining.Sign() == 0 {
+			filled.Status = constants.ORDER_STATUS_FILLED
+		}
+		if filled != incoming {
+			changes.order(filled)
+		}
+
+		delta := new(big.Int).Set(quantity)
+		if !filled.IsBuy {
+			delta.Neg(delta)
+		}
+		server.applyPosition(filled.key, product.Id, delta, resting.price, changes)
+	}
+
+	server.sequence++
+	trade := types.Trade{
+		Id:           strconv.FormatInt(server.sequence, 10),
+		ProductId:    product.Id,
+		Symbol:       product.Symbol,
+		Price:        resting.price.String(),
+		Quantity:     quantity.String(),
+		IsBuyerMaker: resting.IsBuy,
+		Time:         now,
+	}
+	server.trades[product.Id] = append(server.trades[product.Id], trade)
+	changes.trades = append(changes.trades, trade)
+}
+
+// applyPosition adds a signed fill to a position and settles realized PnL in USDC. The caller must hold the mutex.
+func (server *Server) applyPosition(key subAccountKey, productId int64, delta *big.Int, price *big.Int, changes *changes) {
+	state := server.state(key)
+	current, ok := state.positions[productId]
+	if !ok {
+		current = &position{quantity: new(big.Int), avgEntryPrice: new(big.Int)}
+		state.positions[productId] = current
+	}
+
+	updated := new(big.Int).Add(current.quantity, delta)
+	switch {
+	case current.quantity.Sign() == 0 || current.quantity.Sign() == delta.Sign():
+		// Increase: weight the entry price by size.
+		notional := new(big.Int).Mul(current.avgEntryPrice, new(big.Int).Abs(current.quantity))
+		notional.Add(notional, new(big.Int).Mul(price, new(big.Int).Abs(delta)))
+		current.avgEntryPrice = notional.Quo(notional, new(big.Int).Abs(updated))
+	default:
+		// Reduce: realize PnL on the closed size, flip entry price when crossing zero.
+		closed := new(big.Int).Abs(delta)
+		if closed.Cmp(new(big.Int).Abs(current.quantity)) > 0 {
+			closed.Abs(current.quantity)
+		}
+		realized := new(big.Int).Sub(price, current.avgEntryPrice)
+		realized.Mul(realized, closed)
+		realized.Quo(realized, constants.E18)
+		if current.quantity.Sign() < 0 {
+			realized.Neg(realized)
+		}
+		server.credit(key, server.usdc, realized, changes)
+		switch {
+		case updated.Sign() == 0:
+			current.avgEntryPrice = new(big.Int)
+		case updated.Sign() != current.quantity.Sign():
+			current.avgEntryPrice = new(big.Int).Set(price)
+		}
+	}
+	current.quantity = updated
+
+	perpetualPosition := server.perpetualPosition(key, productId, current)
+	changes.updates[key] = append(changes.updates[key], types.AccountUpdate{
+		Type:     constants.ACCOUNT_UPDATE_POSITION,
+		Position: &perpetualPosition,
+	})
+}
+
+// credit adds a signed quantity to a spot balance. The caller must hold the mutex.
+func (server *Server) credit(key subAccountKey, asset string, quantity *big.Int, changes *changes) {
+	state := server.state(key)
+	balance, ok := state.balances[asset]
+	if !ok {
+		balance = new(big.Int)
+		state.balances[asset] = balance
+	}
+	balance.Add(balance, quantity)
+
+	spotBalance := server.spotBalance(key, asset, balance)
+	changes.updates[key] = append(changes.updates[key], types.AccountUpdate{
+		Type:    constants.ACCOUNT_UPDATE_BALANCE,
+		Balance: &spotBalance,
+	})
+}
+
+// withdraw debits a spot balance. The caller must hold the mutex.
+func (server *Server) withdraw(request *withdrawRequest, changes *changes) (*types.SpotBalance, error) {
+	asset := normalizeAddress(request.Asset)
+	if asset != server.usdc {
+		return nil, fmt.Errorf("asset %s is not supported", request.Asset)
+	}
+	quantity, ok := new(big.Int).SetString(request.Quantity, 10)
+	if !ok || quantity.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid quantity %q", request.Quantity)
+	}
+
+	key := subAccountKey{account: request.Account, subAccountId: request.SubAccountId}
+	state := server.state(key)
+	if state.nonces[request.Nonce] {
+		return nil, fmt.Errorf("nonce %d %w", request.Nonce, errNonceUsed)
+	}
+	balance := state.balances[asset]
+	if balance == nil || balance.Cmp(quantity) < 0 {
+		return nil, errInsufficientBalance
+	}
+	state.nonces[request.Nonce] = true
+
+	server.credit(key, asset, new(big.Int).Neg(quantity), changes)
+	spotBalance := server.spotBalance(key, asset, state.balances[asset])
+	return &spotBalance, nil
+}
+
+// setSigner approves or revokes a signer. The caller must hold the mutex.
+func (server *Server) setSigner(request *signerRequest) (*types.ApprovedSigner, error) {
+	key := subAccountKey{account: request.Account, subAccountId: request.SubAccountId}
+	state := server.state(key)
+	if state.nonces[request.Nonce] {
+		return nil, fmt.Errorf("nonce %d %w", request.Nonce, errNonceUsed)
+	}
+	state.nonces[request.Nonce] = true
+
+	signer := normalizeAddress(request.ApprovedSigner)
+	state.signers[signer] = request.IsApproved
+	return &types.ApprovedSigner{
+		Account:    key.account,
+		Subaccount: key.subAccountId,
+		Signer:     signer,
+		Approved:   request.IsApproved,
+	}, nil
+}
+
+// cancelOrder cancels an open order of a sub-account. The caller must hold the mutex.
+func (server *Server) cancelOrder(key subAccountKey, productId int64, orderId string, changes *changes) (*types.Order, error) {
+	server.expireOrders(time.Now().UnixMilli(), changes)
+	cancelled, ok := server.orders[orderId]
+	if !ok || cancelled.key != key || cancelled.ProductId != productId {
+		return nil, fmt.Errorf("order %s %w", orderId, errNotFound)
+	}
+	if cancelled.Status != constants.ORDER_STATUS_OPEN && cancelled.Status != constants.ORDER_STATUS_PARTIALLY_FILLED {
+		return nil, fmt.Errorf("order %s is %s", orderId, cancelled.Status)
+	}
+
+	server.books[productId].remove(cancelled)
+	cancelled.Status = constants.ORDER_STATUS_CANCELLED
+	changes.order(cancelled)
+	return &cancelled.Order, nil
+}
+
+// cancelOpenOrders cancels every open order of a sub-account on a product. The caller must hold the mutex.
+func (server *Server) cancelOpenOrders(key subAccountKey, productId int64, changes *changes) ([]types.Order, error) {
+	if _, ok := server.products[productId]; !ok {
+		return nil, fmt.Errorf("product %d %w", productId, errNotFound)
+	}
+
+	cancelled := []types.Order{}
+	for _, open := range server.openOrders(key, productId, changes) {
+		order, err := server.cancelOrder(key, productId, open.Id, changes)
+		if err != nil {
+			return nil, err
+		}
+		cancelled = append(cancelled, *order)
+	}
+	return cancelled, nil
+}
+
+// openOrders lists the open orders of a sub-account, on every product if productId is 0. The caller must hold the mutex.
+func (server *Server) openOrders(key subAccountKey, productId int64, changes *changes) []types.Order {
+	server.expireOrders(time.Now().UnixMilli(), changes)
+	return server.listOrders(key, productId, nil, func(candidate *order) bool {
+		return candidate.Status == constants.ORDER_STATUS_OPEN || candidate.Status == constants.ORDER_STATUS_PARTIALLY_FILLED
+	})
+}
+
+// listOrders lists the orders of a sub-account by creation, on every product if productId is 0. The caller must hold the mutex.
+func (server *Server) listOrders(key subAccountKey, productId int64, ids []string, filter func(*order) bool) []types.Order {
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	var matching []*order
+	for _, candidate := range server.orders {
+		if candidate.key != key || (productId != 0 && candidate.ProductId != productId) {
+			continue
+		}
+		if len(wanted) > 0 && !wanted[candidate.Id] {
+			continue
+		}
+		if filter != nil && !filter(candidate) {
+			continue
+		}
+		matching = append(matching, candidate)
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		return matching[i].sequence < matching[j].sequence
+	})
+
+	orders := make([]types.Order, 0, len(matching))
+	for _, listed := range matching {
+		orders = append(orders, listed.Order)
+	}
+	return orders
+}
+
+// spotBalances lists the spot balances of a sub-account, restricted to assets if any. The caller must hold the mutex.
+func (server *Server) spotBalances(key subAccountKey, assets []string) []types.SpotBalance {
+	wanted := make(map[string]bool, len(assets))
+	for _, asset := range assets {
+		wanted[normalizeAddress(asset)] = true
+	}
+
+	state := server.state(key)
+	balances := []types.SpotBalance{}
+	for asset, balance := range state.balances {
+		if len(wanted) == 0 || wanted[asset] {
+			balances = append(balances, server.spotBalance(key, asset, balance))
+		}
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		return balances[i].Asset < balances[j].Asset
+	})
+	return balances
+}
+
+// perpetualPositions lists the non-zero positions of a sub-account, restricted to productIds if any. The caller must hold the mutex.
+func (server *Server) perpetualPositions(key subAccountKey, productIds []int64) []types.PerpetualPosition {
+	wanted := make(map[int64]bool, len(productIds))
+	for _, productId := range productIds {
+		wanted[productId] = true
+	}
+
+	state := server.state(key)
+	positions := []types.PerpetualPosition{}
+	for productId, current := range state.positions {
+		if current.quantity.Sign() != 0 && (len(wanted) == 0 || wanted[productId]) {
+			positions = append(positions, server.perpetualPosition(key, productId, current))
+		}
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].ProductId < positions[j].ProductId
+	})
+	return positions
+}
+
+// approvedSigners lists the signers of a sub-account, approved or revoked. The caller must hold the mutex.
+func (server *Server) approvedSigners(key subAccountKey) []types.ApprovedSigner {
+	signers := []types.ApprovedSigner{}
+	for signer, approved := range server.state(key).signers {
+		signers = append(signers, types.ApprovedSigner{
+			Account:    key.account,
+			Subaccount: key.subAccountId,
+			Signer:     signer,
+			Approved:   approved,
+		})
+	}
+	sort.Slice(signers, func(i, j int) bool {
+		return signers[i].Signer < signers[j].Signer
+	})
+	return signers
+}
+
+func (server *Server) spotBalance(key subAccountKey, asset string, balance *big.Int) types.SpotBalance {
+	return types.SpotBalance{
+		Account:           key.account,
+		SubAccountId:      key.subAccountId,
+		Asset:             asset,
+		Quantity:          balance.String(),
+		PendingWithdrawal: "0",
+	}
+}
+
+func (server *Server) perpetualPosition(key subAccountKey, productId int64, current *position) types.PerpetualPosition {
+	return types.PerpetualPosition{
+		Account:        key.account,
+		SubAccountId:   key.subAccountId,
+		ProductId:      productId,
+		Quantity:       current.quantity.String(),
+		AvgEntryPrice:  current.avgEntryPrice.String(),
+		InitCumFunding: "0",
+		Margin:         "0",
+	}
+}
+
+// ticker computes the 24 hour rolling statistics of a product. The caller must hold the mutex.
+func (server *Server) ticker(product *Product) types.Ticker {
+	closeTime := time.Now().UnixMilli()
+	openTime := closeTime - (24 * time.Hour).Milliseconds()
+	ticker := types.Ticker{
+		Symbol:      product.Symbol,
+		ProductId:   product.Id,
+		OpenPrice:   "0",
+		HighPrice:   "0",
+		LowPrice:    "0",
+		LastPrice:   "0",
+		PriceChange: "0",
+		Volume:      "0",
+		OpenTime:    openTime,
+		CloseTime:   closeTime,
+	}
+
+	var window []types.Trade
+	for _, trade := range server.trades[product.Id] {
+		if trade.Time > openTime {
+			window = append(window, trade)
+		}
+	}
+	if len(window) == 0 {
+		return ticker
+	}
+	kline := aggregate(window)
+	ticker.OpenPrice = kline.Open
+	ticker.HighPrice = kline.High
+	ticker.LowPrice = kline.Low
+	ticker.LastPrice = kline.Close
+	ticker.Volume = kline.Volume
+	open, _ := new(big.Int).SetString(kline.Open, 10)
+	last, _ := new(big.Int).SetString(kline.Close, 10)
+	ticker.PriceChange = new(big.Int).Sub(last, open).String()
+	return ticker
+}
+
+// klines builds the klines of a product from its trades, oldest first. The caller must hold the mutex.
+func (server *Server) klines(product *Product, interval types.Interval, startTime int64, endTime int64, limit int64) ([]types.Kline, error) {
+	duration, ok := constants.INTERVAL_DURATIONS[interval]
+	if !ok {
+		return nil, fmt.Errorf("invalid interval %q", interval)
+	}
+	if limit <= 0 {
+		limit = DEFAULT_KLINE_LIMIT
+	}
+	if limit > MAX_KLINE_LIMIT {
+		limit = MAX_KLINE_LIMIT
+	}
+
+	// Bucket trades by open time.
+	width := duration.Milliseconds()
+	buckets := make(map[int64][]types.Trade)
+	var openTimes []int64
+	for _, trade := range server.trades[product.Id] {
+		if (startTime != 0 && trade.Time < startTime) || (endTime != 0 && trade.Time > endTime) {
+			continue
+		}
+		openTime := trade.Time - trade.Time%width
+		if _, ok := buckets[openTime]; !ok {
+			openTimes = append(openTimes, openTime)
+		}
+		buckets[openTime] = append(buckets[openTime], trade)
+	}
+	sort.Slice(openTimes, func(i, j int) bool {
+		return openTimes[i] < openTimes[j]
+	})
+	if int64(len(openTimes)) > limit {
+		openTimes = openTimes[int64(len(openTimes))-limit:]
+	}
+
+	klines := make([]types.Kline, 0, len(openTimes))
+	for _, openTime := range openTimes {
+		kline := aggregate(buckets[openTime])
+		kline.Symbol = product.Symbol
+		kline.Interval = interval
+		kline.OpenTime = openTime
+		kline.CloseTime = openTime + width - 1
+		klines = append(klines, kline)
+	}
+	return klines, nil
+}
+
+// depth builds the order book of a product, grouping prices down to multiples of 10^granularity wei. The caller must hold the mutex.
+func (server *Server) depth(product *Product, limit int64, granularity int64) (*types.OrderBookDepth, error) {
+	if granularity < 0 || granularity > 18 {
+		return nil, fmt.Errorf("invalid granularity %d", granularity)
+	}
+	if limit <= 0 {
+		limit = DEFAULT_DEPTH_LIMIT
+	}
+	bucket := new(big.Int).Exp(big.NewInt(10), big.NewInt(granularity), nil)
+
+	levels := func(orders []*order) [][2]string {
+		result := [][2]string{}
+		var price, quantity *big.Int
+		for _, resting := range orders {
+			levelPrice := new(big.Int).Sub(resting.price, new(big.Int).Mod(resting.price, bucket))
+			if price != nil && price.Cmp(levelPrice) == 0 {
+				quantity.Add(quantity, resting.remaining)
+				continue
+			}
+			if price != nil {
+				result = append(result, [2]string{price.String(), quantity.String()})
+			}
+			price, quantity = levelPrice, new(big.Int).Set(resting.remaining)
+		}
+		if price != nil {
+			result = append(result, [2]string{price.String(), quantity.String()})
+		}
+		if int64(len(result)) > limit {
+			result = result[:limit]
+		}
+		return result
+	}
+
+	book := server.books[product.Id]
+	return &types.OrderBookDepth{
+		Symbol:       product.Symbol,
+		LastUpdateId: server.sequence,
+		Bids:         levels(book.bids),
+		Asks:         levels(book.asks),
+	}, nil
+}
+
+// aggregate computes open, high, low, close, volume and count of chronological trades.
+func aggregate(trades []types.Trade) types.Kline {
+	var open, high, low, close *big.Int
+	volume := new(big.Int)
+	for _, trade := range trades {
+		price, _ := new(big.Int).SetString(trade.Price, 10)
+		quantity, _ := new(big.Int).SetString(trade.Quantity, 10)
+		if open == nil {
+			open, high, low = price, price, price
+		}
+		if price.Cmp(high) > 0 {
+			high = price
+		}
+		if price.Cmp(low) < 0 {
+			low = price
+		}
+		close = price
+		volume.Add(volume, quantity)
+	}
+	return types.Kline{
+		Open:   open.String(),
+		High:   high.String(),
+		Low:    low.String(),
+		Close:  close.String(),
+		Volume: volume.String(),
+		Trades: int64(len(trades)),
+	}
+}
@@ -0,0 +1,448 @@
+package ryskfake
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+// errorResponse is the body of failed REST responses.
+type errorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// serverTimeResponse is the body of `time` responses.
+type serverTimeResponse struct {
+	ServerTime int64 `json:"serverTime"`
+}
+
+// referralResponse is the body of `referral/add-referee` responses.
+type referralResponse struct {
+	Account string `json:"account"`
+	Code    string `json:"code"`
+}
+
+// cancelOrderAndReplaceRequest is the body of `order/cancel-and-replace` requests.
+type cancelOrderAndReplaceRequest struct {
+	IdToCancel string        `json:"idToCancel"`
+	NewOrder   *orderRequest `json:"newOrder"`
+}
+
+// handle adapts a handler returning a JSON result to an `http.HandlerFunc`.
+func (server *Server) handle(handler func(*http.Request) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		result, err := handler(req)
+		status := http.StatusOK
+		if err != nil {
+			status = statusCode(err)
+			result = &errorResponse{Code: status, Message: err.Error()}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(constants.HEADER_REQUEST_ID, strconv.FormatInt(server.requests.Add(1), 10))
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+// statusCode maps an error to its HTTP status.
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, errNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errUnauthorized):
+		return http.StatusUnauthorized
+	}
+	return http.StatusBadRequest
+}
+
+// decodeBody decodes a JSON request body.
+func decodeBody(req *http.Request, body interface{}) error {
+	if err := json.NewDecoder(req.Body).Decode(body); err != nil {
+		return fmt.Errorf("invalid body: %v", err)
+	}
+	return nil
+}
+
+// product resolves a product by symbol.
+func (server *Server) product(symbol string) (*Product, error) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	productId, ok := server.symbols[symbol]
+	if !ok {
+		return nil, fmt.Errorf("product %q %w", symbol, errNotFound)
+	}
+	return server.products[productId], nil
+}
+
+// optionalProductId resolves a product ID from an optional symbol, 0 if empty.
+func (server *Server) optionalProductId(symbol string) (int64, error) {
+	if symbol == "" {
+		return 0, nil
+	}
+	product, err := server.product(symbol)
+	if err != nil {
+		return 0, err
+	}
+	return product.Id, nil
+}
+
+// authenticateQuery authenticates a signed read from its `account`, `subAccountId` and `signature` query parameters.
+func (server *Server) authenticateQuery(req *http.Request) (subAccountKey, error) {
+	query := req.URL.Query()
+	subAccountId, err := strconv.ParseInt(query.Get("subAccountId"), 10, 64)
+	if err != nil {
+		return subAccountKey{}, fmt.Errorf("invalid subAccountId %q", query.Get("subAccountId"))
+	}
+	accountAddress := query.Get("account")
+	message := map[string]interface{}{
+		"account":      accountAddress,
+		"subAccountId": strconv.FormatInt(subAccountId, 10),
+	}
+	if err := server.authenticate(constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION, message, query.Get("signature"), accountAddress, subAccountId); err != nil {
+		return subAccountKey{}, err
+	}
+	return subAccountKey{account: normalizeAddress(accountAddress), subAccountId: subAccountId}, nil
+}
+
+// queryInt64 parses an optional integer query parameter, 0 if empty.
+func queryInt64(req *http.Request, name string) (int64, error) {
+	value := req.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return parsed, nil
+}
+
+func (server *Server) handleTicker(req *http.Request) (interface{}, error) {
+	symbol := req.URL.Query().Get("symbol")
+	if symbol != "" {
+		product, err := server.product(symbol)
+		if err != nil {
+			return nil, err
+		}
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		return server.ticker(product), nil
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	tickers := []types.Ticker{}
+	for _, product := range server.sortedProducts() {
+		tickers = append(tickers, server.ticker(product))
+	}
+	return tickers, nil
+}
+
+func (server *Server) handleGetProduct(req *http.Request) (interface{}, error) {
+	return server.product(req.PathValue("symbol"))
+}
+
+func (server *Server) handleGetProductById(req *http.Request) (interface{}, error) {
+	productId, err := strconv.ParseInt(req.PathValue("id"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid product ID %q", req.PathValue("id"))
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	product, ok := server.products[productId]
+	if !ok {
+		return nil, fmt.Errorf("product %d %w", productId, errNotFound)
+	}
+	return product, nil
+}
+
+func (server *Server) handleKlines(req *http.Request) (interface{}, error) {
+	product, err := server.product(req.URL.Query().Get("symbol"))
+	if err != nil {
+		return nil, err
+	}
+	var bounds [3]int64
+	for i, name := range []string{"startTime", "endTime", "limit"} {
+		if bounds[i], err = queryInt64(req, name); err != nil {
+			return nil, err
+		}
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	return server.klines(product, types.Interval(req.URL.Query().Get("interval")), bounds[0], bounds[1], bounds[2])
+}
+
+func (server *Server) handleListProducts(req *http.Request) (interface{}, error) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	return server.sortedProducts(), nil
+}
+
+func (server *Server) handleOrderBook(req *http.Request) (interface{}, error) {
+	product, err := server.product(req.URL.Query().Get("symbol"))
+	if err != nil {
+		return nil, err
+	}
+	granularity, err := queryInt64(req, "granularity")
+	if err != nil {
+		return nil, err
+	}
+	limit, err := queryInt64(req, "limit")
+	if err != nil {
+		return nil, err
+	}
+
+	changes := newChanges()
+	server.mutex.Lock()
+	server.expireOrders(time.Now().UnixMilli(), changes)
+	depth, err := server.depth(product, limit, granularity)
+	server.mutex.Unlock()
+	server.publish(changes)
+	return depth, err
+}
+
+func (server *Server) handleServerTime(req *http.Request) (interface{}, error) {
+	return &serverTimeResponse{ServerTime: time.Now().UnixMilli()}, nil
+}
+
+func (server *Server) handleApproveRevokeSigner(req *http.Request) (interface{}, error) {
+	var request signerRequest
+	if err := decodeBody(req, &request); err != nil {
+		return nil, err
+	}
+	return server.approveRevokeSigner(&request)
+}
+
+func (server *Server) handleWithdraw(req *http.Request) (interface{}, error) {
+	var request withdrawRequest
+	if err := decodeBody(req, &request); err != nil {
+		return nil, err
+	}
+	return server.placeWithdrawal(&request)
+}
+
+func (server *Server) handleNewOrder(req *http.Request) (interface{}, error) {
+	var request orderRequest
+	if err := decodeBody(req, &request); err != nil {
+		return nil, err
+	}
+	return server.placeOrder(&request)
+}
+
+func (server *Server) handleCancelOrderAndReplace(req *http.Request) (interface{}, error) {
+	var request cancelOrderAndReplaceRequest
+	if err := decodeBody(req, &request); err != nil {
+		return nil, err
+	}
+	if request.NewOrder == nil {
+		return nil, fmt.Errorf("missing new order")
+	}
+	if err := server.authenticate(constants.PRIMARY_TYPE_ORDER, request.NewOrder.message(), request.NewOrder.Signature, request.NewOrder.Account, request.NewOrder.SubAccountId); err != nil {
+		return nil, err
+	}
+	request.NewOrder.Account = normalizeAddress(request.NewOrder.Account)
+	key := subAccountKey{account: request.NewOrder.Account, subAccountId: request.NewOrder.SubAccountId}
+
+	changes := newChanges()
+	server.mutex.Lock()
+	order, err := server.cancelOrder(key, request.NewOrder.ProductId, request.IdToCancel, changes)
+	if err == nil {
+		order, err = server.newOrder(request.NewOrder, changes)
+	}
+	server.mutex.Unlock()
+	server.publish(changes)
+	return order, err
+}
+
+func (server *Server) handleCancelOrder(req *http.Request) (interface{}, error) {
+	var request cancelOrderRequest
+	if err := decodeBody(req, &request); err != nil {
+		return nil, err
+	}
+	if err := server.authenticate(constants.PRIMARY_TYPE_CANCEL_ORDER, request.message(), request.Signature, request.Account, request.SubAccountId); err != nil {
+		return nil, err
+	}
+	key := subAccountKey{account: normalizeAddress(request.Account), subAccountId: request.SubAccountId}
+
+	changes := newChanges()
+	server.mutex.Lock()
+	order, err := server.cancelOrder(key, request.ProductId, request.OrderId, changes)
+	server.mutex.Unlock()
+	server.publish(changes)
+	return order, err
+}
+
+func (server *Server) handleCancelAllOpenOrders(req *http.Request) (interface{}, error) {
+	var request cancelOrdersRequest
+	if err := decodeBody(req, &request); err != nil {
+		return nil, err
+	}
+	if err := server.authenticate(constants.PRIMARY_TYPE_CANCEL_ORDERS, request.message(), request.Signature, request.Account, request.SubAccountId); err != nil {
+		return nil, err
+	}
+	return server.cancelAllOpenOrders(subAccountKey{account: normalizeAddress(request.Account), subAccountId: request.SubAccountId}, request.ProductId)
+}
+
+func (server *Server) handleAddReferee(req *http.Request) (interface{}, error) {
+	var request referralRequest
+	if err := decodeBody(req, &request); err != nil {
+		return nil, err
+	}
+	if err := server.authenticate(constants.PRIMARY_TYPE_REFERRAL, request.message(), request.Signature, request.Account, 0); err != nil {
+		return nil, err
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	server.state(subAccountKey{account: normalizeAddress(request.Account)}).referral = request.Code
+	return &referralResponse{Account: normalizeAddress(request.Account), Code: request.Code}, nil
+}
+
+func (server *Server) handleSpotBalances(req *http.Request) (interface{}, error) {
+	key, err := server.authenticateQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	return server.spotBalances(key, nil), nil
+}
+
+func (server *Server) handlePerpetualPositions(req *http.Request) (interface{}, error) {
+	key, err := server.authenticateQuery(req)
+	if err != nil {
+		return nil, err
+	}
+	productId, err := server.optionalProductId(req.URL.Query().Get("symbol"))
+	if err != nil {
+		return nil, err
+	}
+	var productIds []int64
+	if productId != 0 {
+		productIds = append(productIds, productId)
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	return server.perpetualPositions(key, productIds), nil
+}
+
+func (server *Server) handleListApprovedSigners(req *http.Request) (interface{}, error) {
+	key, err := server.authenticateQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	return server.approvedSigners(key), nil
+}
+
+func (server *Server) handleListOpenOrders(req *http.Request) (interface{}, error) {
+	key, err := server.authenticateQuery(req)
+	if err != nil {
+		return nil, err
+	}
+	productId, err := server.optionalProductId(req.URL.Query().Get("symbol"))
+	if err != nil {
+		return nil, err
+	}
+
+	changes := newChanges()
+	server.mutex.Lock()
+	orders := server.openOrders(key, productId, changes)
+	server.mutex.Unlock()
+	server.publish(changes)
+	return orders, nil
+}
+
+func (server *Server) handleListOrders(req *http.Request) (interface{}, error) {
+	key, err := server.authenticateQuery(req)
+	if err != nil {
+		return nil, err
+	}
+	productId, err := server.optionalProductId(req.URL.Query().Get("symbol"))
+	if err != nil {
+		return nil, err
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	return server.listOrders(key, productId, req.URL.Query()["ids"], nil), nil
+}
+
+// placeOrder authenticates and places an order, publishing its effects.
+func (server *Server) placeOrder(request *orderRequest) (*types.Order, error) {
+	if err := server.authenticate(constants.PRIMARY_TYPE_ORDER, request.message(), request.Signature, request.Account, request.SubAccountId); err != nil {
+		return nil, err
+	}
+	request.Account = normalizeAddress(request.Account)
+
+	changes := newChanges()
+	server.mutex.Lock()
+	order, err := server.newOrder(request, changes)
+	server.mutex.Unlock()
+	server.publish(changes)
+	return order, err
+}
+
+// placeWithdrawal authenticates and applies a withdrawal, publishing its effects.
+func (server *Server) placeWithdrawal(request *withdrawRequest) (*types.SpotBalance, error) {
+	if err := server.authenticate(constants.PRIMARY_TYPE_WITHDRAW, request.message(), request.Signature, request.Account, request.SubAccountId); err != nil {
+		return nil, err
+	}
+	request.Account = normalizeAddress(request.Account)
+
+	changes := newChanges()
+	server.mutex.Lock()
+	balance, err := server.withdraw(request, changes)
+	server.mutex.Unlock()
+	server.publish(changes)
+	return balance, err
+}
+
+// approveRevokeSigner authenticates and applies a signer approval or revocation.
+func (server *Server) approveRevokeSigner(request *signerRequest) (*types.ApprovedSigner, error) {
+	if err := server.authenticate(constants.PRIMARY_TYPE_APPROVE_SIGNER, request.message(), request.Signature, request.Account, request.SubAccountId); err != nil {
+		return nil, err
+	}
+	request.Account = normalizeAddress(request.Account)
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	return server.setSigner(request)
+}
+
+// cancelAllOpenOrders cancels the open orders of an authenticated sub-account, publishing its effects.
+func (server *Server) cancelAllOpenOrders(key subAccountKey, productId int64) ([]types.Order, error) {
+	changes := newChanges()
+	server.mutex.Lock()
+	orders, err := server.cancelOpenOrders(key, productId, changes)
+	server.mutex.Unlock()
+	server.publish(changes)
+	return orders, err
+}
+
+// sortedProducts lists the products by ID. The caller must hold the mutex.
+func (server *Server) sortedProducts() []*Product {
+	products := make([]*Product, 0, len(server.products))
+	for _, product := range server.products {
+		products = append(products, product)
+	}
+	sort.Slice(products, func(i, j int) bool {
+		return products[i].Id < products[j].Id
+	})
+	return products
+}
@@ -0,0 +1,302 @@
+package ryskfake
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/gorilla/websocket"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/typed_data"
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+const (
+	API_PATH_PREFIX     = "/v1"            // API_PATH_PREFIX is the path prefix of the REST API, as in `constants.API_BASE_URL`.
+	WS_RPC_PATH         = "/v1/ws/operate" // WS_RPC_PATH is the path of the RPC websocket, as in `constants.WS_RPC_URL`.
+	WS_STREAM_PATH      = "/stream"        // WS_STREAM_PATH is the path of the stream websocket.
+	DEFAULT_KLINE_LIMIT = 500              // DEFAULT_KLINE_LIMIT is the number of klines returned when no limit is requested.
+	MAX_KLINE_LIMIT     = 1000             // MAX_KLINE_LIMIT is the maximum number of klines returned.
+	DEFAULT_DEPTH_LIMIT = 100              // DEFAULT_DEPTH_LIMIT is the number of book levels returned when no limit is requested.
+	LOGIN_MAX_AGE       = 10 * time.Second // LOGIN_MAX_AGE is how old a `session.login` timestamp may be.
+)
+
+// DEFAULT_PRODUCTS are the products listed when none are configured.
+var DEFAULT_PRODUCTS = []Product{
+	{Id: constants.PRODUCT_ETH_PERP.Id, Symbol: constants.PRODUCT_ETH_PERP.Symbol, Increment: constants.E16.String(), IsActive: true},
+	{Id: constants.PRODUCT_BTC_PERP.Id, Symbol: constants.PRODUCT_BTC_PERP.Symbol, Increment: constants.E18.String(), IsActive: true},
+	{Id: constants.PRODUCT_SOL_PERP.Id, Symbol: constants.PRODUCT_SOL_PERP.Symbol, Increment: constants.E15.String(), IsActive: true},
+}
+
+// Product is a product listed on the fake exchange.
+type Product struct {
+	Id        int64  `json:"id"`        // The ID of the product.
+	Symbol    string `json:"symbol"`    // The symbol of the product.
+	Increment string `json:"increment"` // Price increment in wei (e18).
+	IsActive  bool   `json:"isActive"`  // Whether the product accepts orders.
+}
+
+// ServerConfiguration represents configuration settings for the fake exchange server.
+type ServerConfiguration struct {
+	Env      types.Environment // Env selects the EIP-712 domain and USDC address, defaults to `constants.ENVIRONMENT_TESTNET`.
+	Products []Product         // Products lists the products to serve, defaults to `DEFAULT_PRODUCTS`.
+}
+
+// Server is an in-process fake of the Rysk V2 exchange serving REST, RPC websocket and stream websocket APIs.
+type Server struct {
+	server   *httptest.Server                   // server is the underlying HTTP test server.
+	domain   apitypes.TypedDataDomain           // domain is the EIP-712 domain signatures are verified against.
+	usdc     string                             // usdc is the lowercase USDC address, the only spot asset.
+	upgrader websocket.Upgrader                 // upgrader upgrades websocket requests.
+	mutex    sync.Mutex                         // mutex guards the exchange state below.
+	products map[int64]*Product                 // products holds the listed products by ID.
+	symbols  map[string]int64                   // symbols maps product symbols to IDs.
+	books    map[int64]*book                    // books holds the order books by product ID.
+	orders   map[string]*order                  // orders holds every order by ID.
+	trades   map[int64][]types.Trade            // trades holds the trade history by product ID.
+	accounts map[subAccountKey]*subAccountState // accounts holds the sub-account states.
+	sequence int64                              // sequence numbers orders, trades and book updates.
+	requests atomic.Int64                       // requests numbers REST requests.
+
+	connectionsMutex  sync.Mutex               // connectionsMutex guards the connection sets.
+	rpcConnections    map[*connection]struct{} // rpcConnections holds the open RPC websocket connections.
+	streamConnections map[*connection]struct{} // streamConnections holds the open stream websocket connections.
+}
+
+// subAccountKey identifies a sub-account by lowercase account address and sub-account ID.
+type subAccountKey struct {
+	account      string
+	subAccountId int64
+}
+
+// subAccountState is the state of a sub-account.
+type subAccountState struct {
+	balances  map[string]*big.Int // balances holds spot balances by lowercase asset address.
+	positions map[int64]*position // positions holds perpetual positions by product ID.
+	signers   map[string]bool     // signers holds signer approvals by lowercase address.
+	nonces    map[int64]bool      // nonces holds the nonces already used.
+	referral  string              // referral is the referral code the account registered with.
+}
+
+// NewServer creates and starts a new fake exchange server on a local port.
+//
+// Parameters:
+//   - config: A pointer to ServerConfiguration containing the configuration settings.
+//
+// Returns:
+//   - A pointer to Server, to be closed with `Close`.
+//   - An error if the environment is unknown or if a product is invalid.
+func NewServer(config *ServerConfiguration) (*Server, error) {
+	env := config.Env
+	if env == "" {
+		env = constants.ENVIRONMENT_TESTNET
+	}
+	if _, ok := constants.CHAIN_ID[env]; !ok {
+		return nil, fmt.Errorf("unknown environment %q", env)
+	}
+	products := config.Products
+	if len(products) == 0 {
+		products = DEFAULT_PRODUCTS
+	}
+
+	server := &Server{
+		domain:            typed_data.Domain(env),
+		usdc:              strings.ToLower(constants.USDC_ADDRESS[env]),
+		products:          make(map[int64]*Product),
+		symbols:           make(map[string]int64),
+		books:             make(map[int64]*book),
+		orders:            make(map[string]*order),
+		trades:            make(map[int64][]types.Trade),
+		accounts:          make(map[subAccountKey]*subAccountState),
+		rpcConnections:    make(map[*connection]struct{}),
+		streamConnections: make(map[*connection]struct{}),
+	}
+
+	// Index products.
+	for _, product := range products {
+		increment, ok := new(big.Int).SetString(product.Increment, 10)
+		if !ok || increment.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid increment %q for product %s", product.Increment, product.Symbol)
+		}
+		product := product
+		server.products[product.Id] = &product
+		server.symbols[product.Symbol] = product.Id
+		server.books[product.Id] = &book{}
+	}
+
+	server.server = httptest.NewServer(server.routes())
+	return server, nil
+}
+
+// URL returns the REST API base URL, to be used as `BaseUrl` in client configurations.
+func (server *Server) URL() string {
+	return server.server.URL + API_PATH_PREFIX
+}
+
+// RPCURL returns the RPC websocket URL, to be used as `WSRpcUrl` in client configurations.
+func (server *Server) RPCURL() string {
+	return "ws" + strings.TrimPrefix(server.server.URL, "http") + WS_RPC_PATH
+}
+
+// StreamURL returns the stream websocket URL, to be used as `WSStreamUrl` in client configurations.
+func (server *Server) StreamURL() string {
+	return "ws" + strings.TrimPrefix(server.server.URL, "http") + WS_STREAM_PATH
+}
+
+// Close closes every websocket connection and shuts the server down.
+func (server *Server) Close() {
+	server.Disconnect()
+	server.server.Close()
+}
+
+// Disconnect closes every websocket connection while the server keeps accepting new ones,
+// as a dropped connection would.
+func (server *Server) Disconnect() {
+	server.connectionsMutex.Lock()
+	defer server.connectionsMutex.Unlock()
+	for connection := range server.rpcConnections {
+		connection.conn.Close()
+	}
+	for connection := range server.streamConnections {
+		connection.conn.Close()
+	}
+}
+
+// Credit adds a spot balance to a sub-account, as an on-chain deposit would.
+//
+// Parameters:
+//   - accountAddress: The account address.
+//   - subAccountId: The ID of the sub-account.
+//   - asset: The asset address.
+//   - quantity: The quantity in wei (e18), negative to debit.
+func (server *Server) Credit(accountAddress common.Address, subAccountId uint8, asset common.Address, quantity *big.Int) {
+	changes := newChanges()
+	server.mutex.Lock()
+	key := subAccountKey{account: strings.ToLower(accountAddress.Hex()), subAccountId: int64(subAccountId)}
+	server.credit(key, strings.ToLower(asset.Hex()), quantity, changes)
+	server.mutex.Unlock()
+	server.publish(changes)
+}
+
+// Balance returns the spot balance of a sub-account.
+//
+// Parameters:
+//   - accountAddress: The account address.
+//   - subAccountId: The ID of the sub-account.
+//   - asset: The asset address.
+//
+// Returns:
+//   - The balance in wei (e18).
+func (server *Server) Balance(accountAddress common.Address, subAccountId uint8, asset common.Address) *big.Int {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	key := subAccountKey{account: strings.ToLower(accountAddress.Hex()), subAccountId: int64(subAccountId)}
+	if balance, ok := server.state(key).balances[strings.ToLower(asset.Hex())]; ok {
+		return new(big.Int).Set(balance)
+	}
+	return new(big.Int)
+}
+
+// Position returns the signed perpetual position of a sub-account.
+//
+// Parameters:
+//   - accountAddress: The account address.
+//   - subAccountId: The ID of the sub-account.
+//   - productId: The ID of the product.
+//
+// Returns:
+//   - The position size in wei (e18), negative for shorts.
+func (server *Server) Position(accountAddress common.Address, subAccountId uint8, productId int64) *big.Int {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	key := subAccountKey{account: strings.ToLower(accountAddress.Hex()), subAccountId: int64(subAccountId)}
+	if position, ok := server.state(key).positions[productId]; ok {
+		return new(big.Int).Set(position.quantity)
+	}
+	return new(big.Int)
+}
+
+// routes registers the REST and websocket handlers.
+func (server *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	route := func(method string, endpoint types.APIEndpoint, handler func(*http.Request) (interface{}, error)) {
+		mux.HandleFunc(method+" "+API_PATH_PREFIX+string(endpoint), server.handle(handler))
+	}
+
+	// Public endpoints.
+	route(http.MethodGet, constants.API_ENDPOINT_GET_24H_TICKER_PRICE_CHANGE_STATISTICS, server.handleTicker)
+	route(http.MethodGet, constants.API_ENDPOINT_GET_PRODUCT+"{symbol}", server.handleGetProduct)
+	route(http.MethodGet, constants.API_ENDPOINT_GET_PRODUCT_BY_ID+"{id}", server.handleGetProductById)
+	route(http.MethodGet, constants.API_ENDPOINT_GET_KLINE_DATA, server.handleKlines)
+	route(http.MethodGet, constants.API_ENDPOINT_LIST_PRODUCTS, server.handleListProducts)
+	route(http.MethodGet, constants.API_ENDPOINT_ORDER_BOOK, server.handleOrderBook)
+	route(http.MethodGet, constants.API_ENDPOINT_SERVER_TIME, server.handleServerTime)
+
+	// Signed endpoints.
+	route(http.MethodPost, constants.API_ENDPOINT_APPROVE_REVOKE_SIGNER, server.handleApproveRevokeSigner)
+	route(http.MethodPost, constants.API_ENDPOINT_WITHDRAW, server.handleWithdraw)
+	route(http.MethodPost, constants.API_ENDPOINT_NEW_ORDER, server.handleNewOrder)
+	route(http.MethodPost, constants.API_ENDPOINT_CANCEL_REPLACE_ORDER, server.handleCancelOrderAndReplace)
+	route(http.MethodDelete, constants.API_ENDPOINT_CANCEL_ORDER, server.handleCancelOrder)
+	route(http.MethodDelete, constants.API_ENDPOINT_CANCEL_ALL_OPEN_ORDERS, server.handleCancelAllOpenOrders)
+	route(http.MethodPost, constants.API_ENDPOINT_ADD_REFEREE, server.handleAddReferee)
+
+	// Authenticated reads.
+	route(http.MethodGet, constants.API_ENDPOINT_GET_SPOT_BALANCES, server.handleSpotBalances)
+	route(http.MethodGet, constants.API_ENDPOINT_GET_PERPETUAL_POSITION, server.handlePerpetualPositions)
+	route(http.MethodGet, constants.API_ENDPOINT_LIST_APPROVED_SIGNERS, server.handleListApprovedSigners)
+	route(http.MethodGet, constants.API_ENDPOINT_LIST_OPEN_ORDERS, server.handleListOpenOrders)
+	route(http.MethodGet, constants.API_ENDPOINT_LIST_ORDERS, server.handleListOrders)
+
+	// Websockets.
+	mux.HandleFunc(WS_RPC_PATH, server.handleRPCWebsocket)
+	mux.HandleFunc(WS_STREAM_PATH, server.handleStreamWebsocket)
+	mux.HandleFunc(WS_STREAM_PATH+"/", server.handleStreamWebsocket)
+	return mux
+}
+
+// state returns the state of a sub-account, creating it if needed. The caller must hold the mutex.
+func (server *Server) state(key subAccountKey) *subAccountState {
+	if state, ok := server.accounts[key]; ok {
+		return state
+	}
+	state := &subAccountState{
+		balances:  make(map[string]*big.Int),
+		positions: make(map[int64]*position),
+		signers:   make(map[string]bool),
+		nonces:    make(map[int64]bool),
+	}
+	server.accounts[key] = state
+	return state
+}
+
+// subAccounts lists the sub-accounts of an account known to the server, always including sub-account 0.
+func (server *Server) subAccounts(accountAddress string) []types.SubAccount {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	subAccountIds := map[int64]bool{0: true}
+	for key := range server.accounts {
+		if key.account == accountAddress {
+			subAccountIds[key.subAccountId] = true
+		}
+	}
+	subAccounts := make([]types.SubAccount, 0, len(subAccountIds))
+	for subAccountId := range subAccountIds {
+		subAccounts = append(subAccounts, types.SubAccount{Account: accountAddress, SubAccountId: subAccountId})
+	}
+	sort.Slice(subAccounts, func(i, j int) bool {
+		return subAccounts[i].SubAccountId < subAccounts[j].SubAccountId
+	})
+	return subAccounts
+}
@@ -0,0 +1,498 @@
+//go:build !integration
+// +build !integration
+
+package ryskfake
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gorilla/websocket"
+	"github.com/rysk-finance/v2_client_go/api_client"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+	"github.com/rysk-finance/v2_client_go/ws_client"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type RyskFakeUnitTestSuite struct {
+	suite.Suite
+	Server *Server
+	Maker  *api_client.RyskV2APIClient
+	Taker  *api_client.RyskV2APIClient
+	nonce  int64
+}
+
+func (s *RyskFakeUnitTestSuite) SetupTest() {
+	server, err := NewServer(&ServerConfiguration{})
+	require.NoError(s.T(), err)
+	s.Server = server
+	s.Maker = s.newAPIClient(newPrivateKey(s.T()))
+	s.Taker = s.newAPIClient(newPrivateKey(s.T()))
+}
+
+func (s *RyskFakeUnitTestSuite) TearDownTest() {
+	s.Server.Close()
+}
+
+func TestRunSuiteUnit_RyskFakeUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(RyskFakeUnitTestSuite))
+}
+
+func newPrivateKey(t *testing.T) string {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return hex.EncodeToString(crypto.FromECDSA(privateKey))
+}
+
+func (s *RyskFakeUnitTestSuite) newAPIClient(privateKey string) *api_client.RyskV2APIClient {
+	client, err := api_client.NewRyskV2APIClient(&api_client.RyskV2APIClientConfiguration{
+		Env:        constants.ENVIRONMENT_TESTNET,
+		PrivateKey: privateKey,
+		RpcUrl:     s.Server.server.URL,
+		BaseUrl:    s.Server.URL(),
+	})
+	require.NoError(s.T(), err)
+	return client
+}
+
+func (s *RyskFakeUnitTestSuite) newOrder(price *big.Int, quantity *big.Int, isBuy bool, orderType types.OrderType, timeInForce types.TimeInForce) *types.NewOrderRequest {
+	s.nonce++
+	return &types.NewOrderRequest{
+		Product:     &constants.PRODUCT_ETH_PERP,
+		IsBuy:       isBuy,
+		OrderType:   orderType,
+		TimeInForce: timeInForce,
+		Price:       price.String(),
+		Quantity:    quantity.String(),
+		Expiration:  time.Now().Add(time.Hour).UnixMilli(),
+		Nonce:       s.nonce,
+	}
+}
+
+func (s *RyskFakeUnitTestSuite) placeOrder(client *api_client.RyskV2APIClient, params *types.NewOrderRequest) types.Order {
+	res, err := client.NewOrder(params)
+	require.NoError(s.T(), err)
+	var order types.Order
+	require.NoError(s.T(), utils.DecodeHTTPResponse(res, &order))
+	return order
+}
+
+func price(units int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(units), constants.E18)
+}
+
+func (s *RyskFakeUnitTestSuite) TestUnit_NewServer_UnknownEnvironment() {
+	server, err := NewServer(&ServerConfiguration{Env: "devnet"})
+	require.Error(s.T(), err)
+	require.Nil(s.T(), server)
+}
+
+func (s *RyskFakeUnitTestSuite) TestUnit_NewServer_InvalidIncrement() {
+	server, err := NewServer(&ServerConfiguration{Products: []Product{{Id: 1, Symbol: "x", Increment: "0"}}})
+	require.Error(s.T(), err)
+	require.Nil(s.T(), server)
+}
+
+func (s *RyskFakeUnitTestSuite) TestUnit_PublicEndpoints() {
+	var products []Product
+	res, err := s.Maker.ListProducts()
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), utils.DecodeHTTPResponse(res, &products))
+	require.Len(s.T(), products, len(DEFAULT_PRODUCTS))
+
+	var product Product
+	res, err = s.Maker.GetProduct(constants.PRODUCT_BTC_PERP.Symbol)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), utils.DecodeHTTPResponse(res, &product))
+	require.Equal(s.T(), constants.PRODUCT_BTC_PERP.Id, product.Id)
+
+	res, err = s.Maker.GetProductById(constants.PRODUCT_SOL_PERP.Id)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), utils.DecodeHTTPResponse(res, &product))
+	require.Equal(s.T(), constants.PRODUCT_SOL_PERP.Symbol, product.Symbol)
+
+	res, err = s.Maker.GetProductById(69420)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), http.StatusNotFound, res.StatusCode)
+	var apiError *utils.APIError
+	require.ErrorAs(s.T(), utils.DecodeHTTPResponse(res, &product), &apiError)
+	require.NotEmpty(s.T(), apiError.RequestID)
+
+	var tickers []types.Ticker
+	res, err = s.Maker.Get24hrPriceChangeStatistics(&types.Product{})
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), utils.DecodeHTTPResponse(res, &tickers))
+	require.Len(s.T(), tickers, len(DEFAULT_PRODUCTS))
+
+	var serverTime serverTimeResponse
+	res, err = s.Maker.ServerTime()
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), utils.DecodeHTTPResponse(res, &serverTime))
+	require.NotZero(s.T(), serverTime.ServerTime)
+}
+
+func (s *RyskFakeUnitTestSuite) TestUnit_Matching() {
+	maker := s.placeOrder(s.Maker, s.newOrder(price(3000), constants.E18, false, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC))
+	require.Equal(s.T(), constants.ORDER_STATUS_OPEN, maker.Status)
+
+	// Cross with a better price, filling at the resting price.
+	taker := s.placeOrder(s.Taker, s.newOrder(price(3100), constants.E17, true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC))
+	require.Equal(s.T(), constants.ORDER_STATUS_FILLED, taker.Status)
+	require.Equal(s.T(), constants.E17.String(), taker.Filled)
+
+	require.Equal(s.T(), constants.E17, s.Server.Position(s.Taker.Address(), 0, constants.PRODUCT_ETH_PERP.Id))
+	require.Equal(s.T(), new(big.Int).Neg(constants.E17), s.Server.Position(s.Maker.Address(), 0, constants.PRODUCT_ETH_PERP.Id))
+
+	// Open orders reflect the partial fill.
+	var orders []types.Order
+	res, err := s.Maker.ListOpenOrders(&constants.PRODUCT_ETH_PERP)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), utils.DecodeHTTPResponse(res, &orders))
+	require.Len(s.T(), orders, 1)
+	require.Equal(s.T(), constants.ORDER_STATUS_PARTIALLY_FILLED, orders[0].Status)
+
+	// Positions, book, ticker and klines follow the trade.
+	var positions []types.PerpetualPosition
+	res, err = s.Taker.GetPerpetualPositionAllProducts()
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), utils.DecodeHTTPResponse(res, &positions))
+	require.Len(s.T(), positions, 1)
+	require.Equal(s.T(), price(3000).String(), positions[0].AvgEntryPrice)
+
+	var depth types.OrderBookDepth
+	res, err = s.Maker.OrderBook(&types.OrderBookRequest{Product: &constants.PRODUCT_ETH_PERP, Limit: constants.LIMIT_FIVE})
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), utils.DecodeHTTPResponse(res, &depth))
+	require.Empty(s.T(), depth.Bids)
+	require.Equal(s.T(), [][2]string{{price(3000).String(), new(big.Int).Sub(constants.E18, constants.E17).String()}}, depth.Asks)
+
+	var ticker types.Ticker
+	res, err = s.Maker.Get24hrPriceChangeStatistics(&constants.PRODUCT_ETH_PERP)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), utils.DecodeHTTPResponse(res, &ticker))
+	require.Equal(s.T(), price(3000).String(), ticker.LastPrice)
+	require.Equal(s.T(), constants.E17.String(), ticker.Volume)
+
+	var klines []types.Kline
+	res, err = s.Maker.GetKlineData(&types.KlineDataRequest{Product: &constants.PRODUCT_ETH_PERP, Interval: constants.INTERVAL_1M})
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), utils.DecodeHTTPResponse(res, &klines))
+	require.Len(s.T(), klines, 1)
+	require.Equal(s.T(), int64(1), klines[0].Trades)
+}
+
+func (s *RyskFakeUnitTestSuite) TestUnit_RealizedPnL() {
+	s.placeOrder(s.Maker, s.newOrder(price(3000), constants.E18, false, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC))
+	s.placeOrder(s.Taker, s.newOrder(price(3000), constants.E18, true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC))
+	s.placeOrder(s.Maker, s.newOrder(price(3100), constants.E18, true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC))
+	s.placeOrder(s.Taker, s.newOrder(price(3100), constants.E18, false, constants.ORDER_TYPE_MARKET, constants.TIME_IN_FORCE_IOC))
+
+	usdc := s.Taker.USDCAddress()
+	require.Equal(s.T(), price(100), s.Server.Balance(s.Taker.Address(), 0, usdc))
+	require.Equal(s.T(), price(-100), s.Server.Balance(s.Maker.Address(), 0, usdc))
+	require.Zero(s.T(), s.Server.Position(s.Taker.Address(), 0, constants.PRODUCT_ETH_PERP.Id).Sign())
+}
+
+func (s *RyskFakeUnitTestSuite) TestUnit_TimeInForce() {
+	s.placeOrder(s.Maker, s.newOrder(price(3000), constants.E17, false, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC))
+
+	// Fill or kill larger than the book is expired untouched.
+	order := s.placeOrder(s.Taker, s.newOrder(price(3000), constants.E18, true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_FOK))
+	require.Equal(s.T(), constants.ORDER_STATUS_EXPIRED, order.Status)
+	require.Equal(s.T(), "0", order.Filled)
+
+	// Immediate or cancel fills what it can.
+	order = s.placeOrder(s.Taker, s.newOrder(price(3000), constants.E18, true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_IOC))
+	require.Equal(s.T(), constants.ORDER_STATUS_CANCELLED, order.Status)
+	require.Equal(s.T(), constants.E17.String(), order.Filled)
+
+	// Limit maker rejects crossing orders.
+	s.placeOrder(s.Maker, s.newOrder(price(3000), constants.E17, false, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC))
+	res, err := s.Taker.NewOrder(s.newOrder(price(3000), constants.E17, true, constants.ORDER_TYPE_LIMIT_MAKER, constants.TIME_IN_FORCE_GTC))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), http.StatusBadRequest, res.StatusCode)
+}
+
+func (s *RyskFakeUnitTestSuite) TestUnit_NewOrder_Invalid() {
+	// Price off the increment.
+	res, err := s.Maker.NewOrder(s.newOrder(big.NewInt(1), constants.E17, true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), http.StatusBadRequest, res.StatusCode)
+
+	// Reused nonce.
+	params := s.newOrder(price(3000), constants.E17, true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC)
+	s.placeOrder(s.Maker, params)
+	res, err = s.Maker.NewOrder(params)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), http.StatusBadRequest, res.StatusCode)
+
+	// Unsupported order type.
+	res, err = s.Maker.NewOrder(s.newOrder(price(3000), constants.E17, true, constants.ORDER_TYPE_STOP_LOSS, constants.TIME_IN_FORCE_GTC))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), http.StatusBadRequest, res.StatusCode)
+}
+
+func (s *RyskFakeUnitTestSuite) TestUnit_Cancel() {
+	first := s.placeOrder(s.Maker, s.newOrder(price(2900), constants.E17, true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC))
+	s.placeOrder(s.Maker, s.newOrder(price(2800), constants.E17, true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC))
+
+	// Other accounts cannot cancel the order.
+	res, err := s.Taker.CancelOrder(&types.CancelOrderRequest{Product: &constants.PRODUCT_ETH_PERP, IdToCancel: first.Id})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), http.StatusNotFound, res.StatusCode)
+
+	var cancelled types.Order
+	res, err = s.Maker.CancelOrder(&types.CancelOrderRequest{Product: &constants.PRODUCT_ETH_PERP, IdToCancel: first.Id})
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), utils.DecodeHTTPResponse(res, &cancelled))
+	require.Equal(s.T(), constants.ORDER_STATUS_CANCELLED, cancelled.Status)
+
+	// Replace the cancelled order fails, the open one succeeds.
+	res, err = s.Maker.CancelOrderAndReplace(&types.CancelOrderAndReplaceRequest{
+		IdToCancel: first.Id,
+		NewOrder:   s.newOrder(price(2700), constants.E17, true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC),
+	})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), http.StatusBadRequest, res.StatusCode)
+
+	var allCancelled []types.Order
+	res, err = s.Maker.CancelAllOpenOrders(&constants.PRODUCT_ETH_PERP)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), utils.DecodeHTTPResponse(res, &allCancelled))
+	require.Len(s.T(), allCancelled, 1)
+
+	var orders []types.Order
+	res, err = s.Maker.ListOrders(&types.ListOrdersRequest{Product: &constants.PRODUCT_ETH_PERP, Ids: []string{first.Id}})
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), utils.DecodeHTTPResponse(res, &orders))
+	require.Len(s.T(), orders, 1)
+	require.Equal(s.T(), first.Id, orders[0].Id)
+}
+
+func (s *RyskFakeUnitTestSuite) TestUnit_Withdraw() {
+	usdc := s.Maker.USDCAddress()
+	s.Server.Credit(s.Maker.Address(), 0, usdc, constants.E20)
+
+	var balance types.SpotBalance
+	res, err := s.Maker.Withdraw(&types.WithdrawRequest{Quantity: constants.E19.String(), Nonce: 1})
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), utils.DecodeHTTPResponse(res, &balance))
+	require.Equal(s.T(), new(big.Int).Sub(constants.E20, constants.E19).String(), balance.Quantity)
+
+	var balances []types.SpotBalance
+	res, err = s.Maker.GetSpotBalances()
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), utils.DecodeHTTPResponse(res, &balances))
+	require.Equal(s.T(), []types.SpotBalance{balance}, balances)
+
+	res, err = s.Maker.Withdraw(&types.WithdrawRequest{Quantity: constants.E21.String(), Nonce: 2})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), http.StatusBadRequest, res.StatusCode)
+}
+
+func (s *RyskFakeUnitTestSuite) TestUnit_ApprovedSigners() {
+	res, err := s.Maker.ApproveSigner(&types.ApproveRevokeSignerRequest{ApprovedSigner: s.Taker.Address().Hex(), Nonce: 1})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), http.StatusOK, res.StatusCode)
+
+	var signers []types.ApprovedSigner
+	res, err = s.Maker.ListApprovedSigners()
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), utils.DecodeHTTPResponse(res, &signers))
+	require.Len(s.T(), signers, 1)
+	require.Equal(s.T(), strings.ToLower(s.Taker.Address().Hex()), signers[0].Signer)
+	require.True(s.T(), signers[0].Approved)
+
+	res, err = s.Maker.RevokeSigner(&types.ApproveRevokeSignerRequest{ApprovedSigner: s.Taker.Address().Hex(), Nonce: 2})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), http.StatusOK, res.StatusCode)
+
+	res, err = s.Maker.ListApprovedSigners()
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), utils.DecodeHTTPResponse(res, &signers))
+	require.False(s.T(), signers[0].Approved)
+}
+
+func (s *RyskFakeUnitTestSuite) TestUnit_Authenticate() {
+	makerKey, takerKey := newPrivateKey(s.T()), newPrivateKey(s.T())
+	maker := common.HexToAddress(utils.AddressFromPrivateKey(makerKey))
+	taker := common.HexToAddress(utils.AddressFromPrivateKey(takerKey))
+	message := map[string]interface{}{"account": maker.Hex(), "subAccountId": "0"}
+
+	sign := func(privateKey string) string {
+		signature, err := utils.SignMessage(s.Server.domain, privateKey, constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION, message)
+		require.NoError(s.T(), err)
+		return signature
+	}
+
+	// The account itself.
+	require.NoError(s.T(), s.Server.authenticate(constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION, message, sign(makerKey), maker.Hex(), 0))
+
+	// Another key, before and after approval.
+	err := s.Server.authenticate(constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION, message, sign(takerKey), maker.Hex(), 0)
+	require.ErrorIs(s.T(), err, errUnauthorized)
+	s.Server.mutex.Lock()
+	s.Server.state(subAccountKey{account: strings.ToLower(maker.Hex())}).signers[strings.ToLower(taker.Hex())] = true
+	s.Server.mutex.Unlock()
+	require.NoError(s.T(), s.Server.authenticate(constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION, message, sign(takerKey), maker.Hex(), 0))
+
+	// Approved signers cannot approve other signers.
+	err = s.Server.authenticate(constants.PRIMARY_TYPE_APPROVE_SIGNER, message, sign(takerKey), maker.Hex(), 0)
+	require.ErrorIs(s.T(), err, errUnauthorized)
+
+	// Malformed signature.
+	err = s.Server.authenticate(constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION, message, "0x1234", maker.Hex(), 0)
+	require.ErrorIs(s.T(), err, errUnauthorized)
+}
+
+func (s *RyskFakeUnitTestSuite) TestUnit_UnsignedRead() {
+	res, err := http.Get(s.Server.URL() + string(constants.API_ENDPOINT_GET_SPOT_BALANCES) + "?account=" + s.Maker.Address().Hex() + "&subAccountId=0&signature=0x00")
+	require.NoError(s.T(), err)
+	defer res.Body.Close()
+	require.Equal(s.T(), http.StatusUnauthorized, res.StatusCode)
+}
+
+func (s *RyskFakeUnitTestSuite) TestUnit_Websocket() {
+	privateKey := newPrivateKey(s.T())
+	wsClient, err := ws_client.NewRyskV2WSClient(&ws_client.RyskV2WSClientConfiguration{
+		Env:         constants.ENVIRONMENT_TESTNET,
+		PrivateKey:  privateKey,
+		RpcUrl:      s.Server.server.URL,
+		BaseUrl:     s.Server.URL(),
+		WSRpcUrl:    s.Server.RPCURL(),
+		WSStreamUrl: s.Server.StreamURL(),
+	})
+	require.NoError(s.T(), err)
+	defer wsClient.RPCConnection.Close()
+	defer wsClient.StreamConnection.Close()
+	wsClient.RPCConnection.SetReadDeadline(time.Now().Add(5 * time.Second))
+	wsClient.StreamConnection.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	// Private reads require a session.
+	require.NoError(s.T(), wsClient.GetSpotBalances("balances", nil))
+	_, err = utils.ReadRPCResponse(wsClient.RPCConnection, "balances")
+	require.ErrorContains(s.T(), err, "401")
+
+	require.NoError(s.T(), wsClient.Login("login"))
+	_, err = utils.ReadRPCResponse(wsClient.RPCConnection, "login")
+	require.NoError(s.T(), err)
+
+	require.NoError(s.T(), wsClient.AccountUpdates("updates"))
+	_, err = utils.ReadRPCResponse(wsClient.RPCConnection, "updates")
+	require.NoError(s.T(), err)
+
+	require.NoError(s.T(), wsClient.SubscribeSingleTrades("trades", []*types.Product{&constants.PRODUCT_ETH_PERP}))
+	_, err = utils.ReadRPCResponse(wsClient.StreamConnection, "trades")
+	require.NoError(s.T(), err)
+
+	// Rest an order from the websocket client and take it over REST.
+	require.NoError(s.T(), wsClient.NewOrder("order", s.newOrder(price(3000), constants.E17, true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC)))
+	response, err := utils.ReadRPCResponse(wsClient.RPCConnection, "order")
+	require.NoError(s.T(), err)
+	var order types.Order
+	require.NoError(s.T(), utils.DecodeRPCResult(response, &order))
+	require.Equal(s.T(), constants.ORDER_STATUS_OPEN, order.Status)
+
+	s.placeOrder(s.Taker, s.newOrder(price(3000), constants.E17, false, constants.ORDER_TYPE_MARKET, constants.TIME_IN_FORCE_IOC))
+
+	// The trade is streamed.
+	var message struct {
+		Stream string      `json:"stream"`
+		Data   types.Trade `json:"data"`
+	}
+	require.NoError(s.T(), wsClient.StreamConnection.ReadJSON(&message))
+	require.Equal(s.T(), constants.PRODUCT_ETH_PERP.Symbol+"@trade", message.Stream)
+	require.Equal(s.T(), constants.E17.String(), message.Data.Quantity)
+	require.True(s.T(), message.Data.IsBuyerMaker)
+
+	// The fill is pushed as an account update.
+	for {
+		var notification struct {
+			Method types.WSMethod      `json:"method"`
+			Params types.AccountUpdate `json:"params"`
+		}
+		require.NoError(s.T(), wsClient.RPCConnection.ReadJSON(&notification))
+		if notification.Method == constants.WS_METHOD_ACCOUNT_UPDATES && notification.Params.Type == constants.ACCOUNT_UPDATE_POSITION {
+			require.Equal(s.T(), constants.E17.String(), notification.Params.Position.Quantity)
+			break
+		}
+	}
+
+	require.NoError(s.T(), wsClient.GetPerpetualPosition("positions", []*types.Product{&constants.PRODUCT_ETH_PERP}))
+	response, err = utils.ReadRPCResponse(wsClient.RPCConnection, "positions")
+	require.NoError(s.T(), err)
+	var positions []types.PerpetualPosition
+	require.NoError(s.T(), utils.DecodeRPCResult(response, &positions))
+	require.Len(s.T(), positions, 1)
+	require.Equal(s.T(), constants.PRODUCT_ETH_PERP.Id, positions[0].ProductId)
+
+	require.NoError(s.T(), wsClient.SubAccountList("subaccounts"))
+	response, err = utils.ReadRPCResponse(wsClient.RPCConnection, "subaccounts")
+	require.NoError(s.T(), err)
+	var subAccounts []types.SubAccount
+	require.NoError(s.T(), utils.DecodeRPCResult(response, &subAccounts))
+	require.Len(s.T(), subAccounts, 1)
+}
+
+func (s *RyskFakeUnitTestSuite) TestUnit_StreamDepth() {
+	connection, _, err := websocket.DefaultDialer.Dial(s.Server.StreamURL(), nil)
+	require.NoError(s.T(), err)
+	defer connection.Close()
+	connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	topic := constants.PRODUCT_ETH_PERP.Symbol + "@depth5_18"
+	require.NoError(s.T(), connection.WriteJSON(&types.WebsocketRequest{ID: "depth", Method: constants.WS_METHOD_MARKET_DATA_STREAMS_SUBSCRIBE, Params: []string{topic}}))
+	_, err = utils.ReadRPCResponse(connection, "depth")
+	require.NoError(s.T(), err)
+
+	s.placeOrder(s.Maker, s.newOrder(price(3000), constants.E17, true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC))
+	s.placeOrder(s.Maker, s.newOrder(new(big.Int).Add(price(3000), constants.E17), constants.E17, true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC))
+
+	// Both orders group into the 3000 level at 1e18 granularity.
+	var message struct {
+		Stream string               `json:"stream"`
+		Data   types.OrderBookDepth `json:"data"`
+	}
+	for i := 0; i < 2; i++ {
+		require.NoError(s.T(), connection.ReadJSON(&message))
+	}
+	require.Equal(s.T(), topic, message.Stream)
+	require.Equal(s.T(), [][2]string{{price(3000).String(), new(big.Int).Mul(constants.E17, big.NewInt(2)).String()}}, message.Data.Bids)
+
+	var raw json.RawMessage
+	require.NoError(s.T(), connection.WriteJSON(&types.WebsocketRequest{ID: "bad", Method: "LIST"}))
+	require.NoError(s.T(), connection.ReadJSON(&raw))
+	require.Contains(s.T(), string(raw), "not found")
+}
+
+func (s *RyskFakeUnitTestSuite) TestUnit_Disconnect() {
+	dial := func() *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial(s.Server.StreamURL(), nil)
+		require.NoError(s.T(), err)
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		return conn
+	}
+	conn := dial()
+	defer conn.Close()
+
+	s.Server.Disconnect()
+	_, _, err := conn.ReadMessage()
+	require.Error(s.T(), err)
+
+	// New connections are still accepted.
+	conn = dial()
+	defer conn.Close()
+	require.NoError(s.T(), conn.WriteJSON(map[string]interface{}{"id": "1", "method": constants.WS_METHOD_MARKET_DATA_STREAMS_SUBSCRIBE, "params": []string{"ethperp@trade"}}))
+	_, err = utils.ReadRPCResponse(conn, "1")
+	require.NoError(s.T(), err)
+}
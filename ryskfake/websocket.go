@@ -0,0 +1,480 @@
+package ryskfake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+// connection is an open websocket connection with its session and subscriptions.
+type connection struct {
+	conn       *websocket.Conn        // conn is the underlying websocket connection.
+	writeMutex sync.Mutex             // writeMutex serialises writes.
+	mutex      sync.Mutex             // mutex guards the fields below.
+	account    string                 // account is the logged-in account, empty before `session.login`.
+	updates    map[subAccountKey]bool // updates holds the sub-accounts subscribed via `account.updates`.
+	topics     map[string]bool        // topics holds the subscribed stream topics.
+}
+
+// rpcRequest is a JSON-RPC request with raw params.
+type rpcRequest struct {
+	JsonRPC string          `json:"jsonrpc"`
+	ID      string          `json:"id"`
+	Method  types.WSMethod  `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+// sessionResponse is the result of `session.login` and `session.status` requests.
+type sessionResponse struct {
+	Account       string `json:"account"`
+	Authenticated bool   `json:"authenticated"`
+}
+
+// write sends a JSON message on the connection.
+func (connection *connection) write(message interface{}) error {
+	connection.writeMutex.Lock()
+	defer connection.writeMutex.Unlock()
+	return connection.conn.WriteJSON(message)
+}
+
+// session returns the key of a sub-account of the logged-in account.
+func (connection *connection) session(accountAddress string, subAccountId int64) (subAccountKey, error) {
+	connection.mutex.Lock()
+	defer connection.mutex.Unlock()
+
+	if connection.account == "" || connection.account != normalizeAddress(accountAddress) {
+		return subAccountKey{}, fmt.Errorf("%w: session not logged in as %s", errUnauthorized, accountAddress)
+	}
+	return subAccountKey{account: connection.account, subAccountId: subAccountId}, nil
+}
+
+// accept upgrades a websocket request and tracks the connection until it closes.
+func (server *Server) accept(w http.ResponseWriter, req *http.Request, connections map[*connection]struct{}, serve func(*connection)) {
+	conn, err := server.upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	connection := &connection{
+		conn:    conn,
+		updates: make(map[subAccountKey]bool),
+		topics:  make(map[string]bool),
+	}
+
+	server.connectionsMutex.Lock()
+	connections[connection] = struct{}{}
+	server.connectionsMutex.Unlock()
+
+	serve(connection)
+
+	server.connectionsMutex.Lock()
+	delete(connections, connection)
+	server.connectionsMutex.Unlock()
+	conn.Close()
+}
+
+func (server *Server) handleRPCWebsocket(w http.ResponseWriter, req *http.Request) {
+	server.accept(w, req, server.rpcConnections, func(connection *connection) {
+		for {
+			var request rpcRequest
+			if err := connection.conn.ReadJSON(&request); err != nil {
+				return
+			}
+
+			result, err := server.handleRPC(connection, &request)
+			response := &types.WebsocketResponse{JsonRPC: constants.WS_JSON_RPC, ID: request.ID, Success: err == nil, Result: result}
+			if err != nil {
+				response.Result = nil
+				response.Error = &types.WebsocketError{Code: statusCode(err), Message: err.Error()}
+			}
+			if err := connection.write(response); err != nil {
+				return
+			}
+		}
+	})
+}
+
+func (server *Server) handleStreamWebsocket(w http.ResponseWriter, req *http.Request) {
+	server.accept(w, req, server.streamConnections, func(connection *connection) {
+		for {
+			var request struct {
+				ID     string         `json:"id"`
+				Method types.WSMethod `json:"method"`
+				Params []string       `json:"params"`
+			}
+			if err := connection.conn.ReadJSON(&request); err != nil {
+				return
+			}
+
+			response := &types.WebsocketResponse{JsonRPC: constants.WS_JSON_RPC, ID: request.ID, Success: true}
+			connection.mutex.Lock()
+			switch request.Method {
+			case constants.WS_METHOD_MARKET_DATA_STREAMS_SUBSCRIBE:
+				for _, topic := range request.Params {
+					connection.topics[topic] = true
+				}
+			case constants.WS_METHOD_MARKET_DATA_STREAMS_UNSUBSCRIBE:
+				for _, topic := range request.Params {
+					delete(connection.topics, topic)
+				}
+			default:
+				response.Success = false
+				response.Error = &types.WebsocketError{Code: http.StatusNotFound, Message: fmt.Sprintf("method %s not found", request.Method)}
+			}
+			connection.mutex.Unlock()
+			if err := connection.write(response); err != nil {
+				return
+			}
+		}
+	})
+}
+
+// handleRPC dispatches an RPC request and returns its result.
+func (server *Server) handleRPC(connection *connection, request *rpcRequest) (interface{}, error) {
+	decode := func(params interface{}) error {
+		if err := json.Unmarshal(request.Params, params); err != nil {
+			return fmt.Errorf("invalid params: %v", err)
+		}
+		return nil
+	}
+
+	switch request.Method {
+	case constants.WS_METHOD_LIST_PRODUCTS:
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		return server.sortedProducts(), nil
+
+	case constants.WS_METHOD_GET_PRODUCT:
+		var params struct {
+			Symbol string `json:"symbol"`
+		}
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		return server.product(params.Symbol)
+
+	case constants.WS_METHOD_SERVER_TIME:
+		return &serverTimeResponse{ServerTime: time.Now().UnixMilli()}, nil
+
+	case constants.WS_METHOD_LOGIN:
+		var params loginRequest
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		if time.UnixMilli(int64(params.Timestamp)).Before(time.Now().Add(-LOGIN_MAX_AGE)) {
+			return nil, fmt.Errorf("%w: login timestamp expired", errUnauthorized)
+		}
+		if err := server.authenticate(constants.PRIMARY_TYPE_LOGIN_MESSAGE, params.message(), params.Signature, params.Account, 0); err != nil {
+			return nil, err
+		}
+		connection.mutex.Lock()
+		connection.account = normalizeAddress(params.Account)
+		connection.mutex.Unlock()
+		return &sessionResponse{Account: normalizeAddress(params.Account), Authenticated: true}, nil
+
+	case constants.WS_METHOD_SESSION_STATUS:
+		connection.mutex.Lock()
+		defer connection.mutex.Unlock()
+		return &sessionResponse{Account: connection.account, Authenticated: connection.account != ""}, nil
+
+	case constants.WS_METHOD_SUB_ACCOUNT_LIST:
+		var params struct {
+			Account string `json:"account"`
+		}
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		key, err := connection.session(params.Account, 0)
+		if err != nil {
+			return nil, err
+		}
+		return server.subAccounts(key.account), nil
+
+	case constants.WS_METHOD_WITHDRAW:
+		var params withdrawRequest
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		return server.placeWithdrawal(&params)
+
+	case constants.WS_METHOD_APPROVE_REVOKE_SIGNER:
+		var params signerRequest
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		return server.approveRevokeSigner(&params)
+
+	case constants.WS_METHOD_NEW_ORDER:
+		var params orderRequest
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		return server.placeOrder(&params)
+
+	case constants.WS_METHOD_ORDER_LIST:
+		var params struct {
+			Account      string   `json:"account"`
+			SubAccountId int64    `json:"subAccountId"`
+			ProductId    int64    `json:"productId"`
+			OrderIds     []string `json:"orderIds"`
+			StartTime    int64    `json:"startTime"`
+			EndTime      int64    `json:"endTime"`
+			Limit        int64    `json:"limit"`
+		}
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		key, err := connection.session(params.Account, params.SubAccountId)
+		if err != nil {
+			return nil, err
+		}
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		orders := server.listOrders(key, params.ProductId, params.OrderIds, func(candidate *order) bool {
+			return (params.StartTime == 0 || candidate.CreatedAt >= params.StartTime) &&
+				(params.EndTime == 0 || candidate.CreatedAt <= params.EndTime)
+		})
+		if params.Limit > 0 && int64(len(orders)) > params.Limit {
+			orders = orders[int64(len(orders))-params.Limit:]
+		}
+		return orders, nil
+
+	case constants.WS_METHOD_CANCEL_ORDER:
+		var params cancelOrderRequest
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		if err := server.authenticate(constants.PRIMARY_TYPE_CANCEL_ORDER, params.message(), params.Signature, params.Account, params.SubAccountId); err != nil {
+			return nil, err
+		}
+		changes := newChanges()
+		server.mutex.Lock()
+		order, err := server.cancelOrder(subAccountKey{account: normalizeAddress(params.Account), subAccountId: params.SubAccountId}, params.ProductId, params.OrderId, changes)
+		server.mutex.Unlock()
+		server.publish(changes)
+		return order, err
+
+	case constants.WS_METHOD_CANCEL_ALL_OPEN_ORDERS:
+		var params cancelOrdersRequest
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		key, err := connection.session(params.Account, params.SubAccountId)
+		if err != nil {
+			return nil, err
+		}
+		return server.cancelAllOpenOrders(key, params.ProductId)
+
+	case constants.WS_METHOD_ORDER_BOOK_DEPTH:
+		var params struct {
+			Symbol      string `json:"symbol"`
+			Granularity int64  `json:"granularity"`
+			Limit       int64  `json:"limit"`
+		}
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		product, err := server.product(params.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		return server.depth(product, params.Limit, params.Granularity)
+
+	case constants.WS_METHOD_GET_PERPETUAL_POSITION:
+		var params struct {
+			Account      string  `json:"account"`
+			SubAccountId int64   `json:"subAccountId"`
+			ProductIds   []int64 `json:"productIds"`
+		}
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		key, err := connection.session(params.Account, params.SubAccountId)
+		if err != nil {
+			return nil, err
+		}
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		return server.perpetualPositions(key, params.ProductIds), nil
+
+	case constants.WS_METHOD_GET_SPOT_BALANCES:
+		var params struct {
+			Account      string   `json:"account"`
+			SubAccountId int64    `json:"subAccountId"`
+			Assets       []string `json:"assets"`
+		}
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		key, err := connection.session(params.Account, params.SubAccountId)
+		if err != nil {
+			return nil, err
+		}
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		return server.spotBalances(key, params.Assets), nil
+
+	case constants.WS_METHOD_ACCOUNT_UPDATES:
+		var params struct {
+			Account      string `json:"account"`
+			SubAccountId int64  `json:"subAccountId"`
+		}
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		key, err := connection.session(params.Account, params.SubAccountId)
+		if err != nil {
+			return nil, err
+		}
+		connection.mutex.Lock()
+		connection.updates[key] = true
+		connection.mutex.Unlock()
+		return nil, nil
+	}
+	return nil, fmt.Errorf("method %s %w", request.Method, errNotFound)
+}
+
+// publish pushes trades, market data snapshots and account updates to subscribed connections.
+func (server *Server) publish(changes *changes) {
+	// Trades, in execution order.
+	for _, trade := range changes.trades {
+		server.broadcast(trade.Symbol+"@trade", trade)
+		server.broadcast(trade.Symbol+"@aggTrade", trade)
+	}
+
+	// Book, ticker and kline snapshots of changed products.
+	for _, topic := range server.subscribedTopics() {
+		if data, ok := server.topicData(topic, changes); ok {
+			server.broadcast(topic, data)
+		}
+	}
+
+	// Account updates.
+	server.connectionsMutex.Lock()
+	connections := make([]*connection, 0, len(server.rpcConnections))
+	for connection := range server.rpcConnections {
+		connections = append(connections, connection)
+	}
+	server.connectionsMutex.Unlock()
+	for key, updates := range changes.updates {
+		for _, connection := range connections {
+			connection.mutex.Lock()
+			subscribed := connection.updates[key]
+			connection.mutex.Unlock()
+			if !subscribed {
+				continue
+			}
+			for _, update := range updates {
+				connection.write(&types.WebsocketNotification{
+					JsonRPC: constants.WS_JSON_RPC,
+					Method:  constants.WS_METHOD_ACCOUNT_UPDATES,
+					Params:  update,
+				})
+			}
+		}
+	}
+}
+
+// subscribedTopics lists the topics subscribed by any stream connection.
+func (server *Server) subscribedTopics() []string {
+	server.connectionsMutex.Lock()
+	defer server.connectionsMutex.Unlock()
+
+	seen := make(map[string]bool)
+	var topics []string
+	for connection := range server.streamConnections {
+		connection.mutex.Lock()
+		for topic := range connection.topics {
+			if !seen[topic] {
+				seen[topic] = true
+				topics = append(topics, topic)
+			}
+		}
+		connection.mutex.Unlock()
+	}
+	return topics
+}
+
+// topicData builds the snapshot published on a `<symbol>@ticker`, `<symbol>@klines_<interval>`
+// or `<symbol>@depth<limit>_<granularity>` topic if its product changed.
+func (server *Server) topicData(topic string, changes *changes) (interface{}, bool) {
+	symbol, stream, ok := strings.Cut(topic, "@")
+	if !ok {
+		return nil, false
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	productId, ok := server.symbols[symbol]
+	if !ok || !changes.products[productId] {
+		return nil, false
+	}
+	product := server.products[productId]
+	traded := false
+	for _, trade := range changes.trades {
+		traded = traded || trade.ProductId == productId
+	}
+
+	switch {
+	case stream == "ticker" && traded:
+		return server.ticker(product), true
+
+	case strings.HasPrefix(stream, "klines_") && traded:
+		interval := types.Interval(strings.TrimPrefix(stream, "klines_"))
+		duration, ok := constants.INTERVAL_DURATIONS[interval]
+		if !ok {
+			return nil, false
+		}
+		now := time.Now().UnixMilli()
+		openTime := now - now%duration.Milliseconds()
+		klines, err := server.klines(product, interval, openTime, 0, 1)
+		if err != nil || len(klines) == 0 {
+			return nil, false
+		}
+		return klines[0], true
+
+	case strings.HasPrefix(stream, "depth"):
+		limit, granularity, ok := strings.Cut(strings.TrimPrefix(stream, "depth"), "_")
+		if !ok {
+			return nil, false
+		}
+		limitValue, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		granularityValue, err := strconv.ParseInt(granularity, 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		depth, err := server.depth(product, limitValue, granularityValue)
+		return depth, err == nil
+	}
+	return nil, false
+}
+
+// broadcast sends a stream message to every connection subscribed to topic.
+func (server *Server) broadcast(topic string, data interface{}) {
+	server.connectionsMutex.Lock()
+	var subscribers []*connection
+	for connection := range server.streamConnections {
+		connection.mutex.Lock()
+		if connection.topics[topic] {
+			subscribers = append(subscribers, connection)
+		}
+		connection.mutex.Unlock()
+	}
+	server.connectionsMutex.Unlock()
+
+	for _, connection := range subscribers {
+		connection.write(&types.StreamMessage{Stream: topic, Data: data})
+	}
+}
@@ -0,0 +1,444 @@
+package sub_accounts
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/rysk-finance/v2_client_go/api_client"
+	"github.com/rysk-finance/v2_client_go/exchange"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+	"github.com/rysk-finance/v2_client_go/ws_client"
+)
+
+// SubAccountManagerConfiguration represents configuration settings for the sub-account manager.
+type SubAccountManagerConfiguration struct {
+	APIClient *api_client.RyskV2APIClient // APIClient is the REST client whose signer and HTTP client are shared by all sub-accounts.
+	WSClient  *ws_client.RyskV2WSClient   // WSClient is the optional WebSocket client whose RPC and stream connections are shared by all sub-accounts.
+	Exchange  *exchange.WSExchange        // Exchange is the optional websocket exchange reading the RPC connection of WSClient, sub-accounts are then listed through it.
+}
+
+// SubAccountManager manages many sub-accounts of one account over a single signer, HTTP client and connection pair.
+type SubAccountManager struct {
+	apiClient   *api_client.RyskV2APIClient // apiClient is the shared REST client.
+	wsClient    *ws_client.RyskV2WSClient   // wsClient is the shared WebSocket client, nil if not configured.
+	exchange    *exchange.WSExchange        // exchange is the websocket exchange reading the RPC connection, nil if not configured.
+	subAccounts map[uint8]*SubAccount       // subAccounts holds the tracked sub-account handles by ID.
+	mutex       sync.Mutex                  // mutex guards subAccounts.
+	rpcMutex    sync.Mutex                  // rpcMutex serialises RPC request/response round trips.
+}
+
+// SubAccount is a handle acting on a single sub-account.
+type SubAccount struct {
+	Id        uint8                       // Id is the ID of the sub-account.
+	APIClient *api_client.RyskV2APIClient // APIClient is the REST client acting on the sub-account.
+	WSClient  *ws_client.RyskV2WSClient   // WSClient is the WebSocket client acting on the sub-account, nil if not configured.
+}
+
+// NewSubAccountManager creates a new `SubAccountManager` from existing clients.
+//
+// Parameters:
+//   - config: A pointer to SubAccountManagerConfiguration containing the shared clients.
+//
+// Returns:
+//   - A pointer to SubAccountManager.
+//   - An error if no REST client is provided.
+func NewSubAccountManager(config *SubAccountManagerConfiguration) (*SubAccountManager, error) {
+	if config.APIClient == nil {
+		return nil, fmt.Errorf("sub-account manager requires an API client")
+	}
+	return &SubAccountManager{
+		apiClient:   config.APIClient,
+		wsClient:    config.WSClient,
+		exchange:    config.Exchange,
+		subAccounts: make(map[uint8]*SubAccount),
+	}, nil
+}
+
+// SubAccount returns the handle of a sub-account, tracking it for aggregated views.
+//
+// Parameters:
+//   - id: The ID of the sub-account.
+//
+// Returns:
+//   - A pointer to the SubAccount handle.
+func (manager *SubAccountManager) SubAccount(id uint8) *SubAccount {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	if subAccount, ok := manager.subAccounts[id]; ok {
+		return subAccount
+	}
+	subAccount := &SubAccount{
+		Id:        id,
+		APIClient: manager.apiClient.WithSubAccount(id),
+	}
+	if manager.wsClient != nil {
+		subAccount.WSClient = manager.wsClient.WithSubAccount(id)
+	}
+	manager.subAccounts[id] = subAccount
+	return subAccount
+}
+
+// SubAccounts returns the tracked sub-account handles ordered by ID.
+//
+// Returns:
+//   - A slice of SubAccount pointers.
+func (manager *SubAccountManager) SubAccounts() []*SubAccount {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	subAccounts := make([]*SubAccount, 0, len(manager.subAccounts))
+	for _, subAccount := range manager.subAccounts {
+		subAccounts = append(subAccounts, subAccount)
+	}
+	sort.Slice(subAccounts, func(i, j int) bool {
+		return subAccounts[i].Id < subAccounts[j].Id
+	})
+	return subAccounts
+}
+
+// ListSubAccounts retrieves the sub-accounts of the account via `subaccount.list` and tracks them.
+//
+// It calls `ListSubAccountsCtx` with a background context.
+func (manager *SubAccountManager) ListSubAccounts(messageId string) ([]types.SubAccount, error) {
+	return manager.ListSubAccountsCtx(context.Background(), messageId)
+}
+
+// ListSubAccountsCtx retrieves the sub-accounts of the account via `subaccount.list` and tracks them.
+// With an exchange configured, the request goes through it and its reader dispatches the response. Otherwise the
+// RPC connection is read through `RPCReader` until the response arrives, so no other reader may consume it meanwhile.
+//
+// Parameters:
+//   - ctx: Context of the request. It bounds sending and, through the exchange, waiting for the response.
+//   - messageId: The unique identifier for the message, unused through the exchange which numbers its own requests.
+//
+// Returns:
+//   - A slice of types.SubAccount.
+//   - An error if no WebSocket client is configured or if the request fails.
+func (manager *SubAccountManager) ListSubAccountsCtx(ctx context.Context, messageId string) ([]types.SubAccount, error) {
+	var subAccounts []types.SubAccount
+	var err error
+	switch {
+	case manager.exchange != nil:
+		subAccounts, err = manager.exchange.SubAccounts(ctx)
+	case manager.wsClient != nil:
+		subAccounts, err = manager.readSubAccounts(ctx, messageId)
+	default:
+		return nil, fmt.Errorf("listing sub-accounts requires a WebSocket client")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Track listed sub-accounts.
+	for _, subAccount := range subAccounts {
+		if subAccount.SubAccountId >= 0 && subAccount.SubAccountId <= 255 {
+			manager.SubAccount(uint8(subAccount.SubAccountId))
+		}
+	}
+	return subAccounts, nil
+}
+
+// readSubAccounts sends `subaccount.list` and reads its response from the RPC connection.
+func (manager *SubAccountManager) readSubAccounts(ctx context.Context, messageId string) ([]types.SubAccount, error) {
+	manager.rpcMutex.Lock()
+	defer manager.rpcMutex.Unlock()
+
+	// Send request and wait for its response.
+	if err := manager.wsClient.SubAccountListCtx(ctx, messageId); err != nil {
+		return nil, err
+	}
+	response, err := utils.ReadRPCResponse(manager.wsClient.RPCReader(), messageId)
+	if err != nil {
+		return nil, err
+	}
+	var subAccounts []types.SubAccount
+	if err := utils.DecodeRPCResult(response, &subAccounts); err != nil {
+		return nil, fmt.Errorf("failed to decode sub-accounts: %v", err)
+	}
+	return subAccounts, nil
+}
+
+// SpotBalances returns the spot balances of every tracked sub-account.
+//
+// It calls `SpotBalancesCtx` with a background context.
+func (manager *SubAccountManager) SpotBalances() (map[uint8][]types.SpotBalance, error) {
+	return manager.SpotBalancesCtx(context.Background())
+}
+
+// SpotBalancesCtx returns the spot balances of every tracked sub-account.
+//
+// Parameters:
+//   - ctx: Context of the requests. It bounds every request, remaining sub-accounts are not requested once it is done.
+//
+// Returns:
+//   - A map of spot balances by sub-account ID.
+//   - An error if any request fails.
+func (manager *SubAccountManager) SpotBalancesCtx(ctx context.Context) (map[uint8][]types.SpotBalance, error) {
+	return collect(ctx, manager.SubAccounts(), (*SubAccount).SpotBalancesCtx)
+}
+
+// PerpetualPositions returns the perpetual positions of every tracked sub-account.
+//
+// It calls `PerpetualPositionsCtx` with a background context.
+func (manager *SubAccountManager) PerpetualPositions() (map[uint8][]types.PerpetualPosition, error) {
+	return manager.PerpetualPositionsCtx(context.Background())
+}
+
+// PerpetualPositionsCtx returns the perpetual positions of every tracked sub-account.
+//
+// Parameters:
+//   - ctx: Context of the requests. It bounds every request, remaining sub-accounts are not requested once it is done.
+//
+// Returns:
+//   - A map of perpetual positions by sub-account ID.
+//   - An error if any request fails.
+func (manager *SubAccountManager) PerpetualPositionsCtx(ctx context.Context) (map[uint8][]types.PerpetualPosition, error) {
+	return collect(ctx, manager.SubAccounts(), (*SubAccount).PerpetualPositionsCtx)
+}
+
+// OpenOrders returns the open orders of every tracked sub-account.
+//
+// It calls `OpenOrdersCtx` with a background context.
+func (manager *SubAccountManager) OpenOrders() (map[uint8][]types.Order, error) {
+	return manager.OpenOrdersCtx(context.Background())
+}
+
+// OpenOrdersCtx returns the open orders of every tracked sub-account.
+//
+// Parameters:
+//   - ctx: Context of the requests. It bounds every request, remaining sub-accounts are not requested once it is done.
+//
+// Returns:
+//   - A map of open orders by sub-account ID.
+//   - An error if any request fails.
+func (manager *SubAccountManager) OpenOrdersCtx(ctx context.Context) (map[uint8][]types.Order, error) {
+	return collect(ctx, manager.SubAccounts(), (*SubAccount).OpenOrdersCtx)
+}
+
+// TotalSpotBalances sums the spot balances of every tracked sub-account by asset.
+//
+// It calls `TotalSpotBalancesCtx` with a background context.
+func (manager *SubAccountManager) TotalSpotBalances() (map[string]*big.Int, error) {
+	return manager.TotalSpotBalancesCtx(context.Background())
+}
+
+// TotalSpotBalancesCtx sums the spot balances of every tracked sub-account by asset.
+//
+// Parameters:
+//   - ctx: Context of the requests. It bounds every request, remaining sub-accounts are not requested once it is done.
+//
+// Returns:
+//   - A map of total quantities in wei (e18) by asset address.
+//   - An error if any request fails or if a quantity is invalid.
+func (manager *SubAccountManager) TotalSpotBalancesCtx(ctx context.Context) (map[string]*big.Int, error) {
+	balances, err := manager.SpotBalancesCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]*big.Int)
+	for _, subAccountBalances := range balances {
+		for _, balance := range subAccountBalances {
+			if err := addQuantity(totals, balance.Asset, balance.Quantity); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return totals, nil
+}
+
+// NetPerpetualPositions sums the perpetual positions of every tracked sub-account by product.
+//
+// It calls `NetPerpetualPositionsCtx` with a background context.
+func (manager *SubAccountManager) NetPerpetualPositions() (map[int64]*big.Int, error) {
+	return manager.NetPerpetualPositionsCtx(context.Background())
+}
+
+// NetPerpetualPositionsCtx sums the perpetual positions of every tracked sub-account by product.
+//
+// Parameters:
+//   - ctx: Context of the requests. It bounds every request, remaining sub-accounts are not requested once it is done.
+//
+// Returns:
+//   - A map of net signed quantities in wei (e18) by product ID.
+//   - An error if any request fails or if a quantity is invalid.
+func (manager *SubAccountManager) NetPerpetualPositionsCtx(ctx context.Context) (map[int64]*big.Int, error) {
+	positions, err := manager.PerpetualPositionsCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[int64]*big.Int)
+	for _, subAccountPositions := range positions {
+		for _, position := range subAccountPositions {
+			if err := addQuantity(totals, position.ProductId, position.Quantity); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return totals, nil
+}
+
+// NewOrder creates a new order on the sub-account.
+//
+// It calls `NewOrderCtx` with a background context.
+func (subAccount *SubAccount) NewOrder(params *types.NewOrderRequest) (*http.Response, error) {
+	return subAccount.NewOrderCtx(context.Background(), params)
+}
+
+// NewOrderCtx creates a new order on the sub-account.
+//
+// Parameters:
+//   - ctx: Context of the request. It bounds signing and the HTTP request.
+//   - params: The order parameters.
+//
+// Returns:
+//   - A pointer to an http.Response containing the response from the API call.
+//   - An error if the API call fails.
+func (subAccount *SubAccount) NewOrderCtx(ctx context.Context, params *types.NewOrderRequest) (*http.Response, error) {
+	return subAccount.APIClient.NewOrderCtx(ctx, params)
+}
+
+// CancelOrder cancels an order of the sub-account.
+//
+// It calls `CancelOrderCtx` with a background context.
+func (subAccount *SubAccount) CancelOrder(params *types.CancelOrderRequest) (*http.Response, error) {
+	return subAccount.CancelOrderCtx(context.Background(), params)
+}
+
+// CancelOrderCtx cancels an order of the sub-account.
+//
+// Parameters:
+//   - ctx: Context of the request. It bounds signing and the HTTP request.
+//   - params: The cancellation parameters.
+//
+// Returns:
+//   - A pointer to an http.Response containing the response from the API call.
+//   - An error if the API call fails.
+func (subAccount *SubAccount) CancelOrderCtx(ctx context.Context, params *types.CancelOrderRequest) (*http.Response, error) {
+	return subAccount.APIClient.CancelOrderCtx(ctx, params)
+}
+
+// CancelAllOpenOrders cancels all open orders of the sub-account for a product.
+//
+// It calls `CancelAllOpenOrdersCtx` with a background context.
+func (subAccount *SubAccount) CancelAllOpenOrders(product *types.Product) (*http.Response, error) {
+	return subAccount.CancelAllOpenOrdersCtx(context.Background(), product)
+}
+
+// CancelAllOpenOrdersCtx cancels all open orders of the sub-account for a product.
+//
+// Parameters:
+//   - ctx: Context of the request. It bounds signing and the HTTP request.
+//   - product: The product whose orders are cancelled.
+//
+// Returns:
+//   - A pointer to an http.Response containing the response from the API call.
+//   - An error if the API call fails.
+func (subAccount *SubAccount) CancelAllOpenOrdersCtx(ctx context.Context, product *types.Product) (*http.Response, error) {
+	return subAccount.APIClient.CancelAllOpenOrdersCtx(ctx, product)
+}
+
+// SpotBalances returns the spot balances of the sub-account.
+//
+// It calls `SpotBalancesCtx` with a background context.
+func (subAccount *SubAccount) SpotBalances() ([]types.SpotBalance, error) {
+	return subAccount.SpotBalancesCtx(context.Background())
+}
+
+// SpotBalancesCtx returns the spot balances of the sub-account.
+//
+// Parameters:
+//   - ctx: Context of the request. It bounds the HTTP request.
+//
+// Returns:
+//   - A slice of types.SpotBalance.
+//   - An error if the API call fails or if the response cannot be decoded.
+func (subAccount *SubAccount) SpotBalancesCtx(ctx context.Context) ([]types.SpotBalance, error) {
+	var balances []types.SpotBalance
+	return balances, decode(ctx, subAccount.APIClient.GetSpotBalancesCtx, &balances)
+}
+
+// PerpetualPositions returns the perpetual positions of the sub-account across all products.
+//
+// It calls `PerpetualPositionsCtx` with a background context.
+func (subAccount *SubAccount) PerpetualPositions() ([]types.PerpetualPosition, error) {
+	return subAccount.PerpetualPositionsCtx(context.Background())
+}
+
+// PerpetualPositionsCtx returns the perpetual positions of the sub-account across all products.
+//
+// Parameters:
+//   - ctx: Context of the request. It bounds the HTTP request.
+//
+// Returns:
+//   - A slice of types.PerpetualPosition.
+//   - An error if the API call fails or if the response cannot be decoded.
+func (subAccount *SubAccount) PerpetualPositionsCtx(ctx context.Context) ([]types.PerpetualPosition, error) {
+	var positions []types.PerpetualPosition
+	return positions, decode(ctx, subAccount.APIClient.GetPerpetualPositionAllProductsCtx, &positions)
+}
+
+// OpenOrders returns the open orders of the sub-account across all products.
+//
+// It calls `OpenOrdersCtx` with a background context.
+func (subAccount *SubAccount) OpenOrders() ([]types.Order, error) {
+	return subAccount.OpenOrdersCtx(context.Background())
+}
+
+// OpenOrdersCtx returns the open orders of the sub-account across all products.
+//
+// Parameters:
+//   - ctx: Context of the request. It bounds the HTTP request.
+//
+// Returns:
+//   - A slice of types.Order.
+//   - An error if the API call fails or if the response cannot be decoded.
+func (subAccount *SubAccount) OpenOrdersCtx(ctx context.Context) ([]types.Order, error) {
+	var orders []types.Order
+	return orders, decode(ctx, subAccount.APIClient.ListOpenOrdersAllProductsCtx, &orders)
+}
+
+// decode sends a REST request and decodes its JSON response.
+func decode(ctx context.Context, request func(context.Context) (*http.Response, error), result interface{}) error {
+	res, err := request(ctx)
+	if err != nil {
+		return err
+	}
+	return utils.DecodeHTTPResponse(res, result)
+}
+
+// collect runs a per-sub-account view over every handle, stopping once the context is done.
+func collect[T any](ctx context.Context, subAccounts []*SubAccount, view func(*SubAccount, context.Context) ([]T, error)) (map[uint8][]T, error) {
+	results := make(map[uint8][]T, len(subAccounts))
+	for _, subAccount := range subAccounts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result, err := view(subAccount, ctx)
+		if err != nil {
+			return nil, fmt.Errorf("sub-account %d: %w", subAccount.Id, err)
+		}
+		results[subAccount.Id] = result
+	}
+	return results, nil
+}
+
+// addQuantity adds a decimal wei quantity to the total under key.
+func addQuantity[K comparable](totals map[K]*big.Int, key K, quantity string) error {
+	value, ok := new(big.Int).SetString(quantity, 10)
+	if !ok {
+		return fmt.Errorf("invalid quantity %q", quantity)
+	}
+	if totals[key] == nil {
+		totals[key] = new(big.Int)
+	}
+	totals[key].Add(totals[key], value)
+	return nil
+}
@@ -0,0 +1,314 @@
+//go:build !integration
+// +build !integration
+
+package sub_accounts
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gorilla/websocket"
+	"github.com/rysk-finance/v2_client_go/api_client"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/exchange"
+	"github.com/rysk-finance/v2_client_go/ryskfake"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/ws_client"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type SubAccountsUnitTestSuite struct {
+	suite.Suite
+	APIClient *api_client.RyskV2APIClient
+	Server    *httptest.Server
+}
+
+// redirectTransport sends every request to the test server.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (transport *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = transport.target.Scheme
+	req.URL.Host = transport.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (s *SubAccountsUnitTestSuite) SetupSuite() {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(s.T(), err)
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		subAccountId := req.URL.Query().Get("subAccountId")
+		switch {
+		case strings.HasSuffix(req.URL.Path, string(constants.API_ENDPOINT_GET_SPOT_BALANCES)):
+			json.NewEncoder(w).Encode([]types.SpotBalance{
+				{SubAccountId: 0, Asset: "USDC", Quantity: map[string]string{"1": "100", "2": "250"}[subAccountId]},
+			})
+		case strings.HasSuffix(req.URL.Path, string(constants.API_ENDPOINT_GET_PERPETUAL_POSITION)):
+			json.NewEncoder(w).Encode([]types.PerpetualPosition{
+				{ProductId: 1002, Quantity: map[string]string{"1": "5", "2": "-3"}[subAccountId]},
+			})
+		case strings.HasSuffix(req.URL.Path, string(constants.API_ENDPOINT_LIST_OPEN_ORDERS)):
+			json.NewEncoder(w).Encode([]types.Order{{Id: "order-" + subAccountId}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	target, err := url.Parse(s.Server.URL)
+	require.NoError(s.T(), err)
+
+	s.APIClient, err = api_client.NewRyskV2APIClient(&api_client.RyskV2APIClientConfiguration{
+		Env:        constants.ENVIRONMENT_TESTNET,
+		PrivateKey: hex.EncodeToString(crypto.FromECDSA(privateKey)),
+		RpcUrl:     s.Server.URL,
+	})
+	require.NoError(s.T(), err)
+	s.APIClient.HttpClient = &http.Client{Transport: &redirectTransport{target: target}}
+}
+
+func (s *SubAccountsUnitTestSuite) TearDownSuite() {
+	s.Server.Close()
+}
+
+func TestRunSuiteUnit_SubAccountsUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(SubAccountsUnitTestSuite))
+}
+
+func (s *SubAccountsUnitTestSuite) TestUnit_NewSubAccountManager_NoAPIClient() {
+	manager, err := NewSubAccountManager(&SubAccountManagerConfiguration{})
+	require.Error(s.T(), err)
+	require.Nil(s.T(), manager)
+}
+
+func (s *SubAccountsUnitTestSuite) TestUnit_SubAccount() {
+	manager, err := NewSubAccountManager(&SubAccountManagerConfiguration{APIClient: s.APIClient})
+	require.NoError(s.T(), err)
+
+	subAccount := manager.SubAccount(3)
+	require.Equal(s.T(), uint8(3), subAccount.Id)
+	require.Equal(s.T(), int64(3), subAccount.APIClient.SubAccountId)
+	require.Equal(s.T(), s.APIClient.HttpClient, subAccount.APIClient.HttpClient)
+	require.Nil(s.T(), subAccount.WSClient)
+	require.Same(s.T(), subAccount, manager.SubAccount(3))
+	require.Equal(s.T(), int64(0), s.APIClient.SubAccountId)
+}
+
+func (s *SubAccountsUnitTestSuite) TestUnit_SubAccounts_Ordered() {
+	manager, err := NewSubAccountManager(&SubAccountManagerConfiguration{APIClient: s.APIClient})
+	require.NoError(s.T(), err)
+	manager.SubAccount(5)
+	manager.SubAccount(1)
+	manager.SubAccount(3)
+
+	var ids []uint8
+	for _, subAccount := range manager.SubAccounts() {
+		ids = append(ids, subAccount.Id)
+	}
+	require.Equal(s.T(), []uint8{1, 3, 5}, ids)
+}
+
+func (s *SubAccountsUnitTestSuite) TestUnit_AggregatedViews() {
+	manager, err := NewSubAccountManager(&SubAccountManagerConfiguration{APIClient: s.APIClient})
+	require.NoError(s.T(), err)
+	manager.SubAccount(1)
+	manager.SubAccount(2)
+
+	totals, err := manager.TotalSpotBalances()
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), big.NewInt(350), totals["USDC"])
+
+	positions, err := manager.NetPerpetualPositions()
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), big.NewInt(2), positions[1002])
+
+	orders, err := manager.OpenOrders()
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "order-1", orders[1][0].Id)
+	require.Equal(s.T(), "order-2", orders[2][0].Id)
+}
+
+func (s *SubAccountsUnitTestSuite) TestUnit_AggregatedViews_ContextCancelled() {
+	manager, err := NewSubAccountManager(&SubAccountManagerConfiguration{APIClient: s.APIClient})
+	require.NoError(s.T(), err)
+	manager.SubAccount(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	totals, err := manager.TotalSpotBalancesCtx(ctx)
+	require.ErrorIs(s.T(), err, context.Canceled)
+	require.Nil(s.T(), totals)
+
+	balances, err := manager.SubAccount(1).SpotBalancesCtx(ctx)
+	require.ErrorIs(s.T(), err, context.Canceled)
+	require.Nil(s.T(), balances)
+}
+
+func (s *SubAccountsUnitTestSuite) TestUnit_AggregatedViews_InvalidQuantity() {
+	manager, err := NewSubAccountManager(&SubAccountManagerConfiguration{APIClient: s.APIClient})
+	require.NoError(s.T(), err)
+	manager.SubAccount(9)
+
+	totals, err := manager.TotalSpotBalances()
+	require.Error(s.T(), err)
+	require.Nil(s.T(), totals)
+}
+
+func (s *SubAccountsUnitTestSuite) TestUnit_ListSubAccounts() {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		connection, err := upgrader.Upgrade(w, req, nil)
+		require.NoError(s.T(), err)
+		defer connection.Close()
+
+		var request types.WebsocketRequest
+		require.NoError(s.T(), connection.ReadJSON(&request))
+		require.Equal(s.T(), constants.WS_METHOD_SUB_ACCOUNT_LIST, request.Method)
+
+		// Unrelated response first, then the expected one.
+		connection.WriteJSON(&types.WebsocketResponse{ID: "other", Success: true})
+		connection.WriteJSON(&types.WebsocketResponse{ID: request.ID, Success: true, Result: []types.SubAccount{
+			{SubAccountId: 0},
+			{SubAccountId: 4, Name: "hedge"},
+		}})
+	}))
+	defer server.Close()
+
+	connection, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(s.T(), err)
+	defer connection.Close()
+
+	manager, err := NewSubAccountManager(&SubAccountManagerConfiguration{
+		APIClient: s.APIClient,
+		WSClient:  &ws_client.RyskV2WSClient{RPCConnection: connection},
+	})
+	require.NoError(s.T(), err)
+
+	subAccounts, err := manager.ListSubAccounts("LIST")
+	require.NoError(s.T(), err)
+	require.Len(s.T(), subAccounts, 2)
+	require.Equal(s.T(), "hedge", subAccounts[1].Name)
+	require.Len(s.T(), manager.SubAccounts(), 2)
+	require.Equal(s.T(), int64(4), manager.SubAccount(4).WSClient.SubAccountId)
+}
+
+func (s *SubAccountsUnitTestSuite) TestUnit_ListSubAccounts_Exchange() {
+	server, err := ryskfake.NewServer(&ryskfake.ServerConfiguration{})
+	require.NoError(s.T(), err)
+	defer server.Close()
+
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(s.T(), err)
+	wsClient, err := ws_client.NewRyskV2WSClient(&ws_client.RyskV2WSClientConfiguration{
+		Env:         constants.ENVIRONMENT_TESTNET,
+		PrivateKey:  hex.EncodeToString(crypto.FromECDSA(privateKey)),
+		RpcUrl:      server.URL(),
+		BaseUrl:     server.URL(),
+		WSRpcUrl:    server.RPCURL(),
+		WSStreamUrl: server.StreamURL(),
+	})
+	require.NoError(s.T(), err)
+	defer wsClient.RPCConnection.Close()
+	defer wsClient.StreamConnection.Close()
+	server.Credit(crypto.PubkeyToAddress(privateKey.PublicKey), 2, common.HexToAddress(constants.USDC_ADDRESS[constants.ENVIRONMENT_TESTNET]), big.NewInt(1))
+
+	// The exchange is the only reader of the RPC connection, the manager goes through it.
+	wsExchange, err := exchange.NewWSExchange(&exchange.WSExchangeConfiguration{Client: wsClient})
+	require.NoError(s.T(), err)
+	manager, err := NewSubAccountManager(&SubAccountManagerConfiguration{
+		APIClient: s.APIClient,
+		WSClient:  wsClient,
+		Exchange:  wsExchange,
+	})
+	require.NoError(s.T(), err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	subAccounts, err := manager.ListSubAccountsCtx(ctx, "LIST")
+	require.NoError(s.T(), err)
+	require.Len(s.T(), subAccounts, 2)
+	require.Equal(s.T(), int64(2), subAccounts[1].SubAccountId)
+	require.Len(s.T(), manager.SubAccounts(), 2)
+}
+
+func (s *SubAccountsUnitTestSuite) TestUnit_SubAccount_ConcurrentWrites() {
+	const requests = 50
+	upgrader := websocket.Upgrader{}
+	received := make(chan error, 2*requests)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		connection, err := upgrader.Upgrade(w, req, nil)
+		require.NoError(s.T(), err)
+		defer connection.Close()
+
+		for {
+			var request types.WebsocketRequest
+			if err := connection.ReadJSON(&request); err != nil {
+				if !websocket.IsUnexpectedCloseError(err) {
+					received <- err
+				}
+				return
+			}
+			received <- nil
+		}
+	}))
+	defer server.Close()
+
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(s.T(), err)
+	wsClient, err := ws_client.NewRyskV2WSClient(&ws_client.RyskV2WSClientConfiguration{
+		Env:         constants.ENVIRONMENT_TESTNET,
+		PrivateKey:  hex.EncodeToString(crypto.FromECDSA(privateKey)),
+		RpcUrl:      s.Server.URL,
+		BaseUrl:     s.Server.URL,
+		WSRpcUrl:    "ws" + strings.TrimPrefix(server.URL, "http"),
+		WSStreamUrl: "ws" + strings.TrimPrefix(server.URL, "http"),
+	})
+	require.NoError(s.T(), err)
+	defer wsClient.RPCConnection.Close()
+	defer wsClient.StreamConnection.Close()
+	manager, err := NewSubAccountManager(&SubAccountManagerConfiguration{APIClient: s.APIClient, WSClient: wsClient})
+	require.NoError(s.T(), err)
+
+	// Handles of two sub-accounts write to the shared RPC connection in parallel.
+	var wg sync.WaitGroup
+	for _, subAccount := range []*SubAccount{manager.SubAccount(1), manager.SubAccount(2)} {
+		wg.Add(1)
+		go func(subAccount *SubAccount) {
+			defer wg.Done()
+			for i := 0; i < requests; i++ {
+				require.NoError(s.T(), subAccount.WSClient.ServerTime("TIME"))
+			}
+		}(subAccount)
+	}
+	wg.Wait()
+
+	for i := 0; i < 2*requests; i++ {
+		select {
+		case err := <-received:
+			require.NoError(s.T(), err)
+		case <-time.After(5 * time.Second):
+			s.T().Fatalf("received %d of %d requests", i, 2*requests)
+		}
+	}
+}
+
+func (s *SubAccountsUnitTestSuite) TestUnit_ListSubAccounts_NoWSClient() {
+	manager, err := NewSubAccountManager(&SubAccountManagerConfiguration{APIClient: s.APIClient})
+	require.NoError(s.T(), err)
+
+	subAccounts, err := manager.ListSubAccounts("LIST")
+	require.Error(s.T(), err)
+	require.Nil(s.T(), subAccounts)
+}
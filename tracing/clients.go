@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/rysk-finance/v2_client_go/metrics"
+	"github.com/rysk-finance/v2_client_go/types"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HTTPClient is a `types.IHTTPClient` tracing the requests of another client, and propagating the trace context on
+// their headers.
+type HTTPClient struct {
+	client types.IHTTPClient
+	tracer *Tracer
+}
+
+// NewHTTPClient creates a new HTTPClient instance.
+//
+// Parameters:
+//   - client: The HTTP client sending the requests, e.g. from `utils.GetHTTPClient`.
+//   - tracer: The tracer of the requests.
+//
+// Returns:
+//   - A pointer to HTTPClient.
+func NewHTTPClient(client types.IHTTPClient, tracer *Tracer) *HTTPClient {
+	return &HTTPClient{client: client, tracer: tracer}
+}
+
+// Do sends the request within a span, child of the span of the request context.
+//
+// Parameters:
+//   - req: HTTP request instance to be sent.
+//
+// Returns:
+//   - *http.Response: HTTP response received from the server.
+//   - error: The error of the client.
+func (client *HTTPClient) Do(req *http.Request) (*http.Response, error) {
+	if client.tracer == nil {
+		return client.client.Do(req)
+	}
+	ctx, span := client.tracer.tracer.Start(
+		req.Context(),
+		req.Method+" "+metrics.Endpoint(req),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(ATTRIBUTE_HTTP_METHOD.String(req.Method), ATTRIBUTE_URL.String(req.URL.String())),
+	)
+	defer span.End()
+
+	// Propagate the trace context on the request headers.
+	req = req.WithContext(ctx)
+	client.tracer.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	res, err := client.client.Do(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(ATTRIBUTE_HTTP_STATUS.Int(res.StatusCode))
+	if res.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", res.StatusCode))
+	}
+	return res, nil
+}
+
+// Reader is a `types.IWSReader` ending the response spans of the requests answered by the messages read from a websocket.
+type Reader struct {
+	reader types.IWSReader
+	tracer *Tracer
+}
+
+// NewReader creates a Reader ending response spans with `ObserveMessage`.
+//
+// Parameters:
+//   - reader: The websocket connection.
+//   - tracer: The tracer of the requests.
+//
+// Returns:
+//   - A pointer to Reader.
+func NewReader(reader types.IWSReader, tracer *Tracer) *Reader {
+	return &Reader{reader: reader, tracer: tracer}
+}
+
+// ReadMessage reads the next message and observes it.
+//
+// Returns:
+//   - messageType: The websocket message type.
+//   - body: The message.
+//   - err: The error of the connection.
+func (reader *Reader) ReadMessage() (int, []byte, error) {
+	messageType, body, err := reader.reader.ReadMessage()
+	if err == nil {
+		reader.tracer.ObserveMessage(body)
+	}
+	return messageType, body, err
+}
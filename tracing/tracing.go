@@ -0,0 +1,279 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	INSTRUMENTATION_NAME     string = "github.com/rysk-finance/v2_client_go" // INSTRUMENTATION_NAME is the name of the tracer creating the spans.
+	MAX_PENDING_RPC_REQUESTS int    = 4096                                   // MAX_PENDING_RPC_REQUESTS is the number of RPC requests awaiting a response traced at once, later requests have no response span.
+)
+
+const (
+	SPAN_SIGN            string = "eip712.sign"        // SPAN_SIGN names the spans of EIP-712 signatures.
+	SPAN_WEBSOCKET_WRITE string = "websocket.write"    // SPAN_WEBSOCKET_WRITE names the spans of websocket requests being written.
+	SPAN_RPC_RESPONSE    string = "websocket.response" // SPAN_RPC_RESPONSE names the spans of websocket requests awaiting their response.
+)
+
+const (
+	ATTRIBUTE_PRODUCT        attribute.Key = "rysk.product"              // ATTRIBUTE_PRODUCT is the symbol of the products of a request.
+	ATTRIBUTE_ORDER_TYPE     attribute.Key = "rysk.order_type"           // ATTRIBUTE_ORDER_TYPE is the type of the order of a request, see `ORDER_TYPES`.
+	ATTRIBUTE_MESSAGE_ID     attribute.Key = "rysk.message_id"           // ATTRIBUTE_MESSAGE_ID is the message ID of a websocket request.
+	ATTRIBUTE_METHOD         attribute.Key = "rysk.method"               // ATTRIBUTE_METHOD is the method of a websocket request.
+	ATTRIBUTE_PRIMARY_TYPE   attribute.Key = "rysk.primary_type"         // ATTRIBUTE_PRIMARY_TYPE is the primary type of a signed EIP-712 message.
+	ATTRIBUTE_SUB_ACCOUNT_ID attribute.Key = "rysk.sub_account_id"       // ATTRIBUTE_SUB_ACCOUNT_ID is the sub-account of a request.
+	ATTRIBUTE_ERROR_CODE     attribute.Key = "rysk.error.code"           // ATTRIBUTE_ERROR_CODE is the exchange error code of a failed request.
+	ATTRIBUTE_HTTP_METHOD    attribute.Key = "http.request.method"       // ATTRIBUTE_HTTP_METHOD is the HTTP method of a REST request.
+	ATTRIBUTE_HTTP_STATUS    attribute.Key = "http.response.status_code" // ATTRIBUTE_HTTP_STATUS is the HTTP status code of a REST response.
+	ATTRIBUTE_URL            attribute.Key = "url.full"                  // ATTRIBUTE_URL is the URL of a REST request.
+)
+
+// ORDER_TYPES maps order types to the value of the `ATTRIBUTE_ORDER_TYPE` attribute.
+var ORDER_TYPES = map[types.OrderType]string{
+	constants.ORDER_TYPE_LIMIT:             "limit",
+	constants.ORDER_TYPE_LIMIT_MAKER:       "limit_maker",
+	constants.ORDER_TYPE_MARKET:            "market",
+	constants.ORDER_TYPE_STOP_LOSS:         "stop_loss",
+	constants.ORDER_TYPE_STOP_LOSS_LIMIT:   "stop_loss_limit",
+	constants.ORDER_TYPE_TAKE_PROFIT:       "take_profit",
+	constants.ORDER_TYPE_TAKE_PROFIT_LIMIT: "take_profit_limit",
+}
+
+// TracingConfiguration holds the configuration for client tracing.
+type TracingConfiguration struct {
+	TracerProvider trace.TracerProvider          // Provider of the tracer creating the spans. Defaults to `otel.GetTracerProvider()`.
+	Propagator     propagation.TextMapPropagator // Propagator of the trace context on HTTP headers. Defaults to W3C trace context.
+}
+
+// Tracer creates OpenTelemetry spans for the operations of the REST and websocket clients.
+// One instance can be shared by the clients of several accounts. A nil *Tracer traces nothing.
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+
+	mutex   sync.Mutex            // mutex guards pending.
+	pending map[string]trace.Span // pending holds the response spans of RPC requests awaiting a response by message ID.
+}
+
+// NewTracer creates a new Tracer instance.
+//
+// Parameters:
+//   - config: A pointer to TracingConfiguration containing the configuration settings.
+//
+// Returns:
+//   - A pointer to Tracer.
+func NewTracer(config *TracingConfiguration) *Tracer {
+	provider := config.TracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	propagator := config.Propagator
+	if propagator == nil {
+		propagator = propagation.TraceContext{}
+	}
+	return &Tracer{
+		tracer:     provider.Tracer(INSTRUMENTATION_NAME),
+		propagator: propagator,
+		pending:    make(map[string]trace.Span),
+	}
+}
+
+// Start starts a span, child of the span of the context if any.
+//
+// Parameters:
+//   - ctx: The context of the operation.
+//   - name: The name of the span.
+//   - attributes: The attributes of the span.
+//
+// Returns:
+//   - The context holding the span, ctx itself when the tracer is nil.
+//   - The span, to be ended by the caller. It records nothing when the tracer is nil.
+func (tracer *Tracer) Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	if tracer == nil {
+		return ctx, noop.Span{}
+	}
+	return tracer.tracer.Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// End ends a span, recording the error of the operation if any.
+//
+// Parameters:
+//   - span: The span.
+//   - err: The error of the operation, nil if it succeeded.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// RPCSent starts the response span of a websocket request, child of the span of the context, until its response is
+// passed to `ObserveMessage`. Requests reusing the message ID of a request awaiting its response end its span.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - messageId: The message ID of the request.
+//   - method: The method of the request.
+func (tracer *Tracer) RPCSent(ctx context.Context, messageId string, method types.WSMethod) {
+	if tracer == nil || messageId == "" {
+		return
+	}
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+	if previous, ok := tracer.pending[messageId]; ok {
+		previous.End()
+	} else if len(tracer.pending) >= MAX_PENDING_RPC_REQUESTS {
+		return
+	}
+	_, tracer.pending[messageId] = tracer.tracer.Start(
+		ctx,
+		SPAN_RPC_RESPONSE,
+		trace.WithAttributes(ATTRIBUTE_MESSAGE_ID.String(messageId), ATTRIBUTE_METHOD.String(string(method))),
+	)
+}
+
+// RPCFailed ends the response span of a websocket request which could not be sent.
+//
+// Parameters:
+//   - messageId: The message ID of the request.
+//   - err: The error of the request.
+func (tracer *Tracer) RPCFailed(messageId string, err error) {
+	if tracer == nil {
+		return
+	}
+	if span, ok := tracer.takePending(messageId); ok {
+		End(span, err)
+	}
+}
+
+// ObserveMessage ends the response span of the request answered by a message read from a websocket, recording the
+// exchange error if any. Other messages are ignored.
+//
+// Parameters:
+//   - body: The message.
+func (tracer *Tracer) ObserveMessage(body []byte) {
+	if tracer == nil {
+		return
+	}
+	var response types.WebsocketResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return
+	}
+	span, ok := tracer.takePending(response.ID)
+	if !ok {
+		return
+	}
+	var err error
+	if response.Error != nil {
+		err = utils.NewRPCError(&response)
+		var rpcError *utils.RPCError
+		if errors.As(err, &rpcError) {
+			span.SetAttributes(ATTRIBUTE_ERROR_CODE.Int(rpcError.Code))
+		}
+	}
+	End(span, err)
+}
+
+// takePending removes and returns a response span.
+func (tracer *Tracer) takePending(messageId string) (trace.Span, bool) {
+	if messageId == "" {
+		return nil, false
+	}
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+	span, ok := tracer.pending[messageId]
+	delete(tracer.pending, messageId)
+	return span, ok
+}
+
+// Product returns the `ATTRIBUTE_PRODUCT` attribute of a product.
+//
+// Parameters:
+//   - product: The product, possibly nil.
+//
+// Returns:
+//   - The attribute, holding the product symbol.
+func Product(product *types.Product) attribute.KeyValue {
+	if product == nil {
+		return ATTRIBUTE_PRODUCT.String("")
+	}
+	return ATTRIBUTE_PRODUCT.String(product.Symbol)
+}
+
+// Products returns the `ATTRIBUTE_PRODUCT` attribute of several products.
+//
+// Parameters:
+//   - products: The products.
+//
+// Returns:
+//   - The attribute, holding the product symbols.
+func Products(products []*types.Product) attribute.KeyValue {
+	symbols := make([]string, 0, len(products))
+	for _, product := range products {
+		if product != nil {
+			symbols = append(symbols, product.Symbol)
+		}
+	}
+	return ATTRIBUTE_PRODUCT.StringSlice(symbols)
+}
+
+// OrderType returns the `ATTRIBUTE_ORDER_TYPE` attribute of an order type.
+//
+// Parameters:
+//   - orderType: The order type.
+//
+// Returns:
+//   - The attribute, holding the name of the order type from `ORDER_TYPES`, or its number if unknown.
+func OrderType(orderType types.OrderType) attribute.KeyValue {
+	if name, ok := ORDER_TYPES[orderType]; ok {
+		return ATTRIBUTE_ORDER_TYPE.String(name)
+	}
+	return ATTRIBUTE_ORDER_TYPE.Int64(int64(orderType))
+}
+
+// MessageId returns the `ATTRIBUTE_MESSAGE_ID` attribute of a websocket request.
+//
+// Parameters:
+//   - messageId: The message ID of the request.
+//
+// Returns:
+//   - The attribute.
+func MessageId(messageId string) attribute.KeyValue {
+	return ATTRIBUTE_MESSAGE_ID.String(messageId)
+}
+
+// SubAccountId returns the `ATTRIBUTE_SUB_ACCOUNT_ID` attribute of a request.
+//
+// Parameters:
+//   - subAccountId: The ID of the sub-account.
+//
+// Returns:
+//   - The attribute.
+func SubAccountId(subAccountId int64) attribute.KeyValue {
+	return ATTRIBUTE_SUB_ACCOUNT_ID.Int64(subAccountId)
+}
+
+// PrimaryType returns the `ATTRIBUTE_PRIMARY_TYPE` attribute of a signed message.
+//
+// Parameters:
+//   - primaryType: The primary type of the message.
+//
+// Returns:
+//   - The attribute.
+func PrimaryType(primaryType types.PrimaryType) attribute.KeyValue {
+	return ATTRIBUTE_PRIMARY_TYPE.String(string(primaryType))
+}
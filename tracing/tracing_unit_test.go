@@ -0,0 +1,204 @@
+//go:build !integration
+// +build !integration
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type TracingUnitTestSuite struct {
+	suite.Suite
+	Exporter *tracetest.InMemoryExporter
+	Tracer   *Tracer
+}
+
+func (s *TracingUnitTestSuite) SetupTest() {
+	s.Exporter = tracetest.NewInMemoryExporter()
+	s.Tracer = NewTracer(&TracingConfiguration{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSyncer(s.Exporter)),
+	})
+}
+
+func TestRunSuiteUnit_TracingUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(TracingUnitTestSuite))
+}
+
+// messages is a websocket reader returning messages in order, then an error.
+type messages []string
+
+func (reader *messages) ReadMessage() (int, []byte, error) {
+	if len(*reader) == 0 {
+		return 0, nil, io.EOF
+	}
+	body := (*reader)[0]
+	*reader = (*reader)[1:]
+	return 1, []byte(body), nil
+}
+
+// span returns the only ended span with a name.
+func (s *TracingUnitTestSuite) span(name string) tracetest.SpanStub {
+	var spans []tracetest.SpanStub
+	for _, span := range s.Exporter.GetSpans() {
+		if span.Name == name {
+			spans = append(spans, span)
+		}
+	}
+	require.Len(s.T(), spans, 1, name)
+	return spans[0]
+}
+
+func (s *TracingUnitTestSuite) TestUnit_Start() {
+	ctx, span := s.Tracer.Start(context.Background(), "parent", Product(&constants.PRODUCT_ETH_PERP))
+	_, child := s.Tracer.Start(ctx, "child")
+	End(child, errors.New("failed"))
+	End(span, nil)
+
+	parent := s.span("parent")
+	require.Equal(s.T(), codes.Unset, parent.Status.Code)
+	require.Contains(s.T(), parent.Attributes, ATTRIBUTE_PRODUCT.String(constants.PRODUCT_ETH_PERP.Symbol))
+	failed := s.span("child")
+	require.Equal(s.T(), parent.SpanContext.SpanID(), failed.Parent.SpanID())
+	require.Equal(s.T(), codes.Error, failed.Status.Code)
+	require.Len(s.T(), failed.Events, 1)
+
+	// A nil tracer keeps the context and traces nothing.
+	var tracer *Tracer
+	background := context.Background()
+	nilCtx, nilSpan := tracer.Start(background, "nil")
+	require.Equal(s.T(), background, nilCtx)
+	require.False(s.T(), nilSpan.IsRecording())
+	End(nilSpan, errors.New("failed"))
+	tracer.RPCSent(background, "1", constants.WS_METHOD_LOGIN)
+	tracer.RPCFailed("1", errors.New("failed"))
+	tracer.ObserveMessage([]byte(`{"id":"1"}`))
+	require.Len(s.T(), s.Exporter.GetSpans(), 2)
+}
+
+func (s *TracingUnitTestSuite) TestUnit_HTTPClient() {
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		traceparent = req.Header.Get("traceparent")
+		if req.Method == http.MethodPost {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+	client := NewHTTPClient(http.DefaultClient, s.Tracer)
+
+	// Requests are traced as children of the span of their context, which is propagated.
+	ctx, parent := s.Tracer.Start(context.Background(), "parent")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/products/ethperp", nil)
+	res, err := client.Do(req)
+	require.NoError(s.T(), err)
+	res.Body.Close()
+	parent.End()
+	span := s.span("GET /products")
+	require.Equal(s.T(), parent.SpanContext().SpanID(), span.Parent.SpanID())
+	require.Equal(s.T(), trace.SpanKindClient, span.SpanKind)
+	require.Equal(s.T(), codes.Unset, span.Status.Code)
+	require.Contains(s.T(), span.Attributes, ATTRIBUTE_HTTP_STATUS.Int(http.StatusOK))
+	require.Contains(s.T(), span.Attributes, ATTRIBUTE_URL.String(server.URL+"/products/ethperp"))
+	require.Equal(s.T(), "00-"+span.SpanContext.TraceID().String()+"-"+span.SpanContext.SpanID().String()+"-01", traceparent)
+
+	// Failed requests are errors.
+	req, _ = http.NewRequest(http.MethodPost, server.URL+"/order", strings.NewReader(`{}`))
+	res, err = client.Do(req)
+	require.NoError(s.T(), err)
+	res.Body.Close()
+	span = s.span("POST /order")
+	require.Equal(s.T(), codes.Error, span.Status.Code)
+	require.False(s.T(), span.Parent.IsValid())
+
+	req, _ = http.NewRequest(http.MethodGet, "http://127.0.0.1:1/time", nil)
+	_, err = client.Do(req)
+	require.Error(s.T(), err)
+	span = s.span("GET /time")
+	require.Equal(s.T(), codes.Error, span.Status.Code)
+	require.NotContains(s.T(), span.Attributes, ATTRIBUTE_HTTP_STATUS.Int(0))
+
+	// Clients without tracer only send requests.
+	traceparent = ""
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/products", nil)
+	res, err = NewHTTPClient(http.DefaultClient, nil).Do(req)
+	require.NoError(s.T(), err)
+	res.Body.Close()
+	require.Empty(s.T(), traceparent)
+	require.Len(s.T(), s.Exporter.GetSpans(), 4)
+}
+
+func (s *TracingUnitTestSuite) TestUnit_RPC() {
+	ctx, parent := s.Tracer.Start(context.Background(), "parent")
+	s.Tracer.RPCSent(ctx, "login", constants.WS_METHOD_LOGIN)
+	s.Tracer.RPCSent(ctx, "order", constants.WS_METHOD_NEW_ORDER)
+	s.Tracer.RPCSent(ctx, "cancel", constants.WS_METHOD_CANCEL_ORDER)
+	parent.End()
+	s.Tracer.RPCFailed("cancel", errors.New("closed"))
+
+	// Responses end their span once, notifications and unknown IDs are ignored.
+	reader := NewReader(&messages{
+		`{"id":"login","result":true}`,
+		`{"id":"login","result":true}`,
+		`{"id":"order","error":{"code":400,"message":"nonce used"}}`,
+		`{"method":"account.updates","params":{}}`,
+		`not json`,
+	}, s.Tracer)
+	for {
+		if _, _, err := reader.ReadMessage(); err != nil {
+			require.ErrorIs(s.T(), err, io.EOF)
+			break
+		}
+	}
+	spans := s.Exporter.GetSpans()
+	require.Len(s.T(), spans, 4)
+	for i, method := range []types.WSMethod{constants.WS_METHOD_CANCEL_ORDER, constants.WS_METHOD_LOGIN, constants.WS_METHOD_NEW_ORDER} {
+		span := spans[i+1]
+		require.Equal(s.T(), SPAN_RPC_RESPONSE, span.Name)
+		require.Equal(s.T(), parent.SpanContext().SpanID(), span.Parent.SpanID())
+		require.Contains(s.T(), span.Attributes, ATTRIBUTE_METHOD.String(string(method)))
+	}
+	require.Equal(s.T(), codes.Error, spans[1].Status.Code)
+	require.Equal(s.T(), codes.Unset, spans[2].Status.Code)
+	require.Equal(s.T(), codes.Error, spans[3].Status.Code)
+	require.Contains(s.T(), spans[3].Attributes, ATTRIBUTE_ERROR_CODE.Int(http.StatusBadRequest))
+	require.Empty(s.T(), s.Tracer.pending)
+
+	// Requests reusing a message ID end the previous span.
+	s.Tracer.RPCSent(context.Background(), "1", constants.WS_METHOD_LOGIN)
+	s.Tracer.RPCSent(context.Background(), "1", constants.WS_METHOD_LOGIN)
+	require.Len(s.T(), s.Exporter.GetSpans(), 5)
+	require.Len(s.T(), s.Tracer.pending, 1)
+
+	// Pending requests are bounded.
+	for i := 0; i <= MAX_PENDING_RPC_REQUESTS; i++ {
+		s.Tracer.RPCSent(context.Background(), strings.Repeat("x", i+1), constants.WS_METHOD_LOGIN)
+	}
+	require.Len(s.T(), s.Tracer.pending, MAX_PENDING_RPC_REQUESTS)
+}
+
+func (s *TracingUnitTestSuite) TestUnit_Attributes() {
+	require.Equal(s.T(), ATTRIBUTE_PRODUCT.String(""), Product(nil))
+	require.Equal(s.T(), ATTRIBUTE_PRODUCT.StringSlice([]string{"ethperp", "btcperp"}), Products([]*types.Product{&constants.PRODUCT_ETH_PERP, nil, &constants.PRODUCT_BTC_PERP}))
+	require.Equal(s.T(), ATTRIBUTE_ORDER_TYPE.String("limit_maker"), OrderType(constants.ORDER_TYPE_LIMIT_MAKER))
+	require.Equal(s.T(), ATTRIBUTE_ORDER_TYPE.Int64(42), OrderType(42))
+	require.Equal(s.T(), ATTRIBUTE_MESSAGE_ID.String("1"), MessageId("1"))
+	require.Equal(s.T(), ATTRIBUTE_SUB_ACCOUNT_ID.Int64(1), SubAccountId(1))
+	require.Equal(s.T(), ATTRIBUTE_PRIMARY_TYPE.String("Order"), PrimaryType(constants.PRIMARY_TYPE_ORDER))
+}
@@ -0,0 +1,936 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+type FillModel string
+
+const (
+	FILL_MODEL_TOUCH FillModel = "touch" // Resting orders fill completely as soon as the market trades or quotes at their price.
+	FILL_MODEL_QUEUE FillModel = "queue" // Resting orders fill with the traded quantity left once the quantity queued ahead of them at their price has traded.
+)
+
+const (
+	PAPER_ORDER_ID_PREFIX string = "paper-"
+	BPS_DENOMINATOR       int64  = 10000
+)
+
+// ErrOrderNotFound is returned by the paper trader when an order to cancel is not open.
+var ErrOrderNotFound = errors.New("order not found")
+
+// DEFAULT_PRODUCTS are the products the paper trader trades unless configured otherwise.
+var DEFAULT_PRODUCTS = []types.Product{constants.PRODUCT_ETH_PERP, constants.PRODUCT_BTC_PERP, constants.PRODUCT_SOL_PERP}
+
+// PaperTraderConfiguration holds the configuration for the paper trader.
+type PaperTraderConfiguration struct {
+	Account        string                            // Account address reported on orders, positions and balances.
+	SubAccountId   int64                             // Sub-account ID reported on orders, positions and balances.
+	Asset          string                            // Collateral asset address. Defaults to `constants.USDC_ADDRESS[constants.ENVIRONMENT_MAINNET]`.
+	InitialBalance *big.Int                          // Starting collateral balance in wei (e18). Defaults to zero.
+	Products       []types.Product                   // Tradable products. Defaults to `DEFAULT_PRODUCTS`.
+	FillModel      FillModel                         // Model filling resting orders. Can be `FILL_MODEL_TOUCH` or `FILL_MODEL_QUEUE`. Defaults to `FILL_MODEL_TOUCH`.
+	SlippageBps    int64                             // Adverse slippage applied to taker fills in basis points.
+	MakerFeeBps    int64                             // Fee charged on maker fills in basis points, negative for rebates.
+	TakerFeeBps    int64                             // Fee charged on taker fills in basis points, negative for rebates.
+	OnUpdate       func(update *types.AccountUpdate) // Optional callback invoked for every simulated order, position and balance change.
+	OnFill         func(fill *Fill)                  // Optional callback invoked for every simulated fill.
+	Now            func() time.Time                  // Optional clock. Defaults to `time.Now`.
+}
+
+// PaperTrader simulates the trading surface locally, filling orders against market data streams.
+type PaperTrader struct {
+	account      string
+	subAccountId int64
+	asset        string
+	fillModel    FillModel
+	slippageBps  int64
+	makerFeeBps  int64
+	takerFeeBps  int64
+	onUpdate     func(update *types.AccountUpdate)
+	onFill       func(fill *Fill)
+	now          func() time.Time
+	products     map[int64]types.Product // products holds the tradable products by ID.
+	symbols      map[string]int64        // symbols maps product symbols to IDs.
+
+	mutex     sync.Mutex
+	books     map[int64]*paperBook     // books holds the last depth snapshot per product, minus simulated taker fills.
+	depthSeen map[int64]bool           // depthSeen marks products whose book comes from depth snapshots rather than klines.
+	orders    map[string]*paperOrder   // orders holds the resting orders by ID.
+	positions map[int64]*paperPosition // positions holds the positions by product ID.
+	balance   *big.Int                 // balance is the collateral balance in wei (e18).
+	sequence  int64                    // sequence numbers orders.
+}
+
+// Fill is a simulated execution of an order.
+type Fill struct {
+	OrderId   string `json:"orderId"`   // The ID of the filled order.
+	ProductId int64  `json:"productId"` // The ID of the product.
+	IsBuy     bool   `json:"isBuy"`     // Whether the order was buying or selling.
+	IsMaker   bool   `json:"isMaker"`   // Whether the order was resting.
+	Price     string `json:"price"`     // Fill price in wei (e18).
+	Quantity  string `json:"quantity"`  // Fill quantity in wei (e18).
+	Fee       string `json:"fee"`       // Fee charged in wei (e18), negative for rebates.
+	Time      int64  `json:"time"`      // UNIX timestamp (in ms) of the fill.
+}
+
+// paperEvents collects the updates and fills of an operation, notified once the mutex is released.
+type paperEvents struct {
+	updates []*types.AccountUpdate
+	fills   []*Fill
+}
+
+// paperOrder is a simulated order with parsed quantities.
+type paperOrder struct {
+	types.Order
+	sequence   int64    // sequence orders resting orders by arrival.
+	price      *big.Int // price is nil for market orders.
+	remaining  *big.Int
+	filled     *big.Int
+	queueAhead *big.Int // queueAhead is the quantity resting ahead at the same price, for `FILL_MODEL_QUEUE`.
+}
+
+// paperLevel is a price level of a depth snapshot.
+type paperLevel struct {
+	price    *big.Int
+	quantity *big.Int
+}
+
+// paperBook is a depth snapshot with levels ordered best first.
+type paperBook struct {
+	bids []*paperLevel
+	asks []*paperLevel
+}
+
+// paperPosition is a simulated perpetual position.
+type paperPosition struct {
+	quantity      *big.Int
+	avgEntryPrice *big.Int
+}
+
+// NewPaperTrader creates a new PaperTrader instance.
+//
+// Parameters:
+//   - config: A pointer to PaperTraderConfiguration containing the configuration settings.
+//
+// Returns:
+//   - A pointer to PaperTrader.
+//   - An error if the fill model is unknown.
+func NewPaperTrader(config *PaperTraderConfiguration) (*PaperTrader, error) {
+	trader := &PaperTrader{
+		account:      config.Account,
+		subAccountId: config.SubAccountId,
+		asset:        config.Asset,
+		fillModel:    config.FillModel,
+		slippageBps:  config.SlippageBps,
+		makerFeeBps:  config.MakerFeeBps,
+		takerFeeBps:  config.TakerFeeBps,
+		onUpdate:     config.OnUpdate,
+		onFill:       config.OnFill,
+		now:          config.Now,
+		products:     make(map[int64]types.Product),
+		symbols:      make(map[string]int64),
+		books:        make(map[int64]*paperBook),
+		depthSeen:    make(map[int64]bool),
+		orders:       make(map[string]*paperOrder),
+		positions:    make(map[int64]*paperPosition),
+		balance:      new(big.Int),
+	}
+	if trader.asset == "" {
+		trader.asset = constants.USDC_ADDRESS[constants.ENVIRONMENT_MAINNET]
+	}
+	if trader.fillModel == "" {
+		trader.fillModel = FILL_MODEL_TOUCH
+	}
+	if trader.fillModel != FILL_MODEL_TOUCH && trader.fillModel != FILL_MODEL_QUEUE {
+		return nil, fmt.Errorf("unknown fill model %q", trader.fillModel)
+	}
+	if trader.now == nil {
+		trader.now = time.Now
+	}
+	if config.InitialBalance != nil {
+		trader.balance.Set(config.InitialBalance)
+	}
+
+	products := config.Products
+	if len(products) == 0 {
+		products = DEFAULT_PRODUCTS
+	}
+	for _, product := range products {
+		trader.products[product.Id] = product
+		trader.symbols[product.Symbol] = product.Id
+	}
+	return trader, nil
+}
+
+// Run feeds the paper trader with `@depth`, `@trade` and `@klines_*` messages read from a market data stream connection
+// until reading fails. Messages that cannot be applied, such as subscription acknowledgements, are skipped.
+//
+// Parameters:
+//   - connection: Stream connection implementing `types.IWSReader` interface, e.g. `RyskV2WSClient.StreamConnection`.
+//
+// Returns:
+//   - The error that stopped reading.
+func (trader *PaperTrader) Run(connection types.IWSReader) error {
+	for {
+		_, body, err := connection.ReadMessage()
+		if err != nil {
+			return err
+		}
+		trader.HandleStreamMessage(body)
+	}
+}
+
+// HandleStreamMessage applies a raw market data stream message.
+//
+// Parameters:
+//   - body: The JSON message, `{"stream": "<symbol>@<topic>", "data": ...}`.
+//
+// Returns:
+//   - An error if a `@depth`, `@trade` or `@klines_*` message cannot be applied. Other messages are ignored.
+func (trader *PaperTrader) HandleStreamMessage(body []byte) error {
+	event, err := DecodeMarketEvent(body)
+	if err != nil || event == nil {
+		return err
+	}
+	return trader.HandleMarketEvent(event)
+}
+
+// HandleMarketEvent applies a decoded market data event.
+//
+// Parameters:
+//   - event: The market data event.
+//
+// Returns:
+//   - An error if the event cannot be applied.
+func (trader *PaperTrader) HandleMarketEvent(event *MarketEvent) error {
+	switch {
+	case event.Trade != nil:
+		return trader.HandleTrade(event.Trade)
+	case event.Depth != nil:
+		return trader.HandleDepth(event.Depth)
+	case event.Kline != nil:
+		return trader.HandleKline(event.Kline)
+	}
+	return nil
+}
+
+// HandleDepth replaces the order book of a product with a depth snapshot and fills resting orders it crosses.
+//
+// Parameters:
+//   - depth: The depth snapshot.
+//
+// Returns:
+//   - An error if the product is unknown or if a level is invalid.
+func (trader *PaperTrader) HandleDepth(depth *types.OrderBookDepth) error {
+	productId, ok := trader.symbols[depth.Symbol]
+	if !ok {
+		return fmt.Errorf("unknown product %q", depth.Symbol)
+	}
+	bids, err := parseLevels(depth.Bids)
+	if err != nil {
+		return err
+	}
+	asks, err := parseLevels(depth.Asks)
+	if err != nil {
+		return err
+	}
+
+	trader.mutex.Lock()
+	events := &paperEvents{}
+	trader.expireOrders(events)
+	book := &paperBook{bids: bids, asks: asks}
+	trader.books[productId] = book
+	trader.depthSeen[productId] = true
+	for _, order := range trader.restingOrders(productId) {
+		// Orders behind fewer resting orders move up the queue.
+		if order.queueAhead != nil {
+			if level := findLevel(book.side(order.IsBuy), order.price); level == nil {
+				order.queueAhead.SetInt64(0)
+			} else if level.quantity.Cmp(order.queueAhead) < 0 {
+				order.queueAhead.Set(level.quantity)
+			}
+		}
+
+		// The opposite side quoting at or through the order fills it at its price.
+		for _, level := range book.side(!order.IsBuy) {
+			if order.remaining.Sign() == 0 || !crosses(order.IsBuy, order.price, level.price) {
+				break
+			}
+			quantity := new(big.Int).Set(order.remaining)
+			if trader.fillModel == FILL_MODEL_QUEUE && level.quantity.Cmp(quantity) < 0 {
+				quantity.Set(level.quantity)
+			}
+			level.quantity.Sub(level.quantity, minQuantity(quantity, level.quantity))
+			trader.fill(order, quantity, order.price, true, events)
+		}
+		book.prune()
+	}
+	trader.mutex.Unlock()
+
+	trader.notify(events)
+	return nil
+}
+
+// HandleTrade fills resting orders of a product against a public trade.
+//
+// Parameters:
+//   - trade: The public trade.
+//
+// Returns:
+//   - An error if the product is unknown or if the trade is invalid.
+func (trader *PaperTrader) HandleTrade(trade *types.Trade) error {
+	productId := trade.ProductId
+	if id, ok := trader.symbols[trade.Symbol]; ok {
+		productId = id
+	}
+	if _, ok := trader.products[productId]; !ok {
+		return fmt.Errorf("unknown product %q", trade.Symbol)
+	}
+	price, ok := new(big.Int).SetString(trade.Price, 10)
+	if !ok {
+		return fmt.Errorf("invalid trade price %q", trade.Price)
+	}
+	available, ok := new(big.Int).SetString(trade.Quantity, 10)
+	if !ok {
+		return fmt.Errorf("invalid trade quantity %q", trade.Quantity)
+	}
+
+	trader.mutex.Lock()
+	events := &paperEvents{}
+	trader.expireOrders(events)
+	orders := trader.restingOrders(productId)
+	sort.SliceStable(orders, func(i, j int) bool {
+		// Best priced orders trade first.
+		if comparison := orders[i].price.Cmp(orders[j].price); comparison != 0 {
+			return (comparison > 0) == orders[i].IsBuy
+		}
+		return orders[i].sequence < orders[j].sequence
+	})
+	for _, order := range orders {
+		if !crosses(order.IsBuy, order.price, price) {
+			continue
+		}
+		if trader.fillModel == FILL_MODEL_TOUCH {
+			trader.fill(order, new(big.Int).Set(order.remaining), order.price, true, events)
+			continue
+		}
+
+		// Trades at the order price first consume the queue ahead of it.
+		if price.Cmp(order.price) == 0 {
+			consumed := minQuantity(order.queueAhead, available)
+			order.queueAhead.Sub(order.queueAhead, consumed)
+			available.Sub(available, consumed)
+		}
+		quantity := minQuantity(order.remaining, available)
+		if quantity.Sign() == 0 {
+			continue
+		}
+		available.Sub(available, quantity)
+		trader.fill(order, quantity, order.price, true, events)
+	}
+	trader.mutex.Unlock()
+
+	trader.notify(events)
+	return nil
+}
+
+// HandleKline fills resting orders of a product whose price the kline range reached. When no depth snapshot was
+// received for the product, the kline close is also quoted on both sides with the kline volume, so that taker
+// orders can fill in kline-only replays.
+//
+// Parameters:
+//   - kline: The kline.
+//
+// Returns:
+//   - An error if the product is unknown or if the kline is invalid.
+func (trader *PaperTrader) HandleKline(kline *types.Kline) error {
+	productId, ok := trader.symbols[kline.Symbol]
+	if !ok {
+		return fmt.Errorf("unknown product %q", kline.Symbol)
+	}
+	low, ok := new(big.Int).SetString(kline.Low, 10)
+	if !ok {
+		return fmt.Errorf("invalid kline low %q", kline.Low)
+	}
+	high, ok := new(big.Int).SetString(kline.High, 10)
+	if !ok {
+		return fmt.Errorf("invalid kline high %q", kline.High)
+	}
+	closePrice, ok := new(big.Int).SetString(kline.Close, 10)
+	if !ok {
+		return fmt.Errorf("invalid kline close %q", kline.Close)
+	}
+	volume, ok := new(big.Int).SetString(kline.Volume, 10)
+	if !ok {
+		return fmt.Errorf("invalid kline volume %q", kline.Volume)
+	}
+
+	trader.mutex.Lock()
+	events := &paperEvents{}
+	trader.expireOrders(events)
+	for _, order := range trader.restingOrders(productId) {
+		// The queue model needs the range to trade through the price, as the volume at the price is unknown.
+		extreme := low
+		if !order.IsBuy {
+			extreme = high
+		}
+		if !crosses(order.IsBuy, order.price, extreme) {
+			continue
+		}
+		if trader.fillModel == FILL_MODEL_QUEUE && extreme.Cmp(order.price) == 0 {
+			continue
+		}
+		trader.fill(order, new(big.Int).Set(order.remaining), order.price, true, events)
+	}
+	if !trader.depthSeen[productId] {
+		trader.books[productId] = &paperBook{
+			bids: []*paperLevel{{price: new(big.Int).Set(closePrice), quantity: new(big.Int).Set(volume)}},
+			asks: []*paperLevel{{price: closePrice, quantity: volume}},
+		}
+		trader.books[productId].prune()
+	}
+	trader.mutex.Unlock()
+
+	trader.notify(events)
+	return nil
+}
+
+// NewOrder simulates a new order, taking liquidity from the last depth snapshot and resting any remainder.
+//
+// Parameters:
+//   - ctx: Context of the request, unused by the simulation.
+//   - params: The order parameters.
+//
+// Returns:
+//   - A pointer to the simulated types.Order.
+//   - An error if the order is invalid.
+func (trader *PaperTrader) NewOrder(ctx context.Context, params *types.NewOrderRequest) (*types.Order, error) {
+	trader.mutex.Lock()
+	events := &paperEvents{}
+	trader.expireOrders(events)
+	order, err := trader.newOrder(params, events)
+	trader.mutex.Unlock()
+
+	trader.notify(events)
+	return order, err
+}
+
+// CancelOrderAndReplace cancels an open order and simulates a new one in its place.
+//
+// Parameters:
+//   - ctx: Context of the request, unused by the simulation.
+//   - params: The cancellation and replacement parameters.
+//
+// Returns:
+//   - A pointer to the replacement types.Order.
+//   - An error wrapping `ErrOrderNotFound` if the order is not open, or if the new order is invalid.
+func (trader *PaperTrader) CancelOrderAndReplace(ctx context.Context, params *types.CancelOrderAndReplaceRequest) (*types.Order, error) {
+	if params.NewOrder == nil || params.NewOrder.Product == nil {
+		return nil, fmt.Errorf("replacement order requires a product")
+	}
+
+	trader.mutex.Lock()
+	events := &paperEvents{}
+	trader.expireOrders(events)
+	order, err := trader.cancelOrder(params.NewOrder.Product.Id, params.IdToCancel, events)
+	if err == nil {
+		order, err = trader.newOrder(params.NewOrder, events)
+	}
+	trader.mutex.Unlock()
+
+	trader.notify(events)
+	return order, err
+}
+
+// CancelOrder cancels an open order.
+//
+// Parameters:
+//   - ctx: Context of the request, unused by the simulation.
+//   - params: The cancellation parameters.
+//
+// Returns:
+//   - A pointer to the cancelled types.Order.
+//   - An error wrapping `ErrOrderNotFound` if the order is not open.
+func (trader *PaperTrader) CancelOrder(ctx context.Context, params *types.CancelOrderRequest) (*types.Order, error) {
+	if params.Product == nil {
+		return nil, fmt.Errorf("cancellation requires a product")
+	}
+
+	trader.mutex.Lock()
+	events := &paperEvents{}
+	trader.expireOrders(events)
+	order, err := trader.cancelOrder(params.Product.Id, params.IdToCancel, events)
+	trader.mutex.Unlock()
+
+	trader.notify(events)
+	return order, err
+}
+
+// CancelAllOpenOrders cancels all open orders for a product.
+//
+// Parameters:
+//   - ctx: Context of the request, unused by the simulation.
+//   - product: The product whose orders are cancelled.
+//
+// Returns:
+//   - A slice of the cancelled types.Order.
+//   - An error if no product is provided.
+func (trader *PaperTrader) CancelAllOpenOrders(ctx context.Context, product *types.Product) ([]types.Order, error) {
+	if product == nil {
+		return nil, fmt.Errorf("cancellation requires a product")
+	}
+
+	trader.mutex.Lock()
+	events := &paperEvents{}
+	trader.expireOrders(events)
+	cancelled := []types.Order{}
+	for _, order := range trader.restingOrders(product.Id) {
+		result, _ := trader.cancelOrder(product.Id, order.Id, events)
+		cancelled = append(cancelled, *result)
+	}
+	trader.mutex.Unlock()
+
+	trader.notify(events)
+	return cancelled, nil
+}
+
+// ListOpenOrders returns the open orders for a product, or for all products when product is nil.
+//
+// Parameters:
+//   - ctx: Context of the request, unused by the simulation.
+//   - product: The product whose orders are listed.
+//
+// Returns:
+//   - A slice of types.Order ordered by creation.
+//   - A nil error, paper listing cannot fail.
+func (trader *PaperTrader) ListOpenOrders(ctx context.Context, product *types.Product) ([]types.Order, error) {
+	trader.mutex.Lock()
+	events := &paperEvents{}
+	trader.expireOrders(events)
+	var productId int64
+	if product != nil {
+		productId = product.Id
+	}
+	orders := []types.Order{}
+	for _, order := range trader.restingOrders(productId) {
+		orders = append(orders, order.Order)
+	}
+	trader.mutex.Unlock()
+
+	trader.notify(events)
+	return orders, nil
+}
+
+// PerpetualPositions returns the open simulated positions.
+//
+// Parameters:
+//   - ctx: Context of the request, unused by the simulation.
+//
+// Returns:
+//   - A slice of types.PerpetualPosition ordered by product ID.
+//   - A nil error, paper listing cannot fail.
+func (trader *PaperTrader) PerpetualPositions(ctx context.Context) ([]types.PerpetualPosition, error) {
+	trader.mutex.Lock()
+	defer trader.mutex.Unlock()
+
+	positions := []types.PerpetualPosition{}
+	for productId, position := range trader.positions {
+		if position.quantity.Sign() != 0 {
+			positions = append(positions, trader.perpetualPosition(productId, position))
+		}
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].ProductId < positions[j].ProductId
+	})
+	return positions, nil
+}
+
+// SpotBalances returns the simulated collateral balance.
+//
+// Parameters:
+//   - ctx: Context of the request, unused by the simulation.
+//
+// Returns:
+//   - A slice holding the collateral types.SpotBalance.
+//   - A nil error, paper listing cannot fail.
+func (trader *PaperTrader) SpotBalances(ctx context.Context) ([]types.SpotBalance, error) {
+	trader.mutex.Lock()
+	defer trader.mutex.Unlock()
+
+	return []types.SpotBalance{trader.spotBalance()}, nil
+}
+
+// newOrder validates and simulates a new order. The caller must hold the mutex.
+func (trader *PaperTrader) newOrder(params *types.NewOrderRequest, events *paperEvents) (*types.Order, error) {
+	// Validate order.
+	if params.Product == nil {
+		return nil, fmt.Errorf("order requires a product")
+	}
+	if _, ok := trader.products[params.Product.Id]; !ok {
+		return nil, fmt.Errorf("unknown product %d", params.Product.Id)
+	}
+	switch params.OrderType {
+	case constants.ORDER_TYPE_LIMIT, constants.ORDER_TYPE_LIMIT_MAKER, constants.ORDER_TYPE_MARKET:
+	default:
+		return nil, fmt.Errorf("unsupported order type %d", params.OrderType)
+	}
+	quantity, ok := new(big.Int).SetString(params.Quantity, 10)
+	if !ok || quantity.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid quantity %q", params.Quantity)
+	}
+	var price *big.Int
+	if params.OrderType != constants.ORDER_TYPE_MARKET {
+		price, ok = new(big.Int).SetString(params.Price, 10)
+		if !ok || price.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid price %q", params.Price)
+		}
+	}
+	now := trader.now().UnixMilli()
+	if params.Expiration != 0 && params.Expiration <= now {
+		return nil, fmt.Errorf("order expired at %d", params.Expiration)
+	}
+
+	book := trader.books[params.Product.Id]
+	if book == nil {
+		book = &paperBook{}
+	}
+	opposite := book.side(!params.IsBuy)
+	if params.OrderType == constants.ORDER_TYPE_LIMIT_MAKER && len(opposite) > 0 && crosses(params.IsBuy, price, opposite[0].price) {
+		return nil, fmt.Errorf("limit maker order would cross the book")
+	}
+
+	trader.sequence++
+	order := &paperOrder{
+		Order: types.Order{
+			Id:           fmt.Sprintf("%s%d", PAPER_ORDER_ID_PREFIX, trader.sequence),
+			Account:      trader.account,
+			SubAccountId: trader.subAccountId,
+			ProductId:    params.Product.Id,
+			IsBuy:        params.IsBuy,
+			OrderType:    params.OrderType,
+			TimeInForce:  params.TimeInForce,
+			Price:        params.Price,
+			Quantity:     quantity.String(),
+			Filled:       "0",
+			Status:       constants.ORDER_STATUS_OPEN,
+			Expiration:   params.Expiration,
+			Nonce:        params.Nonce,
+			CreatedAt:    now,
+		},
+		sequence:  trader.sequence,
+		price:     price,
+		remaining: quantity,
+		filled:    new(big.Int),
+	}
+
+	// Fill or kill orders expire untouched unless the book covers them.
+	if params.TimeInForce == constants.TIME_IN_FORCE_FOK {
+		available := new(big.Int)
+		for _, level := range opposite {
+			if !crosses(order.IsBuy, order.price, level.price) {
+				break
+			}
+			available.Add(available, level.quantity)
+		}
+		if available.Cmp(quantity) < 0 {
+			order.Status = constants.ORDER_STATUS_EXPIRED
+			trader.orderUpdate(order, events)
+			return &order.Order, nil
+		}
+	}
+
+	// Take liquidity at slipped level prices.
+	for _, level := range opposite {
+		if order.remaining.Sign() == 0 || !crosses(order.IsBuy, order.price, level.price) {
+			break
+		}
+		fillQuantity := minQuantity(order.remaining, level.quantity)
+		level.quantity.Sub(level.quantity, fillQuantity)
+		trader.fill(order, fillQuantity, trader.slip(level.price, order.IsBuy), false, events)
+	}
+	book.prune()
+
+	// Cancel or rest the remainder.
+	switch {
+	case order.remaining.Sign() == 0:
+	case order.OrderType == constants.ORDER_TYPE_MARKET || order.TimeInForce != constants.TIME_IN_FORCE_GTC:
+		order.Status = constants.ORDER_STATUS_CANCELLED
+		trader.orderUpdate(order, events)
+	default:
+		if trader.fillModel == FILL_MODEL_QUEUE {
+			order.queueAhead = new(big.Int)
+			if level := findLevel(book.side(order.IsBuy), order.price); level != nil {
+				order.queueAhead.Set(level.quantity)
+			}
+		}
+		trader.orders[order.Id] = order
+		if order.filled.Sign() == 0 {
+			trader.orderUpdate(order, events)
+		}
+	}
+	return &order.Order, nil
+}
+
+// cancelOrder cancels a resting order. The caller must hold the mutex.
+func (trader *PaperTrader) cancelOrder(productId int64, orderId string, events *paperEvents) (*types.Order, error) {
+	order, ok := trader.orders[orderId]
+	if !ok || order.ProductId != productId {
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, orderId)
+	}
+	delete(trader.orders, orderId)
+	order.Status = constants.ORDER_STATUS_CANCELLED
+	trader.orderUpdate(order, events)
+	return &order.Order, nil
+}
+
+// expireOrders expires resting orders past their expiration. The caller must hold the mutex.
+func (trader *PaperTrader) expireOrders(events *paperEvents) {
+	now := trader.now().UnixMilli()
+	for _, order := range trader.restingOrders(0) {
+		if order.Expiration != 0 && order.Expiration <= now {
+			delete(trader.orders, order.Id)
+			order.Status = constants.ORDER_STATUS_EXPIRED
+			trader.orderUpdate(order, events)
+		}
+	}
+}
+
+// restingOrders returns the resting orders of a product, or of all products when productId is zero,
+// ordered by arrival. The caller must hold the mutex.
+func (trader *PaperTrader) restingOrders(productId int64) []*paperOrder {
+	orders := make([]*paperOrder, 0, len(trader.orders))
+	for _, order := range trader.orders {
+		if productId == 0 || order.ProductId == productId {
+			orders = append(orders, order)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].sequence < orders[j].sequence
+	})
+	return orders
+}
+
+// fill applies a fill to an order, its position and the collateral balance. The caller must hold the mutex.
+func (trader *PaperTrader) fill(order *paperOrder, quantity *big.Int, price *big.Int, isMaker bool, events *paperEvents) {
+	order.remaining.Sub(order.remaining, quantity)
+	order.filled.Add(order.filled, quantity)
+	order.Filled = order.filled.String()
+	if order.remaining.Sign() == 0 {
+		order.Status = constants.ORDER_STATUS_FILLED
+		delete(trader.orders, order.Id)
+	} else {
+		order.Status = constants.ORDER_STATUS_PARTIALLY_FILLED
+	}
+	trader.orderUpdate(order, events)
+
+	delta := new(big.Int).Set(quantity)
+	if !order.IsBuy {
+		delta.Neg(delta)
+	}
+	trader.applyPosition(order.ProductId, delta, price, events)
+
+	// Charge the fee on the fill notional.
+	feeBps := trader.takerFeeBps
+	if isMaker {
+		feeBps = trader.makerFeeBps
+	}
+	fee := new(big.Int).Mul(price, quantity)
+	fee.Quo(fee, constants.E18)
+	fee.Mul(fee, big.NewInt(feeBps))
+	fee.Quo(fee, big.NewInt(BPS_DENOMINATOR))
+	trader.credit(new(big.Int).Neg(fee), events)
+
+	events.fills = append(events.fills, &Fill{
+		OrderId:   order.Id,
+		ProductId: order.ProductId,
+		IsBuy:     order.IsBuy,
+		IsMaker:   isMaker,
+		Price:     price.String(),
+		Quantity:  quantity.String(),
+		Fee:       fee.String(),
+		Time:      trader.now().UnixMilli(),
+	})
+}
+
+// applyPosition adds a signed fill to a position and settles realized PnL. The caller must hold the mutex.
+func (trader *PaperTrader) applyPosition(productId int64, delta *big.Int, price *big.Int, events *paperEvents) {
+	current, ok := trader.positions[productId]
+	if !ok {
+		current = &paperPosition{quantity: new(big.Int), avgEntryPrice: new(big.Int)}
+		trader.positions[productId] = current
+	}
+
+	updated := new(big.Int).Add(current.quantity, delta)
+	switch {
+	case current.quantity.Sign() == 0 || current.quantity.Sign() == delta.Sign():
+		// Increase: weight the entry price by size.
+		notional := new(big.Int).Mul(current.avgEntryPrice, new(big.Int).Abs(current.quantity))
+		notional.Add(notional, new(big.Int).Mul(price, new(big.Int).Abs(delta)))
+		current.avgEntryPrice = notional.Quo(notional, new(big.Int).Abs(updated))
+	default:
+		// Reduce: realize PnL on the closed size, flip entry price when crossing zero.
+		closed := new(big.Int).Abs(delta)
+		if closed.Cmp(new(big.Int).Abs(current.quantity)) > 0 {
+			closed.Abs(current.quantity)
+		}
+		realized := new(big.Int).Sub(price, current.avgEntryPrice)
+		realized.Mul(realized, closed)
+		realized.Quo(realized, constants.E18)
+		if current.quantity.Sign() < 0 {
+			realized.Neg(realized)
+		}
+		trader.credit(realized, events)
+		switch {
+		case updated.Sign() == 0:
+			current.avgEntryPrice = new(big.Int)
+		case updated.Sign() != current.quantity.Sign():
+			current.avgEntryPrice = new(big.Int).Set(price)
+		}
+	}
+	current.quantity = updated
+
+	position := trader.perpetualPosition(productId, current)
+	events.updates = append(events.updates, &types.AccountUpdate{Type: constants.ACCOUNT_UPDATE_POSITION, Position: &position})
+}
+
+// credit adds a signed quantity to the collateral balance. The caller must hold the mutex.
+func (trader *PaperTrader) credit(quantity *big.Int, events *paperEvents) {
+	if quantity.Sign() == 0 {
+		return
+	}
+	trader.balance.Add(trader.balance, quantity)
+	balance := trader.spotBalance()
+	events.updates = append(events.updates, &types.AccountUpdate{Type: constants.ACCOUNT_UPDATE_BALANCE, Balance: &balance})
+}
+
+// orderUpdate records an order update. The caller must hold the mutex.
+func (trader *PaperTrader) orderUpdate(order *paperOrder, events *paperEvents) {
+	snapshot := order.Order
+	events.updates = append(events.updates, &types.AccountUpdate{Type: constants.ACCOUNT_UPDATE_ORDER, Order: &snapshot})
+}
+
+// notify invokes the callbacks outside of the mutex.
+func (trader *PaperTrader) notify(events *paperEvents) {
+	if trader.onFill != nil {
+		for _, fill := range events.fills {
+			trader.onFill(fill)
+		}
+	}
+	if trader.onUpdate != nil {
+		for _, update := range events.updates {
+			trader.onUpdate(update)
+		}
+	}
+}
+
+// slip moves a taker fill price against the order by the configured slippage.
+func (trader *PaperTrader) slip(price *big.Int, isBuy bool) *big.Int {
+	bps := BPS_DENOMINATOR + trader.slippageBps
+	if !isBuy {
+		bps = BPS_DENOMINATOR - trader.slippageBps
+	}
+	slipped := new(big.Int).Mul(price, big.NewInt(bps))
+	return slipped.Quo(slipped, big.NewInt(BPS_DENOMINATOR))
+}
+
+func (trader *PaperTrader) perpetualPosition(productId int64, position *paperPosition) types.PerpetualPosition {
+	return types.PerpetualPosition{
+		Account:        trader.account,
+		SubAccountId:   trader.subAccountId,
+		ProductId:      productId,
+		Quantity:       position.quantity.String(),
+		AvgEntryPrice:  position.avgEntryPrice.String(),
+		InitCumFunding: "0",
+		Margin:         "0",
+	}
+}
+
+func (trader *PaperTrader) spotBalance() types.SpotBalance {
+	return types.SpotBalance{
+		Account:           trader.account,
+		SubAccountId:      trader.subAccountId,
+		Asset:             trader.asset,
+		Quantity:          trader.balance.String(),
+		PendingWithdrawal: "0",
+	}
+}
+
+// side returns the bids or the asks of the book.
+func (book *paperBook) side(isBuy bool) []*paperLevel {
+	if isBuy {
+		return book.bids
+	}
+	return book.asks
+}
+
+// prune removes emptied levels.
+func (book *paperBook) prune() {
+	book.bids = pruneLevels(book.bids)
+	book.asks = pruneLevels(book.asks)
+}
+
+func pruneLevels(levels []*paperLevel) []*paperLevel {
+	kept := levels[:0]
+	for _, level := range levels {
+		if level.quantity.Sign() > 0 {
+			kept = append(kept, level)
+		}
+	}
+	return kept
+}
+
+// findLevel returns the level at price, or nil.
+func findLevel(levels []*paperLevel, price *big.Int) *paperLevel {
+	for _, level := range levels {
+		if level.price.Cmp(price) == 0 {
+			return level
+		}
+	}
+	return nil
+}
+
+// parseLevels parses `[price, quantity]` depth levels.
+func parseLevels(levels [][2]string) ([]*paperLevel, error) {
+	parsed := make([]*paperLevel, 0, len(levels))
+	for _, level := range levels {
+		price, ok := new(big.Int).SetString(level[0], 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid level price %q", level[0])
+		}
+		quantity, ok := new(big.Int).SetString(level[1], 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid level quantity %q", level[1])
+		}
+		parsed = append(parsed, &paperLevel{price: price, quantity: quantity})
+	}
+	return parsed, nil
+}
+
+// crosses reports whether an order at limit trades against price. Market orders have a nil limit and always cross.
+func crosses(isBuy bool, limit *big.Int, price *big.Int) bool {
+	if limit == nil {
+		return true
+	}
+	if isBuy {
+		return limit.Cmp(price) >= 0
+	}
+	return limit.Cmp(price) <= 0
+}
+
+// minQuantity returns a copy of the smaller of two quantities.
+func minQuantity(a *big.Int, b *big.Int) *big.Int {
+	if a.Cmp(b) < 0 {
+		return new(big.Int).Set(a)
+	}
+	return new(big.Int).Set(b)
+}
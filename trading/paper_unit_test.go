@@ -0,0 +1,352 @@
+//go:build !integration
+// +build !integration
+
+package trading
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils/mocks"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type PaperTraderUnitTestSuite struct {
+	suite.Suite
+	now     time.Time
+	updates []*types.AccountUpdate
+}
+
+func (s *PaperTraderUnitTestSuite) SetupTest() {
+	s.now = time.UnixMilli(1700000000000)
+	s.updates = nil
+}
+
+func TestRunSuiteUnit_PaperTraderUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(PaperTraderUnitTestSuite))
+}
+
+func (s *PaperTraderUnitTestSuite) newTrader(config PaperTraderConfiguration) *PaperTrader {
+	config.Account = "0xabc"
+	config.InitialBalance = new(big.Int).Set(constants.E22)
+	config.Now = func() time.Time { return s.now }
+	config.OnUpdate = func(update *types.AccountUpdate) { s.updates = append(s.updates, update) }
+	trader, err := NewPaperTrader(&config)
+	require.NoError(s.T(), err)
+	return trader
+}
+
+func price(units int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(units), constants.E18)
+}
+
+func (s *PaperTraderUnitTestSuite) order(isBuy bool, orderType types.OrderType, timeInForce types.TimeInForce, orderPrice *big.Int, quantity *big.Int) *types.NewOrderRequest {
+	params := &types.NewOrderRequest{
+		Product:     &constants.PRODUCT_ETH_PERP,
+		IsBuy:       isBuy,
+		OrderType:   orderType,
+		TimeInForce: timeInForce,
+		Quantity:    quantity.String(),
+		Expiration:  s.now.Add(time.Hour).UnixMilli(),
+	}
+	if orderPrice != nil {
+		params.Price = orderPrice.String()
+	}
+	return params
+}
+
+func (s *PaperTraderUnitTestSuite) depth(trader *PaperTrader, bids [][2]string, asks [][2]string) {
+	require.NoError(s.T(), trader.HandleDepth(&types.OrderBookDepth{Symbol: constants.PRODUCT_ETH_PERP.Symbol, Bids: bids, Asks: asks}))
+}
+
+func (s *PaperTraderUnitTestSuite) trade(trader *PaperTrader, tradePrice *big.Int, quantity *big.Int) {
+	require.NoError(s.T(), trader.HandleTrade(&types.Trade{Symbol: constants.PRODUCT_ETH_PERP.Symbol, Price: tradePrice.String(), Quantity: quantity.String()}))
+}
+
+func (s *PaperTraderUnitTestSuite) balance(trader *PaperTrader) string {
+	balances, err := trader.SpotBalances(context.Background())
+	require.NoError(s.T(), err)
+	require.Len(s.T(), balances, 1)
+	return balances[0].Quantity
+}
+
+func (s *PaperTraderUnitTestSuite) TestUnit_NewPaperTrader_UnknownFillModel() {
+	trader, err := NewPaperTrader(&PaperTraderConfiguration{FillModel: "optimistic"})
+	require.Error(s.T(), err)
+	require.Nil(s.T(), trader)
+}
+
+func (s *PaperTraderUnitTestSuite) TestUnit_NewOrder_Invalid() {
+	trader := s.newTrader(PaperTraderConfiguration{})
+
+	_, err := trader.NewOrder(context.Background(), &types.NewOrderRequest{Product: &types.Product{Id: 1}, Quantity: "1"})
+	require.ErrorContains(s.T(), err, "unknown product")
+
+	_, err = trader.NewOrder(context.Background(), s.order(true, constants.ORDER_TYPE_STOP_LOSS, constants.TIME_IN_FORCE_GTC, price(3000), constants.E18))
+	require.ErrorContains(s.T(), err, "unsupported order type")
+
+	_, err = trader.NewOrder(context.Background(), s.order(true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC, price(3000), big.NewInt(0)))
+	require.ErrorContains(s.T(), err, "invalid quantity")
+
+	_, err = trader.NewOrder(context.Background(), s.order(true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC, nil, constants.E18))
+	require.ErrorContains(s.T(), err, "invalid price")
+
+	params := s.order(true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC, price(3000), constants.E18)
+	params.Expiration = s.now.UnixMilli()
+	_, err = trader.NewOrder(context.Background(), params)
+	require.ErrorContains(s.T(), err, "expired")
+}
+
+func (s *PaperTraderUnitTestSuite) TestUnit_NewOrder_TakerSlippageAndFees() {
+	trader := s.newTrader(PaperTraderConfiguration{SlippageBps: 10, TakerFeeBps: 5})
+	s.depth(trader, nil, [][2]string{{price(3000).String(), new(big.Int).Mul(constants.E18, big.NewInt(2)).String()}})
+
+	order, err := trader.NewOrder(context.Background(), s.order(true, constants.ORDER_TYPE_MARKET, constants.TIME_IN_FORCE_IOC, nil, constants.E18))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), constants.ORDER_STATUS_FILLED, order.Status)
+
+	// Filled at 3003 with a 1.5015 fee.
+	positions, err := trader.PerpetualPositions(context.Background())
+	require.NoError(s.T(), err)
+	require.Len(s.T(), positions, 1)
+	require.Equal(s.T(), constants.E18.String(), positions[0].Quantity)
+	require.Equal(s.T(), price(3003).String(), positions[0].AvgEntryPrice)
+	fee := new(big.Int).Add(constants.E18, new(big.Int).Mul(big.NewInt(5015), constants.E14))
+	require.Equal(s.T(), new(big.Int).Sub(constants.E22, fee).String(), s.balance(trader))
+
+	// Taken liquidity is gone until the next snapshot.
+	order, err = trader.NewOrder(context.Background(), s.order(true, constants.ORDER_TYPE_MARKET, constants.TIME_IN_FORCE_IOC, nil, new(big.Int).Mul(constants.E18, big.NewInt(2))))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), constants.ORDER_STATUS_CANCELLED, order.Status)
+	require.Equal(s.T(), constants.E18.String(), order.Filled)
+}
+
+func (s *PaperTraderUnitTestSuite) TestUnit_NewOrder_TimeInForce() {
+	trader := s.newTrader(PaperTraderConfiguration{})
+	s.depth(trader, [][2]string{{price(2990).String(), constants.E18.String()}}, [][2]string{{price(3000).String(), constants.E18.String()}})
+
+	// Limit maker orders may not cross.
+	_, err := trader.NewOrder(context.Background(), s.order(true, constants.ORDER_TYPE_LIMIT_MAKER, constants.TIME_IN_FORCE_GTC, price(3000), constants.E18))
+	require.ErrorContains(s.T(), err, "would cross")
+
+	// Fill or kill orders larger than the book expire untouched.
+	order, err := trader.NewOrder(context.Background(), s.order(true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_FOK, price(3000), constants.E19))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), constants.ORDER_STATUS_EXPIRED, order.Status)
+	require.Equal(s.T(), "0", order.Filled)
+
+	// Good till cancel orders rest their remainder.
+	order, err = trader.NewOrder(context.Background(), s.order(true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC, price(3000), new(big.Int).Mul(constants.E18, big.NewInt(2))))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), constants.ORDER_STATUS_PARTIALLY_FILLED, order.Status)
+
+	orders, err := trader.ListOpenOrders(context.Background(), &constants.PRODUCT_ETH_PERP)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), orders, 1)
+	require.Equal(s.T(), order.Id, orders[0].Id)
+}
+
+func (s *PaperTraderUnitTestSuite) TestUnit_TouchFillModel() {
+	trader := s.newTrader(PaperTraderConfiguration{MakerFeeBps: -2})
+	order, err := trader.NewOrder(context.Background(), s.order(true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC, price(2990), constants.E18))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), constants.ORDER_STATUS_OPEN, order.Status)
+
+	// Trades above the price leave the order untouched.
+	s.trade(trader, price(2995), constants.E18)
+	orders, err := trader.ListOpenOrders(context.Background(), nil)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), orders, 1)
+
+	// A small trade at the price fills it completely, earning the maker rebate.
+	s.trade(trader, price(2990), constants.E15)
+	orders, err = trader.ListOpenOrders(context.Background(), nil)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), orders)
+	rebate := new(big.Int).Mul(big.NewInt(598), constants.E15)
+	require.Equal(s.T(), new(big.Int).Add(constants.E22, rebate).String(), s.balance(trader))
+
+	last := s.updates[len(s.updates)-1]
+	require.Equal(s.T(), constants.ACCOUNT_UPDATE_BALANCE, last.Type)
+	var filled *types.Order
+	for _, update := range s.updates {
+		if update.Type == constants.ACCOUNT_UPDATE_ORDER {
+			filled = update.Order
+		}
+	}
+	require.Equal(s.T(), constants.ORDER_STATUS_FILLED, filled.Status)
+}
+
+func (s *PaperTraderUnitTestSuite) TestUnit_QueueFillModel() {
+	trader := s.newTrader(PaperTraderConfiguration{FillModel: FILL_MODEL_QUEUE})
+	s.depth(trader, [][2]string{{price(2990).String(), constants.E18.String()}}, nil)
+	half := new(big.Int).Quo(constants.E18, big.NewInt(2))
+	order, err := trader.NewOrder(context.Background(), s.order(true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC, price(2990), half))
+	require.NoError(s.T(), err)
+
+	// The queue ahead trades first.
+	s.trade(trader, price(2990), new(big.Int).Mul(constants.E17, big.NewInt(8)))
+	orders, err := trader.ListOpenOrders(context.Background(), nil)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "0", orders[0].Filled)
+
+	// Cancellations ahead shrink the queue.
+	s.depth(trader, [][2]string{{price(2990).String(), constants.E17.String()}}, nil)
+	s.trade(trader, price(2990), new(big.Int).Mul(constants.E17, big.NewInt(3)))
+	orders, err = trader.ListOpenOrders(context.Background(), nil)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), new(big.Int).Mul(constants.E17, big.NewInt(2)).String(), orders[0].Filled)
+	require.Equal(s.T(), constants.ORDER_STATUS_PARTIALLY_FILLED, orders[0].Status)
+
+	// Trades through the price fill up to their quantity.
+	s.trade(trader, price(2980), constants.E18)
+	orders, err = trader.ListOpenOrders(context.Background(), nil)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), orders)
+
+	positions, err := trader.PerpetualPositions(context.Background())
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), half.String(), positions[0].Quantity)
+	require.Equal(s.T(), price(2990).String(), positions[0].AvgEntryPrice)
+	require.NotEmpty(s.T(), order.Id)
+}
+
+func (s *PaperTraderUnitTestSuite) TestUnit_DepthCrossingFillsRestingOrders() {
+	trader := s.newTrader(PaperTraderConfiguration{FillModel: FILL_MODEL_QUEUE})
+	_, err := trader.NewOrder(context.Background(), s.order(false, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC, price(3010), constants.E18))
+	require.NoError(s.T(), err)
+
+	s.depth(trader, [][2]string{{price(3015).String(), constants.E17.String()}}, nil)
+	positions, err := trader.PerpetualPositions(context.Background())
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), new(big.Int).Neg(constants.E17).String(), positions[0].Quantity)
+	require.Equal(s.T(), price(3010).String(), positions[0].AvgEntryPrice)
+}
+
+func (s *PaperTraderUnitTestSuite) TestUnit_RealizedPnL() {
+	trader := s.newTrader(PaperTraderConfiguration{})
+	s.depth(trader, nil, [][2]string{{price(3000).String(), constants.E18.String()}})
+	_, err := trader.NewOrder(context.Background(), s.order(true, constants.ORDER_TYPE_MARKET, constants.TIME_IN_FORCE_IOC, nil, constants.E18))
+	require.NoError(s.T(), err)
+
+	s.depth(trader, [][2]string{{price(3100).String(), constants.E18.String()}}, nil)
+	_, err = trader.NewOrder(context.Background(), s.order(false, constants.ORDER_TYPE_MARKET, constants.TIME_IN_FORCE_IOC, nil, constants.E18))
+	require.NoError(s.T(), err)
+
+	require.Equal(s.T(), new(big.Int).Add(constants.E22, price(100)).String(), s.balance(trader))
+	positions, err := trader.PerpetualPositions(context.Background())
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), positions)
+}
+
+func (s *PaperTraderUnitTestSuite) TestUnit_Cancel() {
+	trader := s.newTrader(PaperTraderConfiguration{})
+	first, err := trader.NewOrder(context.Background(), s.order(true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC, price(2900), constants.E18))
+	require.NoError(s.T(), err)
+	_, err = trader.NewOrder(context.Background(), s.order(true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC, price(2800), constants.E18))
+	require.NoError(s.T(), err)
+
+	// Wrong product.
+	_, err = trader.CancelOrder(context.Background(), &types.CancelOrderRequest{Product: &constants.PRODUCT_BTC_PERP, IdToCancel: first.Id})
+	require.ErrorIs(s.T(), err, ErrOrderNotFound)
+
+	replacement, err := trader.CancelOrderAndReplace(context.Background(), &types.CancelOrderAndReplaceRequest{
+		IdToCancel: first.Id,
+		NewOrder:   s.order(true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC, price(2950), constants.E18),
+	})
+	require.NoError(s.T(), err)
+	require.NotEqual(s.T(), first.Id, replacement.Id)
+
+	_, err = trader.CancelOrder(context.Background(), &types.CancelOrderRequest{Product: &constants.PRODUCT_ETH_PERP, IdToCancel: first.Id})
+	require.ErrorIs(s.T(), err, ErrOrderNotFound)
+
+	cancelled, err := trader.CancelOrder(context.Background(), &types.CancelOrderRequest{Product: &constants.PRODUCT_ETH_PERP, IdToCancel: replacement.Id})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), constants.ORDER_STATUS_CANCELLED, cancelled.Status)
+
+	orders, err := trader.CancelAllOpenOrders(context.Background(), &constants.PRODUCT_ETH_PERP)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), orders, 1)
+	require.Equal(s.T(), price(2800).String(), orders[0].Price)
+
+	orders, err = trader.ListOpenOrders(context.Background(), nil)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), orders)
+}
+
+func (s *PaperTraderUnitTestSuite) TestUnit_Expiration() {
+	trader := s.newTrader(PaperTraderConfiguration{})
+	_, err := trader.NewOrder(context.Background(), s.order(true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC, price(2900), constants.E18))
+	require.NoError(s.T(), err)
+
+	s.now = s.now.Add(2 * time.Hour)
+	orders, err := trader.ListOpenOrders(context.Background(), nil)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), orders)
+	require.Equal(s.T(), constants.ORDER_STATUS_EXPIRED, s.updates[len(s.updates)-1].Order.Status)
+}
+
+func (s *PaperTraderUnitTestSuite) TestUnit_Run() {
+	trader := s.newTrader(PaperTraderConfiguration{})
+	_, err := trader.NewOrder(context.Background(), s.order(true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC, price(2990), constants.E18))
+	require.NoError(s.T(), err)
+
+	connection := new(mocks.MockWebSocketConnection)
+	connection.On("ReadMessage").Return(1, []byte(`{"id":"1","result":{"success":true}}`), nil).Once()
+	connection.On("ReadMessage").Return(1, []byte(`{"stream":"ethperp@depth5_0","data":{"bids":[],"asks":[["3000000000000000000000","1000000000000000000"]]}}`), nil).Once()
+	connection.On("ReadMessage").Return(1, []byte(`{"stream":"ethperp@trade","data":{"price":"2990000000000000000000","quantity":"1000000000000000"}}`), nil).Once()
+	connection.On("ReadMessage").Return(0, []byte(nil), errors.New("closed")).Once()
+
+	require.EqualError(s.T(), trader.Run(connection), "closed")
+	orders, err := trader.ListOpenOrders(context.Background(), nil)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), orders)
+
+	// The streamed book is used by taker orders.
+	order, err := trader.NewOrder(context.Background(), s.order(true, constants.ORDER_TYPE_MARKET, constants.TIME_IN_FORCE_IOC, nil, constants.E18))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), constants.ORDER_STATUS_FILLED, order.Status)
+}
+
+func (s *PaperTraderUnitTestSuite) TestUnit_HandleStreamMessage_Invalid() {
+	trader := s.newTrader(PaperTraderConfiguration{})
+	require.Error(s.T(), trader.HandleStreamMessage([]byte(`{"stream":"ethperp@trade","data":{"price":"x","quantity":"1"}}`)))
+	require.Error(s.T(), trader.HandleStreamMessage([]byte(`{"stream":"dogeperp@depth5_0","data":{}}`)))
+	require.NoError(s.T(), trader.HandleStreamMessage([]byte(`{"stream":"ethperp@ticker","data":{}}`)))
+}
+
+func (s *PaperTraderUnitTestSuite) TestUnit_HandleKline() {
+	var fills []*Fill
+	trader := s.newTrader(PaperTraderConfiguration{FillModel: FILL_MODEL_QUEUE, OnFill: func(fill *Fill) { fills = append(fills, fill) }})
+	_, err := trader.NewOrder(context.Background(), s.order(true, constants.ORDER_TYPE_LIMIT, constants.TIME_IN_FORCE_GTC, price(2990), constants.E18))
+	require.NoError(s.T(), err)
+
+	kline := types.Kline{Symbol: constants.PRODUCT_ETH_PERP.Symbol, High: price(3010).String(), Low: price(2990).String(), Close: price(3000).String(), Volume: constants.E19.String()}
+
+	// The queue model needs the range to trade through the price.
+	require.NoError(s.T(), trader.HandleKline(&kline))
+	require.Empty(s.T(), fills)
+
+	kline.Low = price(2980).String()
+	require.NoError(s.T(), trader.HandleKline(&kline))
+	require.Len(s.T(), fills, 1)
+	require.True(s.T(), fills[0].IsMaker)
+	require.Equal(s.T(), price(2990).String(), fills[0].Price)
+	require.Equal(s.T(), s.now.UnixMilli(), fills[0].Time)
+
+	// Without depth snapshots, taker orders fill at the close.
+	order, err := trader.NewOrder(context.Background(), s.order(false, constants.ORDER_TYPE_MARKET, constants.TIME_IN_FORCE_IOC, nil, constants.E18))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), constants.ORDER_STATUS_FILLED, order.Status)
+	require.Equal(s.T(), price(3000).String(), fills[1].Price)
+	require.False(s.T(), fills[1].IsMaker)
+
+	kline.Close = "x"
+	require.Error(s.T(), trader.HandleKline(&kline))
+}
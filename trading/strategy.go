@@ -0,0 +1,116 @@
+package trading
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+// MarketEvent is a decoded market data stream message. Exactly one of Trade, Depth and Kline is set.
+type MarketEvent struct {
+	Trade *types.Trade          `json:"trade,omitempty"` // Public trade from a `@trade` stream.
+	Depth *types.OrderBookDepth `json:"depth,omitempty"` // Depth snapshot from a `@depth*` stream.
+	Kline *types.Kline          `json:"kline,omitempty"` // Kline from a `@klines_*` stream.
+}
+
+// IStrategy reacts to market data by trading through an ITrader.
+// The same strategy runs unchanged against live, paper and backtest traders.
+type IStrategy interface {
+	OnMarketEvent(ctx context.Context, trader ITrader, event *MarketEvent) error
+}
+
+// StrategyFunc adapts a function to the IStrategy interface.
+type StrategyFunc func(ctx context.Context, trader ITrader, event *MarketEvent) error
+
+// OnMarketEvent calls the function.
+func (strategy StrategyFunc) OnMarketEvent(ctx context.Context, trader ITrader, event *MarketEvent) error {
+	return strategy(ctx, trader, event)
+}
+
+// DecodeMarketEvent decodes a raw market data stream message.
+//
+// Parameters:
+//   - body: The JSON message, `{"stream": "<symbol>@<topic>", "data": ...}`.
+//
+// Returns:
+//   - A pointer to the MarketEvent, nil for messages other than `@trade`, `@depth*` and `@klines_*`.
+//   - An error if the message data cannot be decoded.
+func DecodeMarketEvent(body []byte) (*MarketEvent, error) {
+	var message struct {
+		Stream string          `json:"stream"`
+		Data   json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &message); err != nil || message.Stream == "" {
+		return nil, nil
+	}
+	symbol, topic, _ := strings.Cut(message.Stream, "@")
+
+	switch {
+	case topic == "trade":
+		var trade types.Trade
+		if err := json.Unmarshal(message.Data, &trade); err != nil {
+			return nil, fmt.Errorf("failed to decode trade: %v", err)
+		}
+		if trade.Symbol == "" {
+			trade.Symbol = symbol
+		}
+		return &MarketEvent{Trade: &trade}, nil
+	case strings.HasPrefix(topic, "klines_"):
+		var kline types.Kline
+		if err := json.Unmarshal(message.Data, &kline); err != nil {
+			return nil, fmt.Errorf("failed to decode kline: %v", err)
+		}
+		if kline.Symbol == "" {
+			kline.Symbol = symbol
+		}
+		return &MarketEvent{Kline: &kline}, nil
+	case strings.HasPrefix(topic, "depth"):
+		var depth types.OrderBookDepth
+		if err := json.Unmarshal(message.Data, &depth); err != nil {
+			return nil, fmt.Errorf("failed to decode depth: %v", err)
+		}
+		if depth.Symbol == "" {
+			depth.Symbol = symbol
+		}
+		return &MarketEvent{Depth: &depth}, nil
+	}
+	return nil, nil
+}
+
+// RunStrategy feeds market data read from a stream connection to a strategy until reading fails, the strategy
+// returns an error or the context is done. Paper traders are fed each event before the strategy sees it.
+//
+// Parameters:
+//   - ctx: Context handed to the strategy for its requests. Once done, reading stops after the current message.
+//   - connection: Stream connection implementing `types.IWSReader` interface, e.g. `RyskV2WSClient.StreamConnection`.
+//   - trader: The trader the strategy acts through, as returned by `NewTrader`.
+//   - strategy: The strategy.
+//
+// Returns:
+//   - The error that stopped reading, the strategy error or the context error.
+func RunStrategy(ctx context.Context, connection types.IWSReader, trader ITrader, strategy IStrategy) error {
+	paperTrader, isPaper := trader.(*PaperTrader)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		_, body, err := connection.ReadMessage()
+		if err != nil {
+			return err
+		}
+		event, err := DecodeMarketEvent(body)
+		if err != nil || event == nil {
+			continue
+		}
+
+		if isPaper {
+			paperTrader.HandleMarketEvent(event)
+		}
+		if err := strategy.OnMarketEvent(ctx, trader, event); err != nil {
+			return err
+		}
+	}
+}
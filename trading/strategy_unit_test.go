@@ -0,0 +1,84 @@
+//go:build !integration
+// +build !integration
+
+package trading
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils/mocks"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type StrategyUnitTestSuite struct {
+	suite.Suite
+}
+
+func TestRunSuiteUnit_StrategyUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(StrategyUnitTestSuite))
+}
+
+func (s *StrategyUnitTestSuite) TestUnit_DecodeMarketEvent() {
+	event, err := DecodeMarketEvent([]byte(`{"stream":"ethperp@trade","data":{"price":"1","quantity":"2"}}`))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), constants.PRODUCT_ETH_PERP.Symbol, event.Trade.Symbol)
+
+	event, err = DecodeMarketEvent([]byte(`{"stream":"btcperp@klines_1m","data":{"close":"3"}}`))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), constants.PRODUCT_BTC_PERP.Symbol, event.Kline.Symbol)
+	require.Equal(s.T(), "3", event.Kline.Close)
+
+	event, err = DecodeMarketEvent([]byte(`{"stream":"solperp@depth10_2","data":{"bids":[["1","2"]]}}`))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), constants.PRODUCT_SOL_PERP.Symbol, event.Depth.Symbol)
+	require.Len(s.T(), event.Depth.Bids, 1)
+
+	// Acknowledgements and other topics are not market events.
+	event, err = DecodeMarketEvent([]byte(`{"id":"1","result":null}`))
+	require.NoError(s.T(), err)
+	require.Nil(s.T(), event)
+	event, err = DecodeMarketEvent([]byte(`{"stream":"ethperp@ticker","data":{}}`))
+	require.NoError(s.T(), err)
+	require.Nil(s.T(), event)
+
+	_, err = DecodeMarketEvent([]byte(`{"stream":"ethperp@trade","data":[]}`))
+	require.Error(s.T(), err)
+}
+
+func (s *StrategyUnitTestSuite) TestUnit_RunStrategy() {
+	trader, err := NewPaperTrader(&PaperTraderConfiguration{})
+	require.NoError(s.T(), err)
+
+	connection := new(mocks.MockWebSocketConnection)
+	connection.On("ReadMessage").Return(1, []byte(`{"stream":"ethperp@depth5_0","data":{"asks":[["3000000000000000000000","1000000000000000000"]]}}`), nil).Once()
+	connection.On("ReadMessage").Return(1, []byte(`{"stream":"ethperp@trade","data":[]}`), nil).Once()
+	connection.On("ReadMessage").Return(1, []byte(`{"stream":"ethperp@trade","data":{"price":"3000000000000000000000","quantity":"1"}}`), nil).Once()
+
+	// The paper book is up to date when the strategy sees the depth.
+	failure := errors.New("done")
+	var events []*MarketEvent
+	strategy := StrategyFunc(func(ctx context.Context, trader ITrader, event *MarketEvent) error {
+		events = append(events, event)
+		if event.Depth != nil {
+			order, err := trader.NewOrder(ctx, &types.NewOrderRequest{
+				Product:     &constants.PRODUCT_ETH_PERP,
+				IsBuy:       true,
+				OrderType:   constants.ORDER_TYPE_MARKET,
+				TimeInForce: constants.TIME_IN_FORCE_IOC,
+				Quantity:    constants.E18.String(),
+			})
+			require.NoError(s.T(), err)
+			require.Equal(s.T(), constants.ORDER_STATUS_FILLED, order.Status)
+			return nil
+		}
+		return failure
+	})
+
+	require.ErrorIs(s.T(), RunStrategy(context.Background(), connection, trader, strategy), failure)
+	require.Len(s.T(), events, 2)
+}
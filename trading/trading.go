@@ -0,0 +1,75 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rysk-finance/v2_client_go/api_client"
+	"github.com/rysk-finance/v2_client_go/exchange"
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+type TradingMode string
+
+const (
+	TRADING_MODE_LIVE  TradingMode = "live"
+	TRADING_MODE_PAPER TradingMode = "paper"
+)
+
+// ITrader is the trading surface shared by live, paper and backtest trading.
+// It is the trading subset of `exchange.IExchange`, so every exchange trades live, e.g. a `FailoverExchange`.
+type ITrader interface {
+	NewOrder(ctx context.Context, params *types.NewOrderRequest) (*types.Order, error)
+	CancelOrderAndReplace(ctx context.Context, params *types.CancelOrderAndReplaceRequest) (*types.Order, error)
+	CancelOrder(ctx context.Context, params *types.CancelOrderRequest) (*types.Order, error)
+	CancelAllOpenOrders(ctx context.Context, product *types.Product) ([]types.Order, error)
+	ListOpenOrders(ctx context.Context, product *types.Product) ([]types.Order, error)
+	PerpetualPositions(ctx context.Context) ([]types.PerpetualPosition, error)
+	SpotBalances(ctx context.Context) ([]types.SpotBalance, error)
+}
+
+// TraderConfiguration holds the configuration selecting between live and paper trading.
+type TraderConfiguration struct {
+	Mode      TradingMode                 // Trading mode. Can be `TRADING_MODE_LIVE` or `TRADING_MODE_PAPER`. Defaults to `TRADING_MODE_LIVE`.
+	Exchange  exchange.IExchange          // Exchange trading in live mode, e.g. a `FailoverExchange`. Defaults to a `RESTExchange` over APIClient.
+	APIClient *api_client.RyskV2APIClient // REST client trading in live mode when no exchange is set, and providing the account in paper mode.
+	Paper     *PaperTraderConfiguration   // Optional paper trading settings, used in paper mode.
+}
+
+// NewTrader creates a live or paper trader depending on the configured mode.
+//
+// Parameters:
+//   - config: A pointer to TraderConfiguration containing the configuration settings.
+//
+// Returns:
+//   - An ITrader sending orders to the exchange or simulating them locally.
+//   - An error if the mode is unknown or if the selected trader cannot be created.
+func NewTrader(config *TraderConfiguration) (ITrader, error) {
+	switch config.Mode {
+	case TRADING_MODE_LIVE, "":
+		if config.Exchange != nil {
+			return config.Exchange, nil
+		}
+		if config.APIClient == nil {
+			return nil, fmt.Errorf("live trading requires an exchange or an API client")
+		}
+		return exchange.NewRESTExchange(config.APIClient)
+	case TRADING_MODE_PAPER:
+		paperConfig := PaperTraderConfiguration{}
+		if config.Paper != nil {
+			paperConfig = *config.Paper
+		}
+		if config.APIClient != nil {
+			if paperConfig.Account == "" {
+				paperConfig.Account = config.APIClient.Address().Hex()
+				paperConfig.SubAccountId = config.APIClient.SubAccountId
+			}
+			if paperConfig.Asset == "" {
+				paperConfig.Asset = config.APIClient.USDCAddress().Hex()
+			}
+		}
+		return NewPaperTrader(&paperConfig)
+	default:
+		return nil, fmt.Errorf("unknown trading mode %q", config.Mode)
+	}
+}
@@ -0,0 +1,136 @@
+//go:build !integration
+// +build !integration
+
+package trading
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rysk-finance/v2_client_go/api_client"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/exchange"
+	"github.com/rysk-finance/v2_client_go/ryskfake"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TradingUnitTestSuite struct {
+	suite.Suite
+	Server    *ryskfake.Server
+	APIClient *api_client.RyskV2APIClient
+}
+
+func (s *TradingUnitTestSuite) SetupTest() {
+	server, err := ryskfake.NewServer(&ryskfake.ServerConfiguration{})
+	require.NoError(s.T(), err)
+	s.Server = server
+
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(s.T(), err)
+	s.APIClient, err = api_client.NewRyskV2APIClient(&api_client.RyskV2APIClientConfiguration{
+		Env:          constants.ENVIRONMENT_TESTNET,
+		PrivateKey:   hex.EncodeToString(crypto.FromECDSA(privateKey)),
+		RpcUrl:       server.URL(),
+		BaseUrl:      server.URL(),
+		SubAccountId: 2,
+	})
+	require.NoError(s.T(), err)
+}
+
+func (s *TradingUnitTestSuite) TearDownTest() {
+	s.Server.Close()
+}
+
+func TestRunSuiteUnit_TradingUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(TradingUnitTestSuite))
+}
+
+func (s *TradingUnitTestSuite) TestUnit_NewTrader() {
+	trader, err := NewTrader(&TraderConfiguration{APIClient: s.APIClient})
+	require.NoError(s.T(), err)
+	require.IsType(s.T(), &exchange.RESTExchange{}, trader)
+
+	// A configured exchange, e.g. a FailoverExchange, trades as is.
+	restExchange, err := exchange.NewRESTExchange(s.APIClient)
+	require.NoError(s.T(), err)
+	trader, err = NewTrader(&TraderConfiguration{Exchange: restExchange})
+	require.NoError(s.T(), err)
+	require.Same(s.T(), restExchange, trader)
+
+	trader, err = NewTrader(&TraderConfiguration{Mode: TRADING_MODE_PAPER, APIClient: s.APIClient})
+	require.NoError(s.T(), err)
+	require.IsType(s.T(), &PaperTrader{}, trader)
+
+	// Paper balances are reported for the client account.
+	balances, err := trader.SpotBalances(context.Background())
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), s.APIClient.Address().Hex(), balances[0].Account)
+	require.Equal(s.T(), int64(2), balances[0].SubAccountId)
+	require.Equal(s.T(), s.APIClient.USDCAddress().Hex(), balances[0].Asset)
+
+	_, err = NewTrader(&TraderConfiguration{})
+	require.Error(s.T(), err)
+
+	_, err = NewTrader(&TraderConfiguration{Mode: "simulated"})
+	require.Error(s.T(), err)
+
+	_, err = NewTrader(&TraderConfiguration{Mode: TRADING_MODE_PAPER, Paper: &PaperTraderConfiguration{FillModel: "optimistic"}})
+	require.Error(s.T(), err)
+}
+
+func (s *TradingUnitTestSuite) TestUnit_LiveTrader() {
+	trader, err := NewTrader(&TraderConfiguration{Mode: TRADING_MODE_LIVE, APIClient: s.APIClient})
+	require.NoError(s.T(), err)
+
+	newOrder := func(orderPrice int64, nonce int64) *types.NewOrderRequest {
+		return &types.NewOrderRequest{
+			Product:     &constants.PRODUCT_ETH_PERP,
+			IsBuy:       true,
+			OrderType:   constants.ORDER_TYPE_LIMIT,
+			TimeInForce: constants.TIME_IN_FORCE_GTC,
+			Price:       new(big.Int).Mul(big.NewInt(orderPrice), constants.E18).String(),
+			Quantity:    constants.E17.String(),
+			Expiration:  time.Now().Add(time.Hour).UnixMilli(),
+			Nonce:       nonce,
+		}
+	}
+
+	order, err := trader.NewOrder(context.Background(), newOrder(2900, 1))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), constants.ORDER_STATUS_OPEN, order.Status)
+
+	replacement, err := trader.CancelOrderAndReplace(context.Background(), &types.CancelOrderAndReplaceRequest{IdToCancel: order.Id, NewOrder: newOrder(2950, 2)})
+	require.NoError(s.T(), err)
+	require.NotEqual(s.T(), order.Id, replacement.Id)
+
+	_, err = trader.CancelOrder(context.Background(), &types.CancelOrderRequest{Product: &constants.PRODUCT_ETH_PERP, IdToCancel: order.Id})
+	require.Error(s.T(), err)
+
+	orders, err := trader.ListOpenOrders(context.Background(), &constants.PRODUCT_ETH_PERP)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), orders, 1)
+
+	cancelled, err := trader.CancelOrder(context.Background(), &types.CancelOrderRequest{Product: &constants.PRODUCT_ETH_PERP, IdToCancel: replacement.Id})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), constants.ORDER_STATUS_CANCELLED, cancelled.Status)
+
+	orders, err = trader.CancelAllOpenOrders(context.Background(), &constants.PRODUCT_ETH_PERP)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), orders)
+
+	positions, err := trader.PerpetualPositions(context.Background())
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), positions)
+
+	s.Server.Credit(s.APIClient.Address(), 2, s.APIClient.USDCAddress(), constants.E20)
+	balances, err := trader.SpotBalances(context.Background())
+	require.NoError(s.T(), err)
+	require.Len(s.T(), balances, 1)
+	require.Equal(s.T(), constants.E20.String(), balances[0].Quantity)
+}
@@ -0,0 +1,286 @@
+package tx_manager
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	geth_types "github.com/ethereum/go-ethereum/core/types"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/logging"
+	"github.com/rysk-finance/v2_client_go/metrics"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+)
+
+// IDepositor sends the approval and deposit transactions of `Account.Deposit` and waits for them, e.g. a REST or
+// websocket client, so that they are traced as the client's own.
+type IDepositor interface {
+	ApproveUSDC(ctx context.Context, amount *big.Int) (*geth_types.Transaction, error)
+	DepositUSDC(ctx context.Context, amount *big.Int) (*geth_types.Transaction, error)
+	WaitTransaction(ctx context.Context, transaction *geth_types.Transaction) (*geth_types.Receipt, error)
+}
+
+// Account sends the on-chain transactions of a sub-account and waits for them. It holds the on-chain settings of a
+// client, which builds it from its current settings for each transaction.
+type Account struct {
+	EthClient          types.IEthClient        // Ethereum client for interacting with the blockchain.
+	PrivateKey         *ecdsa.PrivateKey       // Private key signing the transactions.
+	Address            common.Address          // Address of the private key.
+	SubAccountId       int64                   // Sub-account credited by deposits.
+	CIAO               common.Address          // Address of the CIAO contract.
+	USDC               common.Address          // Address of the USDC contract.
+	GasConfiguration   *types.GasConfiguration // EIP-1559 gas settings, nil for suggested fees.
+	TransactionManager *TransactionManager     // Optional transaction manager, transactions track nonces locally when set.
+	ApprovalMode       types.ApprovalMode      // Approval mode used by `Deposit`, `constants.APPROVAL_MODE_EXACT` (default) or `constants.APPROVAL_MODE_MAX`.
+	Metrics            *metrics.Metrics        // Optional metrics of confirmation times, nil records nothing.
+	Logger             *slog.Logger            // Optional logger of transactions, nil logs nothing.
+}
+
+// ApproveUSDC approves the CIAO contract to spend USDC of the account.
+//
+// Parameters:
+//   - ctx: The context.Context for the Ethereum transaction.
+//   - amount: The amount of USDC tokens to approve, specified as a *big.Int.
+//
+// Returns:
+//   - A pointer to a geth_types.Transaction representing the Ethereum transaction.
+//   - An error if the Ethereum transaction fails or encounters an issue.
+func (account *Account) ApproveUSDC(ctx context.Context, amount *big.Int) (*geth_types.Transaction, error) {
+	data, err := pack(constants.ERC20_ABI, "approve", account.CIAO, amount)
+	if err != nil {
+		return nil, err
+	}
+	return account.SendTransaction(ctx, account.USDC, data)
+}
+
+// DepositUSDC sends USDC of the account to the sub-account through the CIAO contract.
+//
+// Parameters:
+//   - ctx: The context.Context for the Ethereum transaction.
+//   - amount: The amount of USDC tokens to deposit, specified as a *big.Int.
+//
+// Returns:
+//   - A pointer to a geth_types.Transaction representing the Ethereum transaction.
+//   - An error if the Ethereum transaction fails or encounters an issue.
+func (account *Account) DepositUSDC(ctx context.Context, amount *big.Int) (*geth_types.Transaction, error) {
+	data, err := pack(constants.CIAO_ABI, "deposit", account.Address, uint8(account.SubAccountId), amount, account.USDC)
+	if err != nil {
+		return nil, err
+	}
+	return account.SendTransaction(ctx, account.CIAO, data)
+}
+
+// Deposit deposits USDC to the sub-account, approving the CIAO contract first only when needed.
+//
+// The workflow checks the USDC balance and the CIAO minimum deposit amount, approves
+// the missing allowance according to `ApprovalMode`, deposits and waits for each receipt.
+//
+// Parameters:
+//   - ctx: The context.Context for the Ethereum transactions.
+//   - depositor: The client sending the transactions and waiting for them.
+//   - amount: The amount of USDC tokens to deposit, specified as a *big.Int.
+//
+// Returns:
+//   - A pointer to a types.DepositResult holding the transactions and receipts.
+//   - An error if a check fails or a transaction fails or reverts, CIAO reverts match `utils.ErrBalanceInsufficient` and friends with `errors.Is`.
+func (account *Account) Deposit(ctx context.Context, depositor IDepositor, amount *big.Int) (*types.DepositResult, error) {
+	// Check USDC balance
+	balance, err := utils.GetBalanceOf(ctx, account.EthClient, account.USDC, account.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get USDC balance: %v", err)
+	}
+	if balance.Cmp(amount) < 0 {
+		return nil, fmt.Errorf("insufficient USDC balance: %s < %s", balance, amount)
+	}
+
+	// Check minimum deposit amount
+	minDepositAmount, err := utils.GetMinDepositAmount(ctx, account.EthClient, account.CIAO, account.USDC)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get minimum deposit amount: %v", err)
+	}
+	if amount.Cmp(minDepositAmount) < 0 {
+		return nil, fmt.Errorf("deposit amount below minimum: %s < %s", amount, minDepositAmount)
+	}
+
+	// Check allowance and approve when needed
+	result := &types.DepositResult{}
+	allowance, err := utils.GetAllowance(ctx, account.EthClient, account.USDC, account.Address, account.CIAO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get USDC allowance: %v", err)
+	}
+	if allowance.Cmp(amount) < 0 {
+		approveAmount := amount
+		if account.ApprovalMode == constants.APPROVAL_MODE_MAX {
+			approveAmount = abi.MaxUint256
+		}
+		result.ApproveTransaction, result.ApproveReceipt, err = account.sendAndWait(ctx, depositor, func() (*geth_types.Transaction, error) {
+			return depositor.ApproveUSDC(ctx, approveAmount)
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to approve USDC: %w", err)
+		}
+	}
+
+	// Deposit
+	result.DepositTransaction, result.DepositReceipt, err = account.sendAndWait(ctx, depositor, func() (*geth_types.Transaction, error) {
+		return depositor.DepositUSDC(ctx, amount)
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to deposit USDC: %w", err)
+	}
+
+	return result, nil
+}
+
+// SendTransaction builds, signs and sends an EIP-1559 transaction to the given contract, through the transaction
+// manager when set, logging its hash or failure.
+//
+// Parameters:
+//   - ctx: The context.Context for the Ethereum transaction.
+//   - to: The address of the contract to call.
+//   - data: The packed call data.
+//
+// Returns:
+//   - A pointer to a geth_types.Transaction representing the signed Ethereum transaction.
+//   - An error if building, signing or sending the transaction fails.
+func (account *Account) SendTransaction(ctx context.Context, to common.Address, data []byte) (*geth_types.Transaction, error) {
+	transaction, err := account.submitTransaction(ctx, to, data)
+	if err != nil {
+		account.log().WarnContext(ctx, "transaction failed", "to", to.Hex(), "error", err)
+		return nil, err
+	}
+	account.log().InfoContext(ctx, "transaction sent", "hash", transaction.Hash().Hex(), "to", to.Hex(), "nonce", transaction.Nonce())
+	return transaction, nil
+}
+
+// WaitTransaction waits for a transaction to be mined and returns its receipt.
+// With a transaction manager, it waits for the configured confirmations and follows replacements.
+//
+// Parameters:
+//   - ctx: The context.Context for the Ethereum transaction.
+//   - transaction: The Ethereum transaction (*geth_types.Transaction) to monitor.
+//
+// Returns:
+//   - A pointer to a geth_types.Receipt containing the transaction receipt once the transaction is mined.
+//   - An error if the transaction fails to be mined or encounters an issue.
+func (account *Account) WaitTransaction(ctx context.Context, transaction *geth_types.Transaction) (*geth_types.Receipt, error) {
+	start := time.Now()
+
+	// Wait for the configured confirmations when a transaction manager is set.
+	if account.TransactionManager != nil {
+		receipt, err := account.TransactionManager.WaitConfirmations(ctx, transaction)
+		account.observeTransaction(ctx, transaction, receipt, err, time.Since(start))
+		return receipt, err
+	}
+
+	receipt, err := bind.WaitMined(ctx, account.EthClient, transaction)
+	account.observeTransaction(ctx, transaction, receipt, err, time.Since(start))
+	if err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// sendAndWait sends a transaction, waits for its receipt through the depositor and checks it succeeded.
+//
+// Parameters:
+//   - ctx: The context.Context for the Ethereum transaction.
+//   - depositor: The client waiting for the transaction.
+//   - send: The function sending the transaction.
+//
+// Returns:
+//   - The sent transaction, nil if sending failed.
+//   - The transaction receipt, nil if waiting failed.
+//   - An error if sending or waiting fails, or a decoded revert error if the transaction reverted.
+func (account *Account) sendAndWait(ctx context.Context, depositor IDepositor, send func() (*geth_types.Transaction, error)) (*geth_types.Transaction, *geth_types.Receipt, error) {
+	transaction, err := send()
+	if err != nil {
+		return nil, nil, err
+	}
+	receipt, err := depositor.WaitTransaction(ctx, transaction)
+	if err != nil {
+		return transaction, nil, err
+	}
+	if receipt.Status != geth_types.ReceiptStatusSuccessful {
+		return transaction, receipt, utils.GetRevertError(ctx, account.EthClient, account.Address, transaction, receipt)
+	}
+	return transaction, receipt, nil
+}
+
+// submitTransaction signs and sends a transaction calling a contract, through the transaction manager when set.
+func (account *Account) submitTransaction(ctx context.Context, to common.Address, data []byte) (*geth_types.Transaction, error) {
+	// Delegate to the transaction manager when set.
+	if account.TransactionManager != nil {
+		return account.TransactionManager.Send(ctx, to, data)
+	}
+
+	// Get transaction parameters
+	nonce, gasTipCap, gasFeeCap, chainID, gasLimit, err := utils.GetDynamicFeeTransactionParams(ctx, account.EthClient, account.GasConfiguration, &account.Address, &to, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create and sign a new dynamic fee transaction
+	signedTx, err := utils.SignDynamicFeeTransaction(account.PrivateKey, chainID, &geth_types.DynamicFeeTx{
+		Nonce:     nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       gasLimit,
+		To:        &to,
+		Value:     big.NewInt(0),
+		Data:      data,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Send transaction
+	err = account.EthClient.SendTransaction(ctx, signedTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send transaction: %w", utils.DecodeRevertError(err))
+	}
+
+	return signedTx, nil
+}
+
+// observeTransaction records and logs the outcome of waiting for a transaction.
+func (account *Account) observeTransaction(ctx context.Context, transaction *geth_types.Transaction, receipt *geth_types.Receipt, err error, duration time.Duration) {
+	account.Metrics.ObserveTransaction(receipt, duration)
+	switch {
+	case err != nil || receipt == nil:
+		account.log().WarnContext(ctx, "transaction wait failed", "hash", transaction.Hash().Hex(), "duration", duration, "error", err)
+	case receipt.Status != geth_types.ReceiptStatusSuccessful:
+		account.log().WarnContext(ctx, "transaction reverted", "hash", transaction.Hash().Hex(), "block", receipt.BlockNumber, "duration", duration)
+	default:
+		account.log().InfoContext(ctx, "transaction confirmed", "hash", transaction.Hash().Hex(), "block", receipt.BlockNumber, "gas_used", receipt.GasUsed, "duration", duration)
+	}
+}
+
+// log returns the logger of the account, discarding records when not set.
+func (account *Account) log() *slog.Logger {
+	return logging.OrDiscard(account.Logger)
+}
+
+// pack packs the call data of a contract method.
+func pack(contractABI string, method string, args ...interface{}) ([]byte, error) {
+	// Parse ABI
+	parsedABI, err := abi.JSON(strings.NewReader(contractABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %v", err)
+	}
+
+	// Pack transaction data
+	data, err := parsedABI.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack transaction data: %v", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,96 @@
+package tx_manager
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// ITransactionStore persists the pending transactions of a TransactionManager.
+type ITransactionStore interface {
+	Load() ([]*PendingTransaction, error)
+	Save(pendingTransactions []*PendingTransaction) error
+}
+
+// FileStore is an ITransactionStore writing pending transactions to a JSON file.
+type FileStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFileStore creates a new FileStore writing to the given path.
+//
+// Parameters:
+//   - path: Path of the JSON file, created on first save.
+//
+// Returns:
+//   - A pointer to FileStore.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load reads pending transactions from the file. A missing file holds no transactions.
+//
+// Returns:
+//   - A slice of PendingTransaction pointers.
+//   - An error if the file cannot be read or decoded.
+func (store *FileStore) Load() ([]*PendingTransaction, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	content, err := os.ReadFile(store.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var pendingTransactions []*PendingTransaction
+	if err := json.Unmarshal(content, &pendingTransactions); err != nil {
+		return nil, err
+	}
+	return pendingTransactions, nil
+}
+
+// Save atomically replaces the file content with the given pending transactions.
+//
+// Parameters:
+//   - pendingTransactions: The pending transactions to persist.
+//
+// Returns:
+//   - An error if the file cannot be written.
+func (store *FileStore) Save(pendingTransactions []*PendingTransaction) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	content, err := json.MarshalIndent(pendingTransactions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated store.
+	temporaryFile, err := os.CreateTemp(filepath.Dir(store.path), filepath.Base(store.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temporaryFile.Name())
+	if _, err := temporaryFile.Write(content); err != nil {
+		temporaryFile.Close()
+		return err
+	}
+	if err := temporaryFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temporaryFile.Name(), store.path)
+}
+
+// sortPendingTransactions orders pending transactions by nonce.
+func sortPendingTransactions(pendingTransactions []*PendingTransaction) {
+	sort.Slice(pendingTransactions, func(i, j int) bool {
+		return pendingTransactions[i].Nonce < pendingTransactions[j].Nonce
+	})
+}
@@ -0,0 +1,526 @@
+package tx_manager
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/logging"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	geth_types "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	DEFAULT_CONFIRMATIONS    uint64        = 1
+	DEFAULT_POLL_INTERVAL    time.Duration = time.Second
+	DEFAULT_FEE_BUMP_PERCENT int64         = 10
+)
+
+var (
+	ErrTransactionNotPending = errors.New("transaction is not pending")
+	ErrTransactionCancelled  = errors.New("transaction was cancelled")
+)
+
+// TransactionManagerConfiguration holds the configuration for the transaction manager.
+type TransactionManagerConfiguration struct {
+	EthClient        types.IEthClient        // Ethereum client for interacting with the blockchain.
+	PrivateKey       string                  // Private key as a string, e.g., `0x2638b4...` or `2638b4...`.
+	GasConfiguration *types.GasConfiguration // EIP-1559 gas settings, defaults to suggested fees.
+	Confirmations    uint64                  // Number of blocks, inclusion block included, before a transaction is confirmed. Defaults to `DEFAULT_CONFIRMATIONS`.
+	PollInterval     time.Duration           // Interval between receipt polls. Defaults to `DEFAULT_POLL_INTERVAL`.
+	FeeBumpPercent   int64                   // Minimum fee increase for replacements, in percent. Defaults to `DEFAULT_FEE_BUMP_PERCENT`.
+	Store            ITransactionStore       // Optional store persisting pending transactions.
+	Logger           *slog.Logger            // Optional logger of store failures, nil logs nothing.
+}
+
+// PendingTransaction is a transaction sent to the network and not yet confirmed.
+type PendingTransaction struct {
+	Nonce       uint64                  `json:"nonce"`       // Nonce shared by the transaction and its replacements.
+	Transaction *geth_types.Transaction `json:"transaction"` // Latest signed transaction sent for the nonce.
+	Hashes      []common.Hash           `json:"hashes"`      // Hashes of every transaction sent for the nonce, replacements included.
+	Cancelled   []common.Hash           `json:"cancelled"`   // Hashes of the cancellations sent for the nonce, and of their speed-ups.
+	SentAt      time.Time               `json:"sentAt"`      // Time the latest transaction was sent.
+}
+
+// TransactionManager sends on-chain transactions for a single account.
+// Nonces are tracked locally so concurrent sends never race on the same nonce,
+// and pending transactions can be sped up, cancelled and awaited.
+type TransactionManager struct {
+	ethClient        types.IEthClient
+	privateKey       *ecdsa.PrivateKey
+	address          common.Address
+	gasConfiguration *types.GasConfiguration
+	confirmations    uint64
+	pollInterval     time.Duration
+	feeBumpPercent   int64
+	store            ITransactionStore
+	logger           *slog.Logger
+
+	mutex       sync.Mutex
+	chainID     *big.Int
+	nonce       uint64
+	nonceSynced bool
+	pending     map[uint64]*PendingTransaction
+	confirmed   map[common.Hash]*confirmedTransaction
+}
+
+// confirmedTransaction is the outcome of a confirmed nonce, kept for every hash sent for it
+// so each waiter finds the included transaction once it stopped being pending.
+type confirmedTransaction struct {
+	hash      common.Hash
+	receipt   *geth_types.Receipt
+	cancelled bool
+}
+
+// NewTransactionManager creates a new TransactionManager instance.
+// Pending transactions are restored from the store when one is configured.
+//
+// Parameters:
+//   - config: A pointer to TransactionManagerConfiguration containing the configuration settings.
+//
+// Returns:
+//   - A pointer to TransactionManager.
+//   - An error if initialization fails.
+func NewTransactionManager(config *TransactionManagerConfiguration) (*TransactionManager, error) {
+	if config.EthClient == nil {
+		return nil, fmt.Errorf("missing Ethereum client")
+	}
+
+	// Get ecdsa.PrivateKey.
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(config.PrivateKey, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %v", err)
+	}
+
+	manager := &TransactionManager{
+		ethClient:        config.EthClient,
+		privateKey:       privateKey,
+		address:          crypto.PubkeyToAddress(privateKey.PublicKey),
+		gasConfiguration: config.GasConfiguration,
+		confirmations:    config.Confirmations,
+		pollInterval:     config.PollInterval,
+		feeBumpPercent:   config.FeeBumpPercent,
+		store:            config.Store,
+		logger:           logging.OrDiscard(config.Logger),
+		pending:          map[uint64]*PendingTransaction{},
+		confirmed:        map[common.Hash]*confirmedTransaction{},
+	}
+	if manager.confirmations == 0 {
+		manager.confirmations = DEFAULT_CONFIRMATIONS
+	}
+	if manager.pollInterval == 0 {
+		manager.pollInterval = DEFAULT_POLL_INTERVAL
+	}
+	if manager.feeBumpPercent == 0 {
+		manager.feeBumpPercent = DEFAULT_FEE_BUMP_PERCENT
+	}
+
+	// Restore pending transactions.
+	if manager.store != nil {
+		pendingTransactions, err := manager.store.Load()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load pending transactions: %v", err)
+		}
+		for _, pendingTransaction := range pendingTransactions {
+			manager.pending[pendingTransaction.Nonce] = pendingTransaction
+		}
+	}
+
+	return manager, nil
+}
+
+// Address returns the address sending the transactions.
+func (manager *TransactionManager) Address() common.Address {
+	return manager.address
+}
+
+// Send builds, signs and sends an EIP-1559 transaction using the next local nonce.
+// Calls are queued, so concurrent sends are assigned consecutive nonces.
+// Once sent, the transaction is returned even if the store fails to save it, the failure is logged.
+//
+// Parameters:
+//   - ctx: The context.Context for the Ethereum transaction.
+//   - to: The recipient of the transaction.
+//   - data: The call data of the transaction.
+//
+// Returns:
+//   - A pointer to the signed geth_types.Transaction.
+//   - An error if building, signing or sending the transaction fails.
+func (manager *TransactionManager) Send(ctx context.Context, to common.Address, data []byte) (*geth_types.Transaction, error) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	// Get next nonce.
+	nonce, err := manager.nextNonce(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get gas fees and gas limit.
+	gasTipCap, gasFeeCap, err := utils.GetGasFees(ctx, manager.ethClient, manager.gasConfiguration)
+	if err != nil {
+		return nil, err
+	}
+	gasLimit, err := utils.EstimateGasLimit(ctx, manager.ethClient, manager.gasConfiguration, ethereum.CallMsg{
+		From: manager.address,
+		To:   &to,
+		Data: data,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Sign and send transaction.
+	signedTx, err := manager.signAndSend(ctx, &geth_types.DynamicFeeTx{
+		Nonce:     nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       gasLimit,
+		To:        &to,
+		Value:     big.NewInt(0),
+		Data:      data,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	manager.nonce = nonce + 1
+	manager.pending[nonce] = &PendingTransaction{
+		Nonce:       nonce,
+		Transaction: signedTx,
+		Hashes:      []common.Hash{signedTx.Hash()},
+		SentAt:      time.Now(),
+	}
+	manager.persistSent(ctx)
+	return signedTx, nil
+}
+
+// SpeedUp replaces a pending transaction with the same transaction paying higher fees.
+//
+// Parameters:
+//   - ctx: The context.Context for the Ethereum transaction.
+//   - hash: The hash of the pending transaction, or of any of its replacements.
+//
+// Returns:
+//   - A pointer to the replacement geth_types.Transaction.
+//   - An error if the transaction is not pending or the replacement fails.
+func (manager *TransactionManager) SpeedUp(ctx context.Context, hash common.Hash) (*geth_types.Transaction, error) {
+	return manager.replace(ctx, hash, false)
+}
+
+// Cancel replaces a pending transaction with an empty transfer to self paying higher fees.
+//
+// Parameters:
+//   - ctx: The context.Context for the Ethereum transaction.
+//   - hash: The hash of the pending transaction, or of any of its replacements.
+//
+// Returns:
+//   - A pointer to the cancellation geth_types.Transaction.
+//   - An error if the transaction is not pending or the cancellation fails.
+func (manager *TransactionManager) Cancel(ctx context.Context, hash common.Hash) (*geth_types.Transaction, error) {
+	return manager.replace(ctx, hash, true)
+}
+
+// WaitConfirmations waits until the transaction, or one of its replacements, is included
+// and buried under the configured number of confirmations.
+//
+// Parameters:
+//   - ctx: The context.Context for the Ethereum transaction.
+//   - transaction: The transaction to monitor.
+//
+// Returns:
+//   - A pointer to the geth_types.Receipt of the included transaction.
+//   - An error if the context is done or the client fails, matching `ErrTransactionCancelled` when a cancellation was included instead.
+func (manager *TransactionManager) WaitConfirmations(ctx context.Context, transaction *geth_types.Transaction) (*geth_types.Receipt, error) {
+	ticker := time.NewTicker(manager.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		receipt, err := manager.confirmedReceipt(ctx, transaction)
+		if err != nil {
+			return nil, err
+		}
+		if receipt != nil {
+			return receipt, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Pending returns the transactions sent and not yet confirmed, ordered by nonce.
+//
+// Returns:
+//   - A slice of PendingTransaction pointers.
+func (manager *TransactionManager) Pending() []*PendingTransaction {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	pendingTransactions := make([]*PendingTransaction, 0, len(manager.pending))
+	for _, pendingTransaction := range manager.pending {
+		pendingTransactions = append(pendingTransactions, pendingTransaction)
+	}
+	sortPendingTransactions(pendingTransactions)
+	return pendingTransactions
+}
+
+// ResetNonce forces the next send to resynchronise the nonce with the network.
+func (manager *TransactionManager) ResetNonce() {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	manager.nonceSynced = false
+}
+
+// nextNonce returns the nonce of the next transaction, synchronising with the network when needed.
+func (manager *TransactionManager) nextNonce(ctx context.Context) (uint64, error) {
+	if manager.nonceSynced {
+		return manager.nonce, nil
+	}
+
+	// Get network pending nonce.
+	nonce, err := manager.ethClient.PendingNonceAt(ctx, manager.address)
+	if err != nil {
+		return 0, err
+	}
+
+	// Never reuse a nonce still tracked as pending.
+	for pendingNonce := range manager.pending {
+		if pendingNonce >= nonce {
+			nonce = pendingNonce + 1
+		}
+	}
+
+	manager.nonce = nonce
+	manager.nonceSynced = true
+	return nonce, nil
+}
+
+// signAndSend signs a dynamic fee transaction and sends it to the network.
+func (manager *TransactionManager) signAndSend(ctx context.Context, transaction *geth_types.DynamicFeeTx) (*geth_types.Transaction, error) {
+	// Get Chain ID once.
+	if manager.chainID == nil {
+		chainID, err := manager.ethClient.NetworkID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		manager.chainID = chainID
+	}
+
+	// Sign transaction.
+	signedTx, err := utils.SignDynamicFeeTransaction(manager.privateKey, manager.chainID, transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send transaction, resynchronising the nonce on the next send if it fails.
+	err = manager.ethClient.SendTransaction(ctx, signedTx)
+	if err != nil {
+		manager.nonceSynced = false
+		return nil, fmt.Errorf("failed to send transaction: %w", utils.DecodeRevertError(err))
+	}
+
+	return signedTx, nil
+}
+
+// replace sends a replacement for a pending transaction with bumped fees.
+func (manager *TransactionManager) replace(ctx context.Context, hash common.Hash, cancel bool) (*geth_types.Transaction, error) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	pendingTransaction := manager.findPending(hash)
+	if pendingTransaction == nil {
+		return nil, ErrTransactionNotPending
+	}
+	previous := pendingTransaction.Transaction
+
+	// Bump fees.
+	gasTipCap, gasFeeCap, err := manager.bumpedGasFees(ctx, previous)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build replacement.
+	replacement := &geth_types.DynamicFeeTx{
+		Nonce:     pendingTransaction.Nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       previous.Gas(),
+		To:        previous.To(),
+		Value:     previous.Value(),
+		Data:      previous.Data(),
+	}
+	if cancel {
+		// Estimate the transfer to self, it costs more than 21000 gas on some networks.
+		gasLimit, err := utils.EstimateGasLimit(ctx, manager.ethClient, manager.gasConfiguration, ethereum.CallMsg{
+			From:  manager.address,
+			To:    &manager.address,
+			Value: big.NewInt(0),
+		})
+		if err != nil {
+			return nil, err
+		}
+		replacement.Gas = gasLimit
+		replacement.To = &manager.address
+		replacement.Value = big.NewInt(0)
+		replacement.Data = nil
+	}
+
+	// Sign and send replacement.
+	signedTx, err := manager.signAndSend(ctx, replacement)
+	if err != nil {
+		return nil, err
+	}
+
+	// Speeding up a cancellation sends another cancellation.
+	if cancel || pendingTransaction.isCancellation(previous.Hash()) {
+		pendingTransaction.Cancelled = append(pendingTransaction.Cancelled, signedTx.Hash())
+	}
+	pendingTransaction.Transaction = signedTx
+	pendingTransaction.Hashes = append(pendingTransaction.Hashes, signedTx.Hash())
+	pendingTransaction.SentAt = time.Now()
+	manager.persistSent(ctx)
+	return signedTx, nil
+}
+
+// bumpedGasFees returns the fees of a transaction increased by the fee bump,
+// or the current network fees when they are higher.
+func (manager *TransactionManager) bumpedGasFees(ctx context.Context, transaction *geth_types.Transaction) (*big.Int, *big.Int, error) {
+	bump := big.NewInt(100 + manager.feeBumpPercent)
+	gasTipCap := new(big.Int).Div(new(big.Int).Mul(transaction.GasTipCap(), bump), big.NewInt(100))
+	gasFeeCap := new(big.Int).Div(new(big.Int).Mul(transaction.GasFeeCap(), bump), big.NewInt(100))
+
+	suggestedGasTipCap, suggestedGasFeeCap, err := utils.GetGasFees(ctx, manager.ethClient, manager.gasConfiguration)
+	if err != nil {
+		return nil, nil, err
+	}
+	if suggestedGasTipCap.Cmp(gasTipCap) > 0 {
+		gasTipCap = suggestedGasTipCap
+	}
+	if suggestedGasFeeCap.Cmp(gasFeeCap) > 0 {
+		gasFeeCap = suggestedGasFeeCap
+	}
+	if gasTipCap.Cmp(gasFeeCap) > 0 {
+		gasFeeCap = new(big.Int).Set(gasTipCap)
+	}
+	return gasTipCap, gasFeeCap, nil
+}
+
+// confirmedReceipt returns the receipt of the transaction or one of its replacements once confirmed, nil otherwise.
+func (manager *TransactionManager) confirmedReceipt(ctx context.Context, transaction *geth_types.Transaction) (*geth_types.Receipt, error) {
+	manager.mutex.Lock()
+	if confirmed, ok := manager.confirmed[transaction.Hash()]; ok {
+		manager.mutex.Unlock()
+		return confirmed.result(transaction)
+	}
+	hashes := []common.Hash{transaction.Hash()}
+	cancelled := map[common.Hash]bool{}
+	if pendingTransaction := manager.findPending(transaction.Hash()); pendingTransaction != nil {
+		hashes = append([]common.Hash{}, pendingTransaction.Hashes...)
+		for _, hash := range pendingTransaction.Cancelled {
+			cancelled[hash] = true
+		}
+	}
+	manager.mutex.Unlock()
+
+	for _, hash := range hashes {
+		receipt, err := manager.ethClient.TransactionReceipt(ctx, hash)
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Check confirmation depth.
+		blockNumber, err := manager.ethClient.BlockNumber(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if receipt.BlockNumber != nil && blockNumber+1 < receipt.BlockNumber.Uint64()+manager.confirmations {
+			return nil, nil
+		}
+
+		// Transaction confirmed, stop tracking it and keep its outcome for the other waiters.
+		confirmed := &confirmedTransaction{hash: hash, receipt: receipt, cancelled: cancelled[hash]}
+		manager.mutex.Lock()
+		for _, sentHash := range hashes {
+			manager.confirmed[sentHash] = confirmed
+		}
+		delete(manager.pending, transaction.Nonce())
+		manager.persistSent(ctx)
+		manager.mutex.Unlock()
+
+		return confirmed.result(transaction)
+	}
+	return nil, nil
+}
+
+// result returns the receipt of the confirmed transaction, or `ErrTransactionCancelled` when the nonce
+// was used by a cancellation and the transaction never executed.
+func (confirmed *confirmedTransaction) result(transaction *geth_types.Transaction) (*geth_types.Receipt, error) {
+	if confirmed.cancelled {
+		return nil, fmt.Errorf("%w: nonce %d used by %s", ErrTransactionCancelled, transaction.Nonce(), confirmed.hash.Hex())
+	}
+	return confirmed.receipt, nil
+}
+
+// findPending returns the pending transaction matching a hash of any of its attempts.
+func (manager *TransactionManager) findPending(hash common.Hash) *PendingTransaction {
+	for _, pendingTransaction := range manager.pending {
+		for _, pendingHash := range pendingTransaction.Hashes {
+			if pendingHash == hash {
+				return pendingTransaction
+			}
+		}
+	}
+	return nil
+}
+
+// isCancellation reports whether a hash belongs to a cancellation of the transaction.
+func (pendingTransaction *PendingTransaction) isCancellation(hash common.Hash) bool {
+	for _, cancelledHash := range pendingTransaction.Cancelled {
+		if cancelledHash == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// persistSent saves pending transactions once the network has accepted a change, logging failures
+// since the transaction is sent and must reach the caller whether or not it was stored.
+func (manager *TransactionManager) persistSent(ctx context.Context) {
+	if err := manager.persist(); err != nil {
+		manager.logger.WarnContext(ctx, "failed to persist pending transactions", "error", err)
+	}
+}
+
+// persist saves pending transactions to the store, if any.
+func (manager *TransactionManager) persist() error {
+	if manager.store == nil {
+		return nil
+	}
+	pendingTransactions := make([]*PendingTransaction, 0, len(manager.pending))
+	for _, pendingTransaction := range manager.pending {
+		pendingTransactions = append(pendingTransactions, pendingTransaction)
+	}
+	sortPendingTransactions(pendingTransactions)
+	if err := manager.store.Save(pendingTransactions); err != nil {
+		return fmt.Errorf("failed to save pending transactions: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,404 @@
+//go:build !integration
+// +build !integration
+
+package tx_manager
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	geth_types "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TransactionManagerUnitTestSuite struct {
+	suite.Suite
+	PrivateKey    string
+	MockEthClient *mocks.MockEthClient
+	Manager       *TransactionManager
+}
+
+func (s *TransactionManagerUnitTestSuite) SetupSuite() {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(s.T(), err)
+	s.PrivateKey = hex.EncodeToString(crypto.FromECDSA(privateKey))
+}
+
+func (s *TransactionManagerUnitTestSuite) SetupTest() {
+	s.MockEthClient = new(mocks.MockEthClient)
+	s.MockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(100), nil).Maybe()
+	s.MockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000)}, nil).Maybe()
+	s.MockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(42161), nil).Maybe()
+	s.MockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(50000), nil).Maybe()
+
+	manager, err := NewTransactionManager(&TransactionManagerConfiguration{
+		EthClient:    s.MockEthClient,
+		PrivateKey:   s.PrivateKey,
+		PollInterval: time.Millisecond,
+	})
+	require.NoError(s.T(), err)
+	s.Manager = manager
+}
+
+func TestRunSuiteUnit_TransactionManagerUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(TransactionManagerUnitTestSuite))
+}
+
+func (s *TransactionManagerUnitTestSuite) TestUnit_NewTransactionManager_InvalidPrivateKey() {
+	manager, err := NewTransactionManager(&TransactionManagerConfiguration{
+		EthClient:  s.MockEthClient,
+		PrivateKey: "0x123",
+	})
+	require.Error(s.T(), err)
+	require.Nil(s.T(), manager)
+}
+
+func (s *TransactionManagerUnitTestSuite) TestUnit_NewTransactionManager_MissingEthClient() {
+	manager, err := NewTransactionManager(&TransactionManagerConfiguration{
+		PrivateKey: s.PrivateKey,
+	})
+	require.Error(s.T(), err)
+	require.Nil(s.T(), manager)
+}
+
+func (s *TransactionManagerUnitTestSuite) TestUnit_Send_TracksNonceLocally() {
+	s.MockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(5), nil).Once()
+	s.MockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+
+	first, err := s.Manager.Send(context.Background(), common.MaxAddress, []byte{0x01})
+	require.NoError(s.T(), err)
+	second, err := s.Manager.Send(context.Background(), common.MaxAddress, []byte{0x02})
+	require.NoError(s.T(), err)
+
+	require.Equal(s.T(), uint64(5), first.Nonce())
+	require.Equal(s.T(), uint64(6), second.Nonce())
+	require.Equal(s.T(), uint8(geth_types.DynamicFeeTxType), first.Type())
+	require.Len(s.T(), s.Manager.Pending(), 2)
+	s.MockEthClient.AssertNumberOfCalls(s.T(), "PendingNonceAt", 1)
+}
+
+func (s *TransactionManagerUnitTestSuite) TestUnit_Send_Concurrent() {
+	s.MockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(0), nil).Once()
+	s.MockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+
+	var wg sync.WaitGroup
+	nonces := make(chan uint64, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			transaction, err := s.Manager.Send(context.Background(), common.MaxAddress, nil)
+			require.NoError(s.T(), err)
+			nonces <- transaction.Nonce()
+		}()
+	}
+	wg.Wait()
+	close(nonces)
+
+	seen := map[uint64]bool{}
+	for nonce := range nonces {
+		require.False(s.T(), seen[nonce])
+		seen[nonce] = true
+	}
+	require.Len(s.T(), seen, 10)
+}
+
+func (s *TransactionManagerUnitTestSuite) TestUnit_Send_ErrorResyncsNonce() {
+	s.MockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(3), nil).Twice()
+	s.MockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(fmt.Errorf("nonce too low")).Once()
+	s.MockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+
+	transaction, err := s.Manager.Send(context.Background(), common.MaxAddress, nil)
+	require.Error(s.T(), err)
+	require.Nil(s.T(), transaction)
+
+	transaction, err = s.Manager.Send(context.Background(), common.MaxAddress, nil)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), uint64(3), transaction.Nonce())
+	s.MockEthClient.AssertNumberOfCalls(s.T(), "PendingNonceAt", 2)
+}
+
+func (s *TransactionManagerUnitTestSuite) TestUnit_Send_ErrorEstimateGas() {
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(0), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(100), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000)}, nil)
+	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(0), fmt.Errorf("execution reverted"))
+	s.Manager.ethClient = mockEthClient
+
+	transaction, err := s.Manager.Send(context.Background(), common.MaxAddress, nil)
+	require.Error(s.T(), err)
+	require.Nil(s.T(), transaction)
+	require.Empty(s.T(), s.Manager.Pending())
+}
+
+func (s *TransactionManagerUnitTestSuite) TestUnit_SpeedUp() {
+	s.MockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
+	s.MockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+
+	original, err := s.Manager.Send(context.Background(), common.MaxAddress, []byte{0x01})
+	require.NoError(s.T(), err)
+
+	replacement, err := s.Manager.SpeedUp(context.Background(), original.Hash())
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), original.Nonce(), replacement.Nonce())
+	require.Equal(s.T(), original.Data(), replacement.Data())
+	require.Equal(s.T(), original.To(), replacement.To())
+	require.Equal(s.T(), big.NewInt(110), replacement.GasTipCap())
+	require.Equal(s.T(), big.NewInt(2310), replacement.GasFeeCap())
+
+	pending := s.Manager.Pending()
+	require.Len(s.T(), pending, 1)
+	require.Equal(s.T(), []common.Hash{original.Hash(), replacement.Hash()}, pending[0].Hashes)
+}
+
+func (s *TransactionManagerUnitTestSuite) TestUnit_Cancel() {
+	s.MockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
+	s.MockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+
+	original, err := s.Manager.Send(context.Background(), common.MaxAddress, []byte{0x01})
+	require.NoError(s.T(), err)
+
+	cancellation, err := s.Manager.Cancel(context.Background(), original.Hash())
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), original.Nonce(), cancellation.Nonce())
+	require.Equal(s.T(), s.Manager.Address(), *cancellation.To())
+	require.Empty(s.T(), cancellation.Data())
+	require.Equal(s.T(), uint64(50000), cancellation.Gas())
+	require.Equal(s.T(), 1, cancellation.GasFeeCap().Cmp(original.GasFeeCap()))
+}
+
+func (s *TransactionManagerUnitTestSuite) TestUnit_Cancel_GasLimitBuffer() {
+	manager, err := NewTransactionManager(&TransactionManagerConfiguration{
+		EthClient:        s.MockEthClient,
+		PrivateKey:       s.PrivateKey,
+		GasConfiguration: &types.GasConfiguration{GasLimitBuffer: 20},
+	})
+	require.NoError(s.T(), err)
+	s.MockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
+	s.MockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+
+	original, err := manager.Send(context.Background(), common.MaxAddress, []byte{0x01})
+	require.NoError(s.T(), err)
+
+	cancellation, err := manager.Cancel(context.Background(), original.Hash())
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), uint64(60000), cancellation.Gas())
+	s.MockEthClient.AssertCalled(s.T(), "EstimateGas", mock.Anything, mock.MatchedBy(func(call ethereum.CallMsg) bool {
+		return call.To != nil && *call.To == manager.Address() && len(call.Data) == 0
+	}))
+}
+
+func (s *TransactionManagerUnitTestSuite) TestUnit_SpeedUp_NotPending() {
+	transaction, err := s.Manager.SpeedUp(context.Background(), common.Hash{})
+	require.ErrorIs(s.T(), err, ErrTransactionNotPending)
+	require.Nil(s.T(), transaction)
+}
+
+func (s *TransactionManagerUnitTestSuite) TestUnit_WaitConfirmations() {
+	manager, err := NewTransactionManager(&TransactionManagerConfiguration{
+		EthClient:     s.MockEthClient,
+		PrivateKey:    s.PrivateKey,
+		PollInterval:  time.Millisecond,
+		Confirmations: 3,
+	})
+	require.NoError(s.T(), err)
+	s.MockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
+	s.MockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+	s.MockEthClient.On("TransactionReceipt", mock.Anything, mock.Anything).Return((*geth_types.Receipt)(nil), ethereum.NotFound).Once()
+	s.MockEthClient.On("TransactionReceipt", mock.Anything, mock.Anything).Return(&geth_types.Receipt{BlockNumber: big.NewInt(100)}, nil)
+	s.MockEthClient.On("BlockNumber", mock.Anything).Return(uint64(101), nil).Once()
+	s.MockEthClient.On("BlockNumber", mock.Anything).Return(uint64(102), nil)
+
+	transaction, err := manager.Send(context.Background(), common.MaxAddress, nil)
+	require.NoError(s.T(), err)
+
+	receipt, err := manager.WaitConfirmations(context.Background(), transaction)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), big.NewInt(100), receipt.BlockNumber)
+	require.Empty(s.T(), manager.Pending())
+	s.MockEthClient.AssertNumberOfCalls(s.T(), "BlockNumber", 2)
+}
+
+func (s *TransactionManagerUnitTestSuite) TestUnit_WaitConfirmations_Replacement() {
+	s.MockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
+	s.MockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+
+	original, err := s.Manager.Send(context.Background(), common.MaxAddress, nil)
+	require.NoError(s.T(), err)
+	replacement, err := s.Manager.SpeedUp(context.Background(), original.Hash())
+	require.NoError(s.T(), err)
+
+	s.MockEthClient.On("TransactionReceipt", mock.Anything, original.Hash()).Return((*geth_types.Receipt)(nil), ethereum.NotFound)
+	s.MockEthClient.On("TransactionReceipt", mock.Anything, replacement.Hash()).Return(&geth_types.Receipt{TxHash: replacement.Hash(), BlockNumber: big.NewInt(10)}, nil)
+	s.MockEthClient.On("BlockNumber", mock.Anything).Return(uint64(10), nil)
+
+	receipt, err := s.Manager.WaitConfirmations(context.Background(), original)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), replacement.Hash(), receipt.TxHash)
+}
+
+func (s *TransactionManagerUnitTestSuite) TestUnit_WaitConfirmations_ReplacementSecondWaiter() {
+	s.MockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
+	s.MockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+
+	original, err := s.Manager.Send(context.Background(), common.MaxAddress, nil)
+	require.NoError(s.T(), err)
+	replacement, err := s.Manager.SpeedUp(context.Background(), original.Hash())
+	require.NoError(s.T(), err)
+
+	s.MockEthClient.On("TransactionReceipt", mock.Anything, original.Hash()).Return((*geth_types.Receipt)(nil), ethereum.NotFound)
+	s.MockEthClient.On("TransactionReceipt", mock.Anything, replacement.Hash()).Return(&geth_types.Receipt{TxHash: replacement.Hash(), BlockNumber: big.NewInt(10)}, nil)
+	s.MockEthClient.On("BlockNumber", mock.Anything).Return(uint64(10), nil)
+
+	receipt, err := s.Manager.WaitConfirmations(context.Background(), replacement)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), replacement.Hash(), receipt.TxHash)
+	require.Empty(s.T(), s.Manager.Pending())
+
+	// A waiter on the original transaction still finds the mined replacement.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	receipt, err = s.Manager.WaitConfirmations(ctx, original)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), replacement.Hash(), receipt.TxHash)
+}
+
+func (s *TransactionManagerUnitTestSuite) TestUnit_WaitConfirmations_Cancelled() {
+	s.MockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
+	s.MockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+
+	original, err := s.Manager.Send(context.Background(), common.MaxAddress, []byte{0x01})
+	require.NoError(s.T(), err)
+	cancellation, err := s.Manager.Cancel(context.Background(), original.Hash())
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []common.Hash{cancellation.Hash()}, s.Manager.Pending()[0].Cancelled)
+
+	s.MockEthClient.On("TransactionReceipt", mock.Anything, original.Hash()).Return((*geth_types.Receipt)(nil), ethereum.NotFound)
+	s.MockEthClient.On("TransactionReceipt", mock.Anything, cancellation.Hash()).Return(&geth_types.Receipt{TxHash: cancellation.Hash(), Status: geth_types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(10)}, nil)
+	s.MockEthClient.On("BlockNumber", mock.Anything).Return(uint64(10), nil)
+
+	receipt, err := s.Manager.WaitConfirmations(context.Background(), original)
+	require.ErrorIs(s.T(), err, ErrTransactionCancelled)
+	require.Nil(s.T(), receipt)
+	require.Empty(s.T(), s.Manager.Pending())
+}
+
+func (s *TransactionManagerUnitTestSuite) TestUnit_SpeedUp_Cancellation() {
+	s.MockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
+	s.MockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+
+	original, err := s.Manager.Send(context.Background(), common.MaxAddress, []byte{0x01})
+	require.NoError(s.T(), err)
+	cancellation, err := s.Manager.Cancel(context.Background(), original.Hash())
+	require.NoError(s.T(), err)
+	replacement, err := s.Manager.SpeedUp(context.Background(), cancellation.Hash())
+	require.NoError(s.T(), err)
+
+	require.Equal(s.T(), []common.Hash{cancellation.Hash(), replacement.Hash()}, s.Manager.Pending()[0].Cancelled)
+}
+
+func (s *TransactionManagerUnitTestSuite) TestUnit_WaitConfirmations_ContextCancelled() {
+	s.MockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
+	s.MockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+	s.MockEthClient.On("TransactionReceipt", mock.Anything, mock.Anything).Return((*geth_types.Receipt)(nil), ethereum.NotFound)
+
+	transaction, err := s.Manager.Send(context.Background(), common.MaxAddress, nil)
+	require.NoError(s.T(), err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	receipt, err := s.Manager.WaitConfirmations(ctx, transaction)
+	require.ErrorIs(s.T(), err, context.DeadlineExceeded)
+	require.Nil(s.T(), receipt)
+	require.Len(s.T(), s.Manager.Pending(), 1)
+}
+
+func (s *TransactionManagerUnitTestSuite) TestUnit_WaitConfirmations_ReceiptError() {
+	s.MockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
+	s.MockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+	s.MockEthClient.On("TransactionReceipt", mock.Anything, mock.Anything).Return((*geth_types.Receipt)(nil), fmt.Errorf("connection refused"))
+
+	transaction, err := s.Manager.Send(context.Background(), common.MaxAddress, nil)
+	require.NoError(s.T(), err)
+
+	receipt, err := s.Manager.WaitConfirmations(context.Background(), transaction)
+	require.Error(s.T(), err)
+	require.Nil(s.T(), receipt)
+}
+
+func (s *TransactionManagerUnitTestSuite) TestUnit_FileStore_PersistsPending() {
+	store := NewFileStore(filepath.Join(s.T().TempDir(), "pending.json"))
+	manager, err := NewTransactionManager(&TransactionManagerConfiguration{
+		EthClient:  s.MockEthClient,
+		PrivateKey: s.PrivateKey,
+		Store:      store,
+	})
+	require.NoError(s.T(), err)
+	s.MockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(4), nil)
+	s.MockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+
+	transaction, err := manager.Send(context.Background(), common.MaxAddress, []byte{0x01})
+	require.NoError(s.T(), err)
+
+	// A new manager restores pending transactions and never reuses their nonce.
+	restored, err := NewTransactionManager(&TransactionManagerConfiguration{
+		EthClient:  s.MockEthClient,
+		PrivateKey: s.PrivateKey,
+		Store:      store,
+	})
+	require.NoError(s.T(), err)
+	pending := restored.Pending()
+	require.Len(s.T(), pending, 1)
+	require.Equal(s.T(), transaction.Hash(), pending[0].Transaction.Hash())
+
+	next, err := restored.Send(context.Background(), common.MaxAddress, nil)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), uint64(5), next.Nonce())
+}
+
+// failingStore is a transaction store failing every save.
+type failingStore struct{}
+
+func (failingStore) Load() ([]*PendingTransaction, error) { return nil, nil }
+func (failingStore) Save([]*PendingTransaction) error     { return fmt.Errorf("disk full") }
+
+func (s *TransactionManagerUnitTestSuite) TestUnit_Send_StoreFailureKeepsTransaction() {
+	manager, err := NewTransactionManager(&TransactionManagerConfiguration{
+		EthClient:  s.MockEthClient,
+		PrivateKey: s.PrivateKey,
+		Store:      failingStore{},
+	})
+	require.NoError(s.T(), err)
+	s.MockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(2), nil)
+	s.MockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+
+	transaction, err := manager.Send(context.Background(), common.MaxAddress, []byte{0x01})
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), transaction)
+
+	replacement, err := manager.SpeedUp(context.Background(), transaction.Hash())
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), replacement)
+	require.Len(s.T(), manager.Pending(), 1)
+}
+
+func (s *TransactionManagerUnitTestSuite) TestUnit_FileStore_MissingFile() {
+	store := NewFileStore(filepath.Join(s.T().TempDir(), "missing.json"))
+	pendingTransactions, err := store.Load()
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), pendingTransactions)
+}
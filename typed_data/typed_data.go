@@ -0,0 +1,424 @@
+package typed_data
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+)
+
+// integerFields are the message fields of each primary type sent as JSON numbers in requests, by primary type. Other
+// fields, including prices and quantities, are sent as in the message.
+var integerFields = map[types.PrimaryType][]string{
+	constants.PRIMARY_TYPE_ORDER:          {"subAccountId", "productId", "orderType", "timeInForce", "expiration", "nonce"},
+	constants.PRIMARY_TYPE_CANCEL_ORDER:   {"subAccountId", "productId"},
+	constants.PRIMARY_TYPE_CANCEL_ORDERS:  {"subAccountId", "productId"},
+	constants.PRIMARY_TYPE_APPROVE_SIGNER: {"subAccountId", "nonce"},
+	constants.PRIMARY_TYPE_WITHDRAW:       {"subAccountId", "nonce"},
+	constants.PRIMARY_TYPE_LOGIN_MESSAGE:  {"timestamp"},
+	constants.PRIMARY_TYPE_REFERRAL:       {},
+}
+
+// BuilderConfiguration holds the configuration of a Builder.
+type BuilderConfiguration struct {
+	Env          types.Environment // `constants.ENVIRONMENT_TESTNET` or `constants.ENVIRONMENT_MAINNET`.
+	Account      string            // Address of the account signing the actions externally, e.g. a hardware wallet or a multisig.
+	SubAccountId uint8             // ID of the subaccount the actions act on.
+}
+
+// Builder builds the EIP-712 typed data of the actions of an account without signing them, to be signed externally
+// through `eth_signTypedData_v4` and submitted with the `SubmitTypedData` method of either client.
+type Builder struct {
+	env          types.Environment        // Environment (testnet or mainnet).
+	domain       apitypes.TypedDataDomain // Typed data domain for EIP-712.
+	account      string                   // Address of the account.
+	subAccountId int64                    // Subaccount ID.
+}
+
+// NewBuilder creates a new Builder.
+//
+// Parameters:
+//   - config: A pointer to BuilderConfiguration containing the configuration settings.
+//
+// Returns:
+//   - A pointer to Builder.
+//   - An error if the environment or account is invalid.
+func NewBuilder(config *BuilderConfiguration) (*Builder, error) {
+	if _, ok := constants.CHAIN_ID[config.Env]; !ok {
+		return nil, fmt.Errorf("invalid environment %q", config.Env)
+	}
+	if !common.IsHexAddress(config.Account) {
+		return nil, fmt.Errorf("invalid account %q", config.Account)
+	}
+	return &Builder{
+		env:          config.Env,
+		domain:       Domain(config.Env),
+		account:      config.Account,
+		subAccountId: int64(config.SubAccountId),
+	}, nil
+}
+
+// Domain returns the EIP-712 domain of the actions of an environment.
+//
+// Parameters:
+//   - env: `constants.ENVIRONMENT_TESTNET` or `constants.ENVIRONMENT_MAINNET`.
+//
+// Returns:
+//   - The typed data domain.
+func Domain(env types.Environment) apitypes.TypedDataDomain {
+	return apitypes.TypedDataDomain{
+		Name:              constants.DOMAIN_NAME,
+		Version:           constants.DOMAIN_VERSION,
+		ChainId:           (*math.HexOrDecimal256)(new(big.Int).Set((*big.Int)(constants.CHAIN_ID[env]))),
+		VerifyingContract: constants.ORDER_DISPATCHER_ADDRESS[env],
+	}
+}
+
+// NewOrder builds the typed data of a new order, as signed by the `NewOrder` method of the clients.
+//
+// Parameters:
+//   - params: An instance of types.NewOrderRequest containing the order parameters.
+//
+// Returns:
+//   - A pointer to apitypes.TypedData.
+//   - An error if the typed data cannot be built.
+func (builder *Builder) NewOrder(params *types.NewOrderRequest) (*apitypes.TypedData, error) {
+	return utils.TypedData(
+		builder.domain,
+		constants.PRIMARY_TYPE_ORDER,
+		&struct {
+			Account      string `json:"account"`
+			SubAccountId string `json:"subAccountId"`
+			ProductId    string `json:"productId"`
+			IsBuy        bool   `json:"isBuy"`
+			OrderType    string `json:"orderType"`
+			TimeInForce  string `json:"timeInForce"`
+			Expiration   string `json:"expiration"`
+			Price        string `json:"price"`
+			Quantity     string `json:"quantity"`
+			Nonce        string `json:"nonce"`
+		}{
+			Account:      builder.account,
+			SubAccountId: strconv.FormatInt(builder.subAccountId, 10),
+			ProductId:    strconv.FormatInt(params.Product.Id, 10),
+			IsBuy:        params.IsBuy,
+			OrderType:    strconv.FormatInt(int64(params.OrderType), 10),
+			TimeInForce:  strconv.FormatInt(int64(params.TimeInForce), 10),
+			Expiration:   strconv.FormatInt(params.Expiration, 10),
+			Price:        params.Price,
+			Quantity:     params.Quantity,
+			Nonce:        strconv.FormatInt(params.Nonce, 10),
+		},
+	)
+}
+
+// CancelOrder builds the typed data of an order cancellation, as signed by the `CancelOrder` method of the clients.
+//
+// Parameters:
+//   - params: An instance of types.CancelOrderRequest identifying the order to cancel.
+//
+// Returns:
+//   - A pointer to apitypes.TypedData.
+//   - An error if the typed data cannot be built.
+func (builder *Builder) CancelOrder(params *types.CancelOrderRequest) (*apitypes.TypedData, error) {
+	return utils.TypedData(
+		builder.domain,
+		constants.PRIMARY_TYPE_CANCEL_ORDER,
+		&struct {
+			Account      string `json:"account"`
+			SubAccountId string `json:"subAccountId"`
+			ProductId    string `json:"productId"`
+			OrderId      string `json:"orderId"`
+		}{
+			Account:      builder.account,
+			SubAccountId: strconv.FormatInt(builder.subAccountId, 10),
+			ProductId:    strconv.FormatInt(params.Product.Id, 10),
+			OrderId:      params.IdToCancel,
+		},
+	)
+}
+
+// CancelAllOpenOrders builds the typed data of the cancellation of all open orders on a product, as signed by the
+// `CancelAllOpenOrders` method of the REST client.
+//
+// Parameters:
+//   - product: The product for which all active orders should be canceled.
+//
+// Returns:
+//   - A pointer to apitypes.TypedData.
+//   - An error if the typed data cannot be built.
+func (builder *Builder) CancelAllOpenOrders(product *types.Product) (*apitypes.TypedData, error) {
+	return utils.TypedData(
+		builder.domain,
+		constants.PRIMARY_TYPE_CANCEL_ORDERS,
+		&struct {
+			Account      string `json:"account"`
+			SubAccountId string `json:"subAccountId"`
+			ProductId    string `json:"productId"`
+		}{
+			Account:      builder.account,
+			SubAccountId: strconv.FormatInt(builder.subAccountId, 10),
+			ProductId:    strconv.FormatInt(product.Id, 10),
+		},
+	)
+}
+
+// ApproveSigner builds the typed data of a signer approval, as signed by the `ApproveSigner` method of the clients.
+//
+// Parameters:
+//   - params: An instance of types.ApproveRevokeSignerRequest containing the signer and nonce.
+//
+// Returns:
+//   - A pointer to apitypes.TypedData.
+//   - An error if the typed data cannot be built.
+func (builder *Builder) ApproveSigner(params *types.ApproveRevokeSignerRequest) (*apitypes.TypedData, error) {
+	return builder.approveRevokeSigner(params, true)
+}
+
+// RevokeSigner builds the typed data of a signer revocation, as signed by the `RevokeSigner` method of the clients.
+//
+// Parameters:
+//   - params: An instance of types.ApproveRevokeSignerRequest containing the signer and nonce.
+//
+// Returns:
+//   - A pointer to apitypes.TypedData.
+//   - An error if the typed data cannot be built.
+func (builder *Builder) RevokeSigner(params *types.ApproveRevokeSignerRequest) (*apitypes.TypedData, error) {
+	return builder.approveRevokeSigner(params, false)
+}
+
+// approveRevokeSigner builds the typed data of a signer approval or revocation.
+func (builder *Builder) approveRevokeSigner(params *types.ApproveRevokeSignerRequest, isApproved bool) (*apitypes.TypedData, error) {
+	return utils.TypedData(
+		builder.domain,
+		constants.PRIMARY_TYPE_APPROVE_SIGNER,
+		&struct {
+			Account        string `json:"account"`
+			SubAccountId   string `json:"subAccountId"`
+			ApprovedSigner string `json:"approvedSigner"`
+			IsApproved     bool   `json:"isApproved"`
+			Nonce          string `json:"nonce"`
+		}{
+			Account:        builder.account,
+			SubAccountId:   strconv.FormatInt(builder.subAccountId, 10),
+			ApprovedSigner: params.ApprovedSigner,
+			IsApproved:     isApproved,
+			Nonce:          strconv.FormatInt(params.Nonce, 10),
+		},
+	)
+}
+
+// Withdraw builds the typed data of a USDC withdrawal, as signed by the `Withdraw` method of the clients.
+//
+// Parameters:
+//   - params: An instance of types.WithdrawRequest containing the quantity and nonce.
+//
+// Returns:
+//   - A pointer to apitypes.TypedData.
+//   - An error if the typed data cannot be built.
+func (builder *Builder) Withdraw(params *types.WithdrawRequest) (*apitypes.TypedData, error) {
+	return utils.TypedData(
+		builder.domain,
+		constants.PRIMARY_TYPE_WITHDRAW,
+		&struct {
+			Account      string `json:"account"`
+			SubAccountId string `json:"subAccountId"`
+			Asset        string `json:"asset"`
+			Quantity     string `json:"quantity"`
+			Nonce        string `json:"nonce"`
+		}{
+			Account:      builder.account,
+			SubAccountId: strconv.FormatInt(builder.subAccountId, 10),
+			Asset:        constants.USDC_ADDRESS[builder.env],
+			Quantity:     params.Quantity,
+			Nonce:        strconv.FormatInt(params.Nonce, 10),
+		},
+	)
+}
+
+// Login builds the typed data of a websocket login, as signed by the `Login` method of the websocket client.
+//
+// Parameters:
+//   - timestamp: UNIX timestamp (in ms) of the login. Logins older than 10 seconds are rejected when submitted, so it
+//     should leave time to sign externally.
+//
+// Returns:
+//   - A pointer to apitypes.TypedData.
+//   - An error if the typed data cannot be built.
+func (builder *Builder) Login(timestamp uint64) (*apitypes.TypedData, error) {
+	return utils.TypedData(
+		builder.domain,
+		constants.PRIMARY_TYPE_LOGIN_MESSAGE,
+		&struct {
+			Account   string `json:"account"`
+			Message   string `json:"message"`
+			Timestamp string `json:"timestamp"`
+		}{
+			Account:   builder.account,
+			Message:   constants.LOGIN_MESSAGE,
+			Timestamp: strconv.FormatUint(timestamp, 10),
+		},
+	)
+}
+
+// Referral builds the typed data of the addition of the account as referee of a referral code.
+//
+// Parameters:
+//   - code: The referral code.
+//
+// Returns:
+//   - A pointer to apitypes.TypedData.
+//   - An error if the typed data cannot be built.
+func (builder *Builder) Referral(code string) (*apitypes.TypedData, error) {
+	return utils.TypedData(
+		builder.domain,
+		constants.PRIMARY_TYPE_REFERRAL,
+		&struct {
+			Account string `json:"account"`
+			Code    string `json:"code"`
+		}{
+			Account: builder.account,
+			Code:    code,
+		},
+	)
+}
+
+// Marshal encodes typed data as the JSON accepted by `eth_signTypedData_v4`, with a numeric chain ID.
+//
+// Parameters:
+//   - typedData: The typed data, as built by a Builder.
+//
+// Returns:
+//   - The JSON typed data.
+//   - An error if the typed data cannot be encoded.
+func Marshal(typedData *apitypes.TypedData) ([]byte, error) {
+	domain := typedData.Domain.Map()
+	if typedData.Domain.ChainId != nil {
+		domain["chainId"] = (*big.Int)(typedData.Domain.ChainId)
+	}
+	return json.Marshal(&struct {
+		Types       apitypes.Types            `json:"types"`
+		PrimaryType string                    `json:"primaryType"`
+		Domain      map[string]interface{}    `json:"domain"`
+		Message     apitypes.TypedDataMessage `json:"message"`
+	}{
+		Types:       typedData.Types,
+		PrimaryType: typedData.PrimaryType,
+		Domain:      domain,
+		Message:     typedData.Message,
+	})
+}
+
+// Unmarshal decodes the JSON typed data of an action, as encoded by `Marshal`.
+//
+// Parameters:
+//   - data: The JSON typed data.
+//
+// Returns:
+//   - A pointer to apitypes.TypedData.
+//   - An error if the JSON is invalid or its primary type is not an action.
+func Unmarshal(data []byte) (*apitypes.TypedData, error) {
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal(data, &typedData); err != nil {
+		return nil, fmt.Errorf("failed to decode typed data: %v", err)
+	}
+	if _, ok := integerFields[types.PrimaryType(typedData.PrimaryType)]; !ok {
+		return nil, fmt.Errorf("unsupported primary type %q", typedData.PrimaryType)
+	}
+	return &typedData, nil
+}
+
+// Params rebuilds the request params of an action from its typed data and external signature, after checking that the
+// typed data is in the expected domain and that the signature is valid. The typed data is hashed with the types of
+// `types.EIP712_TYPES`, whatever types it holds.
+//
+// Parameters:
+//   - domain: The EIP-712 domain of the client submitting the action.
+//   - typedData: The typed data of the action, as built by a Builder.
+//   - signature: The external signature of the typed data in hexadecimal format.
+//
+// Returns:
+//   - The request params, the message fields with their integers as numbers and the signature.
+//   - An error if the action is not supported, the domain differs, the signature is invalid (a *utils.SignatureError)
+//     or a message integer is invalid.
+func Params(domain apitypes.TypedDataDomain, typedData *apitypes.TypedData, signature string) (map[string]interface{}, error) {
+	primaryType := types.PrimaryType(typedData.PrimaryType)
+	fields, ok := integerFields[primaryType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported primary type %q", typedData.PrimaryType)
+	}
+	if err := checkDomain(domain, typedData.Domain); err != nil {
+		return nil, err
+	}
+	if _, err := utils.RecoverSigner(domain, primaryType, typedData.Message, signature); err != nil {
+		return nil, err
+	}
+
+	params := map[string]interface{}{"signature": signature}
+	for name, value := range typedData.Message {
+		params[name] = value
+	}
+	for _, name := range fields {
+		integer, err := parseInteger(typedData.Message[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", name, err)
+		}
+		params[name] = integer
+	}
+	return params, nil
+}
+
+// checkDomain checks that the domain of typed data has the same separator as the expected domain.
+func checkDomain(expected apitypes.TypedDataDomain, domain apitypes.TypedDataDomain) error {
+	separator := func(domain apitypes.TypedDataDomain) (string, error) {
+		typedData := apitypes.TypedData{Types: types.EIP712_TYPES, Domain: domain}
+		hash, err := typedData.HashStruct(constants.EIP_712_DOMAIN, domain.Map())
+		return hash.String(), err
+	}
+	expectedSeparator, err := separator(expected)
+	if err != nil {
+		return fmt.Errorf("invalid domain: %v", err)
+	}
+	domainSeparator, err := separator(domain)
+	if err != nil {
+		return fmt.Errorf("invalid typed data domain: %v", err)
+	}
+	if domainSeparator != expectedSeparator {
+		return fmt.Errorf("typed data domain %s does not match domain %s", domainSeparator, expectedSeparator)
+	}
+	return nil
+}
+
+// parseInteger parses a message integer, a decimal or hexadecimal string or a JSON number.
+func parseInteger(value interface{}) (int64, error) {
+	var integer *big.Int
+	switch value := value.(type) {
+	case string:
+		parsed, ok := math.ParseBig256(value)
+		if !ok {
+			return 0, fmt.Errorf("%q is not an integer", value)
+		}
+		integer = parsed
+	case float64:
+		parsed, accuracy := big.NewFloat(value).Int(nil)
+		if accuracy != big.Exact {
+			return 0, fmt.Errorf("%v is not an integer", value)
+		}
+		integer = parsed
+	case json.Number:
+		return parseInteger(value.String())
+	default:
+		return 0, fmt.Errorf("%v is not an integer", value)
+	}
+	if !integer.IsInt64() {
+		return 0, fmt.Errorf("%v is out of range", integer)
+	}
+	return integer.Int64(), nil
+}
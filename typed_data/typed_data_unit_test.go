@@ -0,0 +1,191 @@
+//go:build !integration
+// +build !integration
+
+package typed_data
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TypedDataUnitTestSuite struct {
+	suite.Suite
+	PrivateKey *ecdsa.PrivateKey
+	Account    string
+	Builder    *Builder
+}
+
+func (s *TypedDataUnitTestSuite) SetupTest() {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(s.T(), err)
+	s.PrivateKey = privateKey
+	s.Account = crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+	s.Builder, err = NewBuilder(&BuilderConfiguration{Env: constants.ENVIRONMENT_TESTNET, Account: s.Account, SubAccountId: 1})
+	require.NoError(s.T(), err)
+}
+
+func TestRunSuiteUnit_TypedData(t *testing.T) {
+	suite.Run(t, new(TypedDataUnitTestSuite))
+}
+
+// sign signs JSON typed data as an external wallet does with `eth_signTypedData_v4`.
+func (s *TypedDataUnitTestSuite) sign(data []byte) string {
+	var typedData apitypes.TypedData
+	require.NoError(s.T(), json.Unmarshal(data, &typedData))
+	digest, _, err := apitypes.TypedDataAndHash(typedData)
+	require.NoError(s.T(), err)
+	signature, err := crypto.Sign(digest, s.PrivateKey)
+	require.NoError(s.T(), err)
+	signature[crypto.RecoveryIDOffset] += 27
+	return hexutil.Encode(signature)
+}
+
+func (s *TypedDataUnitTestSuite) TestUnit_NewBuilder() {
+	_, err := NewBuilder(&BuilderConfiguration{Env: "devnet", Account: s.Account})
+	require.Error(s.T(), err)
+	_, err = NewBuilder(&BuilderConfiguration{Env: constants.ENVIRONMENT_TESTNET, Account: "0x1234"})
+	require.Error(s.T(), err)
+	require.Equal(s.T(), Domain(constants.ENVIRONMENT_MAINNET).VerifyingContract, constants.ORDER_DISPATCHER_ADDRESS[constants.ENVIRONMENT_MAINNET])
+}
+
+func (s *TypedDataUnitTestSuite) TestUnit_Domain() {
+	// Each domain owns its chain ID, which go-ethereum mutates while hashing.
+	domain := Domain(constants.ENVIRONMENT_TESTNET)
+	require.Equal(s.T(), (*big.Int)(constants.CHAIN_ID[constants.ENVIRONMENT_TESTNET]), (*big.Int)(domain.ChainId))
+	require.NotSame(s.T(), constants.CHAIN_ID[constants.ENVIRONMENT_TESTNET], domain.ChainId)
+	require.NotSame(s.T(), domain.ChainId, Domain(constants.ENVIRONMENT_TESTNET).ChainId)
+
+	(*big.Int)(domain.ChainId).SetInt64(1)
+	require.Equal(s.T(), int64(421614), (*big.Int)(constants.CHAIN_ID[constants.ENVIRONMENT_TESTNET]).Int64())
+}
+
+func (s *TypedDataUnitTestSuite) TestUnit_Actions() {
+	product := &constants.PRODUCT_ETH_PERP
+	signerParams := &types.ApproveRevokeSignerRequest{ApprovedSigner: "0x0000000000000000000000000000000000000001", Nonce: 2}
+	type action struct {
+		typedData *apitypes.TypedData
+		err       error
+		params    map[string]interface{} // params are the expected request params besides the account and signature.
+	}
+	var actions []action
+	typedData, err := s.Builder.NewOrder(&types.NewOrderRequest{Product: product, IsBuy: true, OrderType: constants.ORDER_TYPE_LIMIT, TimeInForce: constants.TIME_IN_FORCE_GTC, Price: "100", Quantity: "3", Expiration: 4, Nonce: 5})
+	actions = append(actions, action{typedData, err, map[string]interface{}{"subAccountId": int64(1), "productId": product.Id, "isBuy": true, "orderType": int64(constants.ORDER_TYPE_LIMIT), "timeInForce": int64(constants.TIME_IN_FORCE_GTC), "expiration": int64(4), "price": "100", "quantity": "3", "nonce": int64(5)}})
+	typedData, err = s.Builder.CancelOrder(&types.CancelOrderRequest{Product: product, IdToCancel: "0xabc"})
+	actions = append(actions, action{typedData, err, map[string]interface{}{"subAccountId": int64(1), "productId": product.Id, "orderId": "0xabc"}})
+	typedData, err = s.Builder.CancelAllOpenOrders(product)
+	actions = append(actions, action{typedData, err, map[string]interface{}{"subAccountId": int64(1), "productId": product.Id}})
+	typedData, err = s.Builder.ApproveSigner(signerParams)
+	actions = append(actions, action{typedData, err, map[string]interface{}{"subAccountId": int64(1), "approvedSigner": signerParams.ApprovedSigner, "isApproved": true, "nonce": int64(2)}})
+	typedData, err = s.Builder.RevokeSigner(signerParams)
+	actions = append(actions, action{typedData, err, map[string]interface{}{"subAccountId": int64(1), "approvedSigner": signerParams.ApprovedSigner, "isApproved": false, "nonce": int64(2)}})
+	typedData, err = s.Builder.Withdraw(&types.WithdrawRequest{Quantity: "7", Nonce: 8})
+	actions = append(actions, action{typedData, err, map[string]interface{}{"subAccountId": int64(1), "asset": constants.USDC_ADDRESS[constants.ENVIRONMENT_TESTNET], "quantity": "7", "nonce": int64(8)}})
+	typedData, err = s.Builder.Login(1718000000000)
+	actions = append(actions, action{typedData, err, map[string]interface{}{"message": constants.LOGIN_MESSAGE, "timestamp": int64(1718000000000)}})
+	typedData, err = s.Builder.Referral("CODE")
+	actions = append(actions, action{typedData, err, map[string]interface{}{"code": "CODE"}})
+
+	domain := Domain(constants.ENVIRONMENT_TESTNET)
+	for _, action := range actions {
+		require.NoError(s.T(), action.err)
+		primaryType := types.PrimaryType(action.typedData.PrimaryType)
+
+		// Typed data hold the domain and primary types only, and hash as the clients sign.
+		require.Len(s.T(), action.typedData.Types, 2)
+		data, err := Marshal(action.typedData)
+		require.NoError(s.T(), err)
+		var decoded struct {
+			Domain map[string]interface{} `json:"domain"`
+		}
+		require.NoError(s.T(), json.Unmarshal(data, &decoded))
+		require.Equal(s.T(), float64(421614), decoded.Domain["chainId"])
+		require.NotContains(s.T(), decoded.Domain, "salt")
+
+		// Externally signed JSON is submitted with its integers as numbers.
+		signature := s.sign(data)
+		imported, err := Unmarshal(data)
+		require.NoError(s.T(), err)
+		params, err := Params(domain, imported, signature)
+		require.NoError(s.T(), err, primaryType)
+		expected := map[string]interface{}{"account": s.Account, "signature": signature}
+		for name, value := range action.params {
+			expected[name] = value
+		}
+		require.Equal(s.T(), expected, params, primaryType)
+
+		// The signature recovers the account and matches the one of `utils.SignMessage`.
+		signer, err := utils.RecoverSigner(domain, primaryType, imported.Message, signature)
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), s.Account, signer.Hex())
+		clientSignature, err := utils.SignMessage(domain, hex.EncodeToString(crypto.FromECDSA(s.PrivateKey)), primaryType, action.typedData.Message)
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), clientSignature, signature)
+	}
+}
+
+func (s *TypedDataUnitTestSuite) TestUnit_Params() {
+	domain := Domain(constants.ENVIRONMENT_TESTNET)
+	typedData, err := s.Builder.Withdraw(&types.WithdrawRequest{Quantity: "7", Nonce: 8})
+	require.NoError(s.T(), err)
+	data, err := Marshal(typedData)
+	require.NoError(s.T(), err)
+	signature := s.sign(data)
+
+	// Typed data of another domain or with another signature are rejected.
+	_, err = Params(Domain(constants.ENVIRONMENT_MAINNET), typedData, signature)
+	require.ErrorContains(s.T(), err, "does not match")
+	var signatureError *utils.SignatureError
+	_, err = Params(domain, typedData, "0x1234")
+	require.ErrorAs(s.T(), err, &signatureError)
+
+	// Edited messages no longer match their signature, or fail to hash.
+	edited, err := Unmarshal(data)
+	require.NoError(s.T(), err)
+	edited.Message["quantity"] = "8"
+	params, err := Params(domain, edited, signature)
+	require.NoError(s.T(), err)
+	signer, err := utils.RecoverSigner(domain, constants.PRIMARY_TYPE_WITHDRAW, edited.Message, signature)
+	require.NoError(s.T(), err)
+	require.NotEqual(s.T(), s.Account, signer.Hex())
+	require.Equal(s.T(), "8", params["quantity"])
+	edited.Message["extra"] = "1"
+	_, err = Params(domain, edited, signature)
+	require.ErrorAs(s.T(), err, &signatureError)
+
+	// Integers may be hexadecimal strings or JSON numbers.
+	for _, nonce := range []interface{}{"0x8", float64(8), json.Number("8")} {
+		typedData.Message["nonce"] = nonce
+		params, err = Params(domain, typedData, signature)
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), int64(8), params["nonce"])
+	}
+	_, err = parseInteger(8.5)
+	require.Error(s.T(), err)
+	_, err = parseInteger("0x10000000000000000")
+	require.ErrorContains(s.T(), err, "out of range")
+	_, err = parseInteger(true)
+	require.Error(s.T(), err)
+
+	// Only actions are supported.
+	typedData.PrimaryType = string(constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION)
+	_, err = Params(domain, typedData, signature)
+	require.ErrorContains(s.T(), err, "unsupported primary type")
+	data, err = Marshal(typedData)
+	require.NoError(s.T(), err)
+	_, err = Unmarshal(data)
+	require.ErrorContains(s.T(), err, "unsupported primary type")
+	_, err = Unmarshal([]byte("{"))
+	require.Error(s.T(), err)
+}
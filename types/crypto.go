@@ -9,12 +9,37 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
+type GasStrategy string
+type ApprovalMode string
+
 type IEthClient interface {
 	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
 	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
 	EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error)
 	NetworkID(ctx context.Context) (*big.Int, error)
 	SendTransaction(ctx context.Context, tx *types.Transaction) error
 	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
 	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// GasConfiguration holds the EIP-1559 fee settings used when building on-chain transactions.
+type GasConfiguration struct {
+	Strategy       GasStrategy // The gas strategy. Can be `constants.GAS_STRATEGY_SUGGESTED`, `constants.GAS_STRATEGY_FIXED`, `constants.GAS_STRATEGY_MULTIPLIER` or `constants.GAS_STRATEGY_CAP`.
+	GasTipCap      *big.Int    // Priority fee per gas in wei. Required by `constants.GAS_STRATEGY_FIXED`.
+	GasFeeCap      *big.Int    // Max fee per gas in wei. Required by `constants.GAS_STRATEGY_FIXED`.
+	Multiplier     float64     // Factor applied to the suggested fees. Required by `constants.GAS_STRATEGY_MULTIPLIER`.
+	MaxGasFeeCap   *big.Int    // Upper bound for the max fee per gas in wei. Required by `constants.GAS_STRATEGY_CAP`, optional otherwise.
+	GasLimitBuffer uint64      // Percentage added on top of `EstimateGas`, e.g. 20 for +20%.
+}
+
+// DepositResult holds the transactions and receipts of a deposit workflow.
+type DepositResult struct {
+	ApproveTransaction *types.Transaction // USDC approval transaction, nil when the allowance was already sufficient.
+	ApproveReceipt     *types.Receipt     // USDC approval receipt, nil when the allowance was already sufficient.
+	DepositTransaction *types.Transaction // Deposit transaction.
+	DepositReceipt     *types.Receipt     // Deposit receipt.
 }
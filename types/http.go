@@ -2,6 +2,8 @@ package types
 
 import "net/http"
 
+type EndpointClass string
+
 type IHTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
@@ -0,0 +1,99 @@
+package types
+
+type SubAccount struct {
+	Account      string `json:"account"`      // The account address.
+	SubAccountId int64  `json:"subAccountId"` // The ID of the sub-account.
+	Name         string `json:"name"`         // The name of the sub-account, if any.
+}
+
+type SpotBalance struct {
+	Account           string `json:"account"`           // The account address.
+	SubAccountId      int64  `json:"subAccountId"`      // The ID of the sub-account.
+	Asset             string `json:"asset"`             // The asset address.
+	Quantity          string `json:"quantity"`          // Quantity in wei (e18).
+	PendingWithdrawal string `json:"pendingWithdrawal"` // Quantity pending withdrawal in wei (e18).
+}
+
+type PerpetualPosition struct {
+	Account        string `json:"account"`        // The account address.
+	SubAccountId   int64  `json:"subAccountId"`   // The ID of the sub-account.
+	ProductId      int64  `json:"productId"`      // The ID of the product.
+	Quantity       string `json:"quantity"`       // Signed position size in wei (e18), negative for shorts.
+	AvgEntryPrice  string `json:"avgEntryPrice"`  // Average entry price in wei (e18).
+	InitCumFunding string `json:"initCumFunding"` // Cumulative funding when the position was opened in wei (e18).
+	Margin         string `json:"margin"`         // Margin allocated to the position in wei (e18).
+}
+
+type Order struct {
+	Id           string      `json:"id"`           // The unique ID of the order.
+	Account      string      `json:"account"`      // The account address.
+	SubAccountId int64       `json:"subAccountId"` // The ID of the sub-account.
+	ProductId    int64       `json:"productId"`    // The ID of the product.
+	IsBuy        bool        `json:"isBuy"`        // Whether the order is buying or selling.
+	OrderType    OrderType   `json:"orderType"`    // The order type.
+	TimeInForce  TimeInForce `json:"timeInForce"`  // Order time in force.
+	Price        string      `json:"price"`        // Price in wei (e18).
+	Quantity     string      `json:"quantity"`     // Quantity in wei (e18).
+	Filled       string      `json:"filled"`       // Filled quantity in wei (e18).
+	Status       string      `json:"status"`       // The order status.
+	Expiration   int64       `json:"expiration"`   // UNIX timestamp (in ms) after which the order is no longer active.
+	Nonce        int64       `json:"nonce"`        // The order nonce.
+	CreatedAt    int64       `json:"createdAt"`    // UNIX timestamp (in ms) of the order creation.
+}
+
+type ApprovedSigner struct {
+	Account    string `json:"account"`    // The account address.
+	Subaccount int64  `json:"subaccount"` // The ID of the sub-account.
+	Signer     string `json:"signer"`     // The approved signer address.
+	Approved   bool   `json:"approved"`   // Whether the signer is currently approved.
+}
+
+type Trade struct {
+	Id           string `json:"id"`           // The unique ID of the trade.
+	ProductId    int64  `json:"productId"`    // The ID of the product.
+	Symbol       string `json:"symbol"`       // The symbol of the product.
+	Price        string `json:"price"`        // Price in wei (e18).
+	Quantity     string `json:"quantity"`     // Quantity in wei (e18).
+	IsBuyerMaker bool   `json:"isBuyerMaker"` // Whether the buyer was the resting order.
+	Time         int64  `json:"time"`         // UNIX timestamp (in ms) of the trade.
+}
+
+type Kline struct {
+	Symbol    string   `json:"symbol"`    // The symbol of the product.
+	Interval  Interval `json:"interval"`  // The interval of the kline.
+	OpenTime  int64    `json:"openTime"`  // UNIX timestamp (in ms) of the kline open.
+	CloseTime int64    `json:"closeTime"` // UNIX timestamp (in ms) of the kline close.
+	Open      string   `json:"open"`      // Open price in wei (e18).
+	High      string   `json:"high"`      // High price in wei (e18).
+	Low       string   `json:"low"`       // Low price in wei (e18).
+	Close     string   `json:"close"`     // Close price in wei (e18).
+	Volume    string   `json:"volume"`    // Traded quantity in wei (e18).
+	Trades    int64    `json:"trades"`    // Number of trades.
+}
+
+type Ticker struct {
+	Symbol      string `json:"symbol"`      // The symbol of the product.
+	ProductId   int64  `json:"productId"`   // The ID of the product.
+	OpenPrice   string `json:"openPrice"`   // First price of the window in wei (e18).
+	HighPrice   string `json:"highPrice"`   // Highest price of the window in wei (e18).
+	LowPrice    string `json:"lowPrice"`    // Lowest price of the window in wei (e18).
+	LastPrice   string `json:"lastPrice"`   // Last price of the window in wei (e18).
+	PriceChange string `json:"priceChange"` // Last price minus open price in wei (e18).
+	Volume      string `json:"volume"`      // Traded quantity of the window in wei (e18).
+	OpenTime    int64  `json:"openTime"`    // UNIX timestamp (in ms) of the window start.
+	CloseTime   int64  `json:"closeTime"`   // UNIX timestamp (in ms) of the window end.
+}
+
+type OrderBookDepth struct {
+	Symbol       string      `json:"symbol"`       // The symbol of the product.
+	LastUpdateId int64       `json:"lastUpdateId"` // Sequence number of the last book update.
+	Bids         [][2]string `json:"bids"`         // Bid levels as `[price, quantity]` in wei (e18), best first.
+	Asks         [][2]string `json:"asks"`         // Ask levels as `[price, quantity]` in wei (e18), best first.
+}
+
+type AccountUpdate struct {
+	Type     string             `json:"type"`               // The update type: `order`, `position` or `balance`.
+	Order    *Order             `json:"order,omitempty"`    // The updated order, for `order` updates.
+	Position *PerpetualPosition `json:"position,omitempty"` // The updated position, for `position` updates.
+	Balance  *SpotBalance       `json:"balance,omitempty"`  // The updated balance, for `balance` updates.
+}
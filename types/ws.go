@@ -26,3 +26,18 @@ type WebsocketError struct {
 type IWSConnection interface {
 	WriteMessage(messageType int, body []byte) error
 }
+
+type IWSReader interface {
+	ReadMessage() (messageType int, body []byte, err error)
+}
+
+type WebsocketNotification struct {
+	JsonRPC string      `json:"jsonrpc"`
+	Method  WSMethod    `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type StreamMessage struct {
+	Stream string      `json:"stream"`
+	Data   interface{} `json:"data"`
+}
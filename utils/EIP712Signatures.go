@@ -1,18 +1,24 @@
 package utils
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/rysk-finance/v2_client_go/constants"
 	"github.com/rysk-finance/v2_client_go/types"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 )
 
+// ErrUnexpectedSigner reports a valid signature by a signer other than the expected account and approved signers.
+var ErrUnexpectedSigner = errors.New("unexpected signer")
+
 // SignMessage signs a message using EIP-712 and returns the signature.
 //
 // This function signs a message using the EIP-712 standard, which defines structured data hashing
@@ -27,28 +33,183 @@ import (
 //
 // Returns:
 //   - string: The signature of the message in hexadecimal format (with '0x' prefix).
-//   - error: An error if the signing process fails, nil otherwise.
+//   - error: A *SignatureError if the signing process fails, nil otherwise.
 func SignMessage(domain apitypes.TypedDataDomain, privateKey string, primaryType types.PrimaryType, message interface{}) (string, error) {
 	// Map message to `TypedDataMessage` interface.
 	typedDataMessage, err := mapMessageToTypedData(message)
 	if err != nil {
-		return "", err
+		return "", &SignatureError{Message: "failed to sign message", Err: err}
 	}
 
 	// Generate the EIP-712 message using the provided primary type, client `TypedDataDomain`, and `TypedDataMessage` message.
 	unsignedMessage, err := generateEIP712Message(primaryType, domain, typedDataMessage)
 	if err != nil {
-		return "", err
+		return "", &SignatureError{Message: "failed to sign message", Err: err}
 	}
 
 	// Load the private key from hex.
 	hexPrivateKey, err := crypto.HexToECDSA(privateKey)
 	if err != nil {
-		return "", err
+		return "", &SignatureError{Message: "failed to sign message", Err: err}
 	}
 
 	// Sign EIP-712 message and return the signature.
-	return signEIP712Message(unsignedMessage, hexPrivateKey)
+	signature, err := signEIP712Message(unsignedMessage, hexPrivateKey)
+	if err != nil {
+		return "", &SignatureError{Message: "failed to sign message", Err: err}
+	}
+	return signature, nil
+}
+
+// SignMessageCtx signs a message using EIP-712 like `SignMessage`, unless the context is done.
+//
+// Parameters:
+//   - ctx: Context of the request the message is signed for.
+//   - domain: The domain parameters required for EIP-712 signing.
+//   - privateKey: The private key of the signer in hexadecimal format (without '0x' prefix).
+//   - primaryType: The primary type describing the structure of the message being signed.
+//   - message: The message payload to be signed. It should conform to the primaryType structure.
+//
+// Returns:
+//   - string: The signature of the message in hexadecimal format (with '0x' prefix).
+//   - error: The context error if it is done, or an error if the signing process fails.
+func SignMessageCtx(ctx context.Context, domain apitypes.TypedDataDomain, privateKey string, primaryType types.PrimaryType, message interface{}) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return SignMessage(domain, privateKey, primaryType, message)
+}
+
+// HashMessage rebuilds the EIP-712 digest a signature of a message signs, from the types of `types.EIP712_TYPES`.
+//
+// Parameters:
+//   - domain: The domain parameters of the signature.
+//   - primaryType: The primary type describing the structure of the message, one of `types.EIP712_TYPES`.
+//   - message: The message payload, a struct or a `apitypes.TypedDataMessage` as passed to `SignMessage`.
+//
+// Returns:
+//   - []byte: The 32 bytes EIP-712 digest.
+//   - error: A *SignatureError if the primary type is unknown or the message does not conform to it.
+func HashMessage(domain apitypes.TypedDataDomain, primaryType types.PrimaryType, message interface{}) ([]byte, error) {
+	if err := checkPrimaryType(primaryType); err != nil {
+		return nil, &SignatureError{Message: "failed to hash message", Err: err}
+	}
+	typedDataMessage, err := mapMessageToTypedData(message)
+	if err != nil {
+		return nil, &SignatureError{Message: "failed to hash message", Err: err}
+	}
+	digest, err := generateEIP712Message(primaryType, domain, typedDataMessage)
+	if err != nil {
+		return nil, &SignatureError{Message: "failed to hash message", Err: err}
+	}
+	return digest, nil
+}
+
+// TypedData builds the EIP-712 typed data of a message, holding the domain and primary types of `types.EIP712_TYPES`
+// only, as signed by `SignMessage` and by external signers through `eth_signTypedData_v4`.
+//
+// Parameters:
+//   - domain: The domain parameters of the signature.
+//   - primaryType: The primary type describing the structure of the message, one of `types.EIP712_TYPES`.
+//   - message: The message payload, a struct or a `apitypes.TypedDataMessage` as passed to `SignMessage`.
+//
+// Returns:
+//   - *apitypes.TypedData: The typed data of the message.
+//   - error: A *SignatureError if the primary type is unknown or the message does not conform to it.
+func TypedData(domain apitypes.TypedDataDomain, primaryType types.PrimaryType, message interface{}) (*apitypes.TypedData, error) {
+	if err := checkPrimaryType(primaryType); err != nil {
+		return nil, &SignatureError{Message: "failed to build typed data", Err: err}
+	}
+	typedDataMessage, err := mapMessageToTypedData(message)
+	if err != nil {
+		return nil, &SignatureError{Message: "failed to build typed data", Err: err}
+	}
+	if _, err := generateEIP712Message(primaryType, domain, typedDataMessage); err != nil {
+		return nil, &SignatureError{Message: "failed to build typed data", Err: err}
+	}
+	return &apitypes.TypedData{
+		Types: apitypes.Types{
+			constants.EIP_712_DOMAIN: types.EIP712_TYPES[constants.EIP_712_DOMAIN],
+			string(primaryType):      types.EIP712_TYPES[string(primaryType)],
+		},
+		PrimaryType: string(primaryType),
+		Domain:      domain,
+		Message:     typedDataMessage,
+	}, nil
+}
+
+// RecoverSigner recovers the address whose key signed a message using EIP-712.
+//
+// Parameters:
+//   - domain: The domain parameters of the signature.
+//   - primaryType: The primary type describing the structure of the message, one of `types.EIP712_TYPES`.
+//   - message: The message payload, a struct or a `apitypes.TypedDataMessage` as passed to `SignMessage`.
+//   - signature: The 65 bytes signature in hexadecimal format, with recovery ID 27 or 28 as returned by `SignMessage`, or 0 or 1.
+//
+// Returns:
+//   - common.Address: The address of the signer.
+//   - error: A *SignatureError if the message cannot be hashed or the signature is malformed.
+func RecoverSigner(domain apitypes.TypedDataDomain, primaryType types.PrimaryType, message interface{}, signature string) (common.Address, error) {
+	digest, err := HashMessage(domain, primaryType, message)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	// Normalize the recovery ID to 0 or 1.
+	signatureBytes, err := hexutil.Decode(signature)
+	if err != nil {
+		return common.Address{}, &SignatureError{Message: "invalid signature", Err: err}
+	}
+	if len(signatureBytes) != crypto.SignatureLength {
+		return common.Address{}, &SignatureError{Message: "invalid signature", Err: fmt.Errorf("invalid signature length %d", len(signatureBytes))}
+	}
+	if signatureBytes[crypto.RecoveryIDOffset] >= 27 {
+		signatureBytes[crypto.RecoveryIDOffset] -= 27
+	}
+
+	publicKey, err := crypto.SigToPub(digest, signatureBytes)
+	if err != nil {
+		return common.Address{}, &SignatureError{Message: "invalid signature", Err: err}
+	}
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
+
+// VerifySignature checks that a message was signed using EIP-712 by an account, or one of its approved signers.
+//
+// Parameters:
+//   - domain: The domain parameters of the signature.
+//   - primaryType: The primary type describing the structure of the message, one of `types.EIP712_TYPES`.
+//   - message: The message payload, a struct or a `apitypes.TypedDataMessage` as passed to `SignMessage`.
+//   - signature: The signature in hexadecimal format, as returned by `SignMessage`.
+//   - account: The account expected to sign the message.
+//   - approvedSigners: The signers approved to sign the message on behalf of the account, if any.
+//
+// Returns:
+//   - common.Address: The address of the signer, the account or one of the approved signers.
+//   - error: A *SignatureError if the signature is invalid, wrapping `ErrUnexpectedSigner` if it is valid but by
+//     another signer.
+func VerifySignature(domain apitypes.TypedDataDomain, primaryType types.PrimaryType, message interface{}, signature string, account common.Address, approvedSigners ...common.Address) (common.Address, error) {
+	signer, err := RecoverSigner(domain, primaryType, message, signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if signer == account {
+		return signer, nil
+	}
+	for _, approvedSigner := range approvedSigners {
+		if signer == approvedSigner {
+			return signer, nil
+		}
+	}
+	return common.Address{}, &SignatureError{Message: fmt.Sprintf("signer %s cannot sign for %s", signer.Hex(), account.Hex()), Err: ErrUnexpectedSigner}
+}
+
+// checkPrimaryType checks that a primary type is a message type of `types.EIP712_TYPES`.
+func checkPrimaryType(primaryType types.PrimaryType) error {
+	if _, ok := types.EIP712_TYPES[string(primaryType)]; !ok || string(primaryType) == constants.EIP_712_DOMAIN {
+		return fmt.Errorf("unknown primary type %q", primaryType)
+	}
+	return nil
 }
 
 // mapMessageToTypedData maps any struct to `TypedDataMessage`.
@@ -4,6 +4,7 @@
 package utils
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"encoding/hex"
 	"strconv"
@@ -15,6 +16,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -215,6 +217,30 @@ func (suite *EIP712SignaturesTestSuite) TestUnit_SignMessage() {
 	require.True(suite.T(), strings.HasPrefix(signature, "0x"))
 }
 
+func (suite *EIP712SignaturesTestSuite) TestUnit_SignMessageCtx() {
+	typedDataDomain := apitypes.TypedDataDomain{
+		Name:              constants.DOMAIN_NAME,
+		Version:           constants.DOMAIN_VERSION,
+		ChainId:           constants.CHAIN_ID[constants.ENVIRONMENT_TESTNET],
+		VerifyingContract: constants.ORDER_DISPATCHER_ADDRESS[constants.ENVIRONMENT_TESTNET],
+	}
+	message := &struct {
+		Account      string `json:"account"`
+		SubAccountId string `json:"subAccountId"`
+	}{
+		Account:      "0x0000000000000000000000000000000000000000",
+		SubAccountId: "1",
+	}
+	signature, err := SignMessageCtx(context.Background(), typedDataDomain, suite.PrivateKeyString, constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION, message)
+	require.NoError(suite.T(), err)
+	require.True(suite.T(), strings.HasPrefix(signature, "0x"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = SignMessageCtx(ctx, typedDataDomain, suite.PrivateKeyString, constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION, message)
+	require.ErrorIs(suite.T(), err, context.Canceled)
+}
+
 func (suite *EIP712SignaturesTestSuite) TestUnit_SignMessage_MapMessageToTypedDataError() {
 	typedDataDomain := apitypes.TypedDataDomain{
 		Name:              constants.DOMAIN_NAME,
@@ -288,5 +314,159 @@ func (suite *EIP712SignaturesTestSuite) TestUnit_SignMessage_HexToECDSAError() {
 			Nonce:          strconv.FormatInt(time.Now().UnixMilli(), 10),
 		},
 	)
-	require.Error(suite.T(), err)
+	var signatureError *SignatureError
+	require.ErrorAs(suite.T(), err, &signatureError)
+}
+
+func (suite *EIP712SignaturesTestSuite) TestUnit_HashMessage() {
+	typedDataDomain := apitypes.TypedDataDomain{
+		Name:              constants.DOMAIN_NAME,
+		Version:           constants.DOMAIN_VERSION,
+		ChainId:           constants.CHAIN_ID[constants.ENVIRONMENT_TESTNET],
+		VerifyingContract: constants.ORDER_DISPATCHER_ADDRESS[constants.ENVIRONMENT_TESTNET],
+	}
+	message := &struct {
+		Account string `json:"account"`
+		Code    string `json:"code"`
+	}{
+		Account: "0x0000000000000000000000000000000000000000",
+		Code:    "CODE",
+	}
+	typedDataMessage, err := mapMessageToTypedData(message)
+	require.NoError(suite.T(), err)
+	expected, err := generateEIP712Message(constants.PRIMARY_TYPE_REFERRAL, typedDataDomain, typedDataMessage)
+	require.NoError(suite.T(), err)
+
+	hash, err := HashMessage(typedDataDomain, constants.PRIMARY_TYPE_REFERRAL, message)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), expected, hash)
+	hash, err = HashMessage(typedDataDomain, constants.PRIMARY_TYPE_REFERRAL, typedDataMessage)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), expected, hash)
+
+	var signatureError *SignatureError
+	_, err = HashMessage(typedDataDomain, "Unknown", message)
+	require.ErrorAs(suite.T(), err, &signatureError)
+	_, err = HashMessage(typedDataDomain, types.PrimaryType(constants.EIP_712_DOMAIN), message)
+	require.ErrorAs(suite.T(), err, &signatureError)
+	_, err = HashMessage(typedDataDomain, constants.PRIMARY_TYPE_ORDER, message)
+	require.ErrorAs(suite.T(), err, &signatureError)
+}
+
+func (suite *EIP712SignaturesTestSuite) TestUnit_RecoverSigner() {
+	typedDataDomain := apitypes.TypedDataDomain{
+		Name:              constants.DOMAIN_NAME,
+		Version:           constants.DOMAIN_VERSION,
+		ChainId:           constants.CHAIN_ID[constants.ENVIRONMENT_TESTNET],
+		VerifyingContract: constants.ORDER_DISPATCHER_ADDRESS[constants.ENVIRONMENT_TESTNET],
+	}
+	message := &struct {
+		Account      string `json:"account"`
+		SubAccountId string `json:"subAccountId"`
+	}{
+		Account:      "0x0000000000000000000000000000000000000000",
+		SubAccountId: "1",
+	}
+	signature, err := SignMessage(typedDataDomain, suite.PrivateKeyString, constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION, message)
+	require.NoError(suite.T(), err)
+
+	signer, err := RecoverSigner(typedDataDomain, constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION, message, signature)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), crypto.PubkeyToAddress(suite.PrivateKey.PublicKey), signer)
+
+	// Recovery IDs 0 and 1 are accepted.
+	signatureBytes := common.FromHex(signature)
+	signatureBytes[crypto.RecoveryIDOffset] -= 27
+	signer, err = RecoverSigner(typedDataDomain, constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION, message, "0x"+common.Bytes2Hex(signatureBytes))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), crypto.PubkeyToAddress(suite.PrivateKey.PublicKey), signer)
+
+	// Another message recovers another signer.
+	message.SubAccountId = "2"
+	signer, err = RecoverSigner(typedDataDomain, constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION, message, signature)
+	require.NoError(suite.T(), err)
+	require.NotEqual(suite.T(), crypto.PubkeyToAddress(suite.PrivateKey.PublicKey), signer)
+
+	var signatureError *SignatureError
+	for _, invalid := range []string{"", "0x", "0xzz", signature[:len(signature)-2], "0x" + strings.Repeat("00", crypto.SignatureLength)} {
+		_, err = RecoverSigner(typedDataDomain, constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION, message, invalid)
+		require.ErrorAs(suite.T(), err, &signatureError, invalid)
+	}
+	_, err = RecoverSigner(typedDataDomain, "", message, signature)
+	require.ErrorAs(suite.T(), err, &signatureError)
+}
+
+func (suite *EIP712SignaturesTestSuite) TestUnit_VerifySignature() {
+	typedDataDomain := apitypes.TypedDataDomain{
+		Name:              constants.DOMAIN_NAME,
+		Version:           constants.DOMAIN_VERSION,
+		ChainId:           constants.CHAIN_ID[constants.ENVIRONMENT_TESTNET],
+		VerifyingContract: constants.ORDER_DISPATCHER_ADDRESS[constants.ENVIRONMENT_TESTNET],
+	}
+	account := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	signer := crypto.PubkeyToAddress(suite.PrivateKey.PublicKey)
+	message := &struct {
+		Account      string `json:"account"`
+		SubAccountId string `json:"subAccountId"`
+	}{
+		Account:      account.Hex(),
+		SubAccountId: "1",
+	}
+	signature, err := SignMessage(typedDataDomain, suite.PrivateKeyString, constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION, message)
+	require.NoError(suite.T(), err)
+
+	verified, err := VerifySignature(typedDataDomain, constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION, message, signature, signer)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), signer, verified)
+	verified, err = VerifySignature(typedDataDomain, constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION, message, signature, account, common.Address{}, signer)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), signer, verified)
+
+	// Signatures by other signers are rejected.
+	var signatureError *SignatureError
+	_, err = VerifySignature(typedDataDomain, constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION, message, signature, account)
+	require.ErrorAs(suite.T(), err, &signatureError)
+	require.ErrorIs(suite.T(), err, ErrUnexpectedSigner)
+	require.Contains(suite.T(), err.Error(), signer.Hex())
+	_, err = VerifySignature(typedDataDomain, constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION, message, signature, account, common.Address{})
+	require.ErrorIs(suite.T(), err, ErrUnexpectedSigner)
+
+	// Invalid signatures are not reported as unexpected signers.
+	_, err = VerifySignature(typedDataDomain, constants.PRIMARY_TYPE_SIGNED_AUTHENTICATION, message, "0x", signer)
+	require.ErrorAs(suite.T(), err, &signatureError)
+	require.NotErrorIs(suite.T(), err, ErrUnexpectedSigner)
+}
+
+func (suite *EIP712SignaturesTestSuite) TestUnit_TypedData() {
+	typedDataDomain := apitypes.TypedDataDomain{
+		Name:              constants.DOMAIN_NAME,
+		Version:           constants.DOMAIN_VERSION,
+		ChainId:           constants.CHAIN_ID[constants.ENVIRONMENT_TESTNET],
+		VerifyingContract: constants.ORDER_DISPATCHER_ADDRESS[constants.ENVIRONMENT_TESTNET],
+	}
+	message := &struct {
+		Account string `json:"account"`
+		Code    string `json:"code"`
+	}{
+		Account: "0x0000000000000000000000000000000000000000",
+		Code:    "CODE",
+	}
+	typedData, err := TypedData(typedDataDomain, constants.PRIMARY_TYPE_REFERRAL, message)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(constants.PRIMARY_TYPE_REFERRAL), typedData.PrimaryType)
+	require.Len(suite.T(), typedData.Types, 2)
+	require.Equal(suite.T(), "CODE", typedData.Message["code"])
+
+	// The typed data hash to the digest signed by `SignMessage`.
+	digest, _, err := apitypes.TypedDataAndHash(*typedData)
+	require.NoError(suite.T(), err)
+	hash, err := HashMessage(typedDataDomain, constants.PRIMARY_TYPE_REFERRAL, message)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), hash, digest)
+
+	var signatureError *SignatureError
+	_, err = TypedData(typedDataDomain, "Unknown", message)
+	require.ErrorAs(suite.T(), err, &signatureError)
+	_, err = TypedData(typedDataDomain, constants.PRIMARY_TYPE_ORDER, message)
+	require.ErrorAs(suite.T(), err, &signatureError)
 }
@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+// CallContract calls a read-only contract method at the latest block and returns its unpacked outputs.
+//
+// Parameters:
+//   - ctx: The context for the Ethereum client operations.
+//   - ethClient: Interface for interacting with the Ethereum blockchain.
+//   - contractABI: The JSON ABI of the contract, e.g. `constants.ERC20_ABI`.
+//   - contract: The address of the contract.
+//   - method: The name of the method to call.
+//   - args: The method arguments.
+//
+// Returns:
+//   - []interface{}: The unpacked method outputs.
+//   - error: An error if packing, calling or unpacking fails.
+func CallContract(
+	ctx context.Context,
+	ethClient types.IEthClient,
+	contractABI string,
+	contract common.Address,
+	method string,
+	args ...interface{},
+) ([]interface{}, error) {
+	// Parse ABI
+	parsedABI, err := abi.JSON(strings.NewReader(contractABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %v", err)
+	}
+
+	// Pack call data
+	data, err := parsedABI.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack call data: %v", err)
+	}
+
+	// Call contract
+	output, err := ethClient.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unpack outputs
+	return parsedABI.Unpack(method, output)
+}
+
+// GetAllowance returns the amount of an ERC20 token the spender is allowed to transfer from the owner.
+//
+// Parameters:
+//   - ctx: The context for the Ethereum client operations.
+//   - ethClient: Interface for interacting with the Ethereum blockchain.
+//   - token: The address of the ERC20 token.
+//   - owner: The address of the token owner.
+//   - spender: The address of the spender.
+//
+// Returns:
+//   - *big.Int: The allowance in token units.
+//   - error: An error if the call fails.
+func GetAllowance(ctx context.Context, ethClient types.IEthClient, token common.Address, owner common.Address, spender common.Address) (*big.Int, error) {
+	outputs, err := CallContract(ctx, ethClient, constants.ERC20_ABI, token, "allowance", owner, spender)
+	if err != nil {
+		return nil, err
+	}
+	return bigIntOutput(outputs)
+}
+
+// GetBalanceOf returns the ERC20 token balance of an account.
+//
+// Parameters:
+//   - ctx: The context for the Ethereum client operations.
+//   - ethClient: Interface for interacting with the Ethereum blockchain.
+//   - token: The address of the ERC20 token.
+//   - account: The address of the account.
+//
+// Returns:
+//   - *big.Int: The balance in token units.
+//   - error: An error if the call fails.
+func GetBalanceOf(ctx context.Context, ethClient types.IEthClient, token common.Address, account common.Address) (*big.Int, error) {
+	outputs, err := CallContract(ctx, ethClient, constants.ERC20_ABI, token, "balanceOf", account)
+	if err != nil {
+		return nil, err
+	}
+	return bigIntOutput(outputs)
+}
+
+// GetMinDepositAmount returns the minimum amount of an asset accepted by the CIAO contract for a deposit.
+//
+// Parameters:
+//   - ctx: The context for the Ethereum client operations.
+//   - ethClient: Interface for interacting with the Ethereum blockchain.
+//   - ciao: The address of the CIAO contract.
+//   - asset: The address of the deposited asset.
+//
+// Returns:
+//   - *big.Int: The minimum deposit amount in token units.
+//   - error: An error if the call fails.
+func GetMinDepositAmount(ctx context.Context, ethClient types.IEthClient, ciao common.Address, asset common.Address) (*big.Int, error) {
+	outputs, err := CallContract(ctx, ethClient, constants.CIAO_ABI, ciao, "minDepositAmount", asset)
+	if err != nil {
+		return nil, err
+	}
+	return bigIntOutput(outputs)
+}
+
+// GetDecimals returns the number of decimals of an ERC20 token.
+//
+// Parameters:
+//   - ctx: The context for the Ethereum client operations.
+//   - ethClient: Interface for interacting with the Ethereum blockchain.
+//   - token: The address of the ERC20 token.
+//
+// Returns:
+//   - uint8: The number of decimals.
+//   - error: An error if the call fails.
+func GetDecimals(ctx context.Context, ethClient types.IEthClient, token common.Address) (uint8, error) {
+	outputs, err := CallContract(ctx, ethClient, constants.ERC20_ABI, token, "decimals")
+	if err != nil {
+		return 0, err
+	}
+	if len(outputs) != 1 {
+		return 0, fmt.Errorf("unexpected number of outputs: %d", len(outputs))
+	}
+	decimals, ok := outputs[0].(uint8)
+	if !ok {
+		return 0, fmt.Errorf("unexpected output type: %T", outputs[0])
+	}
+	return decimals, nil
+}
+
+// bigIntOutput extracts a single `*big.Int` from unpacked contract outputs.
+func bigIntOutput(outputs []interface{}) (*big.Int, error) {
+	if len(outputs) != 1 {
+		return nil, fmt.Errorf("unexpected number of outputs: %d", len(outputs))
+	}
+	value, ok := outputs[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected output type: %T", outputs[0])
+	}
+	return value, nil
+}
@@ -0,0 +1,86 @@
+//go:build !integration
+// +build !integration
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/utils/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ContractsUnitTestSuite struct {
+	suite.Suite
+}
+
+func TestRunSuiteUnit_ContractsUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(ContractsUnitTestSuite))
+}
+
+func (s *ContractsUnitTestSuite) TestUnit_GetAllowance() {
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.OnCallContractMethod(constants.ERC20_ABI, "allowance", big.NewInt(1000))
+
+	allowance, err := GetAllowance(context.Background(), mockEthClient, common.MaxAddress, common.MaxAddress, common.MaxAddress)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), big.NewInt(1000), allowance)
+}
+
+func (s *ContractsUnitTestSuite) TestUnit_GetBalanceOf() {
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.OnCallContractMethod(constants.ERC20_ABI, "balanceOf", big.NewInt(2000))
+
+	balance, err := GetBalanceOf(context.Background(), mockEthClient, common.MaxAddress, common.MaxAddress)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), big.NewInt(2000), balance)
+}
+
+func (s *ContractsUnitTestSuite) TestUnit_GetMinDepositAmount() {
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.OnCallContractMethod(constants.CIAO_ABI, "minDepositAmount", big.NewInt(10))
+
+	minDepositAmount, err := GetMinDepositAmount(context.Background(), mockEthClient, common.MaxAddress, common.MaxAddress)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), big.NewInt(10), minDepositAmount)
+}
+
+func (s *ContractsUnitTestSuite) TestUnit_GetDecimals() {
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.OnCallContractMethod(constants.ERC20_ABI, "decimals", uint8(6))
+
+	decimals, err := GetDecimals(context.Background(), mockEthClient, common.MaxAddress)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), uint8(6), decimals)
+}
+
+func (s *ContractsUnitTestSuite) TestUnit_CallContract_ErrorCall() {
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.On("CallContract", mock.Anything, mock.Anything, mock.Anything).Return([]byte(nil), fmt.Errorf("failed to call contract"))
+
+	balance, err := GetBalanceOf(context.Background(), mockEthClient, common.MaxAddress, common.MaxAddress)
+	require.Error(s.T(), err)
+	require.Nil(s.T(), balance)
+}
+
+func (s *ContractsUnitTestSuite) TestUnit_CallContract_ErrorUnknownMethod() {
+	outputs, err := CallContract(context.Background(), new(mocks.MockEthClient), constants.ERC20_ABI, common.MaxAddress, "unknown")
+	require.Error(s.T(), err)
+	require.Nil(s.T(), outputs)
+}
+
+func (s *ContractsUnitTestSuite) TestUnit_CallContract_ErrorUnpack() {
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.On("CallContract", mock.Anything, mock.Anything, mock.Anything).Return([]byte{1, 2, 3}, nil)
+
+	allowance, err := GetAllowance(context.Background(), mockEthClient, common.MaxAddress, common.MaxAddress, common.MaxAddress)
+	require.Error(s.T(), err)
+	require.Nil(s.T(), allowance)
+}
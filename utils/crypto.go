@@ -59,6 +59,8 @@ func AddressFromPrivateKey(privateKeyHex string) string {
 //   - chainID: The ID of the Ethereum chain.
 //   - gasLimit: The maximum gas limit for the transaction.
 //   - err: Any error encountered during the retrieval of parameters.
+//
+// Deprecated: The clients send EIP-1559 transactions, use `GetDynamicFeeTransactionParams` instead.
 func GetTransactionParams(
 	ctx context.Context,
 	ethClient types.IEthClient,
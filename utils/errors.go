@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+// APIError is a failed REST response. Failures with a known cause are returned wrapped in a more specific error,
+// e.g. a `*ValidationError`, from which `errors.As` still extracts the APIError.
+type APIError struct {
+	StatusCode int    // StatusCode is the HTTP status code.
+	Code       int    // Code is the `code` field of the body, or the status code if the body has none.
+	Message    string // Message is the exchange error message, or the raw body if it is not JSON.
+	RequestID  string // RequestID is the ID the exchange assigned to the request, empty if not reported.
+}
+
+// Error returns the status, code, message and request ID of the response.
+func (apiError *APIError) Error() string {
+	message := fmt.Sprintf("API error %d", apiError.StatusCode)
+	if apiError.Code != apiError.StatusCode {
+		message += fmt.Sprintf(" (code %d)", apiError.Code)
+	}
+	message += ": " + apiError.Message
+	if apiError.RequestID != "" {
+		message += fmt.Sprintf(" (request %s)", apiError.RequestID)
+	}
+	return message
+}
+
+// RPCError is a JSON RPC error response. Errors with a known cause are returned wrapped in a more specific error,
+// e.g. a `*SignatureError`, from which `errors.As` still extracts the RPCError.
+type RPCError struct {
+	ID      string      // ID is the message ID of the request.
+	Code    int         // Code is the error code reported by the exchange.
+	Message string      // Message is the exchange error message.
+	Data    interface{} // Data is the optional error data.
+}
+
+// Error returns the code and message of the error.
+func (rpcError *RPCError) Error() string {
+	return fmt.Sprintf("RPC error %d: %s", rpcError.Code, rpcError.Message)
+}
+
+// ValidationError reports a request rejected for invalid parameters, e.g. a quantity, price or reused nonce.
+type ValidationError struct {
+	Message string // Message describes the invalid parameters.
+	Err     error  // Err is the APIError or RPCError reporting the failure, nil for failures detected locally.
+}
+
+func (validationError *ValidationError) Error() string {
+	return describe(validationError.Message, validationError.Err)
+}
+
+func (validationError *ValidationError) Unwrap() error {
+	return validationError.Err
+}
+
+// SignatureError reports a request which could not be signed, or whose signature or signer the exchange rejected.
+type SignatureError struct {
+	Message string // Message describes the failure.
+	Err     error  // Err is the signing error, or the APIError or RPCError reporting the rejection.
+}
+
+func (signatureError *SignatureError) Error() string {
+	return describe(signatureError.Message, signatureError.Err)
+}
+
+func (signatureError *SignatureError) Unwrap() error {
+	return signatureError.Err
+}
+
+// RateLimitError reports a request rejected for exceeding a rate limit, by the exchange or a client-side limiter.
+type RateLimitError struct {
+	Message    string        // Message describes the exceeded limit.
+	RetryAfter time.Duration // RetryAfter is how long to wait before retrying, 0 if unknown.
+	Err        error         // Err is the APIError or RPCError reporting the failure, or the client-side limiter error.
+}
+
+func (rateLimitError *RateLimitError) Error() string {
+	return describe(rateLimitError.Message, rateLimitError.Err)
+}
+
+func (rateLimitError *RateLimitError) Unwrap() error {
+	return rateLimitError.Err
+}
+
+// InsufficientBalanceError reports a request rejected because the balance or margin of the account is too low.
+type InsufficientBalanceError struct {
+	Message string // Message describes the failure.
+	Err     error  // Err is the APIError or RPCError reporting the failure.
+}
+
+func (insufficientBalanceError *InsufficientBalanceError) Error() string {
+	return describe(insufficientBalanceError.Message, insufficientBalanceError.Err)
+}
+
+func (insufficientBalanceError *InsufficientBalanceError) Unwrap() error {
+	return insufficientBalanceError.Err
+}
+
+// describe returns the message of an error wrapping another, prefixed to the wrapped error unless already part of it.
+func describe(message string, err error) string {
+	switch {
+	case err == nil:
+		return message
+	case message == "" || strings.Contains(err.Error(), message):
+		return err.Error()
+	}
+	return message + ": " + err.Error()
+}
+
+// NewAPIError creates the error reported by a failed REST response.
+//
+// Parameters:
+//   - res: HTTP response received from the server.
+//   - body: The body of the response, already read.
+//
+// Returns:
+//   - error: An *APIError, wrapped in a specific error when its status or message gives the cause.
+func NewAPIError(res *http.Response, body []byte) error {
+	apiError := &APIError{
+		StatusCode: res.StatusCode,
+		Code:       res.StatusCode,
+		Message:    strings.TrimSpace(string(body)),
+		RequestID:  res.Header.Get(constants.HEADER_REQUEST_ID),
+	}
+	var errorBody struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &errorBody); err == nil && errorBody.Message != "" {
+		apiError.Message = errorBody.Message
+		if errorBody.Code != 0 {
+			apiError.Code = errorBody.Code
+		}
+	}
+	retryAfter, _ := parseRetryAfter(res.Header.Get("Retry-After"))
+	return classifyError(apiError, res.StatusCode, apiError.Message, retryAfter)
+}
+
+// NewRPCError creates the error reported by a JSON RPC error response.
+//
+// Parameters:
+//   - response: The RPC response, its `Error` must be set.
+//
+// Returns:
+//   - error: An *RPCError, wrapped in a specific error when its message gives the cause.
+func NewRPCError(response *types.WebsocketResponse) error {
+	rpcError := &RPCError{
+		ID:      response.ID,
+		Code:    response.Error.Code,
+		Message: response.Error.Message,
+		Data:    response.Error.Data,
+	}
+	return classifyError(rpcError, 0, rpcError.Message, 0)
+}
+
+// classifyError wraps an APIError or RPCError in the specific error matching its HTTP status, 0 for RPC errors, or
+// its message. The exchange does not document its error codes, so they are reported but not classified.
+func classifyError(err error, status int, message string, retryAfter time.Duration) error {
+	lower := strings.ToLower(message)
+	switch {
+	case status == http.StatusTooManyRequests || containsAny(lower, "rate limit", "too many requests"):
+		return &RateLimitError{Message: message, RetryAfter: retryAfter, Err: err}
+	case containsAny(lower, "insufficient"):
+		return &InsufficientBalanceError{Message: message, Err: err}
+	case status == http.StatusUnauthorized || status == http.StatusForbidden || containsAny(lower, "signature", "signer", "unauthorized", "not logged in"):
+		return &SignatureError{Message: message, Err: err}
+	case status == http.StatusBadRequest || status == http.StatusUnprocessableEntity || containsAny(lower, "invalid", "nonce"):
+		return &ValidationError{Message: message, Err: err}
+	}
+	return err
+}
+
+// containsAny reports whether a message contains any of the substrings.
+func containsAny(message string, substrings ...string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(message, substring) {
+			return true
+		}
+	}
+	return false
+}
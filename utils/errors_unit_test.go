@@ -0,0 +1,108 @@
+//go:build !integration
+// +build !integration
+
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ErrorsUnitTestSuite struct {
+	suite.Suite
+}
+
+func TestRunSuiteUnit_ErrorsUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(ErrorsUnitTestSuite))
+}
+
+func (s *ErrorsUnitTestSuite) response(status int, body string) (*http.Response, []byte) {
+	res := &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(bytes.NewBufferString(body))}
+	res.Header.Set(constants.HEADER_REQUEST_ID, "42")
+	return res, []byte(body)
+}
+
+func (s *ErrorsUnitTestSuite) TestUnit_NewAPIError() {
+	// Unknown causes are plain API errors.
+	err := NewAPIError(s.response(http.StatusNotFound, `{"code":404,"message":"product not found"}`))
+	var apiError *APIError
+	require.ErrorAs(s.T(), err, &apiError)
+	require.Equal(s.T(), &APIError{StatusCode: 404, Code: 404, Message: "product not found", RequestID: "42"}, apiError)
+	require.Equal(s.T(), "API error 404: product not found (request 42)", err.Error())
+	var validationError *ValidationError
+	require.False(s.T(), errors.As(err, &validationError))
+
+	// Bodies which are not JSON are kept as the message.
+	err = NewAPIError(s.response(http.StatusInternalServerError, "upstream failure\n"))
+	require.ErrorAs(s.T(), err, &apiError)
+	require.Equal(s.T(), "upstream failure", apiError.Message)
+	require.Equal(s.T(), http.StatusInternalServerError, apiError.Code)
+}
+
+func (s *ErrorsUnitTestSuite) TestUnit_NewAPIError_Classified() {
+	// Causes are classified by status, codes are reported but not interpreted.
+	err := NewAPIError(s.response(http.StatusBadRequest, `{"code":7,"message":"nonce 1 already used"}`))
+	var validationError *ValidationError
+	require.ErrorAs(s.T(), err, &validationError)
+	require.Equal(s.T(), "API error 400 (code 7): nonce 1 already used (request 42)", err.Error())
+	var apiError *APIError
+	require.ErrorAs(s.T(), err, &apiError)
+	require.Equal(s.T(), 7, apiError.Code)
+
+	err = NewAPIError(s.response(http.StatusForbidden, `{"message":"forbidden"}`))
+	var signatureError *SignatureError
+	require.ErrorAs(s.T(), err, &signatureError)
+
+	// Messages refine the status.
+	err = NewAPIError(s.response(http.StatusBadRequest, `{"code":400,"message":"insufficient balance"}`))
+	var insufficientBalanceError *InsufficientBalanceError
+	require.ErrorAs(s.T(), err, &insufficientBalanceError)
+	require.False(s.T(), errors.As(err, &validationError))
+
+	// Rate limited responses report when to retry, whatever their code.
+	res, body := s.response(http.StatusTooManyRequests, `{"code":1,"message":"slow down"}`)
+	res.Header.Set("Retry-After", "3")
+	err = NewAPIError(res, body)
+	var rateLimitError *RateLimitError
+	require.ErrorAs(s.T(), err, &rateLimitError)
+	require.Equal(s.T(), 3*time.Second, rateLimitError.RetryAfter)
+}
+
+func (s *ErrorsUnitTestSuite) TestUnit_NewRPCError() {
+	// RPC errors are classified by their message only.
+	response := &types.WebsocketResponse{ID: "1", Error: &types.WebsocketError{Code: 1, Message: "not logged in"}}
+	err := NewRPCError(response)
+	var signatureError *SignatureError
+	require.ErrorAs(s.T(), err, &signatureError)
+	var rpcError *RPCError
+	require.ErrorAs(s.T(), err, &rpcError)
+	require.Equal(s.T(), "1", rpcError.ID)
+	require.Equal(s.T(), "RPC error 1: not logged in", err.Error())
+
+	response.Error.Message = "invalid quantity"
+	var validationError *ValidationError
+	require.ErrorAs(s.T(), NewRPCError(response), &validationError)
+
+	response.Error.Message = "method not found"
+	require.ErrorAs(s.T(), NewRPCError(response), &rpcError)
+	require.False(s.T(), errors.As(NewRPCError(response), &signatureError))
+	require.False(s.T(), errors.As(NewRPCError(response), &validationError))
+}
+
+func (s *ErrorsUnitTestSuite) TestUnit_Describe() {
+	cause := errors.New("invalid quantity")
+	require.Equal(s.T(), "quantity", describe("quantity", nil))
+	require.Equal(s.T(), "invalid quantity", describe("", cause))
+	require.Equal(s.T(), "invalid quantity", describe("quantity", cause))
+	require.Equal(s.T(), "failed to sign: invalid quantity", describe("failed to sign", cause))
+	require.ErrorIs(s.T(), &ValidationError{Message: "failed", Err: cause}, cause)
+}
@@ -2,7 +2,9 @@ package utils
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"time"
 
@@ -46,6 +48,22 @@ func GetHTTPClient(timeout time.Duration) *http.Client {
 //   - *http.Request: Created HTTP request instance.
 //   - error: Any error encountered during request creation.
 func CreateHTTPRequestWithBody(method string, uri string, body interface{}) (*http.Request, error) {
+	return CreateHTTPRequestWithBodyCtx(context.Background(), method, uri, body)
+}
+
+// CreateHTTPRequestWithBodyCtx creates a new HTTP request with a request body, bound to a context.
+//
+// Parameters:
+//   - ctx: Context of the request, cancelling it once done.
+//   - method: HTTP method (GET, POST, PUT, DELETE, etc.).
+//   - uri: Request URI.
+//   - body: Request body to be included in the HTTP request. It can be a string,
+//     []byte, or any other type that can be marshaled into a valid HTTP request body.
+//
+// Returns:
+//   - *http.Request: Created HTTP request instance.
+//   - error: Any error encountered during request creation.
+func CreateHTTPRequestWithBodyCtx(ctx context.Context, method string, uri string, body interface{}) (*http.Request, error) {
 	// Marshal body into JSON.
 	bodyJSON, err := json.Marshal(body)
 	if err != nil {
@@ -53,7 +71,7 @@ func CreateHTTPRequestWithBody(method string, uri string, body interface{}) (*ht
 	}
 
 	// Create API request.
-	return http.NewRequest(method, uri, bytes.NewBuffer(bodyJSON))
+	return http.NewRequestWithContext(ctx, method, uri, bytes.NewBuffer(bodyJSON))
 }
 
 // SendHTTPRequest sends an HTTP request using a provided `http.Client` and returns the response.
@@ -74,3 +92,24 @@ func SendHTTPRequest(c types.IHTTPClient, req *http.Request) (*http.Response, er
 
 	return res, nil
 }
+
+// DecodeHTTPResponse decodes the JSON body of a successful HTTP response and closes it.
+//
+// Parameters:
+//   - res: HTTP response received from the server.
+//   - result: Pointer to the value receiving the decoded body.
+//
+// Returns:
+//   - error: An error created by `NewAPIError` if the status code is not 2xx, or an error if the body cannot be decoded.
+func DecodeHTTPResponse(res *http.Response, result interface{}) error {
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return NewAPIError(res, body)
+	}
+	return json.Unmarshal(body, result)
+}
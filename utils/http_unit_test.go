@@ -5,6 +5,7 @@ package utils
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -71,6 +72,16 @@ func (s *HttpUnitTestSuite) TestUnit_CreateHTTPRequestWithBody() {
 	require.Equal(s.T(), expectedBody, actualBody)
 }
 
+func (s *HttpUnitTestSuite) TestUnit_CreateHTTPRequestWithBodyCtx() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, err := CreateHTTPRequestWithBodyCtx(ctx, http.MethodDelete, "http://example.com", map[string]interface{}{"key": "value"})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), http.MethodDelete, req.Method)
+	require.Equal(s.T(), ctx, req.Context())
+}
+
 func (s *HttpUnitTestSuite) TestUnit_CreateHTTPRequestWithBody_MarshalError() {
 	unsupportedType := make(chan int)
 	req, err := CreateHTTPRequestWithBody(http.MethodPost, "http://example.com", unsupportedType)
@@ -133,3 +144,40 @@ func (s *HttpUnitTestSuite) TestUnit_SendHTTPRequest_ServerError() {
 	require.Equal(s.T(), http.StatusInternalServerError, res.StatusCode)
 	mockClient.AssertExpectations(s.T())
 }
+
+func (s *HttpUnitTestSuite) TestUnit_DecodeHTTPResponse() {
+	res := &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString(`{"quantity":"100"}`)),
+	}
+
+	var result struct {
+		Quantity string `json:"quantity"`
+	}
+	err := DecodeHTTPResponse(res, &result)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "100", result.Quantity)
+}
+
+func (s *HttpUnitTestSuite) TestUnit_DecodeHTTPResponse_ServerError() {
+	res := &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Body:       io.NopCloser(bytes.NewBufferString(`{"error":"bad request"}`)),
+	}
+
+	var result map[string]interface{}
+	err := DecodeHTTPResponse(res, &result)
+	require.Error(s.T(), err)
+	require.Nil(s.T(), result)
+}
+
+func (s *HttpUnitTestSuite) TestUnit_DecodeHTTPResponse_InvalidJSON() {
+	res := &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString(`not json`)),
+	}
+
+	var result map[string]interface{}
+	err := DecodeHTTPResponse(res, &result)
+	require.Error(s.T(), err)
+}
@@ -1,10 +1,13 @@
 package mocks
 
 import (
+	"bytes"
 	"context"
 	"math/big"
+	"strings"
 
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/mock"
@@ -48,3 +51,53 @@ func (m *MockEthClient) CodeAt(ctx context.Context, account common.Address, bloc
 	args := m.Called(ctx, account, blockNumber)
 	return args.Get(0).([]byte), args.Error(1)
 }
+
+func (m *MockEthClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*big.Int), args.Error(1)
+}
+
+func (m *MockEthClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	args := m.Called(ctx, number)
+	return args.Get(0).(*types.Header), args.Error(1)
+}
+
+func (m *MockEthClient) BlockNumber(ctx context.Context) (uint64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (m *MockEthClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	args := m.Called(ctx, call, blockNumber)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+// OnCallContractMethod mocks `CallContract` for calls to the given ABI method, returning the packed outputs.
+func (m *MockEthClient) OnCallContractMethod(contractABI string, method string, outputs ...interface{}) *mock.Call {
+	parsedABI, err := abi.JSON(strings.NewReader(contractABI))
+	if err != nil {
+		panic(err)
+	}
+	output, err := parsedABI.Methods[method].Outputs.Pack(outputs...)
+	if err != nil {
+		panic(err)
+	}
+	selector := parsedABI.Methods[method].ID
+	return m.On("CallContract", mock.Anything, mock.MatchedBy(func(call ethereum.CallMsg) bool {
+		return bytes.HasPrefix(call.Data, selector)
+	}), mock.Anything).Return(output, nil)
+}
+
+// MockDataError is an RPC error carrying revert data, as returned by `EstimateGas` or `CallContract`.
+type MockDataError struct {
+	Message string
+	Data    interface{}
+}
+
+func (e *MockDataError) Error() string {
+	return e.Message
+}
+
+func (e *MockDataError) ErrorData() interface{} {
+	return e.Data
+}
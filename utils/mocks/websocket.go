@@ -12,3 +12,8 @@ func (m *MockWebSocketConnection) WriteMessage(messageType int, data []byte) err
 	args := m.Called(messageType, data)
 	return args.Error(0)
 }
+
+func (m *MockWebSocketConnection) ReadMessage() (int, []byte, error) {
+	args := m.Called()
+	return args.Int(0), args.Get(1).([]byte), args.Error(2)
+}
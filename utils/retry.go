@@ -0,0 +1,237 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+const (
+	DEFAULT_RETRY_MAX_ATTEMPTS    int           = 3                      // DEFAULT_RETRY_MAX_ATTEMPTS is the default number of attempts per request, including the first.
+	DEFAULT_RETRY_INITIAL_BACKOFF time.Duration = 200 * time.Millisecond // DEFAULT_RETRY_INITIAL_BACKOFF is the default delay before the first retry.
+	DEFAULT_RETRY_MAX_BACKOFF     time.Duration = 5 * time.Second        // DEFAULT_RETRY_MAX_BACKOFF is the default longest delay between attempts.
+	DEFAULT_RETRY_MULTIPLIER      float64       = 2                      // DEFAULT_RETRY_MULTIPLIER is the default growth factor of the delay after each retry.
+	DEFAULT_RETRY_JITTER          float64       = 0.2                    // DEFAULT_RETRY_JITTER is the default fraction of each delay which is randomised.
+)
+
+// ErrOutcomeUnknown reports a signed request rejected on retry after an attempt whose outcome is unknown.
+// The exchange rejects reused nonces, so the earlier attempt may have been executed.
+var ErrOutcomeUnknown = errors.New("outcome of a previous attempt is unknown")
+
+// RetryConfiguration holds the retry policy of a RetryHTTPClient.
+type RetryConfiguration struct {
+	MaxAttempts    int                                                                      // Number of attempts per request, including the first. Defaults to `DEFAULT_RETRY_MAX_ATTEMPTS`.
+	InitialBackoff time.Duration                                                            // Delay before the first retry. Defaults to `DEFAULT_RETRY_INITIAL_BACKOFF`.
+	MaxBackoff     time.Duration                                                            // Longest delay between attempts. Responses asking to wait longer with `Retry-After` are returned. Defaults to `DEFAULT_RETRY_MAX_BACKOFF`.
+	Multiplier     float64                                                                  // Growth factor of the delay after each retry. Defaults to `DEFAULT_RETRY_MULTIPLIER`.
+	Jitter         float64                                                                  // Fraction of each delay which is randomised, between 0 and 1. Defaults to `DEFAULT_RETRY_JITTER`, negative disables jitter.
+	OnRetry        func(request *http.Request, attempt int, err error, delay time.Duration) // Optional callback invoked before each retry with the failed attempt number, its error and the delay.
+}
+
+// RetryHTTPClient is a `types.IHTTPClient` retrying transient failures of another client with exponential backoff and jitter.
+// Transient failures are transport errors, `429 Too Many Requests` and `500`, `502`, `503` and `504` responses.
+//
+// Whether a request is retried depends on what it does:
+//   - Read-only requests (GET, HEAD, OPTIONS) are retried on any transient failure.
+//   - Signed requests, whose JSON body carries a `signature` and a `nonce`, are retried on any transient failure too.
+//     The nonce is their idempotency key: the exchange rejects a reused nonce, so a retry never executes a request twice.
+//     A retry rejected after an attempt with an unknown outcome returns `ErrOutcomeUnknown`.
+//   - Other requests are only retried when they were not processed: on `429` responses and on failures to connect.
+//
+// A *RateLimitError of the wrapped client, as returned by a fail-fast client-side rate limiter, is returned at once:
+// the attempt was never sent.
+type RetryHTTPClient struct {
+	client         types.IHTTPClient
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	multiplier     float64
+	jitter         float64
+	onRetry        func(request *http.Request, attempt int, err error, delay time.Duration)
+}
+
+// NewRetryHTTPClient creates a new RetryHTTPClient instance.
+//
+// Parameters:
+//   - client: The HTTP client sending the attempts, e.g. from `GetHTTPClient`.
+//   - config: A pointer to RetryConfiguration containing the retry policy.
+//
+// Returns:
+//   - *RetryHTTPClient: Client retrying the requests of `client`.
+func NewRetryHTTPClient(client types.IHTTPClient, config *RetryConfiguration) *RetryHTTPClient {
+	retryClient := &RetryHTTPClient{
+		client:         client,
+		maxAttempts:    config.MaxAttempts,
+		initialBackoff: config.InitialBackoff,
+		maxBackoff:     config.MaxBackoff,
+		multiplier:     config.Multiplier,
+		jitter:         config.Jitter,
+		onRetry:        config.OnRetry,
+	}
+	if retryClient.maxAttempts <= 0 {
+		retryClient.maxAttempts = DEFAULT_RETRY_MAX_ATTEMPTS
+	}
+	if retryClient.initialBackoff <= 0 {
+		retryClient.initialBackoff = DEFAULT_RETRY_INITIAL_BACKOFF
+	}
+	if retryClient.maxBackoff <= 0 {
+		retryClient.maxBackoff = DEFAULT_RETRY_MAX_BACKOFF
+	}
+	if retryClient.multiplier < 1 {
+		retryClient.multiplier = DEFAULT_RETRY_MULTIPLIER
+	}
+	if retryClient.jitter == 0 {
+		retryClient.jitter = DEFAULT_RETRY_JITTER
+	}
+	retryClient.jitter = min(max(retryClient.jitter, 0), 1)
+	return retryClient
+}
+
+// Do sends a request, retrying it while its failures are transient and retrying is safe.
+//
+// Parameters:
+//   - req: HTTP request instance to be sent. Requests with a body must set `GetBody` to be retried,
+//     as those created by `http.NewRequest` and `CreateHTTPRequestWithBody` do.
+//
+// Returns:
+//   - *http.Response: HTTP response of the last attempt.
+//   - error: The error of the last attempt, the context error if the request is cancelled while waiting,
+//     a *RateLimitError at once if a client-side rate limiter rejects an attempt, or `ErrOutcomeUnknown`.
+func (client *RetryHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	readOnly := req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == http.MethodOptions
+	nonce, signed := requestNonce(req)
+	rewindable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	backoff := client.initialBackoff
+	outcomeUnknown := false
+	for attempt := 1; ; attempt++ {
+		// Send the attempt with a fresh body.
+		attemptRequest := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %v", err)
+			}
+			attemptRequest = req.Clone(req.Context())
+			attemptRequest.Body = body
+		}
+		res, err := client.client.Do(attemptRequest)
+
+		// Client-side rate limiters failing fast reject the attempt before sending it, the caller decides when to retry.
+		var rateLimitError *RateLimitError
+		if errors.As(err, &rateLimitError) {
+			if outcomeUnknown {
+				return nil, fmt.Errorf("%w: request with nonce %d not retried on attempt %d: %w", ErrOutcomeUnknown, nonce, attempt, err)
+			}
+			return nil, err
+		}
+
+		// Signed requests rejected after an attempt with an unknown outcome may have been executed.
+		if err == nil && outcomeUnknown && res.StatusCode >= 400 && res.StatusCode < 500 && res.StatusCode != http.StatusTooManyRequests {
+			body, _ := io.ReadAll(res.Body)
+			res.Body.Close()
+			return nil, fmt.Errorf("%w: request with nonce %d rejected on attempt %d with status code %d: %s", ErrOutcomeUnknown, nonce, attempt, res.StatusCode, string(body))
+		}
+
+		// Stop on success, permanent failures and unsafe retries.
+		if req.Context().Err() != nil || attempt >= client.maxAttempts || !rewindable {
+			return res, err
+		}
+		var failure error
+		processed := true
+		switch {
+		case err != nil:
+			failure = err
+			var opError *net.OpError
+			processed = !errors.As(err, &opError) || opError.Op != "dial"
+		case res.StatusCode == http.StatusTooManyRequests:
+			failure = fmt.Errorf("unexpected status code %d", res.StatusCode)
+			processed = false
+		case res.StatusCode == http.StatusInternalServerError || res.StatusCode == http.StatusBadGateway ||
+			res.StatusCode == http.StatusServiceUnavailable || res.StatusCode == http.StatusGatewayTimeout:
+			failure = fmt.Errorf("unexpected status code %d", res.StatusCode)
+		default:
+			return res, err
+		}
+		if processed && !readOnly && !signed {
+			return res, err
+		}
+
+		// Wait as the server asks, or back off with jitter.
+		delay := time.Duration(float64(backoff) * (1 - client.jitter*rand.Float64()))
+		if res != nil {
+			if retryAfter, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+				if retryAfter > client.maxBackoff {
+					return res, err
+				}
+				delay = retryAfter
+			}
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+		if processed && signed {
+			outcomeUnknown = true
+		}
+		if client.onRetry != nil {
+			client.onRetry(req, attempt, failure, delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+		backoff = min(time.Duration(float64(backoff)*client.multiplier), client.maxBackoff)
+	}
+}
+
+// requestNonce returns the nonce of a signed request, read from its JSON body.
+func requestNonce(req *http.Request) (int64, bool) {
+	if req.GetBody == nil {
+		return 0, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return 0, false
+	}
+	defer body.Close()
+
+	var signedBody struct {
+		Nonce     *json.Number `json:"nonce"`
+		Signature string       `json:"signature"`
+	}
+	decoder := json.NewDecoder(body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&signedBody); err != nil || signedBody.Nonce == nil || signedBody.Signature == "" {
+		return 0, false
+	}
+	nonce, err := signedBody.Nonce.Int64()
+	if err != nil {
+		return 0, false
+	}
+	return nonce, true
+}
+
+// parseRetryAfter parses a `Retry-After` header holding seconds or an HTTP date.
+func parseRetryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,206 @@
+//go:build !integration
+// +build !integration
+
+package utils
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type RetryUnitTestSuite struct {
+	suite.Suite
+	Server    *httptest.Server
+	mutex     sync.Mutex
+	responses []scriptedResponse
+	bodies    []string
+	retries   []time.Duration
+}
+
+// scriptedResponse is a response served by the suite server.
+type scriptedResponse struct {
+	status     int
+	retryAfter string
+}
+
+func (s *RetryUnitTestSuite) SetupTest() {
+	s.responses = nil
+	s.bodies = nil
+	s.retries = nil
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		s.bodies = append(s.bodies, string(body))
+		response := scriptedResponse{status: http.StatusOK}
+		if len(s.responses) > 0 {
+			response, s.responses = s.responses[0], s.responses[1:]
+		}
+		if response.retryAfter != "" {
+			w.Header().Set("Retry-After", response.retryAfter)
+		}
+		w.WriteHeader(response.status)
+		w.Write([]byte("{}"))
+	}))
+}
+
+func (s *RetryUnitTestSuite) TearDownTest() {
+	s.Server.Close()
+}
+
+func TestRunSuiteUnit_RetryUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(RetryUnitTestSuite))
+}
+
+func (s *RetryUnitTestSuite) client() *RetryHTTPClient {
+	return NewRetryHTTPClient(GetHTTPClient(time.Second), &RetryConfiguration{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     100 * time.Millisecond,
+		Jitter:         -1,
+		OnRetry: func(request *http.Request, attempt int, err error, delay time.Duration) {
+			s.retries = append(s.retries, delay)
+		},
+	})
+}
+
+func (s *RetryUnitTestSuite) script(responses ...scriptedResponse) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.responses = responses
+}
+
+func (s *RetryUnitTestSuite) TestUnit_NewRetryHTTPClient_Defaults() {
+	client := NewRetryHTTPClient(GetHTTPClient(time.Second), &RetryConfiguration{})
+	require.Equal(s.T(), DEFAULT_RETRY_MAX_ATTEMPTS, client.maxAttempts)
+	require.Equal(s.T(), DEFAULT_RETRY_INITIAL_BACKOFF, client.initialBackoff)
+	require.Equal(s.T(), DEFAULT_RETRY_MAX_BACKOFF, client.maxBackoff)
+	require.Equal(s.T(), DEFAULT_RETRY_MULTIPLIER, client.multiplier)
+	require.Equal(s.T(), DEFAULT_RETRY_JITTER, client.jitter)
+}
+
+func (s *RetryUnitTestSuite) TestUnit_Do_ReadOnly() {
+	s.script(scriptedResponse{status: http.StatusServiceUnavailable}, scriptedResponse{status: http.StatusBadGateway})
+	request, err := http.NewRequest(http.MethodGet, s.Server.URL, nil)
+	require.NoError(s.T(), err)
+
+	// Retried with exponential backoff until it succeeds.
+	res, err := SendHTTPRequest(s.client(), request)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), http.StatusOK, res.StatusCode)
+	require.Equal(s.T(), []time.Duration{time.Millisecond, 2 * time.Millisecond}, s.retries)
+
+	// Attempts run out.
+	s.retries = nil
+	s.script(scriptedResponse{status: 500}, scriptedResponse{status: 500}, scriptedResponse{status: 500})
+	res, err = SendHTTPRequest(s.client(), request)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), http.StatusInternalServerError, res.StatusCode)
+	require.Len(s.T(), s.retries, 2)
+
+	// Client errors are not retried.
+	s.retries = nil
+	s.script(scriptedResponse{status: http.StatusBadRequest})
+	res, err = SendHTTPRequest(s.client(), request)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), http.StatusBadRequest, res.StatusCode)
+	require.Empty(s.T(), s.retries)
+}
+
+func (s *RetryUnitTestSuite) TestUnit_Do_RetryAfter() {
+	request, err := http.NewRequest(http.MethodGet, s.Server.URL, nil)
+	require.NoError(s.T(), err)
+
+	// The server delay replaces the backoff.
+	s.script(scriptedResponse{status: http.StatusTooManyRequests, retryAfter: "0"})
+	res, err := SendHTTPRequest(s.client(), request)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), http.StatusOK, res.StatusCode)
+	require.Equal(s.T(), []time.Duration{0}, s.retries)
+
+	// Delays longer than the maximum backoff are not waited for.
+	s.retries = nil
+	s.script(scriptedResponse{status: http.StatusTooManyRequests, retryAfter: "60"})
+	res, err = SendHTTPRequest(s.client(), request)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), http.StatusTooManyRequests, res.StatusCode)
+	require.Empty(s.T(), s.retries)
+
+	delay, ok := parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	require.True(s.T(), ok)
+	require.InDelta(s.T(), float64(time.Hour), float64(delay), float64(2*time.Second))
+	_, ok = parseRetryAfter("soon")
+	require.False(s.T(), ok)
+}
+
+func (s *RetryUnitTestSuite) TestUnit_Do_Signed() {
+	body := map[string]interface{}{"account": "0x1", "nonce": 42, "signature": "0x2"}
+
+	// Signed requests are retried with the same nonce.
+	s.script(scriptedResponse{status: http.StatusBadGateway})
+	request, err := CreateHTTPRequestWithBody(http.MethodPost, s.Server.URL, body)
+	require.NoError(s.T(), err)
+	res, err := SendHTTPRequest(s.client(), request)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), http.StatusOK, res.StatusCode)
+	require.Len(s.T(), s.bodies, 2)
+	require.Equal(s.T(), s.bodies[0], s.bodies[1])
+
+	// A rejection after an unknown outcome is reported as such.
+	s.script(scriptedResponse{status: http.StatusGatewayTimeout}, scriptedResponse{status: http.StatusBadRequest})
+	request, err = CreateHTTPRequestWithBody(http.MethodPost, s.Server.URL, body)
+	require.NoError(s.T(), err)
+	_, err = SendHTTPRequest(s.client(), request)
+	require.ErrorIs(s.T(), err, ErrOutcomeUnknown)
+	require.Contains(s.T(), err.Error(), "nonce 42")
+}
+
+func (s *RetryUnitTestSuite) TestUnit_Do_Unsigned() {
+	// Unsigned mutating requests are not retried once processed.
+	s.script(scriptedResponse{status: http.StatusBadGateway})
+	request, err := CreateHTTPRequestWithBody(http.MethodPost, s.Server.URL, map[string]interface{}{"account": "0x1"})
+	require.NoError(s.T(), err)
+	res, err := SendHTTPRequest(s.client(), request)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), http.StatusBadGateway, res.StatusCode)
+	require.Empty(s.T(), s.retries)
+
+	// They are retried when rate limited.
+	s.script(scriptedResponse{status: http.StatusTooManyRequests})
+	request, err = CreateHTTPRequestWithBody(http.MethodPost, s.Server.URL, map[string]interface{}{"account": "0x1"})
+	require.NoError(s.T(), err)
+	res, err = SendHTTPRequest(s.client(), request)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), http.StatusOK, res.StatusCode)
+	require.Len(s.T(), s.retries, 1)
+
+	// And when the connection could not be established.
+	s.retries = nil
+	s.Server.Close()
+	request, err = CreateHTTPRequestWithBody(http.MethodPost, s.Server.URL, map[string]interface{}{"account": "0x1"})
+	require.NoError(s.T(), err)
+	_, err = SendHTTPRequest(s.client(), request)
+	require.Error(s.T(), err)
+	require.Len(s.T(), s.retries, 2)
+}
+
+func (s *RetryUnitTestSuite) TestUnit_Do_ContextDone() {
+	s.script(scriptedResponse{status: http.StatusServiceUnavailable})
+	client := NewRetryHTTPClient(GetHTTPClient(time.Second), &RetryConfiguration{InitialBackoff: time.Hour, MaxBackoff: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, s.Server.URL, nil)
+	require.NoError(s.T(), err)
+
+	// Waiting for the retry is cancelled.
+	_, err = SendHTTPRequest(client, request)
+	require.ErrorIs(s.T(), err, context.DeadlineExceeded)
+}
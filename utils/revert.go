@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	geth_types "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+// Sentinel errors matching the custom errors declared by the CIAO contract, to be checked with `errors.Is`.
+var (
+	ErrBalanceInsufficient          = errors.New("balance insufficient")
+	ErrDepositQuantityInvalid       = errors.New("deposit quantity invalid")
+	ErrInvalidInitialization        = errors.New("invalid initialization")
+	ErrNotInitializing              = errors.New("not initializing")
+	ErrProductInvalid               = errors.New("product invalid")
+	ErrReentrancyGuardReentrantCall = errors.New("reentrancy guard reentrant call")
+	ErrSenderInvalid                = errors.New("sender invalid")
+	ErrWithdrawQuantityInvalid      = errors.New("withdraw quantity invalid")
+)
+
+// ciaoErrors maps the CIAO custom error names to their sentinel errors.
+var ciaoErrors = map[string]error{
+	"BalanceInsufficient":          ErrBalanceInsufficient,
+	"DepositQuantityInvalid":       ErrDepositQuantityInvalid,
+	"InvalidInitialization":        ErrInvalidInitialization,
+	"NotInitializing":              ErrNotInitializing,
+	"ProductInvalid":               ErrProductInvalid,
+	"ReentrancyGuardReentrantCall": ErrReentrancyGuardReentrantCall,
+	"SenderInvalid":                ErrSenderInvalid,
+	"WithdrawQuantityInvalid":      ErrWithdrawQuantityInvalid,
+}
+
+// ciaoABI is the parsed CIAO ABI used to decode revert data.
+var ciaoABI = func() abi.ABI {
+	parsedABI, err := abi.JSON(strings.NewReader(constants.CIAO_ABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsedABI
+}()
+
+// RevertError is a decoded contract revert.
+type RevertError struct {
+	Name   string // Name is the custom error name, or `Error` for a `require` reason string.
+	Reason string // Reason is the `require` reason string, empty for custom errors.
+	Data   []byte // Data is the raw revert data.
+	Err    error  // Err is the matching sentinel error, nil for reason strings.
+	Cause  error  // Cause is the original RPC error, nil when decoded from a replayed call.
+}
+
+// Error returns the decoded revert description.
+func (revertError *RevertError) Error() string {
+	if revertError.Reason != "" {
+		return fmt.Sprintf("execution reverted: %s", revertError.Reason)
+	}
+	return fmt.Sprintf("execution reverted: %s", revertError.Name)
+}
+
+// Unwrap returns the sentinel error and the original RPC error.
+func (revertError *RevertError) Unwrap() []error {
+	var errs []error
+	if revertError.Err != nil {
+		errs = append(errs, revertError.Err)
+	}
+	if revertError.Cause != nil {
+		errs = append(errs, revertError.Cause)
+	}
+	return errs
+}
+
+// DecodeRevertError decodes the revert data carried by an RPC error against the CIAO ABI.
+//
+// Parameters:
+//   - err: The error returned by `EstimateGas`, `CallContract` or `SendTransaction`.
+//
+// Returns:
+//   - error: A *RevertError wrapping `err` when its revert data is recognised, `err` unchanged otherwise.
+func DecodeRevertError(err error) error {
+	var dataError rpc.DataError
+	if !errors.As(err, &dataError) {
+		return err
+	}
+	data, ok := revertData(dataError.ErrorData())
+	if !ok {
+		return err
+	}
+	revertError := DecodeRevertData(data)
+	if revertError == nil {
+		return err
+	}
+	revertError.Cause = err
+	return revertError
+}
+
+// DecodeRevertData decodes raw revert data against the CIAO custom errors and the standard `Error(string)`.
+//
+// Parameters:
+//   - data: The raw revert data.
+//
+// Returns:
+//   - *RevertError: The decoded revert, nil if the data is not recognised.
+func DecodeRevertData(data []byte) *RevertError {
+	if len(data) < 4 {
+		return nil
+	}
+
+	// Match CIAO custom errors by selector.
+	for name, abiError := range ciaoABI.Errors {
+		if bytes.Equal(data[:4], abiError.ID[:4]) {
+			return &RevertError{Name: name, Data: data, Err: ciaoErrors[name]}
+		}
+	}
+
+	// Fall back to `require` reason strings.
+	reason, err := abi.UnpackRevert(data)
+	if err != nil {
+		return nil
+	}
+	return &RevertError{Name: "Error", Reason: reason, Data: data}
+}
+
+// GetRevertError replays a reverted transaction at its block to recover the revert reason.
+//
+// Parameters:
+//   - ctx: The context for the Ethereum client operations.
+//   - ethClient: Interface for interacting with the Ethereum blockchain.
+//   - from: The sender's Ethereum address.
+//   - transaction: The reverted transaction.
+//   - receipt: The receipt of the reverted transaction.
+//
+// Returns:
+//   - error: A *RevertError when the revert is recognised, a generic revert error otherwise.
+func GetRevertError(ctx context.Context, ethClient types.IEthClient, from common.Address, transaction *geth_types.Transaction, receipt *geth_types.Receipt) error {
+	reverted := fmt.Errorf("transaction %s reverted", transaction.Hash().Hex())
+
+	// Replay the call at the block the transaction was mined in.
+	var blockNumber *big.Int
+	if receipt != nil {
+		blockNumber = receipt.BlockNumber
+	}
+	_, err := ethClient.CallContract(ctx, ethereum.CallMsg{
+		From:  from,
+		To:    transaction.To(),
+		Gas:   transaction.Gas(),
+		Value: transaction.Value(),
+		Data:  transaction.Data(),
+	}, blockNumber)
+	if err == nil {
+		return reverted
+	}
+
+	var revertError *RevertError
+	if errors.As(DecodeRevertError(err), &revertError) {
+		return fmt.Errorf("transaction %s reverted: %w", transaction.Hash().Hex(), revertError)
+	}
+	return reverted
+}
+
+// revertData extracts revert bytes from RPC error data, usually a hex string.
+func revertData(errorData interface{}) ([]byte, bool) {
+	switch value := errorData.(type) {
+	case []byte:
+		return value, true
+	case string:
+		data, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
+		if err != nil {
+			return nil, false
+		}
+		return data, true
+	}
+	return nil, false
+}
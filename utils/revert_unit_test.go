@@ -0,0 +1,113 @@
+//go:build !integration
+// +build !integration
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	geth_types "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rysk-finance/v2_client_go/utils/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type RevertUnitTestSuite struct {
+	suite.Suite
+}
+
+func TestRunSuiteUnit_RevertUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(RevertUnitTestSuite))
+}
+
+func revertSelector(signature string) string {
+	return hexutil.Encode(crypto.Keccak256([]byte(signature))[:4])
+}
+
+func (s *RevertUnitTestSuite) TestUnit_DecodeRevertError_CustomErrors() {
+	for signature, sentinel := range map[string]error{
+		"BalanceInsufficient()":     ErrBalanceInsufficient,
+		"DepositQuantityInvalid()":  ErrDepositQuantityInvalid,
+		"ProductInvalid()":          ErrProductInvalid,
+		"SenderInvalid()":           ErrSenderInvalid,
+		"WithdrawQuantityInvalid()": ErrWithdrawQuantityInvalid,
+	} {
+		cause := &mocks.MockDataError{Message: "execution reverted", Data: revertSelector(signature)}
+		err := DecodeRevertError(cause)
+		require.ErrorIs(s.T(), err, sentinel, signature)
+		require.ErrorIs(s.T(), err, cause, signature)
+
+		var revertError *RevertError
+		require.True(s.T(), errors.As(err, &revertError))
+		require.Equal(s.T(), signature[:len(signature)-2], revertError.Name)
+	}
+}
+
+func (s *RevertUnitTestSuite) TestUnit_DecodeRevertError_Wrapped() {
+	err := DecodeRevertError(fmt.Errorf("failed: %w", &mocks.MockDataError{Data: revertSelector("BalanceInsufficient()")}))
+	require.ErrorIs(s.T(), err, ErrBalanceInsufficient)
+	require.NotErrorIs(s.T(), err, ErrProductInvalid)
+}
+
+func (s *RevertUnitTestSuite) TestUnit_DecodeRevertError_ReasonString() {
+	// Error(string) with reason "ERC20: insufficient allowance".
+	data := "0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"000000000000000000000000000000000000000000000000000000000000001d" +
+		"45524332303a20696e73756666696369656e7420616c6c6f77616e6365000000"
+	err := DecodeRevertError(&mocks.MockDataError{Data: data})
+
+	var revertError *RevertError
+	require.True(s.T(), errors.As(err, &revertError))
+	require.Equal(s.T(), "ERC20: insufficient allowance", revertError.Reason)
+	require.Nil(s.T(), revertError.Err)
+}
+
+func (s *RevertUnitTestSuite) TestUnit_DecodeRevertError_Unknown() {
+	for _, cause := range []error{
+		fmt.Errorf("connection refused"),
+		&mocks.MockDataError{Data: "0xdeadbeef"},
+		&mocks.MockDataError{Data: "not hex"},
+		&mocks.MockDataError{Data: 42},
+	} {
+		require.Equal(s.T(), cause, DecodeRevertError(cause))
+	}
+	require.Nil(s.T(), DecodeRevertError(nil))
+}
+
+func (s *RevertUnitTestSuite) TestUnit_EstimateGasLimit_Revert() {
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(0), &mocks.MockDataError{Data: revertSelector("DepositQuantityInvalid()")})
+
+	_, err := EstimateGasLimit(context.Background(), mockEthClient, nil, ethereum.CallMsg{})
+	require.ErrorIs(s.T(), err, ErrDepositQuantityInvalid)
+}
+
+func (s *RevertUnitTestSuite) TestUnit_GetRevertError() {
+	transaction := geth_types.NewTx(&geth_types.DynamicFeeTx{To: &common.MaxAddress, Value: big.NewInt(0)})
+	receipt := &geth_types.Receipt{BlockNumber: big.NewInt(10)}
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.On("CallContract", mock.Anything, mock.Anything, big.NewInt(10)).Return([]byte(nil), &mocks.MockDataError{Data: revertSelector("SenderInvalid()")})
+
+	err := GetRevertError(context.Background(), mockEthClient, common.MaxAddress, transaction, receipt)
+	require.ErrorIs(s.T(), err, ErrSenderInvalid)
+}
+
+func (s *RevertUnitTestSuite) TestUnit_GetRevertError_NotReproduced() {
+	transaction := geth_types.NewTx(&geth_types.DynamicFeeTx{To: &common.MaxAddress, Value: big.NewInt(0)})
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.On("CallContract", mock.Anything, mock.Anything, mock.Anything).Return([]byte(nil), nil)
+
+	err := GetRevertError(context.Background(), mockEthClient, common.MaxAddress, transaction, &geth_types.Receipt{})
+	require.Error(s.T(), err)
+	require.NotErrorIs(s.T(), err, ErrSenderInvalid)
+}
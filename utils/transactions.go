@@ -0,0 +1,204 @@
+package utils
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	geth_types "github.com/ethereum/go-ethereum/core/types"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+)
+
+// GetDynamicFeeTransactionParams retrieves the parameters required for sending an EIP-1559 transaction.
+//
+// This function queries the Ethereum network using the provided Ethereum client (`ethClient`)
+// to fetch the following transaction parameters:
+// - Nonce: The transaction count of the sender's address.
+// - GasTipCap: The priority fee per gas, according to the gas configuration.
+// - GasFeeCap: The max fee per gas, according to the gas configuration.
+// - ChainID: The ID of the Ethereum chain the transaction will be sent on.
+// - GasLimit: The estimated gas usage increased by the configured buffer.
+//
+// Parameters:
+//   - ctx: The context for the Ethereum client operations.
+//   - ethClient: Interface for interacting with the Ethereum blockchain.
+//   - gasConfiguration: The gas settings to use, nil for `constants.GAS_STRATEGY_SUGGESTED` without buffer.
+//   - from: The sender's Ethereum address.
+//   - to: The recipient's Ethereum address.
+//   - data: The data payload for the transaction.
+//
+// Returns:
+//   - nonce: The current nonce (transaction count) of the sender's address.
+//   - gasTipCap: The priority fee per gas in Wei.
+//   - gasFeeCap: The max fee per gas in Wei.
+//   - chainID: The ID of the Ethereum chain.
+//   - gasLimit: The maximum gas limit for the transaction.
+//   - err: Any error encountered during the retrieval of parameters.
+func GetDynamicFeeTransactionParams(
+	ctx context.Context,
+	ethClient types.IEthClient,
+	gasConfiguration *types.GasConfiguration,
+	from *common.Address,
+	to *common.Address,
+	data *[]byte,
+) (nonce uint64, gasTipCap *big.Int, gasFeeCap *big.Int, chainID *big.Int, gasLimit uint64, err error) {
+	// Get next nonce
+	nonce, err = ethClient.PendingNonceAt(ctx, *from)
+	if err != nil {
+		return
+	}
+	// Get gas fees
+	gasTipCap, gasFeeCap, err = GetGasFees(ctx, ethClient, gasConfiguration)
+	if err != nil {
+		return
+	}
+	// Get Chain ID
+	chainID, err = ethClient.NetworkID(ctx)
+	if err != nil {
+		return
+	}
+	// Compute gas limit
+	gasLimit, err = EstimateGasLimit(ctx, ethClient, gasConfiguration, ethereum.CallMsg{
+		From: *from,
+		To:   to,
+		Data: *data,
+	})
+	return
+}
+
+// GetGasFees returns the priority fee and the max fee per gas according to the gas configuration.
+//
+// Suggested fees are derived from `SuggestGasTipCap` and the base fee of the latest block header,
+// the max fee being `constants.BASE_FEE_MULTIPLIER` times the base fee plus the priority fee.
+//
+// Parameters:
+//   - ctx: The context for the Ethereum client operations.
+//   - ethClient: Interface for interacting with the Ethereum blockchain.
+//   - gasConfiguration: The gas settings to use, nil for `constants.GAS_STRATEGY_SUGGESTED`.
+//
+// Returns:
+//   - *big.Int: The priority fee per gas in Wei.
+//   - *big.Int: The max fee per gas in Wei.
+//   - error: An error if the fees cannot be computed.
+func GetGasFees(ctx context.Context, ethClient types.IEthClient, gasConfiguration *types.GasConfiguration) (*big.Int, *big.Int, error) {
+	if gasConfiguration == nil {
+		gasConfiguration = &types.GasConfiguration{Strategy: constants.GAS_STRATEGY_SUGGESTED}
+	}
+
+	// Fixed fees do not need the network.
+	if gasConfiguration.Strategy == constants.GAS_STRATEGY_FIXED {
+		if gasConfiguration.GasTipCap == nil || gasConfiguration.GasFeeCap == nil {
+			return nil, nil, fmt.Errorf("fixed gas strategy requires both GasTipCap and GasFeeCap")
+		}
+		if gasConfiguration.GasFeeCap.Cmp(gasConfiguration.GasTipCap) < 0 {
+			return nil, nil, fmt.Errorf("GasFeeCap %s is lower than GasTipCap %s", gasConfiguration.GasFeeCap, gasConfiguration.GasTipCap)
+		}
+		return capGasFees(new(big.Int).Set(gasConfiguration.GasTipCap), new(big.Int).Set(gasConfiguration.GasFeeCap), gasConfiguration.MaxGasFeeCap)
+	}
+
+	// Get suggested priority fee
+	gasTipCap, err := ethClient.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Get base fee from latest header
+	header, err := ethClient.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if header.BaseFee == nil {
+		return nil, nil, fmt.Errorf("chain does not support EIP-1559 transactions")
+	}
+	gasFeeCap := new(big.Int).Add(new(big.Int).Mul(header.BaseFee, big.NewInt(constants.BASE_FEE_MULTIPLIER)), gasTipCap)
+
+	switch gasConfiguration.Strategy {
+	case "", constants.GAS_STRATEGY_SUGGESTED:
+		return capGasFees(gasTipCap, gasFeeCap, gasConfiguration.MaxGasFeeCap)
+	case constants.GAS_STRATEGY_MULTIPLIER:
+		if gasConfiguration.Multiplier <= 0 {
+			return nil, nil, fmt.Errorf("multiplier gas strategy requires a positive Multiplier")
+		}
+		return capGasFees(
+			MulBigIntFloat(gasTipCap, gasConfiguration.Multiplier),
+			MulBigIntFloat(gasFeeCap, gasConfiguration.Multiplier),
+			gasConfiguration.MaxGasFeeCap,
+		)
+	case constants.GAS_STRATEGY_CAP:
+		if gasConfiguration.MaxGasFeeCap == nil {
+			return nil, nil, fmt.Errorf("cap gas strategy requires MaxGasFeeCap")
+		}
+		return capGasFees(gasTipCap, gasFeeCap, gasConfiguration.MaxGasFeeCap)
+	default:
+		return nil, nil, fmt.Errorf("unknown gas strategy: %s", gasConfiguration.Strategy)
+	}
+}
+
+// EstimateGasLimit estimates the gas usage of a call and adds the configured buffer on top of it.
+//
+// Parameters:
+//   - ctx: The context for the Ethereum client operations.
+//   - ethClient: Interface for interacting with the Ethereum blockchain.
+//   - gasConfiguration: The gas settings to use, nil for no buffer.
+//   - call: The call to estimate.
+//
+// Returns:
+//   - uint64: The gas limit to use for the transaction.
+//   - error: An error if the estimation fails, a *RevertError if the call reverts with known revert data.
+func EstimateGasLimit(ctx context.Context, ethClient types.IEthClient, gasConfiguration *types.GasConfiguration, call ethereum.CallMsg) (uint64, error) {
+	gasLimit, err := ethClient.EstimateGas(ctx, call)
+	if err != nil {
+		return 0, DecodeRevertError(err)
+	}
+	if gasConfiguration != nil && gasConfiguration.GasLimitBuffer > 0 {
+		gasLimit += gasLimit * gasConfiguration.GasLimitBuffer / 100
+	}
+	return gasLimit, nil
+}
+
+// SignDynamicFeeTransaction creates and signs an EIP-1559 transaction.
+//
+// Parameters:
+//   - privateKey: The private key used to sign the transaction.
+//   - chainID: The ID of the Ethereum chain the transaction will be sent on.
+//   - transaction: The dynamic fee transaction data, `ChainID` is set from `chainID`.
+//
+// Returns:
+//   - *geth_types.Transaction: The signed transaction.
+//   - error: An error if signing fails.
+func SignDynamicFeeTransaction(privateKey *ecdsa.PrivateKey, chainID *big.Int, transaction *geth_types.DynamicFeeTx) (*geth_types.Transaction, error) {
+	transaction.ChainID = chainID
+	signedTx, err := geth_types.SignNewTx(privateKey, geth_types.LatestSignerForChainID(chainID), transaction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %v", err)
+	}
+	return signedTx, nil
+}
+
+// MulBigIntFloat multiplies a big integer by a float factor, rounding down.
+//
+// Parameters:
+//   - value: The big integer to multiply.
+//   - factor: The multiplication factor.
+//
+// Returns:
+//   - *big.Int: The product as a new big integer.
+func MulBigIntFloat(value *big.Int, factor float64) *big.Int {
+	product, _ := new(big.Float).Mul(new(big.Float).SetInt(value), big.NewFloat(factor)).Int(nil)
+	return product
+}
+
+// capGasFees bounds the max fee per gas, lowering the priority fee accordingly.
+func capGasFees(gasTipCap *big.Int, gasFeeCap *big.Int, maxGasFeeCap *big.Int) (*big.Int, *big.Int, error) {
+	if maxGasFeeCap != nil && gasFeeCap.Cmp(maxGasFeeCap) > 0 {
+		gasFeeCap = new(big.Int).Set(maxGasFeeCap)
+	}
+	if gasTipCap.Cmp(gasFeeCap) > 0 {
+		gasTipCap = new(big.Int).Set(gasFeeCap)
+	}
+	return gasTipCap, gasFeeCap, nil
+}
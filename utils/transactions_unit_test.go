@@ -0,0 +1,219 @@
+//go:build !integration
+// +build !integration
+
+package utils
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	geth_types "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TransactionsUnitTestSuite struct {
+	suite.Suite
+	privateKey *ecdsa.PrivateKey
+}
+
+func (s *TransactionsUnitTestSuite) SetupSuite() {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		panic(err)
+	}
+	s.privateKey = privateKey
+}
+
+func TestRunSuiteUnit_TransactionsUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(TransactionsUnitTestSuite))
+}
+
+func newFeeMockEthClient(tipCap int64, baseFee int64) *mocks.MockEthClient {
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(tipCap), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(baseFee)}, nil)
+	return mockEthClient
+}
+
+func (s *TransactionsUnitTestSuite) TestUnit_GetGasFees_Suggested() {
+	gasTipCap, gasFeeCap, err := GetGasFees(context.Background(), newFeeMockEthClient(100, 1000), nil)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), big.NewInt(100), gasTipCap)
+	require.Equal(s.T(), big.NewInt(2100), gasFeeCap)
+}
+
+func (s *TransactionsUnitTestSuite) TestUnit_GetGasFees_Fixed() {
+	mockEthClient := new(mocks.MockEthClient)
+	gasTipCap, gasFeeCap, err := GetGasFees(context.Background(), mockEthClient, &types.GasConfiguration{
+		Strategy:  constants.GAS_STRATEGY_FIXED,
+		GasTipCap: big.NewInt(5),
+		GasFeeCap: big.NewInt(50),
+	})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), big.NewInt(5), gasTipCap)
+	require.Equal(s.T(), big.NewInt(50), gasFeeCap)
+	mockEthClient.AssertNotCalled(s.T(), "SuggestGasTipCap", mock.Anything)
+}
+
+func (s *TransactionsUnitTestSuite) TestUnit_GetGasFees_FixedMissingValues() {
+	_, _, err := GetGasFees(context.Background(), new(mocks.MockEthClient), &types.GasConfiguration{
+		Strategy:  constants.GAS_STRATEGY_FIXED,
+		GasTipCap: big.NewInt(5),
+	})
+	require.Error(s.T(), err)
+}
+
+func (s *TransactionsUnitTestSuite) TestUnit_GetGasFees_FixedTipAboveFee() {
+	_, _, err := GetGasFees(context.Background(), new(mocks.MockEthClient), &types.GasConfiguration{
+		Strategy:  constants.GAS_STRATEGY_FIXED,
+		GasTipCap: big.NewInt(50),
+		GasFeeCap: big.NewInt(5),
+	})
+	require.Error(s.T(), err)
+}
+
+func (s *TransactionsUnitTestSuite) TestUnit_GetGasFees_Multiplier() {
+	gasTipCap, gasFeeCap, err := GetGasFees(context.Background(), newFeeMockEthClient(100, 1000), &types.GasConfiguration{
+		Strategy:   constants.GAS_STRATEGY_MULTIPLIER,
+		Multiplier: 1.5,
+	})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), big.NewInt(150), gasTipCap)
+	require.Equal(s.T(), big.NewInt(3150), gasFeeCap)
+}
+
+func (s *TransactionsUnitTestSuite) TestUnit_GetGasFees_MultiplierInvalid() {
+	_, _, err := GetGasFees(context.Background(), newFeeMockEthClient(100, 1000), &types.GasConfiguration{
+		Strategy: constants.GAS_STRATEGY_MULTIPLIER,
+	})
+	require.Error(s.T(), err)
+}
+
+func (s *TransactionsUnitTestSuite) TestUnit_GetGasFees_Cap() {
+	gasTipCap, gasFeeCap, err := GetGasFees(context.Background(), newFeeMockEthClient(100, 1000), &types.GasConfiguration{
+		Strategy:     constants.GAS_STRATEGY_CAP,
+		MaxGasFeeCap: big.NewInt(80),
+	})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), big.NewInt(80), gasTipCap)
+	require.Equal(s.T(), big.NewInt(80), gasFeeCap)
+}
+
+func (s *TransactionsUnitTestSuite) TestUnit_GetGasFees_CapMissingValue() {
+	_, _, err := GetGasFees(context.Background(), newFeeMockEthClient(100, 1000), &types.GasConfiguration{
+		Strategy: constants.GAS_STRATEGY_CAP,
+	})
+	require.Error(s.T(), err)
+}
+
+func (s *TransactionsUnitTestSuite) TestUnit_GetGasFees_UnknownStrategy() {
+	_, _, err := GetGasFees(context.Background(), newFeeMockEthClient(100, 1000), &types.GasConfiguration{
+		Strategy: "unknown",
+	})
+	require.Error(s.T(), err)
+}
+
+func (s *TransactionsUnitTestSuite) TestUnit_GetGasFees_NoBaseFee() {
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(100), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{}, nil)
+
+	_, _, err := GetGasFees(context.Background(), mockEthClient, nil)
+	require.Error(s.T(), err)
+}
+
+func (s *TransactionsUnitTestSuite) TestUnit_GetGasFees_ErrorSuggestGasTipCap() {
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return((*big.Int)(nil), fmt.Errorf("failed to get gas tip cap"))
+
+	_, _, err := GetGasFees(context.Background(), mockEthClient, nil)
+	require.Error(s.T(), err)
+}
+
+func (s *TransactionsUnitTestSuite) TestUnit_GetGasFees_ErrorHeaderByNumber() {
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(100), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return((*geth_types.Header)(nil), fmt.Errorf("failed to get header"))
+
+	_, _, err := GetGasFees(context.Background(), mockEthClient, nil)
+	require.Error(s.T(), err)
+}
+
+func (s *TransactionsUnitTestSuite) TestUnit_EstimateGasLimit_Buffer() {
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(100000), nil)
+
+	gasLimit, err := EstimateGasLimit(context.Background(), mockEthClient, &types.GasConfiguration{GasLimitBuffer: 25}, ethereum.CallMsg{})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), uint64(125000), gasLimit)
+}
+
+func (s *TransactionsUnitTestSuite) TestUnit_GetDynamicFeeTransactionParams() {
+	mockEthClient := newFeeMockEthClient(100, 1000)
+	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(7), nil)
+	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(42161), nil)
+	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
+
+	nonce, gasTipCap, gasFeeCap, chainID, gasLimit, err := GetDynamicFeeTransactionParams(
+		context.Background(),
+		mockEthClient,
+		nil,
+		&common.MaxAddress,
+		&common.MaxAddress,
+		new([]byte),
+	)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), uint64(7), nonce)
+	require.Equal(s.T(), big.NewInt(100), gasTipCap)
+	require.Equal(s.T(), big.NewInt(2100), gasFeeCap)
+	require.Equal(s.T(), big.NewInt(42161), chainID)
+	require.Equal(s.T(), uint64(21000), gasLimit)
+}
+
+func (s *TransactionsUnitTestSuite) TestUnit_GetDynamicFeeTransactionParams_ErrorEstimateGas() {
+	mockEthClient := newFeeMockEthClient(100, 1000)
+	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(7), nil)
+	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(42161), nil)
+	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(0), fmt.Errorf("failed to estimate gas"))
+
+	_, _, _, _, gasLimit, err := GetDynamicFeeTransactionParams(
+		context.Background(),
+		mockEthClient,
+		nil,
+		&common.MaxAddress,
+		&common.MaxAddress,
+		new([]byte),
+	)
+	require.Error(s.T(), err)
+	require.Empty(s.T(), gasLimit)
+}
+
+func (s *TransactionsUnitTestSuite) TestUnit_SignDynamicFeeTransaction() {
+	chainID := big.NewInt(42161)
+	signedTx, err := SignDynamicFeeTransaction(s.privateKey, chainID, &geth_types.DynamicFeeTx{
+		Nonce:     1,
+		GasTipCap: big.NewInt(100),
+		GasFeeCap: big.NewInt(2100),
+		Gas:       21000,
+		To:        &common.MaxAddress,
+		Value:     big.NewInt(0),
+	})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), uint8(geth_types.DynamicFeeTxType), signedTx.Type())
+	require.Equal(s.T(), chainID, signedTx.ChainId())
+
+	sender, err := geth_types.Sender(geth_types.LatestSignerForChainID(chainID), signedTx)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), crypto.PubkeyToAddress(s.privateKey.PublicKey), sender)
+}
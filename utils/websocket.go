@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/rysk-finance/v2_client_go/types"
@@ -25,3 +27,103 @@ func SendRPCRequest(connection types.IWSConnection, request interface{}) error {
 	// Send RPC request.
 	return connection.WriteMessage(websocket.TextMessage, body)
 }
+
+// SendRPCRequestCtx sends a RPC request via a WebSocket connection like `SendRPCRequest`, unless the context is done.
+// If the connection supports write deadlines, as `*websocket.Conn` does, the write is bounded by the context deadline
+// and aborted once the context is done. An aborted write leaves the connection unusable for further writes.
+//
+// Parameters:
+//   - ctx: Context of the request.
+//   - connection: WebSocket connection implementing `types.IWSConnection` interface.
+//   - request: JSON-RPC request payload to be sent over the WebSocket connection.
+//
+// Returns:
+//   - error: The context error if it is done before or during the write, or any other error sending the RPC request.
+func SendRPCRequestCtx(ctx context.Context, connection types.IWSConnection, request interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Marshal request into JSON.
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	// Write without deadline when the connection does not support any or the context can't be done.
+	deadliner, ok := connection.(interface{ SetWriteDeadline(t time.Time) error })
+	if !ok || ctx.Done() == nil {
+		return connection.WriteMessage(websocket.TextMessage, body)
+	}
+
+	// Bound the write by the context deadline, and expire it once the context is done.
+	deadline, _ := ctx.Deadline()
+	if err := deadliner.SetWriteDeadline(deadline); err != nil {
+		return err
+	}
+	written := make(chan struct{})
+	watched := make(chan struct{})
+	go func() {
+		defer close(watched)
+		select {
+		case <-ctx.Done():
+			deadliner.SetWriteDeadline(time.Now())
+		case <-written:
+		}
+	}()
+	err = connection.WriteMessage(websocket.TextMessage, body)
+	close(written)
+	<-watched
+	deadliner.SetWriteDeadline(time.Time{})
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// ReadRPCResponse reads RPC messages from a WebSocket connection until the response to `messageId` is received.
+// Messages answering other requests are discarded.
+//
+// Parameters:
+//   - connection: WebSocket connection implementing `types.IWSReader` interface.
+//   - messageId: The unique identifier of the request to wait for.
+//
+// Returns:
+//   - *types.WebsocketResponse: The response to the request.
+//   - error: Returns an error if reading fails, or an error created by `NewRPCError` if the response reports an error.
+func ReadRPCResponse(connection types.IWSReader, messageId string) (*types.WebsocketResponse, error) {
+	for {
+		// Read next message.
+		_, body, err := connection.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+
+		// Skip messages answering other requests.
+		var response types.WebsocketResponse
+		if err := json.Unmarshal(body, &response); err != nil || response.ID != messageId {
+			continue
+		}
+
+		if response.Error != nil {
+			return &response, NewRPCError(&response)
+		}
+		return &response, nil
+	}
+}
+
+// DecodeRPCResult converts the generic result of an RPC response into the given type.
+//
+// Parameters:
+//   - response: The RPC response.
+//   - result: Pointer to the value receiving the result.
+//
+// Returns:
+//   - error: Returns an error if the result cannot be converted.
+func DecodeRPCResult(response *types.WebsocketResponse, result interface{}) error {
+	body, err := json.Marshal(response.Result)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, result)
+}
@@ -4,9 +4,12 @@
 package utils
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/rysk-finance/v2_client_go/utils/mocks"
@@ -61,3 +64,97 @@ func (s *WebSocketUnitTestSuite) TestUnit_SendRPCRequest_WriteError() {
 	require.Error(s.T(), err)
 	mockConnection.AssertExpectations(s.T())
 }
+
+// blockingConnection is a connection whose writes block until their deadline expires.
+type blockingConnection struct {
+	mutex     sync.Mutex
+	deadlines []time.Time
+	expired   chan struct{}
+}
+
+func (connection *blockingConnection) WriteMessage(messageType int, body []byte) error {
+	<-connection.expired
+	return errors.New("i/o timeout")
+}
+
+func (connection *blockingConnection) SetWriteDeadline(deadline time.Time) error {
+	connection.mutex.Lock()
+	defer connection.mutex.Unlock()
+	connection.deadlines = append(connection.deadlines, deadline)
+	if !deadline.IsZero() && !deadline.After(time.Now()) {
+		close(connection.expired)
+	}
+	return nil
+}
+
+func (s *WebSocketUnitTestSuite) TestUnit_SendRPCRequestCtx() {
+	mockConnection := new(mocks.MockWebSocketConnection)
+	request := map[string]interface{}{
+		"method": "example",
+		"params": nil,
+	}
+	requestJSON, err := json.Marshal(request)
+	require.NoError(s.T(), err)
+	mockConnection.On("WriteMessage", websocket.TextMessage, requestJSON).Return(nil).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = SendRPCRequestCtx(ctx, mockConnection, request)
+	require.NoError(s.T(), err)
+
+	// Done contexts are not written.
+	cancel()
+	err = SendRPCRequestCtx(ctx, mockConnection, request)
+	require.ErrorIs(s.T(), err, context.Canceled)
+	mockConnection.AssertExpectations(s.T())
+}
+
+func (s *WebSocketUnitTestSuite) TestUnit_SendRPCRequestCtx_Cancel() {
+	connection := &blockingConnection{expired: make(chan struct{})}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	// The write is bounded by the deadline, aborted on cancellation, then the deadline is cleared.
+	err := SendRPCRequestCtx(ctx, connection, map[string]interface{}{"method": "example"})
+	require.ErrorIs(s.T(), err, context.Canceled)
+	deadline, _ := ctx.Deadline()
+	require.Len(s.T(), connection.deadlines, 3)
+	require.Equal(s.T(), deadline, connection.deadlines[0])
+	require.True(s.T(), connection.deadlines[2].IsZero())
+}
+
+func (s *WebSocketUnitTestSuite) TestUnit_ReadRPCResponse() {
+	mockConnection := new(mocks.MockWebSocketConnection)
+	mockConnection.On("ReadMessage").Return(websocket.TextMessage, []byte(`not json`), nil).Once()
+	mockConnection.On("ReadMessage").Return(websocket.TextMessage, []byte(`{"id":"other","success":true}`), nil).Once()
+	mockConnection.On("ReadMessage").Return(websocket.TextMessage, []byte(`{"id":"LIST","success":true,"result":[{"subAccountId":2}]}`), nil).Once()
+
+	response, err := ReadRPCResponse(mockConnection, "LIST")
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "LIST", response.ID)
+
+	var result []struct {
+		SubAccountId int64 `json:"subAccountId"`
+	}
+	err = DecodeRPCResult(response, &result)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), int64(2), result[0].SubAccountId)
+	mockConnection.AssertNumberOfCalls(s.T(), "ReadMessage", 3)
+}
+
+func (s *WebSocketUnitTestSuite) TestUnit_ReadRPCResponse_RPCError() {
+	mockConnection := new(mocks.MockWebSocketConnection)
+	mockConnection.On("ReadMessage").Return(websocket.TextMessage, []byte(`{"id":"LIST","success":false,"error":{"code":401,"message":"unauthorized"}}`), nil)
+
+	response, err := ReadRPCResponse(mockConnection, "LIST")
+	require.Error(s.T(), err)
+	require.NotNil(s.T(), response)
+}
+
+func (s *WebSocketUnitTestSuite) TestUnit_ReadRPCResponse_ReadError() {
+	mockConnection := new(mocks.MockWebSocketConnection)
+	mockConnection.On("ReadMessage").Return(0, []byte(nil), errors.New("connection closed"))
+
+	response, err := ReadRPCResponse(mockConnection, "LIST")
+	require.Error(s.T(), err)
+	require.Nil(s.T(), response)
+}
@@ -3,52 +3,98 @@ package ws_client
 import (
 	"context"
 	"crypto/ecdsa"
+	"encoding/json"
 	"fmt"
+	"log/slog"
 	"math/big"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/rysk-finance/v2_client_go/audit"
 	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/logging"
+	"github.com/rysk-finance/v2_client_go/metrics"
+	"github.com/rysk-finance/v2_client_go/ratelimit"
+	"github.com/rysk-finance/v2_client_go/tracing"
+	"github.com/rysk-finance/v2_client_go/tx_manager"
+	"github.com/rysk-finance/v2_client_go/typed_data"
 	"github.com/rysk-finance/v2_client_go/types"
 	"github.com/rysk-finance/v2_client_go/utils"
 
-	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	geth_types "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// typedDataMethods are the RPC methods of the actions submitted by `SubmitTypedData`, by primary type.
+var typedDataMethods = map[types.PrimaryType]types.WSMethod{
+	constants.PRIMARY_TYPE_LOGIN_MESSAGE:  constants.WS_METHOD_LOGIN,
+	constants.PRIMARY_TYPE_ORDER:          constants.WS_METHOD_NEW_ORDER,
+	constants.PRIMARY_TYPE_CANCEL_ORDER:   constants.WS_METHOD_CANCEL_ORDER,
+	constants.PRIMARY_TYPE_CANCEL_ORDERS:  constants.WS_METHOD_CANCEL_ALL_OPEN_ORDERS,
+	constants.PRIMARY_TYPE_APPROVE_SIGNER: constants.WS_METHOD_APPROVE_REVOKE_SIGNER,
+	constants.PRIMARY_TYPE_WITHDRAW:       constants.WS_METHOD_WITHDRAW,
+}
+
 // RyskV2WSClientConfiguration represents configuration settings for the Rysk V2 WebSocket client.
 type RyskV2WSClientConfiguration struct {
-	Env          types.Environment // Env specifies the environment: `constants.ENVIRONMENT_TESTNET` or `constants.ENVIRONMENT_MAINNET`.
-	PrivateKey   string            // PrivateKey is the account private key with or without `0x` prefix.
-	RpcUrl       string            // RPC URL of the Ethereum client.
-	SubAccountId uint8             // SubAccountId is the ID of the subaccount to use.
+	Env                types.Environment                           // Env specifies the environment: `constants.ENVIRONMENT_TESTNET` or `constants.ENVIRONMENT_MAINNET`.
+	PrivateKey         string                                      // PrivateKey is the account private key with or without `0x` prefix.
+	RpcUrl             string                                      // RPC URL of the Ethereum client.
+	SubAccountId       uint8                                       // SubAccountId is the ID of the subaccount to use.
+	Gas                *types.GasConfiguration                     // Gas is the optional EIP-1559 gas settings for on-chain transactions, defaults to suggested fees.
+	TransactionManager *tx_manager.TransactionManagerConfiguration // TransactionManager is the optional transaction manager settings, on-chain transactions track nonces locally when set.
+	ApprovalMode       types.ApprovalMode                          // ApprovalMode is the approval mode used by `Deposit`, `constants.APPROVAL_MODE_EXACT` (default) or `constants.APPROVAL_MODE_MAX`.
+	BaseUrl            string                                      // BaseUrl is the optional REST API base URL, defaults to `constants.API_BASE_URL[Env]`.
+	WSRpcUrl           string                                      // WSRpcUrl is the optional RPC websocket URL, defaults to `constants.WS_RPC_URL[Env]`.
+	WSStreamUrl        string                                      // WSStreamUrl is the optional stream websocket URL, defaults to `constants.WS_STREAM_URL[Env]`.
+	RateLimiter        *ratelimit.RateLimiter                      // RateLimiter is the optional rate limiter of RPC and stream requests, e.g. shared with the REST client of the account.
+	Metrics            *metrics.Metrics                            // Metrics is the optional metrics of requests, signatures, order rejects and transactions, nothing is recorded when nil.
+	Tracer             *tracing.Tracer                             // Tracer is the optional tracer of the client methods, signatures and requests, nothing is traced when nil.
+	Logger             *slog.Logger                                // Logger is the optional logger of connections, subscriptions, requests and messages with redacted signatures, and transactions, nothing is logged when nil.
+	AuditLog           *audit.Log                                  // AuditLog is the optional audit log of signed orders, cancels, signer approvals and withdrawals with their responses, actions are not sent when they cannot be recorded.
 }
 
 // RyskV2WSClient is the WebSocket client for interacting with Rysk V2 services.
 type RyskV2WSClient struct {
-	env              types.Environment        // env is the current environment setting.
-	baseUrl          string                   // baseUrl is the HTTP Api base URL.
-	rpcUrl           string                   // rpcUrl of the Ethereum client.
-	streamUrl        string                   // streamUrl is the WebSocket stream URL.
-	privateKeyString string                   // privateKeyString is the private key string.
-	addressString    string                   // addressString is the Ethereum address string derived from the private key.
-	privateKey       *ecdsa.PrivateKey        // privateKey is the ECDSA private key instance.
-	address          common.Address           // address is the Ethereum address derived from the private key.
-	ciao             common.Address           // ciao is a common address used in the context.
-	usdc             common.Address           // usdc is a common address used in the context.
-	domain           apitypes.TypedDataDomain // domain represents the typed data domain for API requests.
-	SubAccountId     int64                    // SubAccountId is the ID of the subaccount to use.
-	RPCConnection    *websocket.Conn          // RPCConnection is the WebSocket connection for RPC operations.
-	StreamConnection *websocket.Conn          // StreamConnection is the WebSocket connection for streaming operations.
-	EthClient        types.IEthClient         // EthClient is the Ethereum client interface.
+	env                types.Environment              // env is the current environment setting.
+	baseUrl            string                         // baseUrl is the HTTP Api base URL.
+	rpcUrl             string                         // rpcUrl of the Ethereum client.
+	streamUrl          string                         // streamUrl is the WebSocket stream URL.
+	privateKeyString   string                         // privateKeyString is the private key string.
+	addressString      string                         // addressString is the Ethereum address string derived from the private key.
+	privateKey         *ecdsa.PrivateKey              // privateKey is the ECDSA private key instance.
+	address            common.Address                 // address is the Ethereum address derived from the private key.
+	ciao               common.Address                 // ciao is a common address used in the context.
+	usdc               common.Address                 // usdc is a common address used in the context.
+	domain             apitypes.TypedDataDomain       // domain represents the typed data domain for API requests.
+	SubAccountId       int64                          // SubAccountId is the ID of the subaccount to use.
+	RPCConnection      *websocket.Conn                // RPCConnection is the WebSocket connection for RPC operations.
+	StreamConnection   *websocket.Conn                // StreamConnection is the WebSocket connection for streaming operations.
+	EthClient          types.IEthClient               // EthClient is the Ethereum client interface.
+	GasConfiguration   *types.GasConfiguration        // GasConfiguration is the EIP-1559 gas settings for on-chain transactions.
+	TransactionManager *tx_manager.TransactionManager // TransactionManager is the optional transaction manager for on-chain transactions.
+	ApprovalMode       types.ApprovalMode             // ApprovalMode is the approval mode used by `Deposit`, `constants.APPROVAL_MODE_EXACT` (default) or `constants.APPROVAL_MODE_MAX`.
+	rateLimiter        *ratelimit.RateLimiter         // rateLimiter is the optional rate limiter of requests.
+	metrics            *metrics.Metrics               // metrics is the optional metrics, nil records nothing.
+	tracer             *tracing.Tracer                // tracer is the optional tracer, nil traces nothing.
+	logger             *slog.Logger                   // logger is the optional logger, nil logs nothing.
+	auditLog           *audit.Log                     // auditLog is the optional audit log, nil records nothing.
+	writeLocks         *writeLocks                    // writeLocks serialises writes to the connections, shared by the copies returned by `WithSubAccount`.
+}
+
+// writeLocks serialises writes to the RPC and stream connections, which allow a single concurrent writer.
+type writeLocks struct {
+	rpc    sync.Mutex // rpc guards writes to the RPC connection.
+	stream sync.Mutex // stream guards writes to the stream connection.
 }
 
 // NewRyskV2WSClient creates a new `RyskV2WSClient` instance based on the provided configuration.
@@ -77,10 +123,24 @@ func NewRyskV2WSClient(config *RyskV2WSClientConfiguration) (*RyskV2WSClient, er
 		return nil, fmt.Errorf("failed to connect to the Ethereum client: %v", err)
 	}
 
+	// Default URLs to the environment ones.
+	baseUrl := config.BaseUrl
+	if baseUrl == "" {
+		baseUrl = constants.API_BASE_URL[config.Env]
+	}
+	wsRpcUrl := config.WSRpcUrl
+	if wsRpcUrl == "" {
+		wsRpcUrl = constants.WS_RPC_URL[config.Env]
+	}
+	wsStreamUrl := config.WSStreamUrl
+	if wsStreamUrl == "" {
+		wsStreamUrl = constants.WS_STREAM_URL[config.Env]
+	}
+
 	// Create RPC websocket connection.
 	rpcWebsocket, _, err := websocket.DefaultDialer.DialContext(
 		context.Background(),
-		constants.WS_RPC_URL[config.Env],
+		wsRpcUrl,
 		http.Header{},
 	)
 	if err != nil {
@@ -90,7 +150,7 @@ func NewRyskV2WSClient(config *RyskV2WSClientConfiguration) (*RyskV2WSClient, er
 	// Create streamWebsocket websocket connection.
 	streamWebsocket, _, err := websocket.DefaultDialer.DialContext(
 		context.Background(),
-		constants.WS_STREAM_URL[config.Env],
+		wsStreamUrl,
 		http.Header{},
 	)
 	if err != nil {
@@ -100,40 +160,86 @@ func NewRyskV2WSClient(config *RyskV2WSClientConfiguration) (*RyskV2WSClient, er
 	// Return a new `RyskV2WSClient`.
 	wsClient := &RyskV2WSClient{
 		env:              config.Env,
-		baseUrl:          constants.API_BASE_URL[config.Env],
-		rpcUrl:           constants.WS_RPC_URL[config.Env],
-		streamUrl:        constants.WS_STREAM_URL[config.Env],
+		baseUrl:          baseUrl,
+		rpcUrl:           wsRpcUrl,
+		streamUrl:        wsStreamUrl,
 		privateKey:       privateKey,
 		privateKeyString: privateKeyString,
 		address:          common.HexToAddress(utils.AddressFromPrivateKey(privateKeyString)),
 		addressString:    utils.AddressFromPrivateKey(privateKeyString),
 		ciao:             common.HexToAddress(constants.CIAO_ADDRESS[config.Env]),
 		usdc:             common.HexToAddress(constants.USDC_ADDRESS[config.Env]),
-		domain: apitypes.TypedDataDomain{
-			Name:              constants.DOMAIN_NAME,
-			Version:           constants.DOMAIN_VERSION,
-			ChainId:           constants.CHAIN_ID[config.Env],
-			VerifyingContract: constants.ORDER_DISPATCHER_ADDRESS[config.Env],
-		},
+		domain:           typed_data.Domain(config.Env),
 		SubAccountId:     int64(config.SubAccountId),
 		RPCConnection:    rpcWebsocket,
 		StreamConnection: streamWebsocket,
 		EthClient:        client,
+		GasConfiguration: config.Gas,
+		ApprovalMode:     config.ApprovalMode,
+		rateLimiter:      config.RateLimiter,
+		metrics:          config.Metrics,
+		tracer:           config.Tracer,
+		logger:           config.Logger,
+		auditLog:         config.AuditLog,
+		writeLocks:       &writeLocks{},
+	}
+	wsClient.log().Info("websocket connected", "connection", metrics.CONNECTION_RPC, "url", wsRpcUrl)
+	wsClient.log().Info("websocket connected", "connection", metrics.CONNECTION_STREAM, "url", wsStreamUrl)
+
+	// Create transaction manager.
+	if config.TransactionManager != nil {
+		transactionManagerConfiguration := *config.TransactionManager
+		transactionManagerConfiguration.EthClient = client
+		transactionManagerConfiguration.PrivateKey = privateKeyString
+		if transactionManagerConfiguration.GasConfiguration == nil {
+			transactionManagerConfiguration.GasConfiguration = config.Gas
+		}
+		wsClient.TransactionManager, err = tx_manager.NewTransactionManager(&transactionManagerConfiguration)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create transaction manager: %v", err)
+		}
 	}
 
 	wsClient.addReferee()
 	return wsClient, nil
 }
 
+// WithSubAccount returns a copy of the client acting on another sub-account.
+// The copy shares the signer, RPC and stream connections, Ethereum client and transaction manager with the original client,
+// and writes to the connections are serialised across the original client and all its copies.
+//
+// Parameters:
+//   - subAccountId: The ID of the sub-account to act on.
+//
+// Returns:
+//   - A pointer to a RyskV2WSClient acting on `subAccountId`.
+func (go100XClient *RyskV2WSClient) WithSubAccount(subAccountId uint8) *RyskV2WSClient {
+	subAccountClient := *go100XClient
+	subAccountClient.SubAccountId = int64(subAccountId)
+	return &subAccountClient
+}
+
 // ListProducts sends a request to retrieve the list of products available on the Rysk V2 WebSocket API.
 // It subscribes to the `LIST_PRODUCTS` message identifier to fetch the products.
 //
+// It calls `ListProductsCtx` with a background context.
+func (go100XClient *RyskV2WSClient) ListProducts(messageId string) error {
+	return go100XClient.ListProductsCtx(context.Background(), messageId)
+}
+
+// ListProductsCtx sends a request to retrieve the list of products available on the Rysk V2 WebSocket API.
+// It subscribes to the `LIST_PRODUCTS` message identifier to fetch the products.
+//
 // Parameters:
+//   - ctx: Context bounding the write of the request, not the wait for its response on `RPCConnection`.
 //   - messageId: The unique identifier for the message.
 //
 // Returns:
 //   - error: An error if the request to fetch the products fails.
-func (go100XClient *RyskV2WSClient) ListProducts(messageId string) error {
+func (go100XClient *RyskV2WSClient) ListProductsCtx(ctx context.Context, messageId string) error {
+	ctx, span := go100XClient.startSpan(ctx, "ListProducts", tracing.MessageId(messageId))
+	defer span.End()
+
 	// Generate RPC request.
 	request := &types.WebsocketRequest{
 		JsonRPC: constants.WS_JSON_RPC,
@@ -142,18 +248,29 @@ func (go100XClient *RyskV2WSClient) ListProducts(messageId string) error {
 	}
 
 	// Send RPC request.
-	return utils.SendRPCRequest(go100XClient.RPCConnection, request)
+	return go100XClient.send(ctx, go100XClient.RPCConnection, request)
 }
 
 // GetProduct sends a request to retrieve details for a specific product using the Rysk V2 WebSocket API.
 //
+// It calls `GetProductCtx` with a background context.
+func (go100XClient *RyskV2WSClient) GetProduct(messageId string, product *types.Product) error {
+	return go100XClient.GetProductCtx(context.Background(), messageId, product)
+}
+
+// GetProductCtx sends a request to retrieve details for a specific product using the Rysk V2 WebSocket API.
+//
 // Parameters:
+//   - ctx: Context bounding the write of the request, not the wait for its response on `RPCConnection`.
 //   - messageId: The unique identifier for the message.
 //   - product: A pointer to the product details structure (types.Product) where the retrieved data will be stored.
 //
 // Returns:
 //   - error: An error if the request to fetch the product details fails.
-func (go100XClient *RyskV2WSClient) GetProduct(messageId string, product *types.Product) error {
+func (go100XClient *RyskV2WSClient) GetProductCtx(ctx context.Context, messageId string, product *types.Product) error {
+	ctx, span := go100XClient.startSpan(ctx, "GetProduct", tracing.MessageId(messageId), tracing.Product(product))
+	defer span.End()
+
 	// Generate RPC request.
 	request := &types.WebsocketRequest{
 		JsonRPC: constants.WS_JSON_RPC,
@@ -167,18 +284,30 @@ func (go100XClient *RyskV2WSClient) GetProduct(messageId string, product *types.
 	}
 
 	// Send RPC request.
-	return utils.SendRPCRequest(go100XClient.RPCConnection, request)
+	return go100XClient.send(ctx, go100XClient.RPCConnection, request)
 }
 
 // ServerTime sends a request to test connectivity and retrieve the current server time
 // using the Rysk V2 WebSocket API.
 //
+// It calls `ServerTimeCtx` with a background context.
+func (go100XClient *RyskV2WSClient) ServerTime(messageId string) error {
+	return go100XClient.ServerTimeCtx(context.Background(), messageId)
+}
+
+// ServerTimeCtx sends a request to test connectivity and retrieve the current server time
+// using the Rysk V2 WebSocket API.
+//
 // Parameters:
+//   - ctx: Context bounding the write of the request, not the wait for its response on `RPCConnection`.
 //   - messageId: The unique identifier for the message.
 //
 // Returns:
 //   - error: An error if the request to fetch the server time fails.
-func (go100XClient *RyskV2WSClient) ServerTime(messageId string) error {
+func (go100XClient *RyskV2WSClient) ServerTimeCtx(ctx context.Context, messageId string) error {
+	ctx, span := go100XClient.startSpan(ctx, "ServerTime", tracing.MessageId(messageId))
+	defer span.End()
+
 	// Generate RPC request.
 	request := &types.WebsocketRequest{
 		JsonRPC: constants.WS_JSON_RPC,
@@ -187,25 +316,36 @@ func (go100XClient *RyskV2WSClient) ServerTime(messageId string) error {
 	}
 
 	// Send RPC request.
-	return utils.SendRPCRequest(go100XClient.RPCConnection, request)
+	return go100XClient.send(ctx, go100XClient.RPCConnection, request)
 }
 
 // Login performs authentication for the WebSocket connection.
 // Authentication using signature is required to create and cancel orders, deposit and withdraw.
 //
+// It calls `LoginCtx` with a background context.
+func (go100XClient *RyskV2WSClient) Login(messageId string) error {
+	return go100XClient.LoginCtx(context.Background(), messageId)
+}
+
+// LoginCtx performs authentication for the WebSocket connection.
+// Authentication using signature is required to create and cancel orders, deposit and withdraw.
+//
 // Parameters:
+//   - ctx: Context bounding the signing of the login message and the write of the request, not the wait for its response.
 //   - messageId: The unique identifier for the message.
 //
 // Returns:
 //   - error: An error if the authentication fails.
-func (go100XClient *RyskV2WSClient) Login(messageId string) error {
+func (go100XClient *RyskV2WSClient) LoginCtx(ctx context.Context, messageId string) error {
+	ctx, span := go100XClient.startSpan(ctx, "Login", tracing.MessageId(messageId))
+	defer span.End()
+
 	// Current timestamp in ms, will be rejected if older than 10s, easiest to send in a time in the future.
 	timestamp := uint64(time.Now().Add(10 * time.Second).UnixMilli())
 
 	// Generate EIP712 signature.
-	signature, err := utils.SignMessage(
-		go100XClient.domain,
-		go100XClient.privateKeyString,
+	signature, err := go100XClient.signMessage(
+		ctx,
 		constants.PRIMARY_TYPE_LOGIN_MESSAGE,
 		&struct {
 			Account   string `json:"account"`
@@ -213,7 +353,7 @@ func (go100XClient *RyskV2WSClient) Login(messageId string) error {
 			Timestamp uint64 `json:"timestamp"`
 		}{
 			Account:   go100XClient.addressString,
-			Message:   "I want to log into rysk.finance",
+			Message:   constants.LOGIN_MESSAGE,
 			Timestamp: timestamp,
 		},
 	)
@@ -233,24 +373,35 @@ func (go100XClient *RyskV2WSClient) Login(messageId string) error {
 			Signature string `json:"signature"`
 		}{
 			Account:   go100XClient.addressString,
-			Message:   "I want to log into rysk.finance",
+			Message:   constants.LOGIN_MESSAGE,
 			Timestamp: timestamp,
 			Signature: signature,
 		},
 	}
 
 	// Send RPC request.
-	return utils.SendRPCRequest(go100XClient.RPCConnection, request)
+	return go100XClient.send(ctx, go100XClient.RPCConnection, request)
 }
 
 // SessionStatus checks the active session and returns the address currently authenticated.
 //
+// It calls `SessionStatusCtx` with a background context.
+func (go100XClient *RyskV2WSClient) SessionStatus(messageId string) error {
+	return go100XClient.SessionStatusCtx(context.Background(), messageId)
+}
+
+// SessionStatusCtx checks the active session and returns the address currently authenticated.
+//
 // Parameters:
+//   - ctx: Context bounding the write of the request, not the wait for its response on `RPCConnection`.
 //   - messageId: The unique identifier for the message.
 //
 // Returns:
 //   - error: An error if the session status retrieval fails.
-func (go100XClient *RyskV2WSClient) SessionStatus(messageId string) error {
+func (go100XClient *RyskV2WSClient) SessionStatusCtx(ctx context.Context, messageId string) error {
+	ctx, span := go100XClient.startSpan(ctx, "SessionStatus", tracing.MessageId(messageId))
+	defer span.End()
+
 	// Generate RPC request.
 	request := &types.WebsocketRequest{
 		JsonRPC: constants.WS_JSON_RPC,
@@ -259,17 +410,28 @@ func (go100XClient *RyskV2WSClient) SessionStatus(messageId string) error {
 	}
 
 	// Send RPC request.
-	return utils.SendRPCRequest(go100XClient.RPCConnection, request)
+	return go100XClient.send(ctx, go100XClient.RPCConnection, request)
 }
 
 // SubAccountList retrieves a list of all sub-accounts associated with the authenticated account.
 //
+// It calls `SubAccountListCtx` with a background context.
+func (go100XClient *RyskV2WSClient) SubAccountList(messageId string) error {
+	return go100XClient.SubAccountListCtx(context.Background(), messageId)
+}
+
+// SubAccountListCtx retrieves a list of all sub-accounts associated with the authenticated account.
+//
 // Parameters:
+//   - ctx: Context bounding the write of the request, not the wait for its response on `RPCConnection`.
 //   - messageId: The unique identifier for the message.
 //
 // Returns:
 //   - error: An error if the sub-account list retrieval fails.
-func (go100XClient *RyskV2WSClient) SubAccountList(messageId string) error {
+func (go100XClient *RyskV2WSClient) SubAccountListCtx(ctx context.Context, messageId string) error {
+	ctx, span := go100XClient.startSpan(ctx, "SubAccountList", tracing.MessageId(messageId))
+	defer span.End()
+
 	// Generate RPC request.
 	request := &types.WebsocketRequest{
 		JsonRPC: constants.WS_JSON_RPC,
@@ -283,47 +445,69 @@ func (go100XClient *RyskV2WSClient) SubAccountList(messageId string) error {
 	}
 
 	// Send RPC request.
-	return utils.SendRPCRequest(go100XClient.RPCConnection, request)
+	return go100XClient.send(ctx, go100XClient.RPCConnection, request)
 }
 
 // ApproveSigner approves a signer for a sub-account.
 //
+// It calls `ApproveSignerCtx` with a background context.
+func (go100XClient *RyskV2WSClient) ApproveSigner(messageId string, params *types.ApproveRevokeSignerRequest) error {
+	return go100XClient.ApproveSignerCtx(context.Background(), messageId, params)
+}
+
+// ApproveSignerCtx approves a signer for a sub-account.
+//
 // Parameters:
+//   - ctx: Context bounding the signing of the approval and the write of the request, not the wait for its response.
 //   - messageId: The unique identifier for the message.
 //   - params: Approval parameters including the signer details.
 //
 // Returns:
 //   - error: An error if the approval process fails.
-func (go100XClient *RyskV2WSClient) ApproveSigner(messageId string, params *types.ApproveRevokeSignerRequest) error {
-	return go100XClient.approveRevokeSigner(messageId, params, true)
+func (go100XClient *RyskV2WSClient) ApproveSignerCtx(ctx context.Context, messageId string, params *types.ApproveRevokeSignerRequest) error {
+	ctx, span := go100XClient.startSpan(ctx, "ApproveSigner", tracing.MessageId(messageId))
+	defer span.End()
+
+	return go100XClient.approveRevokeSigner(ctx, messageId, params, true)
 }
 
 // RevokeSigner revokes a signer for a sub-account.
 //
+// It calls `RevokeSignerCtx` with a background context.
+func (go100XClient *RyskV2WSClient) RevokeSigner(messageId string, params *types.ApproveRevokeSignerRequest) error {
+	return go100XClient.RevokeSignerCtx(context.Background(), messageId, params)
+}
+
+// RevokeSignerCtx revokes a signer for a sub-account.
+//
 // Parameters:
+//   - ctx: Context bounding the signing of the revocation and the write of the request, not the wait for its response.
 //   - messageId: The unique identifier for the message.
 //   - params: Revocation parameters including the signer details.
 //
 // Returns:
 //   - error: An error if the revocation process fails.
-func (go100XClient *RyskV2WSClient) RevokeSigner(messageId string, params *types.ApproveRevokeSignerRequest) error {
-	return go100XClient.approveRevokeSigner(messageId, params, false)
+func (go100XClient *RyskV2WSClient) RevokeSignerCtx(ctx context.Context, messageId string, params *types.ApproveRevokeSignerRequest) error {
+	ctx, span := go100XClient.startSpan(ctx, "RevokeSigner", tracing.MessageId(messageId))
+	defer span.End()
+
+	return go100XClient.approveRevokeSigner(ctx, messageId, params, false)
 }
 
 // approveRevokeSigner approves or revokes a signer for a sub-account.
 //
 // Parameters:
+//   - ctx: Context bounding the signing and the write of the request, not the wait for its response.
 //   - messageId: The unique identifier for the message.
 //   - params: Approval or revocation parameters, including signer details.
 //   - isApproved: Boolean flag indicating whether to approve or revoke the signer.
 //
 // Returns:
 //   - error: An error if the operation fails.
-func (go100XClient *RyskV2WSClient) approveRevokeSigner(messageId string, params *types.ApproveRevokeSignerRequest, isApproved bool) error {
+func (go100XClient *RyskV2WSClient) approveRevokeSigner(ctx context.Context, messageId string, params *types.ApproveRevokeSignerRequest, isApproved bool) error {
 	// Generate EIP712 signature.
-	signature, err := utils.SignMessage(
-		go100XClient.domain,
-		go100XClient.privateKeyString,
+	signature, action, err := go100XClient.signAction(
+		ctx,
 		constants.PRIMARY_TYPE_APPROVE_SIGNER,
 		&struct {
 			Account        string `json:"account"`
@@ -365,23 +549,101 @@ func (go100XClient *RyskV2WSClient) approveRevokeSigner(messageId string, params
 		},
 	}
 
-	// Send RPC request.
-	return utils.SendRPCRequest(go100XClient.RPCConnection, request)
+	// Send RPC request, recording the action.
+	return go100XClient.sendAction(ctx, action, request)
+}
+
+// Withdraw initiates a withdrawal of USDC from the SubAccount.
+//
+// It calls `WithdrawCtx` with a background context.
+func (go100XClient *RyskV2WSClient) Withdraw(messageId string, params *types.WithdrawRequest) error {
+	return go100XClient.WithdrawCtx(context.Background(), messageId, params)
+}
+
+// WithdrawCtx initiates a withdrawal of USDC from the SubAccount.
+//
+// Parameters:
+//   - ctx: Context bounding the signing of the withdrawal and the write of the request, not the wait for its response.
+//   - messageId: The unique identifier for the message.
+//   - params: A struct containing the withdrawal quantity and nonce.
+//
+// Returns:
+//   - error: An error if the operation fails.
+func (go100XClient *RyskV2WSClient) WithdrawCtx(ctx context.Context, messageId string, params *types.WithdrawRequest) error {
+	ctx, span := go100XClient.startSpan(ctx, "Withdraw", tracing.MessageId(messageId))
+	defer span.End()
+
+	// Generate EIP712 signature.
+	signature, action, err := go100XClient.signAction(
+		ctx,
+		constants.PRIMARY_TYPE_WITHDRAW,
+		&struct {
+			Account      string `json:"account"`
+			SubAccountId string `json:"subAccountId"`
+			Asset        string `json:"asset"`
+			Quantity     string `json:"quantity"`
+			Nonce        string `json:"nonce"`
+		}{
+			Account:      go100XClient.addressString,
+			SubAccountId: strconv.FormatInt(go100XClient.SubAccountId, 10),
+			Asset:        constants.USDC_ADDRESS[go100XClient.env],
+			Quantity:     params.Quantity,
+			Nonce:        strconv.FormatInt(params.Nonce, 10),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Generate RPC request.
+	request := &types.WebsocketRequest{
+		JsonRPC: constants.WS_JSON_RPC,
+		ID:      messageId,
+		Method:  constants.WS_METHOD_WITHDRAW,
+		Params: &struct {
+			Account      string `json:"account"`
+			SubAccountId int64  `json:"subAccountId"`
+			Asset        string `json:"asset"`
+			Quantity     string `json:"quantity"`
+			Nonce        int64  `json:"nonce"`
+			Signature    string `json:"signature"`
+		}{
+			Account:      go100XClient.addressString,
+			SubAccountId: go100XClient.SubAccountId,
+			Asset:        constants.USDC_ADDRESS[go100XClient.env],
+			Quantity:     params.Quantity,
+			Nonce:        params.Nonce,
+			Signature:    signature,
+		},
+	}
+
+	// Send RPC request, recording the action.
+	return go100XClient.sendAction(ctx, action, request)
 }
 
 // NewOrder creates a new order on the SubAccount.
 //
+// It calls `NewOrderCtx` with a background context.
+func (go100XClient *RyskV2WSClient) NewOrder(messageId string, params *types.NewOrderRequest) error {
+	return go100XClient.NewOrderCtx(context.Background(), messageId, params)
+}
+
+// NewOrderCtx creates a new order on the SubAccount.
+//
 // Parameters:
+//   - ctx: Context bounding the signing of the order and the write of the request, not the wait for its response.
 //   - messageId: The unique identifier for the message.
 //   - params: A struct containing details for the new order, such as product symbol, order type, quantity, price, etc.
 //
 // Returns:
 //   - error: An error if the operation fails.
-func (go100XClient *RyskV2WSClient) NewOrder(messageId string, params *types.NewOrderRequest) error {
+func (go100XClient *RyskV2WSClient) NewOrderCtx(ctx context.Context, messageId string, params *types.NewOrderRequest) error {
+	ctx, span := go100XClient.startSpan(ctx, "NewOrder", tracing.MessageId(messageId), tracing.Product(params.Product), tracing.OrderType(params.OrderType))
+	defer span.End()
+
 	// Generate EIP712 signature.
-	signature, err := utils.SignMessage(
-		go100XClient.domain,
-		go100XClient.privateKeyString,
+	signature, action, err := go100XClient.signAction(
+		ctx,
 		constants.PRIMARY_TYPE_ORDER,
 		&struct {
 			Account      string `json:"account"`
@@ -443,19 +705,30 @@ func (go100XClient *RyskV2WSClient) NewOrder(messageId string, params *types.New
 		},
 	}
 
-	// Send RPC request.
-	return utils.SendRPCRequest(go100XClient.RPCConnection, request)
+	// Send RPC request, recording the action.
+	return go100XClient.sendAction(ctx, action, request)
 }
 
 // ListOpenOrders returns all open orders on the `SubAccount` per product.
 //
+// It calls `ListOpenOrdersCtx` with a background context.
+func (go100XClient *RyskV2WSClient) ListOpenOrders(messageId string, params *types.ListOrdersRequest) error {
+	return go100XClient.ListOpenOrdersCtx(context.Background(), messageId, params)
+}
+
+// ListOpenOrdersCtx returns all open orders on the `SubAccount` per product.
+//
 // Parameters:
+//   - ctx: Context bounding the write of the request, not the wait for its response on `RPCConnection`.
 //   - messageId: The unique identifier for the message.
 //   - params: A struct containing parameters to specify the product and additional filtering criteria for the orders.
 //
 // Returns:
 //   - error: An error if the operation fails.
-func (go100XClient *RyskV2WSClient) ListOpenOrders(messageId string, params *types.ListOrdersRequest) error {
+func (go100XClient *RyskV2WSClient) ListOpenOrdersCtx(ctx context.Context, messageId string, params *types.ListOrdersRequest) error {
+	ctx, span := go100XClient.startSpan(ctx, "ListOpenOrders", tracing.MessageId(messageId), tracing.Product(params.Product))
+	defer span.End()
+
 	// Generate RPC request.
 	request := &types.WebsocketRequest{
 		JsonRPC: constants.WS_JSON_RPC,
@@ -481,22 +754,32 @@ func (go100XClient *RyskV2WSClient) ListOpenOrders(messageId string, params *typ
 	}
 
 	// Send RPC request.
-	return utils.SendRPCRequest(go100XClient.RPCConnection, request)
+	return go100XClient.send(ctx, go100XClient.RPCConnection, request)
 }
 
 // CancelOrder cancels an active order on the `SubAccount`.
 //
+// It calls `CancelOrderCtx` with a background context.
+func (go100XClient *RyskV2WSClient) CancelOrder(messageId string, params *types.CancelOrderRequest) error {
+	return go100XClient.CancelOrderCtx(context.Background(), messageId, params)
+}
+
+// CancelOrderCtx cancels an active order on the `SubAccount`.
+//
 // Parameters:
+//   - ctx: Context bounding the signing of the cancellation and the write of the request, not the wait for its response.
 //   - messageId: The unique identifier for the message.
 //   - params: A struct containing parameters to specify the order to be canceled.
 //
 // Returns:
 //   - error: An error if the operation fails.
-func (go100XClient *RyskV2WSClient) CancelOrder(messageId string, params *types.CancelOrderRequest) error {
+func (go100XClient *RyskV2WSClient) CancelOrderCtx(ctx context.Context, messageId string, params *types.CancelOrderRequest) error {
+	ctx, span := go100XClient.startSpan(ctx, "CancelOrder", tracing.MessageId(messageId), tracing.Product(params.Product))
+	defer span.End()
+
 	// Generate EIP712 signature.
-	signature, err := utils.SignMessage(
-		go100XClient.domain,
-		go100XClient.privateKeyString,
+	signature, action, err := go100XClient.signAction(
+		ctx,
 		constants.PRIMARY_TYPE_CANCEL_ORDER,
 		&struct {
 			Account      string `json:"account"`
@@ -534,13 +817,21 @@ func (go100XClient *RyskV2WSClient) CancelOrder(messageId string, params *types.
 		},
 	}
 
-	// Send RPC request.
-	return utils.SendRPCRequest(go100XClient.RPCConnection, request)
+	// Send RPC request, recording the action.
+	return go100XClient.sendAction(ctx, action, request)
 }
 
 // CancelAllOpenOrders cancels all active orders on a product for the `SubAccount`.
 //
+// It calls `CancelAllOpenOrdersCtx` with a background context.
+func (go100XClient *RyskV2WSClient) CancelAllOpenOrders(messageId string, product *types.Product) error {
+	return go100XClient.CancelAllOpenOrdersCtx(context.Background(), messageId, product)
+}
+
+// CancelAllOpenOrdersCtx cancels all active orders on a product for the `SubAccount`.
+//
 // Parameters:
+//   - ctx: Context bounding the write of the request, not the wait for its response on `RPCConnection`.
 //   - messageId: The unique identifier for the message.
 //   - product: The product for which all active orders should be canceled.
 //
@@ -548,7 +839,10 @@ func (go100XClient *RyskV2WSClient) CancelOrder(messageId string, params *types.
 //   - error: An error if the operation fails.
 //
 // Returns number of deleted orders.
-func (go100XClient *RyskV2WSClient) CancelAllOpenOrders(messageId string, product *types.Product) error {
+func (go100XClient *RyskV2WSClient) CancelAllOpenOrdersCtx(ctx context.Context, messageId string, product *types.Product) error {
+	ctx, span := go100XClient.startSpan(ctx, "CancelAllOpenOrders", tracing.MessageId(messageId), tracing.Product(product))
+	defer span.End()
+
 	// Generate RPC request.
 	request := &types.WebsocketRequest{
 		JsonRPC: constants.WS_JSON_RPC,
@@ -566,21 +860,86 @@ func (go100XClient *RyskV2WSClient) CancelAllOpenOrders(messageId string, produc
 	}
 
 	// Send RPC request.
-	return utils.SendRPCRequest(go100XClient.RPCConnection, request)
+	return go100XClient.send(ctx, go100XClient.RPCConnection, request)
+}
+
+// SubmitTypedData submits an action whose typed data, as built by `typed_data.Builder`, was signed externally.
+//
+// It calls `SubmitTypedDataCtx` with a background context.
+func (go100XClient *RyskV2WSClient) SubmitTypedData(messageId string, typedData *apitypes.TypedData, signature string) error {
+	return go100XClient.SubmitTypedDataCtx(context.Background(), messageId, typedData, signature)
+}
+
+// SubmitTypedDataCtx submits an action whose typed data, as built by `typed_data.Builder`, was signed externally, e.g.
+// by a hardware wallet through `eth_signTypedData_v4`. Logins, orders, cancels, cancels of all open orders, signer
+// approvals and revocations and withdrawals are sent with their RPC method for the account of the typed data, which
+// may differ from the client account; the session must be logged in as that account, e.g. by submitting its login
+// first. Referrals are only submitted by the REST client.
+//
+// Parameters:
+//   - ctx: Context bounding the write of the already signed request, not the wait for its response.
+//   - messageId: The unique identifier for the message.
+//   - typedData: The typed data of the action, in the domain of the client.
+//   - signature: The external signature of the typed data in hexadecimal format.
+//
+// Returns:
+//   - error: An error if the action is not supported, the typed data or signature is invalid, or sending fails.
+func (go100XClient *RyskV2WSClient) SubmitTypedDataCtx(ctx context.Context, messageId string, typedData *apitypes.TypedData, signature string) error {
+	primaryType := types.PrimaryType(typedData.PrimaryType)
+	ctx, span := go100XClient.startSpan(ctx, "SubmitTypedData", tracing.MessageId(messageId), tracing.PrimaryType(primaryType))
+	defer span.End()
+
+	// Rebuild request params from typed data.
+	method, ok := typedDataMethods[primaryType]
+	if !ok {
+		return fmt.Errorf("primary type %q cannot be submitted over websocket", typedData.PrimaryType)
+	}
+	params, err := typed_data.Params(go100XClient.domain, typedData, signature)
+	if err != nil {
+		return err
+	}
+	var action *audit.Action
+	if go100XClient.auditLog != nil {
+		if action, err = audit.NewAction(go100XClient.domain, primaryType, typedData.Message, signature); err != nil {
+			return err
+		}
+	}
+
+	// Generate RPC request.
+	request := &types.WebsocketRequest{
+		JsonRPC: constants.WS_JSON_RPC,
+		ID:      messageId,
+		Method:  method,
+		Params:  params,
+	}
+
+	// Send RPC request, recording the action.
+	return go100XClient.sendAction(ctx, action, request)
 }
 
 // OrderBook returns bids and asks for a market.
 //
+// It calls `OrderBookCtx` with a background context.
+func (go100XClient *RyskV2WSClient) OrderBook(messageId string, params *types.OrderBookRequest) error {
+	return go100XClient.OrderBookCtx(context.Background(), messageId, params)
+}
+
+// OrderBookCtx returns bids and asks for a market.
+//
 // It retrieves the order book data for the specified market based on the provided parameters.
 // The order book includes bids and asks, which represent buy and sell orders respectively.
 //
 // Parameters:
+//   - ctx: Context bounding the write of the request, not the wait for its response on `RPCConnection`.
 //   - messageId: A unique identifier for the message.
 //   - params: An OrderBookRequest struct pointer containing parameters such as market ID.
 //
 // Returns:
 //   - error: An error if the operation fails, nil otherwise.
-func (go100XClient *RyskV2WSClient) OrderBook(messageId string, params *types.OrderBookRequest) error {
+func (go100XClient *RyskV2WSClient) OrderBookCtx(ctx context.Context, messageId string, params *types.OrderBookRequest) error {
+	ctx, span := go100XClient.startSpan(ctx, "OrderBook", tracing.MessageId(messageId), tracing.Product(params.Product))
+	defer span.End()
+
 	// Generate RPC request.
 	request := &types.WebsocketRequest{
 		JsonRPC: constants.WS_JSON_RPC,
@@ -598,18 +957,29 @@ func (go100XClient *RyskV2WSClient) OrderBook(messageId string, params *types.Or
 	}
 
 	// Send RPC request.
-	return utils.SendRPCRequest(go100XClient.RPCConnection, request)
+	return go100XClient.send(ctx, go100XClient.RPCConnection, request)
 }
 
 // GetPerpetualPosition returns perpetual position for sub account id.
 //
+// It calls `GetPerpetualPositionCtx` with a background context.
+func (go100XClient *RyskV2WSClient) GetPerpetualPosition(messageId string, products []*types.Product) error {
+	return go100XClient.GetPerpetualPositionCtx(context.Background(), messageId, products)
+}
+
+// GetPerpetualPositionCtx returns perpetual position for sub account id.
+//
 // Parameters:
+//   - ctx: Context bounding the write of the request, not the wait for its response on `RPCConnection`.
 //   - messageId: A unique identifier for the message.
 //   - products: A slice of Product pointers representing the products for which to retrieve perpetual positions.
 //
 // Returns:
 //   - error: An error if the operation fails, nil otherwise.
-func (go100XClient *RyskV2WSClient) GetPerpetualPosition(messageId string, products []*types.Product) error {
+func (go100XClient *RyskV2WSClient) GetPerpetualPositionCtx(ctx context.Context, messageId string, products []*types.Product) error {
+	ctx, span := go100XClient.startSpan(ctx, "GetPerpetualPosition", tracing.MessageId(messageId), tracing.Products(products))
+	defer span.End()
+
 	// Create ProductIds slice.
 	var productIds []int64
 	for _, product := range products {
@@ -633,18 +1003,29 @@ func (go100XClient *RyskV2WSClient) GetPerpetualPosition(messageId string, produ
 	}
 
 	// Send RPC request.
-	return utils.SendRPCRequest(go100XClient.RPCConnection, request)
+	return go100XClient.send(ctx, go100XClient.RPCConnection, request)
 }
 
 // GetSpotBalances returns spot balances for sub account id.
 //
+// It calls `GetSpotBalancesCtx` with a background context.
+func (go100XClient *RyskV2WSClient) GetSpotBalances(messageId string, assets []string) error {
+	return go100XClient.GetSpotBalancesCtx(context.Background(), messageId, assets)
+}
+
+// GetSpotBalancesCtx returns spot balances for sub account id.
+//
 // Parameters:
+//   - ctx: Context bounding the write of the request, not the wait for its response on `RPCConnection`.
 //   - messageId: A unique identifier for the message.
 //   - assets: A slice of strings representing the assets for which to retrieve spot balances.
 //
 // Returns:
 //   - error: An error if the operation fails, nil otherwise.
-func (go100XClient *RyskV2WSClient) GetSpotBalances(messageId string, assets []string) error {
+func (go100XClient *RyskV2WSClient) GetSpotBalancesCtx(ctx context.Context, messageId string, assets []string) error {
+	ctx, span := go100XClient.startSpan(ctx, "GetSpotBalances", tracing.MessageId(messageId))
+	defer span.End()
+
 	// Generate RPC request.
 	request := &types.WebsocketRequest{
 		JsonRPC: constants.WS_JSON_RPC,
@@ -662,18 +1043,30 @@ func (go100XClient *RyskV2WSClient) GetSpotBalances(messageId string, assets []s
 	}
 
 	// Send RPC request.
-	return utils.SendRPCRequest(go100XClient.RPCConnection, request)
+	return go100XClient.send(ctx, go100XClient.RPCConnection, request)
 }
 
 // AccountUpdates returns immediate order updates on placement, execution, cancellation,
 // up to date spot balances and perp positions pushed out every 5s.
 //
+// It calls `AccountUpdatesCtx` with a background context.
+func (go100XClient *RyskV2WSClient) AccountUpdates(messageId string) error {
+	return go100XClient.AccountUpdatesCtx(context.Background(), messageId)
+}
+
+// AccountUpdatesCtx returns immediate order updates on placement, execution, cancellation,
+// up to date spot balances and perp positions pushed out every 5s.
+//
 // Parameters:
+//   - ctx: Context bounding the write of the subscription, not the wait for its response or updates.
 //   - messageId: A unique identifier for the message.
 //
 // Returns:
 //   - error: An error if the operation fails, nil otherwise.
-func (go100XClient *RyskV2WSClient) AccountUpdates(messageId string) error {
+func (go100XClient *RyskV2WSClient) AccountUpdatesCtx(ctx context.Context, messageId string) error {
+	ctx, span := go100XClient.startSpan(ctx, "AccountUpdates", tracing.MessageId(messageId))
+	defer span.End()
+
 	// Generate RPC request.
 	request := &types.WebsocketRequest{
 		JsonRPC: constants.WS_JSON_RPC,
@@ -689,45 +1082,70 @@ func (go100XClient *RyskV2WSClient) AccountUpdates(messageId string) error {
 	}
 
 	// Send RPC request.
-	return utils.SendRPCRequest(go100XClient.RPCConnection, request)
+	return go100XClient.send(ctx, go100XClient.RPCConnection, request)
 }
 
 // SubscribeAggregateTrades subscribes to aggregate trade (aggTrade) that represents one or more individual trades.
 // Trades that fill at the same time, from the same taker order.
 //
+// It calls `SubscribeAggregateTradesCtx` with a background context.
+func (go100XClient *RyskV2WSClient) SubscribeAggregateTrades(messageId string, products []*types.Product) error {
+	return go100XClient.SubscribeAggregateTradesCtx(context.Background(), messageId, products)
+}
+
+// SubscribeAggregateTradesCtx subscribes to aggregate trade (aggTrade) that represents one or more individual trades.
+// Trades that fill at the same time, from the same taker order.
+//
 // Parameters:
+//   - ctx: Context bounding the write of the subscription on `StreamConnection`.
 //   - messageId: A unique identifier for the message.
 //   - products: A slice of Product pointers representing the products to subscribe to for aggregate trades.
 //
 // Returns:
 //   - error: An error if the operation fails, nil otherwise.
-func (go100XClient *RyskV2WSClient) SubscribeAggregateTrades(messageId string, products []*types.Product) error {
-	return go100XClient.subscribeUnsubscribeAggregateTrades(messageId, constants.WS_METHOD_MARKET_DATA_STREAMS_SUBSCRIBE, products)
+func (go100XClient *RyskV2WSClient) SubscribeAggregateTradesCtx(ctx context.Context, messageId string, products []*types.Product) error {
+	ctx, span := go100XClient.startSpan(ctx, "SubscribeAggregateTrades", tracing.MessageId(messageId), tracing.Products(products))
+	defer span.End()
+
+	return go100XClient.subscribeUnsubscribeAggregateTrades(ctx, messageId, constants.WS_METHOD_MARKET_DATA_STREAMS_SUBSCRIBE, products)
 }
 
 // UnsubscribeAggregateTrades unsubscribes from aggregate trade (aggTrade) that represents one or more individual trades.
 // Trades that fill at the same time, from the same taker order.
 //
+// It calls `UnsubscribeAggregateTradesCtx` with a background context.
+func (go100XClient *RyskV2WSClient) UnsubscribeAggregateTrades(messageId string, products []*types.Product) error {
+	return go100XClient.UnsubscribeAggregateTradesCtx(context.Background(), messageId, products)
+}
+
+// UnsubscribeAggregateTradesCtx unsubscribes from aggregate trade (aggTrade) that represents one or more individual trades.
+// Trades that fill at the same time, from the same taker order.
+//
 // Parameters:
+//   - ctx: Context bounding the write of the unsubscription on `StreamConnection`.
 //   - messageId: A unique identifier for the message.
 //   - products: A slice of Product pointers representing the products to unsubscribe from for aggregate trades.
 //
 // Returns:
 //   - error: An error if the operation fails, nil otherwise.
-func (go100XClient *RyskV2WSClient) UnsubscribeAggregateTrades(messageId string, products []*types.Product) error {
-	return go100XClient.subscribeUnsubscribeAggregateTrades(messageId, constants.WS_METHOD_MARKET_DATA_STREAMS_UNSUBSCRIBE, products)
+func (go100XClient *RyskV2WSClient) UnsubscribeAggregateTradesCtx(ctx context.Context, messageId string, products []*types.Product) error {
+	ctx, span := go100XClient.startSpan(ctx, "UnsubscribeAggregateTrades", tracing.MessageId(messageId), tracing.Products(products))
+	defer span.End()
+
+	return go100XClient.subscribeUnsubscribeAggregateTrades(ctx, messageId, constants.WS_METHOD_MARKET_DATA_STREAMS_UNSUBSCRIBE, products)
 }
 
 // subscribeUnsubscribeAggregateTrades subscribes or unsubscribes to/from aggregate trade (aggTrade).
 //
 // Parameters:
+//   - ctx: Context bounding the write of the request on `StreamConnection`.
 //   - messageId: A unique identifier for the message.
 //   - method: The WebSocket method (subscribe or unsubscribe).
 //   - products: A slice of Product pointers representing the products to subscribe or unsubscribe for aggregate trades.
 //
 // Returns:
 //   - error: An error if the operation fails, nil otherwise.
-func (go100XClient *RyskV2WSClient) subscribeUnsubscribeAggregateTrades(messageId string, method types.WSMethod, products []*types.Product) error {
+func (go100XClient *RyskV2WSClient) subscribeUnsubscribeAggregateTrades(ctx context.Context, messageId string, method types.WSMethod, products []*types.Product) error {
 	// Create @aggTrade params.
 	var params []string
 	for _, product := range products {
@@ -743,43 +1161,66 @@ func (go100XClient *RyskV2WSClient) subscribeUnsubscribeAggregateTrades(messageI
 	}
 
 	// Send RPC request.
-	return utils.SendRPCRequest(go100XClient.StreamConnection, request)
+	return go100XClient.send(ctx, go100XClient.StreamConnection, request)
 }
 
 // SubscribeSingleTrades subscribes to Trade Streams that push raw trade information; each trade has a unique buyer and seller.
 //
+// It calls `SubscribeSingleTradesCtx` with a background context.
+func (go100XClient *RyskV2WSClient) SubscribeSingleTrades(messageId string, products []*types.Product) error {
+	return go100XClient.SubscribeSingleTradesCtx(context.Background(), messageId, products)
+}
+
+// SubscribeSingleTradesCtx subscribes to Trade Streams that push raw trade information; each trade has a unique buyer and seller.
+//
 // Parameters:
+//   - ctx: Context bounding the write of the subscription on `StreamConnection`.
 //   - messageId: A unique identifier for the message.
 //   - products: A slice of Product pointers representing the products to subscribe to for single trades.
 //
 // Returns:
 //   - error: An error if the operation fails, nil otherwise.
-func (go100XClient *RyskV2WSClient) SubscribeSingleTrades(messageId string, products []*types.Product) error {
-	return go100XClient.subscribeUnsubscribeSingleTrades(messageId, constants.WS_METHOD_MARKET_DATA_STREAMS_SUBSCRIBE, products)
+func (go100XClient *RyskV2WSClient) SubscribeSingleTradesCtx(ctx context.Context, messageId string, products []*types.Product) error {
+	ctx, span := go100XClient.startSpan(ctx, "SubscribeSingleTrades", tracing.MessageId(messageId), tracing.Products(products))
+	defer span.End()
+
+	return go100XClient.subscribeUnsubscribeSingleTrades(ctx, messageId, constants.WS_METHOD_MARKET_DATA_STREAMS_SUBSCRIBE, products)
+}
+
+// UnsubscribeSingleTrades unsubscribes from Trade Streams that push raw trade information; each trade has a unique buyer and seller.
+//
+// It calls `UnubscribeSingleTradesCtx` with a background context.
+func (go100XClient *RyskV2WSClient) UnubscribeSingleTrades(messageId string, products []*types.Product) error {
+	return go100XClient.UnubscribeSingleTradesCtx(context.Background(), messageId, products)
 }
 
 // UnsubscribeSingleTrades unsubscribes from Trade Streams that push raw trade information; each trade has a unique buyer and seller.
 //
 // Parameters:
+//   - ctx: Context bounding the write of the unsubscription on `StreamConnection`.
 //   - messageId: A unique identifier for the message.
 //   - products: A slice of Product pointers representing the products to unsubscribe from for single trades.
 //
 // Returns:
 //   - error: An error if the operation fails, nil otherwise.
-func (go100XClient *RyskV2WSClient) UnubscribeSingleTrades(messageId string, products []*types.Product) error {
-	return go100XClient.subscribeUnsubscribeSingleTrades(messageId, constants.WS_METHOD_MARKET_DATA_STREAMS_UNSUBSCRIBE, products)
+func (go100XClient *RyskV2WSClient) UnubscribeSingleTradesCtx(ctx context.Context, messageId string, products []*types.Product) error {
+	ctx, span := go100XClient.startSpan(ctx, "UnubscribeSingleTrades", tracing.MessageId(messageId), tracing.Products(products))
+	defer span.End()
+
+	return go100XClient.subscribeUnsubscribeSingleTrades(ctx, messageId, constants.WS_METHOD_MARKET_DATA_STREAMS_UNSUBSCRIBE, products)
 }
 
 // subscribeUnsubscribeSingleTrades subscribes or unsubscribes to/from Trade Streams.
 //
 // Parameters:
+//   - ctx: Context bounding the write of the request on `StreamConnection`.
 //   - messageId: A unique identifier for the message.
 //   - method: The WebSocket method (subscribe or unsubscribe).
 //   - products: A slice of Product pointers representing the products to subscribe or unsubscribe for single trades.
 //
 // Returns:
 //   - error: An error if the operation fails, nil otherwise.
-func (go100XClient *RyskV2WSClient) subscribeUnsubscribeSingleTrades(messageId string, method types.WSMethod, products []*types.Product) error {
+func (go100XClient *RyskV2WSClient) subscribeUnsubscribeSingleTrades(ctx context.Context, messageId string, method types.WSMethod, products []*types.Product) error {
 	// Create @trade params.
 	var params []string
 	for _, product := range products {
@@ -795,38 +1236,61 @@ func (go100XClient *RyskV2WSClient) subscribeUnsubscribeSingleTrades(messageId s
 	}
 
 	// Send RPC request.
-	return utils.SendRPCRequest(go100XClient.StreamConnection, request)
+	return go100XClient.send(ctx, go100XClient.StreamConnection, request)
 }
 
 // SubscribeKlineData subscribes to Kline/Candlestick Stream that push updates to the current klines/candlestick every second.
 //
+// It calls `SubscribeKlineDataCtx` with a background context.
+func (go100XClient *RyskV2WSClient) SubscribeKlineData(messageId string, products []*types.Product, intervals []types.Interval) error {
+	return go100XClient.SubscribeKlineDataCtx(context.Background(), messageId, products, intervals)
+}
+
+// SubscribeKlineDataCtx subscribes to Kline/Candlestick Stream that push updates to the current klines/candlestick every second.
+//
 // Parameters:
+//   - ctx: Context bounding the write of the subscription on `StreamConnection`.
 //   - messageId: A unique identifier for the message.
 //   - products: A slice of Product pointers representing the products to subscribe to for Kline/Candlestick data.
 //   - intervals: A slice of Interval values representing the time intervals for the Kline/Candlestick data.
 //
 // Returns:
 //   - error: An error if the operation fails, nil otherwise.
-func (go100XClient *RyskV2WSClient) SubscribeKlineData(messageId string, products []*types.Product, intervals []types.Interval) error {
-	return go100XClient.subscribeUnsubscribeKlineData(messageId, constants.WS_METHOD_MARKET_DATA_STREAMS_SUBSCRIBE, products, intervals)
+func (go100XClient *RyskV2WSClient) SubscribeKlineDataCtx(ctx context.Context, messageId string, products []*types.Product, intervals []types.Interval) error {
+	ctx, span := go100XClient.startSpan(ctx, "SubscribeKlineData", tracing.MessageId(messageId), tracing.Products(products))
+	defer span.End()
+
+	return go100XClient.subscribeUnsubscribeKlineData(ctx, messageId, constants.WS_METHOD_MARKET_DATA_STREAMS_SUBSCRIBE, products, intervals)
 }
 
 // UnsubscribeKlineData unsubscribes from Kline/Candlestick Stream that pushes updates to the current klines/candlestick every second.
 //
+// It calls `UnsubscribeKlineDataCtx` with a background context.
+func (go100XClient *RyskV2WSClient) UnsubscribeKlineData(messageId string, products []*types.Product, intervals []types.Interval) error {
+	return go100XClient.UnsubscribeKlineDataCtx(context.Background(), messageId, products, intervals)
+}
+
+// UnsubscribeKlineDataCtx unsubscribes from Kline/Candlestick Stream that pushes updates to the current klines/candlestick every second.
+//
 // Parameters:
+//   - ctx: Context bounding the write of the unsubscription on `StreamConnection`.
 //   - messageId: A unique identifier for the message.
 //   - products: A slice of Product pointers representing the products to unsubscribe from for Kline/Candlestick data.
 //   - intervals: A slice of Interval values representing the time intervals for the Kline/Candlestick data.
 //
 // Returns:
 //   - error: An error if the operation fails, nil otherwise.
-func (go100XClient *RyskV2WSClient) UnsubscribeKlineData(messageId string, products []*types.Product, intervals []types.Interval) error {
-	return go100XClient.subscribeUnsubscribeKlineData(messageId, constants.WS_METHOD_MARKET_DATA_STREAMS_UNSUBSCRIBE, products, intervals)
+func (go100XClient *RyskV2WSClient) UnsubscribeKlineDataCtx(ctx context.Context, messageId string, products []*types.Product, intervals []types.Interval) error {
+	ctx, span := go100XClient.startSpan(ctx, "UnsubscribeKlineData", tracing.MessageId(messageId), tracing.Products(products))
+	defer span.End()
+
+	return go100XClient.subscribeUnsubscribeKlineData(ctx, messageId, constants.WS_METHOD_MARKET_DATA_STREAMS_UNSUBSCRIBE, products, intervals)
 }
 
 // subscribeUnsubscribeKlineData subscribes or unsubscribes to/from Kline/Candlestick Stream.
 //
 // Parameters:
+//   - ctx: Context bounding the write of the request on `StreamConnection`.
 //   - messageId: A unique identifier for the message.
 //   - method: The WebSocket method (subscribe or unsubscribe).
 //   - products: A slice of Product pointers representing the products to subscribe or unsubscribe for Kline/Candlestick data.
@@ -834,7 +1298,7 @@ func (go100XClient *RyskV2WSClient) UnsubscribeKlineData(messageId string, produ
 //
 // Returns:
 //   - error: An error if the operation fails, nil otherwise.
-func (go100XClient *RyskV2WSClient) subscribeUnsubscribeKlineData(messageId string, method types.WSMethod, products []*types.Product, intervals []types.Interval) error {
+func (go100XClient *RyskV2WSClient) subscribeUnsubscribeKlineData(ctx context.Context, messageId string, method types.WSMethod, products []*types.Product, intervals []types.Interval) error {
 	// Create @klines params.
 	var params []string
 	for _, product := range products {
@@ -852,51 +1316,76 @@ func (go100XClient *RyskV2WSClient) subscribeUnsubscribeKlineData(messageId stri
 	}
 
 	// Send RPC request.
-	return utils.SendRPCRequest(go100XClient.StreamConnection, request)
+	return go100XClient.send(ctx, go100XClient.StreamConnection, request)
 }
 
 // SubscribePartialBookDepth subscribes to top {limit} bids and asks, pushed every second.
 // Prices are rounded by 1e{granularity}.
 //
+// It calls `SubscribePartialBookDepthCtx` with a background context.
+func (go100XClient *RyskV2WSClient) SubscribePartialBookDepth(messageId string, products []*types.Product, limits []types.Limit, granularities []int64) error {
+	return go100XClient.SubscribePartialBookDepthCtx(context.Background(), messageId, products, limits, granularities)
+}
+
+// SubscribePartialBookDepthCtx subscribes to top {limit} bids and asks, pushed every second.
+// Prices are rounded by 1e{granularity}.
+//
 // Parameters:
-// - messageId: A unique identifier for the message.
-// - products: A slice of Product pointers representing the products to subscribe to for partial book depth.
-// - limits: A slice of Limit values representing the depth limits for the book.
-// - granularities: A slice of int64 values representing the price rounding granularity.
+//   - ctx: Context bounding the write of the subscription on `StreamConnection`.
+//   - messageId: A unique identifier for the message.
+//   - products: A slice of Product pointers representing the products to subscribe to for partial book depth.
+//   - limits: A slice of Limit values representing the depth limits for the book.
+//   - granularities: A slice of int64 values representing the price rounding granularity.
 //
 // Returns:
 // - error: An error if the operation fails, nil otherwise.
-func (go100XClient *RyskV2WSClient) SubscribePartialBookDepth(messageId string, products []*types.Product, limits []types.Limit, granularities []int64) error {
-	return go100XClient.subscribeUnsubscribePartialBookDepth(messageId, constants.WS_METHOD_MARKET_DATA_STREAMS_SUBSCRIBE, products, limits, granularities)
+func (go100XClient *RyskV2WSClient) SubscribePartialBookDepthCtx(ctx context.Context, messageId string, products []*types.Product, limits []types.Limit, granularities []int64) error {
+	ctx, span := go100XClient.startSpan(ctx, "SubscribePartialBookDepth", tracing.MessageId(messageId), tracing.Products(products))
+	defer span.End()
+
+	return go100XClient.subscribeUnsubscribePartialBookDepth(ctx, messageId, constants.WS_METHOD_MARKET_DATA_STREAMS_SUBSCRIBE, products, limits, granularities)
 }
 
 // UnsubscribePartialBookDepth unsubscribes from top {limit} bids and asks, pushed every second.
 // Prices are rounded by 1e{granularity}.
 //
+// It calls `UnsubscribePartialBookDepthCtx` with a background context.
+func (go100XClient *RyskV2WSClient) UnsubscribePartialBookDepth(messageId string, products []*types.Product, limits []types.Limit, granularities []int64) error {
+	return go100XClient.UnsubscribePartialBookDepthCtx(context.Background(), messageId, products, limits, granularities)
+}
+
+// UnsubscribePartialBookDepthCtx unsubscribes from top {limit} bids and asks, pushed every second.
+// Prices are rounded by 1e{granularity}.
+//
 // Parameters:
-// - messageId: A unique identifier for the message.
-// - products: A slice of Product pointers representing the products to unsubscribe from for partial book depth.
-// - limits: A slice of Limit values representing the depth limits for the book.
-// - granularities: A slice of int64 values representing the price rounding granularity.
+//   - ctx: Context bounding the write of the unsubscription on `StreamConnection`.
+//   - messageId: A unique identifier for the message.
+//   - products: A slice of Product pointers representing the products to unsubscribe from for partial book depth.
+//   - limits: A slice of Limit values representing the depth limits for the book.
+//   - granularities: A slice of int64 values representing the price rounding granularity.
 //
 // Returns:
 // - error: An error if the operation fails, nil otherwise.
-func (go100XClient *RyskV2WSClient) UnsubscribePartialBookDepth(messageId string, products []*types.Product, limits []types.Limit, granularities []int64) error {
-	return go100XClient.subscribeUnsubscribePartialBookDepth(messageId, constants.WS_METHOD_MARKET_DATA_STREAMS_UNSUBSCRIBE, products, limits, granularities)
+func (go100XClient *RyskV2WSClient) UnsubscribePartialBookDepthCtx(ctx context.Context, messageId string, products []*types.Product, limits []types.Limit, granularities []int64) error {
+	ctx, span := go100XClient.startSpan(ctx, "UnsubscribePartialBookDepth", tracing.MessageId(messageId), tracing.Products(products))
+	defer span.End()
+
+	return go100XClient.subscribeUnsubscribePartialBookDepth(ctx, messageId, constants.WS_METHOD_MARKET_DATA_STREAMS_UNSUBSCRIBE, products, limits, granularities)
 }
 
 // subscribeUnsubscribePartialBookDepth subscribes or unsubscribes to/from partial book depth updates.
 //
 // Parameters:
-// - messageId: A unique identifier for the message.
-// - method: The WebSocket method (subscribe or unsubscribe).
-// - products: A slice of Product pointers representing the products to subscribe or unsubscribe for partial book depth updates.
-// - limits: A slice of Limit values representing the depth limits for the book.
-// - granularities: A slice of int64 values representing the price rounding granularities.
+//   - ctx: Context bounding the write of the request on `StreamConnection`.
+//   - messageId: A unique identifier for the message.
+//   - method: The WebSocket method (subscribe or unsubscribe).
+//   - products: A slice of Product pointers representing the products to subscribe or unsubscribe for partial book depth updates.
+//   - limits: A slice of Limit values representing the depth limits for the book.
+//   - granularities: A slice of int64 values representing the price rounding granularities.
 //
 // Returns:
 // - error: An error if the operation fails, nil otherwise.
-func (go100XClient *RyskV2WSClient) subscribeUnsubscribePartialBookDepth(messageId string, method types.WSMethod, products []*types.Product, limits []types.Limit, granularities []int64) error {
+func (go100XClient *RyskV2WSClient) subscribeUnsubscribePartialBookDepth(ctx context.Context, messageId string, method types.WSMethod, products []*types.Product, limits []types.Limit, granularities []int64) error {
 	// Create @depth params.
 	var params []string
 	for _, product := range products {
@@ -916,47 +1405,74 @@ func (go100XClient *RyskV2WSClient) subscribeUnsubscribePartialBookDepth(message
 	}
 
 	// Send RPC request.
-	return utils.SendRPCRequest(go100XClient.StreamConnection, request)
+	return go100XClient.send(ctx, go100XClient.StreamConnection, request)
 }
 
 // Subscribe24hrPriceChangeStatistics subscribes to 24hr rolling window mini-ticker statistics.
 // These are NOT the statistics of the UTC day, but a 24hr rolling window for the previous 24hrs.
 // Pushed out every 5s.
 //
+// It calls `Subscribe24hrPriceChangeStatisticsCtx` with a background context.
+func (go100XClient *RyskV2WSClient) Subscribe24hrPriceChangeStatistics(messageId string, products []*types.Product) error {
+	return go100XClient.Subscribe24hrPriceChangeStatisticsCtx(context.Background(), messageId, products)
+}
+
+// Subscribe24hrPriceChangeStatisticsCtx subscribes to 24hr rolling window mini-ticker statistics.
+// These are NOT the statistics of the UTC day, but a 24hr rolling window for the previous 24hrs.
+// Pushed out every 5s.
+//
 // Parameters:
-// - messageId: A unique identifier for the message.
-// - products: A slice of Product pointers representing the products to subscribe to for 24hr rolling window mini-ticker statistics.
+//   - ctx: Context bounding the write of the subscription on `StreamConnection`.
+//   - messageId: A unique identifier for the message.
+//   - products: A slice of Product pointers representing the products to subscribe to for 24hr rolling window mini-ticker statistics.
 //
 // Returns:
 // - error: An error if the operation fails, nil otherwise.
-func (go100XClient *RyskV2WSClient) Subscribe24hrPriceChangeStatistics(messageId string, products []*types.Product) error {
-	return go100XClient.subscribeUnsubscribe24hrPriceChangeStatistics(messageId, constants.WS_METHOD_MARKET_DATA_STREAMS_SUBSCRIBE, products)
+func (go100XClient *RyskV2WSClient) Subscribe24hrPriceChangeStatisticsCtx(ctx context.Context, messageId string, products []*types.Product) error {
+	ctx, span := go100XClient.startSpan(ctx, "Subscribe24hrPriceChangeStatistics", tracing.MessageId(messageId), tracing.Products(products))
+	defer span.End()
+
+	return go100XClient.subscribeUnsubscribe24hrPriceChangeStatistics(ctx, messageId, constants.WS_METHOD_MARKET_DATA_STREAMS_SUBSCRIBE, products)
 }
 
 // Unsubscribe24hrPriceChangeStatistics unsubscribes from 24hr rolling window mini-ticker statistics.
 // These are NOT the statistics of the UTC day, but a 24hr rolling window for the previous 24hrs.
 // Pushed out every 5s.
 //
+// It calls `Unsubscribe24hrPriceChangeStatisticsCtx` with a background context.
+func (go100XClient *RyskV2WSClient) Unsubscribe24hrPriceChangeStatistics(messageId string, products []*types.Product) error {
+	return go100XClient.Unsubscribe24hrPriceChangeStatisticsCtx(context.Background(), messageId, products)
+}
+
+// Unsubscribe24hrPriceChangeStatisticsCtx unsubscribes from 24hr rolling window mini-ticker statistics.
+// These are NOT the statistics of the UTC day, but a 24hr rolling window for the previous 24hrs.
+// Pushed out every 5s.
+//
 // Parameters:
-// - messageId: A unique identifier for the message.
-// - products: A slice of Product pointers representing the products to unsubscribe from for 24hr rolling window mini-ticker statistics.
+//   - ctx: Context bounding the write of the unsubscription on `StreamConnection`.
+//   - messageId: A unique identifier for the message.
+//   - products: A slice of Product pointers representing the products to unsubscribe from for 24hr rolling window mini-ticker statistics.
 //
 // Returns:
 // - error: An error if the operation fails, nil otherwise.
-func (go100XClient *RyskV2WSClient) Unsubscribe24hrPriceChangeStatistics(messageId string, products []*types.Product) error {
-	return go100XClient.subscribeUnsubscribe24hrPriceChangeStatistics(messageId, constants.WS_METHOD_MARKET_DATA_STREAMS_UNSUBSCRIBE, products)
+func (go100XClient *RyskV2WSClient) Unsubscribe24hrPriceChangeStatisticsCtx(ctx context.Context, messageId string, products []*types.Product) error {
+	ctx, span := go100XClient.startSpan(ctx, "Unsubscribe24hrPriceChangeStatistics", tracing.MessageId(messageId), tracing.Products(products))
+	defer span.End()
+
+	return go100XClient.subscribeUnsubscribe24hrPriceChangeStatistics(ctx, messageId, constants.WS_METHOD_MARKET_DATA_STREAMS_UNSUBSCRIBE, products)
 }
 
 // subscribeUnsubscribe24hrPriceChangeStatistics subscribes or unsubscribes to/from 24hr rolling window mini-ticker statistics.
 //
 // Parameters:
-// - messageId: A unique identifier for the message.
-// - method: The WebSocket method (subscribe or unsubscribe).
-// - products: A slice of Product pointers representing the products to subscribe or unsubscribe for 24hr rolling window mini-ticker statistics.
+//   - ctx: Context bounding the write of the request on `StreamConnection`.
+//   - messageId: A unique identifier for the message.
+//   - method: The WebSocket method (subscribe or unsubscribe).
+//   - products: A slice of Product pointers representing the products to subscribe or unsubscribe for 24hr rolling window mini-ticker statistics.
 //
 // Returns:
 // - error: An error if the operation fails, nil otherwise.
-func (go100XClient *RyskV2WSClient) subscribeUnsubscribe24hrPriceChangeStatistics(messageId string, method types.WSMethod, products []*types.Product) error {
+func (go100XClient *RyskV2WSClient) subscribeUnsubscribe24hrPriceChangeStatistics(ctx context.Context, messageId string, method types.WSMethod, products []*types.Product) error {
 	// Create @ticker params.
 	var params []string
 	for _, product := range products {
@@ -972,7 +1488,7 @@ func (go100XClient *RyskV2WSClient) subscribeUnsubscribe24hrPriceChangeStatistic
 	}
 
 	// Send RPC request.
-	return utils.SendRPCRequest(go100XClient.StreamConnection, request)
+	return go100XClient.send(ctx, go100XClient.StreamConnection, request)
 }
 
 // ApproveUSDC approves Rysk V2 to spend USDC on your behalf.
@@ -985,34 +1501,14 @@ func (go100XClient *RyskV2WSClient) subscribeUnsubscribe24hrPriceChangeStatistic
 //   - A pointer to a geth_types.Transaction representing the Ethereum transaction.
 //   - An error if the Ethereum transaction fails or encounters an issue.
 func (go100XClient *RyskV2WSClient) ApproveUSDC(ctx context.Context, amount *big.Int) (*geth_types.Transaction, error) {
-	// Parse ABI
-	parsedABI, _ := abi.JSON(strings.NewReader(constants.ERC20_ABI))
+	ctx, span := go100XClient.startSpan(ctx, "ApproveUSDC")
+	defer span.End()
 
-	// Pack transaction data
-	data, _ := parsedABI.Pack("approve", go100XClient.ciao, amount)
-
-	// Get transaction parameters
-	nonce, gasPrice, chainID, gasLimit, err := utils.GetTransactionParams(ctx, go100XClient.EthClient, go100XClient.privateKey, &go100XClient.address, &go100XClient.usdc, &data)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create a new transaction
-	tx := geth_types.NewTransaction(nonce, go100XClient.usdc, big.NewInt(0), gasLimit, gasPrice, data)
-
-	// Sign transaction
-	signedTx, _ := geth_types.SignTx(tx, geth_types.NewEIP155Signer(chainID), go100XClient.privateKey)
-
-	// Send transaction
-	err = go100XClient.EthClient.SendTransaction(ctx, signedTx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send transaction: %v", err)
-	}
-
-	return signedTx, nil
+	return go100XClient.account().ApproveUSDC(ctx, amount)
 }
 
 // DepositUSDC sends USDC to Rysk V2.
+// The CIAO contract must already be allowed to spend `amount`, see `ApproveUSDC` or `Deposit`.
 //
 // Parameters:
 //   - ctx: The context.Context for the Ethereum transaction.
@@ -1022,34 +1518,50 @@ func (go100XClient *RyskV2WSClient) ApproveUSDC(ctx context.Context, amount *big
 //   - A pointer to a geth_types.Transaction representing the Ethereum transaction.
 //   - An error if the Ethereum transaction fails or encounters an issue.
 func (go100XClient *RyskV2WSClient) DepositUSDC(ctx context.Context, amount *big.Int) (*geth_types.Transaction, error) {
-	// Parse ABI
-	parsedABI, _ := abi.JSON(strings.NewReader(constants.CIAO_ABI))
-
-	// Pack transaction data
-	data, _ := parsedABI.Pack("deposit", go100XClient.address, uint8(go100XClient.SubAccountId), amount, go100XClient.usdc)
+	ctx, span := go100XClient.startSpan(ctx, "DepositUSDC")
+	defer span.End()
 
-	// Get transaction parameters
-	nonce, gasPrice, chainID, gasLimit, err := utils.GetTransactionParams(ctx, go100XClient.EthClient, go100XClient.privateKey, &go100XClient.address, &go100XClient.ciao, &data)
-	if err != nil {
-		return nil, err
-	}
+	return go100XClient.account().DepositUSDC(ctx, amount)
+}
 
-	// Create a new transaction
-	tx := geth_types.NewTransaction(nonce, go100XClient.ciao, big.NewInt(0), gasLimit, gasPrice, data)
+// Deposit deposits USDC to Rysk V2, approving the CIAO contract first only when needed.
+//
+// The workflow checks the USDC balance and the CIAO minimum deposit amount, approves
+// the missing allowance according to `ApprovalMode`, deposits and waits for each receipt.
+//
+// Parameters:
+//   - ctx: The context.Context for the Ethereum transactions.
+//   - amount: The amount of USDC tokens to deposit, specified as a *big.Int.
+//
+// Returns:
+//   - A pointer to a types.DepositResult holding the transactions and receipts.
+//   - An error if a check fails or a transaction fails or reverts, CIAO reverts match `utils.ErrBalanceInsufficient` and friends with `errors.Is`.
+func (go100XClient *RyskV2WSClient) Deposit(ctx context.Context, amount *big.Int) (*types.DepositResult, error) {
+	ctx, span := go100XClient.startSpan(ctx, "Deposit")
+	defer span.End()
 
-	// Sign transaction
-	signedTx, _ := geth_types.SignTx(tx, geth_types.NewEIP155Signer(chainID), go100XClient.privateKey)
+	return go100XClient.account().Deposit(ctx, go100XClient, amount)
+}
 
-	// Send transaction
-	err = go100XClient.EthClient.SendTransaction(ctx, signedTx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send transaction: %v", err)
+// account returns the on-chain account of the client, from its current settings.
+func (go100XClient *RyskV2WSClient) account() *tx_manager.Account {
+	return &tx_manager.Account{
+		EthClient:          go100XClient.EthClient,
+		PrivateKey:         go100XClient.privateKey,
+		Address:            go100XClient.address,
+		SubAccountId:       go100XClient.SubAccountId,
+		CIAO:               go100XClient.ciao,
+		USDC:               go100XClient.usdc,
+		GasConfiguration:   go100XClient.GasConfiguration,
+		TransactionManager: go100XClient.TransactionManager,
+		ApprovalMode:       go100XClient.ApprovalMode,
+		Metrics:            go100XClient.metrics,
+		Logger:             go100XClient.logger,
 	}
-
-	return signedTx, nil
 }
 
 // WaitTransaction waits for a transaction to be mined and returns its receipt.
+// With a transaction manager, it waits for the configured confirmations and follows replacements.
 //
 // Parameters:
 //   - ctx: The context.Context for the Ethereum transaction.
@@ -1059,11 +1571,164 @@ func (go100XClient *RyskV2WSClient) DepositUSDC(ctx context.Context, amount *big
 //   - A pointer to a geth_types.Receipt containing the transaction receipt once the transaction is mined.
 //   - An error if the transaction fails to be mined or encounters an issue.
 func (go100XClient *RyskV2WSClient) WaitTransaction(ctx context.Context, transaction *geth_types.Transaction) (*geth_types.Receipt, error) {
-	receipt, err := bind.WaitMined(ctx, go100XClient.EthClient, transaction)
+	ctx, span := go100XClient.startSpan(ctx, "WaitTransaction")
+	defer span.End()
+
+	return go100XClient.account().WaitTransaction(ctx, transaction)
+}
+
+// RPCReader returns the reader of the RPC connection. When metrics, tracing, debug logging or an audit log are
+// configured, it records the round-trip latency, ends the response spans and audits the responses of the requests whose
+// responses it reads, and logs its messages, so readers of the RPC connection should use it instead.
+//
+// Returns:
+//   - The RPC connection, or a reader recording its messages.
+func (go100XClient *RyskV2WSClient) RPCReader() types.IWSReader {
+	var reader types.IWSReader = go100XClient.RPCConnection
+	if go100XClient.log().Enabled(context.Background(), slog.LevelDebug) {
+		reader = logging.NewReader(reader, go100XClient.log(), metrics.CONNECTION_RPC)
+	}
+	if go100XClient.tracer != nil {
+		reader = tracing.NewReader(reader, go100XClient.tracer)
+	}
+	if go100XClient.metrics != nil {
+		reader = metrics.NewRPCReader(reader, go100XClient.metrics)
+	}
+	if go100XClient.auditLog != nil {
+		reader = audit.NewReader(reader, go100XClient.auditLog)
+	}
+	return reader
+}
+
+// StreamReader returns the reader of the stream connection. When metrics, tracing or debug logging are configured, it
+// records the topic of the messages it reads, ends the response spans of subscriptions and logs its messages, so readers
+// of the stream connection should use it instead.
+//
+// Returns:
+//   - The stream connection, or a reader recording its messages.
+func (go100XClient *RyskV2WSClient) StreamReader() types.IWSReader {
+	var reader types.IWSReader = go100XClient.StreamConnection
+	if go100XClient.log().Enabled(context.Background(), slog.LevelDebug) {
+		reader = logging.NewReader(reader, go100XClient.log(), metrics.CONNECTION_STREAM)
+	}
+	if go100XClient.tracer != nil {
+		reader = tracing.NewReader(reader, go100XClient.tracer)
+	}
+	if go100XClient.metrics != nil {
+		reader = metrics.NewStreamReader(reader, go100XClient.metrics)
+	}
+	return reader
+}
+
+// send waits for the rate limiter budget of a request, then sends it over a connection within a span, timing and
+// tracing it until its response.
+func (go100XClient *RyskV2WSClient) send(ctx context.Context, connection types.IWSConnection, request *types.WebsocketRequest) error {
+	if go100XClient.rateLimiter != nil {
+		if err := go100XClient.rateLimiter.Wait(ctx, ratelimit.ClassifyWSMethod(request.Method)); err != nil {
+			return err
+		}
+	}
+	go100XClient.logRequest(ctx, request)
+	go100XClient.metrics.RPCSent(request.ID, request.Method)
+	go100XClient.tracer.RPCSent(ctx, request.ID, request.Method)
+	_, span := go100XClient.tracer.Start(
+		ctx,
+		tracing.SPAN_WEBSOCKET_WRITE,
+		tracing.MessageId(request.ID),
+		tracing.ATTRIBUTE_METHOD.String(string(request.Method)),
+	)
+	unlock := go100XClient.lockWrite(connection)
+	err := utils.SendRPCRequestCtx(ctx, connection, request)
+	unlock()
+	tracing.End(span, err)
 	if err != nil {
-		return nil, err
+		go100XClient.log().WarnContext(ctx, "websocket request failed", "id", request.ID, "method", request.Method, "error", err)
+		go100XClient.metrics.RPCFailed(request.ID)
+		go100XClient.tracer.RPCFailed(request.ID, err)
+		return err
+	}
+	return nil
+}
+
+// lockWrite locks writes to a connection of the client, returning the function unlocking them.
+// Clients not created by `NewRyskV2WSClient` have no locks and do not serialise writes.
+func (go100XClient *RyskV2WSClient) lockWrite(connection types.IWSConnection) func() {
+	if go100XClient.writeLocks == nil {
+		return func() {}
+	}
+	mutex := &go100XClient.writeLocks.rpc
+	if go100XClient.StreamConnection != nil && connection == types.IWSConnection(go100XClient.StreamConnection) {
+		mutex = &go100XClient.writeLocks.stream
+	}
+	mutex.Lock()
+	return mutex.Unlock
+}
+
+// sendAction sends the RPC request of a signed action like `send`, recording the action before sending it when
+// auditing, its response being recorded by `RPCReader`. The action is not sent when it cannot be recorded.
+func (go100XClient *RyskV2WSClient) sendAction(ctx context.Context, action *audit.Action, request *types.WebsocketRequest) error {
+	if action == nil {
+		return go100XClient.send(ctx, go100XClient.RPCConnection, request)
+	}
+	if err := go100XClient.auditLog.RPCRequest(action, request); err != nil {
+		return err
+	}
+	err := go100XClient.send(ctx, go100XClient.RPCConnection, request)
+	if err != nil {
+		go100XClient.auditLog.RPCFailed(request.ID, err)
+	}
+	return err
+}
+
+// logRequest logs a websocket request with redacted params, subscriptions at info level and others at debug level.
+func (go100XClient *RyskV2WSClient) logRequest(ctx context.Context, request *types.WebsocketRequest) {
+	level := slog.LevelDebug
+	message := "websocket request"
+	if request.Method == constants.WS_METHOD_MARKET_DATA_STREAMS_SUBSCRIBE || request.Method == constants.WS_METHOD_MARKET_DATA_STREAMS_UNSUBSCRIBE {
+		level = slog.LevelInfo
+		message = "websocket subscription"
+	}
+	if !go100XClient.log().Enabled(ctx, level) {
+		return
+	}
+	params, _ := json.Marshal(request.Params)
+	go100XClient.log().Log(ctx, level, message, "id", request.ID, "method", request.Method, "params", logging.Redact(params))
+}
+
+// log returns the logger of the client, discarding records when not configured.
+func (go100XClient *RyskV2WSClient) log() *slog.Logger {
+	return logging.OrDiscard(go100XClient.logger)
+}
+
+// signMessage signs an EIP-712 message of the account within a span, recording the signing latency.
+func (go100XClient *RyskV2WSClient) signMessage(ctx context.Context, primaryType types.PrimaryType, message interface{}) (string, error) {
+	ctx, span := go100XClient.tracer.Start(ctx, tracing.SPAN_SIGN, tracing.PrimaryType(primaryType))
+	start := time.Now()
+	signature, err := utils.SignMessageCtx(ctx, go100XClient.domain, go100XClient.privateKeyString, primaryType, message)
+	if err == nil {
+		go100XClient.metrics.ObserveSigning(primaryType, time.Since(start))
 	}
-	return receipt, nil
+	tracing.End(span, err)
+	return signature, err
+}
+
+// signAction signs the EIP-712 message of an action like `signMessage`, returning the action to record when auditing.
+func (go100XClient *RyskV2WSClient) signAction(ctx context.Context, primaryType types.PrimaryType, message interface{}) (string, *audit.Action, error) {
+	signature, err := go100XClient.signMessage(ctx, primaryType, message)
+	if err != nil || go100XClient.auditLog == nil {
+		return signature, nil, err
+	}
+	action, err := audit.NewAction(go100XClient.domain, primaryType, message, signature)
+	if err != nil {
+		return "", nil, err
+	}
+	return signature, action, nil
+}
+
+// startSpan starts the span of a client method, recording the sub-account.
+func (go100XClient *RyskV2WSClient) startSpan(ctx context.Context, method string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	attributes = append(attributes, tracing.SubAccountId(go100XClient.SubAccountId))
+	return go100XClient.tracer.Start(ctx, "RyskV2WSClient."+method, attributes...)
 }
 
 // addReferee adds a referee to author referral code.
@@ -4,20 +4,28 @@
 package ws_client
 
 import (
+	"bytes"
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"math/big"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"strconv"
 	"testing"
 	"time"
 
+	geth_types "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/joho/godotenv"
 	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/ryskfake"
 	"github.com/rysk-finance/v2_client_go/types"
+	"github.com/rysk-finance/v2_client_go/utils/mocks"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -27,9 +35,49 @@ type WsClientIntegrationTestSuite struct {
 	PrivateKeys    string
 	RpcUrl         string
 	RyskV2WSClient *RyskV2WSClient
+	PriceUrl       string
+	Server         *ryskfake.Server // Server is the fake exchange the suite runs against when `RYSK_FAKE` is set.
+	PriceServer    *httptest.Server // PriceServer serves a fixed ETH price when `RYSK_FAKE` is set.
 }
 
 func (s *WsClientIntegrationTestSuite) SetupSuite() {
+	s.PriceUrl = "https://api.coinbase.com/v2/exchange-rates?currency=ETH"
+
+	// Run against a fake exchange and chain, without network, when `RYSK_FAKE` is set.
+	if os.Getenv("RYSK_FAKE") != "" {
+		server, err := ryskfake.NewServer(&ryskfake.ServerConfiguration{})
+		require.NoError(s.T(), err)
+		privateKey, err := crypto.GenerateKey()
+		require.NoError(s.T(), err)
+		wsClient, err := NewRyskV2WSClient(&RyskV2WSClientConfiguration{
+			Env:          constants.ENVIRONMENT_TESTNET,
+			PrivateKey:   hex.EncodeToString(crypto.FromECDSA(privateKey)),
+			RpcUrl:       server.URL(),
+			BaseUrl:      server.URL(),
+			WSRpcUrl:     server.RPCURL(),
+			WSStreamUrl:  server.StreamURL(),
+			SubAccountId: 1,
+		})
+		require.NoError(s.T(), err)
+		server.Credit(wsClient.address, 1, wsClient.usdc, new(big.Int).Mul(big.NewInt(1000), constants.E18))
+		mockEthClient := new(mocks.MockEthClient)
+		mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(0), nil)
+		mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+		mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
+		mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(421614), nil)
+		mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
+		mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+		mockEthClient.On("TransactionReceipt", mock.Anything, mock.Anything).Return(&geth_types.Receipt{Status: geth_types.ReceiptStatusSuccessful}, nil)
+		wsClient.EthClient = mockEthClient
+		s.PriceServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Write([]byte(`{"data":{"currency":"ETH","rates":{"USD":"2000.5"}}}`))
+		}))
+		s.PriceUrl = s.PriceServer.URL
+		s.Server = server
+		s.RyskV2WSClient = wsClient
+		return
+	}
+
 	if err := godotenv.Load(); err != nil {
 		fmt.Println("Error loading .env file:", err)
 		return
@@ -45,6 +93,19 @@ func (s *WsClientIntegrationTestSuite) SetupSuite() {
 func (s *WsClientIntegrationTestSuite) TearDownSuite() {
 	s.RyskV2WSClient.RPCConnection.Close()
 	s.RyskV2WSClient.StreamConnection.Close()
+	if s.Server != nil {
+		s.Server.Close()
+		s.PriceServer.Close()
+	}
+}
+
+// requireAcknowledged requires the exchange to acknowledge a request with `expected`. The fake exchange
+// answers with the affected resource or nothing instead, so only the live exchange is checked.
+func (s *WsClientIntegrationTestSuite) requireAcknowledged(expected string, result interface{}) {
+	if s.Server != nil {
+		return
+	}
+	require.Equal(s.T(), expected, result)
 }
 
 func TestRunSuiteIntegration_WsClientIntegrationTestSuite(t *testing.T) {
@@ -137,7 +198,7 @@ func (s *WsClientIntegrationTestSuite) TestIntegration_Login() {
 			require.True(s.T(), response.Success)
 			require.Nil(s.T(), response.Error)
 			require.Equal(s.T(), "2.0", string(response.JsonRPC))
-			require.Equal(s.T(), "OK", response.Result)
+			s.requireAcknowledged("OK", response.Result)
 			fmt.Println(response.Result)
 			break
 		}
@@ -216,7 +277,7 @@ func (s *WsClientIntegrationTestSuite) TestIntegration_ApproveSigner() {
 			require.True(s.T(), response.Success)
 			require.Nil(s.T(), response.Error)
 			require.Equal(s.T(), "2.0", string(response.JsonRPC))
-			require.Equal(s.T(), "OK", response.Result)
+			s.requireAcknowledged("OK", response.Result)
 			fmt.Println(response.Result)
 			break
 		}
@@ -244,7 +305,7 @@ func (s *WsClientIntegrationTestSuite) TestIntegration_RevokeSigner() {
 			require.True(s.T(), response.Success)
 			require.Nil(s.T(), response.Error)
 			require.Equal(s.T(), "2.0", string(response.JsonRPC))
-			require.Equal(s.T(), "OK", response.Result)
+			s.requireAcknowledged("OK", response.Result)
 			fmt.Println(response.Result)
 			break
 		}
@@ -258,7 +319,7 @@ func (s *WsClientIntegrationTestSuite) TestIntegration_NewOrder() {
 	// get market price
 	request, err := http.NewRequest(
 		http.MethodGet,
-		"https://api.coinbase.com/v2/exchange-rates?currency=ETH",
+		s.PriceUrl,
 		nil,
 	)
 	require.NoError(s.T(), err)
@@ -399,7 +460,7 @@ func (s *WsClientIntegrationTestSuite) TestIntegration_CancelOrder() {
 	// get market price
 	request, err := http.NewRequest(
 		http.MethodGet,
-		"https://api.coinbase.com/v2/exchange-rates?currency=ETH",
+		s.PriceUrl,
 		nil,
 	)
 	require.NoError(s.T(), err)
@@ -534,7 +595,7 @@ func (s *WsClientIntegrationTestSuite) TestIntegration_CancelOrder() {
 			require.True(s.T(), response.Success)
 			require.Nil(s.T(), response.Error)
 			require.Equal(s.T(), "2.0", string(response.JsonRPC))
-			require.Equal(s.T(), "OK", response.Result)
+			s.requireAcknowledged("OK", response.Result)
 			fmt.Println(response.Result)
 			break
 		}
@@ -559,7 +620,7 @@ func (s *WsClientIntegrationTestSuite) TestIntegration_CancelAllOpenOrders() {
 			require.True(s.T(), response.Success)
 			require.Nil(s.T(), response.Error)
 			require.Equal(s.T(), "2.0", string(response.JsonRPC))
-			require.Equal(s.T(), "OK", response.Result)
+			s.requireAcknowledged("OK", response.Result)
 			fmt.Println(response.Result)
 			break
 		}
@@ -617,7 +678,10 @@ func (s *WsClientIntegrationTestSuite) TestIntegration_GetPerpetualPosition() {
 			require.True(s.T(), response.Success)
 			require.Nil(s.T(), response.Error)
 			require.Equal(s.T(), "2.0", string(response.JsonRPC))
-			require.NotEmpty(s.T(), response.Result)
+			// The sub-account never trades on the fake exchange, so it holds no positions there.
+			if s.Server == nil {
+				require.NotEmpty(s.T(), response.Result)
+			}
 			fmt.Println(response.Result)
 			break
 		}
@@ -667,7 +731,7 @@ func (s *WsClientIntegrationTestSuite) TestIntegration_AccountUpdates() {
 			require.True(s.T(), response.Success)
 			require.Nil(s.T(), response.Error)
 			require.Equal(s.T(), "2.0", string(response.JsonRPC))
-			require.Equal(s.T(), "Subscribed to updates", response.Result)
+			s.requireAcknowledged("Subscribed to updates", response.Result)
 			break
 		}
 	}
@@ -691,7 +755,7 @@ func (s *WsClientIntegrationTestSuite) TestIntegration_SubscribeAggregateTrades(
 			require.True(s.T(), response.Success)
 			require.Nil(s.T(), response.Error)
 			require.Equal(s.T(), "2.0", string(response.JsonRPC))
-			require.Equal(s.T(), "Subscribed", response.Result)
+			s.requireAcknowledged("Subscribed", response.Result)
 			break
 		}
 	}
@@ -715,7 +779,7 @@ func (s *WsClientIntegrationTestSuite) TestIntegration_UnsubscribeAggregateTrade
 			require.True(s.T(), response.Success)
 			require.Nil(s.T(), response.Error)
 			require.Equal(s.T(), "2.0", string(response.JsonRPC))
-			require.Equal(s.T(), "Unsubscribed", response.Result)
+			s.requireAcknowledged("Unsubscribed", response.Result)
 			break
 		}
 	}
@@ -739,7 +803,7 @@ func (s *WsClientIntegrationTestSuite) TestIntegration_SubscribeSingleTrade() {
 			require.True(s.T(), response.Success)
 			require.Nil(s.T(), response.Error)
 			require.Equal(s.T(), "2.0", string(response.JsonRPC))
-			require.Equal(s.T(), "Subscribed", response.Result)
+			s.requireAcknowledged("Subscribed", response.Result)
 			break
 		}
 	}
@@ -763,7 +827,7 @@ func (s *WsClientIntegrationTestSuite) TestIntegration_UnsubscribeSingleTrade()
 			require.True(s.T(), response.Success)
 			require.Nil(s.T(), response.Error)
 			require.Equal(s.T(), "2.0", string(response.JsonRPC))
-			require.Equal(s.T(), "Unsubscribed", response.Result)
+			s.requireAcknowledged("Unsubscribed", response.Result)
 			break
 		}
 	}
@@ -791,7 +855,7 @@ func (s *WsClientIntegrationTestSuite) TestIntegration_SubscribeKlineData() {
 			require.True(s.T(), response.Success)
 			require.Nil(s.T(), response.Error)
 			require.Equal(s.T(), "2.0", string(response.JsonRPC))
-			require.Equal(s.T(), "Subscribed", response.Result)
+			s.requireAcknowledged("Subscribed", response.Result)
 			break
 		}
 	}
@@ -819,7 +883,7 @@ func (s *WsClientIntegrationTestSuite) TestIntegration_UnsubscribeKlineData() {
 			require.True(s.T(), response.Success)
 			require.Nil(s.T(), response.Error)
 			require.Equal(s.T(), "2.0", string(response.JsonRPC))
-			require.Equal(s.T(), "Unsubscribed", response.Result)
+			s.requireAcknowledged("Unsubscribed", response.Result)
 			break
 		}
 	}
@@ -855,7 +919,7 @@ func (s *WsClientIntegrationTestSuite) TestIntegration_SubscribePartialBookDepth
 			require.True(s.T(), response.Success)
 			require.Nil(s.T(), response.Error)
 			require.Equal(s.T(), "2.0", string(response.JsonRPC))
-			require.Equal(s.T(), "Subscribed", response.Result)
+			s.requireAcknowledged("Subscribed", response.Result)
 			break
 		}
 	}
@@ -891,7 +955,7 @@ func (s *WsClientIntegrationTestSuite) TestIntegration_UnsubscribePartialBookDep
 			require.True(s.T(), response.Success)
 			require.Nil(s.T(), response.Error)
 			require.Equal(s.T(), "2.0", string(response.JsonRPC))
-			require.Equal(s.T(), "Unsubscribed", response.Result)
+			s.requireAcknowledged("Unsubscribed", response.Result)
 			break
 		}
 	}
@@ -921,7 +985,7 @@ func (s *WsClientIntegrationTestSuite) TestIntegration_Subscribe24hrPriceChangeS
 			require.True(s.T(), response.Success)
 			require.Nil(s.T(), response.Error)
 			require.Equal(s.T(), "2.0", string(response.JsonRPC))
-			require.Equal(s.T(), "Subscribed", response.Result)
+			s.requireAcknowledged("Subscribed", response.Result)
 			break
 		}
 	}
@@ -951,7 +1015,7 @@ func (s *WsClientIntegrationTestSuite) TestIntegration_Unsubscribe24hrPriceChang
 			require.True(s.T(), response.Success)
 			require.Nil(s.T(), response.Error)
 			require.Equal(s.T(), "2.0", string(response.JsonRPC))
-			require.Equal(s.T(), "Unsubscribed", response.Result)
+			s.requireAcknowledged("Unsubscribed", response.Result)
 			break
 		}
 	}
@@ -995,10 +1059,11 @@ func (s *WsClientIntegrationTestSuite) TestIntegration_addReferee() {
 }
 
 func verifyValidJSONResponse(t *testing.T, response *http.Response) {
-	// Read response
-	defer response.Body.Close()
+	// Read response, keeping the body readable by the caller
 	bytesBody, err := io.ReadAll(response.Body)
+	response.Body.Close()
 	require.NoError(t, err)
+	response.Body = io.NopCloser(bytes.NewReader(bytesBody))
 
 	// Check if res is valid JSON by trying to unmarshal it
 	var data interface{}
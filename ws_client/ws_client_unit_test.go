@@ -4,6 +4,7 @@
 package ws_client
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"encoding/json"
@@ -18,11 +19,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
 	geth_types "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/gorilla/websocket"
 	"github.com/joho/godotenv"
 	"github.com/rysk-finance/v2_client_go/constants"
+	"github.com/rysk-finance/v2_client_go/tx_manager"
 	"github.com/rysk-finance/v2_client_go/types"
 	"github.com/rysk-finance/v2_client_go/utils"
 	"github.com/rysk-finance/v2_client_go/utils/mocks"
@@ -592,6 +596,72 @@ func (s *WSClientUnitTestSuite) TestUnit_RevokeSigner_BadAddress() {
 	require.Error(s.T(), err)
 }
 
+func (s *WSClientUnitTestSuite) TestUnit_Withdraw() {
+	done := make(chan struct{})
+	nonce := time.Now().UnixMicro()
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var upgrader = websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+		}
+		conn, _ := upgrader.Upgrade(w, r, nil)
+		defer conn.Close()
+		for {
+			_, message, _ := conn.ReadMessage()
+
+			var requestBody struct {
+				JsonRPC string          `json:"jsonrpc"`
+				Id      string          `json:"id"`
+				Method  string          `json:"method"`
+				Params  json.RawMessage `json:"params"`
+			}
+			err := json.Unmarshal(message, &requestBody)
+			require.NoError(s.T(), err)
+			require.Equal(s.T(), "2.0", requestBody.JsonRPC)
+			require.Equal(s.T(), "69420", requestBody.Id)
+			require.Equal(s.T(), string(constants.WS_METHOD_WITHDRAW), requestBody.Method)
+			require.NotEmpty(s.T(), requestBody.Params)
+
+			var params struct {
+				Account      string `json:"account"`
+				SubAccountId int64  `json:"subAccountId"`
+				Asset        string `json:"asset"`
+				Quantity     string `json:"quantity"`
+				Nonce        int64  `json:"nonce"`
+				Signature    string `json:"signature"`
+			}
+			err = json.Unmarshal(requestBody.Params, &params)
+			require.NoError(s.T(), err)
+			require.Equal(s.T(), s.Address, params.Account)
+			require.Equal(s.T(), int64(1), params.SubAccountId)
+			require.Equal(s.T(), constants.USDC_ADDRESS[constants.ENVIRONMENT_TESTNET], params.Asset)
+			require.Equal(s.T(), "1000000000000000000", params.Quantity)
+			require.Equal(s.T(), nonce, params.Nonce)
+			require.NotEmpty(s.T(), params.Signature)
+			done <- struct{}{}
+			break
+		}
+	}
+	mockHttpServer := httptest.NewServer(http.HandlerFunc(handler))
+	url := strings.Replace(mockHttpServer.URL, "http", "ws", 1)
+	defer mockHttpServer.Close()
+	rpcWebsocket, _, err := websocket.DefaultDialer.DialContext(
+		context.Background(),
+		url,
+		http.Header{},
+	)
+	require.NoError(s.T(), err)
+	s.RyskV2WSClient.RPCConnection = rpcWebsocket
+	s.RyskV2WSClient.rpcUrl = url
+
+	err = s.RyskV2WSClient.Withdraw("69420", &types.WithdrawRequest{
+		Quantity: "1000000000000000000",
+		Nonce:    nonce,
+	})
+	require.NoError(s.T(), err)
+	<-done
+}
+
 func (s *WSClientUnitTestSuite) TestUnit_NewOrder() {
 	done := make(chan struct{})
 	nonce := time.Now().UnixMicro()
@@ -2022,7 +2092,8 @@ func (s *WSClientUnitTestSuite) TestUnit_ApproveUSDC() {
 	mockEthClient := new(mocks.MockEthClient)
 	s.RyskV2WSClient.EthClient = mockEthClient
 	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
-	mockEthClient.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
 	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
 	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
 	mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
@@ -2036,7 +2107,8 @@ func (s *WSClientUnitTestSuite) TestUnit_ApproveUSDC_ErrorGettingParameters() {
 	mockEthClient := new(mocks.MockEthClient)
 	s.RyskV2WSClient.EthClient = mockEthClient
 	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
-	mockEthClient.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
 	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
 	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), fmt.Errorf("error getting parameters"))
 
@@ -2045,11 +2117,68 @@ func (s *WSClientUnitTestSuite) TestUnit_ApproveUSDC_ErrorGettingParameters() {
 	require.Nil(s.T(), transaction)
 }
 
+func (s *WSClientUnitTestSuite) TestUnit_ApproveUSDC_DynamicFeeTransaction() {
+	mockEthClient := new(mocks.MockEthClient)
+	s.RyskV2WSClient.EthClient = mockEthClient
+	s.RyskV2WSClient.GasConfiguration = &types.GasConfiguration{GasLimitBuffer: 20}
+	defer func() { s.RyskV2WSClient.GasConfiguration = nil }()
+	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(100), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000)}, nil)
+	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
+	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
+	mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+
+	transaction, err := s.RyskV2WSClient.ApproveUSDC(context.Background(), big.NewInt(1000))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), uint8(geth_types.DynamicFeeTxType), transaction.Type())
+	require.Equal(s.T(), big.NewInt(100), transaction.GasTipCap())
+	require.Equal(s.T(), big.NewInt(2100), transaction.GasFeeCap())
+	require.Equal(s.T(), uint64(25200), transaction.Gas())
+}
+
+func (s *WSClientUnitTestSuite) TestUnit_ApproveUSDC_TransactionManager() {
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(7), nil).Once()
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(100), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000)}, nil)
+	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
+	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
+	mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+	transactionManager, err := tx_manager.NewTransactionManager(&tx_manager.TransactionManagerConfiguration{
+		EthClient:  mockEthClient,
+		PrivateKey: s.PrivateKey,
+	})
+	require.NoError(s.T(), err)
+	s.RyskV2WSClient.TransactionManager = transactionManager
+	defer func() { s.RyskV2WSClient.TransactionManager = nil }()
+
+	firstTransaction, err := s.RyskV2WSClient.ApproveUSDC(context.Background(), big.NewInt(1000))
+	require.NoError(s.T(), err)
+	secondTransaction, err := s.RyskV2WSClient.ApproveUSDC(context.Background(), big.NewInt(1000))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), uint64(7), firstTransaction.Nonce())
+	require.Equal(s.T(), uint64(8), secondTransaction.Nonce())
+	mockEthClient.AssertNumberOfCalls(s.T(), "PendingNonceAt", 1)
+}
+
+func (s *WSClientUnitTestSuite) TestUnit_ApproveUSDC_ErrorGasFees() {
+	mockEthClient := new(mocks.MockEthClient)
+	s.RyskV2WSClient.EthClient = mockEthClient
+	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return((*big.Int)(nil), fmt.Errorf("failed to get gas tip cap"))
+
+	transaction, err := s.RyskV2WSClient.ApproveUSDC(context.Background(), big.NewInt(1000))
+	require.Error(s.T(), err)
+	require.Nil(s.T(), transaction)
+}
+
 func (s *WSClientUnitTestSuite) TestUnit_ApproveUSDC_ErrorSendTransaction() {
 	mockEthClient := new(mocks.MockEthClient)
 	s.RyskV2WSClient.EthClient = mockEthClient
 	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
-	mockEthClient.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
 	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
 	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
 	mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(fmt.Errorf("failed to send transaction"))
@@ -2063,7 +2192,8 @@ func (s *WSClientUnitTestSuite) TestUnit_DepositUSDC() {
 	mockEthClient := new(mocks.MockEthClient)
 	s.RyskV2WSClient.EthClient = mockEthClient
 	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
-	mockEthClient.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
 	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
 	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
 	mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
@@ -2084,7 +2214,8 @@ func (s *WSClientUnitTestSuite) TestUnit_DepositUSDC_ErrorGettingParameters() {
 	mockEthClient := new(mocks.MockEthClient)
 	s.RyskV2WSClient.EthClient = mockEthClient
 	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
-	mockEthClient.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
 	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
 	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), fmt.Errorf("error getting parameters"))
 
@@ -2097,7 +2228,8 @@ func (s *WSClientUnitTestSuite) TestUnit_DepositUSDC_ErrorSendTransaction() {
 	mockEthClient := new(mocks.MockEthClient)
 	s.RyskV2WSClient.EthClient = mockEthClient
 	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
-	mockEthClient.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
 	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
 	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
 	mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(fmt.Errorf("failed to send transaction"))
@@ -2107,11 +2239,120 @@ func (s *WSClientUnitTestSuite) TestUnit_DepositUSDC_ErrorSendTransaction() {
 	require.Nil(s.T(), transaction)
 }
 
+func (s *WSClientUnitTestSuite) TestUnit_WithSubAccount() {
+	subAccountClient := s.RyskV2WSClient.WithSubAccount(7)
+	require.Equal(s.T(), int64(7), subAccountClient.SubAccountId)
+	require.NotEqual(s.T(), int64(7), s.RyskV2WSClient.SubAccountId)
+	require.Equal(s.T(), s.RyskV2WSClient.EthClient, subAccountClient.EthClient)
+	require.Equal(s.T(), s.RyskV2WSClient.addressString, subAccountClient.addressString)
+}
+
+func newDepositMockEthClient(balance int64, minDepositAmount int64, allowance int64, status uint64) *mocks.MockEthClient {
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.OnCallContractMethod(constants.ERC20_ABI, "balanceOf", big.NewInt(balance))
+	mockEthClient.OnCallContractMethod(constants.CIAO_ABI, "minDepositAmount", big.NewInt(minDepositAmount))
+	mockEthClient.OnCallContractMethod(constants.ERC20_ABI, "allowance", big.NewInt(allowance))
+	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
+	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
+	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
+	mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+	mockEthClient.On("TransactionReceipt", mock.Anything, mock.Anything).Return(&geth_types.Receipt{Status: status}, nil)
+	return mockEthClient
+}
+
+func (s *WSClientUnitTestSuite) TestUnit_Deposit() {
+	mockEthClient := newDepositMockEthClient(5000, 10, 0, geth_types.ReceiptStatusSuccessful)
+	s.RyskV2WSClient.EthClient = mockEthClient
+
+	result, err := s.RyskV2WSClient.Deposit(context.Background(), big.NewInt(1000))
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), result.ApproveTransaction)
+	require.NotNil(s.T(), result.ApproveReceipt)
+	require.NotNil(s.T(), result.DepositTransaction)
+	require.NotNil(s.T(), result.DepositReceipt)
+	require.True(s.T(), bytes.HasSuffix(result.ApproveTransaction.Data(), common.LeftPadBytes(big.NewInt(1000).Bytes(), 32)))
+	mockEthClient.AssertNumberOfCalls(s.T(), "SendTransaction", 2)
+}
+
+func (s *WSClientUnitTestSuite) TestUnit_Deposit_AllowanceSufficient() {
+	mockEthClient := newDepositMockEthClient(5000, 10, 1000, geth_types.ReceiptStatusSuccessful)
+	s.RyskV2WSClient.EthClient = mockEthClient
+
+	result, err := s.RyskV2WSClient.Deposit(context.Background(), big.NewInt(1000))
+	require.NoError(s.T(), err)
+	require.Nil(s.T(), result.ApproveTransaction)
+	require.Nil(s.T(), result.ApproveReceipt)
+	require.NotNil(s.T(), result.DepositTransaction)
+	require.NotNil(s.T(), result.DepositReceipt)
+	mockEthClient.AssertNumberOfCalls(s.T(), "SendTransaction", 1)
+}
+
+func (s *WSClientUnitTestSuite) TestUnit_Deposit_ApprovalModeMax() {
+	s.RyskV2WSClient.EthClient = newDepositMockEthClient(5000, 10, 0, geth_types.ReceiptStatusSuccessful)
+	s.RyskV2WSClient.ApprovalMode = constants.APPROVAL_MODE_MAX
+	defer func() { s.RyskV2WSClient.ApprovalMode = "" }()
+
+	result, err := s.RyskV2WSClient.Deposit(context.Background(), big.NewInt(1000))
+	require.NoError(s.T(), err)
+	require.True(s.T(), bytes.HasSuffix(result.ApproveTransaction.Data(), abi.MaxUint256.Bytes()))
+}
+
+func (s *WSClientUnitTestSuite) TestUnit_Deposit_ErrorInsufficientBalance() {
+	mockEthClient := newDepositMockEthClient(500, 10, 0, geth_types.ReceiptStatusSuccessful)
+	s.RyskV2WSClient.EthClient = mockEthClient
+
+	result, err := s.RyskV2WSClient.Deposit(context.Background(), big.NewInt(1000))
+	require.Error(s.T(), err)
+	require.Nil(s.T(), result)
+	mockEthClient.AssertNotCalled(s.T(), "SendTransaction", mock.Anything, mock.Anything)
+}
+
+func (s *WSClientUnitTestSuite) TestUnit_Deposit_ErrorBelowMinimum() {
+	mockEthClient := newDepositMockEthClient(5000, 2000, 0, geth_types.ReceiptStatusSuccessful)
+	s.RyskV2WSClient.EthClient = mockEthClient
+
+	result, err := s.RyskV2WSClient.Deposit(context.Background(), big.NewInt(1000))
+	require.Error(s.T(), err)
+	require.Nil(s.T(), result)
+	mockEthClient.AssertNotCalled(s.T(), "SendTransaction", mock.Anything, mock.Anything)
+}
+
+func (s *WSClientUnitTestSuite) TestUnit_Deposit_ErrorReverted() {
+	mockEthClient := newDepositMockEthClient(5000, 10, 1000, geth_types.ReceiptStatusFailed)
+	mockEthClient.On("CallContract", mock.Anything, mock.Anything, mock.Anything).Return([]byte(nil), &mocks.MockDataError{Data: "0x" + hex.EncodeToString(crypto.Keccak256([]byte("DepositQuantityInvalid()"))[:4])})
+	s.RyskV2WSClient.EthClient = mockEthClient
+
+	result, err := s.RyskV2WSClient.Deposit(context.Background(), big.NewInt(1000))
+	require.ErrorIs(s.T(), err, utils.ErrDepositQuantityInvalid)
+	require.NotNil(s.T(), result.DepositTransaction)
+	require.NotNil(s.T(), result.DepositReceipt)
+}
+
+func (s *WSClientUnitTestSuite) TestUnit_Deposit_ErrorEstimateGasRevert() {
+	mockEthClient := new(mocks.MockEthClient)
+	mockEthClient.OnCallContractMethod(constants.ERC20_ABI, "balanceOf", big.NewInt(5000))
+	mockEthClient.OnCallContractMethod(constants.CIAO_ABI, "minDepositAmount", big.NewInt(10))
+	mockEthClient.OnCallContractMethod(constants.ERC20_ABI, "allowance", big.NewInt(1000))
+	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
+	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
+	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(0), &mocks.MockDataError{Data: "0x" + hex.EncodeToString(crypto.Keccak256([]byte("BalanceInsufficient()"))[:4])})
+	s.RyskV2WSClient.EthClient = mockEthClient
+
+	result, err := s.RyskV2WSClient.Deposit(context.Background(), big.NewInt(1000))
+	require.ErrorIs(s.T(), err, utils.ErrBalanceInsufficient)
+	require.Nil(s.T(), result.DepositTransaction)
+}
+
 func (s *WSClientUnitTestSuite) TestUnit_WaitTransaction() {
 	mockEthClient := new(mocks.MockEthClient)
 	s.RyskV2WSClient.EthClient = mockEthClient
 	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
-	mockEthClient.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
 	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
 	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
 	mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
@@ -2130,7 +2371,8 @@ func (s *WSClientUnitTestSuite) TestUnit_WaitTransaction_WaitMinedError() {
 	mockEthClient := new(mocks.MockEthClient)
 	s.RyskV2WSClient.EthClient = mockEthClient
 	mockEthClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(1), nil)
-	mockEthClient.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1000000000), nil)
+	mockEthClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&geth_types.Header{BaseFee: big.NewInt(1000000000)}, nil)
 	mockEthClient.On("NetworkID", mock.Anything).Return(big.NewInt(1), nil)
 	mockEthClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(21000), nil)
 	mockEthClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)